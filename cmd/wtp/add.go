@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/urfave/cli/v3"
@@ -17,23 +18,38 @@ import (
 	"github.com/satococoa/wtp/v2/internal/command"
 	"github.com/satococoa/wtp/v2/internal/config"
 	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/events"
 	"github.com/satococoa/wtp/v2/internal/git"
 	"github.com/satococoa/wtp/v2/internal/hooks"
 	wtpio "github.com/satococoa/wtp/v2/internal/io"
+	"github.com/satococoa/wtp/v2/internal/progress"
+	"github.com/satococoa/wtp/v2/internal/vcs"
 )
 
 // NewAddCommand creates the add command definition
 func NewAddCommand() *cli.Command {
 	return &cli.Command{
-		Name:      "add",
-		Usage:     "Create a new worktree",
-		UsageText: "wtp add <existing-branch>\n       wtp add -b <new-branch> [<commit>]",
+		Name:  "add",
+		Usage: "Create a new worktree",
+		UsageText: "wtp add <existing-branch>\n       wtp add <remote>/<branch>\n" +
+			"       wtp add -b <new-branch> [<commit>]\n       wtp add --detach <tag-or-commit>\n" +
+			"       wtp add --pr <number>",
 		Description: "Creates a new worktree for the specified branch. If the branch doesn't exist locally " +
-			"but exists on a remote, it will be automatically tracked.\n\n" +
+			"but exists on a remote, a local branch tracking it is created.\n\n" +
 			"Examples:\n" +
 			"  wtp add feature/auth                    # Create worktree from existing branch\n" +
+			"  wtp add origin/feature/auth             # Create local feature/auth tracking origin\n" +
 			"  wtp add -b new-feature                  # Create new branch and worktree\n" +
-			"  wtp add -b hotfix/urgent main           # Create new branch from main commit",
+			"  wtp add -b hotfix/urgent main           # Create new branch from main commit\n" +
+			"  wtp add --no-track -b fix origin/main   # Start from origin/main without tracking it\n" +
+			"  wtp add --dry-run -b feature/x          # Show what would happen\n" +
+			"  wtp add --detach v1.2.3                 # Check out a tag or commit with a detached HEAD\n" +
+			"  wtp add --pr 123                        # Fetch pull request #123 into branch pr/123\n" +
+			"  wtp add --profile frontend feature/ui   # Also run the hooks of the frontend profile\n" +
+			"  wtp add --sparse apps,libs feature/ui   # Check out only the apps and libs directories\n" +
+			"  wtp add --submodules top feature/x      # Also initialize the top-level submodules\n" +
+			"  wtp add --max-duration 2m feature/x     # Abort if setup is estimated to take longer\n" +
+			"  wtp add --timeout 10m feature/x         # Stop git and hooks still running after 10 minutes",
 		ShellComplete: completeBranches,
 		Flags: []cli.Flag{
 			&cli.StringFlag{
@@ -41,12 +57,56 @@ func NewAddCommand() *cli.Command {
 				Usage:   "Create new branch",
 				Aliases: []string{"b"},
 			},
+			&cli.BoolFlag{
+				Name:    "detach",
+				Aliases: []string{"checkout-detached"},
+				Usage:   "Check out a tag, commit, or branch with a detached HEAD instead of a branch",
+			},
+			&cli.BoolWithInverseFlag{
+				Name:  "track",
+				Usage: "Set (or with --no-track, do not set) the remote branch a new branch starts from as its upstream",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Print the worktree path, branch setup, and hooks without creating anything",
+			},
+			&cli.BoolFlag{
+				Name:  "ignore-policy",
+				Usage: "Skip the checks in the 'policy' config section (requires policy.allow_override)",
+			},
+			&cli.DurationFlag{
+				Name:  "max-duration",
+				Usage: "Abort before creating the worktree if the estimated hook time exceeds this, e.g. 5m",
+			},
+			&cli.IntFlag{
+				Name:    "pr",
+				Aliases: []string{"from-pr"},
+				Usage:   "Fetch this GitHub pull request or GitLab merge request and create its worktree",
+			},
+			&cli.StringFlag{
+				Name:  "profile",
+				Usage: "Also run the hooks of this profile from the 'profiles' config section",
+			},
+			&cli.StringSliceFlag{
+				Name:  "sparse",
+				Usage: "Check out only these directories, comma-separated, with git sparse-checkout",
+			},
+			&cli.StringFlag{
+				Name:  "submodules",
+				Usage: "Initialize submodules before the hooks run: recursive, top, or none (default: defaults.submodules)",
+			},
+			&cli.BoolFlag{
+				Name:    "quiet",
+				Aliases: []string{"q"},
+				Usage:   "Do not show the spinner and elapsed time of running hooks",
+			},
+			newTimeoutFlag(),
 		},
 		Action: addCommand,
 	}
 }
 
-func addCommand(_ context.Context, cmd *cli.Command) error {
+func addCommand(ctx context.Context, cmd *cli.Command) error {
 	// Get the writer from cli.Command
 	w := cmd.Root().Writer
 	if w == nil {
@@ -59,78 +119,237 @@ func addCommand(_ context.Context, cmd *cli.Command) error {
 		return err
 	}
 
-	// Setup repository and configuration
-	_, cfg, mainRepoPath, err := setupRepoAndConfig()
+	// Setup repository and configuration, including overlays for the target branch
+	_, cfg, mainRepoPath, err := setupRepoAndConfigForBranch(addLocalBranch(cmd, "."))
 	if err != nil {
 		return err
 	}
+	cfg, err = applyAddProfile(cfg, cmd.String("profile"))
+	if err != nil {
+		return err
+	}
+	if !cmd.Bool("dry-run") {
+		if err := ensureWritable(cfg, "create worktrees"); err != nil {
+			return err
+		}
+		release, err := acquireRepoLock(fw, cmd, cfg, mainRepoPath, "wtp add")
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+
+	ctx, cancel := operationContext(ctx, cmd, cfg, "wtp add")
+	defer cancel()
 
 	// Create command executor
-	executor := command.NewRealExecutor()
+	executor := command.NewRealExecutorContext(ctx)
 
-	return addCommandWithCommandExecutor(cmd, fw, executor, cfg, mainRepoPath)
+	return addCommandWithCommandExecutor(ctx, cmd, fw, executor, cfg, mainRepoPath)
 }
 
 // addCommandWithCommandExecutor is the new implementation using CommandExecutor
 func addCommandWithCommandExecutor(
-	cmd *cli.Command, w io.Writer, cmdExec command.Executor, cfg *config.Config, mainRepoPath string,
+	ctx context.Context, cmd *cli.Command, w io.Writer, cmdExec command.Executor, cfg *config.Config, mainRepoPath string,
 ) error {
+	pr, err := resolveAddPullRequest(w, cmd, cmdExec, cfg, mainRepoPath)
+	if err != nil {
+		return err
+	}
+
 	// Resolve worktree path and branch name
-	var firstArg string
-	if cmd.Args().Len() > 0 {
-		firstArg = cmd.Args().Get(0)
+	workTreePath, branchName, ref, err := resolveAddTarget(cmd, cfg, mainRepoPath)
+	if err != nil {
+		return err
 	}
 
-	workTreePath, branchName := resolveWorktreePath(cfg, mainRepoPath, firstArg, cmd)
+	if err := checkAddPolicy(w, cmd, cmdExec, cfg); err != nil {
+		return err
+	}
+	if err := checkWorktreeLocation(w, cfg, mainRepoPath, workTreePath); err != nil {
+		return err
+	}
 
 	// Resolve branch if needed
-	resolvedTrack, err := resolveBranchTracking(cmd, branchName, mainRepoPath)
+	resolvedTrack, err := resolveBranchTracking(cmd, addTargetBranch(cmd), mainRepoPath)
 	if err != nil {
 		return err
 	}
 
-	// Build git worktree command using the new command builder
-	worktreeCmd := buildWorktreeCommand(cmd, workTreePath, branchName, resolvedTrack)
+	backend, err := vcs.ForConfig(cfg, mainRepoPath)
+	if err != nil {
+		return err
+	}
+	worktreeCmds := buildWorktreeCommands(cmd, cfg, backend, workTreePath, branchName, resolvedTrack)
+	submoduleCmds, err := submoduleCommands(cmd, cfg, backend, workTreePath)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Bool("dry-run") {
+		return writeAddDryRun(w, cmd, cfg, mainRepoPath, workTreePath, branchName, resolvedTrack,
+			append(worktreeCmds, submoduleCmds...), pr, ref)
+	}
+	if err := checkProvisionEstimate(w, cmd, cmdExec, cfg); err != nil {
+		return err
+	}
+
+	if err := fetchPullRequest(ctx, cmdExec, pr); err != nil {
+		return err
+	}
+
+	if err := createWorktree(ctx, cmdExec, worktreeCmds, workTreePath, branchName); err != nil {
+		return err
+	}
+	events.Emit(&events.Event{Type: events.TypeWorktreeCreated, Worktree: workTreePath, Branch: branchName})
+
+	if err := syncWorktreeMap(w, cmdExec, mainRepoPath); err != nil {
+		return err
+	}
 
-	// Execute the command
-	result, err := cmdExec.Execute([]command.Command{worktreeCmd})
+	if err := initSubmodules(ctx, w, cmdExec, submoduleCmds, workTreePath); err != nil {
+		return err
+	}
+
+	err = provisionWorktree(ctx, w, cmd, cfg, mainRepoPath, workTreePath, branchName, resolvedTrack,
+		ref.hookVariables(pr.hookVariables()))
 	if err != nil {
 		return err
 	}
 
-	// Check if command succeeded
-	if len(result.Results) > 0 && result.Results[0].Error != nil {
-		gitError := result.Results[0].Error
-		gitOutput := result.Results[0].Output
+	return finishAdd(w, cfg, mainRepoPath, workTreePath, branchName, ref)
+}
 
-		// Analyze git error output for better error messages
-		return analyzeGitWorktreeError(workTreePath, branchName, gitError, gitOutput)
+// finishAdd tells how to switch to the new worktree and runs the after_add actions.
+func finishAdd(w io.Writer, cfg *config.Config, mainRepoPath, workTreePath, branchName string, ref *addRef) error {
+	err := displaySuccessMessageWithCommitish(w, branchName, workTreePath, ref.describe(), cfg, mainRepoPath)
+	if err != nil {
+		return err
 	}
+	return runAfterAdd(w, cfg, workTreePath)
+}
 
-	if err := executePostCreateHooks(w, cfg, mainRepoPath, workTreePath); err != nil {
-		if _, warnErr := fmt.Fprintf(w, "Warning: Hook execution failed: %v\n", err); warnErr != nil {
-			return warnErr
+// createWorktree runs the commands creating the worktree one by one, stopping at the
+// first that fails.
+func createWorktree(
+	ctx context.Context, cmdExec command.Executor, worktreeCmds []command.Command, workTreePath, branchName string,
+) error {
+	for _, worktreeCmd := range worktreeCmds {
+		result, err := cmdExec.Execute([]command.Command{worktreeCmd})
+		if err != nil {
+			return err
+		}
+
+		// Check if command succeeded
+		if len(result.Results) > 0 && result.Results[0].Error != nil {
+			if err := operationTimedOut(ctx, fmt.Sprintf(
+				"'git worktree add' was stopped; if %s was left behind, remove it with 'git worktree prune'",
+				workTreePath)); err != nil {
+				return err
+			}
+			gitError := result.Results[0].Error
+			gitOutput := result.Results[0].Output
+
+			// Analyze git error output for better error messages
+			return analyzeGitWorktreeError(workTreePath, branchName, gitError, gitOutput)
 		}
 	}
+	return nil
+}
 
-	if err := displaySuccessMessage(w, branchName, workTreePath, cfg, mainRepoPath); err != nil {
+// provisionWorktree runs the hooks and verify checks for a freshly created worktree and
+// records the result. Hook and check failures are reported as warnings; once ctx's
+// timeout passes, what is left is skipped and the timeout is returned. The hooks see vars
+// in addition to the usual variables.
+func provisionWorktree(
+	ctx context.Context, w io.Writer, cmd *cli.Command, cfg *config.Config,
+	mainRepoPath, workTreePath, branchName, resolvedTrack string, vars map[string]string,
+) error {
+	timings, hookErr := executePostCreateHooks(ctx, w, cfg, mainRepoPath, workTreePath, vars,
+		newProgressDisplay(cmd.Bool("quiet")))
+	if err := addTimedOut(ctx, cfg, mainRepoPath, workTreePath, timings); err != nil {
+		// Record which hooks completed, so that 'wtp hooks status --rerun' runs the rest
+		record := newProvisionRecord(branchName, addBaseRef(cmd, resolvedTrack), cfg.Hooks.PostCreate, timings, hookErr)
+		record.Profile = cmd.String("profile")
+		record.Variables = vars
+		_ = saveProvisionRecord(cfg, workTreePath, record)
+		_ = registerWorktree(cfg, mainRepoPath, workTreePath, record)
 		return err
 	}
+	if hookErr != nil {
+		warnErr := writeWarning(w, errors.CodeWarnPostCreateHookFailed, "Hook execution failed: %v", hookErr)
+		if warnErr != nil {
+			return warnErr
+		}
+	}
 
-	return nil
+	record := newProvisionRecord(branchName, addBaseRef(cmd, resolvedTrack), cfg.Hooks.PostCreate, timings, hookErr)
+	record.Profile = cmd.String("profile")
+	record.Variables = vars
+	if err := saveProvisionRecord(cfg, workTreePath, record); err != nil {
+		if warnErr := writeWarning(w, errors.CodeWarnProvisionRecordFailed, "%v", err); warnErr != nil {
+			return warnErr
+		}
+	}
+	if err := registerWorktree(cfg, mainRepoPath, workTreePath, record); err != nil {
+		if warnErr := writeWarning(w, errors.CodeWarnProvisionRecordFailed, "%v", err); warnErr != nil {
+			return warnErr
+		}
+	}
+
+	err := executePostCheckoutHooks(ctx, w, cfg, mainRepoPath, workTreePath, "", branchName, vars)
+	if timeoutErr := addTimedOut(ctx, cfg, mainRepoPath, workTreePath, timings); timeoutErr != nil {
+		return timeoutErr
+	}
+	if err != nil {
+		warnErr := writeWarning(w, errors.CodeWarnPostCheckoutHookFailed, "Hook execution failed: %v", err)
+		if warnErr != nil {
+			return warnErr
+		}
+	}
+
+	return runPostCreateVerify(w, cfg, mainRepoPath, workTreePath)
 }
 
-// buildWorktreeCommand builds a git worktree command using the new command package
-func buildWorktreeCommand(
-	cmd *cli.Command, workTreePath, _, resolvedTrack string,
-) command.Command {
-	opts := command.GitWorktreeAddOptions{
-		Branch: cmd.String("branch"),
+// addTimedOut returns the error reporting that 'wtp add' ran out of time after creating
+// the worktree, listing the post_create hooks that completed, or nil while there is time.
+func addTimedOut(
+	ctx context.Context, cfg *config.Config, mainRepoPath, workTreePath string, timings []hooks.HookTiming,
+) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	var completed []string
+	for _, timing := range timings {
+		if timing.Err == nil {
+			completed = append(completed, strconv.Itoa(timing.Index))
+		}
 	}
+	hookProgress := "no post_create hook completed"
+	if len(completed) > 0 {
+		hookProgress = fmt.Sprintf("post_create hooks completed: %s of %d", strings.Join(completed, ", "),
+			len(cfg.Hooks.PostCreate))
+	}
+	name := getWorktreeDisplayName(git.Worktree{Path: workTreePath}, cfg, mainRepoPath)
+	return operationTimedOut(ctx,
+		"created the worktree at "+workTreePath,
+		hookProgress,
+		fmt.Sprintf("run the remaining hooks with 'wtp hooks status --rerun %s'", name))
+}
 
-	// Use resolved track if provided
-	if resolvedTrack != "" {
-		opts.Track = resolvedTrack
+// buildWorktreeCommands builds the commands that create the worktree with backend
+func buildWorktreeCommands(
+	cmd *cli.Command, cfg *config.Config, backend vcs.Backend, workTreePath, branchName, resolvedTrack string,
+) []command.Command {
+	// 'wtp add --pr' checks out the branch the pull request was fetched into
+	if pullRequestOf(cmd) != 0 {
+		return backend.AddWorkdir(workTreePath, branchName, command.GitWorktreeAddOptions{Sparse: addSparsePaths(cmd, cfg)})
+	}
+
+	opts := command.GitWorktreeAddOptions{
+		Branch: cmd.String("branch"),
+		Detach: addDetached(cmd),
+		Sparse: addSparsePaths(cmd, cfg),
 	}
 
 	var commitish string
@@ -139,10 +358,11 @@ func buildWorktreeCommand(
 	if resolvedTrack != "" {
 		// When using resolved tracking, the commitish is the remote branch
 		commitish = resolvedTrack
-		// If there's an argument, it's the local branch name (not used as commitish)
-		if cmd.Args().Len() > 0 && opts.Branch == "" {
-			// The first argument is the branch name when using resolved tracking without -b
-			opts.Branch = cmd.Args().Get(0)
+		opts.Track = resolvedTrack
+		opts.NoTrack = !addTracking(cmd, cfg)
+		if opts.Branch == "" {
+			// The local branch is named after the remote one, e.g. feature/x for origin/feature/x
+			opts.Branch = branchName
 		}
 	} else if cmd.Args().Len() > 0 {
 		// Normal case: first argument is the branch/commitish
@@ -152,8 +372,39 @@ func buildWorktreeCommand(
 			commitish = cmd.Args().Get(1)
 		}
 	}
+	if opts.Branch != "" && resolvedTrack == "" {
+		applyAddTracking(cmd, cfg, &opts, commitish)
+	}
+
+	return backend.AddWorkdir(workTreePath, commitish, opts)
+}
+
+// addSparsePaths returns the directories the new worktree checks out: those of --sparse,
+// else defaults.sparse, which a profile may replace. Empty means all of them.
+func addSparsePaths(cmd *cli.Command, cfg *config.Config) []string {
+	if paths := cmd.StringSlice("sparse"); len(paths) > 0 {
+		return paths
+	}
+	return cfg.Defaults.Sparse
+}
 
-	return command.GitWorktreeAdd(workTreePath, commitish, opts)
+// addBaseRef returns the ref a new branch was started from, mirroring buildWorktreeCommands.
+// It is empty when an existing branch was checked out.
+func addBaseRef(cmd *cli.Command, resolvedTrack string) string {
+	if resolvedTrack != "" {
+		return resolvedTrack
+	}
+	if cmd.String("branch") == "" {
+		return ""
+	}
+	switch cmd.Args().Len() {
+	case 0:
+		return "HEAD"
+	case 1:
+		return cmd.Args().Get(0)
+	default:
+		return cmd.Args().Get(1)
+	}
 }
 
 // analyzeGitWorktreeError analyzes git worktree errors and provides specific error messages
@@ -278,6 +529,9 @@ Solutions:
 Original error: %v`, e.BranchName, e.BranchName, e.GitError)
 }
 
+// ErrorCode returns the stable code of the error.
+func (*WorktreeAlreadyExistsError) ErrorCode() errors.Code { return errors.CodeWorktreeAlreadyExists }
+
 // BranchAlreadyExistsError indicates that a branch creation request conflicts with an existing branch.
 type BranchAlreadyExistsError struct {
 	BranchName string
@@ -297,6 +551,9 @@ Solutions:
 Original error: %v`, e.BranchName, e.BranchName, e.BranchName, e.GitError)
 }
 
+// ErrorCode returns the stable code of the error.
+func (*BranchAlreadyExistsError) ErrorCode() errors.Code { return errors.CodeBranchAlreadyExists }
+
 // PathAlreadyExistsError indicates that the destination directory already exists.
 type PathAlreadyExistsError struct {
 	Path     string
@@ -316,6 +573,9 @@ Solutions:
 Original error: %v`, e.Path, e.GitError)
 }
 
+// ErrorCode returns the stable code of the error.
+func (*PathAlreadyExistsError) ErrorCode() errors.Code { return errors.CodePathAlreadyExists }
+
 // MultipleBranchesError reports that a branch name resolves to multiple remotes and needs disambiguation.
 type MultipleBranchesError struct {
 	BranchName string
@@ -325,32 +585,65 @@ type MultipleBranchesError struct {
 func (e *MultipleBranchesError) Error() string {
 	return fmt.Sprintf(`branch '%s' exists in multiple remotes
 
-Use the --track flag to specify which remote to use:
-  • wtp add --track origin/%s %s
-  • wtp add --track upstream/%s %s
+Name the remote branch to create the local branch from:
+  • wtp add origin/%s
+  • wtp add upstream/%s
 
-Original error: %v`, e.BranchName, e.BranchName, e.BranchName, e.BranchName, e.BranchName, e.GitError)
+Original error: %v`, e.BranchName, e.BranchName, e.BranchName, e.GitError)
 }
 
-func executePostCreateHooks(w io.Writer, cfg *config.Config, repoPath, workTreePath string) error {
-	if cfg.HasHooks() {
-		if _, err := fmt.Fprintln(w, "\nExecuting post-create hooks..."); err != nil {
-			return err
-		}
+// ErrorCode returns the stable code of the error.
+func (*MultipleBranchesError) ErrorCode() errors.Code { return errors.CodeMultipleBranchesFound }
 
-		executor := hooks.NewExecutor(cfg, repoPath)
-		if err := executor.ExecutePostCreateHooks(w, workTreePath); err != nil {
-			return err
-		}
+// executePostCreateHooks runs the configured post_create hooks, showing them on display
+// while they run, and returns the timings of the hooks that completed, including when a
+// later hook failed.
+func executePostCreateHooks(
+	ctx context.Context, w io.Writer, cfg *config.Config, repoPath, workTreePath string, vars map[string]string,
+	display *progress.Display,
+) ([]hooks.HookTiming, error) {
+	if !cfg.HasHooks() {
+		return nil, nil
+	}
+	if _, err := fmt.Fprintln(w, "\nExecuting post-create hooks..."); err != nil {
+		return nil, err
+	}
 
-		if _, err := fmt.Fprintln(w, "✓ All hooks executed successfully"); err != nil {
-			return err
-		}
+	executor := hooks.NewExecutor(cfg, repoPath).WithContext(ctx).WithVariables(vars).WithProgress(display)
+	timings, err := executor.ExecutePostCreateHooksTimed(w, workTreePath)
+	if err != nil {
+		return timings, err
 	}
-	return nil
+
+	_, err = fmt.Fprintln(w, "✓ All hooks executed successfully")
+	return timings, err
+}
+
+// applyAddProfile returns cfg with the hooks of the profile named by --profile appended.
+func applyAddProfile(cfg *config.Config, profile string) (*config.Config, error) {
+	withProfile, ok := cfg.ForProfile(profile)
+	if !ok {
+		return nil, errors.UnknownProfile(profile, cfg.ProfileNames())
+	}
+	return withProfile, nil
 }
 
 func validateAddInput(cmd *cli.Command) error {
+	if err := config.ValidateSparsePaths(cmd.StringSlice("sparse")); err != nil {
+		return fmt.Errorf("invalid --sparse: %w", err)
+	}
+	if err := config.ValidateSubmodules(cmd.String("submodules")); err != nil {
+		return fmt.Errorf("invalid --submodules: %w", err)
+	}
+	if addDetached(cmd) {
+		return validateAddDetach(cmd)
+	}
+	if cmd.IsSet("pr") {
+		if cmd.Args().Len() > 0 || cmd.String("branch") != "" {
+			return errors.InvalidArguments("--pr names the branch itself; it cannot be combined with a branch argument or -b")
+		}
+		return nil
+	}
 	if cmd.Args().Len() == 0 && cmd.String("branch") == "" {
 		return errors.BranchNameRequired("wtp add <existing-branch> | -b <new-branch> [<commit>]")
 	}
@@ -359,6 +652,12 @@ func validateAddInput(cmd *cli.Command) error {
 }
 
 func setupRepoAndConfig() (*git.Repository, *config.Config, string, error) {
+	return setupRepoAndConfigForBranch("")
+}
+
+// setupRepoAndConfigForBranch is like setupRepoAndConfig but merges the 'branches'
+// overlays matching branch into the configuration.
+func setupRepoAndConfigForBranch(branch string) (*git.Repository, *config.Config, string, error) {
 	cwd, err := os.Getwd()
 	if err != nil {
 		return nil, nil, "", errors.DirectoryAccessFailed("access current", ".", err)
@@ -374,7 +673,7 @@ func setupRepoAndConfig() (*git.Repository, *config.Config, string, error) {
 		mainRepoPath = repo.Path()
 	}
 
-	cfg, err := config.LoadConfig(mainRepoPath)
+	cfg, err := config.LoadConfig(mainRepoPath, branch)
 	if err != nil {
 		configPath := mainRepoPath + "/.wtp.yml"
 		return nil, nil, "", errors.ConfigLoadFailed(configPath, err)
@@ -521,6 +820,21 @@ func isMainWorktree(workTreePath, mainRepoPath string) bool {
 	return absWorkTreePath == absMainRepoPath
 }
 
+// addTargetBranch returns the branch a new worktree is created for: the -b value or the first argument.
+// It is empty with --detach, which creates no branch.
+func addTargetBranch(cmd *cli.Command) string {
+	if addDetached(cmd) {
+		return ""
+	}
+	if number := pullRequestOf(cmd); number != 0 {
+		return pullRequestBranch(number)
+	}
+	if newBranch := cmd.String("branch"); newBranch != "" {
+		return newBranch
+	}
+	return cmd.Args().Get(0)
+}
+
 // resolveWorktreePath determines the worktree path and branch name based on arguments
 func resolveWorktreePath(
 	cfg *config.Config, repoPath, firstArg string, cmd *cli.Command,
@@ -533,11 +847,6 @@ func resolveWorktreePath(
 		branchName = newBranch
 	}
 
-	// If still no branch name, try to use the first argument
-	if branchName == "" && firstArg != "" {
-		branchName = firstArg
-	}
-
 	workTreePath = cfg.ResolveWorktreePath(repoPath, branchName)
 	return workTreePath, branchName
 }
@@ -546,8 +855,9 @@ func resolveWorktreePath(
 func resolveBranchTracking(
 	cmd *cli.Command, branchName string, mainRepoPath string,
 ) (string, error) {
-	// Only auto-resolve branch when not creating a new branch and branch name exists
-	if cmd.String("branch") != "" || branchName == "" {
+	// Only auto-resolve branch when not creating a new branch and branch name exists;
+	// a pull request's branch is fetched instead
+	if cmd.String("branch") != "" || branchName == "" || cmd.IsSet("pr") {
 		return "", nil
 	}
 
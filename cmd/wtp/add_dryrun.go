@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/hooks"
+)
+
+// writeAddDryRun prints what 'wtp add' would do: the worktree path, how the branch is set
+// up, the commands creating it, and the hooks with their variables expanded. Nothing is created.
+// pr is the pull request of 'wtp add --pr', or nil; ref is what the worktree checks out.
+func writeAddDryRun(
+	w io.Writer, cmd *cli.Command, cfg *config.Config, mainRepoPath, workTreePath, branchName, resolvedTrack string,
+	worktreeCmds []command.Command, pr *pullRequest, ref *addRef,
+) error {
+	executor := hooks.NewExecutor(cfg, mainRepoPath).WithVariables(ref.hookVariables(pr.hookVariables()))
+	postCreate, err := executor.PlanPostCreateHooks(workTreePath, branchName)
+	if err != nil {
+		return err
+	}
+	postCheckout, err := executor.PlanPostCheckoutHooks(workTreePath, branchName)
+	if err != nil {
+		return err
+	}
+
+	branch := describeAddBranch(cmd, branchName, resolvedTrack, addUpstream(cmd, cfg, resolvedTrack))
+	if ref.detached {
+		branch = "none, " + ref.describe()
+	}
+	if pr != nil {
+		branch = fmt.Sprintf("%s (fetched from %s %s)", branchName, pr.remote, pr.ref)
+		worktreeCmds = append([]command.Command{pr.fetchCommand()}, worktreeCmds...)
+	}
+	if _, err := fmt.Fprintf(w, "Dry run: nothing will be created\n\n"+
+		"Worktree path: %s\nBranch:        %s\n", workTreePath, branch); err != nil {
+		return err
+	}
+	label := "Git command:"
+	if len(worktreeCmds) > 1 || (len(worktreeCmds) > 0 && worktreeCmds[0].Name != "git") {
+		label = "Commands:"
+	}
+	for _, worktreeCmd := range worktreeCmds {
+		line := strings.Join(append([]string{worktreeCmd.Name}, worktreeCmd.Args...), " ")
+		if worktreeCmd.WorkDir == workTreePath {
+			line += "  (in the new worktree)"
+		}
+		if _, err := fmt.Fprintf(w, "%-15s%s\n", label, line); err != nil {
+			return err
+		}
+		label = ""
+	}
+	if err := writePlannedHooks(w, "Post-create hooks", postCreate); err != nil {
+		return err
+	}
+	return writePlannedHooks(w, "Post-checkout hooks", postCheckout)
+}
+
+// describeAddBranch explains how the worktree's branch is set up; upstream is the branch
+// a new one tracks, if wtp sets one.
+func describeAddBranch(cmd *cli.Command, branchName, resolvedTrack, upstream string) string {
+	switch {
+	case upstream != "":
+		return fmt.Sprintf("%s (new, tracking %s)", branchName, upstream)
+	case resolvedTrack != "" || cmd.String("branch") != "":
+		return fmt.Sprintf("%s (new, from %s)", branchName, addBaseRef(cmd, resolvedTrack))
+	default:
+		return fmt.Sprintf("%s (existing)", branchName)
+	}
+}
+
+func writePlannedHooks(w io.Writer, title string, planned []hooks.PlannedHook) error {
+	if len(planned) == 0 {
+		_, err := fmt.Fprintf(w, "\n%s: none\n", title)
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "\n%s:\n", title); err != nil {
+		return err
+	}
+	for i := range planned {
+		entry := &planned[i]
+		line := fmt.Sprintf("  #%d %s: %s", entry.Index, entry.Hook.Type, describeHook(&entry.Hook))
+		switch {
+		case entry.Skip != "":
+			line += fmt.Sprintf("  (skipped, %s)", entry.Skip)
+		case entry.Problem != "":
+			line += fmt.Sprintf("  (would fail: %s)", entry.Problem)
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+		if err := writeIndentedDiff(w, entry.Diff); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeIndentedDiff prints a planned hook's diff below its line.
+func writeIndentedDiff(w io.Writer, diff string) error {
+	if diff == "" {
+		return nil
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		if _, err := fmt.Fprintf(w, "      %s\n", line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func TestAddCommand_DryRun(t *testing.T) {
+	mainRepoPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(mainRepoPath, ".env"), []byte("PORT=3000\n"), 0o644))
+	cfg := &config.Config{
+		Defaults: config.Defaults{BaseDir: "../worktrees"},
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{Type: config.HookTypeCopy, From: ".env", To: ".env"},
+				{Type: config.HookTypeCommand, Command: "make db NAME=${BRANCH_SLUG}"},
+				{Type: config.HookTypeCommand, Command: "make seed", When: `branch == "main"`},
+			},
+			PostCheckout: []config.Hook{
+				{Type: config.HookTypeCommand, Command: "echo ${BRANCH}"},
+			},
+		},
+	}
+	cmd := createTestCLICommand(map[string]any{"branch": "feature/auth", "dry-run": true}, []string{"main"})
+	var buf bytes.Buffer
+	mockExec := &mockCommandExecutor{}
+
+	err := addCommandWithCommandExecutor(context.Background(), cmd, &buf, mockExec, cfg, mainRepoPath)
+
+	require.NoError(t, err)
+	assert.Empty(t, mockExec.executedCommands, "git must not run")
+	workTreePath := filepath.Join(filepath.Dir(mainRepoPath), "worktrees", "feature", "auth")
+	assert.NoDirExists(t, workTreePath)
+	assert.Equal(t, "Dry run: nothing will be created\n\n"+
+		"Worktree path: "+workTreePath+"\n"+
+		"Branch:        feature/auth (new, from main)\n"+
+		"Git command:   git worktree add -b feature/auth "+workTreePath+" main\n"+
+		"\nPost-create hooks:\n"+
+		"  #1 copy: .env → .env\n"+
+		"      --- /dev/null\n"+
+		"      +++ b/.env\n"+
+		"      @@ -0,0 +1 @@\n"+
+		"      +PORT=3000\n"+
+		"  #2 command: make db NAME=feature-auth\n"+
+		"  #3 command: make seed  (skipped, when: branch == \"main\")\n"+
+		"\nPost-checkout hooks:\n"+
+		"  #1 command: echo feature/auth\n", buf.String())
+}
+
+func TestAddCommand_DryRunWithoutHooks(t *testing.T) {
+	cmd := createTestCLICommand(map[string]any{"branch": "feature/auth", "dry-run": true}, nil)
+	var buf bytes.Buffer
+
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+	err := addCommandWithCommandExecutor(context.Background(), cmd, &buf, &mockCommandExecutor{}, cfg, t.TempDir())
+
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "Branch:        feature/auth (new, from HEAD)")
+	assert.Contains(t, buf.String(), "Post-create hooks: none")
+	assert.Contains(t, buf.String(), "Post-checkout hooks: none")
+}
+
+func TestAddCommand_DryRunSparse(t *testing.T) {
+	mainRepoPath := t.TempDir()
+	cmd := createTestCLICommand(map[string]any{"branch": "feature/auth", "dry-run": true}, []string{"main"})
+	var buf bytes.Buffer
+
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees", Sparse: []string{"apps/web"}}}
+	err := addCommandWithCommandExecutor(context.Background(), cmd, &buf, &mockCommandExecutor{}, cfg, mainRepoPath)
+
+	require.NoError(t, err)
+	workTreePath := filepath.Join(filepath.Dir(mainRepoPath), "worktrees", "feature", "auth")
+	assert.Contains(t, buf.String(),
+		"Commands:      git worktree add --no-checkout -b feature/auth "+workTreePath+" main\n"+
+			"               git sparse-checkout set -- apps/web  (in the new worktree)\n"+
+			"               git checkout  (in the new worktree)\n")
+}
+
+func TestAddCommand_DryRunWithJujutsu(t *testing.T) {
+	mainRepoPath := t.TempDir()
+	cmd := createTestCLICommand(map[string]any{"branch": "feature/auth", "dry-run": true}, []string{"main"})
+	var buf bytes.Buffer
+
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees", VCS: config.VCSJujutsu}}
+	err := addCommandWithCommandExecutor(context.Background(), cmd, &buf, &mockCommandExecutor{}, cfg, mainRepoPath)
+
+	require.NoError(t, err)
+	workTreePath := filepath.Join(filepath.Dir(mainRepoPath), "worktrees", "feature", "auth")
+	assert.Contains(t, buf.String(),
+		"Commands:      jj workspace add --name ../worktrees/feature/auth -r main "+workTreePath+"\n"+
+			"               jj bookmark create feature/auth -r main\n")
+}
+
+func TestDescribeAddBranch(t *testing.T) {
+	existing := createTestCLICommand(map[string]any{}, []string{"feature/auth"})
+	assert.Equal(t, "feature/auth (existing)", describeAddBranch(existing, "feature/auth", "", ""))
+	assert.Equal(t, "feature/auth (new, tracking origin/feature/auth)",
+		describeAddBranch(existing, "feature/auth", "origin/feature/auth", "origin/feature/auth"))
+	assert.Equal(t, "feature/auth (new, from origin/feature/auth)",
+		describeAddBranch(existing, "feature/auth", "origin/feature/auth", ""))
+}
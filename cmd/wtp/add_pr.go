@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+)
+
+// pullRequestBranchPrefix starts the local branch of a 'wtp add --pr' worktree, which is
+// named after the pull request, e.g. pr/123.
+const pullRequestBranchPrefix = "pr/"
+
+// pullRequest is the GitHub pull request or GitLab merge request 'wtp add --pr' creates a
+// worktree for.
+type pullRequest struct {
+	number int
+	title  string
+	// headBranch is the branch the request was opened from; empty when neither gh nor
+	// glab could tell.
+	headBranch string
+	remote     string
+	// ref is where the remote publishes the request's head, e.g. refs/pull/123/head.
+	ref string
+}
+
+// pullRequestOf returns the number 'wtp add --pr' was given, or 0 without the flag.
+func pullRequestOf(cmd *cli.Command) int {
+	return cmd.Int("pr")
+}
+
+// pullRequestBranch returns the local branch of the worktree for pull request number.
+func pullRequestBranch(number int) string {
+	return pullRequestBranchPrefix + strconv.Itoa(number)
+}
+
+func (pr *pullRequest) branch() string {
+	return pullRequestBranch(pr.number)
+}
+
+// fetchCommand fetches the request's head into its local branch.
+func (pr *pullRequest) fetchCommand() command.Command {
+	return command.GitFetchRef(pr.remote, pr.ref, "refs/heads/"+pr.branch())
+}
+
+// hookVariables are the variables the hooks of the new worktree see; none without a
+// pull request.
+func (pr *pullRequest) hookVariables() map[string]string {
+	if pr == nil {
+		return nil
+	}
+	return map[string]string{
+		"PR_NUMBER": strconv.Itoa(pr.number),
+		"PR_TITLE":  pr.title,
+	}
+}
+
+// resolveAddPullRequest resolves the pull request 'wtp add --pr' was given, or returns nil
+// without the flag.
+func resolveAddPullRequest(
+	w io.Writer, cmd *cli.Command, executor command.Executor, cfg *config.Config, mainRepoPath string,
+) (*pullRequest, error) {
+	if !cmd.IsSet("pr") {
+		return nil, nil
+	}
+	return resolvePullRequest(w, executor, cfg, mainRepoPath, pullRequestOf(cmd))
+}
+
+// resolvePullRequest looks up pull request number on the remote the repository works
+// with. GitLab remotes are asked with glab, others with gh; without the CLI, or when it
+// fails, the request is still fetched through the remote's refspec, only without title.
+func resolvePullRequest(
+	w io.Writer, executor command.Executor, cfg *config.Config, mainRepoPath string, number int,
+) (*pullRequest, error) {
+	if number <= 0 {
+		return nil, errors.InvalidArguments("invalid pull request number %d: must be positive", number)
+	}
+	remote := cfg.ExpandVariables("${REMOTE}", mainRepoPath, "")
+	if remote == "" {
+		return nil, fmt.Errorf("cannot fetch pull request #%d: the repository has no remote", number)
+	}
+	pr := &pullRequest{number: number, remote: remote}
+
+	gitLab := isGitLabRemote(executor, remote)
+	view := command.GitHubPRView(number)
+	pr.ref = fmt.Sprintf("refs/pull/%d/head", number)
+	if gitLab {
+		view = command.GitLabMRView(number)
+		pr.ref = fmt.Sprintf("refs/merge-requests/%d/head", number)
+	}
+
+	result, err := executor.Execute([]command.Command{view})
+	if err == nil && len(result.Results) > 0 && result.Results[0].Error == nil {
+		if parseErr := pr.parseView(result.Results[0].Output, gitLab); parseErr == nil {
+			_, err := fmt.Fprintf(w, "Pull request #%d: %s (from branch '%s')\n", pr.number, pr.title, pr.headBranch)
+			return pr, err
+		}
+	}
+	_, err = fmt.Fprintf(w, "Could not look up pull request #%d with %s; fetching %s from %s\n",
+		number, view.Name, pr.ref, remote)
+	return pr, err
+}
+
+// parseView reads the JSON gh or glab printed about the request.
+func (pr *pullRequest) parseView(output string, gitLab bool) error {
+	var view struct {
+		Title string `json:"title"`
+		// gh
+		HeadRefName string `json:"headRefName"`
+		// glab
+		SourceBranch string `json:"source_branch"`
+	}
+	if err := json.Unmarshal([]byte(output), &view); err != nil {
+		return err
+	}
+	pr.title = view.Title
+	pr.headBranch = view.HeadRefName
+	if gitLab {
+		pr.headBranch = view.SourceBranch
+	}
+	return nil
+}
+
+// isGitLabRemote reports whether the URL of remote names a GitLab host.
+func isGitLabRemote(executor command.Executor, remote string) bool {
+	result, err := executor.Execute([]command.Command{command.GitRemoteGetURL(remote)})
+	if err != nil || len(result.Results) == 0 || result.Results[0].Error != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(result.Results[0].Output), "gitlab")
+}
+
+// fetchPullRequest fetches the request's head into its local branch before the worktree
+// is created from it. Without a pull request there is nothing to fetch.
+func fetchPullRequest(ctx context.Context, executor command.Executor, pr *pullRequest) error {
+	if pr == nil {
+		return nil
+	}
+	fetch := pr.fetchCommand()
+	commandLine := "git " + strings.Join(fetch.Args, " ")
+	result, err := executor.Execute([]command.Command{fetch})
+	if err != nil {
+		return errors.GitCommandFailed(commandLine, err.Error())
+	}
+	if res := result.Results[0]; res.Error != nil {
+		if timeoutErr := operationTimedOut(ctx, "'git fetch' was stopped"); timeoutErr != nil {
+			return timeoutErr
+		}
+		return errors.GitCommandFailed(commandLine, res.Output)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+// mockPullRequestExecutor answers 'git remote get-url', the gh or glab view, and the fetch.
+type mockPullRequestExecutor struct {
+	remoteURL string
+	view      string
+	viewErr   error
+	fetchErr  error
+	executed  []command.Command
+}
+
+func (m *mockPullRequestExecutor) Execute(commands []command.Command) (*command.ExecutionResult, error) {
+	results := make([]command.Result, len(commands))
+	for i, cmd := range commands {
+		m.executed = append(m.executed, cmd)
+		results[i].Command = cmd
+		switch {
+		case cmd.Name != "git":
+			results[i].Output, results[i].Error = m.view, m.viewErr
+		case cmd.Args[0] == "remote":
+			results[i].Output = m.remoteURL
+		case cmd.Args[0] == "fetch":
+			if m.fetchErr != nil {
+				results[i].Output, results[i].Error = "fatal: couldn't find remote ref", m.fetchErr
+			}
+		}
+	}
+	return &command.ExecutionResult{Results: results}, nil
+}
+
+func pullRequestTestConfig() *config.Config {
+	return &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+}
+
+// pullRequestTestRepo creates a repository whose only remote is origin.
+func pullRequestTestRepo(t *testing.T) string {
+	t.Helper()
+	repo := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", "-q", repo).Run())
+	require.NoError(t, exec.Command("git", "-C", repo, "remote", "add", "origin", "https://example.com/app.git").Run())
+	return repo
+}
+
+func TestResolvePullRequest_GitHub(t *testing.T) {
+	executor := &mockPullRequestExecutor{
+		remoteURL: "git@github.com:acme/app.git",
+		view:      `{"number":123,"title":"Fix login","headRefName":"fix/login"}`,
+	}
+	var buf bytes.Buffer
+
+	pr, err := resolvePullRequest(&buf, executor, pullRequestTestConfig(), pullRequestTestRepo(t), 123)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Fix login", pr.title)
+	assert.Equal(t, "fix/login", pr.headBranch)
+	assert.Equal(t, "refs/pull/123/head", pr.ref)
+	assert.Equal(t, "pr/123", pr.branch())
+	assert.Equal(t, "gh", executor.executed[1].Name)
+	assert.Contains(t, buf.String(), "Pull request #123: Fix login (from branch 'fix/login')")
+	assert.Equal(t, []string{"fetch", "origin", "refs/pull/123/head:refs/heads/pr/123"}, pr.fetchCommand().Args)
+	assert.Equal(t, map[string]string{"PR_NUMBER": "123", "PR_TITLE": "Fix login"}, pr.hookVariables())
+}
+
+func TestResolvePullRequest_GitLab(t *testing.T) {
+	executor := &mockPullRequestExecutor{
+		remoteURL: "https://gitlab.example.com/acme/app.git",
+		view:      `{"iid":9,"title":"Add search","source_branch":"feature/search"}`,
+	}
+
+	pr, err := resolvePullRequest(&bytes.Buffer{}, executor, pullRequestTestConfig(), pullRequestTestRepo(t), 9)
+	require.NoError(t, err)
+
+	assert.Equal(t, "glab", executor.executed[1].Name)
+	assert.Equal(t, "Add search", pr.title)
+	assert.Equal(t, "feature/search", pr.headBranch)
+	assert.Equal(t, "refs/merge-requests/9/head", pr.ref)
+}
+
+func TestResolvePullRequest_FallsBackToRefspec(t *testing.T) {
+	executor := &mockPullRequestExecutor{
+		remoteURL: "git@github.com:acme/app.git",
+		viewErr:   fmt.Errorf("executable file not found in $PATH"),
+	}
+	var buf bytes.Buffer
+
+	pr, err := resolvePullRequest(&buf, executor, pullRequestTestConfig(), pullRequestTestRepo(t), 5)
+	require.NoError(t, err)
+
+	assert.Empty(t, pr.title)
+	assert.Equal(t, "refs/pull/5/head", pr.ref)
+	assert.Contains(t, buf.String(), "Could not look up pull request #5 with gh; fetching refs/pull/5/head from origin")
+}
+
+func TestResolvePullRequest_InvalidNumber(t *testing.T) {
+	_, err := resolvePullRequest(&bytes.Buffer{}, &mockPullRequestExecutor{}, pullRequestTestConfig(), "/repo", 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be positive")
+}
+
+func TestResolvePullRequest_NoRemote(t *testing.T) {
+	repo := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", "-q", repo).Run())
+
+	_, err := resolvePullRequest(&bytes.Buffer{}, &mockPullRequestExecutor{}, pullRequestTestConfig(), repo, 3)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the repository has no remote")
+}
+
+func TestFetchPullRequest(t *testing.T) {
+	pr := &pullRequest{number: 7, remote: "origin", ref: "refs/pull/7/head"}
+
+	executor := &mockPullRequestExecutor{}
+	require.NoError(t, fetchPullRequest(context.Background(), executor, pr))
+	require.Len(t, executor.executed, 1)
+	assert.Equal(t, "refs/pull/7/head:refs/heads/pr/7", executor.executed[0].Args[2])
+
+	executor = &mockPullRequestExecutor{fetchErr: fmt.Errorf("exit status 128")}
+	err := fetchPullRequest(context.Background(), executor, pr)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "git fetch origin refs/pull/7/head:refs/heads/pr/7")
+	assert.Contains(t, err.Error(), "couldn't find remote ref")
+
+	assert.NoError(t, fetchPullRequest(context.Background(), executor, nil))
+}
+
+func TestAddCommand_PullRequestValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "pr alone", args: []string{"add", "--pr", "12"}},
+		{name: "from-pr alias", args: []string{"add", "--from-pr", "12"}},
+		{name: "pr with branch", args: []string{"add", "--pr", "12", "feature"}, wantErr: "--pr names the branch"},
+		{name: "pr with -b", args: []string{"add", "--pr", "12", "-b", "x"}, wantErr: "--pr names the branch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var validateErr error
+			cmd := NewAddCommand()
+			cmd.Action = func(_ context.Context, cmd *cli.Command) error {
+				validateErr = validateAddInput(cmd)
+				if validateErr == nil {
+					assert.Equal(t, "pr/12", addTargetBranch(cmd))
+				}
+				return nil
+			}
+			require.NoError(t, cmd.Run(context.Background(), tt.args))
+			if tt.wantErr == "" {
+				assert.NoError(t, validateErr)
+				return
+			}
+			require.Error(t, validateErr)
+			assert.Contains(t, validateErr.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+// addRef is what 'wtp add' checks out: a branch, or with --detach, a tag, branch, or
+// commit at a detached HEAD.
+type addRef struct {
+	name     string
+	refType  string // git.RefTypeBranch, git.RefTypeTag, or git.RefTypeCommit
+	detached bool
+}
+
+// addDetached reports whether 'wtp add' was given --detach.
+func addDetached(cmd *cli.Command) bool {
+	return cmd.Bool("detach")
+}
+
+// validateAddDetach checks the arguments of 'wtp add --detach', which takes a single tag
+// or commit and creates no branch.
+func validateAddDetach(cmd *cli.Command) error {
+	if cmd.String("branch") != "" || cmd.IsSet("pr") || cmd.IsSet("track") {
+		return errors.InvalidArguments("--detach checks out a tag or commit without a branch; " +
+			"it cannot be combined with -b, --pr, or --track")
+	}
+	switch cmd.Args().Len() {
+	case 0:
+		return errors.BranchNameRequired("wtp add --detach <tag-or-commit>")
+	case 1:
+		return nil
+	default:
+		return errors.InvalidArguments("--detach takes a single tag or commit, got %d arguments", cmd.Args().Len())
+	}
+}
+
+// resolveAddTarget returns the path of the new worktree, the branch it has checked out,
+// and the ref it is created from. With --detach, the branch is empty, and the path comes
+// from base_dir expanded for the ref and its type.
+func resolveAddTarget(
+	cmd *cli.Command, cfg *config.Config, mainRepoPath string,
+) (workTreePath, branchName string, ref *addRef, err error) {
+	if !addDetached(cmd) {
+		workTreePath, branchName = resolveWorktreePath(cfg, mainRepoPath, addLocalBranch(cmd, mainRepoPath), cmd)
+		return workTreePath, branchName, &addRef{name: branchName, refType: git.RefTypeBranch}, nil
+	}
+
+	ref = &addRef{name: cmd.Args().First(), detached: true}
+	repo, err := git.NewRepository(mainRepoPath)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if ref.refType, err = repo.ResolveRefType(ref.name); err != nil {
+		return "", "", nil, err
+	}
+	return cfg.ResolveRefWorktreePath(mainRepoPath, ref.name, ref.refType), "", ref, nil
+}
+
+// hookVariables returns vars with ${REF} and ${REF_TYPE} added for the hooks of the new
+// worktree.
+func (r *addRef) hookVariables(vars map[string]string) map[string]string {
+	merged := make(map[string]string, len(vars)+2) //nolint:mnd // REF and REF_TYPE
+	for name, value := range vars {
+		merged[name] = value
+	}
+	merged["REF"] = r.name
+	merged["REF_TYPE"] = r.refType
+	return merged
+}
+
+// describe names a detached ref with its type for messages, e.g. "v1.2.3 (tag, detached
+// HEAD)"; it is empty for a branch, which messages show as such.
+func (r *addRef) describe() string {
+	if !r.detached {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s, detached HEAD)", r.name, r.refType)
+}
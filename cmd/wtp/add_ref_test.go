@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/vcs"
+)
+
+func setupAddRefRepo(t *testing.T) (repo, commit string) {
+	t.Helper()
+	repo = t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q")
+	git("-c", "user.name=wtp", "-c", "user.email=wtp@example.com", "commit", "-q", "--allow-empty", "-m", "init")
+	git("tag", "v1.2.3")
+	return repo, git("rev-parse", "--short", "HEAD")
+}
+
+func TestValidateAddInput_Detach(t *testing.T) {
+	runAddFlags(t, []string{"--detach", "v1.2.3"}, func(cmd *cli.Command) {
+		assert.NoError(t, validateAddInput(cmd))
+		assert.Empty(t, addTargetBranch(cmd), "--detach creates no branch")
+	})
+	runAddFlags(t, []string{"--checkout-detached", "v1.2.3"}, func(cmd *cli.Command) {
+		assert.True(t, addDetached(cmd))
+	})
+	runAddFlags(t, []string{"--detach"}, func(cmd *cli.Command) {
+		code, _ := errors.CodeOf(validateAddInput(cmd))
+		assert.Equal(t, errors.CodeBranchNameRequired, code)
+	})
+	runAddFlags(t, []string{"--detach", "v1.2.3", "v1.2.4"}, func(cmd *cli.Command) {
+		assert.ErrorContains(t, validateAddInput(cmd), "single tag or commit")
+	})
+	for _, args := range [][]string{
+		{"--detach", "-b", "feature/x", "v1.2.3"},
+		{"--detach", "--pr", "12"},
+		{"--detach", "--track", "origin/main"},
+	} {
+		runAddFlags(t, args, func(cmd *cli.Command) {
+			assert.ErrorContains(t, validateAddInput(cmd), "cannot be combined", args)
+		})
+	}
+}
+
+func TestBuildWorktreeCommands_Detach(t *testing.T) {
+	runAddFlags(t, []string{"--detach", "v1.2.3"}, func(cmd *cli.Command) {
+		cmds := buildWorktreeCommands(cmd, &config.Config{}, vcs.Git{}, "/wt", "", "")
+		require.Len(t, cmds, 1)
+		assert.Equal(t, []string{"worktree", "add", "--detach", "/wt", "v1.2.3"}, cmds[0].Args)
+	})
+}
+
+func TestResolveAddTarget(t *testing.T) {
+	repo, commit := setupAddRefRepo(t)
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees/${REF_TYPE}"}}
+	worktrees := filepath.Join(filepath.Dir(repo), "worktrees")
+
+	tests := []struct {
+		args     []string
+		wantPath string
+		wantRef  addRef
+	}{
+		{
+			args:     []string{"--detach", "v1.2.3"},
+			wantPath: filepath.Join(worktrees, "tag", "v1.2.3"),
+			wantRef:  addRef{name: "v1.2.3", refType: "tag", detached: true},
+		},
+		{
+			args:     []string{"--detach", commit},
+			wantPath: filepath.Join(worktrees, "commit", commit),
+			wantRef:  addRef{name: commit, refType: "commit", detached: true},
+		},
+		{
+			args:     []string{"-b", "feature/x", "v1.2.3"},
+			wantPath: filepath.Join(worktrees, "branch", "feature", "x"),
+			wantRef:  addRef{name: "feature/x", refType: "branch"},
+		},
+	}
+	for _, tt := range tests {
+		runAddFlags(t, tt.args, func(cmd *cli.Command) {
+			path, branch, ref, err := resolveAddTarget(cmd, cfg, repo)
+			require.NoError(t, err, tt.args)
+			assert.Equal(t, tt.wantPath, path, tt.args)
+			assert.Equal(t, tt.wantRef, *ref, tt.args)
+			if ref.detached {
+				assert.Empty(t, branch)
+			}
+		})
+	}
+
+	runAddFlags(t, []string{"--detach", "v9.9.9"}, func(cmd *cli.Command) {
+		_, _, _, err := resolveAddTarget(cmd, cfg, repo)
+		code, _ := errors.CodeOf(err)
+		assert.Equal(t, errors.CodeRefNotFound, code)
+	})
+}
+
+func TestAddRef_HookVariables(t *testing.T) {
+	ref := &addRef{name: "v1.2.3", refType: "tag", detached: true}
+	assert.Equal(t, map[string]string{"REF": "v1.2.3", "REF_TYPE": "tag"}, ref.hookVariables(nil))
+	assert.Equal(t, map[string]string{"PR_NUMBER": "1", "REF": "pr/1", "REF_TYPE": "branch"},
+		(&addRef{name: "pr/1", refType: "branch"}).hookVariables(map[string]string{"PR_NUMBER": "1"}))
+	assert.Equal(t, "v1.2.3 (tag, detached HEAD)", ref.describe())
+	assert.Empty(t, (&addRef{name: "main", refType: "branch"}).describe())
+}
+
+func TestAddCommand_DryRunDetach(t *testing.T) {
+	repo, _ := setupAddRefRepo(t)
+	cfg := &config.Config{
+		Defaults: config.Defaults{BaseDir: "../worktrees"},
+		Hooks: config.Hooks{PostCreate: []config.Hook{
+			{Type: config.HookTypeCommand, Command: "echo ${REF_TYPE} ${REF}"},
+		}},
+	}
+	var buf bytes.Buffer
+	mockExec := &mockCommandExecutor{}
+	runAddFlags(t, []string{"--detach", "--dry-run", "v1.2.3"}, func(cmd *cli.Command) {
+		require.NoError(t, addCommandWithCommandExecutor(context.Background(), cmd, &buf, mockExec, cfg, repo))
+	})
+
+	workTreePath := filepath.Join(filepath.Dir(repo), "worktrees", "v1.2.3")
+	assert.Contains(t, buf.String(), "Branch:        none, v1.2.3 (tag, detached HEAD)\n")
+	assert.Contains(t, buf.String(), "git worktree add --detach "+workTreePath+" v1.2.3\n")
+	assert.Contains(t, buf.String(), "echo tag v1.2.3")
+}
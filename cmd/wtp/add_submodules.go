@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/vcs"
+)
+
+// addSubmodules returns how the submodules of the new worktree are initialized: --submodules
+// when given, else defaults.submodules.
+func addSubmodules(cmd *cli.Command, cfg *config.Config) string {
+	if cmd.IsSet("submodules") {
+		return cmd.String("submodules")
+	}
+	return cfg.Defaults.Submodules
+}
+
+// submoduleCommands returns the commands initializing the submodules of the new worktree,
+// none when they are left alone. Only git worktrees have submodules wtp can initialize.
+func submoduleCommands(
+	cmd *cli.Command, cfg *config.Config, backend vcs.Backend, workTreePath string,
+) ([]command.Command, error) {
+	mode := addSubmodules(cmd, cfg)
+	if mode == "" || mode == config.SubmodulesNone {
+		return nil, nil
+	}
+	if backend.Name() != config.VCSGit {
+		return nil, errors.InvalidArguments("submodules can only be initialized in git worktrees, not with vcs '%s'; "+
+			"set --submodules none or remove defaults.submodules", backend.Name())
+	}
+	return []command.Command{command.GitSubmoduleUpdate(workTreePath, mode == config.SubmodulesRecursive)}, nil
+}
+
+// initSubmodules runs the submodule commands in the new worktree. The worktree is kept
+// when they fail, so the error says how to finish the job by hand.
+func initSubmodules(
+	ctx context.Context, w io.Writer, executor command.Executor, cmds []command.Command, workTreePath string,
+) error {
+	if len(cmds) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintln(w, "\nInitializing submodules..."); err != nil {
+		return err
+	}
+	for _, submoduleCmd := range cmds {
+		commandLine := "git " + strings.Join(submoduleCmd.Args, " ")
+		result, err := executor.Execute([]command.Command{submoduleCmd})
+		if err != nil {
+			return errors.GitCommandFailed(commandLine, err.Error())
+		}
+		if res := result.Results[0]; res.Error != nil {
+			if timeoutErr := operationTimedOut(ctx, "'git submodule update' was stopped"); timeoutErr != nil {
+				return timeoutErr
+			}
+			return fmt.Errorf("worktree created at %s, but its submodules were not initialized: %w",
+				workTreePath, errors.GitCommandFailed(commandLine, res.Output))
+		}
+	}
+	_, err := fmt.Fprintln(w, "✓ Submodules initialized")
+	return err
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/vcs"
+)
+
+func TestSubmoduleCommands(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		defaults string
+		want     []string
+	}{
+		{name: "none by default", args: []string{"feature/x"}},
+		{
+			name:     "defaults.submodules",
+			args:     []string{"feature/x"},
+			defaults: config.SubmodulesRecursive,
+			want:     []string{"submodule", "update", "--init", "--recursive"},
+		},
+		{
+			name:     "flag overrides defaults",
+			args:     []string{"--submodules", "top", "feature/x"},
+			defaults: config.SubmodulesRecursive,
+			want:     []string{"submodule", "update", "--init"},
+		},
+		{
+			name:     "flag turns them off",
+			args:     []string{"--submodules", "none", "feature/x"},
+			defaults: config.SubmodulesRecursive,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Defaults: config.Defaults{Submodules: tt.defaults}}
+			runAddFlags(t, tt.args, func(cmd *cli.Command) {
+				cmds, err := submoduleCommands(cmd, cfg, vcs.Git{}, "/wt")
+				require.NoError(t, err)
+				if tt.want == nil {
+					assert.Empty(t, cmds)
+					return
+				}
+				require.Len(t, cmds, 1)
+				assert.Equal(t, tt.want, cmds[0].Args)
+				assert.Equal(t, "/wt", cmds[0].WorkDir)
+			})
+		})
+	}
+}
+
+func TestSubmoduleCommands_Jujutsu(t *testing.T) {
+	cfg := &config.Config{Defaults: config.Defaults{Submodules: config.SubmodulesTop}}
+	runAddFlags(t, []string{"feature/x"}, func(cmd *cli.Command) {
+		_, err := submoduleCommands(cmd, cfg, vcs.NewJujutsu("/repo"), "/wt")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only be initialized in git worktrees")
+	})
+}
+
+func TestValidateAddInput_Submodules(t *testing.T) {
+	cmd := createTestCLICommand(map[string]any{"submodules": "all"}, []string{"feature/x"})
+	err := validateAddInput(cmd)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid --submodules")
+}
+
+func TestInitSubmodules(t *testing.T) {
+	cmds := []command.Command{command.GitSubmoduleUpdate("/wt", true)}
+
+	var buf bytes.Buffer
+	executor := &mockCommandExecutor{}
+	require.NoError(t, initSubmodules(context.Background(), &buf, executor, cmds, "/wt"))
+	assert.Equal(t, cmds, executor.executedCommands)
+	assert.Contains(t, buf.String(), "✓ Submodules initialized")
+
+	buf.Reset()
+	err := initSubmodules(context.Background(), &buf, &mockCommandExecutor{shouldFail: true}, cmds, "/wt")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "worktree created at /wt, but its submodules were not initialized")
+	assert.Contains(t, err.Error(), "git submodule update --init --recursive")
+
+	executor = &mockCommandExecutor{}
+	require.NoError(t, initSubmodules(context.Background(), &buf, executor, nil, "/wt"))
+	assert.Empty(t, executor.executedCommands)
+}
+
+func TestAddCommand_DryRunSubmodules(t *testing.T) {
+	mainRepoPath := t.TempDir()
+	cmd := createTestCLICommand(map[string]any{"branch": "feature/auth", "submodules": "top", "dry-run": true}, nil)
+	var buf bytes.Buffer
+
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+	err := addCommandWithCommandExecutor(context.Background(), cmd, &buf, &mockCommandExecutor{}, cfg, mainRepoPath)
+
+	require.NoError(t, err)
+	workTreePath := filepath.Join(filepath.Dir(mainRepoPath), "worktrees", "feature", "auth")
+	assert.Contains(t, buf.String(),
+		"Commands:      git worktree add -b feature/auth "+workTreePath+"\n"+
+			"               git submodule update --init  (in the new worktree)\n")
+}
@@ -3,7 +3,10 @@ package main
 import (
 	"bytes"
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -12,6 +15,8 @@ import (
 	"github.com/satococoa/wtp/v2/internal/command"
 	"github.com/satococoa/wtp/v2/internal/config"
 	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/hooks"
+	"github.com/satococoa/wtp/v2/internal/vcs"
 )
 
 // ===== Command Structure Tests =====
@@ -167,8 +172,8 @@ func TestMultipleBranchesError(t *testing.T) {
 		// Then: should contain branch name, track suggestions, and original error
 		assert.Contains(t, message, "feature/shared")
 		assert.Contains(t, message, "exists in multiple remotes")
-		assert.Contains(t, message, "--track origin/feature/shared")
-		assert.Contains(t, message, "--track upstream/feature/shared")
+		assert.Contains(t, message, "wtp add origin/feature/shared")
+		assert.Contains(t, message, "wtp add upstream/feature/shared")
 		assert.Contains(t, message, "multiple remotes found")
 	})
 
@@ -184,8 +189,8 @@ func TestMultipleBranchesError(t *testing.T) {
 
 		// Then: should properly format all instances of branch name
 		assert.Contains(t, message, "feature/fix-bugs-#123")
-		assert.Contains(t, message, "--track origin/feature/fix-bugs-#123")
-		assert.Contains(t, message, "--track upstream/feature/fix-bugs-#123")
+		assert.Contains(t, message, "wtp add origin/feature/fix-bugs-#123")
+		assert.Contains(t, message, "wtp add upstream/feature/fix-bugs-#123")
 	})
 }
 
@@ -281,6 +286,29 @@ func TestValidateAddInput(t *testing.T) {
 	}
 }
 
+func TestApplyAddProfile(t *testing.T) {
+	cfg := &config.Config{
+		Hooks: config.Hooks{PostCreate: []config.Hook{{Type: config.HookTypeCommand, Command: "make env"}}},
+		Profiles: map[string]config.Profile{
+			"frontend": {Hooks: config.Hooks{PostCreate: []config.Hook{{Type: config.HookTypeCommand, Command: "npm ci"}}}},
+		},
+	}
+
+	withProfile, err := applyAddProfile(cfg, "frontend")
+	require.NoError(t, err)
+	require.Len(t, withProfile.Hooks.PostCreate, 2)
+	assert.Equal(t, "npm ci", withProfile.Hooks.PostCreate[1].Command)
+
+	same, err := applyAddProfile(cfg, "")
+	require.NoError(t, err)
+	assert.Same(t, cfg, same)
+
+	_, err = applyAddProfile(cfg, "backend")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown profile 'backend'")
+	assert.Contains(t, err.Error(), "Available profiles: frontend")
+}
+
 func TestResolveWorktreePath(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -322,6 +350,14 @@ func TestResolveWorktreePath(t *testing.T) {
 	}
 }
 
+func TestAddTargetBranch(t *testing.T) {
+	cmd := createTestCLICommand(map[string]any{}, []string{"release/1.0"})
+	assert.Equal(t, "release/1.0", addTargetBranch(cmd))
+
+	cmd = createTestCLICommand(map[string]any{"branch": "release/2.0"}, []string{"main"})
+	assert.Equal(t, "release/2.0", addTargetBranch(cmd))
+}
+
 // ===== Command Building Tests =====
 
 // ===== Command Execution Tests =====
@@ -372,7 +408,7 @@ func TestAddCommand_CommandConstruction(t *testing.T) {
 				},
 			}
 
-			err := addCommandWithCommandExecutor(cmd, &buf, mockExec, cfg, "/test/repo")
+			err := addCommandWithCommandExecutor(context.Background(), cmd, &buf, mockExec, cfg, "/test/repo")
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -413,7 +449,7 @@ func TestAddCommand_SuccessMessage(t *testing.T) {
 				Defaults: config.Defaults{BaseDir: "/test/worktrees"},
 			}
 
-			err := addCommandWithCommandExecutor(cmd, &buf, mockExec, cfg, "/test/repo")
+			err := addCommandWithCommandExecutor(context.Background(), cmd, &buf, mockExec, cfg, "/test/repo")
 
 			assert.NoError(t, err)
 			assert.Contains(t, buf.String(), tt.expectedOutput)
@@ -456,12 +492,59 @@ func TestAddCommand_ExecutionError(t *testing.T) {
 		Defaults: config.Defaults{BaseDir: "/test/worktrees"},
 	}
 
-	err := addCommandWithCommandExecutor(cmd, &buf, mockExec, cfg, "/test/repo")
+	err := addCommandWithCommandExecutor(context.Background(), cmd, &buf, mockExec, cfg, "/test/repo")
 
 	assert.Error(t, err)
 	assert.Len(t, mockExec.executedCommands, 1)
 }
 
+func TestAddCommand_PathInOtherClone(t *testing.T) {
+	root := t.TempDir()
+	worktreesDir := filepath.Join(root, "worktrees")
+	setupClone := func(name string) string {
+		repoPath := filepath.Join(root, name)
+		gitDir := filepath.Join(repoPath, ".git", "worktrees", "x")
+		require.NoError(t, os.MkdirAll(gitDir, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(gitDir, "commondir"), []byte("../..\n"), 0o644))
+		return repoPath
+	}
+	cloneA := setupClone("clone-a")
+	cloneB := setupClone("clone-b")
+
+	// clone-b already created feature/x in the shared base_dir
+	worktreePath := filepath.Join(worktreesDir, "feature", "x")
+	require.NoError(t, os.MkdirAll(worktreePath, 0o755))
+	gitFile := "gitdir: " + filepath.Join(cloneB, ".git", "worktrees", "x") + "\n"
+	require.NoError(t, os.WriteFile(filepath.Join(worktreePath, ".git"), []byte(gitFile), 0o644))
+
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: worktreesDir}}
+
+	t.Run("worktree of another clone is refused", func(t *testing.T) {
+		mockExec := &mockCommandExecutor{}
+		var buf bytes.Buffer
+		cmd := createTestCLICommand(map[string]any{"branch": "feature/x"}, []string{})
+
+		err := addCommandWithCommandExecutor(context.Background(), cmd, &buf, mockExec, cfg, cloneA)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "belongs to another clone: "+cloneB)
+		assert.Contains(t, err.Error(), "${DIRNAME}")
+		assert.Empty(t, mockExec.executedCommands)
+	})
+
+	t.Run("own worktree is left to git", func(t *testing.T) {
+		mockExec := &mockCommandExecutor{shouldFail: true}
+		var buf bytes.Buffer
+		cmd := createTestCLICommand(map[string]any{"branch": "feature/x"}, []string{})
+
+		err := addCommandWithCommandExecutor(context.Background(), cmd, &buf, mockExec, cfg, cloneB)
+
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "another clone")
+		assert.Len(t, mockExec.executedCommands, 1)
+	})
+}
+
 // ===== Edge Cases Tests =====
 
 func TestAddCommand_InternationalCharacters(t *testing.T) {
@@ -496,7 +579,7 @@ func TestAddCommand_InternationalCharacters(t *testing.T) {
 				Defaults: config.Defaults{BaseDir: "/test/worktrees"},
 			}
 
-			err := addCommandWithCommandExecutor(cmd, &buf, mockExec, cfg, "/test/repo")
+			err := addCommandWithCommandExecutor(context.Background(), cmd, &buf, mockExec, cfg, "/test/repo")
 
 			assert.NoError(t, err)
 			assert.Len(t, mockExec.executedCommands, 1)
@@ -521,6 +604,10 @@ func createTestCLICommand(flags map[string]any, args []string) *cli.Command {
 					&cli.StringFlag{Name: "track"},
 					&cli.BoolFlag{Name: "cd"},
 					&cli.BoolFlag{Name: "no-cd"},
+					&cli.BoolFlag{Name: "ignore-policy"},
+					&cli.BoolFlag{Name: "dry-run"},
+					&cli.DurationFlag{Name: "max-duration"},
+					&cli.StringFlag{Name: "submodules"},
 				},
 				Action: func(_ context.Context, _ *cli.Command) error {
 					return nil
@@ -561,7 +648,7 @@ func TestAddCommand_SimplifiedInterface(t *testing.T) {
 		}
 
 		// When: running add command with existing branch (mock mode - skip repo check)
-		err := addCommandWithCommandExecutor(cmd, &buf, mockExec, cfg, "/test/repo")
+		err := addCommandWithCommandExecutor(context.Background(), cmd, &buf, mockExec, cfg, "/test/repo")
 
 		// Then: should create worktree successfully (in mock mode, branch tracking will fail but command should work)
 		// Note: This test will fail with "not in git repository" because resolveBranchTracking calls git.NewRepository
@@ -580,7 +667,7 @@ func TestAddCommand_SimplifiedInterface(t *testing.T) {
 		}
 
 		// When: running add command with -b flag (this should work without git repo)
-		err := addCommandWithCommandExecutor(cmd, &buf, mockExec, cfg, "/test/repo")
+		err := addCommandWithCommandExecutor(context.Background(), cmd, &buf, mockExec, cfg, "/test/repo")
 
 		// Then: should create new branch and worktree
 		assert.NoError(t, err)
@@ -600,7 +687,7 @@ func TestAddCommand_SimplifiedInterface(t *testing.T) {
 		}
 
 		// When: running add command with -b flag and commit
-		err := addCommandWithCommandExecutor(cmd, &buf, mockExec, cfg, "/test/repo")
+		err := addCommandWithCommandExecutor(context.Background(), cmd, &buf, mockExec, cfg, "/test/repo")
 
 		// Then: should create new branch from commit and worktree
 		assert.NoError(t, err)
@@ -659,6 +746,8 @@ func TestAddCommand_Integration(t *testing.T) {
 						&cli.StringFlag{Name: "track", Aliases: []string{"t"}},
 						&cli.BoolFlag{Name: "cd"},
 						&cli.BoolFlag{Name: "no-cd"},
+						&cli.BoolFlag{Name: "ignore-policy"},
+						&cli.BoolFlag{Name: "dry-run"},
 					},
 					Action: addCommand,
 				},
@@ -689,6 +778,8 @@ func TestAddCommand_Integration(t *testing.T) {
 						&cli.StringFlag{Name: "track", Aliases: []string{"t"}},
 						&cli.BoolFlag{Name: "cd"},
 						&cli.BoolFlag{Name: "no-cd"},
+						&cli.BoolFlag{Name: "ignore-policy"},
+						&cli.BoolFlag{Name: "dry-run"},
 					},
 					Action: addCommand,
 				},
@@ -712,7 +803,7 @@ func TestExecutePostCreateHooks_Integration(t *testing.T) {
 		var buf bytes.Buffer
 
 		// When: executing post create hooks
-		err := executePostCreateHooks(&buf, cfg, "/test/repo", "/test/worktree")
+		_, err := executePostCreateHooks(context.Background(), &buf, cfg, "/test/repo", "/test/worktree", nil, nil)
 
 		// Then: should complete without error and no output
 		assert.NoError(t, err)
@@ -731,7 +822,7 @@ func TestExecutePostCreateHooks_Integration(t *testing.T) {
 		var buf bytes.Buffer
 
 		// When: executing post create hooks
-		err := executePostCreateHooks(&buf, cfg, "/test/repo", "/test/worktree")
+		_, err := executePostCreateHooks(context.Background(), &buf, cfg, "/test/repo", "/test/worktree", nil, nil)
 
 		// Then: should return error for failed hook execution
 		// This tests the error handling path in executePostCreateHooks
@@ -741,6 +832,36 @@ func TestExecutePostCreateHooks_Integration(t *testing.T) {
 	})
 }
 
+func TestAddTimedOut(t *testing.T) {
+	cfg := &config.Config{
+		Defaults: config.Defaults{BaseDir: "../worktrees"},
+		Hooks: config.Hooks{PostCreate: []config.Hook{
+			{Type: config.HookTypeCommand, Command: "echo one"},
+			{Type: config.HookTypeCommand, Command: "sleep 30"},
+			{Type: config.HookTypeCommand, Command: "echo three"},
+		}},
+	}
+	workTreePath := "/test/worktrees/feature/auth"
+	timings := []hooks.HookTiming{{Index: 1}, {Index: 2, Err: context.DeadlineExceeded}}
+
+	assert.NoError(t, addTimedOut(context.Background(), cfg, "/test/repo", workTreePath, timings))
+
+	ctx, cancel := context.WithTimeoutCause(context.Background(), time.Millisecond,
+		&operationTimeout{operation: "wtp add", timeout: time.Millisecond})
+	defer cancel()
+	<-ctx.Done()
+	err := addTimedOut(ctx, cfg, "/test/repo", workTreePath, timings)
+	require.Error(t, err)
+	code, _ := errors.CodeOf(err)
+	assert.Equal(t, errors.CodeOperationTimedOut, code)
+	assert.Contains(t, err.Error(), "created the worktree at "+workTreePath)
+	assert.Contains(t, err.Error(), "post_create hooks completed: 1 of 3")
+	assert.Contains(t, err.Error(), "'wtp hooks status --rerun feature/auth'")
+
+	err = addTimedOut(ctx, cfg, "/test/repo", workTreePath, nil)
+	assert.Contains(t, err.Error(), "no post_create hook completed")
+}
+
 func TestDisplaySuccessMessage_Integration(t *testing.T) {
 	t.Run("should display friendly success message with branch name", func(t *testing.T) {
 		// Given: a buffer and branch name
@@ -991,3 +1112,22 @@ func TestCompleteBranches(t *testing.T) {
 		})
 	})
 }
+
+func TestAddSparsePaths(t *testing.T) {
+	cfg := &config.Config{Defaults: config.Defaults{Sparse: []string{"libs"}}}
+
+	runAddFlags(t, []string{"feature/x"}, func(cmd *cli.Command) {
+		assert.Equal(t, []string{"libs"}, addSparsePaths(cmd, cfg))
+	})
+	runAddFlags(t, []string{"--sparse", "apps/web,docs", "feature/x"}, func(cmd *cli.Command) {
+		assert.Equal(t, []string{"apps/web", "docs"}, addSparsePaths(cmd, cfg))
+
+		cmds := buildWorktreeCommands(cmd, cfg, vcs.Git{}, "/wt", "feature/x", "")
+		require.Len(t, cmds, 3)
+		assert.Equal(t, []string{"worktree", "add", "--no-checkout", "/wt", "feature/x"}, cmds[0].Args)
+		assert.Equal(t, []string{"sparse-checkout", "set", "--", "apps/web", "docs"}, cmds[1].Args)
+	})
+	runAddFlags(t, []string{"--sparse", "../outside", "feature/x"}, func(cmd *cli.Command) {
+		assert.ErrorContains(t, validateAddInput(cmd), "invalid --sparse")
+	})
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+// addTracking reports whether a branch 'wtp add' creates from a remote branch gets it as
+// its upstream: --track or --no-track when given, else defaults.auto_track.
+func addTracking(cmd *cli.Command, cfg *config.Config) bool {
+	if cmd.IsSet("track") {
+		return cmd.Bool("track")
+	}
+	return cfg.AutoTrack()
+}
+
+// addLocalBranch returns the branch the new worktree has checked out. It is
+// addTargetBranch, except that a remote-tracking branch such as origin/feature/x becomes
+// feature/x, the local branch created from it, unless a local branch has its full name.
+func addLocalBranch(cmd *cli.Command, repoPath string) string {
+	target := addTargetBranch(cmd)
+	if cmd.String("branch") != "" || cmd.IsSet("pr") || target == "" {
+		return target
+	}
+	repo, err := git.NewRepository(repoPath)
+	if err != nil {
+		return target
+	}
+	if exists, err := repo.BranchExists(target); err != nil || exists {
+		return target
+	}
+	if _, branch, ok := repo.SplitRemoteBranch(target); ok {
+		return branch
+	}
+	return target
+}
+
+// applyAddTracking sets up the upstream of the branch 'wtp add -b' creates from commitish.
+// Without --track, git gives it one when commitish is a remote branch, unless
+// defaults.auto_track is false.
+func applyAddTracking(cmd *cli.Command, cfg *config.Config, opts *command.GitWorktreeAddOptions, commitish string) {
+	switch {
+	case !addTracking(cmd, cfg):
+		opts.NoTrack = true
+	case cmd.Bool("track") && commitish != "":
+		opts.Track = commitish
+	}
+}
+
+// addUpstream returns the upstream the branch 'wtp add' creates is set up with, or "" when
+// wtp does not set one.
+func addUpstream(cmd *cli.Command, cfg *config.Config, resolvedTrack string) string {
+	if !addTracking(cmd, cfg) {
+		return ""
+	}
+	if resolvedTrack != "" {
+		return resolvedTrack
+	}
+	if cmd.String("branch") != "" && cmd.Bool("track") && cmd.Args().Len() > 0 {
+		return addBaseRef(cmd, resolvedTrack)
+	}
+	return ""
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/vcs"
+)
+
+// runAddFlags parses args with the flags of 'wtp add' and passes the command to check.
+func runAddFlags(t *testing.T, args []string, check func(cmd *cli.Command)) {
+	t.Helper()
+	cmd := NewAddCommand()
+	cmd.Action = func(_ context.Context, cmd *cli.Command) error {
+		check(cmd)
+		return nil
+	}
+	require.NoError(t, cmd.Run(context.Background(), append([]string{"add"}, args...)))
+}
+
+func TestBuildWorktreeCommands_Tracking(t *testing.T) {
+	off := false
+	tests := []struct {
+		name          string
+		args          []string
+		autoTrack     *bool
+		resolvedTrack string
+		want          []string
+	}{
+		{
+			name:          "remote branch is tracked",
+			args:          []string{"feature/x"},
+			resolvedTrack: "origin/feature/x",
+			want:          []string{"worktree", "add", "-b", "feature/x", "--track", "/wt", "origin/feature/x"},
+		},
+		{
+			name:          "remote-tracking branch argument",
+			args:          []string{"origin/feature/x"},
+			resolvedTrack: "origin/feature/x",
+			want:          []string{"worktree", "add", "-b", "feature/x", "--track", "/wt", "origin/feature/x"},
+		},
+		{
+			name:          "--no-track",
+			args:          []string{"--no-track", "feature/x"},
+			resolvedTrack: "origin/feature/x",
+			want:          []string{"worktree", "add", "-b", "feature/x", "--no-track", "/wt", "origin/feature/x"},
+		},
+		{
+			name:          "auto_track off",
+			args:          []string{"feature/x"},
+			autoTrack:     &off,
+			resolvedTrack: "origin/feature/x",
+			want:          []string{"worktree", "add", "-b", "feature/x", "--no-track", "/wt", "origin/feature/x"},
+		},
+		{
+			name:          "--track overrides auto_track",
+			args:          []string{"--track", "feature/x"},
+			autoTrack:     &off,
+			resolvedTrack: "origin/feature/x",
+			want:          []string{"worktree", "add", "-b", "feature/x", "--track", "/wt", "origin/feature/x"},
+		},
+		{
+			name: "new branch left to git",
+			args: []string{"-b", "feature/x", "origin/main"},
+			want: []string{"worktree", "add", "-b", "feature/x", "/wt", "origin/main"},
+		},
+		{
+			name: "new branch with --track",
+			args: []string{"--track", "-b", "feature/x", "origin/main"},
+			want: []string{"worktree", "add", "-b", "feature/x", "--track", "/wt", "origin/main"},
+		},
+		{
+			name:      "new branch with auto_track off",
+			args:      []string{"-b", "feature/x", "origin/main"},
+			autoTrack: &off,
+			want:      []string{"worktree", "add", "-b", "feature/x", "--no-track", "/wt", "origin/main"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Defaults: config.Defaults{AutoTrack: tt.autoTrack}}
+			runAddFlags(t, tt.args, func(cmd *cli.Command) {
+				cmds := buildWorktreeCommands(cmd, cfg, vcs.Git{}, "/wt", "feature/x", tt.resolvedTrack)
+				require.Len(t, cmds, 1)
+				assert.Equal(t, tt.want, cmds[0].Args)
+			})
+		})
+	}
+}
+
+func TestAddUpstream(t *testing.T) {
+	cfg := &config.Config{}
+	runAddFlags(t, []string{"feature/x"}, func(cmd *cli.Command) {
+		assert.Equal(t, "origin/feature/x", addUpstream(cmd, cfg, "origin/feature/x"))
+		assert.Empty(t, addUpstream(cmd, cfg, ""))
+	})
+	runAddFlags(t, []string{"--no-track", "feature/x"}, func(cmd *cli.Command) {
+		assert.Empty(t, addUpstream(cmd, cfg, "origin/feature/x"))
+	})
+	runAddFlags(t, []string{"--track", "-b", "feature/x", "origin/main"}, func(cmd *cli.Command) {
+		assert.Equal(t, "origin/main", addUpstream(cmd, cfg, ""))
+	})
+}
+
+func TestAddLocalBranch(t *testing.T) {
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("init", "-q")
+	git("-c", "user.name=wtp", "-c", "user.email=wtp@example.com", "commit", "-q", "--allow-empty", "-m", "init")
+	git("remote", "add", "origin", "https://example.com/app.git")
+	git("update-ref", "refs/remotes/origin/feature/x", "HEAD")
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"origin/feature/x"}, want: "feature/x"},
+		{args: []string{"feature/x"}, want: "feature/x"},
+		{args: []string{"origin/missing"}, want: "origin/missing"},
+		{args: []string{"-b", "origin/feature/x"}, want: "origin/feature/x"},
+	}
+	for _, tt := range tests {
+		runAddFlags(t, tt.args, func(cmd *cli.Command) {
+			assert.Equal(t, tt.want, addLocalBranch(cmd, repo), tt.args)
+		})
+	}
+
+	git("branch", "origin/feature/x")
+	runAddFlags(t, []string{"origin/feature/x"}, func(cmd *cli.Command) {
+		assert.Equal(t, "origin/feature/x", addLocalBranch(cmd, repo), "a local branch of that name wins")
+	})
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+)
+
+// cdFileEnv names the file the shell hook from 'wtp hook' reads, after 'wtp add' and
+// 'wtp switch', for the directory to change to.
+const cdFileEnv = "WTP_CD_FILE"
+
+const cdFilePermissions = 0o600
+
+// Variables to allow mocking in tests
+var (
+	copyToClipboard = writeClipboard
+	openInEditor    = runEditor
+)
+
+// runAfterAdd performs defaults.after_add for the worktree 'wtp add' just created. A failed
+// action is only a warning: the worktree is ready either way.
+func runAfterAdd(w io.Writer, cfg *config.Config, workTreePath string) error {
+	action := cfg.Defaults.AfterAdd
+	var err error
+	switch action {
+	case config.AfterAddPrintPath:
+		_, err = fmt.Fprintln(w, workTreePath)
+		return err
+	case config.AfterAddCopyPath:
+		if err = copyToClipboard(workTreePath); err == nil {
+			_, err = fmt.Fprintln(w, "📋 Path copied to the clipboard")
+			return err
+		}
+	case config.AfterAddCd:
+		err = writeCdFile(workTreePath)
+	case config.AfterAddOpenEditor:
+		err = openInEditor(cfg.Defaults.Editor, workTreePath)
+	default:
+		return nil
+	}
+	if err != nil {
+		return writeWarning(w, errors.CodeWarnAfterAddFailed, "after_add %s: %v", action, err)
+	}
+	return nil
+}
+
+// writeCdFile hands path to the shell hook, which changes to it once wtp exits; a
+// process cannot change its parent shell's directory itself.
+func writeCdFile(path string) error {
+	cdFile := os.Getenv(cdFileEnv)
+	if cdFile == "" {
+		return fmt.Errorf("the shell hook is not loaded; add eval \"$(wtp hook <shell>)\" to your shell config")
+	}
+	return os.WriteFile(cdFile, []byte(path), cdFilePermissions)
+}
+
+// runEditor opens path in editor, a command such as "code" or "vim -O", or without it in
+// $VISUAL, else $EDITOR, and waits for the editor to exit.
+func runEditor(editor, path string) error {
+	if editor == "" {
+		editor = os.Getenv("VISUAL")
+	}
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	fields := strings.Fields(editor)
+	if len(fields) == 0 {
+		return fmt.Errorf("no editor: defaults.editor is not set, and neither VISUAL nor EDITOR is set")
+	}
+
+	// #nosec G204 -- the editor is chosen by the user
+	cmd := exec.Command(fields[0], append(fields[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %w", fields[0], err)
+	}
+	return nil
+}
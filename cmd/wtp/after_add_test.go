@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func afterAddConfig(action string) *config.Config {
+	return &config.Config{Defaults: config.Defaults{AfterAdd: action}}
+}
+
+func TestRunAfterAdd_PrintPath(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, runAfterAdd(&buf, afterAddConfig(config.AfterAddPrintPath), "/repo/.git/wtp/feature"))
+	assert.Equal(t, "/repo/.git/wtp/feature\n", buf.String())
+}
+
+func TestRunAfterAdd_Unset(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, runAfterAdd(&buf, afterAddConfig(""), "/repo/.git/wtp/feature"))
+	assert.Empty(t, buf.String())
+}
+
+func TestRunAfterAdd_CopyPath(t *testing.T) {
+	original := copyToClipboard
+	t.Cleanup(func() { copyToClipboard = original })
+
+	t.Run("copies the path", func(t *testing.T) {
+		var copied string
+		copyToClipboard = func(text string) error {
+			copied = text
+			return nil
+		}
+
+		var buf bytes.Buffer
+		require.NoError(t, runAfterAdd(&buf, afterAddConfig(config.AfterAddCopyPath), "/wt"))
+		assert.Equal(t, "/wt", copied)
+		assert.Contains(t, buf.String(), "Path copied to the clipboard")
+	})
+
+	t.Run("warns without a clipboard tool", func(t *testing.T) {
+		copyToClipboard = func(string) error { return fmt.Errorf("no clipboard tool found") }
+
+		var buf bytes.Buffer
+		require.NoError(t, runAfterAdd(&buf, afterAddConfig(config.AfterAddCopyPath), "/wt"))
+		assert.Contains(t, buf.String(), "Warning [WTP7010]: after_add copy-path: no clipboard tool found")
+	})
+}
+
+func TestRunAfterAdd_Cd(t *testing.T) {
+	t.Run("hands the path to the shell hook", func(t *testing.T) {
+		cdFile := filepath.Join(t.TempDir(), "cd")
+		t.Setenv(cdFileEnv, cdFile)
+
+		var buf bytes.Buffer
+		require.NoError(t, runAfterAdd(&buf, afterAddConfig(config.AfterAddCd), "/wt"))
+
+		content, err := os.ReadFile(cdFile)
+		require.NoError(t, err)
+		assert.Equal(t, "/wt", string(content))
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("warns without the shell hook", func(t *testing.T) {
+		t.Setenv(cdFileEnv, "")
+
+		var buf bytes.Buffer
+		require.NoError(t, runAfterAdd(&buf, afterAddConfig(config.AfterAddCd), "/wt"))
+		assert.Contains(t, buf.String(), "Warning [WTP7010]: after_add cd: the shell hook is not loaded")
+	})
+}
+
+func TestRunAfterAdd_OpenEditor(t *testing.T) {
+	original := openInEditor
+	t.Cleanup(func() { openInEditor = original })
+
+	var editor, opened string
+	openInEditor = func(e, path string) error {
+		editor, opened = e, path
+		return nil
+	}
+
+	cfg := afterAddConfig(config.AfterAddOpenEditor)
+	cfg.Defaults.Editor = "code"
+	var buf bytes.Buffer
+	require.NoError(t, runAfterAdd(&buf, cfg, "/wt"))
+	assert.Equal(t, "/wt", opened)
+	assert.Equal(t, "code", editor, "defaults.editor is used")
+}
+
+func TestRunEditor_NoEditor(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+
+	err := runEditor("", "/wt")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "neither VISUAL nor EDITOR is set")
+}
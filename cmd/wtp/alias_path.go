@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+)
+
+// NewAliasPathCommand creates the alias-path command definition
+func NewAliasPathCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "alias-path",
+		Usage:     "Show worktree IDs, or the path of one worktree",
+		UsageText: "wtp alias-path [<worktree>] [--id]",
+		Description: "Every worktree has a short ID such as wt-3f2a that stays the same when its branch " +
+			"is renamed or the worktree is moved with 'git worktree move'. IDs are accepted wherever a " +
+			"worktree name is, and 'wtp list' shows them.\n\n" +
+			"Without arguments, lists the ID, name, and path of every worktree. With a worktree name " +
+			"or ID, prints its path, or its ID with --id.\n\n" +
+			"Examples:\n" +
+			"  wtp alias-path                     # List every worktree's ID\n" +
+			"  wtp alias-path wt-3f2a             # Path of a worktree by ID\n" +
+			"  wtp alias-path feature/auth --id   # ID of a worktree",
+		ArgsUsage:     "[<worktree>]",
+		ShellComplete: completeWorktreesForCd,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "id",
+				Usage: "Print the worktree's ID instead of its path",
+			},
+		},
+		Action: aliasPathCommand,
+	}
+}
+
+func aliasPathCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	_, cfg, _, err := setupRepoAndConfig()
+	if err != nil {
+		return err
+	}
+
+	executor := command.NewRealExecutor()
+	return aliasPathCommandWithCommandExecutor(w, executor, cfg, cmd.Args().First(), cmd.Bool("id"))
+}
+
+func aliasPathCommandWithCommandExecutor(
+	w io.Writer, executor command.Executor, cfg *config.Config, worktreeName string, printID bool,
+) error {
+	result, err := executor.Execute([]command.Command{command.GitWorktreeList()})
+	if err != nil {
+		return errors.GitCommandFailed("git worktree list", err.Error())
+	}
+	worktrees := parseWorktreesFromOutput(result.Results[0].Output)
+	mainWorktreePath := findMainWorktreePath(worktrees)
+	ids := worktreeIDs(worktrees)
+
+	if worktreeName == "" {
+		if printID {
+			return errors.InvalidArguments("--id requires a worktree name")
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:mnd // column padding
+		for i := range worktrees {
+			name := getWorktreeNameFromPath(worktrees[i].Path, cfg, mainWorktreePath, worktrees[i].IsMain)
+			if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\n", ids[i], name, worktrees[i].Path); err != nil {
+				return err
+			}
+		}
+		return tw.Flush()
+	}
+
+	target, err := resolveWorktreeName(worktreeName, worktrees, cfg, mainWorktreePath)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		return errors.WorktreeNotFound(worktreeName, managedWorktreeNames(worktrees, cfg, mainWorktreePath))
+	}
+	if !printID {
+		_, err = fmt.Fprintln(w, target.Path)
+		return err
+	}
+	for i := range worktrees {
+		if &worktrees[i] == target {
+			_, err = fmt.Fprintln(w, ids[i])
+		}
+	}
+	return err
+}
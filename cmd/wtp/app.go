@@ -1,6 +1,18 @@
 package main
 
-import "github.com/urfave/cli/v3"
+import (
+	"context"
+	"os"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/events"
+)
+
+// eventsFileMode is the mode of a --json-events-file that wtp creates.
+const eventsFileMode = 0o600
 
 func newApp() *cli.Command {
 	return &cli.Command{
@@ -16,16 +28,105 @@ func newApp() *cli.Command {
 				Name:  "version",
 				Usage: "Show version information",
 			},
+			&cli.BoolFlag{
+				Name:    "strict",
+				Usage:   "Fail on configuration keys that name no setting, like defaults.strict",
+				Sources: cli.EnvVars(config.StrictEnvVar),
+				Action:  enableStrictMode,
+			},
+			&cli.BoolFlag{
+				Name:    "no-lock",
+				Usage:   "Do not wait for other wtp commands creating or removing worktrees in the repository",
+				Sources: cli.EnvVars(noLockEnvVar),
+				Action:  enableNoLock,
+			},
+			&cli.DurationFlag{
+				Name:        "lock-timeout",
+				Usage:       "How long to wait for other wtp commands in the repository, e.g. 2m",
+				DefaultText: "defaults.lock_timeout, 10m",
+			},
+			&cli.BoolFlag{
+				Name:   "json-events",
+				Usage:  "Stream progress as newline-delimited JSON events on stderr",
+				Action: enableJSONEvents,
+			},
+			&cli.StringFlag{
+				Name:   "json-events-file",
+				Usage:  "Stream the --json-events events to `PATH`, e.g. /dev/fd/3, apart from stderr",
+				Action: enableJSONEventsFile,
+			},
 		},
 		Commands: []*cli.Command{
 			NewAddCommand(),
 			NewListCommand(),
+			NewInfoCommand(),
+			NewAliasPathCommand(),
+			NewGraphCommand(),
+			NewMapCommand(),
+			NewVerifyCommand(),
 			NewRemoveCommand(),
+			NewPruneCommand(),
+			NewLockCommand(),
+			NewUnlockCommand(),
 			NewInitCommand(),
+			NewConfigCommand(),
 			NewCdCommand(),
+			NewSwitchCommand(),
+			NewExecCommand(),
+			NewGrepCommand(),
+			NewCheckoutCommand(),
+			NewMoveCommand(),
+			NewRenameCommand(),
+			NewOpenCommand(),
+			NewTmuxCommand(),
+			NewCodeCommand(),
+			NewMaintainCommand(),
+			NewCleanCommand(),
+			NewCacheCommand(),
+			NewRelinkCommand(),
+			NewMigrateLayoutCommand(),
+			NewHibernateCommand(),
+			NewWakeCommand(),
+			NewBenchCommand(),
+			NewHooksCommand(),
+			NewExplainCommand(),
+			NewDoctorCommand(),
+			NewInternalCommand(),
 			// Built-in completion is automatically provided by urfave/cli
 			NewHookCommand(),
 			NewShellInitCommand(),
 		},
 	}
 }
+
+// enableStrictMode exports --strict as WTP_STRICT, which config.LoadConfig reads, so it
+// also applies to wtp commands that hooks run.
+func enableStrictMode(_ context.Context, _ *cli.Command, strict bool) error {
+	if !strict {
+		return nil
+	}
+	return os.Setenv(config.StrictEnvVar, "true")
+}
+
+// enableJSONEvents streams events on stderr, leaving stdout to the command's own output,
+// such as the paths 'wtp cd' prints. An error is then reported only as an error event
+// (see reportError). --json-events-file takes precedence.
+func enableJSONEvents(_ context.Context, _ *cli.Command, enabled bool) error {
+	if enabled && !events.Enabled() {
+		events.Enable(os.Stderr)
+	}
+	return nil
+}
+
+// enableJSONEventsFile streams events to the file at path, such as a descriptor the
+// caller reads from, so they stay apart from the messages and hook output on stderr. The
+// file is appended to and left open until wtp exits.
+func enableJSONEventsFile(_ context.Context, _ *cli.Command, path string) error {
+	// #nosec G304 -- path is given on the command line
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, eventsFileMode)
+	if err != nil {
+		return errors.InvalidArguments("cannot open --json-events-file %s: %v", path, err)
+	}
+	events.Enable(file)
+	return nil
+}
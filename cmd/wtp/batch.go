@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/errors"
+)
+
+// Outcomes of a command in one of the worktrees it runs across
+const (
+	batchSucceeded = "ok"
+	batchFailed    = "failed"
+	batchSkipped   = "skipped"
+)
+
+// failFastReason is why worktrees not reached after a failure with --fail-fast are skipped.
+const failFastReason = "--fail-fast"
+
+var batchSymbols = map[string]string{
+	batchSucceeded: "✓",
+	batchFailed:    "✗",
+	batchSkipped:   "-",
+}
+
+// newFailFastFlag returns the --fail-fast flag of a command that runs across worktrees.
+func newFailFastFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:  "fail-fast",
+		Usage: "Stop at the first worktree that fails instead of continuing with the others",
+	}
+}
+
+// batchResult is what a command did in one worktree.
+type batchResult struct {
+	name    string
+	outcome string
+	// detail is the error of a failed worktree or why one was skipped.
+	detail string
+}
+
+// batchReport collects the results of a command that runs across worktrees, such as
+// 'wtp exec --all', 'wtp prune', or 'wtp maintain', in the order they were reached.
+type batchReport struct {
+	results []batchResult
+}
+
+func (r *batchReport) succeed(name string) {
+	r.results = append(r.results, batchResult{name: name, outcome: batchSucceeded})
+}
+
+func (r *batchReport) fail(name string, err error) {
+	r.results = append(r.results, batchResult{name: name, outcome: batchFailed, detail: firstErrorLine(err)})
+}
+
+func (r *batchReport) skip(name, reason string) {
+	r.results = append(r.results, batchResult{name: name, outcome: batchSkipped, detail: reason})
+}
+
+// names returns the worktrees with outcome.
+func (r *batchReport) names(outcome string) []string {
+	var names []string
+	for _, result := range r.results {
+		if result.outcome == outcome {
+			names = append(names, result.name)
+		}
+	}
+	return names
+}
+
+// complete reports whether the command succeeded in every worktree.
+func (r *batchReport) complete() bool {
+	return len(r.names(batchSucceeded)) == len(r.results)
+}
+
+// write prints a row per worktree with its outcome, followed by the totals.
+func (r *batchReport) write(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "\nResults:"); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:mnd // column padding
+	for _, result := range r.results {
+		row := fmt.Sprintf("  %s\t%s %s", result.name, batchSymbols[result.outcome], result.outcome)
+		if result.detail != "" {
+			row += ": " + result.detail
+		}
+		if _, err := fmt.Fprintln(tw, row); err != nil {
+			return err
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%d succeeded, %d failed, %d skipped\n",
+		len(r.names(batchSucceeded)), len(r.names(batchFailed)), len(r.names(batchSkipped)))
+	return err
+}
+
+// err returns nil when no worktree failed, and otherwise the error failure builds from the
+// failed worktrees, marked as a partial failure when the command succeeded in others.
+func (r *batchReport) err(failure func(failed []string) error) error {
+	failed := r.names(batchFailed)
+	if len(failed) == 0 {
+		return nil
+	}
+	err := failure(failed)
+	if len(r.names(batchSucceeded)) > 0 {
+		return errors.PartialFailure(err)
+	}
+	return err
+}
+
+// firstErrorLine returns the first line of err's message; wtp errors go on with solutions
+// that do not fit in a table.
+func firstErrorLine(err error) string {
+	line, _, _ := strings.Cut(err.Error(), "\n")
+	return line
+}
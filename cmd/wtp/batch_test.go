@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/errors"
+)
+
+func TestBatchReport_Write(t *testing.T) {
+	report := &batchReport{}
+	report.succeed("@")
+	report.fail("feature/long-name", errors.ExecFailed([]string{"feature/long-name"}))
+	report.skip("bar", failFastReason)
+
+	var buf bytes.Buffer
+	require.NoError(t, report.write(&buf))
+	assert.Equal(t, "\nResults:\n"+
+		"  @                  ✓ ok\n"+
+		"  feature/long-name  ✗ failed: command failed in 1 worktree(s): feature/long-name\n"+
+		"  bar                - skipped: --fail-fast\n"+
+		"1 succeeded, 1 failed, 1 skipped\n", buf.String())
+	assert.False(t, report.complete())
+}
+
+func TestBatchReport_Err(t *testing.T) {
+	failure := func(failed []string) error { return fmt.Errorf("failed in %v", failed) }
+
+	report := &batchReport{}
+	report.succeed("@")
+	assert.True(t, report.complete())
+	assert.NoError(t, report.err(failure))
+
+	report.fail("foo", fmt.Errorf("exit status 1"))
+	err := report.err(failure)
+	assert.EqualError(t, err, "failed in [foo]")
+	assert.True(t, errors.IsPartialFailure(err))
+
+	report = &batchReport{}
+	report.fail("foo", fmt.Errorf("exit status 1"))
+	report.skip("bar", failFastReason)
+	err = report.err(failure)
+	assert.EqualError(t, err, "failed in [foo]")
+	assert.False(t, errors.IsPartialFailure(err))
+}
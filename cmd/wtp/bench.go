@@ -0,0 +1,432 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/git"
+	"github.com/satococoa/wtp/v2/internal/hooks"
+)
+
+const (
+	defaultBenchIterations = 3
+	stateDirName           = "wtp"
+	benchBaselineFileName  = "bench-baseline.json"
+	benchPhaseGitAdd       = "git worktree add"
+	benchPhaseCleanup      = "cleanup"
+	benchPhaseTotal        = "total"
+	stateDirMode           = 0o755
+	benchBaselineFileMode  = 0o600
+	percentMultiplier      = 100
+)
+
+// Variable to allow mocking in tests
+var benchMkdirTemp = os.MkdirTemp
+
+// NewBenchCommand creates the bench command definition
+func NewBenchCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "bench",
+		Usage:     "Benchmark worktree provisioning",
+		UsageText: "wtp bench [--iterations <n>] [--hooks <list> | --no-hooks] [--save-baseline]",
+		Description: "Creates a throwaway detached worktree several times, runs the configured " +
+			"post-create hooks against it, and reports how long each phase took. " +
+			"Results can be saved as a baseline and are compared against it on later runs.\n\n" +
+			"Examples:\n" +
+			"  wtp bench                               # 3 iterations with all hooks\n" +
+			"  wtp bench -n 10 --hooks 1,3             # Only run hooks #1 and #3\n" +
+			"  wtp bench --no-hooks                    # Measure git overhead only\n" +
+			"  wtp bench --save-baseline               # Store results for later comparison",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:    "iterations",
+				Aliases: []string{"n"},
+				Usage:   "Number of throwaway worktrees to create",
+				Value:   defaultBenchIterations,
+			},
+			&cli.StringFlag{
+				Name:  "hooks",
+				Usage: "Comma-separated post_create hook numbers to run (default: all)",
+			},
+			&cli.BoolFlag{
+				Name:  "no-hooks",
+				Usage: "Skip post_create hooks entirely",
+			},
+			&cli.BoolFlag{
+				Name:  "save-baseline",
+				Usage: "Store this run as the baseline for future comparisons",
+			},
+			&cli.BoolFlag{
+				Name:    "verbose",
+				Aliases: []string{"v"},
+				Usage:   "Stream hook output while benchmarking",
+			},
+		},
+		Action: benchCommand,
+	}
+}
+
+type benchOptions struct {
+	Iterations  int
+	HookNumbers []int // 1-based post_create indexes; nil selects every hook
+	NoHooks     bool
+}
+
+// benchPhase aggregates the samples recorded for one provisioning phase.
+type benchPhase struct {
+	Name    string        `json:"name"`
+	Hook    int           `json:"hook,omitempty"` // 1-based post_create number for hook phases
+	Mean    time.Duration `json:"mean_ns"`
+	Min     time.Duration `json:"min_ns"`
+	Max     time.Duration `json:"max_ns"`
+	samples []time.Duration
+}
+
+// benchReport is the result of a benchmark run and the on-disk baseline format.
+type benchReport struct {
+	Iterations int          `json:"iterations"`
+	RecordedAt time.Time    `json:"recorded_at"`
+	Phases     []benchPhase `json:"phases"`
+}
+
+func benchCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	repo, cfg, mainRepoPath, err := setupRepoAndConfig()
+	if err != nil {
+		return err
+	}
+	if err := ensureWritable(cfg, "run benchmarks, which create worktrees"); err != nil {
+		return err
+	}
+
+	opts, err := resolveBenchOptions(cmd, cfg)
+	if err != nil {
+		return err
+	}
+
+	baselinePath, err := benchBaselinePath(repo)
+	if err != nil {
+		return err
+	}
+
+	hookOutput := io.Discard
+	if cmd.Bool("verbose") {
+		hookOutput = w
+	}
+
+	report, err := runBench(hookOutput, command.NewRealExecutor(), cfg, mainRepoPath, opts)
+	if err != nil {
+		return err
+	}
+
+	baseline, err := loadBenchBaseline(baselinePath)
+	if err != nil {
+		return err
+	}
+
+	if err := writeBenchReport(w, report, baseline); err != nil {
+		return err
+	}
+
+	if cmd.Bool("save-baseline") {
+		if err := saveBenchBaseline(baselinePath, report); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "\nBaseline saved: %s\n", baselinePath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// benchBaselinePath returns where the benchmark baseline of the repository is stored.
+func benchBaselinePath(repo *git.Repository) (string, error) {
+	commonDir, err := repo.GetGitCommonDir()
+	if err != nil {
+		return "", errors.GitCommandFailed("git rev-parse --git-common-dir", err.Error())
+	}
+	return filepath.Join(commonDir, stateDirName, benchBaselineFileName), nil
+}
+
+func resolveBenchOptions(cmd *cli.Command, cfg *config.Config) (benchOptions, error) {
+	opts := benchOptions{
+		Iterations: cmd.Int("iterations"),
+		NoHooks:    cmd.Bool("no-hooks"),
+	}
+
+	if opts.Iterations <= 0 {
+		return opts, errors.InvalidArguments("--iterations must be a positive number, got %d", opts.Iterations)
+	}
+
+	selection := cmd.String("hooks")
+	if selection == "" {
+		return opts, nil
+	}
+	if opts.NoHooks {
+		return opts, errors.InvalidArguments("--hooks cannot be combined with --no-hooks")
+	}
+
+	numbers, err := parseHookNumbers("--hooks", selection, len(cfg.Hooks.PostCreate))
+	if err != nil {
+		return opts, err
+	}
+	opts.HookNumbers = numbers
+	return opts, nil
+}
+
+// parseHookNumbers parses a "1,3" style selection given with flag into validated 1-based
+// hook numbers.
+func parseHookNumbers(flag, selection string, hookCount int) ([]int, error) {
+	var numbers []int
+	seen := make(map[int]struct{})
+	for _, part := range strings.Split(selection, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, errors.InvalidArguments("invalid hook number '%s' in %s", part, flag)
+		}
+		if n < 1 || n > hookCount {
+			return nil, errors.InvalidArguments(
+				"hook number %d out of range (configuration has %d post_create hooks)", n, hookCount)
+		}
+		if _, dup := seen[n]; dup {
+			continue
+		}
+		seen[n] = struct{}{}
+		numbers = append(numbers, n)
+	}
+	if len(numbers) == 0 {
+		return nil, errors.InvalidArguments("%s requires at least one hook number", flag)
+	}
+	return numbers, nil
+}
+
+// selectBenchHooks returns a config containing only the hooks chosen for the run,
+// along with the original 1-based number of each selected hook.
+func selectBenchHooks(cfg *config.Config, opts benchOptions) (selected *config.Config, numbers []int) {
+	copied := *cfg
+	copied.Hooks.PostCreate = nil
+	if opts.NoHooks {
+		return &copied, nil
+	}
+
+	numbers = opts.HookNumbers
+	if numbers == nil {
+		for i := range cfg.Hooks.PostCreate {
+			numbers = append(numbers, i+1)
+		}
+	}
+
+	for _, n := range numbers {
+		copied.Hooks.PostCreate = append(copied.Hooks.PostCreate, cfg.Hooks.PostCreate[n-1])
+	}
+	return &copied, numbers
+}
+
+// runBench provisions opts.Iterations throwaway worktrees and records each phase duration.
+func runBench(
+	hookOutput io.Writer, executor command.Executor, cfg *config.Config, mainRepoPath string, opts benchOptions,
+) (*benchReport, error) {
+	benchCfg, hookNumbers := selectBenchHooks(cfg, opts)
+	hookExecutor := hooks.NewExecutor(benchCfg, mainRepoPath)
+
+	var phases []benchPhase
+	record := func(name string, hookNumber int, d time.Duration) {
+		for i := range phases {
+			if phases[i].Name == name {
+				phases[i].samples = append(phases[i].samples, d)
+				return
+			}
+		}
+		phases = append(phases, benchPhase{Name: name, Hook: hookNumber, samples: []time.Duration{d}})
+	}
+
+	for range opts.Iterations {
+		tmpDir, err := benchMkdirTemp("", "wtp-bench-")
+		if err != nil {
+			return nil, errors.DirectoryAccessFailed("create temporary", os.TempDir(), err)
+		}
+		worktreePath := filepath.Join(tmpDir, "worktree")
+
+		iterationStart := time.Now()
+		if err := benchExecute(executor, command.GitWorktreeAdd(worktreePath, "HEAD",
+			command.GitWorktreeAddOptions{Detach: true})); err != nil {
+			_ = os.RemoveAll(tmpDir)
+			return nil, errors.WorktreeCreationFailed(worktreePath, "HEAD", err)
+		}
+		record(benchPhaseGitAdd, 0, time.Since(iterationStart))
+
+		timings, hookErr := hookExecutor.ExecutePostCreateHooksTimed(hookOutput, worktreePath)
+		for _, timing := range timings {
+			number := hookNumbers[timing.Index-1]
+			record(fmt.Sprintf("hook %d (%s)", number, timing.Type), number, timing.Duration)
+		}
+
+		cleanupStart := time.Now()
+		removeErr := benchExecute(executor, command.GitWorktreeRemove(worktreePath, true))
+		_ = os.RemoveAll(tmpDir)
+		record(benchPhaseCleanup, 0, time.Since(cleanupStart))
+		record(benchPhaseTotal, 0, time.Since(iterationStart))
+
+		if hookErr != nil {
+			return nil, fmt.Errorf("benchmark aborted: %w", hookErr)
+		}
+		if removeErr != nil {
+			return nil, errors.WorktreeRemovalFailed(worktreePath, removeErr)
+		}
+	}
+
+	for i := range phases {
+		phases[i].summarize()
+	}
+
+	return &benchReport{
+		Iterations: opts.Iterations,
+		RecordedAt: time.Now().UTC(),
+		Phases:     phases,
+	}, nil
+}
+
+func benchExecute(executor command.Executor, cmd command.Command) error {
+	result, err := executor.Execute([]command.Command{cmd})
+	if err != nil {
+		return err
+	}
+	if len(result.Results) > 0 && result.Results[0].Error != nil {
+		if output := result.Results[0].Output; output != "" {
+			return fmt.Errorf("%w: %s", result.Results[0].Error, output)
+		}
+		return result.Results[0].Error
+	}
+	return nil
+}
+
+func (p *benchPhase) summarize() {
+	if len(p.samples) == 0 {
+		return
+	}
+
+	var sum time.Duration
+	p.Min, p.Max = p.samples[0], p.samples[0]
+	for _, s := range p.samples {
+		sum += s
+		p.Min = min(p.Min, s)
+		p.Max = max(p.Max, s)
+	}
+	p.Mean = sum / time.Duration(len(p.samples))
+}
+
+func loadBenchBaseline(path string) (*benchReport, error) {
+	// #nosec G304 -- path is derived from the repository's git directory
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read benchmark baseline: %w", err)
+	}
+
+	var baseline benchReport
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to parse benchmark baseline %s: %w", path, err)
+	}
+	return &baseline, nil
+}
+
+func saveBenchBaseline(path string, report *benchReport) error {
+	if err := os.MkdirAll(filepath.Dir(path), stateDirMode); err != nil {
+		return errors.DirectoryAccessFailed("create", filepath.Dir(path), err)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode benchmark baseline: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), benchBaselineFileMode); err != nil {
+		return fmt.Errorf("failed to write benchmark baseline: %w", err)
+	}
+	return nil
+}
+
+func writeBenchReport(w io.Writer, report, baseline *benchReport) error {
+	if _, err := fmt.Fprintf(w, "Benchmarked %d iteration(s)\n\n", report.Iterations); err != nil {
+		return err
+	}
+
+	baselineMeans := make(map[string]time.Duration)
+	if baseline != nil {
+		for _, phase := range baseline.Phases {
+			baselineMeans[phase.Name] = phase.Mean
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:mnd // column padding
+	header := "PHASE\tMEAN\tMIN\tMAX"
+	if baseline != nil {
+		header += "\tBASELINE\tDELTA"
+	}
+	if _, err := fmt.Fprintln(tw, header); err != nil {
+		return err
+	}
+
+	for _, phase := range report.Phases {
+		row := fmt.Sprintf("%s\t%s\t%s\t%s",
+			phase.Name, formatBenchDuration(phase.Mean), formatBenchDuration(phase.Min), formatBenchDuration(phase.Max))
+		if baseline != nil {
+			previous, ok := baselineMeans[phase.Name]
+			if ok {
+				row += fmt.Sprintf("\t%s\t%s", formatBenchDuration(previous), formatBenchDelta(phase.Mean, previous))
+			} else {
+				row += "\t-\t-"
+			}
+		}
+		if _, err := fmt.Fprintln(tw, row); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if baseline != nil {
+		_, err := fmt.Fprintf(w, "\nCompared against baseline recorded %s (%d iteration(s))\n",
+			baseline.RecordedAt.Local().Format(time.RFC3339), baseline.Iterations)
+		return err
+	}
+	return nil
+}
+
+func formatBenchDuration(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+}
+
+func formatBenchDelta(current, previous time.Duration) string {
+	if previous <= 0 {
+		return "-"
+	}
+	delta := float64(current-previous) / float64(previous) * percentMultiplier
+	return fmt.Sprintf("%+.1f%%", delta)
+}
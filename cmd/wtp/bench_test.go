@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func TestNewBenchCommand(t *testing.T) {
+	cmd := NewBenchCommand()
+
+	assert.Equal(t, "bench", cmd.Name)
+	assert.NotNil(t, cmd.Action)
+
+	flagNames := map[string]bool{}
+	for _, flag := range cmd.Flags {
+		for _, name := range flag.Names() {
+			flagNames[name] = true
+		}
+	}
+	for _, name := range []string{"iterations", "n", "hooks", "no-hooks", "save-baseline", "verbose"} {
+		assert.True(t, flagNames[name], "expected flag %s", name)
+	}
+}
+
+func TestParseHookNumbers(t *testing.T) {
+	tests := []struct {
+		name        string
+		selection   string
+		hookCount   int
+		expected    []int
+		expectedErr string
+	}{
+		{name: "single", selection: "2", hookCount: 3, expected: []int{2}},
+		{name: "list with spaces and duplicates", selection: "3, 1,3", hookCount: 3, expected: []int{3, 1}},
+		{name: "not a number", selection: "1,x", hookCount: 3, expectedErr: "invalid hook number 'x'"},
+		{name: "out of range", selection: "4", hookCount: 3, expectedErr: "out of range"},
+		{name: "zero", selection: "0", hookCount: 3, expectedErr: "out of range"},
+		{name: "empty list", selection: ",", hookCount: 3, expectedErr: "at least one hook"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			numbers, err := parseHookNumbers("--hooks", tt.selection, tt.hookCount)
+			if tt.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, numbers)
+		})
+	}
+}
+
+func TestSelectBenchHooks(t *testing.T) {
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{Type: config.HookTypeCommand, Command: "echo one"},
+				{Type: config.HookTypeCommand, Command: "echo two"},
+				{Type: config.HookTypeCommand, Command: "echo three"},
+			},
+		},
+	}
+
+	t.Run("all hooks by default", func(t *testing.T) {
+		selected, numbers := selectBenchHooks(cfg, benchOptions{})
+		assert.Len(t, selected.Hooks.PostCreate, 3)
+		assert.Equal(t, []int{1, 2, 3}, numbers)
+	})
+
+	t.Run("subset keeps original numbering", func(t *testing.T) {
+		selected, numbers := selectBenchHooks(cfg, benchOptions{HookNumbers: []int{3, 1}})
+		require.Len(t, selected.Hooks.PostCreate, 2)
+		assert.Equal(t, "echo three", selected.Hooks.PostCreate[0].Command)
+		assert.Equal(t, []int{3, 1}, numbers)
+	})
+
+	t.Run("no hooks", func(t *testing.T) {
+		selected, numbers := selectBenchHooks(cfg, benchOptions{NoHooks: true})
+		assert.Empty(t, selected.Hooks.PostCreate)
+		assert.Nil(t, numbers)
+		assert.Len(t, cfg.Hooks.PostCreate, 3, "original config must not be modified")
+	})
+}
+
+func TestRunBench(t *testing.T) {
+	t.Run("records git and cleanup phases per iteration", func(t *testing.T) {
+		mockExec := &mockBenchCommandExecutor{}
+		cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+
+		report, err := runBench(io.Discard, mockExec, cfg, t.TempDir(), benchOptions{Iterations: 2})
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, report.Iterations)
+		require.Len(t, mockExec.executedCommands, 4)
+		assert.Equal(t, []string{"worktree", "add", "--detach"}, mockExec.executedCommands[0].Args[:3])
+		assert.Equal(t, "HEAD", mockExec.executedCommands[0].Args[4])
+		assert.Equal(t, []string{"worktree", "remove", "--force"}, mockExec.executedCommands[1].Args[:3])
+
+		names := make([]string, 0, len(report.Phases))
+		for _, phase := range report.Phases {
+			names = append(names, phase.Name)
+			assert.Len(t, phase.samples, 2)
+		}
+		assert.Equal(t, []string{benchPhaseGitAdd, benchPhaseCleanup, benchPhaseTotal}, names)
+	})
+
+	t.Run("times each selected hook", func(t *testing.T) {
+		repoRoot := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(repoRoot, ".env"), []byte("KEY=value"), 0o600))
+
+		cfg := &config.Config{
+			Hooks: config.Hooks{
+				PostCreate: []config.Hook{
+					{Type: config.HookTypeCommand, Command: "exit 1"},
+					{Type: config.HookTypeCopy, From: ".env", To: ".env"},
+				},
+			},
+		}
+
+		report, err := runBench(io.Discard, &mockBenchCommandExecutor{}, cfg, repoRoot,
+			benchOptions{Iterations: 1, HookNumbers: []int{2}})
+		require.NoError(t, err)
+
+		var names []string
+		for _, phase := range report.Phases {
+			names = append(names, phase.Name)
+		}
+		assert.Contains(t, names, "hook 2 (copy)")
+		assert.NotContains(t, names, "hook 1 (command)")
+	})
+
+	t.Run("cleans up and reports git failures", func(t *testing.T) {
+		var created string
+		original := benchMkdirTemp
+		benchMkdirTemp = func(dir, pattern string) (string, error) {
+			path, err := os.MkdirTemp(dir, pattern)
+			created = path
+			return path, err
+		}
+		t.Cleanup(func() { benchMkdirTemp = original })
+
+		mockExec := &mockBenchCommandExecutor{failOutput: "fatal: invalid reference: HEAD"}
+		_, err := runBench(io.Discard, mockExec, &config.Config{}, t.TempDir(), benchOptions{Iterations: 1})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid reference")
+		_, statErr := os.Stat(created)
+		assert.True(t, os.IsNotExist(statErr), "temporary directory should be removed")
+	})
+}
+
+func TestBenchBaselineRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wtp", benchBaselineFileName)
+
+	missing, err := loadBenchBaseline(path)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	report := &benchReport{
+		Iterations: 3,
+		RecordedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Phases: []benchPhase{
+			{Name: benchPhaseGitAdd, Mean: 20 * time.Millisecond, Min: 10 * time.Millisecond, Max: 30 * time.Millisecond},
+		},
+	}
+	require.NoError(t, saveBenchBaseline(path, report))
+
+	loaded, err := loadBenchBaseline(path)
+	require.NoError(t, err)
+	assert.Equal(t, report.Iterations, loaded.Iterations)
+	assert.Equal(t, report.Phases, loaded.Phases)
+	assert.True(t, report.RecordedAt.Equal(loaded.RecordedAt))
+}
+
+func TestWriteBenchReport(t *testing.T) {
+	report := &benchReport{
+		Iterations: 2,
+		Phases: []benchPhase{
+			{Name: benchPhaseGitAdd, Mean: 12 * time.Millisecond, Min: 10 * time.Millisecond, Max: 14 * time.Millisecond},
+			{Name: "hook 1 (command)", Mean: 50 * time.Millisecond},
+		},
+	}
+
+	t.Run("without baseline", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeBenchReport(&buf, report, nil))
+
+		output := buf.String()
+		assert.Contains(t, output, "Benchmarked 2 iteration(s)")
+		assert.Contains(t, output, "git worktree add")
+		assert.Contains(t, output, "12.0ms")
+		assert.NotContains(t, output, "BASELINE")
+	})
+
+	t.Run("with baseline", func(t *testing.T) {
+		baseline := &benchReport{
+			Iterations: 5,
+			Phases: []benchPhase{
+				{Name: benchPhaseGitAdd, Mean: 10 * time.Millisecond},
+			},
+		}
+
+		var buf bytes.Buffer
+		require.NoError(t, writeBenchReport(&buf, report, baseline))
+
+		output := buf.String()
+		assert.Contains(t, output, "BASELINE")
+		assert.Contains(t, output, "+20.0%")
+		assert.Contains(t, output, "Compared against baseline")
+	})
+}
+
+func TestFormatBenchDelta(t *testing.T) {
+	assert.Equal(t, "-50.0%", formatBenchDelta(5*time.Millisecond, 10*time.Millisecond))
+	assert.Equal(t, "+0.0%", formatBenchDelta(10*time.Millisecond, 10*time.Millisecond))
+	assert.Equal(t, "-", formatBenchDelta(10*time.Millisecond, 0))
+}
+
+func TestResolveBenchOptions(t *testing.T) {
+	cfg := &config.Config{
+		Hooks: config.Hooks{PostCreate: []config.Hook{{Type: config.HookTypeCommand, Command: "true"}}},
+	}
+
+	run := func(args ...string) (benchOptions, error) {
+		var opts benchOptions
+		var resolveErr error
+		cmd := NewBenchCommand()
+		cmd.Action = func(_ context.Context, c *cli.Command) error {
+			opts, resolveErr = resolveBenchOptions(c, cfg)
+			return nil
+		}
+		require.NoError(t, cmd.Run(context.Background(), append([]string{"bench"}, args...)))
+		return opts, resolveErr
+	}
+
+	opts, err := run()
+	require.NoError(t, err)
+	assert.Equal(t, defaultBenchIterations, opts.Iterations)
+
+	_, err = run("--iterations", "0")
+	assert.ErrorContains(t, err, "--iterations must be a positive number")
+
+	_, err = run("--hooks", "1", "--no-hooks")
+	assert.ErrorContains(t, err, "cannot be combined")
+
+	opts, err = run("-n", "5", "--hooks", "1")
+	require.NoError(t, err)
+	assert.Equal(t, 5, opts.Iterations)
+	assert.Equal(t, []int{1}, opts.HookNumbers)
+}
+
+// ===== Mock Implementations =====
+
+type mockBenchCommandExecutor struct {
+	executedCommands []command.Command
+	failOutput       string
+}
+
+func (m *mockBenchCommandExecutor) Execute(commands []command.Command) (*command.ExecutionResult, error) {
+	m.executedCommands = append(m.executedCommands, commands...)
+
+	results := make([]command.Result, len(commands))
+	for i, cmd := range commands {
+		results[i] = command.Result{Command: cmd}
+		if m.failOutput != "" {
+			results[i].Output = m.failOutput
+			results[i].Error = fmt.Errorf("exit status 128")
+		}
+	}
+	return &command.ExecutionResult{Results: results}, nil
+}
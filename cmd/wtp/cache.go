@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/cache"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/hooks"
+)
+
+// cacheLocation is one of the caches 'wtp cache' manages.
+type cacheLocation struct {
+	// name is used in messages; title heads the cache's output.
+	name  string
+	title string
+	root  string
+	// skip lists the children of root that are not cache entries.
+	skip []string
+}
+
+// NewCacheCommand creates the cache command definition
+func NewCacheCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "cache",
+		Usage: "Inspect and trim the shared caches",
+		Description: "Manages the shared cache command hooks get in $WTP_CACHE_DIR and the cache of " +
+			"checksum-pinned downloads. Each top-level directory or file in a cache is an entry; wtp " +
+			"records which worktrees used it. 'wtp cache gc' evicts the least recently used entries " +
+			"no existing worktree uses until the cache settings in .wtp.yml hold.\n\n" +
+			"Examples:\n" +
+			"  wtp cache ls            # Show entries, sizes, and users\n" +
+			"  wtp cache gc --dry-run  # Show what gc would evict\n" +
+			"  wtp cache clear         # Remove every entry",
+		Commands: []*cli.Command{
+			{
+				Name:   "ls",
+				Usage:  "List cache entries with their size, last use, and worktrees",
+				Action: cacheLsCommand,
+			},
+			{
+				Name:  "gc",
+				Usage: "Evict unused entries beyond cache.max_size or cache.max_age",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Show the entries that would be evicted without removing them",
+					},
+				},
+				Action: cacheGCCommand,
+			},
+			{
+				Name:   "clear",
+				Usage:  "Remove every cache entry",
+				Action: cacheClearCommand,
+			},
+		},
+	}
+}
+
+func cacheLsCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+	_, locations, err := setupCacheLocations()
+	if err != nil {
+		return err
+	}
+	return listCaches(w, locations)
+}
+
+func cacheGCCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+	cfg, locations, err := setupCacheLocations()
+	if err != nil {
+		return err
+	}
+	dryRun := cmd.Bool("dry-run")
+	if !dryRun {
+		if err := ensureWritable(cfg, "evict cache entries"); err != nil {
+			return err
+		}
+	}
+	limits := cache.Limits{MaxSize: cfg.Cache.MaxSizeBytes(), MaxAge: cfg.Cache.MaxAgeDuration()}
+	return gcCaches(w, locations, limits, dryRun, time.Now())
+}
+
+func cacheClearCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+	cfg, locations, err := setupCacheLocations()
+	if err != nil {
+		return err
+	}
+	if err := ensureWritable(cfg, "clear caches"); err != nil {
+		return err
+	}
+	return clearCaches(w, locations)
+}
+
+// setupCacheLocations returns the configuration and the caches of the repository
+// containing the current directory.
+func setupCacheLocations() (*config.Config, []cacheLocation, error) {
+	repo, cfg, _, err := setupRepoAndConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	commonDir, err := repo.GetGitCommonDir()
+	if err != nil {
+		return nil, nil, errors.GitCommandFailed("git rev-parse --git-common-dir", err.Error())
+	}
+	downloads, err := hooks.DownloadCacheDir()
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, []cacheLocation{
+		{name: "shared cache", title: "Shared cache", root: hooks.SharedCacheDir(commonDir), skip: []string{lockDirName}},
+		{name: "download cache", title: "Download cache", root: downloads},
+	}, nil
+}
+
+func listCaches(w io.Writer, locations []cacheLocation) error {
+	for i, location := range locations {
+		entries, err := cache.List(location.root, location.skip...)
+		if err != nil {
+			return fmt.Errorf("failed to read the %s: %w", location.name, err)
+		}
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s (%s)\n",
+			location.title, location.root, describeCacheEntries(entries)); err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			continue
+		}
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:mnd // column padding
+		// Most recently used first
+		for j := len(entries) - 1; j >= 0; j-- {
+			entry := &entries[j]
+			if _, err := fmt.Fprintf(tw, "  %s\t%s\tused %s\t%s\n", entry.Name, formatDiskSize(entry.Size),
+				formatCacheTime(entry.LastUsed), describeCacheRefs(entry.Refs)); err != nil {
+				return err
+			}
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gcCaches evicts from each cache the entries cache.Select picks for limits, or only shows
+// them with dryRun.
+func gcCaches(w io.Writer, locations []cacheLocation, limits cache.Limits, dryRun bool, now time.Time) error {
+	if limits == (cache.Limits{}) {
+		_, err := fmt.Fprintln(w, "No cache limits configured; set cache.max_size or cache.max_age in .wtp.yml")
+		return err
+	}
+
+	verb, summary := "Evicted", "freed"
+	if dryRun {
+		verb, summary = "Would evict", "would free"
+	}
+	for _, location := range locations {
+		entries, err := cache.List(location.root, location.skip...)
+		if err != nil {
+			return fmt.Errorf("failed to read the %s: %w", location.name, err)
+		}
+		evict := cache.Select(entries, limits, now)
+		if len(evict) > 0 && !dryRun {
+			if evict, err = cache.Evict(location.root, evict); err != nil {
+				return fmt.Errorf("failed to evict from the %s: %w", location.name, err)
+			}
+		}
+
+		var freed, remaining int64
+		for i := range evict {
+			freed += evict[i].Size
+			if _, err := fmt.Fprintf(w, "%s %s (%s, used %s)\n", verb, evict[i].Name,
+				formatDiskSize(evict[i].Size), formatCacheTime(evict[i].LastUsed)); err != nil {
+				return err
+			}
+		}
+		for i := range entries {
+			remaining += entries[i].Size
+		}
+		remaining -= freed
+		if _, err := fmt.Fprintf(w, "%s: %s %s, %s left\n",
+			location.title, summary, formatDiskSize(freed), formatDiskSize(remaining)); err != nil {
+			return err
+		}
+		if limits.MaxSize > 0 && remaining > limits.MaxSize {
+			if err := writeWarning(w, errors.CodeWarnCacheOverBudget,
+				"the %s is still over cache.max_size because worktrees use its remaining entries",
+				location.name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// clearCaches removes every entry of each cache, including those worktrees still use.
+func clearCaches(w io.Writer, locations []cacheLocation) error {
+	for _, location := range locations {
+		entries, err := cache.List(location.root, location.skip...)
+		if err != nil {
+			return fmt.Errorf("failed to read the %s: %w", location.name, err)
+		}
+		if len(entries) > 0 {
+			if entries, err = cache.Evict(location.root, entries); err != nil {
+				return fmt.Errorf("failed to clear the %s: %w", location.name, err)
+			}
+		}
+		var freed int64
+		for i := range entries {
+			freed += entries[i].Size
+		}
+		if _, err := fmt.Fprintf(w, "%s: cleared, freed %s\n", location.title, formatDiskSize(freed)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func describeCacheEntries(entries []cache.Entry) string {
+	if len(entries) == 0 {
+		return "empty"
+	}
+	var total int64
+	for i := range entries {
+		total += entries[i].Size
+	}
+	return fmt.Sprintf("entries: %d, total: %s", len(entries), formatDiskSize(total))
+}
+
+func describeCacheRefs(refs []string) string {
+	if len(refs) == 0 {
+		return "unused"
+	}
+	return fmt.Sprintf("%d worktree(s)", len(refs))
+}
+
+func formatCacheTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
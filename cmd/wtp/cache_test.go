@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/cache"
+)
+
+func setupCacheLocation(t *testing.T) cacheLocation {
+	t.Helper()
+	root := t.TempDir()
+	old := time.Now().Add(-60 * 24 * time.Hour)
+	for name, size := range map[string]int{"npm": 2048, "pip": 1024, lockDirName: 16} {
+		dir := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "data"), make([]byte, size), 0o644))
+		require.NoError(t, os.Chtimes(filepath.Join(dir, "data"), old, old))
+		require.NoError(t, os.Chtimes(dir, old, old))
+	}
+	return cacheLocation{name: "shared cache", title: "Shared cache", root: root, skip: []string{lockDirName}}
+}
+
+func TestNewCacheCommand(t *testing.T) {
+	cmd := NewCacheCommand()
+
+	assert.Equal(t, "cache", cmd.Name)
+	var names []string
+	for _, sub := range cmd.Commands {
+		names = append(names, sub.Name)
+	}
+	assert.Equal(t, []string{"ls", "gc", "clear"}, names)
+}
+
+func TestListCaches(t *testing.T) {
+	location := setupCacheLocation(t)
+	empty := cacheLocation{name: "download cache", title: "Download cache", root: filepath.Join(t.TempDir(), "none")}
+
+	var buf bytes.Buffer
+	require.NoError(t, listCaches(&buf, []cacheLocation{location, empty}))
+
+	output := buf.String()
+	assert.Contains(t, output, "Shared cache: "+location.root+" (entries: 2, total: 3.0 KiB)")
+	assert.Contains(t, output, "npm  2.0 KiB")
+	assert.Contains(t, output, "unused")
+	assert.NotContains(t, output, lockDirName)
+	assert.Contains(t, output, "Download cache: "+empty.root+" (empty)")
+}
+
+func TestGCCaches(t *testing.T) {
+	t.Run("without limits", func(t *testing.T) {
+		location := setupCacheLocation(t)
+
+		var buf bytes.Buffer
+		require.NoError(t, gcCaches(&buf, []cacheLocation{location}, cache.Limits{}, false, time.Now()))
+		assert.Contains(t, buf.String(), "No cache limits configured")
+		assert.DirExists(t, filepath.Join(location.root, "npm"))
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		location := setupCacheLocation(t)
+
+		var buf bytes.Buffer
+		require.NoError(t, gcCaches(&buf, []cacheLocation{location}, cache.Limits{MaxAge: 24 * time.Hour}, true, time.Now()))
+		assert.Contains(t, buf.String(), "Would evict npm")
+		assert.Contains(t, buf.String(), "Shared cache: would free 3.0 KiB, 0 B left")
+		assert.DirExists(t, filepath.Join(location.root, "npm"))
+	})
+
+	t.Run("keeps referenced entries", func(t *testing.T) {
+		location := setupCacheLocation(t)
+		worktree := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(worktree, ".git"), []byte("gitdir: /elsewhere\n"), 0o644))
+		require.NoError(t, cache.Use(location.root, "npm", worktree, time.Now().Add(-48*time.Hour)))
+
+		var buf bytes.Buffer
+		require.NoError(t, gcCaches(&buf, []cacheLocation{location}, cache.Limits{MaxSize: 1024}, false, time.Now()))
+		output := buf.String()
+		assert.Contains(t, output, "Evicted pip")
+		assert.Contains(t, output, "Shared cache: freed 1.0 KiB, 2.0 KiB left")
+		assert.Contains(t, output, "WTP7011")
+		assert.DirExists(t, filepath.Join(location.root, "npm"))
+		assert.NoDirExists(t, filepath.Join(location.root, "pip"))
+		assert.DirExists(t, filepath.Join(location.root, lockDirName))
+	})
+}
+
+func TestClearCaches(t *testing.T) {
+	location := setupCacheLocation(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, clearCaches(&buf, []cacheLocation{location}))
+	assert.Contains(t, buf.String(), "Shared cache: cleared, freed 3.0 KiB")
+	assert.NoDirExists(t, filepath.Join(location.root, "npm"))
+	assert.DirExists(t, filepath.Join(location.root, lockDirName))
+}
@@ -33,7 +33,11 @@ func NewCdCommand() *cli.Command {
 			"Usage:\n" +
 			"  Direct:     cd \"$(wtp cd feature)\"\n" +
 			"  With hook:  wtp cd feature\n" +
-			"  Go home:    wtp cd\n\n" +
+			"  Go home:    wtp cd\n" +
+			"  Fuzzy:      wtp cd auth   # feature/auth-api, if it is the only good match\n\n" +
+			"Names are matched exactly first. Otherwise the branch and worktree names are\n" +
+			"matched case-insensitively by prefix, substring, then subsequence, like every\n" +
+			"other command that takes a worktree name.\n\n" +
 			"To enable the hook for easier navigation:\n" +
 			"  Bash: eval \"$(wtp hook bash)\"\n" +
 			"  Zsh:  eval \"$(wtp hook zsh)\"\n" +
@@ -96,45 +100,49 @@ func cdCommandWithCommandExecutor(
 	// Find the main worktree path
 	mainWorktreePath := findMainWorktreePath(worktrees)
 
-	// Find the worktree using multiple resolution strategies
-	targetPath := resolveCdWorktreePath(worktreeName, worktrees, mainWorktreePath)
-
-	if targetPath == "" {
-		// Get available worktree names for suggestions
-		availableWorktrees := make([]string, 0, len(worktrees))
+	// Load config for worktree names; without it the default base_dir is assumed
+	cfg, err := config.LoadConfig(mainWorktreePath, "")
+	if err != nil {
+		cfg = nil
+	}
 
-		// Load config and main repo path to get proper worktree names
-		mainRepoPath := findMainWorktreePath(worktrees)
-		cfg, err := config.LoadConfig(mainRepoPath)
-		if err != nil {
-			// Fallback to directory names if config can't be loaded
-			for i := range worktrees {
-				// Only include managed worktrees
-				if isWorktreeManagedCd(worktrees[i].Path, nil, mainRepoPath, worktrees[i].IsMain) {
-					availableWorktrees = append(availableWorktrees, filepath.Base(worktrees[i].Path))
-				}
-			}
-		} else {
-			// Use consistent worktree names (relative to base_dir)
-			for i := range worktrees {
-				// Only include managed worktrees
-				if isWorktreeManagedCd(worktrees[i].Path, cfg, mainRepoPath, worktrees[i].IsMain) {
-					name := getWorktreeNameFromPath(worktrees[i].Path, cfg, mainRepoPath, worktrees[i].IsMain)
-					availableWorktrees = append(availableWorktrees, name)
-				}
-			}
-		}
-		return errors.WorktreeNotFound(worktreeName, availableWorktrees)
+	// Find the worktree using exact names first, then fuzzy matches
+	target, err := resolveWorktreeName(worktreeName, worktrees, cfg, mainWorktreePath)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		return errors.WorktreeNotFound(worktreeName, availableCdWorktreeNames(worktrees, cfg, mainWorktreePath))
 	}
 
 	// Output the path for the shell function to cd to
-	if _, err := fmt.Fprintln(w, targetPath); err != nil {
+	if _, err := fmt.Fprintln(w, target.Path); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// availableCdWorktreeNames lists the managed worktrees for the not-found error message.
+func availableCdWorktreeNames(worktrees []git.Worktree, cfg *config.Config, mainRepoPath string) []string {
+	availableWorktrees := make([]string, 0, len(worktrees))
+	for i := range worktrees {
+		// Only include managed worktrees
+		if !isWorktreeManagedCd(worktrees[i].Path, cfg, mainRepoPath, worktrees[i].IsMain) {
+			continue
+		}
+		if cfg == nil {
+			// Fallback to directory names if config can't be loaded
+			availableWorktrees = append(availableWorktrees, filepath.Base(worktrees[i].Path))
+			continue
+		}
+		// Use consistent worktree names (relative to base_dir)
+		name := getWorktreeNameFromPath(worktrees[i].Path, cfg, mainRepoPath, worktrees[i].IsMain)
+		availableWorktrees = append(availableWorktrees, name)
+	}
+	return availableWorktrees
+}
+
 // findMainWorktreePath finds the main worktree from the list of worktrees
 func findMainWorktreePath(worktrees []git.Worktree) string {
 	// The first worktree is always the main worktree (git worktree list behavior)
@@ -150,7 +158,7 @@ func resolveCdWorktreePath(worktreeName string, worktrees []git.Worktree, mainWo
 	worktreeName = strings.TrimSuffix(worktreeName, "*")
 
 	// Load config for unified naming
-	cfg, _ := config.LoadConfig(mainWorktreePath)
+	cfg, _ := config.LoadConfig(mainWorktreePath, "")
 
 	// The order matters: more specific matches come first
 	for i := range worktrees {
@@ -282,7 +290,7 @@ func getWorktreesForCd(w io.Writer) error {
 	}
 
 	// Load config
-	cfg, err := config.LoadConfig(mainRepoPath)
+	cfg, err := config.LoadConfig(mainRepoPath, "")
 	if err != nil {
 		return err
 	}
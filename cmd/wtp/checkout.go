@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/git"
+	"github.com/satococoa/wtp/v2/internal/hooks"
+)
+
+// NewCheckoutCommand creates the checkout command definition
+func NewCheckoutCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "checkout",
+		Usage:     "Switch a worktree to another branch",
+		UsageText: "wtp checkout <worktree-name> <branch>",
+		Description: "Checks out a branch in an existing worktree and then runs the hooks.post_checkout " +
+			"entries from .wtp.yml. Command hooks receive GIT_WTP_OLD_BRANCH and GIT_WTP_NEW_BRANCH.\n\n" +
+			"Examples:\n" +
+			"  wtp checkout feature/auth feature/auth-v2   # Switch the feature/auth worktree\n" +
+			"  wtp checkout @ release/1.2                  # Switch the main worktree",
+		ArgsUsage:     "<worktree-name> <branch>",
+		ShellComplete: completeWorktreesForCd,
+		Action:        checkoutCommand,
+	}
+}
+
+func checkoutCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	if cmd.Args().Len() != 2 { //nolint:mnd // worktree name and branch
+		return errors.InvalidArguments(`worktree name and branch are required
+
+Usage: wtp checkout <worktree-name> <branch>
+
+Tip: Run 'wtp list' to see available worktrees`)
+	}
+
+	_, cfg, _, err := setupRepoAndConfigForBranch(cmd.Args().Get(1))
+	if err != nil {
+		return err
+	}
+	if err := ensureWritable(cfg, "check out branches"); err != nil {
+		return err
+	}
+
+	executor := command.NewRealExecutor()
+	return checkoutCommandWithCommandExecutor(w, executor, cfg, cmd.Args().Get(0), cmd.Args().Get(1))
+}
+
+func checkoutCommandWithCommandExecutor(
+	w io.Writer, executor command.Executor, cfg *config.Config, worktreeName, branch string,
+) error {
+	result, err := executor.Execute([]command.Command{command.GitWorktreeList()})
+	if err != nil {
+		return errors.GitCommandFailed("git worktree list", err.Error())
+	}
+	worktrees := parseWorktreesFromOutput(result.Results[0].Output)
+	mainWorktreePath := findMainWorktreePath(worktrees)
+
+	target, err := resolveWorktreeName(worktreeName, worktrees, cfg, mainWorktreePath)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		return errors.WorktreeNotFound(worktreeName, managedWorktreeNames(worktrees, cfg, mainWorktreePath))
+	}
+
+	oldBranch := target.Branch
+	displayOld := formatBranchDisplay(oldBranch)
+	if oldBranch == detachedKeyword {
+		oldBranch = ""
+	}
+	result, err = executor.Execute([]command.Command{command.GitCheckout(target.Path, branch)})
+	if err != nil {
+		return errors.GitCommandFailed("git checkout "+branch, err.Error())
+	}
+	if len(result.Results) > 0 && result.Results[0].Error != nil {
+		return errors.GitCommandFailed("git checkout "+branch, result.Results[0].Output)
+	}
+
+	if _, err := fmt.Fprintf(w, "Switched worktree '%s' from '%s' to '%s'\n",
+		worktreeName, displayOld, branch); err != nil {
+		return err
+	}
+	if err := syncWorktreeMap(w, executor, mainWorktreePath); err != nil {
+		return err
+	}
+
+	err = executePostCheckoutHooks(context.Background(), w, cfg, mainWorktreePath, target.Path, oldBranch, branch, nil)
+	if err != nil {
+		warnErr := writeWarning(w, errors.CodeWarnPostCheckoutHookFailed, "Hook execution failed: %v", err)
+		if warnErr != nil {
+			return warnErr
+		}
+	}
+
+	return nil
+}
+
+// managedWorktreeNames returns the names of the worktrees wtp manages, for "not found" errors.
+func managedWorktreeNames(worktrees []git.Worktree, cfg *config.Config, mainWorktreePath string) []string {
+	names := make([]string, 0, len(worktrees))
+	for i := range worktrees {
+		wt := &worktrees[i]
+		if isWorktreeManagedCommon(wt.Path, cfg, mainWorktreePath, wt.IsMain) {
+			names = append(names, getWorktreeNameFromPath(wt.Path, cfg, mainWorktreePath, wt.IsMain))
+		}
+	}
+	return names
+}
+
+// executePostCheckoutHooks runs the configured post_checkout hooks for a worktree
+// that now has newBranch checked out.
+func executePostCheckoutHooks(
+	ctx context.Context, w io.Writer, cfg *config.Config, repoPath, workTreePath, oldBranch, newBranch string,
+	vars map[string]string,
+) error {
+	if !cfg.HasPostCheckoutHooks() {
+		return nil
+	}
+
+	if _, err := fmt.Fprintln(w, "\nExecuting post-checkout hooks..."); err != nil {
+		return err
+	}
+
+	executor := hooks.NewExecutor(cfg, repoPath).WithContext(ctx).WithVariables(vars)
+	if err := executor.ExecutePostCheckoutHooks(w, workTreePath, oldBranch, newBranch); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintln(w, "✓ All post-checkout hooks executed successfully")
+	return err
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+type mockCheckoutCommandExecutor struct {
+	executedCommands []command.Command
+	listOutput       string
+	checkoutError    error
+}
+
+func (m *mockCheckoutCommandExecutor) Execute(commands []command.Command) (*command.ExecutionResult, error) {
+	m.executedCommands = append(m.executedCommands, commands...)
+	results := make([]command.Result, len(commands))
+	for i, cmd := range commands {
+		results[i].Command = cmd
+		if cmd.Args[0] == "worktree" {
+			results[i].Output = m.listOutput
+		} else {
+			results[i].Error = m.checkoutError
+			if m.checkoutError != nil {
+				results[i].Output = "error: pathspec did not match"
+			}
+		}
+	}
+	return &command.ExecutionResult{Results: results}, nil
+}
+
+func TestNewCheckoutCommand(t *testing.T) {
+	cmd := NewCheckoutCommand()
+
+	assert.Equal(t, "checkout", cmd.Name)
+	assert.NotEmpty(t, cmd.Usage)
+	assert.NotNil(t, cmd.Action)
+	assert.NotNil(t, cmd.ShellComplete)
+}
+
+func setupCheckoutTest(t *testing.T) (mainPath, worktreePath, listOutput string) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	root := t.TempDir()
+	mainPath = filepath.Join(root, "repo")
+	worktreePath = filepath.Join(root, "worktrees", "feature", "foo")
+	require.NoError(t, os.MkdirAll(mainPath, 0o755))
+	require.NoError(t, os.MkdirAll(worktreePath, 0o755))
+
+	listOutput = fmt.Sprintf(
+		"worktree %s\nHEAD abc123\nbranch refs/heads/main\n\n"+
+			"worktree %s\nHEAD def456\nbranch refs/heads/feature/foo\n\n",
+		mainPath, worktreePath,
+	)
+	return mainPath, worktreePath, listOutput
+}
+
+func TestCheckoutCommand_SwitchesBranchAndRunsHooks(t *testing.T) {
+	_, worktreePath, listOutput := setupCheckoutTest(t)
+	mockExec := &mockCheckoutCommandExecutor{listOutput: listOutput}
+	cfg := &config.Config{
+		Defaults: config.Defaults{BaseDir: "../worktrees"},
+		Hooks: config.Hooks{
+			PostCheckout: []config.Hook{
+				{Type: config.HookTypeCommand, Command: "echo \"hook: $GIT_WTP_OLD_BRANCH -> $GIT_WTP_NEW_BRANCH\""},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	err := checkoutCommandWithCommandExecutor(&buf, mockExec, cfg, "feature/foo", "feature/bar")
+
+	require.NoError(t, err)
+	require.Len(t, mockExec.executedCommands, 2)
+	assert.Equal(t, command.GitCheckout(worktreePath, "feature/bar"), mockExec.executedCommands[1])
+
+	output := buf.String()
+	assert.Contains(t, output, "Switched worktree 'feature/foo' from 'feature/foo' to 'feature/bar'")
+	assert.Contains(t, output, "hook: feature/foo -> feature/bar")
+}
+
+func TestCheckoutCommand_WorktreeNotFound(t *testing.T) {
+	_, _, listOutput := setupCheckoutTest(t)
+	mockExec := &mockCheckoutCommandExecutor{listOutput: listOutput}
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+
+	err := checkoutCommandWithCommandExecutor(&bytes.Buffer{}, mockExec, cfg, "missing", "main")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing")
+	assert.Len(t, mockExec.executedCommands, 1, "checkout must not run for unknown worktrees")
+}
+
+func TestCheckoutCommand_GitFailureSkipsHooks(t *testing.T) {
+	_, _, listOutput := setupCheckoutTest(t)
+	mockExec := &mockCheckoutCommandExecutor{listOutput: listOutput, checkoutError: fmt.Errorf("exit status 1")}
+	cfg := &config.Config{
+		Defaults: config.Defaults{BaseDir: "../worktrees"},
+		Hooks: config.Hooks{
+			PostCheckout: []config.Hook{{Type: config.HookTypeCommand, Command: "echo should-not-run"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	err := checkoutCommandWithCommandExecutor(&buf, mockExec, cfg, "@", "nope")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pathspec did not match")
+	assert.NotContains(t, buf.String(), "should-not-run")
+}
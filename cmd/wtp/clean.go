@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/git"
+	"github.com/satococoa/wtp/v2/internal/hooks"
+)
+
+const noCleanHooksMessage = "No clean hooks configured (add them under hooks.clean in .wtp.yml)"
+
+// NewCleanCommand creates the clean command definition
+func NewCleanCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "clean",
+		Usage:     "Run clean hooks to reclaim disk space without removing a worktree",
+		UsageText: "wtp clean [--all] [--fail-fast] [<worktree-name>]",
+		Description: "Runs the hooks.clean entries from .wtp.yml in a worktree, e.g. deleting " +
+			"node_modules or target, and shows how much disk space was freed. The worktree, its " +
+			"branch, and uncommitted changes are kept. Without a name, the current worktree is used; " +
+			"with --all, every worktree is cleaned.\n\n" +
+			"With --all, a worktree whose hooks fail does not stop the others unless --fail-fast is " +
+			"given; the result in each worktree is then shown, and wtp exits with status 3 when " +
+			"cleaning succeeded in some worktrees.\n\n" +
+			"Examples:\n" +
+			"  wtp clean feature/old   # Clean one worktree\n" +
+			"  wtp clean --all         # Clean every worktree\n" +
+			"  wtp list --usage        # See which worktrees take the most space",
+		ArgsUsage: "[<worktree-name>]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "all",
+				Usage: "Run the clean hooks in every worktree",
+			},
+			newFailFastFlag(),
+		},
+		ShellComplete: completeWorktreesForCd,
+		Action:        cleanCommand,
+	}
+}
+
+func cleanCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	_, cfg, mainRepoPath, err := setupRepoAndConfig()
+	if err != nil {
+		return err
+	}
+	if err := ensureWritable(cfg, "clean worktrees"); err != nil {
+		return err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return errors.DirectoryAccessFailed("access current", ".", err)
+	}
+
+	executor := command.NewRealExecutor()
+	if cmd.Bool("all") {
+		if cmd.Args().Len() > 0 {
+			return errors.InvalidArguments("--all cannot be combined with a worktree name")
+		}
+		return cleanAllCommandWithCommandExecutor(w, executor, cfg, mainRepoPath, cmd.Bool("fail-fast"))
+	}
+	return cleanCommandWithCommandExecutor(w, executor, cfg, mainRepoPath, cwd, cmd.Args().First())
+}
+
+func cleanCommandWithCommandExecutor(
+	w io.Writer, executor command.Executor, cfg *config.Config, mainRepoPath, cwd, worktreeName string,
+) error {
+	target, err := resolveHookWorktree(executor, cfg, cwd, worktreeName)
+	if err != nil {
+		return err
+	}
+	if !target.cfg.HasCleanHooks() {
+		_, err := fmt.Fprintln(w, noCleanHooksMessage)
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "Running clean hooks in %s...\n", target.name); err != nil {
+		return err
+	}
+	freed, err := cleanWorktree(w, target.cfg, mainRepoPath, target.worktree.Path, nil)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "✓ Cleaned %s, freeing %s\n", target.name, formatDiskSize(freed))
+	return err
+}
+
+func cleanAllCommandWithCommandExecutor(
+	w io.Writer, executor command.Executor, cfg *config.Config, mainRepoPath string, failFast bool,
+) error {
+	result, err := executor.Execute([]command.Command{command.GitWorktreeList()})
+	if err != nil {
+		return errors.GitCommandFailed("git worktree list", err.Error())
+	}
+	worktrees := parseWorktreesFromOutput(result.Results[0].Output)
+
+	report, freed, err := runCleanHooks(w, cfg, mainRepoPath, worktrees, failFast)
+	if err != nil {
+		return err
+	}
+	if !report.complete() {
+		if err := report.write(w); err != nil {
+			return err
+		}
+		return report.err(errors.CleanHooksFailed)
+	}
+	if len(report.results) == 0 {
+		_, err := fmt.Fprintln(w, noCleanHooksMessage)
+		return err
+	}
+	_, err = fmt.Fprintf(w, "\n✓ Cleaned %d worktree(s), freeing %s\n", len(report.results), formatDiskSize(freed))
+	return err
+}
+
+// runCleanHooks runs the clean hooks of every worktree that has some, reports the result
+// in each, and returns the disk space freed in total. A failure is reported as a warning
+// and the other worktrees still go ahead, unless failFast skips them.
+func runCleanHooks(
+	w io.Writer, cfg *config.Config, mainRepoPath string, worktrees []git.Worktree, failFast bool,
+) (report *batchReport, freed int64, err error) {
+	report = &batchReport{}
+	for i := range worktrees {
+		wt := &worktrees[i]
+		wtCfg, err := maintenanceConfig(mainRepoPath, wt)
+		if err != nil {
+			return nil, 0, err
+		}
+		if wtCfg == nil || !wtCfg.HasCleanHooks() {
+			continue
+		}
+
+		name := getWorktreeNameFromPath(wt.Path, cfg, mainRepoPath, wt.IsMain)
+		if failFast && len(report.names(batchFailed)) > 0 {
+			report.skip(name, failFastReason)
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "\nRunning clean hooks in %s...\n", name); err != nil {
+			return nil, 0, err
+		}
+		size, err := cleanWorktree(w, wtCfg, mainRepoPath, wt.Path, nestedWorktreePaths(worktrees, wt.Path))
+		if err != nil {
+			report.fail(name, err)
+			if warnErr := writeWarning(w, errors.CodeWarnCleanHooksFailed, "Clean hooks failed in %s: %v",
+				name, err); warnErr != nil {
+				return nil, 0, warnErr
+			}
+			continue
+		}
+		report.succeed(name)
+		freed += size
+	}
+	return report, freed, nil
+}
+
+// cleanWorktree runs the clean hooks of cfg in the worktree at path and returns how much
+// less disk space it takes afterwards, leaving out the worktrees nested in it.
+func cleanWorktree(w io.Writer, cfg *config.Config, mainRepoPath, path string, nested []string) (int64, error) {
+	before := diskUsage(path, nested...)
+	if err := hooks.NewExecutor(cfg, mainRepoPath).ExecuteCleanHooks(w, path); err != nil {
+		return 0, err
+	}
+	return max(before-diskUsage(path, nested...), 0), nil
+}
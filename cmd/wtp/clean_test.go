@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+)
+
+const cleanTestConfig = `version: "1.0"
+defaults:
+  base_dir: ../worktrees
+hooks:
+  clean:
+    - type: command
+      command: "test ! -f fail-here && rm -rf node_modules"
+`
+
+func TestNewCleanCommand(t *testing.T) {
+	cmd := NewCleanCommand()
+
+	assert.Equal(t, "clean", cmd.Name)
+	assert.NotEmpty(t, cmd.Usage)
+	assert.NotNil(t, cmd.Action)
+}
+
+func setupCleanTest(
+	t *testing.T, configYAML string,
+) (mainPath, worktreePath string, mockExec *mockCheckoutCommandExecutor) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+	mainPath, worktreePath, listOutput := setupCheckoutTest(t)
+	require.NoError(t, os.WriteFile(filepath.Join(mainPath, config.ConfigFileName), []byte(configYAML), 0o644))
+	for _, dir := range []string{mainPath, worktreePath} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "node_modules"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "node_modules", "pkg.js"), make([]byte, 2048), 0o644))
+	}
+	return mainPath, worktreePath, &mockCheckoutCommandExecutor{listOutput: listOutput}
+}
+
+func TestCleanCommand_CleansOneWorktree(t *testing.T) {
+	mainPath, worktreePath, mockExec := setupCleanTest(t, cleanTestConfig)
+	cfg, err := config.LoadConfig(mainPath, "")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, cleanCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, mainPath, "feature/foo"))
+
+	assert.NoDirExists(t, filepath.Join(worktreePath, "node_modules"))
+	assert.DirExists(t, filepath.Join(mainPath, "node_modules"), "only the named worktree is cleaned")
+	assert.Contains(t, buf.String(), "Running clean hooks in feature/foo...")
+	assert.Contains(t, buf.String(), "✓ Cleaned feature/foo, freeing 2.0 KiB")
+}
+
+func TestCleanCommand_All(t *testing.T) {
+	mainPath, worktreePath, mockExec := setupCleanTest(t, cleanTestConfig)
+	cfg, err := config.LoadConfig(mainPath, "")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, cleanAllCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, false))
+
+	assert.NoDirExists(t, filepath.Join(mainPath, "node_modules"))
+	assert.NoDirExists(t, filepath.Join(worktreePath, "node_modules"))
+	assert.Contains(t, buf.String(), "✓ Cleaned 2 worktree(s), freeing 4.0 KiB")
+}
+
+func TestCleanCommand_AllReportsFailures(t *testing.T) {
+	mainPath, worktreePath, mockExec := setupCleanTest(t, cleanTestConfig)
+	require.NoError(t, os.WriteFile(filepath.Join(worktreePath, "fail-here"), nil, 0o644))
+	cfg, err := config.LoadConfig(mainPath, "")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = cleanAllCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, false)
+	require.Error(t, err)
+	code, _ := errors.CodeOf(err)
+	assert.Equal(t, errors.CodeCleanHooksFailed, code)
+	assert.Contains(t, err.Error(), "clean hooks failed in 1 worktree(s): feature/foo")
+	assert.Equal(t, errors.ExitPartialFailure, errors.ExitStatus(err))
+	assert.Contains(t, buf.String(), errors.CodeWarnCleanHooksFailed)
+	assert.NoDirExists(t, filepath.Join(mainPath, "node_modules"))
+	assert.DirExists(t, filepath.Join(worktreePath, "node_modules"))
+}
+
+func TestCleanCommand_NoHooks(t *testing.T) {
+	mainPath, _, mockExec := setupCleanTest(t, "version: \"1.0\"\n")
+	cfg, err := config.LoadConfig(mainPath, "")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, cleanCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, mainPath, ""))
+	assert.Contains(t, buf.String(), "No clean hooks configured")
+
+	buf.Reset()
+	require.NoError(t, cleanAllCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, false))
+	assert.Contains(t, buf.String(), "No clean hooks configured")
+	assert.DirExists(t, filepath.Join(mainPath, "node_modules"))
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// writeClipboard copies text to the system clipboard with the first tool of
+// clipboardCommands that is installed.
+func writeClipboard(text string) error {
+	candidates := clipboardCommands()
+	names := make([]string, 0, len(candidates))
+	for _, args := range candidates {
+		names = append(names, args[0])
+		path, err := exec.LookPath(args[0])
+		if err != nil {
+			continue
+		}
+
+		// #nosec G204 -- the clipboard tools are fixed per platform
+		cmd := exec.Command(path, args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%s failed: %w: %s", args[0], err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+	return fmt.Errorf("no clipboard tool found (tried %s)", strings.Join(names, ", "))
+}
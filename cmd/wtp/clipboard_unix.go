@@ -0,0 +1,19 @@
+//go:build !darwin && !windows
+
+package main
+
+import "os"
+
+// clipboardCommands lists the commands that copy their stdin to the clipboard, preferred first:
+// the Wayland tool in a Wayland session, then the X11 tools, then clip.exe under WSL.
+func clipboardCommands() [][]string {
+	commands := [][]string{
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+		{"clip.exe"},
+	}
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		commands = append([][]string{{"wl-copy"}}, commands...)
+	}
+	return commands
+}
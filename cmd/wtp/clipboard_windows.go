@@ -0,0 +1,8 @@
+//go:build windows
+
+package main
+
+// clipboardCommands lists the commands that copy their stdin to the clipboard, preferred first.
+func clipboardCommands() [][]string {
+	return [][]string{{"clip"}}
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+const workspaceFilePermissions = 0o644
+
+// workspaceField is a top-level key of a .code-workspace file with its raw value; the
+// keys are kept in order so that rewriting the folders leaves the rest of the file as
+// it was.
+type workspaceField struct {
+	key   string
+	value json.RawMessage
+}
+
+// workspaceFolder is an entry of the "folders" of a .code-workspace file.
+type workspaceFolder struct {
+	Name string `json:"name,omitempty"`
+	Path string `json:"path,omitempty"`
+	URI  string `json:"uri,omitempty"`
+}
+
+// NewCodeCommand creates the code command definition
+func NewCodeCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "code",
+		Usage: "Open worktrees in VS Code",
+		UsageText: "wtp code <worktree-name>\n" +
+			"   wtp code --workspace [--open]",
+		Description: "Opens a worktree in VS Code, or with --workspace, generates a multi-root " +
+			".code-workspace file with every worktree as a folder, so all active branches show side " +
+			"by side. Running it again updates the folders to the current worktrees and keeps the " +
+			"rest of the file, such as settings, and folders outside the repository's worktrees.\n\n" +
+			"The workspace file is integrations.vscode.workspace_file, \"" +
+			config.DefaultVSCodeWorkspaceFile + "\" by default, and VS Code runs as " +
+			"integrations.vscode.command, \"code\" by default.\n\n" +
+			"Examples:\n" +
+			"  wtp code feature/auth         # Open one worktree\n" +
+			"  wtp code --workspace          # Generate or update the workspace file\n" +
+			"  wtp code --workspace --open   # ... and open it",
+		ArgsUsage: "<worktree-name>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "workspace",
+				Usage: "Generate or update a multi-root workspace file with every worktree",
+			},
+			&cli.BoolFlag{
+				Name:  "open",
+				Usage: "Open the workspace file in VS Code once it is written (with --workspace)",
+			},
+		},
+		ShellComplete: completeWorktreesForCd,
+		Action:        codeCommand,
+	}
+}
+
+func codeCommand(ctx context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	workspace := cmd.Bool("workspace")
+	switch {
+	case workspace && cmd.Args().Len() > 0:
+		return errors.InvalidArguments("--workspace includes every worktree and takes no worktree name")
+	case !workspace && cmd.Bool("open"):
+		return errors.InvalidArguments("--open only applies with --workspace; 'wtp code <worktree-name>' opens a worktree")
+	case !workspace && cmd.Args().Len() != 1:
+		return errors.InvalidArguments(`worktree name is required
+
+Usage: wtp code <worktree-name>
+       wtp code --workspace [--open]
+
+Tip: Run 'wtp list' to see available worktrees`)
+	}
+
+	_, cfg, mainRepoPath, err := setupRepoAndConfig()
+	if err != nil {
+		return err
+	}
+	vscode := &cfg.Integrations.VSCode
+	executor := command.NewRealExecutor()
+
+	if !workspace {
+		path, _, err := openWorktreePath(ctx, w, executor, cfg, cmd.Args().First(), false)
+		if err != nil {
+			return err
+		}
+		return openInEditor(vscode.EditorCommand(), path)
+	}
+
+	result, err := executor.Execute([]command.Command{command.GitWorktreeList()})
+	if err != nil {
+		return errors.GitCommandFailed("git worktree list", err.Error())
+	}
+	worktrees := parseWorktreesFromOutput(result.Results[0].Output)
+	workspaceFile := codeWorkspacePath(cfg, mainRepoPath)
+	if err := writeCodeWorkspace(workspaceFile, worktrees, cfg, mainRepoPath); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "✅ Updated %s with %d worktree(s)\n", workspaceFile, len(worktrees)); err != nil {
+		return err
+	}
+	if cmd.Bool("open") {
+		return openInEditor(vscode.EditorCommand(), workspaceFile)
+	}
+	return nil
+}
+
+// codeWorkspacePath returns the absolute path of the workspace file.
+func codeWorkspacePath(cfg *config.Config, mainRepoPath string) string {
+	path := cfg.ExpandVariables(cfg.Integrations.VSCode.WorkspaceFileTemplate(), mainRepoPath, "")
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(mainRepoPath, path)
+	}
+	return filepath.Clean(path)
+}
+
+// writeCodeWorkspace sets the folders of the workspace file at path to worktrees, keeping
+// its other keys and the folders that are no worktree of the repository. The file is only
+// written when it changes, and never in read-only mode.
+func writeCodeWorkspace(path string, worktrees []git.Worktree, cfg *config.Config, mainRepoPath string) error {
+	if err := ensureWritable(cfg, "write the VS Code workspace"); err != nil {
+		return err
+	}
+	// #nosec G304 -- the workspace file is chosen by the user's configuration
+	original, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	fields, err := parseWorkspaceFields(original)
+	if err != nil {
+		return fmt.Errorf("cannot update %s: %w (comments and trailing commas are not supported)", path, err)
+	}
+	if fields == nil {
+		fields = []workspaceField{{key: "folders"}, {key: "settings", value: json.RawMessage("{}")}}
+	}
+
+	var existing []workspaceFolder
+	index := -1
+	for i := range fields {
+		if fields[i].key != "folders" {
+			continue
+		}
+		index = i
+		if len(fields[i].value) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(fields[i].value, &existing); err != nil {
+			return fmt.Errorf("cannot update %s: invalid folders: %w", path, err)
+		}
+	}
+	folders, err := json.Marshal(workspaceFolders(existing, worktrees, cfg, mainRepoPath, filepath.Dir(path)))
+	if err != nil {
+		return err
+	}
+	if index < 0 {
+		fields = append([]workspaceField{{key: "folders"}}, fields...)
+		index = 0
+	}
+	fields[index].value = folders
+
+	data, err := encodeWorkspaceFields(fields)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(data, original) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), stateDirMode); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	return os.WriteFile(path, data, workspaceFilePermissions)
+}
+
+// workspaceFolders lists a folder for each worktree, named after its branch, followed by
+// the folders of existing that lie outside the repository's worktrees; folders of removed
+// worktrees are dropped. Paths are relative to dir, the workspace file's directory.
+func workspaceFolders(
+	existing []workspaceFolder, worktrees []git.Worktree, cfg *config.Config, mainRepoPath, dir string,
+) []workspaceFolder {
+	folders := make([]workspaceFolder, 0, len(worktrees)+len(existing))
+	worktreePaths := make(map[string]bool, len(worktrees))
+	for i := range worktrees {
+		wt := &worktrees[i]
+		worktreePaths[filepath.Clean(wt.Path)] = true
+		name := wt.Branch
+		if name == "" || name == detachedKeyword {
+			name = getWorktreeNameFromPath(wt.Path, cfg, mainRepoPath, wt.IsMain)
+		}
+		path := wt.Path
+		if rel, err := filepath.Rel(dir, wt.Path); err == nil {
+			path = filepath.ToSlash(rel)
+		}
+		folders = append(folders, workspaceFolder{Name: name, Path: path})
+	}
+
+	for _, folder := range existing {
+		if folder.Path != "" {
+			path := filepath.FromSlash(folder.Path)
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+			path = filepath.Clean(path)
+			if worktreePaths[path] || path == filepath.Clean(mainRepoPath) ||
+				isWorktreeManagedCommon(path, cfg, mainRepoPath, false) {
+				continue
+			}
+		}
+		folders = append(folders, folder)
+	}
+	return folders
+}
+
+// parseWorkspaceFields splits a JSON object into its top-level keys, in order; empty data
+// has none.
+func parseWorkspaceFields(data []byte) ([]workspaceField, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, fmt.Errorf("not a JSON object")
+	}
+	fields := []workspaceField{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := token.(string)
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		fields = append(fields, workspaceField{key: key, value: value})
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// encodeWorkspaceFields writes fields as a JSON object indented with tabs, as VS Code
+// writes workspace files.
+func encodeWorkspaceFields(fields []workspaceField) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{\n")
+	for i, field := range fields {
+		key, err := json.Marshal(field.key)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString("\t")
+		buf.Write(key)
+		buf.WriteString(": ")
+		if err := json.Indent(&buf, field.value, "\t", "\t"); err != nil {
+			return nil, err
+		}
+		if i < len(fields)-1 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes(), nil
+}
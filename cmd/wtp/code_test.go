@@ -0,0 +1,118 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+func TestWriteCodeWorkspace(t *testing.T) {
+	root := t.TempDir()
+	mainRepoPath := filepath.Join(root, "repo")
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+	workspaceFile := codeWorkspacePath(cfg, mainRepoPath)
+	require.Equal(t, filepath.Join(root, "repo.code-workspace"), workspaceFile)
+
+	worktrees := []git.Worktree{
+		{Path: mainRepoPath, Branch: "main", IsMain: true},
+		{Path: filepath.Join(root, "worktrees", "feature", "auth"), Branch: "feature/auth"},
+		{Path: filepath.Join(root, "worktrees", "review"), Branch: detachedKeyword},
+	}
+	require.NoError(t, writeCodeWorkspace(workspaceFile, worktrees, cfg, mainRepoPath))
+	content, err := os.ReadFile(workspaceFile)
+	require.NoError(t, err)
+	assert.Equal(t, `{
+	"folders": [
+		{
+			"name": "main",
+			"path": "repo"
+		},
+		{
+			"name": "feature/auth",
+			"path": "worktrees/feature/auth"
+		},
+		{
+			"name": "review",
+			"path": "worktrees/review"
+		}
+	],
+	"settings": {}
+}
+`, string(content))
+
+	edited := `{"settings": {"editor.tabSize": 2}, "folders": [
+		{"path": "worktrees/review"}, {"name": "docs", "path": "../docs"}, {"uri": "vscode-vfs://github/org/api"}
+	], "launch": {}}`
+	require.NoError(t, os.WriteFile(workspaceFile, []byte(edited), 0o644))
+	require.NoError(t, writeCodeWorkspace(workspaceFile, worktrees[:2], cfg, mainRepoPath))
+	content, err = os.ReadFile(workspaceFile)
+	require.NoError(t, err)
+	assert.Equal(t, `{
+	"settings": {
+		"editor.tabSize": 2
+	},
+	"folders": [
+		{
+			"name": "main",
+			"path": "repo"
+		},
+		{
+			"name": "feature/auth",
+			"path": "worktrees/feature/auth"
+		},
+		{
+			"name": "docs",
+			"path": "../docs"
+		},
+		{
+			"uri": "vscode-vfs://github/org/api"
+		}
+	],
+	"launch": {}
+}
+`, string(content), "the folders of removed worktrees are dropped, other keys and folders are kept in place")
+
+	require.NoError(t, os.WriteFile(workspaceFile, []byte("{\n\t// comment\n}"), 0o644))
+	err = writeCodeWorkspace(workspaceFile, worktrees, cfg, mainRepoPath)
+	assert.ErrorContains(t, err, "comments and trailing commas are not supported")
+}
+
+func TestWriteCodeWorkspace_ReadOnly(t *testing.T) {
+	t.Setenv(readOnlyEnvVar, "1")
+	root := t.TempDir()
+	mainRepoPath := filepath.Join(root, "repo")
+	cfg := &config.Config{}
+	workspaceFile := codeWorkspacePath(cfg, mainRepoPath)
+
+	err := writeCodeWorkspace(workspaceFile, []git.Worktree{{Path: mainRepoPath, Branch: "main", IsMain: true}},
+		cfg, mainRepoPath)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "write the VS Code workspace")
+	assert.NoFileExists(t, workspaceFile)
+}
+
+func TestCodeWorkspacePath(t *testing.T) {
+	cfg := &config.Config{Integrations: config.Integrations{VSCode: config.VSCode{
+		WorkspaceFile: ".vscode/${DIRNAME}.code-workspace",
+	}}}
+	mainRepoPath := filepath.Join(t.TempDir(), "app")
+	assert.Equal(t, filepath.Join(mainRepoPath, ".vscode", "app.code-workspace"), codeWorkspacePath(cfg, mainRepoPath))
+}
+
+func TestNewCodeCommand(t *testing.T) {
+	cmd := NewCodeCommand()
+
+	assert.Equal(t, "code", cmd.Name)
+	var names []string
+	for _, flag := range cmd.Flags {
+		names = append(names, flag.Names()[0])
+	}
+	assert.Equal(t, []string{"workspace", "open"}, names)
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"context"
+	"io"
 	"os"
 	"strings"
 
@@ -17,6 +18,18 @@ func configureCompletionCommand(cmd *cli.Command) {
 		return
 	}
 
+	cmd.Hidden = false
+	cmd.Usage = "Output shell completion script for bash, zsh, fish, or powershell"
+	cmd.ArgsUsage = "<bash|zsh|fish|powershell>"
+	cmd.Description = "Output a completion script for the given shell. The script asks wtp for candidates " +
+		"on every TAB, so subcommands, flags, branch names, and worktree names come straight from git.\n\n" +
+		"Examples:\n" +
+		"  Bash (~/.bashrc):      source <(wtp completion bash)\n" +
+		"  Zsh (~/.zshrc):        source <(wtp completion zsh)\n" +
+		"  Fish:                  wtp completion fish > ~/.config/fish/completions/wtp.fish\n" +
+		"  PowerShell ($PROFILE): wtp completion powershell | Out-String | Invoke-Expression\n\n" +
+		"'wtp shell-init <shell>' emits the same script together with the cd hook."
+
 	cmd.Action = func(ctx context.Context, c *cli.Command) error {
 		writer := c.Writer
 		if writer == nil {
@@ -27,6 +40,17 @@ func configureCompletionCommand(cmd *cli.Command) {
 			}
 		}
 
+		var shell string
+		if args := c.Args(); args != nil && args.Len() > 0 {
+			shell = args.First()
+		}
+
+		// urfave/cli only knows "pwsh" and its script is not dynamic, so PowerShell is generated here
+		if isPowerShell(shell) {
+			_, err := io.WriteString(writer, buildPowerShellCompletionScript())
+			return err
+		}
+
 		var buf bytes.Buffer
 		c.Writer = &buf
 
@@ -37,11 +61,6 @@ func configureCompletionCommand(cmd *cli.Command) {
 
 		c.Writer = writer
 
-		var shell string
-		if args := c.Args(); args != nil && args.Len() > 0 {
-			shell = args.First()
-		}
-
 		script := patchCompletionScript(shell, buf.String())
 		_, err := writer.Write([]byte(script))
 		return err
@@ -56,6 +75,8 @@ func patchCompletionScript(shell, script string) string {
 		return patchBashCompletionScript(script)
 	case "zsh":
 		return patchZshCompletionScript(script)
+	case "pwsh", "powershell":
+		return buildPowerShellCompletionScript()
 	default:
 		return script
 	}
@@ -140,6 +161,56 @@ complete -c wtp -f -a '(__fish_wtp_dynamic_complete)'
 `
 }
 
+func isPowerShell(shell string) bool {
+	return shell == "pwsh" || shell == "powershell"
+}
+
+func buildPowerShellCompletionScript() string {
+	return `# wtp PowerShell completion
+
+Register-ArgumentCompleter -Native -CommandName wtp -ScriptBlock {
+	param($wordToComplete, $commandAst, $cursorPosition)
+
+	$words = @($commandAst.CommandElements |
+		Where-Object { $_.Extent.EndOffset -le $cursorPosition } |
+		ForEach-Object { $_.Extent.Text })
+	$arguments = @()
+	if ($words.Count -gt 1) {
+		$arguments = @($words[1..($words.Count - 1)])
+	}
+	if ($wordToComplete -and -not $wordToComplete.StartsWith('-') -and $arguments.Count -gt 0) {
+		$arguments = @($arguments | Select-Object -SkipLast 1)
+	}
+	$arguments += '--generate-shell-completion'
+
+	$env:WTP_SHELL_COMPLETION = '1'
+	try {
+		$raw = & wtp @arguments 2>$null
+	} finally {
+		Remove-Item Env:WTP_SHELL_COMPLETION -ErrorAction SilentlyContinue
+	}
+
+	foreach ($line in $raw) {
+		if (-not $line) {
+			continue
+		}
+
+		$value = $line
+		$tooltip = $line
+		$parts = $line -split ':', 2
+		if ($parts.Count -gt 1 -and $parts[1].Contains(' ')) {
+			$value = $parts[0]
+			$tooltip = $parts[1]
+		}
+
+		if ($value -like "$wordToComplete*") {
+			[System.Management.Automation.CompletionResult]::new($value, $value, 'ParameterValue', $tooltip)
+		}
+	}
+}
+`
+}
+
 func patchBashCompletionScript(script string) string {
 	if strings.Contains(script, "_wtp_sanitize_completion_list") {
 		return script
@@ -8,6 +8,8 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/urfave/cli/v3"
 )
 
 func TestNormalizeCompletionArgs(t *testing.T) {
@@ -103,6 +105,38 @@ func TestPatchCompletionScriptFishMatchesGolden(t *testing.T) {
 	assertCompletionGolden(t, "fish_expected.fish", got)
 }
 
+func TestPowerShellCompletionIsDynamic(t *testing.T) {
+	script := buildPowerShellCompletionScript()
+
+	for _, want := range []string{
+		"Register-ArgumentCompleter -Native -CommandName wtp",
+		"$env:WTP_SHELL_COMPLETION = '1'",
+		"--generate-shell-completion",
+	} {
+		if !strings.Contains(script, want) {
+			t.Fatalf("expected PowerShell completion to contain %q, got:\n%s", want, script)
+		}
+	}
+}
+
+func TestCompletionCommandIsListed(t *testing.T) {
+	var completion *cli.Command
+	app := newApp()
+	app.Writer = io.Discard
+	if err := app.Run(context.Background(), []string{"wtp", "--help"}); err != nil {
+		t.Fatalf("wtp --help failed: %v", err)
+	}
+	for _, cmd := range app.Commands {
+		if cmd.Name == "completion" {
+			completion = cmd
+		}
+	}
+
+	if completion == nil || completion.Hidden {
+		t.Fatalf("expected a visible completion command, got %+v", completion)
+	}
+}
+
 func TestPatchCompletionScriptPassthroughForOtherShells(t *testing.T) {
 	original := "original-script"
 
@@ -119,6 +153,8 @@ func TestCompletionCommandMatchesGolden(t *testing.T) {
 		{shell: "bash", file: "bash_expected.sh"},
 		{shell: "fish", file: "fish_expected.fish"},
 		{shell: "zsh", file: "zsh_expected.zsh"},
+		{shell: "powershell", file: "powershell_expected.ps1"},
+		{shell: "pwsh", file: "powershell_expected.ps1"},
 	}
 
 	for _, tc := range cases {
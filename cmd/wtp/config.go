@@ -0,0 +1,744 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+	"go.yaml.in/yaml/v3"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+// configSchemaFileMode is the mode of schema files written by 'wtp config schema'; unlike
+// configuration files they are meant to be committed and shared.
+const configSchemaFileMode = 0o644
+
+// configLayer is a configuration file 'wtp config' works on.
+type configLayer struct {
+	// name is how messages refer to the file, e.g. ".wtp.yml" or "~/.wtp.yml".
+	name string
+	path string
+}
+
+// NewConfigCommand creates the config command definition
+func NewConfigCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "config",
+		Usage: "Manage the configuration files",
+		Commands: []*cli.Command{
+			{
+				Name:  "migrate",
+				Usage: "Rewrite configuration files written for an older version",
+				Description: "Upgrades ~/.wtp.yml, .wtp.yml, and .wtp.local.yml to the current configuration " +
+					"version, renaming fields that changed, and sets their 'version'. Files are migrated in " +
+					"memory whenever they are loaded, so this only makes the change permanent. Comments " +
+					"are kept; JSON and TOML files are left for you to edit by hand. Version 1.0 is the " +
+					"only version so far, so there is nothing to migrate yet.\n\n" +
+					"Examples:\n" +
+					"  wtp config migrate --dry-run  # Show what would change\n" +
+					"  wtp config migrate            # Rewrite the files",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Show the changes without writing them",
+					},
+				},
+				Action: configMigrateCommand,
+			},
+			{
+				Name:      "get",
+				Usage:     "Print the value of a setting",
+				ArgsUsage: "<key>",
+				Description: "Prints the effective value of a dotted key, after merging ~/.wtp.yml, .wtp.yml, " +
+					"and .wtp.local.yml and applying defaults. A section prints as YAML. With --global, " +
+					"--repo, or --local, only that file is read.\n\n" +
+					"Examples:\n" +
+					"  wtp config get defaults.base_dir\n" +
+					"  wtp config get hooks.post_create.0.command\n" +
+					"  wtp config get --local defaults.env",
+				Flags:  configLayerFlags(),
+				Action: configGetCommand,
+			},
+			{
+				Name:      "set",
+				Usage:     "Set a setting in one configuration file",
+				ArgsUsage: "<key> <value>",
+				Description: "Writes a dotted key to .wtp.yml, or to ~/.wtp.yml with --global or " +
+					".wtp.local.yml with --local, creating the file when needed. The value is checked " +
+					"against the setting's type; lists of strings are given comma-separated. Comments " +
+					"in the file are kept, and a change that would make the configuration invalid is " +
+					"not written. Hooks and branch overlays are edited by hand.\n\n" +
+					"Examples:\n" +
+					"  wtp config set defaults.base_dir ../worktrees\n" +
+					"  wtp config set --local defaults.env.PORT 3001\n" +
+					"  wtp config set --global policy.required_branch_prefixes feature/,fix/",
+				Flags:  configLayerFlags(),
+				Action: configSetCommand,
+			},
+			{
+				Name:      "unset",
+				Usage:     "Remove a setting from one configuration file",
+				ArgsUsage: "<key>",
+				Description: "Removes a dotted key, or a whole section, from .wtp.yml, or from ~/.wtp.yml " +
+					"with --global or .wtp.local.yml with --local.\n\n" +
+					"Examples:\n" +
+					"  wtp config unset defaults.hook_timeout\n" +
+					"  wtp config unset --local defaults.env",
+				Flags:  configLayerFlags(),
+				Action: configUnsetCommand,
+			},
+			{
+				Name:  "list",
+				Usage: "List the settings of every configuration file",
+				Description: "Lists each setting as key=value, prefixed by the file that sets it. With " +
+					"--effective, lists the merged configuration wtp uses instead, including defaults. " +
+					"--origin does the same and prefixes each value with the file it came from, like " +
+					"'git config --show-origin': hooks and other list items name the file that added " +
+					"them, and values no file sets are marked 'default'.\n\n" +
+					"Examples:\n" +
+					"  wtp config list              # Every file, in the order they are merged\n" +
+					"  wtp config list --local      # Only .wtp.local.yml\n" +
+					"  wtp config list --effective  # What wtp actually uses\n" +
+					"  wtp config list --origin     # ...and where each value comes from",
+				Flags: append(configLayerFlags(),
+					&cli.BoolFlag{
+						Name:  "effective",
+						Usage: "List the merged configuration, including defaults",
+					},
+					&cli.BoolFlag{
+						Name:  "origin",
+						Usage: "List the merged configuration with the file each value comes from",
+					},
+				),
+				Action: configListCommand,
+			},
+			{
+				Name:      "validate",
+				Usage:     "Check configuration files for errors and unknown keys",
+				ArgsUsage: "[<path>]",
+				Description: "Checks ~/.wtp.yml, .wtp.yml, and .wtp.local.yml one by one and then merged, " +
+					"or only the file at <path>. Besides the errors that stop wtp from loading a file, " +
+					"keys that name no setting are reported with their line and column, whether or not " +
+					"strict mode is on. Exits with an error when anything was found, for use in CI.\n\n" +
+					"Examples:\n" +
+					"  wtp config validate\n" +
+					"  wtp config validate configs/team.wtp.yml",
+				Action: configValidateCommand,
+			},
+			{
+				Name:  "schema",
+				Usage: "Print a JSON Schema for configuration files",
+				Description: "Prints a JSON Schema describing .wtp.yml and the other configuration files, " +
+					"so editors can complete keys and flag misspelled ones. With the YAML language " +
+					"server, e.g. in VS Code, reference it from the first line of .wtp.yml:\n\n" +
+					"  # yaml-language-server: $schema=./.wtp.schema.json\n\n" +
+					"Examples:\n" +
+					"  wtp config schema -o .wtp.schema.json",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "Write the schema to this file instead of standard output",
+					},
+				},
+				Action: configSchemaCommand,
+			},
+		},
+	}
+}
+
+// configLayerFlags selects the configuration file 'wtp config' reads or edits.
+func configLayerFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{Name: "global", Usage: "Use ~/.wtp.yml"},
+		&cli.BoolFlag{Name: "repo", Usage: "Use .wtp.yml (the default for set and unset)"},
+		&cli.BoolFlag{Name: "local", Usage: "Use .wtp.local.yml"},
+	}
+}
+
+func configMigrateCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+	_, cfg, mainRepoPath, err := setupRepoAndConfig()
+	if err != nil {
+		return err
+	}
+	dryRun := cmd.Bool("dry-run")
+	if !dryRun {
+		if err := ensureWritable(cfg, "migrate configuration files"); err != nil {
+			return err
+		}
+	}
+
+	layers, err := configLayers(mainRepoPath)
+	if err != nil {
+		return err
+	}
+	for _, layer := range layers {
+		if err := migrateConfigFile(w, layer, dryRun); err != nil {
+			return fmt.Errorf("failed to migrate %s: %w", layer.name, err)
+		}
+	}
+	return nil
+}
+
+// configLayers returns the configuration files LoadConfig merges, in that order, whether
+// they exist or not.
+func configLayers(mainRepoPath string) ([]configLayer, error) {
+	var layers []configLayer
+	if home, err := os.UserHomeDir(); err == nil {
+		layer, err := findConfigLayer(home, config.ConfigFileName, config.GlobalSourcePrefix)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, layer)
+	}
+	for _, name := range []string{config.ConfigFileName, config.LocalConfigFileName} {
+		layer, err := findConfigLayer(mainRepoPath, name, "")
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, layer)
+	}
+	return layers, nil
+}
+
+// findConfigLayer finds the configuration file named like name in dir; namePrefix is
+// prepended to its name in messages.
+func findConfigLayer(dir, name, namePrefix string) (configLayer, error) {
+	path, err := config.FindConfigFile(dir, name)
+	if err != nil {
+		return configLayer{}, err
+	}
+	return configLayer{name: namePrefix + filepath.Base(path), path: path}, nil
+}
+
+// selectedConfigLayer returns the configuration file chosen with --global, --repo, or
+// --local, and false when none was given.
+func selectedConfigLayer(cmd *cli.Command, mainRepoPath string) (configLayer, bool, error) {
+	var chosen []string
+	for _, flag := range []string{"global", "repo", "local"} {
+		if cmd.Bool(flag) {
+			chosen = append(chosen, "--"+flag)
+		}
+	}
+	if len(chosen) > 1 {
+		return configLayer{}, false, errors.InvalidArguments("%s cannot be used together", strings.Join(chosen, " and "))
+	}
+
+	var layer configLayer
+	var err error
+	switch {
+	case cmd.Bool("global"):
+		home, homeErr := os.UserHomeDir()
+		if homeErr != nil {
+			return configLayer{}, false, fmt.Errorf("failed to find the home directory: %w", homeErr)
+		}
+		layer, err = findConfigLayer(home, config.ConfigFileName, config.GlobalSourcePrefix)
+	case cmd.Bool("local"):
+		layer, err = findConfigLayer(mainRepoPath, config.LocalConfigFileName, "")
+	case cmd.Bool("repo"):
+		layer, err = findConfigLayer(mainRepoPath, config.ConfigFileName, "")
+	default:
+		return configLayer{}, false, nil
+	}
+	return layer, err == nil, err
+}
+
+// migrateConfigFile upgrades one configuration file to config.CurrentVersion and reports
+// what changed. A missing file is skipped.
+func migrateConfigFile(w io.Writer, layer configLayer, dryRun bool) error {
+	// #nosec G304 -- the path is one of the configuration files
+	data, err := os.ReadFile(layer.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	doc, err := config.ParseConfigDocument(layer.path, data)
+	if err != nil {
+		return err
+	}
+	result, err := config.Migrate(doc)
+	if err != nil {
+		return err
+	}
+	if !result.Migrated() {
+		_, err := fmt.Fprintf(w, "✓ %s is up to date (version %s)\n", layer.name, config.CurrentVersion)
+		return err
+	}
+
+	yamlFile := config.IsYAMLConfigFile(layer.path)
+	switch {
+	case !yamlFile:
+		_, err = fmt.Fprintf(w, "%s (version %s) needs these changes; wtp only rewrites YAML, so edit it by hand:\n",
+			layer.name, result.From)
+	case dryRun:
+		_, err = fmt.Fprintf(w, "Would migrate %s from version %s to %s:\n", layer.name, result.From,
+			config.CurrentVersion)
+	default:
+		_, err = fmt.Fprintf(w, "Migrated %s from version %s to %s:\n", layer.name, result.From, config.CurrentVersion)
+	}
+	if err != nil {
+		return err
+	}
+	for _, change := range append(result.Changes, "set version to "+config.CurrentVersion) {
+		if _, err := fmt.Fprintf(w, "  • %s\n", change); err != nil {
+			return err
+		}
+	}
+	if !yamlFile || dryRun {
+		return nil
+	}
+	return writeConfigDocument(layer.path, doc)
+}
+
+// writeConfigDocument replaces the YAML file at path with doc, keeping its permissions.
+func writeConfigDocument(path string, doc *yaml.Node) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(configYAMLIndent)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, out.Bytes(), info.Mode().Perm())
+}
+
+func configGetCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+	if cmd.Args().Len() != 1 {
+		return errors.InvalidArguments("expected one key; usage: wtp config get [--global | --repo | --local] <key>")
+	}
+	key := cmd.Args().First()
+	mainRepoPath, err := configRepoPath()
+	if err != nil {
+		return err
+	}
+	layer, selected, err := selectedConfigLayer(cmd, mainRepoPath)
+	if err != nil {
+		return err
+	}
+
+	source := "the configuration"
+	var doc *yaml.Node
+	if selected {
+		source = layer.name
+		doc, err = readConfigLayer(layer)
+	} else {
+		doc, err = effectiveConfigDocument(mainRepoPath)
+	}
+	if err != nil {
+		return err
+	}
+
+	node := config.LookupKey(doc, key)
+	if node == nil {
+		if err := config.ValidateKey(key); stderrors.Is(err, config.ErrUnknownKey) {
+			return err
+		}
+		return fmt.Errorf("%s is not set in %s", key, source)
+	}
+	if node.Kind == yaml.ScalarNode {
+		_, err = fmt.Fprintln(w, node.Value)
+		return err
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(configYAMLIndent)
+	if err := enc.Encode(node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func configSetCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+	if cmd.Args().Len() != 2 { //nolint:mnd // key and value
+		return errors.InvalidArguments(
+			"expected a key and a value; usage: wtp config set [--global | --repo | --local] <key> <value>")
+	}
+	key, value := cmd.Args().Get(0), cmd.Args().Get(1)
+	layer, mainRepoPath, err := editableConfigLayer(cmd, "change configuration files")
+	if err != nil {
+		return err
+	}
+	doc, err := readConfigLayer(layer)
+	if err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 {
+		// A new file is written for the current version
+		if err := config.SetKey(doc, "version", config.CurrentVersion); err != nil {
+			return err
+		}
+	}
+	if err := config.SetKey(doc, key, value); err != nil {
+		return err
+	}
+	if err := saveConfigLayer(layer, doc, mainRepoPath); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "✓ Set %s in %s\n", key, layer.name)
+	return err
+}
+
+func configUnsetCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+	if cmd.Args().Len() != 1 {
+		return errors.InvalidArguments("expected one key; usage: wtp config unset [--global | --repo | --local] <key>")
+	}
+	key := cmd.Args().First()
+	layer, mainRepoPath, err := editableConfigLayer(cmd, "change configuration files")
+	if err != nil {
+		return err
+	}
+	doc, err := readConfigLayer(layer)
+	if err != nil {
+		return err
+	}
+	removed, err := config.UnsetKey(doc, key)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		_, err = fmt.Fprintf(w, "%s is not set in %s\n", key, layer.name)
+		return err
+	}
+	if err := saveConfigLayer(layer, doc, mainRepoPath); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "✓ Unset %s in %s\n", key, layer.name)
+	return err
+}
+
+func configListCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+	mainRepoPath, err := configRepoPath()
+	if err != nil {
+		return err
+	}
+	layer, selected, err := selectedConfigLayer(cmd, mainRepoPath)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Bool("effective") || cmd.Bool("origin") {
+		if selected {
+			flag := "--effective"
+			if cmd.Bool("origin") {
+				flag = "--origin"
+			}
+			return errors.InvalidArguments("%s lists the merged configuration and cannot be used with a file flag", flag)
+		}
+		return listEffectiveConfig(w, mainRepoPath, cmd.Bool("origin"))
+	}
+
+	layers := []configLayer{layer}
+	if !selected {
+		if layers, err = configLayers(mainRepoPath); err != nil {
+			return err
+		}
+	}
+	for _, layer := range layers {
+		doc, err := readConfigLayer(layer)
+		if err != nil {
+			return err
+		}
+		prefix := layer.name + "\t"
+		if selected {
+			prefix = ""
+		}
+		if err := writeSettings(w, prefix, config.FlattenSettings(doc)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeSettings(w io.Writer, prefix string, settings []config.Setting) error {
+	for _, setting := range settings {
+		if _, err := fmt.Fprintf(w, "%s%s=%s\n", prefix, setting.Key, setting.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listEffectiveConfig lists the merged configuration, with the origin of each value when
+// withOrigin is set.
+func listEffectiveConfig(w io.Writer, mainRepoPath string, withOrigin bool) error {
+	cfg, doc, err := effectiveConfig(mainRepoPath)
+	if err != nil {
+		return err
+	}
+	if withOrigin {
+		return writeSettingOrigins(w, cfg, config.FlattenSettings(doc))
+	}
+	return writeSettings(w, "", config.FlattenSettings(doc))
+}
+
+// writeSettingOrigins lists settings of the merged configuration cfg, each prefixed by the
+// file it came from, or by "default" when no file sets it.
+func writeSettingOrigins(w io.Writer, cfg *config.Config, settings []config.Setting) error {
+	for _, setting := range settings {
+		origin, ok := cfg.Origin(setting.Key)
+		if !ok {
+			origin = "default"
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s=%s\n", origin, setting.Key, setting.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configRepoPath returns the main worktree of the repository in the current directory
+// without loading its configuration, which 'wtp config' may be about to repair.
+func configRepoPath() (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", errors.DirectoryAccessFailed("access current", ".", err)
+	}
+	repo, err := git.NewRepository(cwd)
+	if err != nil {
+		return "", errors.NotInGitRepository()
+	}
+	mainRepoPath, err := repo.GetMainWorktreePath()
+	if err != nil {
+		return repo.Path(), nil
+	}
+	return mainRepoPath, nil
+}
+
+// editableConfigLayer returns the configuration file set and unset change: the one chosen
+// with a flag, .wtp.yml by default. Only YAML files are rewritten.
+func editableConfigLayer(cmd *cli.Command, action string) (configLayer, string, error) {
+	mainRepoPath, err := configRepoPath()
+	if err != nil {
+		return configLayer{}, "", err
+	}
+	if err := ensureWritableRepo(mainRepoPath, action); err != nil {
+		return configLayer{}, "", err
+	}
+	layer, selected, err := selectedConfigLayer(cmd, mainRepoPath)
+	if err != nil {
+		return configLayer{}, "", err
+	}
+	if !selected {
+		if layer, err = findConfigLayer(mainRepoPath, config.ConfigFileName, ""); err != nil {
+			return configLayer{}, "", err
+		}
+	}
+	if !config.IsYAMLConfigFile(layer.path) {
+		return configLayer{}, "", fmt.Errorf("wtp only rewrites YAML; edit %s by hand", layer.name)
+	}
+	return layer, mainRepoPath, nil
+}
+
+// readConfigLayer parses a configuration file into a YAML node tree migrated to the
+// current version. A missing file is an empty document.
+func readConfigLayer(layer configLayer) (*yaml.Node, error) {
+	// #nosec G304 -- the path is one of the configuration files
+	data, err := os.ReadFile(layer.path)
+	if os.IsNotExist(err) {
+		return &yaml.Node{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	doc, err := config.ParseConfigDocument(layer.path, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", layer.name, err)
+	}
+	if _, err := config.Migrate(doc); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", layer.name, err)
+	}
+	return doc, nil
+}
+
+// effectiveConfigDocument returns the configuration LoadConfig merges as a YAML node tree.
+func effectiveConfigDocument(mainRepoPath string) (*yaml.Node, error) {
+	_, doc, err := effectiveConfig(mainRepoPath)
+	return doc, err
+}
+
+// effectiveConfig returns the configuration LoadConfig merges, and it as a YAML node tree.
+func effectiveConfig(mainRepoPath string) (cfg *config.Config, doc *yaml.Node, err error) {
+	cfg, err = config.LoadConfig(mainRepoPath, "")
+	if err != nil {
+		return nil, nil, errors.ConfigLoadFailed(filepath.Join(mainRepoPath, config.ConfigFileName), err)
+	}
+	doc = &yaml.Node{}
+	if err := doc.Encode(cfg); err != nil {
+		return nil, nil, err
+	}
+	return cfg, doc, nil
+}
+
+// saveConfigLayer writes doc to the file of layer, unless the merged configuration would
+// then fail to load; the file is left as it was in that case.
+func saveConfigLayer(layer configLayer, doc *yaml.Node, mainRepoPath string) error {
+	// #nosec G304 -- the path is one of the configuration files
+	original, readErr := os.ReadFile(layer.path)
+	if readErr != nil && !os.IsNotExist(readErr) {
+		return readErr
+	}
+	if os.IsNotExist(readErr) {
+		if err := os.WriteFile(layer.path, nil, configFileMode); err != nil {
+			return err
+		}
+	}
+	if err := writeConfigDocument(layer.path, doc); err != nil {
+		return err
+	}
+
+	if _, err := config.LoadConfig(mainRepoPath, ""); err != nil {
+		if os.IsNotExist(readErr) {
+			_ = os.Remove(layer.path)
+		} else {
+			_ = os.WriteFile(layer.path, original, configFileMode)
+		}
+		return fmt.Errorf("%s was not changed: %w", layer.name, err)
+	}
+	return nil
+}
+
+func configValidateCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+	if cmd.Args().Len() > 1 {
+		return errors.InvalidArguments("expected at most one path; usage: wtp config validate [<path>]")
+	}
+
+	problems := 0
+	if path := cmd.Args().First(); path != "" {
+		n, err := validateConfigFile(w, configLayer{name: path, path: path})
+		if err != nil {
+			return err
+		}
+		problems += n
+	} else {
+		mainRepoPath, err := configRepoPath()
+		if err != nil {
+			return err
+		}
+		if problems, err = validateConfigLayers(w, mainRepoPath); err != nil {
+			return err
+		}
+	}
+	if problems > 0 {
+		return fmt.Errorf("found %d problem(s) in the configuration", problems)
+	}
+	return nil
+}
+
+// validateConfigLayers checks every configuration file that exists, then the merged
+// configuration for errors spanning files. It returns how many problems it reported.
+func validateConfigLayers(w io.Writer, mainRepoPath string) (int, error) {
+	layers, err := configLayers(mainRepoPath)
+	if err != nil {
+		return 0, err
+	}
+	problems := 0
+	for _, layer := range layers {
+		if _, err := os.Stat(layer.path); os.IsNotExist(err) {
+			continue
+		}
+		n, err := validateConfigFile(w, layer)
+		if err != nil {
+			return 0, err
+		}
+		problems += n
+	}
+	if problems > 0 {
+		return problems, nil // the merged configuration would repeat them
+	}
+
+	if _, err := config.LoadConfig(mainRepoPath, ""); err != nil {
+		_, writeErr := fmt.Fprintf(w, "✗ merged configuration: %v\n", err)
+		return 1, writeErr
+	}
+	_, err = fmt.Fprintln(w, "✓ merged configuration")
+	return 0, err
+}
+
+// validateConfigFile checks the configuration file of layer on its own, printing its error
+// and unknown keys, and returns how many problems it reported.
+func validateConfigFile(w io.Writer, layer configLayer) (int, error) {
+	unknownKeys, err := config.CheckConfigFile(layer.path)
+	var problems []string
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("%s: %v", layer.name, err))
+	}
+	for _, key := range unknownKeys {
+		key.File = layer.name
+		problems = append(problems, key.String())
+	}
+
+	if len(problems) == 0 {
+		_, err := fmt.Fprintf(w, "✓ %s\n", layer.name)
+		return 0, err
+	}
+	for _, problem := range problems {
+		if _, err := fmt.Fprintf(w, "✗ %s\n", problem); err != nil {
+			return 0, err
+		}
+	}
+	return len(problems), nil
+}
+
+func configSchemaCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+	data, err := json.MarshalIndent(config.Schema(), "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	output := cmd.String("output")
+	if output == "" {
+		_, err = w.Write(data)
+		return err
+	}
+	if err := os.WriteFile(output, data, configSchemaFileMode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+	_, err = fmt.Fprintf(w, "✓ Wrote the configuration schema to %s\n", output)
+	return err
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func TestNewConfigCommand(t *testing.T) {
+	cmd := NewConfigCommand()
+
+	assert.Equal(t, "config", cmd.Name)
+	var names []string
+	for _, sub := range cmd.Commands {
+		names = append(names, sub.Name)
+	}
+	assert.Equal(t, []string{"migrate", "get", "set", "unset", "list", "validate", "schema"}, names)
+}
+
+func TestMigrateConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".wtp.yml")
+	original := "# shared settings\nversion: \"1.0\"\ndefaults:\n  readonly: true\n"
+	require.NoError(t, os.WriteFile(path, []byte(original), 0o640))
+	layer := configLayer{name: ".wtp.yml", path: path}
+
+	var buf bytes.Buffer
+	require.NoError(t, migrateConfigFile(&buf, layer, false))
+	assert.Equal(t, "✓ .wtp.yml is up to date (version "+config.CurrentVersion+")\n", buf.String())
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(content), "readonly is kept as it is")
+
+	require.NoError(t, os.WriteFile(path, []byte("version: \"9.0\"\n"), 0o640))
+	err = migrateConfigFile(&buf, layer, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "upgrade wtp")
+}
+
+func TestMigrateConfigFile_JSONAndMissing(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".wtp.local.json")
+	original := `{"defaults": {"readonly": true}}`
+	require.NoError(t, os.WriteFile(path, []byte(original), 0o600))
+
+	var buf bytes.Buffer
+	require.NoError(t, migrateConfigFile(&buf, configLayer{name: ".wtp.local.json", path: path}, false))
+	assert.Contains(t, buf.String(), ".wtp.local.json is up to date")
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(content))
+
+	buf.Reset()
+	missing := configLayer{name: ".wtp.yml", path: filepath.Join(dir, ".wtp.yml")}
+	require.NoError(t, migrateConfigFile(&buf, missing, false))
+	assert.Empty(t, buf.String())
+}
+
+// setupConfigCommandTest changes into a new repository with its own home directory.
+func setupConfigCommandTest(t *testing.T) (repo, home string) {
+	repo, home = t.TempDir(), t.TempDir()
+	if err := exec.Command("git", "init", "-q", repo).Run(); err != nil {
+		t.Skip("git not available")
+	}
+	t.Chdir(repo)
+	t.Setenv("HOME", home)
+	t.Setenv(readOnlyEnvVar, "")
+	return repo, home
+}
+
+// runConfigCommand runs 'wtp config' with args and returns its output.
+func runConfigCommand(args ...string) (string, error) {
+	var buf bytes.Buffer
+	app := &cli.Command{Name: "wtp", Writer: &buf, Commands: []*cli.Command{NewConfigCommand()}}
+	err := app.Run(context.Background(), append([]string{"wtp", "config"}, args...))
+	return buf.String(), err
+}
+
+func TestConfigSetGetUnset(t *testing.T) {
+	repo, home := setupConfigCommandTest(t)
+	original := "# team settings\nversion: \"1.0\"\ndefaults:\n  base_dir: ../wt # sibling\n"
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ".wtp.yml"), []byte(original), 0o644))
+
+	_, err := runConfigCommand("set", "defaults.base_dir", "../worktrees")
+	require.NoError(t, err)
+	_, err = runConfigCommand("set", "--local", "defaults.env.PORT", "3001")
+	require.NoError(t, err)
+	_, err = runConfigCommand("set", "--global", "defaults.readonly", "false")
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(repo, ".wtp.yml"))
+	require.NoError(t, err)
+	assert.Equal(t, "# team settings\nversion: \"1.0\"\ndefaults:\n  base_dir: ../worktrees # sibling\n",
+		string(content))
+	content, err = os.ReadFile(filepath.Join(repo, ".wtp.local.yml"))
+	require.NoError(t, err)
+	assert.Equal(t, "version: \"1.0\"\ndefaults:\n  env:\n    PORT: \"3001\"\n", string(content))
+	assert.FileExists(t, filepath.Join(home, ".wtp.yml"))
+
+	out, err := runConfigCommand("get", "defaults.base_dir")
+	require.NoError(t, err)
+	assert.Equal(t, "../worktrees\n", out)
+	out, err = runConfigCommand("get", "defaults.env")
+	require.NoError(t, err)
+	assert.Equal(t, "PORT: \"3001\"\n", out)
+	_, err = runConfigCommand("get", "--local", "defaults.base_dir")
+	assert.EqualError(t, err, "defaults.base_dir is not set in .wtp.local.yml")
+	_, err = runConfigCommand("get", "defaults.basedir")
+	assert.EqualError(t, err, "unknown key 'defaults.basedir'")
+
+	out, err = runConfigCommand("list")
+	require.NoError(t, err)
+	assert.Contains(t, out, "~/.wtp.yml\tdefaults.readonly=false\n")
+	assert.Contains(t, out, ".wtp.yml\tdefaults.base_dir=../worktrees\n")
+	assert.Contains(t, out, ".wtp.local.yml\tdefaults.env.PORT=3001\n")
+	out, err = runConfigCommand("list", "--effective")
+	require.NoError(t, err)
+	assert.Contains(t, out, "defaults.base_dir=../worktrees\ndefaults.env.PORT=3001\n")
+
+	out, err = runConfigCommand("unset", "--local", "defaults.env.PORT")
+	require.NoError(t, err)
+	assert.Equal(t, "✓ Unset defaults.env.PORT in .wtp.local.yml\n", out)
+	content, err = os.ReadFile(filepath.Join(repo, ".wtp.local.yml"))
+	require.NoError(t, err)
+	assert.Equal(t, "version: \"1.0\"\n", string(content))
+}
+
+func TestConfigListOrigin(t *testing.T) {
+	repo, home := setupConfigCommandTest(t)
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".wtp.yml"),
+		[]byte("hooks:\n  post_create:\n    - type: copy\n      from: .env\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ".wtp.yml"),
+		[]byte("hooks:\n  post_create:\n    - type: command\n      command: npm ci\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ".wtp.local.yml"),
+		[]byte("defaults:\n  env:\n    PORT: \"3001\"\n"), 0o644))
+
+	out, err := runConfigCommand("list", "--origin")
+	require.NoError(t, err)
+	assert.Contains(t, out, "default\tdefaults.base_dir=")
+	assert.Contains(t, out, ".wtp.local.yml\tdefaults.env.PORT=3001\n")
+	assert.Contains(t, out, "~/.wtp.yml\thooks.post_create.0.from=.env\n~/.wtp.yml\thooks.post_create.0.to=.env\n")
+	assert.Contains(t, out, ".wtp.yml\thooks.post_create.1.command=npm ci\n")
+
+	_, err = runConfigCommand("list", "--origin", "--local")
+	assert.EqualError(t, err, "--origin lists the merged configuration and cannot be used with a file flag")
+}
+
+func TestConfigSet_Rejected(t *testing.T) {
+	repo, _ := setupConfigCommandTest(t)
+
+	_, err := runConfigCommand("set", "defaults.hook_timeout", "soon")
+	assert.ErrorContains(t, err, ".wtp.yml was not changed: invalid configuration")
+	assert.NoFileExists(t, filepath.Join(repo, ".wtp.yml"), "a new file is removed again")
+
+	original := "defaults:\n  base_dir: ../wt\n"
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ".wtp.yml"), []byte(original), 0o644))
+	_, err = runConfigCommand("set", "map.format", "xml")
+	assert.ErrorContains(t, err, "unsupported format 'xml'")
+	content, err := os.ReadFile(filepath.Join(repo, ".wtp.yml"))
+	require.NoError(t, err)
+	assert.Equal(t, original, string(content))
+
+	_, err = runConfigCommand("set", "--repo", "--local", "defaults.base_dir", "x")
+	assert.EqualError(t, err, "--repo and --local cannot be used together")
+	_, err = runConfigCommand("set", "hooks.post_create", "x")
+	assert.ErrorContains(t, err, "is a section")
+
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ".wtp.local.json"), []byte("{}"), 0o644))
+	_, err = runConfigCommand("set", "--local", "defaults.base_dir", "x")
+	assert.EqualError(t, err, "wtp only rewrites YAML; edit .wtp.local.json by hand")
+}
+
+func TestConfigValidate(t *testing.T) {
+	repo, _ := setupConfigCommandTest(t)
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ".wtp.yml"), []byte("defaults:\n  base_dir: ../wt\n"), 0o644))
+
+	out, err := runConfigCommand("validate")
+	require.NoError(t, err)
+	assert.Equal(t, "✓ .wtp.yml\n✓ merged configuration\n", out)
+
+	local := "defaults:\n  hook_timeot: 1m\n"
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ".wtp.local.yml"), []byte(local), 0o644))
+	out, err = runConfigCommand("validate")
+	assert.EqualError(t, err, "found 1 problem(s) in the configuration")
+	assert.Equal(t, "✓ .wtp.yml\n"+
+		"✗ .wtp.local.yml:2:3: unknown key 'defaults.hook_timeot' (did you mean 'hook_timeout'?)\n", out)
+
+	other := filepath.Join(repo, "team.yml")
+	require.NoError(t, os.WriteFile(other, []byte("map:\n  format: xml\n"), 0o644))
+	out, err = runConfigCommand("validate", other)
+	assert.EqualError(t, err, "found 1 problem(s) in the configuration")
+	assert.Contains(t, out, "✗ "+other+": ")
+	assert.Contains(t, out, "unsupported format 'xml'")
+}
+
+func TestConfigSchema(t *testing.T) {
+	repo, _ := setupConfigCommandTest(t)
+
+	out, err := runConfigCommand("schema")
+	require.NoError(t, err)
+	assert.Contains(t, out, `"$schema": "https://json-schema.org/draft/2020-12/schema"`)
+
+	path := filepath.Join(repo, ".wtp.schema.json")
+	out, err = runConfigCommand("schema", "-o", path)
+	require.NoError(t, err)
+	assert.Equal(t, "✓ Wrote the configuration schema to "+path+"\n", out)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"additionalProperties": false`)
+}
@@ -0,0 +1,391 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/git"
+	"github.com/satococoa/wtp/v2/internal/hooks"
+)
+
+// Severities of a 'wtp doctor' finding
+const (
+	doctorOK      = "ok"
+	doctorWarning = "warning"
+	doctorProblem = "problem"
+)
+
+// The oldest git whose worktree commands wtp relies on
+const (
+	minGitMajor = 2
+	minGitMinor = 17
+)
+
+var (
+	doctorSymbols = map[string]string{
+		doctorOK:      "✓",
+		doctorWarning: "!",
+		doctorProblem: "✗",
+	}
+	gitVersionPattern = regexp.MustCompile(`git version (\d+)\.(\d+)(\S*)`)
+)
+
+// doctorFinding is the result of one 'wtp doctor' check and, unless it is ok, how to fix it.
+type doctorFinding struct {
+	severity string
+	message  string
+	fix      string
+}
+
+// NewDoctorCommand creates the doctor command definition
+func NewDoctorCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "doctor",
+		Usage:     "Check the git installation, configuration, and worktrees for problems",
+		UsageText: "wtp doctor",
+		Description: "Checks that git is recent enough for worktrees, that every configuration layer " +
+			"(~/.wtp.yml, .wtp.yml, .wtp.local.yml) is valid on its own and merged, that each " +
+			"base_dir can be written to, that no registered worktree has lost its directory, and " +
+			"that the links symlink hooks created still point somewhere. Every problem comes with " +
+			"a suggested fix, and wtp doctor fails when it found one, so it can run in CI.\n\n" +
+			"Examples:\n" +
+			"  wtp doctor",
+		Action: doctorCommand,
+	}
+}
+
+func doctorCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return errors.DirectoryAccessFailed("access current", ".", err)
+	}
+	return writeDoctorReport(w, runDoctorChecks(command.NewRealExecutor(), cwd))
+}
+
+// runDoctorChecks runs every check for the repository containing cwd. The checks that
+// need a repository, or a configuration that loads, are left out without one.
+func runDoctorChecks(executor command.Executor, cwd string) []doctorFinding {
+	findings := []doctorFinding{checkGitVersion(executor)}
+	repo, err := git.NewRepository(cwd)
+	if err != nil {
+		return append(findings, doctorFinding{
+			severity: doctorProblem,
+			message:  "not in a git repository",
+			fix:      "Run 'wtp doctor' inside the repository you use wtp with",
+		})
+	}
+	mainRepoPath, err := repo.GetMainWorktreePath()
+	if err != nil {
+		mainRepoPath = repo.Path()
+	}
+
+	worktrees, finding := checkWorktreeSupport(executor)
+	findings = append(findings, finding)
+	findings = append(findings, checkConfigLayers(mainRepoPath)...)
+	cfg, err := config.LoadConfig(mainRepoPath, "")
+	if err != nil {
+		return findings // checkConfigLayers reported why
+	}
+	findings = append(findings, checkBaseDirs(cfg, mainRepoPath)...)
+	if worktrees != nil {
+		findings = append(findings, checkOrphanedWorktrees(cfg, mainRepoPath, worktrees)...)
+		findings = append(findings, checkDanglingSymlinks(cfg, mainRepoPath, worktrees)...)
+	}
+	return findings
+}
+
+// checkGitVersion checks that git is installed and at least minGitMajor.minGitMinor.
+func checkGitVersion(executor command.Executor) doctorFinding {
+	result, err := executor.Execute([]command.Command{command.GitVersion()})
+	if err != nil || result.Results[0].Error != nil {
+		return doctorFinding{
+			severity: doctorProblem,
+			message:  "git could not be run",
+			fix:      "Install git and make sure it is on your PATH",
+		}
+	}
+
+	output := result.Results[0].Output
+	match := gitVersionPattern.FindStringSubmatch(output)
+	if match == nil {
+		return doctorFinding{
+			severity: doctorWarning,
+			message:  fmt.Sprintf("could not tell the git version from %q", output),
+			fix:      fmt.Sprintf("Make sure git %d.%d or later is installed", minGitMajor, minGitMinor),
+		}
+	}
+	major, _ := strconv.Atoi(match[1])
+	minor, _ := strconv.Atoi(match[2])
+	version := match[1] + "." + match[2] + match[3]
+	if major < minGitMajor || (major == minGitMajor && minor < minGitMinor) {
+		return doctorFinding{
+			severity: doctorProblem,
+			message:  fmt.Sprintf("git %s is older than %d.%d", version, minGitMajor, minGitMinor),
+			fix:      fmt.Sprintf("Upgrade git to %d.%d or later for worktree support", minGitMajor, minGitMinor),
+		}
+	}
+	return doctorFinding{severity: doctorOK, message: "git " + version}
+}
+
+// checkWorktreeSupport checks that 'git worktree list' works in the repository, and
+// returns the worktrees it lists; nil when it failed.
+func checkWorktreeSupport(executor command.Executor) ([]git.Worktree, doctorFinding) {
+	result, err := executor.Execute([]command.Command{command.GitWorktreeList()})
+	if err == nil && result.Results[0].Error != nil {
+		err = result.Results[0].Error
+	}
+	if err != nil {
+		return nil, doctorFinding{
+			severity: doctorProblem,
+			message:  fmt.Sprintf("'git worktree list' failed: %v", err),
+			fix:      fmt.Sprintf("Upgrade git to %d.%d or later, or repair the repository", minGitMajor, minGitMinor),
+		}
+	}
+	worktrees := parseWorktreesFromOutput(result.Results[0].Output)
+	return worktrees, doctorFinding{
+		severity: doctorOK,
+		message:  fmt.Sprintf("git worktree works (%d worktree(s))", len(worktrees)),
+	}
+}
+
+// checkConfigLayers checks every configuration file that exists on its own, then, when
+// they are all valid, the configuration merged from them.
+func checkConfigLayers(mainRepoPath string) []doctorFinding {
+	layers, err := configLayers(mainRepoPath)
+	if err != nil {
+		return []doctorFinding{{
+			severity: doctorProblem,
+			message:  fmt.Sprintf("configuration files could not be found: %v", err),
+			fix:      "Keep only one of .wtp.yml, .wtp.json, and .wtp.toml per layer",
+		}}
+	}
+
+	var findings []doctorFinding
+	invalid := false
+	for _, layer := range layers {
+		if _, err := os.Stat(layer.path); os.IsNotExist(err) {
+			continue
+		}
+		unknownKeys, err := config.CheckConfigFile(layer.path)
+		if err != nil {
+			invalid = true
+			findings = append(findings, doctorFinding{
+				severity: doctorProblem,
+				message:  fmt.Sprintf("%s: %v", layer.name, err),
+				fix:      fmt.Sprintf("Fix %s; 'wtp config validate' checks it again", layer.path),
+			})
+			continue
+		}
+		for _, key := range unknownKeys {
+			key.File = layer.name
+			findings = append(findings, doctorFinding{
+				severity: doctorWarning,
+				message:  key.String(),
+				fix:      "Remove or rename the key; wtp ignores it, or rejects it with defaults.strict",
+			})
+		}
+		findings = append(findings, doctorFinding{severity: doctorOK, message: layer.name + " is valid"})
+	}
+	if invalid {
+		return findings // the merged configuration would repeat them
+	}
+	if len(findings) == 0 {
+		return []doctorFinding{{severity: doctorOK, message: "no configuration file; wtp uses its defaults"}}
+	}
+
+	if _, err := config.LoadConfig(mainRepoPath, ""); err != nil {
+		return append(findings, doctorFinding{
+			severity: doctorProblem,
+			message:  fmt.Sprintf("merged configuration: %v", err),
+			fix:      "Fix the setting in whichever layer sets it; 'wtp config list' shows where each comes from",
+		})
+	}
+	return findings
+}
+
+// checkBaseDirs checks that worktrees can be created in base_dir and in the base_dir of
+// every branch overlay.
+func checkBaseDirs(cfg *config.Config, mainRepoPath string) []doctorFinding {
+	var findings []doctorFinding
+	seen := map[string]bool{}
+	for _, baseDir := range cfg.BaseDirs() {
+		scoped := *cfg
+		scoped.Defaults.BaseDir = baseDir
+		dir := filepath.Clean(scoped.ResolveWorktreePath(mainRepoPath, ""))
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		findings = append(findings, checkBaseDir(dir))
+	}
+	return findings
+}
+
+// checkBaseDir checks that dir, or the closest directory above it when it does not exist
+// yet, can be written to by creating and removing a file in it.
+func checkBaseDir(dir string) doctorFinding {
+	existing := dir
+	for {
+		info, err := os.Stat(existing)
+		if err == nil && !info.IsDir() {
+			return doctorFinding{
+				severity: doctorProblem,
+				message:  fmt.Sprintf("base_dir %s: %s is not a directory", dir, existing),
+				fix:      "Move the file away or point defaults.base_dir elsewhere",
+			}
+		}
+		if err == nil {
+			break
+		}
+		// A file in the way shows up as ENOTDIR further down, so keep walking up to it
+		parent := filepath.Dir(existing)
+		if os.IsPermission(err) || parent == existing {
+			return doctorFinding{
+				severity: doctorProblem,
+				message:  fmt.Sprintf("base_dir %s cannot be accessed: %v", dir, err),
+				fix:      "Fix the permissions of its parent directories or point defaults.base_dir elsewhere",
+			}
+		}
+		existing = parent
+	}
+
+	probe, err := os.CreateTemp(existing, ".wtp-doctor-*")
+	if err != nil {
+		return doctorFinding{
+			severity: doctorProblem,
+			message:  fmt.Sprintf("base_dir %s is not writable: %v", dir, err),
+			fix:      fmt.Sprintf("Make %s writable or point defaults.base_dir elsewhere", existing),
+		}
+	}
+	_ = probe.Close()
+	_ = os.Remove(probe.Name())
+
+	if existing != dir {
+		return doctorFinding{
+			severity: doctorOK,
+			message:  fmt.Sprintf("base_dir %s does not exist yet and can be created", dir),
+		}
+	}
+	return doctorFinding{severity: doctorOK, message: fmt.Sprintf("base_dir %s is writable", dir)}
+}
+
+// checkOrphanedWorktrees reports the worktrees git still has registered although their
+// directory is gone, e.g. because it was deleted with rm -rf instead of 'wtp remove'.
+func checkOrphanedWorktrees(cfg *config.Config, mainRepoPath string, worktrees []git.Worktree) []doctorFinding {
+	var findings []doctorFinding
+	for _, wt := range worktrees {
+		if wt.IsMain {
+			continue
+		}
+		if _, err := os.Stat(wt.Path); !os.IsNotExist(err) {
+			continue
+		}
+		name := getWorktreeDisplayName(wt, cfg, mainRepoPath)
+		findings = append(findings, doctorFinding{
+			severity: doctorProblem,
+			message:  fmt.Sprintf("worktree %s is registered but %s no longer exists", name, wt.Path),
+			fix:      "Run 'git worktree prune' to drop the registration",
+		})
+	}
+	if len(findings) == 0 {
+		return []doctorFinding{{severity: doctorOK, message: "no orphaned worktree registrations"}}
+	}
+	return findings
+}
+
+// checkDanglingSymlinks reports the links of the symlink hooks in every worktree whose
+// target no longer exists, e.g. because the source was deleted or the repository moved.
+func checkDanglingSymlinks(cfg *config.Config, mainRepoPath string, worktrees []git.Worktree) []doctorFinding {
+	var findings []doctorFinding
+	checked := 0
+	for i := range worktrees {
+		wt := &worktrees[i]
+		wtCfg, err := maintenanceConfig(mainRepoPath, wt)
+		if err != nil || wtCfg == nil {
+			continue
+		}
+		branch := wt.Branch
+		if branch == detachedKeyword {
+			branch = ""
+		}
+		name := getWorktreeDisplayName(*wt, cfg, mainRepoPath)
+		for _, link := range hooks.NewExecutor(wtCfg, mainRepoPath).SymlinkPaths(wt.Path, branch) {
+			info, err := os.Lstat(link)
+			if err != nil || info.Mode()&os.ModeSymlink == 0 {
+				continue
+			}
+			checked++
+			if _, err := os.Stat(link); err == nil {
+				continue
+			}
+			target, _ := os.Readlink(link)
+			findings = append(findings, doctorFinding{
+				severity: doctorProblem,
+				message:  fmt.Sprintf("%s: %s is a dangling symlink to %s", name, relativeToWorktreePath(wt.Path, link), target),
+				fix: fmt.Sprintf("Restore %s, or delete the link and run 'wtp hooks run --type symlink %s'",
+					target, name),
+			})
+		}
+	}
+	if len(findings) == 0 && checked > 0 {
+		return []doctorFinding{{
+			severity: doctorOK,
+			message:  fmt.Sprintf("%d symlink(s) created by hooks point to existing files", checked),
+		}}
+	}
+	return findings
+}
+
+func relativeToWorktreePath(worktreePath, path string) string {
+	rel, err := filepath.Rel(worktreePath, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+// writeDoctorReport prints every finding with the fix for those that are not ok, and
+// fails when any is a problem.
+func writeDoctorReport(w io.Writer, findings []doctorFinding) error {
+	counts := map[string]int{}
+	for _, finding := range findings {
+		counts[finding.severity]++
+		if _, err := fmt.Fprintf(w, "%s %s\n", doctorSymbols[finding.severity], finding.message); err != nil {
+			return err
+		}
+		if finding.fix != "" {
+			if _, err := fmt.Fprintf(w, "    Fix: %s\n", finding.fix); err != nil {
+				return err
+			}
+		}
+	}
+
+	if counts[doctorProblem] > 0 {
+		if _, err := fmt.Fprintf(w, "\n%d problem(s), %d warning(s)\n",
+			counts[doctorProblem], counts[doctorWarning]); err != nil {
+			return err
+		}
+		return fmt.Errorf("wtp doctor found %d problem(s)", counts[doctorProblem])
+	}
+	if counts[doctorWarning] > 0 {
+		_, err := fmt.Fprintf(w, "\nNo problems, %d warning(s)\n", counts[doctorWarning])
+		return err
+	}
+	_, err := fmt.Fprintln(w, "\n✓ No problems found")
+	return err
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+// mockDoctorCommandExecutor answers 'git --version' and 'git worktree list'.
+type mockDoctorCommandExecutor struct {
+	version    string
+	versionErr error
+	listOutput string
+}
+
+func (m *mockDoctorCommandExecutor) Execute(commands []command.Command) (*command.ExecutionResult, error) {
+	results := make([]command.Result, len(commands))
+	for i, cmd := range commands {
+		results[i].Command = cmd
+		if cmd.Args[0] == "--version" {
+			results[i].Output, results[i].Error = m.version, m.versionErr
+		} else {
+			results[i].Output = m.listOutput
+		}
+	}
+	return &command.ExecutionResult{Results: results}, nil
+}
+
+func TestNewDoctorCommand(t *testing.T) {
+	cmd := NewDoctorCommand()
+
+	assert.Equal(t, "doctor", cmd.Name)
+	assert.NotEmpty(t, cmd.Usage)
+	assert.NotNil(t, cmd.Action)
+}
+
+func TestCheckGitVersion(t *testing.T) {
+	tests := []struct {
+		version  string
+		err      error
+		severity string
+		message  string
+	}{
+		{version: "git version 2.43.0", severity: doctorOK, message: "git 2.43.0"},
+		{version: "git version 2.45.1.windows.1", severity: doctorOK, message: "git 2.45.1.windows.1"},
+		{version: "git version 3.0.0", severity: doctorOK, message: "git 3.0.0"},
+		{version: "git version 2.16.6", severity: doctorProblem, message: "git 2.16.6 is older than 2.17"},
+		{version: "unexpected", severity: doctorWarning, message: "could not tell the git version"},
+		{err: fmt.Errorf("executable file not found"), severity: doctorProblem, message: "git could not be run"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			finding := checkGitVersion(&mockDoctorCommandExecutor{version: tt.version, versionErr: tt.err})
+			assert.Equal(t, tt.severity, finding.severity)
+			assert.Contains(t, finding.message, tt.message)
+			assert.Equal(t, tt.severity == doctorOK, finding.fix == "")
+		})
+	}
+}
+
+func TestCheckBaseDir(t *testing.T) {
+	dir := t.TempDir()
+
+	finding := checkBaseDir(dir)
+	assert.Equal(t, doctorOK, finding.severity)
+	assert.Contains(t, finding.message, "is writable")
+
+	finding = checkBaseDir(filepath.Join(dir, "worktrees", "nested"))
+	assert.Equal(t, doctorOK, finding.severity)
+	assert.Contains(t, finding.message, "does not exist yet")
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "the probe file is removed")
+
+	file := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o644))
+	finding = checkBaseDir(filepath.Join(file, "worktrees"))
+	assert.Equal(t, doctorProblem, finding.severity)
+	assert.Contains(t, finding.message, "is not a directory")
+	assert.NotEmpty(t, finding.fix)
+}
+
+func TestCheckOrphanedWorktrees(t *testing.T) {
+	mainRepoPath := t.TempDir()
+	existing := filepath.Join(mainRepoPath, ".worktrees", "feature", "a")
+	require.NoError(t, os.MkdirAll(existing, 0o755))
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: ".worktrees"}}
+	worktrees := []git.Worktree{
+		{Path: mainRepoPath, Branch: "main", IsMain: true},
+		{Path: existing, Branch: "feature/a"},
+		{Path: filepath.Join(mainRepoPath, ".worktrees", "gone"), Branch: "gone"},
+	}
+
+	findings := checkOrphanedWorktrees(cfg, mainRepoPath, worktrees)
+	require.Len(t, findings, 1)
+	assert.Equal(t, doctorProblem, findings[0].severity)
+	assert.Contains(t, findings[0].message, "worktree gone is registered")
+	assert.Contains(t, findings[0].fix, "git worktree prune")
+
+	findings = checkOrphanedWorktrees(cfg, mainRepoPath, worktrees[:2])
+	assert.Equal(t, []doctorFinding{{severity: doctorOK, message: "no orphaned worktree registrations"}}, findings)
+}
+
+func TestCheckDanglingSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping symlink test on Windows")
+	}
+	mainPath, worktreePath, _, _ := setupMaintainTest(t, `version: "1.0"
+defaults:
+  base_dir: ../worktrees
+hooks:
+  post_create:
+    - type: symlink
+      from: node_modules
+      to: node_modules
+    - type: symlink
+      from: .cache
+      to: .cache
+`)
+	cfg, err := config.LoadConfig(mainPath, "")
+	require.NoError(t, err)
+	require.NoError(t, os.Mkdir(filepath.Join(mainPath, "node_modules"), 0o755))
+	require.NoError(t, os.Symlink(filepath.Join(mainPath, "node_modules"), filepath.Join(worktreePath, "node_modules")))
+	require.NoError(t, os.Symlink(filepath.Join(mainPath, ".cache"), filepath.Join(worktreePath, ".cache")))
+	worktrees := []git.Worktree{
+		{Path: mainPath, Branch: "main", IsMain: true},
+		{Path: worktreePath, Branch: "feature/foo"},
+	}
+
+	findings := checkDanglingSymlinks(cfg, mainPath, worktrees)
+	require.Len(t, findings, 1)
+	assert.Equal(t, doctorProblem, findings[0].severity)
+	assert.Equal(t, "feature/foo: .cache is a dangling symlink to "+filepath.Join(mainPath, ".cache"),
+		findings[0].message)
+	assert.Contains(t, findings[0].fix, "wtp hooks run --type symlink feature/foo")
+
+	require.NoError(t, os.Mkdir(filepath.Join(mainPath, ".cache"), 0o755))
+	findings = checkDanglingSymlinks(cfg, mainPath, worktrees)
+	assert.Equal(t, []doctorFinding{{severity: doctorOK,
+		message: "2 symlink(s) created by hooks point to existing files"}}, findings)
+}
+
+func TestCheckWorktreeSupport(t *testing.T) {
+	executor := &mockDoctorCommandExecutor{listOutput: "worktree /repo\nHEAD abc\nbranch refs/heads/main\n"}
+
+	worktrees, finding := checkWorktreeSupport(executor)
+	assert.Equal(t, doctorOK, finding.severity)
+	assert.Equal(t, "git worktree works (1 worktree(s))", finding.message)
+	assert.Len(t, worktrees, 1)
+}
+
+func TestWriteDoctorReport(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeDoctorReport(&buf, []doctorFinding{{severity: doctorOK, message: "git 2.43.0"}}))
+	assert.Equal(t, "✓ git 2.43.0\n\n✓ No problems found\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, writeDoctorReport(&buf, []doctorFinding{
+		{severity: doctorWarning, message: ".wtp.yml:3:1: unknown key 'hoks'", fix: "Remove or rename the key"},
+	}))
+	assert.Equal(t, "! .wtp.yml:3:1: unknown key 'hoks'\n    Fix: Remove or rename the key\n\n"+
+		"No problems, 1 warning(s)\n", buf.String())
+
+	buf.Reset()
+	err := writeDoctorReport(&buf, []doctorFinding{
+		{severity: doctorOK, message: "git 2.43.0"},
+		{severity: doctorProblem, message: "worktree gone is registered", fix: "Run 'git worktree prune'"},
+	})
+	assert.EqualError(t, err, "wtp doctor found 1 problem(s)")
+	assert.Contains(t, buf.String(), "✗ worktree gone is registered\n    Fix: Run 'git worktree prune'\n")
+	assert.Contains(t, buf.String(), "1 problem(s), 0 warning(s)")
+}
+
+func TestCheckConfigLayers(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+	repo := t.TempDir()
+	configPath := filepath.Join(repo, config.ConfigFileName)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("version: \"1.0\"\nhoks: {}\n"), 0o644))
+	findings := checkConfigLayers(repo)
+	require.Len(t, findings, 2)
+	assert.Equal(t, doctorWarning, findings[0].severity)
+	assert.Contains(t, findings[0].message, "unknown key 'hoks'")
+	assert.Equal(t, doctorFinding{severity: doctorOK, message: ".wtp.yml is valid"}, findings[1])
+
+	require.NoError(t, os.WriteFile(configPath, []byte("defaults:\n  after_add: bogus\n"), 0o644))
+	findings = checkConfigLayers(repo)
+	require.Len(t, findings, 1)
+	assert.Equal(t, doctorProblem, findings[0].severity)
+	assert.Contains(t, findings[0].message, ".wtp.yml: ")
+	assert.Contains(t, findings[0].fix, "wtp config validate")
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+)
+
+// provisionEstimate predicts how long the post_create hooks of a new worktree take, based
+// on the hook results 'wtp add' recorded for the existing worktrees.
+type provisionEstimate struct {
+	Total time.Duration
+	// Runs is how many recorded worktrees contributed at least one timing.
+	Runs int
+	// Unknown lists the hook numbers no recorded worktree has a successful timing for.
+	Unknown []int
+}
+
+// checkProvisionEstimate prints the estimated setup time for cfg's post_create hooks and
+// fails when it exceeds --max-duration.
+func checkProvisionEstimate(w io.Writer, cmd *cli.Command, executor command.Executor, cfg *config.Config) error {
+	if !cfg.HasHooks() {
+		return nil
+	}
+	maxDuration := cmd.Duration("max-duration")
+
+	estimate := estimateProvisioning(loadWorktreeProvisionRecords(executor, cfg), cfg.Hooks.PostCreate)
+	if estimate.Runs == 0 {
+		if maxDuration > 0 {
+			return writeWarning(w, errors.CodeWarnNoTimingHistory,
+				"no hook timings recorded yet; --max-duration cannot be checked")
+		}
+		return nil
+	}
+
+	line := fmt.Sprintf("Setup will take ~%s based on %d previous run(s)", formatEstimate(estimate.Total), estimate.Runs)
+	if len(estimate.Unknown) > 0 {
+		line += fmt.Sprintf(" (%s not included: no timings yet)", formatHookNumbers(estimate.Unknown))
+	}
+	if _, err := fmt.Fprintln(w, line); err != nil {
+		return err
+	}
+
+	if maxDuration > 0 && estimate.Total > maxDuration {
+		return fmt.Errorf("estimated setup time ~%s exceeds --max-duration %s; "+
+			"trim the post_create hooks or raise the budget", formatEstimate(estimate.Total), maxDuration)
+	}
+	return nil
+}
+
+// loadWorktreeProvisionRecords returns the provisioning records of the repository's linked
+// worktrees. Worktrees without a readable record are skipped.
+func loadWorktreeProvisionRecords(executor command.Executor, cfg *config.Config) []*provisionRecord {
+	result, err := executor.Execute([]command.Command{command.GitWorktreeList()})
+	if err != nil || len(result.Results) == 0 || result.Results[0].Error != nil {
+		return nil
+	}
+
+	var records []*provisionRecord
+	for _, wt := range parseWorktreesFromOutput(result.Results[0].Output) {
+		if wt.IsMain {
+			continue
+		}
+		if record, err := loadProvisionRecord(cfg, wt.Path); err == nil && record != nil {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// estimateProvisioning takes, for each hook in postCreate, the median duration of its
+// successful runs in records. A recorded hook matches when its type and description are
+// unchanged, so edited or reordered hooks do not borrow another hook's timings. Hooks of
+// one parallel group count as the slowest among them.
+func estimateProvisioning(records []*provisionRecord, postCreate []config.Hook) provisionEstimate {
+	var estimate provisionEstimate
+	contributed := make([]bool, len(records))
+
+	var groupName string
+	var groupMax time.Duration
+	for i := range postCreate {
+		hook := &postCreate[i]
+		description := describeHook(hook)
+
+		var samples []time.Duration
+		for r, record := range records {
+			for _, recorded := range record.Hooks {
+				if recorded.Status == provisionStatusOK && recorded.Type == hook.Type &&
+					recorded.Description == description {
+					samples = append(samples, recorded.Duration)
+					contributed[r] = true
+					break
+				}
+			}
+		}
+
+		var duration time.Duration
+		if len(samples) == 0 {
+			estimate.Unknown = append(estimate.Unknown, i+1)
+		} else {
+			slices.Sort(samples)
+			duration = samples[len(samples)/2]
+		}
+
+		if hook.Group != "" && hook.Group == groupName {
+			if duration > groupMax {
+				estimate.Total += duration - groupMax
+				groupMax = duration
+			}
+			continue
+		}
+		groupName, groupMax = hook.Group, duration
+		estimate.Total += duration
+	}
+
+	for _, ok := range contributed {
+		if ok {
+			estimate.Runs++
+		}
+	}
+	return estimate
+}
+
+// formatEstimate rounds d for display: seconds below a minute, whole minutes above.
+func formatEstimate(d time.Duration) string {
+	if d < time.Second {
+		return "1s"
+	}
+	if d < time.Minute {
+		return d.Round(time.Second).String()
+	}
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	return fmt.Sprintf("%dm", minutes)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func timedRecord(hooks ...provisionHook) *provisionRecord {
+	for i := range hooks {
+		if hooks[i].Status == "" {
+			hooks[i].Status = provisionStatusOK
+		}
+	}
+	return &provisionRecord{Hooks: hooks}
+}
+
+func TestEstimateProvisioning(t *testing.T) {
+	postCreate := []config.Hook{
+		{Type: config.HookTypeCommand, Command: "npm ci"},
+		{Type: config.HookTypeCommand, Command: "make db", Group: "setup"},
+		{Type: config.HookTypeCopy, From: ".env", To: ".env", Group: "setup"},
+		{Type: config.HookTypeCommand, Command: "new step"},
+	}
+	records := []*provisionRecord{
+		timedRecord(
+			provisionHook{Index: 1, Type: "command", Description: "npm ci", Duration: 60 * time.Second},
+			provisionHook{Index: 2, Type: "command", Description: "make db", Duration: 10 * time.Second},
+			provisionHook{Index: 3, Type: "copy", Description: ".env → .env", Duration: 20 * time.Second},
+		),
+		timedRecord(provisionHook{Index: 1, Type: "command", Description: "npm ci", Duration: 90 * time.Second}),
+		timedRecord(provisionHook{Index: 1, Type: "command", Description: "npm ci", Duration: 240 * time.Second}),
+		timedRecord(provisionHook{
+			Index: 1, Type: "command", Description: "npm ci", Duration: time.Hour, Status: provisionStatusFailed,
+		}),
+		timedRecord(provisionHook{Index: 1, Type: "command", Description: "npm install", Duration: time.Hour}),
+	}
+
+	estimate := estimateProvisioning(records, postCreate)
+
+	// median of npm ci (90s) plus the slower hook of the 'setup' group (20s)
+	assert.Equal(t, 110*time.Second, estimate.Total)
+	assert.Equal(t, 3, estimate.Runs)
+	assert.Equal(t, []int{4}, estimate.Unknown)
+}
+
+func TestFormatEstimate(t *testing.T) {
+	assert.Equal(t, "1s", formatEstimate(200*time.Millisecond))
+	assert.Equal(t, "42s", formatEstimate(41600*time.Millisecond))
+	assert.Equal(t, "4m", formatEstimate(3*time.Minute+40*time.Second))
+}
+
+func TestCheckProvisionEstimate(t *testing.T) {
+	_, worktreePath, listOutput := setupCheckoutTest(t)
+	setupInfoWorktree(t, worktreePath)
+	require.NoError(t, saveProvisionRecord(&config.Config{}, worktreePath, timedRecord(
+		provisionHook{Index: 1, Type: "command", Description: "npm ci", Duration: 4 * time.Minute},
+	)))
+
+	cfg := &config.Config{Hooks: config.Hooks{PostCreate: []config.Hook{
+		{Type: config.HookTypeCommand, Command: "npm ci"},
+	}}}
+	mockExec := &mockInfoCommandExecutor{listOutput: listOutput}
+
+	t.Run("prints the estimate", func(t *testing.T) {
+		var buf bytes.Buffer
+		cmd := createTestCLICommand(map[string]any{}, []string{"feature/new"})
+
+		require.NoError(t, checkProvisionEstimate(&buf, cmd, mockExec, cfg))
+		assert.Contains(t, buf.String(), "Setup will take ~4m based on 1 previous run(s)")
+	})
+
+	t.Run("aborts above --max-duration", func(t *testing.T) {
+		var buf bytes.Buffer
+		cmd := createTestCLICommand(map[string]any{"max-duration": "2m"}, []string{"feature/new"})
+
+		err := checkProvisionEstimate(&buf, cmd, mockExec, cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "estimated setup time ~4m exceeds --max-duration 2m0s")
+	})
+
+	t.Run("warns without history", func(t *testing.T) {
+		var buf bytes.Buffer
+		cmd := createTestCLICommand(map[string]any{"max-duration": "2m"}, []string{"feature/new"})
+		noHistory := &mockInfoCommandExecutor{listOutput: "worktree /repo\nHEAD abc\nbranch refs/heads/main\n\n"}
+
+		require.NoError(t, checkProvisionEstimate(&buf, cmd, noHistory, cfg))
+		assert.Contains(t, buf.String(), "Warning [WTP7008]: no hook timings recorded yet")
+	})
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+// execTarget is a worktree 'wtp exec' runs its command in.
+type execTarget struct {
+	name string
+	path string
+}
+
+// execOptions are the command and how 'wtp exec' runs it.
+type execOptions struct {
+	argv     []string
+	parallel int
+	// failFast stops starting the command in more worktrees once it failed in one.
+	failFast bool
+	// repoRoot is exported to the command as GIT_WTP_REPO_ROOT, like for command hooks.
+	repoRoot string
+}
+
+// NewExecCommand creates the exec command definition
+func NewExecCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "exec",
+		Usage: "Run a command in one or more worktrees",
+		UsageText: "wtp exec [--all | --branch <glob>] [--parallel <n>] [--fail-fast] [--timeout <duration>] " +
+			"-- <command> [<args>...]",
+		Description: "Runs a command inside worktree directories. Without --all or --branch it runs in " +
+			"the worktree containing the current directory. The command is started directly, not " +
+			"through a shell; use 'sh -c' for pipes and variables. It gets GIT_WTP_WORKTREE_PATH " +
+			"and GIT_WTP_REPO_ROOT like command hooks do.\n\n" +
+			"With several worktrees, each output line is prefixed with the worktree's name, and " +
+			"the command runs in all of them even when it fails in some; a table of the result in " +
+			"each worktree follows unless it succeeded everywhere. With --fail-fast, no more " +
+			"worktrees are started after the first failure. wtp exits with status 3 when the " +
+			"command succeeded in some worktrees and failed in others.\n\n" +
+			"With --timeout or defaults.operation_timeout, commands still running when it passes " +
+			"are interrupted, then killed after a few seconds, and the worktrees not reached yet " +
+			"are skipped.\n\n" +
+			"Examples:\n" +
+			"  wtp exec --all -- git status --short            # Status of every worktree\n" +
+			"  wtp exec --branch 'feature/*' -j 4 -- make test  # Test feature branches, 4 at a time\n" +
+			"  wtp exec --all -- sh -c 'git log -1 --oneline'  # Use a shell for more\n" +
+			"  wtp exec --all --timeout 5m -- make lint         # Give up on whatever hangs",
+		ArgsUsage: "-- <command> [<args>...]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "all",
+				Aliases: []string{"a"},
+				Usage:   "Run in every worktree",
+			},
+			&cli.StringFlag{
+				Name:    "branch",
+				Aliases: []string{"b"},
+				Usage:   "Run in the worktrees whose branch matches the glob (e.g. 'feature/*')",
+			},
+			&cli.IntFlag{
+				Name:    "parallel",
+				Aliases: []string{"j"},
+				Usage:   "Run in up to n worktrees at once",
+				Value:   1,
+			},
+			newFailFastFlag(),
+			newTimeoutFlag(),
+		},
+		Action: execCommand,
+	}
+}
+
+func execCommand(ctx context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+	errWriter := cmd.Root().ErrWriter
+	if errWriter == nil {
+		errWriter = os.Stderr
+	}
+
+	argv := cmd.Args().Slice()
+	if len(argv) == 0 {
+		return errors.InvalidArguments("no command given; usage: wtp exec [--all | --branch <glob>] -- <command> [<args>...]")
+	}
+	if cmd.Bool("all") && cmd.String("branch") != "" {
+		return errors.InvalidArguments("--all and --branch cannot be used together")
+	}
+	if cmd.Int("parallel") < 1 {
+		return errors.InvalidArguments("--parallel must be at least 1, got %d", cmd.Int("parallel"))
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return errors.DirectoryAccessFailed("access current", ".", err)
+	}
+	_, cfg, mainRepoPath, err := setupRepoAndConfig()
+	if err != nil {
+		return err
+	}
+
+	result, err := command.NewRealExecutor().Execute([]command.Command{command.GitWorktreeList()})
+	if err != nil {
+		return errors.GitCommandFailed("git worktree list", err.Error())
+	}
+	worktrees := parseWorktreesFromOutput(result.Results[0].Output)
+	targets, err := selectExecTargets(worktrees, cfg, mainRepoPath, cwd, cmd.Bool("all"), cmd.String("branch"))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := operationContext(ctx, cmd, cfg, "wtp exec")
+	defer cancel()
+
+	opts := &execOptions{
+		argv:     argv,
+		parallel: int(cmd.Int("parallel")),
+		failFast: cmd.Bool("fail-fast"),
+		repoRoot: mainRepoPath,
+	}
+	if len(targets) == 1 {
+		// A single worktree gets the terminal, so interactive commands work
+		if err := runExecCommand(ctx, opts, targets[0].path, os.Stdin, w, errWriter); err != nil {
+			if timeoutErr := operationTimedOut(ctx, "stopped in: "+targets[0].name); timeoutErr != nil {
+				return timeoutErr
+			}
+			if _, writeErr := fmt.Fprintf(errWriter, "✗ %s: %v\n", targets[0].name, err); writeErr != nil {
+				return writeErr
+			}
+			return errors.ExecFailed([]string{targets[0].name})
+		}
+		return nil
+	}
+	return runExecInWorktrees(ctx, w, errWriter, targets, opts)
+}
+
+// selectExecTargets returns the worktrees to run in, in 'git worktree list' order: every
+// managed worktree with all, those whose branch matches branchGlob, or else the one
+// containing cwd. Worktrees whose directory is gone are skipped.
+func selectExecTargets(
+	worktrees []git.Worktree, cfg *config.Config, mainRepoPath, cwd string, all bool, branchGlob string,
+) ([]execTarget, error) {
+	if !all && branchGlob == "" {
+		wt := findWorktreeContaining(worktrees, cwd)
+		if wt == nil {
+			return nil, errors.NotInWorktree("use --all or --branch")
+		}
+		return []execTarget{{name: getWorktreeDisplayName(*wt, cfg, mainRepoPath), path: wt.Path}}, nil
+	}
+	if _, err := path.Match(branchGlob, ""); err != nil {
+		return nil, fmt.Errorf("invalid --branch pattern '%s': %w", branchGlob, err)
+	}
+
+	var targets []execTarget
+	for i := range worktrees {
+		wt := &worktrees[i]
+		if !isWorktreeManagedCd(wt.Path, cfg, mainRepoPath, wt.IsMain) {
+			continue
+		}
+		if branchGlob != "" {
+			if matched, _ := path.Match(branchGlob, wt.Branch); !matched {
+				continue
+			}
+		}
+		if _, err := os.Stat(wt.Path); err != nil {
+			continue
+		}
+		targets = append(targets, execTarget{name: getWorktreeDisplayName(*wt, cfg, mainRepoPath), path: wt.Path})
+	}
+	if len(targets) == 0 {
+		if branchGlob != "" {
+			return nil, fmt.Errorf("no worktree has a branch matching '%s'", branchGlob)
+		}
+		return nil, fmt.Errorf("no worktrees found")
+	}
+	return targets, nil
+}
+
+// Outcomes of running the command in one worktree
+const (
+	execNotStarted = iota
+	execSucceeded
+	execFailed
+	// execStopped means the command was still running when the timeout passed.
+	execStopped
+)
+
+// runExecInWorktrees runs the command in every target, up to opts.parallel at once, with
+// each output line prefixed with the target's name. Once all are done, it prints the
+// result in each target unless the command succeeded in all of them, and reports the
+// targets it failed in. With opts.failFast, no more targets are started after a failure.
+// Once ctx's timeout passes, running commands are stopped, no more are started, and the
+// timeout is returned with the outcome in each target.
+func runExecInWorktrees(ctx context.Context, w, errWriter io.Writer, targets []execTarget, opts *execOptions) error {
+	var mu sync.Mutex
+	var stopped atomic.Bool
+	outcomes := make([]int, len(targets))
+	failures := make([]error, len(targets))
+	slots := make(chan struct{}, opts.parallel)
+	var wg sync.WaitGroup
+	for i := range targets {
+		// A slot is freed only after its outcome is set, so a failure stops the next target
+		slots <- struct{}{}
+		if ctx.Err() != nil || stopped.Load() {
+			<-slots
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			prefix := "[" + targets[i].name + "] "
+			stdout := &prefixWriter{mu: &mu, out: w, prefix: prefix}
+			stderr := &prefixWriter{mu: &mu, out: errWriter, prefix: prefix}
+			failures[i] = runExecCommand(ctx, opts, targets[i].path, nil, stdout, stderr)
+			_ = stdout.Flush()
+			_ = stderr.Flush()
+			switch {
+			case failures[i] == nil:
+				outcomes[i] = execSucceeded
+			case ctx.Err() != nil:
+				outcomes[i] = execStopped
+			default:
+				outcomes[i] = execFailed
+				if opts.failFast {
+					stopped.Store(true)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if err := operationTimedOut(ctx, execProgress(targets, outcomes)...); err != nil {
+		return err
+	}
+	report := execReport(targets, outcomes, failures)
+	if !report.complete() {
+		if err := report.write(w); err != nil {
+			return err
+		}
+	}
+	if err := report.err(errors.ExecFailed); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "✓ Command succeeded in %d worktree(s)\n", len(targets))
+	return err
+}
+
+// execReport turns the outcome in each target into a batchReport. Without a timeout,
+// targets are only not started after a failure with --fail-fast.
+func execReport(targets []execTarget, outcomes []int, failures []error) *batchReport {
+	report := &batchReport{}
+	for i := range targets {
+		switch outcomes[i] {
+		case execSucceeded:
+			report.succeed(targets[i].name)
+		case execFailed:
+			report.fail(targets[i].name, failures[i])
+		default:
+			report.skip(targets[i].name, failFastReason)
+		}
+	}
+	return report
+}
+
+// execProgress describes, for a timeout error, which targets each outcome applies to.
+func execProgress(targets []execTarget, outcomes []int) []string {
+	labels := []struct {
+		outcome int
+		label   string
+	}{
+		{execSucceeded, "succeeded in"},
+		{execFailed, "failed in"},
+		{execStopped, "stopped in"},
+		{execNotStarted, "not started in"},
+	}
+	var progress []string
+	for _, l := range labels {
+		var names []string
+		for i := range targets {
+			if outcomes[i] == l.outcome {
+				names = append(names, targets[i].name)
+			}
+		}
+		if len(names) > 0 {
+			progress = append(progress, l.label+": "+strings.Join(names, ", "))
+		}
+	}
+	return progress
+}
+
+// runExecCommand runs the command in the worktree at worktreePath. Once ctx is done, the
+// command is interrupted, and killed if it does not exit.
+func runExecCommand(
+	ctx context.Context, opts *execOptions, worktreePath string, stdin io.Reader, stdout, stderr io.Writer,
+) error {
+	// #nosec G204 -- the command is what the user asked to run
+	cmd := exec.CommandContext(ctx, opts.argv[0], opts.argv[1:]...)
+	command.InterruptOnCancel(cmd)
+	cmd.Dir = worktreePath
+	cmd.Env = append(os.Environ(),
+		"GIT_WTP_WORKTREE_PATH="+worktreePath,
+		"GIT_WTP_REPO_ROOT="+opts.repoRoot)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
+
+// prefixWriter writes each complete line to out with prefix, holding back a partial line
+// until it is completed or flushed. The shared mutex keeps the lines of commands running
+// at the same time whole.
+type prefixWriter struct {
+	mu      *sync.Mutex
+	out     io.Writer
+	prefix  string
+	pending []byte
+}
+
+func (p *prefixWriter) Write(data []byte) (int, error) {
+	p.pending = append(p.pending, data...)
+	end := bytes.LastIndexByte(p.pending, '\n')
+	if end < 0 {
+		return len(data), nil
+	}
+	if err := p.writeLines(p.pending[:end+1]); err != nil {
+		return 0, err
+	}
+	p.pending = append(p.pending[:0], p.pending[end+1:]...)
+	return len(data), nil
+}
+
+// Flush writes a trailing line that did not end with a newline.
+func (p *prefixWriter) Flush() error {
+	if len(p.pending) == 0 {
+		return nil
+	}
+	err := p.writeLines(append(p.pending, '\n'))
+	p.pending = nil
+	return err
+}
+
+func (p *prefixWriter) writeLines(lines []byte) error {
+	var b bytes.Buffer
+	for _, line := range bytes.SplitAfter(lines, []byte("\n")) {
+		if len(line) > 0 {
+			b.WriteString(p.prefix)
+			b.Write(line)
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := p.out.Write(b.Bytes())
+	return err
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+)
+
+func TestNewExecCommand(t *testing.T) {
+	cmd := NewExecCommand()
+
+	assert.Equal(t, "exec", cmd.Name)
+	assert.NotEmpty(t, cmd.Usage)
+	assert.NotNil(t, cmd.Action)
+}
+
+func TestSelectExecTargets(t *testing.T) {
+	mainPath, worktreePath, listOutput := setupCheckoutTest(t)
+	missing := filepath.Join(filepath.Dir(worktreePath), "gone")
+	worktrees := parseWorktreesFromOutput(listOutput +
+		"worktree " + missing + "\nHEAD 999999\nbranch refs/heads/feature/gone\n\n")
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+
+	targets, err := selectExecTargets(worktrees, cfg, mainPath, mainPath, true, "")
+	require.NoError(t, err)
+	assert.Equal(t, []execTarget{{name: "@", path: mainPath}, {name: "feature/foo", path: worktreePath}}, targets)
+
+	targets, err = selectExecTargets(worktrees, cfg, mainPath, mainPath, false, "feature/*")
+	require.NoError(t, err)
+	assert.Equal(t, []execTarget{{name: "feature/foo", path: worktreePath}}, targets)
+
+	targets, err = selectExecTargets(worktrees, cfg, mainPath, filepath.Join(worktreePath, "src"), false, "")
+	require.NoError(t, err)
+	assert.Equal(t, []execTarget{{name: "feature/foo", path: worktreePath}}, targets)
+
+	_, err = selectExecTargets(worktrees, cfg, mainPath, mainPath, false, "fix/*")
+	assert.ErrorContains(t, err, "no worktree has a branch matching 'fix/*'")
+	_, err = selectExecTargets(worktrees, cfg, mainPath, mainPath, false, "[")
+	assert.ErrorContains(t, err, "invalid --branch pattern")
+	_, err = selectExecTargets(worktrees, cfg, mainPath, t.TempDir(), false, "")
+	assert.ErrorContains(t, err, "not inside a worktree")
+}
+
+func TestRunExecInWorktrees(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping sh test on Windows")
+	}
+	first, second := t.TempDir(), t.TempDir()
+	targets := []execTarget{{name: "@", path: first}, {name: "feature/a", path: second}}
+
+	var stdout, stderr bytes.Buffer
+	opts := &execOptions{
+		argv:     []string{"sh", "-c", `echo "in $GIT_WTP_WORKTREE_PATH"; printf 'no newline'; echo oops >&2`},
+		parallel: 2,
+		repoRoot: first,
+	}
+	require.NoError(t, runExecInWorktrees(context.Background(), &stdout, &stderr, targets, opts))
+	for _, line := range []string{"[@] in " + first, "[@] no newline", "[feature/a] in " + second,
+		"[feature/a] no newline"} {
+		assert.Contains(t, stdout.String(), line+"\n")
+	}
+	assert.Contains(t, stdout.String(), "✓ Command succeeded in 2 worktree(s)")
+	assert.Contains(t, stderr.String(), "[@] oops\n")
+	assert.Contains(t, stderr.String(), "[feature/a] oops\n")
+
+	stdout.Reset()
+	opts.argv = []string{"sh", "-c", `test "$GIT_WTP_WORKTREE_PATH" != "` + second + `"`}
+	err := runExecInWorktrees(context.Background(), &stdout, &stderr, targets, opts)
+	require.Error(t, err)
+	code, _ := errors.CodeOf(err)
+	assert.Equal(t, errors.CodeExecFailed, code)
+	assert.Contains(t, err.Error(), "command failed in 1 worktree(s): feature/a")
+	assert.Regexp(t, `feature/a +✗ failed: exit status 1\n`, stdout.String())
+	assert.Contains(t, stdout.String(), "1 succeeded, 1 failed, 0 skipped")
+	assert.Equal(t, errors.ExitPartialFailure, errors.ExitStatus(err))
+}
+
+func TestRunExecInWorktrees_FailFast(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping sh test on Windows")
+	}
+	targets := []execTarget{{name: "@", path: t.TempDir()}, {name: "feature/a", path: t.TempDir()},
+		{name: "feature/b", path: t.TempDir()}}
+	opts := &execOptions{argv: []string{"false"}, parallel: 1, failFast: true}
+
+	var stdout, stderr bytes.Buffer
+	err := runExecInWorktrees(context.Background(), &stdout, &stderr, targets, opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "command failed in 1 worktree(s): @")
+	assert.Equal(t, errors.ExitFailure, errors.ExitStatus(err))
+	assert.Regexp(t, `feature/a +- skipped: --fail-fast\n`, stdout.String())
+	assert.Regexp(t, `feature/b +- skipped: --fail-fast\n`, stdout.String())
+	assert.Contains(t, stdout.String(), "0 succeeded, 1 failed, 2 skipped")
+}
+
+func TestRunExecInWorktrees_Timeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping sh test on Windows")
+	}
+	first := t.TempDir()
+	targets := []execTarget{{name: "@", path: first}, {name: "feature/a", path: t.TempDir()},
+		{name: "feature/b", path: t.TempDir()}}
+	opts := &execOptions{
+		argv:     []string{"sh", "-c", `test "$GIT_WTP_WORKTREE_PATH" = "` + first + `" || sleep 30`},
+		parallel: 1,
+	}
+	ctx, cancel := context.WithTimeoutCause(context.Background(), 300*time.Millisecond,
+		&operationTimeout{operation: "wtp exec", timeout: 300 * time.Millisecond})
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	start := time.Now()
+	err := runExecInWorktrees(ctx, &stdout, &stderr, targets, opts)
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 10*time.Second)
+	code, _ := errors.CodeOf(err)
+	assert.Equal(t, errors.CodeOperationTimedOut, code)
+	assert.Contains(t, err.Error(), "wtp exec timed out after 300ms")
+	assert.Contains(t, err.Error(), "succeeded in: @\n")
+	assert.Contains(t, err.Error(), "stopped in: feature/a\n")
+	assert.Contains(t, err.Error(), "not started in: feature/b\n")
+	assert.NotContains(t, stdout.String(), "✗")
+}
+
+func TestOperationContext(t *testing.T) {
+	cmd := &cli.Command{Flags: []cli.Flag{newTimeoutFlag()}}
+
+	ctx, cancel := operationContext(context.Background(), cmd, &config.Config{}, "wtp exec")
+	defer cancel()
+	_, hasDeadline := ctx.Deadline()
+	assert.False(t, hasDeadline)
+	assert.NoError(t, operationTimedOut(ctx))
+
+	cfg := &config.Config{Defaults: config.Defaults{OperationTimeout: "1ms"}}
+	ctx, cancel = operationContext(context.Background(), cmd, cfg, "wtp exec")
+	defer cancel()
+	<-ctx.Done()
+	assert.ErrorContains(t, operationTimedOut(ctx, "stopped in: @"), "wtp exec timed out after 1ms")
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := &prefixWriter{mu: &sync.Mutex{}, out: &out, prefix: "[x] "}
+
+	_, err := w.Write([]byte("one\ntw"))
+	require.NoError(t, err)
+	assert.Equal(t, "[x] one\n", out.String())
+	_, err = w.Write([]byte("o\nthree\n\nfour"))
+	require.NoError(t, err)
+	require.NoError(t, w.Flush())
+	assert.Equal(t, "[x] one\n[x] two\n[x] three\n[x] \n[x] four\n", out.String())
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/errors"
+)
+
+// NewExplainCommand creates the explain command definition
+func NewExplainCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "explain",
+		Usage:     "Explain an error or warning code",
+		UsageText: "wtp explain [<code>]",
+		Description: "Every wtp error and warning carries a stable code such as WTP1001. Shows the " +
+			"causes and fixes for a code, or lists all codes when none is given.\n\n" +
+			"Examples:\n" +
+			"  wtp explain WTP1001   # Details for one code\n" +
+			"  wtp explain 5001      # The WTP prefix is optional\n" +
+			"  wtp explain           # List every code",
+		ArgsUsage: "[<code>]",
+		Action:    explainCommand,
+	}
+}
+
+func explainCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+	if cmd.Args().Len() == 0 {
+		return writeCodeList(w)
+	}
+	return writeExplanation(w, cmd.Args().First())
+}
+
+// writeCodeList prints every registered code with its one-line summary.
+func writeCodeList(w io.Writer) error {
+	for _, code := range errors.Codes() {
+		explanation, _ := errors.Explain(code)
+		if _, err := fmt.Fprintf(w, "%s  %s\n", code, explanation.Summary); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeExplanation prints the summary, causes, and fixes of the code named by input.
+func writeExplanation(w io.Writer, input string) error {
+	code, ok := errors.ParseCode(input)
+	if !ok {
+		return errors.InvalidArguments("unknown code '%s'; run 'wtp explain' to list all codes", input)
+	}
+	explanation, _ := errors.Explain(code)
+
+	if _, err := fmt.Fprintf(w, "%s: %s\n", code, explanation.Summary); err != nil {
+		return err
+	}
+	sections := []struct {
+		title string
+		items []string
+	}{
+		{"Causes", explanation.Causes},
+		{"Fixes", explanation.Fixes},
+	}
+	for _, section := range sections {
+		if _, err := fmt.Fprintf(w, "\n%s:\n", section.title); err != nil {
+			return err
+		}
+		for _, item := range section.items {
+			if _, err := fmt.Fprintf(w, "  • %s\n", item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeWarning prints a warning line tagged with code.
+func writeWarning(w io.Writer, code errors.Code, format string, args ...any) error {
+	_, err := fmt.Fprintln(w, errors.Warning(code, format, args...))
+	return err
+}
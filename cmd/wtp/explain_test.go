@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewExplainCommand(t *testing.T) {
+	cmd := NewExplainCommand()
+	assert.Equal(t, "explain", cmd.Name)
+	assert.NotNil(t, cmd.Action)
+}
+
+func TestWriteExplanation(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeExplanation(&buf, "wtp4001"))
+
+	output := buf.String()
+	assert.Contains(t, output, "WTP4001: A configuration file could not be read or is invalid.")
+	assert.Contains(t, output, "\nCauses:\n  • Syntax errors")
+	assert.Contains(t, output, "\nFixes:\n  • ")
+}
+
+func TestWriteExplanation_UnknownCode(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeExplanation(&buf, "WTP0000")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown code 'WTP0000'")
+}
+
+func TestWriteCodeList(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeCodeList(&buf))
+
+	assert.Contains(t, buf.String(), "WTP1001  The command was run outside a git repository.\n")
+	assert.Contains(t, buf.String(), "WTP7001  Warning: a post_create hook failed")
+}
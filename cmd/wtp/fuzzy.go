@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+// Variables to allow mocking in tests. The choice is asked on stderr so that it stays
+// visible when stdout is captured, as in cd "$(wtp cd auth)".
+var (
+	pickerInput      io.Reader = os.Stdin
+	pickerOutput     io.Writer = os.Stderr
+	pickerIsTerminal           = func() bool {
+		return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stderr.Fd()))
+	}
+)
+
+// Fuzzy match tiers, best first. Only the candidates of the best tier that matched are
+// returned, so "auth" picks "auth-api" over "feature/oauth-fix".
+const (
+	fuzzyTierNone = iota
+	fuzzyTierSubsequence
+	fuzzyTierSubstring
+	fuzzyTierSegmentPrefix
+	fuzzyTierPrefix
+)
+
+// resolveWorktreeName resolves a worktree name argument the same way for every command:
+// exact names first (see resolveCdWorktreePath), then worktree IDs (see worktreeIDs), then
+// fuzzy matches. It returns nil when nothing matches, so that callers can report which
+// worktrees they accept.
+func resolveWorktreeName(
+	worktreeName string, worktrees []git.Worktree, cfg *config.Config, mainWorktreePath string,
+) (*git.Worktree, error) {
+	if wt, err := resolveExactWorktreeName(worktreeName, worktrees, cfg, mainWorktreePath); wt != nil || err != nil {
+		return wt, err
+	}
+	return fuzzyResolveWorktree(worktreeName, worktrees, cfg, mainWorktreePath)
+}
+
+// resolveWorktreeNameToChange resolves a worktree name argument for commands that remove or
+// relocate a worktree, such as 'wtp remove' and 'wtp move': exact names, IDs, and fuzzy
+// matches as resolveWorktreeName does, but a loose fuzzy match must be confirmed first (see
+// fuzzyResolveWorktreeToChange). action, e.g. "move", names the change in the question.
+func resolveWorktreeNameToChange(
+	worktreeName string, worktrees []git.Worktree, cfg *config.Config, mainWorktreePath, action string,
+) (*git.Worktree, error) {
+	if wt, err := resolveExactWorktreeName(worktreeName, worktrees, cfg, mainWorktreePath); wt != nil || err != nil {
+		return wt, err
+	}
+	return fuzzyResolveWorktreeToChange(worktreeName, worktrees, cfg, mainWorktreePath, action)
+}
+
+// resolveExactWorktreeName resolves a worktree name argument like resolveWorktreeName but
+// without fuzzy matches, for a name that is created when nothing matches.
+func resolveExactWorktreeName(
+	worktreeName string, worktrees []git.Worktree, cfg *config.Config, mainWorktreePath string,
+) (*git.Worktree, error) {
+	if targetPath := resolveCdWorktreePath(worktreeName, worktrees, mainWorktreePath); targetPath != "" {
+		for i := range worktrees {
+			if worktrees[i].Path == targetPath {
+				return &worktrees[i], nil
+			}
+		}
+	}
+	return resolveWorktreeID(worktreeName, worktrees, cfg, mainWorktreePath)
+}
+
+// fuzzyResolveWorktree picks the single best fuzzy match for worktreeName. Several equally
+// good matches are offered as a numbered choice on an interactive terminal and reported as
+// errors.AmbiguousWorktree otherwise.
+func fuzzyResolveWorktree(
+	worktreeName string, worktrees []git.Worktree, cfg *config.Config, mainWorktreePath string,
+) (*git.Worktree, error) {
+	matches := fuzzyMatchWorktrees(worktreeName, worktrees, cfg, mainWorktreePath, fuzzyTierSubsequence)
+	switch len(matches) {
+	case 0:
+		return nil, nil
+	case 1:
+		return matches[0], nil
+	}
+
+	candidates := worktreeLabels(matches, cfg, mainWorktreePath)
+	if !pickerIsTerminal() {
+		return nil, errors.AmbiguousWorktree(worktreeName, candidates)
+	}
+	choice, err := askWorktreeChoice(pickerOutput, pickerInput, worktreeName, candidates)
+	if err != nil {
+		return nil, err
+	}
+	if choice < 0 {
+		return nil, errors.AmbiguousWorktree(worktreeName, candidates)
+	}
+	return matches[choice], nil
+}
+
+// fuzzyResolveWorktreeToChange picks the fuzzy match for worktreeName like
+// fuzzyResolveWorktree, for a worktree that action is about to change. The one worktree whose
+// name, or a path segment of it as with "auth" for feature/auth, starts with worktreeName is
+// taken as it is. A looser single match, such as "fm" for feature/m, could be a worktree
+// nobody meant, so it is confirmed on an interactive terminal and an
+// errors.UnconfirmedWorktreeMatch otherwise.
+func fuzzyResolveWorktreeToChange(
+	worktreeName string, worktrees []git.Worktree, cfg *config.Config, mainWorktreePath, action string,
+) (*git.Worktree, error) {
+	matches := fuzzyMatchWorktrees(worktreeName, worktrees, cfg, mainWorktreePath, fuzzyTierSegmentPrefix)
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	matches = fuzzyMatchWorktrees(worktreeName, worktrees, cfg, mainWorktreePath, fuzzyTierSubsequence)
+	if len(matches) != 1 {
+		return fuzzyResolveWorktree(worktreeName, worktrees, cfg, mainWorktreePath)
+	}
+
+	label := worktreeLabel(matches[0], cfg, mainWorktreePath)
+	if !pickerIsTerminal() {
+		return nil, errors.UnconfirmedWorktreeMatch(worktreeName, label)
+	}
+	confirmed, err := askWorktreeConfirmation(pickerOutput, pickerInput, worktreeName, label, action)
+	if err != nil {
+		return nil, err
+	}
+	if !confirmed {
+		return nil, errors.UnconfirmedWorktreeMatch(worktreeName, label)
+	}
+	return matches[0], nil
+}
+
+// askWorktreeConfirmation asks whether the worktree named candidate, which worktreeName
+// matched, is the one to change by action, and reports whether the answer is yes.
+func askWorktreeConfirmation(w io.Writer, input io.Reader, worktreeName, candidate, action string) (bool, error) {
+	if _, err := fmt.Fprintf(w, "'%s' matches %s; %s it? [y/N]: ", worktreeName, candidate, action); err != nil {
+		return false, err
+	}
+	answer, _ := bufio.NewReader(input).ReadString('\n')
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(answer)), "y"), nil
+}
+
+// askWorktreeChoice lists candidates and returns the index of the one chosen, or -1 when the
+// answer is empty or not one of the numbers shown.
+func askWorktreeChoice(w io.Writer, input io.Reader, worktreeName string, candidates []string) (int, error) {
+	if _, err := fmt.Fprintf(w, "'%s' matches several worktrees:\n", worktreeName); err != nil {
+		return -1, err
+	}
+	for i, candidate := range candidates {
+		if _, err := fmt.Fprintf(w, "  %d) %s\n", i+1, candidate); err != nil {
+			return -1, err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "Choose a worktree [1-%d]: ", len(candidates)); err != nil {
+		return -1, err
+	}
+
+	line, err := bufio.NewReader(input).ReadString('\n')
+	if err != nil && line == "" {
+		return -1, nil
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil || n < 1 || n > len(candidates) {
+		return -1, nil
+	}
+	return n - 1, nil
+}
+
+// fuzzyMatchWorktrees returns the managed worktrees whose branch or worktree name matches
+// query loosely (case-insensitive prefix, path segment prefix, substring, or subsequence).
+// Matches of a tier below minTier are left out.
+func fuzzyMatchWorktrees(
+	query string, worktrees []git.Worktree, cfg *config.Config, mainWorktreePath string, minTier int,
+) []*git.Worktree {
+	query = strings.ToLower(strings.TrimSuffix(query, "*"))
+	if query == "" {
+		return nil
+	}
+
+	if cfg == nil {
+		cfg = &config.Config{Defaults: config.Defaults{BaseDir: config.DefaultBaseDir}}
+	}
+
+	bestTier := fuzzyTierNone
+	var matches []*git.Worktree
+	for i := range worktrees {
+		wt := &worktrees[i]
+		if !isWorktreeManagedCd(wt.Path, cfg, mainWorktreePath, wt.IsMain) {
+			continue
+		}
+
+		tier := fuzzyMatchTier(query, wt.Branch)
+		if !wt.IsMain {
+			tier = max(tier, fuzzyMatchTier(query, getWorktreeNameFromPath(wt.Path, cfg, mainWorktreePath, false)))
+		}
+		switch {
+		case tier < minTier || tier < bestTier:
+			continue
+		case tier > bestTier:
+			bestTier = tier
+			matches = matches[:0]
+		}
+		matches = append(matches, wt)
+	}
+	return matches
+}
+
+// fuzzyMatchTier rates how well the lower-cased query matches candidate.
+func fuzzyMatchTier(query, candidate string) int {
+	candidate = strings.ToLower(candidate)
+	switch {
+	case candidate == "":
+		return fuzzyTierNone
+	case strings.HasPrefix(candidate, query):
+		return fuzzyTierPrefix
+	case strings.Contains(candidate, "/"+query):
+		return fuzzyTierSegmentPrefix
+	case strings.Contains(candidate, query):
+		return fuzzyTierSubstring
+	case isSubsequence(query, candidate):
+		return fuzzyTierSubsequence
+	default:
+		return fuzzyTierNone
+	}
+}
+
+// isSubsequence reports whether the characters of query appear in candidate in order.
+func isSubsequence(query, candidate string) bool {
+	remaining := []rune(query)
+	for _, r := range candidate {
+		if len(remaining) == 0 {
+			break
+		}
+		if r == remaining[0] {
+			remaining = remaining[1:]
+		}
+	}
+	return len(remaining) == 0
+}
+
+// worktreeLabels names each of worktrees as worktreeLabel does.
+func worktreeLabels(worktrees []*git.Worktree, cfg *config.Config, mainWorktreePath string) []string {
+	labels := make([]string, 0, len(worktrees))
+	for _, wt := range worktrees {
+		labels = append(labels, worktreeLabel(wt, cfg, mainWorktreePath))
+	}
+	return labels
+}
+
+// worktreeLabel names a worktree in messages, such as ambiguity errors, preferring the
+// branch name.
+func worktreeLabel(wt *git.Worktree, cfg *config.Config, mainWorktreePath string) string {
+	switch {
+	case wt.Branch != "":
+		return wt.Branch
+	case cfg != nil:
+		return getWorktreeNameFromPath(wt.Path, cfg, mainWorktreePath, wt.IsMain)
+	default:
+		return filepath.Base(wt.Path)
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+)
+
+const fuzzyWorktreeList = `worktree /repo
+HEAD abc
+branch refs/heads/main
+
+worktree /worktrees/feature/auth-api
+HEAD def
+branch refs/heads/feature/auth-api
+
+worktree /worktrees/feature/auth-ui
+HEAD ghi
+branch refs/heads/feature/auth-ui
+
+worktree /worktrees/bugfix/OAuth-Token
+HEAD jkl
+branch refs/heads/bugfix/OAuth-Token
+
+worktree /elsewhere/payments
+HEAD mno
+branch refs/heads/payments
+
+`
+
+func TestFuzzyMatchWorktrees(t *testing.T) {
+	worktrees := parseWorktreesFromOutput(fuzzyWorktreeList)
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{"prefix beats segment prefix", "feat", []string{"feature/auth-api", "feature/auth-ui"}},
+		{"segment prefix beats substring", "auth", []string{"feature/auth-api", "feature/auth-ui"}},
+		{"case-insensitive substring", "token", []string{"bugfix/OAuth-Token"}},
+		{"subsequence", "oatok", []string{"bugfix/OAuth-Token"}},
+		{"main worktree by branch", "mai", []string{"main"}},
+		{"unmanaged worktrees are skipped", "pay", nil},
+		{"no match", "zzz", nil},
+		{"completion marker only", "*", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var branches []string
+			for _, wt := range fuzzyMatchWorktrees(tt.query, worktrees, nil, "/repo", fuzzyTierSubsequence) {
+				branches = append(branches, wt.Branch)
+			}
+			assert.Equal(t, tt.expected, branches)
+		})
+	}
+}
+
+func TestResolveWorktreeNameToChange(t *testing.T) {
+	worktrees := parseWorktreesFromOutput(fuzzyWorktreeList)
+
+	tests := []struct {
+		name     string
+		query    string
+		terminal bool
+		input    string
+		expected string
+		code     errors.Code
+	}{
+		{name: "exact name", query: "feature/auth-ui", expected: "feature/auth-ui"},
+		{name: "unique prefix", query: "feature/auth-a", expected: "feature/auth-api"},
+		{name: "unique segment prefix", query: "oauth", expected: "bugfix/OAuth-Token"},
+		{name: "ambiguous prefix", query: "feat", code: errors.CodeAmbiguousWorktree},
+		{name: "ambiguous prefix chosen", query: "feat", terminal: true, input: "2\n", expected: "feature/auth-ui"},
+		{name: "substring without a terminal", query: "token", code: errors.CodeUnconfirmedWorktreeMatch},
+		{name: "substring confirmed", query: "token", terminal: true, input: "y\n", expected: "bugfix/OAuth-Token"},
+		{name: "subsequence declined", query: "oatok", terminal: true, input: "n\n",
+			code: errors.CodeUnconfirmedWorktreeMatch},
+		{name: "no match", query: "zzz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withPickerTerminal(t, tt.terminal, tt.input)
+
+			wt, err := resolveWorktreeNameToChange(tt.query, worktrees, nil, "/repo", "remove")
+			if tt.code != "" {
+				code, _ := errors.CodeOf(err)
+				assert.Equal(t, tt.code, code)
+				return
+			}
+			require.NoError(t, err)
+			if tt.expected == "" {
+				assert.Nil(t, wt)
+				return
+			}
+			require.NotNil(t, wt)
+			assert.Equal(t, tt.expected, wt.Branch)
+		})
+	}
+}
+
+func TestResolveWorktreeNameToChange_ConfirmsSubstring(t *testing.T) {
+	worktrees := parseWorktreesFromOutput(`worktree /repo
+HEAD abc
+branch refs/heads/main
+
+worktree /worktrees/feature/user-auth
+HEAD def
+branch refs/heads/feature/user-auth
+
+worktree /worktrees/bugfix/login
+HEAD ghi
+branch refs/heads/bugfix/login
+
+`)
+	prompt := withPickerTerminal(t, true, "y\n")
+
+	wt, err := resolveWorktreeNameToChange("auth", worktrees, nil, "/repo", "remove")
+
+	require.NoError(t, err)
+	require.NotNil(t, wt)
+	assert.Equal(t, "feature/user-auth", wt.Branch)
+	assert.Equal(t, "'auth' matches feature/user-auth; remove it? [y/N]: ", prompt.String())
+}
+
+func TestCdCommand_FuzzyFallback(t *testing.T) {
+	executor := &mockInfoCommandExecutor{listOutput: fuzzyWorktreeList}
+
+	t.Run("single match", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := cdCommandWithCommandExecutor(nil, &buf, executor, "/repo", "auth-u")
+
+		require.NoError(t, err)
+		assert.Equal(t, "/worktrees/feature/auth-ui\n", buf.String())
+	})
+
+	t.Run("ambiguous", func(t *testing.T) {
+		withPickerTerminal(t, false, "")
+		var buf bytes.Buffer
+		err := cdCommandWithCommandExecutor(nil, &buf, executor, "/repo", "auth")
+
+		require.Error(t, err)
+		code, _ := errors.CodeOf(err)
+		assert.Equal(t, errors.CodeAmbiguousWorktree, code)
+		assert.Contains(t, err.Error(), "• feature/auth-api")
+		assert.Contains(t, err.Error(), "• feature/auth-ui")
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := cdCommandWithCommandExecutor(nil, &buf, executor, "/repo", "zzz")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "worktree 'zzz' not found")
+	})
+}
+
+func withPickerTerminal(t *testing.T, isTerminal bool, input string) *bytes.Buffer {
+	t.Helper()
+	originalInput, originalOutput, originalIsTerminal := pickerInput, pickerOutput, pickerIsTerminal
+	t.Cleanup(func() {
+		pickerInput, pickerOutput, pickerIsTerminal = originalInput, originalOutput, originalIsTerminal
+	})
+
+	var prompt bytes.Buffer
+	pickerInput = strings.NewReader(input)
+	pickerOutput = &prompt
+	pickerIsTerminal = func() bool { return isTerminal }
+	return &prompt
+}
+
+func TestResolveWorktreeName(t *testing.T) {
+	worktrees := parseWorktreesFromOutput(fuzzyWorktreeList)
+
+	t.Run("exact name wins over fuzzy matches", func(t *testing.T) {
+		wt, err := resolveWorktreeName("feature/auth-api", worktrees, nil, "/repo")
+		require.NoError(t, err)
+		assert.Equal(t, "feature/auth-api", wt.Branch)
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		wt, err := resolveWorktreeName("zzz", worktrees, nil, "/repo")
+		require.NoError(t, err)
+		assert.Nil(t, wt)
+	})
+
+	t.Run("interactive choice", func(t *testing.T) {
+		prompt := withPickerTerminal(t, true, "2\n")
+
+		wt, err := resolveWorktreeName("auth", worktrees, nil, "/repo")
+
+		require.NoError(t, err)
+		assert.Equal(t, "feature/auth-ui", wt.Branch)
+		assert.Contains(t, prompt.String(), "'auth' matches several worktrees:\n"+
+			"  1) feature/auth-api\n  2) feature/auth-ui\nChoose a worktree [1-2]: ")
+	})
+
+	t.Run("invalid choice", func(t *testing.T) {
+		withPickerTerminal(t, true, "3\n")
+
+		_, err := resolveWorktreeName("auth", worktrees, nil, "/repo")
+
+		code, _ := errors.CodeOf(err)
+		assert.Equal(t, errors.CodeAmbiguousWorktree, code)
+	})
+}
+
+func TestInfoCommand_FuzzyName(t *testing.T) {
+	executor := &mockInfoCommandExecutor{listOutput: fuzzyWorktreeList}
+	withPickerTerminal(t, false, "")
+
+	var buf bytes.Buffer
+	err := infoCommandWithCommandExecutor(&buf, executor, &config.Config{
+		Defaults: config.Defaults{BaseDir: config.DefaultBaseDir},
+	}, "/repo", "auth", false)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "worktree name 'auth' is ambiguous")
+}
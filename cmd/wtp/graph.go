@@ -0,0 +1,345 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+// Output formats of 'wtp graph'
+const (
+	graphFormatASCII = "ascii"
+	graphFormatDOT   = "dot"
+)
+
+// graphNode is a branch, or a detached worktree, in the branch graph.
+type graphNode struct {
+	key      string // branch name, or "detached:<path>" for a detached worktree
+	label    string
+	worktree string // worktree display name; empty when the branch is not checked out
+	isMain   bool
+	parent   *graphNode
+	children []*graphNode
+}
+
+// NewGraphCommand creates the graph command definition
+func NewGraphCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "graph",
+		Usage:     "Show how branches and worktrees relate",
+		UsageText: "wtp graph [--format ascii|dot]",
+		Description: "Draws the branches checked out in worktrees as a tree: every branch hangs below " +
+			"the branch it was created from, so stacked branches form chains. The base recorded by " +
+			"'wtp add' is used when present; otherwise the closest ancestor branch is found with git.\n\n" +
+			"Examples:\n" +
+			"  wtp graph                              # ASCII tree\n" +
+			"  wtp graph --format dot | dot -Tsvg > worktrees.svg",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "format",
+				Usage: "Output format: ascii or dot",
+				Value: graphFormatASCII,
+			},
+		},
+		Action: graphCommand,
+	}
+}
+
+func graphCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	format := cmd.String("format")
+	if format != graphFormatASCII && format != graphFormatDOT {
+		return errors.InvalidArguments("unsupported --format %q: must be 'ascii' or 'dot'", format)
+	}
+
+	_, cfg, _, err := setupRepoAndConfig()
+	if err != nil {
+		return err
+	}
+
+	return graphCommandWithCommandExecutor(w, command.NewRealExecutor(), cfg, format)
+}
+
+func graphCommandWithCommandExecutor(w io.Writer, executor command.Executor, cfg *config.Config, format string) error {
+	result, err := executor.Execute([]command.Command{command.GitWorktreeList()})
+	if err != nil {
+		return errors.GitCommandFailed("git worktree list", err.Error())
+	}
+	worktrees := parseWorktreesFromOutput(result.Results[0].Output)
+	mainWorktreePath := findMainWorktreePath(worktrees)
+
+	roots := buildBranchGraph(executor, worktrees, cfg, mainWorktreePath)
+	if format == graphFormatDOT {
+		return writeGraphDOT(w, roots)
+	}
+	return writeGraphASCII(w, roots)
+}
+
+// buildBranchGraph links every worktree branch to its parent and returns the roots,
+// the main worktree's branch first.
+func buildBranchGraph(
+	executor command.Executor, worktrees []git.Worktree, cfg *config.Config, mainWorktreePath string,
+) []*graphNode {
+	nodes := make(map[string]*graphNode)
+	var branches []*graphNode // nodes that can have or be a parent, in rank order
+	var detached []*graphNode
+	bases := make(map[string]string)
+
+	for i := range worktrees {
+		wt := &worktrees[i]
+		name := getWorktreeDisplayName(*wt, cfg, mainWorktreePath)
+		if wt.Branch == detachedKeyword || wt.Branch == "" {
+			head := wt.HEAD
+			if len(head) > headDisplayLength {
+				head = head[:headDisplayLength]
+			}
+			detached = append(detached, &graphNode{
+				key: "detached:" + wt.Path, label: "(detached HEAD " + head + ")", worktree: name, isMain: wt.IsMain,
+			})
+			continue
+		}
+		node := &graphNode{key: wt.Branch, label: wt.Branch, worktree: name, isMain: wt.IsMain}
+		nodes[wt.Branch] = node
+		branches = append(branches, node)
+		if record, _ := loadProvisionRecord(cfg, wt.Path); record != nil && isGraphBase(record.Base, wt.Branch) {
+			bases[wt.Branch] = record.Base
+		}
+	}
+
+	// Recorded bases may name branches without a worktree, e.g. "origin/main"; they are
+	// appended to branches but need no base lookup themselves
+	for _, node := range branches {
+		base, ok := bases[node.key]
+		if !ok {
+			continue
+		}
+		parent := nodes[base]
+		if parent == nil {
+			parent = &graphNode{key: base, label: base}
+			nodes[base] = parent
+			branches = append(branches, parent)
+		}
+		if !isGraphAncestor(node, parent) {
+			node.parent = parent
+		}
+	}
+
+	sortGraphNodes(branches)
+	inferGraphParents(executor, branches)
+
+	var roots []*graphNode
+	for _, node := range branches {
+		if node.parent == nil {
+			roots = append(roots, node)
+		} else {
+			node.parent.children = append(node.parent.children, node)
+		}
+	}
+	for _, node := range branches {
+		sortGraphNodes(node.children)
+	}
+	return append(roots, detached...)
+}
+
+// isGraphBase reports whether a recorded base names a parent of branch. Bases such as
+// "HEAD" or branch's own remote-tracking ref say nothing about the parent.
+func isGraphBase(base, branch string) bool {
+	return base != "" && base != "HEAD" && base != branch && !strings.HasSuffix(base, "/"+branch)
+}
+
+// isGraphAncestor reports whether candidate is node itself or hangs below node, i.e.
+// whether making node the child of candidate would create a cycle.
+func isGraphAncestor(node, candidate *graphNode) bool {
+	for n := candidate; n != nil; n = n.parent {
+		if n == node {
+			return true
+		}
+	}
+	return false
+}
+
+// graphParentCandidate is a branch that may be the parent of child.
+type graphParentCandidate struct{ child, candidate *graphNode }
+
+// inferGraphParents gives every node without a parent the closest branch it contains, i.e.
+// the candidate with the fewest commits between them.
+func inferGraphParents(executor command.Executor, branches []*graphNode) {
+	var pairs []graphParentCandidate
+	var commands []command.Command
+	for _, child := range branches {
+		if child.parent != nil {
+			continue
+		}
+		for _, candidate := range branches {
+			if candidate != child {
+				pairs = append(pairs, graphParentCandidate{child, candidate})
+				commands = append(commands, command.GitRevListLeftRightCount(candidate.key, child.key))
+			}
+		}
+	}
+	if len(commands) == 0 {
+		return
+	}
+	result, err := executor.Execute(commands)
+	if err != nil {
+		return
+	}
+
+	best := closestGraphParents(branches, pairs, result.Results)
+	for _, child := range branches {
+		if parent := best[child]; parent != nil && !isGraphAncestor(child, parent) {
+			child.parent = parent
+		}
+	}
+}
+
+// closestGraphParents picks, for every child, the candidate it contains with the fewest
+// commits in between. Branches at the same commit are ordered by rank so that the earlier
+// one becomes the parent.
+func closestGraphParents(
+	branches []*graphNode, pairs []graphParentCandidate, results []command.Result,
+) map[*graphNode]*graphNode {
+	rank := make(map[*graphNode]int, len(branches))
+	for i, node := range branches {
+		rank[node] = i
+	}
+	distance := make(map[*graphNode]int)
+	best := make(map[*graphNode]*graphNode)
+	for i := range pairs {
+		if i >= len(results) || results[i].Error != nil {
+			continue
+		}
+		onlyCandidate, onlyChild, ok := parseLeftRightCount(results[i].Output)
+		if !ok || onlyCandidate != 0 {
+			continue // candidate has commits the child does not contain
+		}
+		child, candidate := pairs[i].child, pairs[i].candidate
+		if onlyChild == 0 && rank[candidate] > rank[child] {
+			continue // same commit: only the higher ranked branch can be the parent
+		}
+		if current, seen := distance[child]; !seen || onlyChild < current {
+			distance[child] = onlyChild
+			best[child] = candidate
+		}
+	}
+	return best
+}
+
+// parseLeftRightCount parses the "<left>\t<right>" output of 'git rev-list --left-right --count'.
+func parseLeftRightCount(output string) (left, right int, ok bool) {
+	fields := strings.Fields(output)
+	if len(fields) != 2 { //nolint:mnd // left and right counts
+		return 0, 0, false
+	}
+	left, leftErr := strconv.Atoi(fields[0])
+	right, rightErr := strconv.Atoi(fields[1])
+	return left, right, leftErr == nil && rightErr == nil
+}
+
+// sortGraphNodes orders the main worktree's branch first, then branches with a worktree,
+// then by name.
+func sortGraphNodes(nodes []*graphNode) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		a, b := nodes[i], nodes[j]
+		if a.isMain != b.isMain {
+			return a.isMain
+		}
+		if (a.worktree != "") != (b.worktree != "") {
+			return a.worktree != ""
+		}
+		return a.key < b.key
+	})
+}
+
+func graphNodeText(node *graphNode) string {
+	if node.worktree == "" {
+		return node.label + "  (no worktree)"
+	}
+	if node.worktree == node.label {
+		return node.label
+	}
+	return fmt.Sprintf("%s  [%s]", node.label, node.worktree)
+}
+
+func writeGraphASCII(w io.Writer, roots []*graphNode) error {
+	if len(roots) == 0 {
+		_, err := fmt.Fprintln(w, "No worktrees found")
+		return err
+	}
+	for _, root := range roots {
+		if _, err := fmt.Fprintln(w, graphNodeText(root)); err != nil {
+			return err
+		}
+		if err := writeGraphChildren(w, root.children, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeGraphChildren(w io.Writer, children []*graphNode, prefix string) error {
+	for i, child := range children {
+		connector, indent := "├── ", "│   "
+		if i == len(children)-1 {
+			connector, indent = "└── ", "    "
+		}
+		if _, err := fmt.Fprintf(w, "%s%s%s\n", prefix, connector, graphNodeText(child)); err != nil {
+			return err
+		}
+		if err := writeGraphChildren(w, child.children, prefix+indent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeGraphDOT(w io.Writer, roots []*graphNode) error {
+	if _, err := fmt.Fprintln(w, "digraph wtp {\n  rankdir=LR;\n  node [shape=box];"); err != nil {
+		return err
+	}
+	var writeNode func(node *graphNode) error
+	writeNode = func(node *graphNode) error {
+		label := node.label
+		style := ""
+		if node.worktree == "" {
+			style = ", style=dashed"
+		} else if node.worktree != node.label {
+			label += "\n" + node.worktree
+		}
+		if _, err := fmt.Fprintf(w, "  %s [label=%s%s];\n",
+			strconv.Quote(node.key), strconv.Quote(label), style); err != nil {
+			return err
+		}
+		for _, child := range node.children {
+			if _, err := fmt.Fprintf(w, "  %s -> %s;\n", strconv.Quote(node.key), strconv.Quote(child.key)); err != nil {
+				return err
+			}
+			if err := writeNode(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, root := range roots {
+		if err := writeNode(root); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
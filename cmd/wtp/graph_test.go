@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+// mockGraphCommandExecutor answers 'git rev-list --left-right --count a...b' from a table
+// of commit counts; unknown pairs share no history.
+type mockGraphCommandExecutor struct {
+	listOutput string
+	counts     map[string]string // "a...b" → "<only a>\t<only b>"
+}
+
+func (m *mockGraphCommandExecutor) Execute(commands []command.Command) (*command.ExecutionResult, error) {
+	results := make([]command.Result, len(commands))
+	for i, cmd := range commands {
+		results[i].Command = cmd
+		if cmd.Args[0] == "worktree" {
+			results[i].Output = m.listOutput
+			continue
+		}
+		count, ok := m.counts[cmd.Args[len(cmd.Args)-1]]
+		if !ok {
+			count = "5\t5"
+		}
+		results[i].Output = count + "\n"
+	}
+	return &command.ExecutionResult{Results: results}, nil
+}
+
+func TestNewGraphCommand(t *testing.T) {
+	cmd := NewGraphCommand()
+
+	assert.Equal(t, "graph", cmd.Name)
+	assert.NotEmpty(t, cmd.Usage)
+	assert.NotNil(t, cmd.Action)
+}
+
+func setupGraphTest(t *testing.T) (root string, mockExec *mockGraphCommandExecutor) {
+	t.Helper()
+	root = t.TempDir()
+	mainPath := filepath.Join(root, "repo")
+	var list strings.Builder
+	for _, wt := range []struct{ path, branch string }{
+		{mainPath, "main"},
+		{filepath.Join(root, "worktrees", "feature", "api"), "feature/api"},
+		{filepath.Join(root, "worktrees", "feature", "api-ui"), "feature/api-ui"},
+		{filepath.Join(root, "worktrees", "hotfix"), "hotfix"},
+		{filepath.Join(root, "worktrees", "release"), "release"},
+	} {
+		require.NoError(t, os.MkdirAll(wt.path, 0o755))
+		fmt.Fprintf(&list, "worktree %s\nHEAD abc123\nbranch refs/heads/%s\n\n", wt.path, wt.branch)
+	}
+	fmt.Fprintf(&list, "worktree %s\nHEAD 0123456789abcdef\ndetached\n\n", filepath.Join(root, "scratch"))
+
+	mockExec = &mockGraphCommandExecutor{
+		listOutput: list.String(),
+		counts: map[string]string{
+			"main...feature/api":           "0\t3",
+			"main...feature/api-ui":        "0\t5",
+			"feature/api...feature/api-ui": "0\t2",
+			"main...hotfix":                "0\t1",
+			"main...release":               "0\t0", // release points at the same commit as main
+			"release...main":               "0\t0",
+			"release...feature/api":        "0\t3",
+			"release...feature/api-ui":     "0\t5",
+			"release...hotfix":             "0\t1",
+		},
+	}
+	return root, mockExec
+}
+
+func TestGraphCommand_ASCII(t *testing.T) {
+	_, mockExec := setupGraphTest(t)
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+
+	var buf bytes.Buffer
+	require.NoError(t, graphCommandWithCommandExecutor(&buf, mockExec, cfg, graphFormatASCII))
+
+	assert.Equal(t, `main  [@]
+├── feature/api
+│   └── feature/api-ui
+├── hotfix
+└── release
+(detached HEAD 01234567)  [../scratch]
+`, buf.String())
+}
+
+func TestGraphCommand_UsesRecordedBase(t *testing.T) {
+	root, mockExec := setupGraphTest(t)
+	hotfixPath := filepath.Join(root, "worktrees", "hotfix")
+	setupInfoWorktree(t, hotfixPath)
+	require.NoError(t, saveProvisionRecord(&config.Config{}, hotfixPath,
+		&provisionRecord{Branch: "hotfix", Base: "origin/release"}))
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+
+	var buf bytes.Buffer
+	require.NoError(t, graphCommandWithCommandExecutor(&buf, mockExec, cfg, graphFormatDOT))
+
+	output := buf.String()
+	assert.True(t, strings.HasPrefix(output, "digraph wtp {\n"))
+	assert.Contains(t, output, `"origin/release" [label="origin/release", style=dashed];`)
+	assert.Contains(t, output, `"origin/release" -> "hotfix";`)
+	assert.Contains(t, output, `"main" -> "feature/api";`)
+	assert.Contains(t, output, `"feature/api" -> "feature/api-ui";`)
+	assert.NotContains(t, output, `"main" -> "hotfix";`)
+	assert.True(t, strings.HasSuffix(output, "}\n"))
+}
+
+func TestIsGraphBase(t *testing.T) {
+	assert.True(t, isGraphBase("main", "feature/x"))
+	assert.False(t, isGraphBase("", "feature/x"))
+	assert.False(t, isGraphBase("HEAD", "feature/x"))
+	assert.False(t, isGraphBase("origin/feature/x", "feature/x"), "own remote-tracking ref")
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/errors"
+)
+
+// Search tools 'wtp grep' delegates to
+const (
+	grepEngineAuto    = "auto"
+	grepEngineGit     = "git"
+	grepEngineRipgrep = "rg"
+)
+
+const (
+	defaultGrepParallel = 4
+	// grepNoMatchExitCode is the exit status of git grep and rg when nothing matched.
+	grepNoMatchExitCode = 1
+)
+
+// grepLookPath finds the rg executable; tests replace it.
+var grepLookPath = exec.LookPath
+
+// grepOptions are the search 'wtp grep' runs in every worktree.
+type grepOptions struct {
+	pattern   string
+	pathspecs []string
+	// engine is grepEngineGit or grepEngineRipgrep.
+	engine       string
+	ignoreCase   bool
+	fixedStrings bool
+	// worktreesOnly prints the names of the worktrees with a match instead of the matches.
+	worktreesOnly bool
+	parallel      int
+}
+
+// NewGrepCommand creates the grep command definition
+func NewGrepCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "grep",
+		Usage:     "Search every worktree for a pattern",
+		UsageText: "wtp grep [--branch <glob>] [-i] [-F] [-l] [--engine auto|git|rg] <pattern> [-- <path>...]",
+		Description: "Searches the files of every worktree, or those whose branch matches --branch, " +
+			"in parallel. Each match is printed as '[<worktree>] <file>:<line>:<text>', grouped by " +
+			"worktree in 'wtp list' order, which answers which in-flight branch contains a change.\n\n" +
+			"The search is delegated to ripgrep when it is installed, and to git grep otherwise; " +
+			"--engine picks one. ripgrep also searches untracked files that are not ignored, git " +
+			"grep only tracked ones. Patterns are extended regular expressions unless -F is given.\n\n" +
+			"Exits with an error when nothing matched, like grep.\n\n" +
+			"Examples:\n" +
+			"  wtp grep 'func retryPolicy'              # Every worktree\n" +
+			"  wtp grep -l TODO-1234                    # Only the names of worktrees that match\n" +
+			"  wtp grep -b 'feature/*' -i apikey -- src # Feature branches, under src/ only",
+		ArgsUsage: "<pattern> [-- <path>...]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "branch",
+				Aliases: []string{"b", "branch-filter"},
+				Usage:   "Search the worktrees whose branch matches the glob (e.g. 'feature/*')",
+			},
+			&cli.BoolFlag{
+				Name:    "ignore-case",
+				Aliases: []string{"i"},
+				Usage:   "Match case-insensitively",
+			},
+			&cli.BoolFlag{
+				Name:    "fixed-strings",
+				Aliases: []string{"F"},
+				Usage:   "Treat the pattern as a literal string",
+			},
+			&cli.BoolFlag{
+				Name:    "worktrees-with-matches",
+				Aliases: []string{"l"},
+				Usage:   "Print only the names of the worktrees with a match",
+			},
+			&cli.StringFlag{
+				Name:  "engine",
+				Usage: "Search tool: auto (ripgrep when installed), git, or rg",
+				Value: grepEngineAuto,
+			},
+			&cli.IntFlag{
+				Name:    "parallel",
+				Aliases: []string{"j"},
+				Usage:   "Search up to n worktrees at once",
+				Value:   defaultGrepParallel,
+			},
+		},
+		Action: grepCommand,
+	}
+}
+
+func grepCommand(ctx context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+	errWriter := cmd.Root().ErrWriter
+	if errWriter == nil {
+		errWriter = os.Stderr
+	}
+
+	args := cmd.Args().Slice()
+	if len(args) == 0 || args[0] == "" {
+		return errors.InvalidArguments("no pattern given; usage: wtp grep [--branch <glob>] <pattern> [-- <path>...]")
+	}
+	if cmd.Int("parallel") < 1 {
+		return errors.InvalidArguments("--parallel must be at least 1, got %d", cmd.Int("parallel"))
+	}
+	engine, err := resolveGrepEngine(cmd.String("engine"))
+	if err != nil {
+		return err
+	}
+	opts := &grepOptions{
+		pattern:       args[0],
+		pathspecs:     args[1:],
+		engine:        engine,
+		ignoreCase:    cmd.Bool("ignore-case"),
+		fixedStrings:  cmd.Bool("fixed-strings"),
+		worktreesOnly: cmd.Bool("worktrees-with-matches"),
+		parallel:      int(cmd.Int("parallel")),
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return errors.DirectoryAccessFailed("access current", ".", err)
+	}
+	_, cfg, mainRepoPath, err := setupRepoAndConfig()
+	if err != nil {
+		return err
+	}
+	result, err := command.NewRealExecutor().Execute([]command.Command{command.GitWorktreeList()})
+	if err != nil {
+		return errors.GitCommandFailed("git worktree list", err.Error())
+	}
+	worktrees := parseWorktreesFromOutput(result.Results[0].Output)
+	branchGlob := cmd.String("branch")
+	targets, err := selectExecTargets(worktrees, cfg, mainRepoPath, cwd, branchGlob == "", branchGlob)
+	if err != nil {
+		return err
+	}
+	return runGrepInWorktrees(ctx, w, errWriter, targets, opts)
+}
+
+// resolveGrepEngine checks the --engine value and resolves auto to ripgrep when it is
+// installed, git grep otherwise.
+func resolveGrepEngine(engine string) (string, error) {
+	_, rgErr := grepLookPath(grepEngineRipgrep)
+	switch engine {
+	case grepEngineAuto:
+		if rgErr == nil {
+			return grepEngineRipgrep, nil
+		}
+		return grepEngineGit, nil
+	case grepEngineGit:
+		return grepEngineGit, nil
+	case grepEngineRipgrep:
+		if rgErr != nil {
+			return "", fmt.Errorf("--engine rg: ripgrep is not installed; use --engine git")
+		}
+		return grepEngineRipgrep, nil
+	default:
+		return "", errors.InvalidArguments("invalid --engine '%s': must be auto, git, or rg", engine)
+	}
+}
+
+// grepArgs returns the command line that runs the search in a worktree.
+func grepArgs(opts *grepOptions) []string {
+	var args []string
+	if opts.engine == grepEngineRipgrep {
+		args = []string{"rg", "--line-number", "--no-heading", "--color=never"}
+	} else {
+		// -I skips binary files, as rg does by default
+		args = []string{"git", "grep", "--line-number", "-I", "--color=never"}
+	}
+	switch {
+	case opts.fixedStrings:
+		args = append(args, "--fixed-strings")
+	case opts.engine == grepEngineGit:
+		args = append(args, "--extended-regexp")
+	}
+	if opts.ignoreCase {
+		args = append(args, "--ignore-case")
+	}
+	if opts.worktreesOnly {
+		args = append(args, "--quiet")
+	}
+	args = append(args, "-e", opts.pattern)
+	if len(opts.pathspecs) > 0 {
+		args = append(append(args, "--"), opts.pathspecs...)
+	}
+	return args
+}
+
+// runGrepInWorktrees searches every target, up to opts.parallel at once. The results of
+// each target are printed, prefixed with its name, once it and the targets before it are
+// done, so the output is grouped by worktree in order. It returns an error when the search
+// failed somewhere or nothing matched.
+func runGrepInWorktrees(ctx context.Context, w, errWriter io.Writer, targets []execTarget, opts *grepOptions) error {
+	outputs := make([]bytes.Buffer, len(targets))
+	matched := make([]bool, len(targets))
+	failures := make([]error, len(targets))
+	done := make([]bool, len(targets))
+
+	var mu sync.Mutex
+	var writeErr error
+	next := 0
+	finish := func(i int) {
+		mu.Lock()
+		defer mu.Unlock()
+		done[i] = true
+		for ; next < len(targets) && done[next]; next++ {
+			if err := writeGrepResult(w, errWriter, targets[next], &outputs[next], matched[next], failures[next],
+				opts); err != nil && writeErr == nil {
+				writeErr = err
+			}
+		}
+	}
+
+	slots := make(chan struct{}, opts.parallel)
+	var wg sync.WaitGroup
+	for i := range targets {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			var outputMu sync.Mutex
+			stdout := &prefixWriter{mu: &outputMu, out: &outputs[i], prefix: "[" + targets[i].name + "] "}
+			matched[i], failures[i] = grepWorktree(ctx, opts, targets[i].path, stdout)
+			_ = stdout.Flush()
+			finish(i)
+		}(i)
+	}
+	wg.Wait()
+	if writeErr != nil {
+		return writeErr
+	}
+
+	var failed, withMatches []string
+	for i := range targets {
+		if failures[i] != nil {
+			failed = append(failed, targets[i].name)
+		}
+		if matched[i] {
+			withMatches = append(withMatches, targets[i].name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("search failed in: %s", strings.Join(failed, ", "))
+	}
+	if len(withMatches) == 0 {
+		return fmt.Errorf("no matches for '%s' in %d worktree(s)", opts.pattern, len(targets))
+	}
+	return nil
+}
+
+// writeGrepResult prints the outcome of the search in one target.
+func writeGrepResult(
+	w, errWriter io.Writer, target execTarget, output *bytes.Buffer, matched bool, failure error, opts *grepOptions,
+) error {
+	if failure != nil {
+		_, err := fmt.Fprintf(errWriter, "✗ %s: %v\n", target.name, failure)
+		return err
+	}
+	if opts.worktreesOnly {
+		if !matched {
+			return nil
+		}
+		_, err := fmt.Fprintln(w, target.name)
+		return err
+	}
+	_, err := output.WriteTo(w)
+	return err
+}
+
+// grepWorktree runs the search in the worktree at worktreePath, writing the matches to
+// stdout, and reports whether anything matched.
+func grepWorktree(ctx context.Context, opts *grepOptions, worktreePath string, stdout io.Writer) (bool, error) {
+	args := grepArgs(opts)
+	// #nosec G204 -- the arguments are the pattern and paths the user searches for
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = worktreePath
+	var stderr bytes.Buffer
+	cmd.Stdout = stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return true, nil
+	case stderrors.As(err, &exitErr) && exitErr.ExitCode() == grepNoMatchExitCode:
+		return false, nil
+	case stderr.Len() > 0:
+		return false, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	default:
+		return false, err
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGrepCommand(t *testing.T) {
+	cmd := NewGrepCommand()
+
+	assert.Equal(t, "grep", cmd.Name)
+	assert.NotEmpty(t, cmd.Usage)
+	assert.NotNil(t, cmd.Action)
+}
+
+func TestResolveGrepEngine(t *testing.T) {
+	original := grepLookPath
+	t.Cleanup(func() { grepLookPath = original })
+
+	grepLookPath = func(string) (string, error) { return "", exec.ErrNotFound }
+	engine, err := resolveGrepEngine(grepEngineAuto)
+	require.NoError(t, err)
+	assert.Equal(t, grepEngineGit, engine)
+	_, err = resolveGrepEngine(grepEngineRipgrep)
+	assert.ErrorContains(t, err, "ripgrep is not installed")
+
+	grepLookPath = func(string) (string, error) { return "/usr/bin/rg", nil }
+	engine, err = resolveGrepEngine(grepEngineAuto)
+	require.NoError(t, err)
+	assert.Equal(t, grepEngineRipgrep, engine)
+	engine, err = resolveGrepEngine(grepEngineGit)
+	require.NoError(t, err)
+	assert.Equal(t, grepEngineGit, engine)
+
+	_, err = resolveGrepEngine("ag")
+	assert.ErrorContains(t, err, "invalid --engine 'ag'")
+}
+
+func TestGrepArgs(t *testing.T) {
+	opts := &grepOptions{pattern: "-v", engine: grepEngineGit, pathspecs: []string{"src"}}
+	assert.Equal(t, []string{"git", "grep", "--line-number", "-I", "--color=never", "--extended-regexp",
+		"-e", "-v", "--", "src"}, grepArgs(opts))
+
+	opts = &grepOptions{pattern: "a.b", engine: grepEngineRipgrep, fixedStrings: true, ignoreCase: true,
+		worktreesOnly: true}
+	assert.Equal(t, []string{"rg", "--line-number", "--no-heading", "--color=never", "--fixed-strings",
+		"--ignore-case", "--quiet", "-e", "a.b"}, grepArgs(opts))
+}
+
+// newGrepTestWorktree creates a repository whose only tracked file has content.
+func newGrepTestWorktree(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := exec.Command("git", "init", "-q", dir).Run(); err != nil {
+		t.Skip("git not available")
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte(content), 0o644))
+	gitAdd := exec.Command("git", "add", "notes.txt")
+	gitAdd.Dir = dir
+	require.NoError(t, gitAdd.Run())
+	return dir
+}
+
+func TestRunGrepInWorktrees(t *testing.T) {
+	targets := []execTarget{
+		{name: "@", path: newGrepTestWorktree(t, "nothing here\n")},
+		{name: "feature/a", path: newGrepTestWorktree(t, "first\nretry policy\n")},
+		{name: "fix/b", path: newGrepTestWorktree(t, "Retry Policy\n")},
+	}
+	opts := &grepOptions{pattern: "retry pol", engine: grepEngineGit, parallel: 2}
+
+	var stdout, stderr bytes.Buffer
+	require.NoError(t, runGrepInWorktrees(context.Background(), &stdout, &stderr, targets, opts))
+	assert.Equal(t, "[feature/a] notes.txt:2:retry policy\n", stdout.String())
+	assert.Empty(t, stderr.String())
+
+	stdout.Reset()
+	opts.ignoreCase = true
+	require.NoError(t, runGrepInWorktrees(context.Background(), &stdout, &stderr, targets, opts))
+	assert.Equal(t, "[feature/a] notes.txt:2:retry policy\n[fix/b] notes.txt:1:Retry Policy\n", stdout.String())
+
+	stdout.Reset()
+	opts.worktreesOnly = true
+	require.NoError(t, runGrepInWorktrees(context.Background(), &stdout, &stderr, targets, opts))
+	assert.Equal(t, "feature/a\nfix/b\n", stdout.String())
+
+	stdout.Reset()
+	opts.pattern = "absent"
+	err := runGrepInWorktrees(context.Background(), &stdout, &stderr, targets, opts)
+	assert.EqualError(t, err, "no matches for 'absent' in 3 worktree(s)")
+	assert.Empty(t, stdout.String())
+
+	opts.pattern, opts.worktreesOnly = "(", false
+	err = runGrepInWorktrees(context.Background(), &stdout, &stderr, targets[:1], opts)
+	assert.EqualError(t, err, "search failed in: @")
+	assert.Contains(t, stderr.String(), "✗ @: exit status")
+}
@@ -0,0 +1,376 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/runtimedir"
+)
+
+const hibernationFileName = "wtp-hibernate.json"
+
+// hibernationState records what 'wtp hibernate' removed from a worktree. Like the provision
+// record it lives in the worktree's runtime directory, <worktree>/.wtp or under
+// defaults.runtime_dir; 'wtp wake' deletes it.
+type hibernationState struct {
+	HibernatedAt time.Time `json:"hibernated_at"`
+	// Paths are the removed paths, relative to the worktree.
+	Paths      []string `json:"paths"`
+	FreedBytes int64    `json:"freed_bytes"`
+}
+
+// NewHibernateCommand creates the hibernate command definition
+func NewHibernateCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "hibernate",
+		Usage:     "Remove regenerable artifacts from a worktree to reclaim disk space",
+		UsageText: "wtp hibernate [--dry-run] [<worktree-name>]",
+		Description: "Deletes the paths matching the 'hibernate' globs in .wtp.yml, such as dependencies, " +
+			"build output, and caches, and marks the worktree hibernated. Only paths git ignores are " +
+			"removed, so the branch, commits, and uncommitted changes stay intact. 'wtp wake' restores " +
+			"the worktree by re-running the hooks that recreate them. Without a name, the current " +
+			"worktree is used.\n\n" +
+			"Examples:\n" +
+			"  wtp hibernate feature/old             # Reclaim space\n" +
+			"  wtp hibernate --dry-run feature/old   # Show what would be removed\n" +
+			"  wtp wake feature/old                  # Restore it",
+		ArgsUsage: "[<worktree-name>]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Show what would be removed without deleting anything",
+			},
+		},
+		ShellComplete: completeWorktreesForCd,
+		Action:        hibernateCommand,
+	}
+}
+
+// NewWakeCommand creates the wake command definition
+func NewWakeCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "wake",
+		Usage:     "Restore a hibernated worktree",
+		UsageText: "wtp wake [--all] [<worktree-name>]",
+		Description: "Re-runs the post_create hooks that recreate what 'wtp hibernate' removed: command " +
+			"hooks, and hooks whose destination lies inside a removed path. With --all, every " +
+			"post_create hook runs. Without a name, the current worktree is used.",
+		ArgsUsage: "[<worktree-name>]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "all",
+				Usage: "Run every post_create hook",
+			},
+		},
+		ShellComplete: completeWorktreesForCd,
+		Action:        wakeCommand,
+	}
+}
+
+func hibernateCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	_, cfg, _, err := setupRepoAndConfig()
+	if err != nil {
+		return err
+	}
+	dryRun := cmd.Bool("dry-run")
+	if !dryRun {
+		if err := ensureWritable(cfg, "hibernate worktrees"); err != nil {
+			return err
+		}
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return errors.DirectoryAccessFailed("access current", ".", err)
+	}
+
+	executor := command.NewRealExecutor()
+	return hibernateCommandWithCommandExecutor(w, executor, cfg, cwd, cmd.Args().First(), dryRun)
+}
+
+func wakeCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	_, cfg, mainRepoPath, err := setupRepoAndConfig()
+	if err != nil {
+		return err
+	}
+	if err := ensureWritable(cfg, "wake worktrees"); err != nil {
+		return err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return errors.DirectoryAccessFailed("access current", ".", err)
+	}
+
+	executor := command.NewRealExecutor()
+	return wakeCommandWithCommandExecutor(w, executor, cfg, mainRepoPath, cwd, cmd.Args().First(), cmd.Bool("all"))
+}
+
+func hibernateCommandWithCommandExecutor(
+	w io.Writer, executor command.Executor, cfg *config.Config, cwd, worktreeName string, dryRun bool,
+) error {
+	target, err := resolveHookWorktree(executor, cfg, cwd, worktreeName)
+	if err != nil {
+		return err
+	}
+	if target.worktree.IsMain {
+		return errors.MainWorktreeUnsupported("hibernate", "other worktrees' hooks read from it")
+	}
+	if !target.cfg.HasHibernatePatterns() {
+		_, err := fmt.Fprintln(w, "No hibernate patterns configured; add a 'hibernate' list to .wtp.yml.")
+		return err
+	}
+
+	root := target.worktree.Path
+	candidates := hibernateCandidates(root, target.cfg.Hibernate)
+	ignored, err := gitIgnoredPaths(executor, root, candidates)
+	if err != nil {
+		return err
+	}
+	if len(ignored) == 0 {
+		_, err := fmt.Fprintf(w, "Nothing to hibernate in %s: no ignored paths match the hibernate patterns\n",
+			target.name)
+		return err
+	}
+
+	freed, err := removeHibernatePaths(w, root, candidates, ignored, dryRun)
+	if err != nil || dryRun {
+		if err == nil {
+			_, err = fmt.Fprintf(w, "Would free %s in %s\n", formatDiskSize(freed), target.name)
+		}
+		return err
+	}
+
+	state, err := loadHibernationState(cfg, root)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		state = &hibernationState{HibernatedAt: time.Now().UTC()}
+	}
+	for _, path := range ignored {
+		if !slices.Contains(state.Paths, path) {
+			state.Paths = append(state.Paths, path)
+		}
+	}
+	state.FreedBytes += freed
+	if err := saveHibernationState(cfg, root, state); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "Hibernated %s: freed %s. Run 'wtp wake %s' to restore it.\n",
+		target.name, formatDiskSize(freed), target.name)
+	return err
+}
+
+// hibernateCandidates returns the paths under root matching patterns, relative to root and
+// sorted. Paths inside another match are dropped since removing the outer one covers them.
+func hibernateCandidates(root string, patterns []string) []string {
+	var matches []string
+	for _, pattern := range patterns {
+		found, _ := filepath.Glob(filepath.Join(root, pattern)) // patterns are validated on load
+		for _, match := range found {
+			if rel, err := filepath.Rel(root, match); err == nil && !slices.Contains(matches, rel) {
+				matches = append(matches, rel)
+			}
+		}
+	}
+	sort.Strings(matches)
+
+	var candidates []string
+	for _, match := range matches {
+		nested := slices.ContainsFunc(candidates, func(outer string) bool { return isPathWithin(outer, match) })
+		if !nested {
+			candidates = append(candidates, match)
+		}
+	}
+	return candidates
+}
+
+// gitIgnoredPaths returns the paths, relative to the worktree at root, that git ignores.
+// Tracked and untracked-but-not-ignored files are never hibernated.
+func gitIgnoredPaths(executor command.Executor, root string, paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	gitPaths := make([]string, len(paths))
+	for i, path := range paths {
+		gitPaths[i] = filepath.ToSlash(path)
+	}
+	result, err := executor.Execute([]command.Command{command.GitCheckIgnore(root, gitPaths)})
+	if err != nil {
+		return nil, errors.GitCommandFailed("git check-ignore", err.Error())
+	}
+	res := result.Results[0]
+	if res.Error != nil {
+		if strings.TrimSpace(res.Output) == "" {
+			return nil, nil // exit status 1: nothing is ignored
+		}
+		return nil, errors.GitCommandFailed("git check-ignore", res.Output)
+	}
+
+	reported := strings.Split(res.Output, "\n")
+	var ignored []string
+	for i, path := range paths {
+		if slices.Contains(reported, gitPaths[i]) {
+			ignored = append(ignored, path)
+		}
+	}
+	return ignored, nil
+}
+
+// removeHibernatePaths deletes the candidates (relative to root) git ignores and returns
+// the bytes they held. The other candidates are reported as kept.
+func removeHibernatePaths(w io.Writer, root string, candidates, ignored []string, dryRun bool) (int64, error) {
+	verb := "Removed"
+	if dryRun {
+		verb = "Would remove"
+	}
+
+	var freed int64
+	for _, path := range candidates {
+		if !slices.Contains(ignored, path) {
+			if _, err := fmt.Fprintf(w, "  Kept %s: not ignored by git\n", path); err != nil {
+				return freed, err
+			}
+			continue
+		}
+		fullPath := filepath.Join(root, path)
+		size := diskUsage(fullPath)
+		if !dryRun {
+			if err := os.RemoveAll(fullPath); err != nil {
+				return freed, errors.DirectoryAccessFailed("remove", fullPath, err)
+			}
+		}
+		freed += size
+		if _, err := fmt.Fprintf(w, "  %s %s (%s)\n", verb, path, formatDiskSize(size)); err != nil {
+			return freed, err
+		}
+	}
+	return freed, nil
+}
+
+func wakeCommandWithCommandExecutor(
+	w io.Writer, executor command.Executor, cfg *config.Config, mainRepoPath, cwd, worktreeName string, all bool,
+) error {
+	target, err := resolveHookWorktree(executor, cfg, cwd, worktreeName)
+	if err != nil {
+		return err
+	}
+	root := target.worktree.Path
+	state, err := loadHibernationState(cfg, root)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		_, err := fmt.Fprintf(w, "%s is not hibernated\n", target.name)
+		return err
+	}
+
+	numbers := wakeHookNumbers(target.cfg.Hooks.PostCreate, state.Paths, all)
+	if len(numbers) == 0 {
+		if _, err := fmt.Fprintln(w, "No post_create hooks recreate the hibernated paths"); err != nil {
+			return err
+		}
+	} else {
+		if _, err := fmt.Fprintf(w, "Waking %s: running %s...\n", target.name, formatHookNumbers(numbers)); err != nil {
+			return err
+		}
+		// On failure the worktree stays hibernated so that 'wtp wake' can be retried
+		if err := target.runHooks(w, mainRepoPath, numbers); err != nil {
+			return err
+		}
+	}
+
+	if err := clearHibernationState(cfg, root); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "✓ %s is awake\n", target.name)
+	return err
+}
+
+// wakeHookNumbers returns the 1-based numbers of the post_create hooks that recreate
+// removed paths: command and script hooks, whose output cannot be known, and hooks
+// writing inside a removed path. all selects every hook.
+func wakeHookNumbers(postCreate []config.Hook, removed []string, all bool) []int {
+	var numbers []int
+	for i := range postCreate {
+		hook := &postCreate[i]
+		relevant := all || runsShell(hook)
+		if target := hookTarget(hook); !relevant && target != "" && !filepath.IsAbs(target) {
+			relevant = slices.ContainsFunc(removed, func(path string) bool { return pathsOverlap(target, path) })
+		}
+		if relevant {
+			numbers = append(numbers, i+1)
+		}
+	}
+	return numbers
+}
+
+// loadHibernationState returns the hibernation state of the worktree at path, or nil when
+// it is not hibernated.
+func loadHibernationState(cfg *config.Config, path string) (*hibernationState, error) {
+	statePath, err := runtimedir.Path(cfg, path, hibernationFileName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// #nosec G304 -- path is derived from the worktree's runtime directory
+	data, err := os.ReadFile(statePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read hibernation state: %w", err)
+	}
+
+	var state hibernationState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse hibernation state %s: %w", statePath, err)
+	}
+	return &state, nil
+}
+
+func saveHibernationState(cfg *config.Config, path string, state *hibernationState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode hibernation state: %w", err)
+	}
+	if err := runtimedir.WriteFile(cfg, path, hibernationFileName, append(data, '\n'),
+		provisionRecordFileMode); err != nil {
+		return fmt.Errorf("failed to write hibernation state: %w", err)
+	}
+	return nil
+}
+
+func clearHibernationState(cfg *config.Config, path string) error {
+	if err := runtimedir.Remove(cfg, path, hibernationFileName); err != nil {
+		return fmt.Errorf("failed to clear hibernation state: %w", err)
+	}
+	return nil
+}
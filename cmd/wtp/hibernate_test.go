@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+)
+
+// mockHibernateCommandExecutor answers 'git worktree list' and reports the paths in ignored
+// as ignored by 'git check-ignore'.
+type mockHibernateCommandExecutor struct {
+	listOutput string
+	ignored    []string
+}
+
+func (m *mockHibernateCommandExecutor) Execute(commands []command.Command) (*command.ExecutionResult, error) {
+	results := make([]command.Result, len(commands))
+	for i, cmd := range commands {
+		results[i].Command = cmd
+		switch {
+		case cmd.Args[0] == "worktree":
+			results[i].Output = m.listOutput
+		case slices.Contains(cmd.Args, "check-ignore"):
+			var reported []string
+			for _, path := range cmd.Args[slices.Index(cmd.Args, "--")+1:] {
+				if slices.Contains(m.ignored, path) {
+					reported = append(reported, path)
+				}
+			}
+			results[i].Output = strings.Join(reported, "\n")
+			if len(reported) == 0 {
+				results[i].Error = assert.AnError
+			}
+		}
+	}
+	return &command.ExecutionResult{Results: results}, nil
+}
+
+func writeHibernateFile(t *testing.T, path string, size int) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, make([]byte, size), 0o644))
+}
+
+func TestNewHibernateCommands(t *testing.T) {
+	hibernate := NewHibernateCommand()
+	assert.Equal(t, "hibernate", hibernate.Name)
+	assert.NotNil(t, hibernate.Action)
+
+	wake := NewWakeCommand()
+	assert.Equal(t, "wake", wake.Name)
+	assert.NotNil(t, wake.Action)
+}
+
+func TestHibernateCandidates(t *testing.T) {
+	root := t.TempDir()
+	writeHibernateFile(t, filepath.Join(root, "node_modules", "pkg", "index.js"), 1)
+	writeHibernateFile(t, filepath.Join(root, "packages", "a", "node_modules", "x.js"), 1)
+	writeHibernateFile(t, filepath.Join(root, "packages", "b", "node_modules", "y.js"), 1)
+	writeHibernateFile(t, filepath.Join(root, "dist", "app.js"), 1)
+
+	candidates := hibernateCandidates(root, []string{
+		"node_modules", "node_modules/pkg", "packages/*/node_modules", "missing",
+	})
+
+	assert.Equal(t, []string{
+		"node_modules",
+		filepath.Join("packages", "a", "node_modules"),
+		filepath.Join("packages", "b", "node_modules"),
+	}, candidates)
+}
+
+func TestHibernateCommand_RemovesIgnoredPaths(t *testing.T) {
+	mainPath, worktreePath, listOutput := setupCheckoutTest(t)
+	setupInfoWorktree(t, worktreePath)
+	writeHibernateFile(t, filepath.Join(worktreePath, "node_modules", "pkg.js"), 2048)
+	writeHibernateFile(t, filepath.Join(worktreePath, "dist", "app.js"), 1024)
+	writeHibernateFile(t, filepath.Join(worktreePath, "vendor", "tracked.go"), 10)
+
+	cfg := &config.Config{
+		Defaults:  config.Defaults{BaseDir: "../worktrees"},
+		Hibernate: []string{"node_modules", "dist", "vendor"},
+	}
+	mockExec := &mockHibernateCommandExecutor{listOutput: listOutput, ignored: []string{"node_modules", "dist"}}
+
+	var buf bytes.Buffer
+	require.NoError(t, hibernateCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, "feature/foo", false))
+
+	output := buf.String()
+	assert.Contains(t, output, "Removed node_modules (2.0 KiB)")
+	assert.Contains(t, output, "Removed dist (1.0 KiB)")
+	assert.Contains(t, output, "Kept vendor: not ignored by git")
+	assert.Contains(t, output, "Hibernated feature/foo: freed 3.0 KiB. Run 'wtp wake feature/foo' to restore it.")
+	assert.NoDirExists(t, filepath.Join(worktreePath, "node_modules"))
+	assert.NoDirExists(t, filepath.Join(worktreePath, "dist"))
+	assert.FileExists(t, filepath.Join(worktreePath, "vendor", "tracked.go"))
+
+	state, err := loadHibernationState(cfg, worktreePath)
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.Equal(t, []string{"dist", "node_modules"}, state.Paths)
+	assert.Equal(t, int64(3072), state.FreedBytes)
+}
+
+func TestHibernateCommand_DryRun(t *testing.T) {
+	mainPath, worktreePath, listOutput := setupCheckoutTest(t)
+	setupInfoWorktree(t, worktreePath)
+	writeHibernateFile(t, filepath.Join(worktreePath, "node_modules", "pkg.js"), 100)
+
+	cfg := &config.Config{
+		Defaults:  config.Defaults{BaseDir: "../worktrees"},
+		Hibernate: []string{"node_modules"},
+	}
+	mockExec := &mockHibernateCommandExecutor{listOutput: listOutput, ignored: []string{"node_modules"}}
+
+	var buf bytes.Buffer
+	require.NoError(t, hibernateCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, "feature/foo", true))
+
+	assert.Contains(t, buf.String(), "Would remove node_modules (100 B)")
+	assert.Contains(t, buf.String(), "Would free 100 B in feature/foo")
+	assert.DirExists(t, filepath.Join(worktreePath, "node_modules"))
+	state, err := loadHibernationState(cfg, worktreePath)
+	require.NoError(t, err)
+	assert.Nil(t, state)
+}
+
+func TestHibernateCommand_RefusesMainWorktree(t *testing.T) {
+	mainPath, _, listOutput := setupCheckoutTest(t)
+	cfg := &config.Config{
+		Defaults:  config.Defaults{BaseDir: "../worktrees"},
+		Hibernate: []string{"node_modules"},
+	}
+
+	var buf bytes.Buffer
+	err := hibernateCommandWithCommandExecutor(
+		&buf, &mockHibernateCommandExecutor{listOutput: listOutput}, cfg, mainPath, "", false)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot hibernate the main worktree")
+	code, _ := errors.CodeOf(err)
+	assert.Equal(t, errors.CodeMainWorktreeUnsupported, code)
+}
+
+func TestWakeHookNumbers(t *testing.T) {
+	postCreate := []config.Hook{
+		{Type: config.HookTypeCopy, From: ".env", To: ".env"},
+		{Type: config.HookTypeCommand, Command: "npm ci"},
+		{Type: config.HookTypeDownload, URL: "https://example.com/x.bin", To: "vendor/bin/x"},
+		{Type: config.HookTypeSymlink, From: ".cache", To: ".cache"},
+		{Type: config.HookTypeScript, Run: "npm ci\nnpm run build"},
+	}
+
+	assert.Equal(t, []int{2, 3, 5}, wakeHookNumbers(postCreate, []string{"node_modules", "vendor"}, false))
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, wakeHookNumbers(postCreate, []string{"node_modules"}, true))
+}
+
+func TestWakeCommand_RunsHooksAndClearsState(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+
+	mainPath, worktreePath, listOutput := setupCheckoutTest(t)
+	setupInfoWorktree(t, worktreePath)
+	require.NoError(t, saveHibernationState(&config.Config{}, worktreePath,
+		&hibernationState{Paths: []string{"node_modules"}}))
+
+	cfg := &config.Config{
+		Defaults: config.Defaults{BaseDir: "../worktrees"},
+		Hooks: config.Hooks{PostCreate: []config.Hook{
+			{Type: config.HookTypeCopy, From: ".env", To: ".env"},
+			{Type: config.HookTypeCommand, Command: "mkdir -p node_modules && touch node_modules/restored"},
+		}},
+	}
+	mockExec := &mockHibernateCommandExecutor{listOutput: listOutput}
+
+	var buf bytes.Buffer
+	require.NoError(t, wakeCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, worktreePath, "", false))
+
+	assert.Contains(t, buf.String(), "Waking feature/foo: running hook #2...")
+	assert.Contains(t, buf.String(), "✓ feature/foo is awake")
+	assert.FileExists(t, filepath.Join(worktreePath, "node_modules", "restored"))
+	state, err := loadHibernationState(cfg, worktreePath)
+	require.NoError(t, err)
+	assert.Nil(t, state)
+}
+
+func TestWakeCommand_FailureKeepsState(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+
+	mainPath, worktreePath, listOutput := setupCheckoutTest(t)
+	setupInfoWorktree(t, worktreePath)
+	require.NoError(t, saveHibernationState(&config.Config{}, worktreePath,
+		&hibernationState{Paths: []string{"node_modules"}}))
+
+	cfg := &config.Config{
+		Defaults: config.Defaults{BaseDir: "../worktrees"},
+		Hooks:    config.Hooks{PostCreate: []config.Hook{{Type: config.HookTypeCommand, Command: "exit 1"}}},
+	}
+
+	var buf bytes.Buffer
+	err := wakeCommandWithCommandExecutor(
+		&buf, &mockHibernateCommandExecutor{listOutput: listOutput}, cfg, mainPath, mainPath, "feature/foo", false)
+
+	require.Error(t, err)
+	state, loadErr := loadHibernationState(cfg, worktreePath)
+	require.NoError(t, loadErr)
+	assert.NotNil(t, state, "the worktree stays hibernated so wake can be retried")
+}
+
+func TestWakeCommand_NotHibernated(t *testing.T) {
+	mainPath, worktreePath, listOutput := setupCheckoutTest(t)
+	setupInfoWorktree(t, worktreePath)
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+
+	var buf bytes.Buffer
+	err := wakeCommandWithCommandExecutor(
+		&buf, &mockHibernateCommandExecutor{listOutput: listOutput}, cfg, mainPath, mainPath, "feature/foo", false)
+
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "feature/foo is not hibernated")
+}
@@ -15,7 +15,9 @@ func NewHookCommand() *cli.Command {
 		Name:  "hook",
 		Usage: "Generate shell hook for cd functionality",
 		Description: "Generate shell hook scripts that enable the 'wtp cd' command to change directories. " +
-			"This provides a seamless navigation experience without needing subshells.\n\n" +
+			"This provides a seamless navigation experience without needing subshells. The hook also " +
+			"changes to a new worktree after 'wtp add' when defaults.after_add is 'cd', and to the worktree " +
+			"picked with 'wtp switch'.\n\n" +
 			"To enable the hook, add the following to your shell config:\n" +
 			"  Bash (~/.bashrc):         eval \"$(wtp hook bash)\"\n" +
 			"  Zsh (~/.zshrc):           eval \"$(wtp hook zsh)\"\n" +
@@ -92,6 +94,17 @@ wtp() {
                 command wtp cd "$2"
             fi
         fi
+    elif [[ "$1" == "add" || "$1" == "switch" ]]; then
+        local cd_file exit_code target_dir
+        cd_file=$(mktemp "${TMPDIR:-/tmp}/wtp-cd.XXXXXX") || { command wtp "$@"; return $?; }
+        WTP_CD_FILE="$cd_file" command wtp "$@"
+        exit_code=$?
+        target_dir=$(cat "$cd_file" 2>/dev/null)
+        rm -f "$cd_file"
+        if [[ -n "$target_dir" ]]; then
+            cd "$target_dir"
+        fi
+        return $exit_code
     else
         command wtp "$@"
     fi
@@ -125,6 +138,17 @@ wtp() {
                 command wtp cd "$2"
             fi
         fi
+    elif [[ "$1" == "add" || "$1" == "switch" ]]; then
+        local cd_file exit_code target_dir
+        cd_file=$(mktemp "${TMPDIR:-/tmp}/wtp-cd.XXXXXX") || { command wtp "$@"; return $?; }
+        WTP_CD_FILE="$cd_file" command wtp "$@"
+        exit_code=$?
+        target_dir=$(cat "$cd_file" 2>/dev/null)
+        rm -f "$cd_file"
+        if [[ -n "$target_dir" ]]; then
+            cd "$target_dir"
+        fi
+        return $exit_code
     else
         command wtp "$@"
     fi
@@ -158,6 +182,22 @@ function wtp
                 command wtp cd $argv[2]
             end
         end
+    else if test "$argv[1]" = "add" -o "$argv[1]" = "switch"
+        set -l tmp_dir /tmp
+        set -q TMPDIR; and set tmp_dir $TMPDIR
+        set -l cd_file (mktemp $tmp_dir/wtp-cd.XXXXXX)
+        or begin
+            command wtp $argv
+            return $status
+        end
+        env WTP_CD_FILE=$cd_file wtp $argv
+        set -l exit_code $status
+        set -l target_dir (cat $cd_file 2>/dev/null)
+        rm -f $cd_file
+        if test -n "$target_dir"
+            cd "$target_dir"
+        end
+        return $exit_code
     else
         command wtp $argv
     end
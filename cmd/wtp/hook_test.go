@@ -47,6 +47,8 @@ func TestHookCommand_GeneratesValidShellScripts(t *testing.T) {
 				"if [[ \"$1\" == \"cd\" ]]",
 				"command wtp cd",
 				"cd \"$target_dir\"",
+				"WTP_CD_FILE=",
+				"\"$1\" == \"switch\"",
 			},
 		},
 		{
@@ -57,6 +59,8 @@ func TestHookCommand_GeneratesValidShellScripts(t *testing.T) {
 				"if [[ \"$1\" == \"cd\" ]]",
 				"command wtp cd",
 				"cd \"$target_dir\"",
+				"WTP_CD_FILE=",
+				"\"$1\" == \"switch\"",
 			},
 		},
 		{
@@ -67,6 +71,8 @@ func TestHookCommand_GeneratesValidShellScripts(t *testing.T) {
 				"if test \"$argv[1]\" = \"cd\"",
 				"command wtp cd",
 				"cd \"$target_dir\"",
+				"WTP_CD_FILE=",
+				"\"$argv[1]\" = \"switch\"",
 			},
 		},
 	}
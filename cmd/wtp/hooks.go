@@ -0,0 +1,475 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+	"go.yaml.in/yaml/v3"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+// backgroundShareThreshold is the fraction of total hook time a trailing run of
+// command hooks must account for before it is suggested for background mode.
+const backgroundShareThreshold = 0.25
+
+// configYAMLIndent is the indentation used when rewriting .wtp.yml, matching 'wtp init'.
+const configYAMLIndent = 2
+
+// NewHooksCommand creates the hooks command definition
+func NewHooksCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "hooks",
+		Usage: "Inspect and tune configured hooks",
+		Description: "Tools for working with the hooks declared in .wtp.yml.\n\n" +
+			"Examples:\n" +
+			"  wtp hooks optimize                      # Suggest parallel groups from benchmark timings\n" +
+			"  wtp hooks optimize --write              # Save the suggested groups to .wtp.yml\n" +
+			"  wtp hooks status feature/auth           # Show which post-create hooks failed\n" +
+			"  wtp hooks status --rerun feature/auth   # Run the failed ones again\n" +
+			"  wtp hooks run --type copy feature/auth  # Re-apply copy hooks after editing .wtp.yml\n" +
+			"  wtp hooks explain --branch feature/x    # Show the merged hooks expanded for feature/x",
+		Commands: []*cli.Command{
+			newHooksStatusCommand(),
+			newHooksRunCommand(),
+			newHooksExplainCommand(),
+			{
+				Name:  "optimize",
+				Usage: "Suggest hook grouping to shorten provisioning",
+				Description: "Reads the hook timings recorded by 'wtp bench --save-baseline', infers which " +
+					"post_create hooks depend on each other, and suggests groups that could run in parallel " +
+					"as well as slow trailing commands that could run in the background.\n\n" +
+					"Dependencies are inferred conservatively: command hooks are ordering barriers, and " +
+					"copy/symlink hooks depend on each other only when their destinations overlap.\n\n" +
+					"With --write, every suggested group of two or more hooks is saved to .wtp.yml as a " +
+					"'group' field so those hooks run in parallel from then on.",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "write",
+						Usage: "Write the suggested groups into .wtp.yml",
+					},
+				},
+				Action: hooksOptimizeCommand,
+			},
+		},
+	}
+}
+
+// hookPlanEntry is a post_create hook annotated with its recorded duration.
+type hookPlanEntry struct {
+	Number   int // 1-based post_create number
+	Hook     config.Hook
+	Duration time.Duration
+	Timed    bool
+}
+
+// hookPlanGroup is a set of consecutive hooks with no dependencies between them.
+type hookPlanGroup struct {
+	Entries  []hookPlanEntry
+	Duration time.Duration // slowest entry, i.e. the group's critical path
+}
+
+// hookOptimizationPlan summarizes the suggestions produced by planHookOptimization.
+type hookOptimizationPlan struct {
+	Entries      []hookPlanEntry
+	Groups       []hookPlanGroup
+	Background   []hookPlanEntry
+	Sequential   time.Duration
+	CriticalPath time.Duration
+}
+
+func hooksOptimizeCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	repo, cfg, mainRepoPath, err := setupRepoAndConfig()
+	if err != nil {
+		return err
+	}
+	if cmd.Bool("write") {
+		if err := ensureWritable(cfg, "write hook groups"); err != nil {
+			return err
+		}
+	}
+
+	if !cfg.HasHooks() {
+		_, err := fmt.Fprintln(w, "No post_create hooks configured; nothing to optimize.")
+		return err
+	}
+
+	baselinePath, err := benchBaselinePath(repo)
+	if err != nil {
+		return err
+	}
+	baseline, err := loadBenchBaseline(baselinePath)
+	if err != nil {
+		return err
+	}
+	if baseline == nil {
+		return fmt.Errorf(`no recorded hook timings found
+
+Hook optimization uses the timings stored by the benchmark command.
+
+Solution: Record a baseline first:
+  • wtp bench --save-baseline`)
+	}
+
+	plan := planHookOptimization(cfg.Hooks.PostCreate, baseline)
+	if err := writeHookOptimizationPlan(w, plan, baseline); err != nil {
+		return err
+	}
+	if !cmd.Bool("write") {
+		return nil
+	}
+	return writeHookOptimization(w, mainRepoPath, plan)
+}
+
+// writeHookOptimization stores plan's parallel groups in the repository's YAML configuration.
+func writeHookOptimization(w io.Writer, mainRepoPath string, plan *hookOptimizationPlan) error {
+	configPath, err := config.FindConfigFile(mainRepoPath, config.ConfigFileName)
+	if err != nil {
+		return err
+	}
+	if !config.IsYAMLConfigFile(configPath) {
+		return fmt.Errorf("--write only edits YAML configuration; add the suggested groups to %s by hand",
+			filepath.Base(configPath))
+	}
+
+	written, err := writeHookGroups(configPath, plan)
+	if err != nil {
+		return fmt.Errorf("failed to write hook groups to %s: %w", configPath, err)
+	}
+	_, err = fmt.Fprintf(w, "\nWrote %d parallel group(s) to %s\n", written, filepath.Base(configPath))
+	return err
+}
+
+// planHookOptimization combines hooks and benchmark timings into grouping and background suggestions.
+func planHookOptimization(postCreate []config.Hook, baseline *benchReport) *hookOptimizationPlan {
+	timings := make(map[string]time.Duration, len(baseline.Phases))
+	for _, phase := range baseline.Phases {
+		timings[phase.Name] = phase.Mean
+	}
+
+	plan := &hookOptimizationPlan{}
+	for i := range postCreate {
+		entry := hookPlanEntry{Number: i + 1, Hook: postCreate[i]}
+		// Phase names embed the hook type, so a reordered or edited config is reported as untimed.
+		entry.Duration, entry.Timed = timings[fmt.Sprintf("hook %d (%s)", entry.Number, entry.Hook.Type)]
+		plan.Entries = append(plan.Entries, entry)
+		plan.Sequential += entry.Duration
+	}
+
+	for i := range plan.Entries {
+		entry := &plan.Entries[i]
+		last := len(plan.Groups) - 1
+		if last < 0 || groupBlocks(&plan.Groups[last], &entry.Hook) {
+			plan.Groups = append(plan.Groups, hookPlanGroup{})
+			last++
+		}
+		plan.Groups[last].Entries = append(plan.Groups[last].Entries, *entry)
+		plan.Groups[last].Duration = max(plan.Groups[last].Duration, entry.Duration)
+	}
+	for _, group := range plan.Groups {
+		plan.CriticalPath += group.Duration
+	}
+
+	var trailing []hookPlanEntry
+	var trailingTime time.Duration
+	for i := len(plan.Entries) - 1; i >= 0 && runsShell(&plan.Entries[i].Hook); i-- {
+		trailing = append([]hookPlanEntry{plan.Entries[i]}, trailing...)
+		trailingTime += plan.Entries[i].Duration
+	}
+	if plan.Sequential > 0 && float64(trailingTime) >= float64(plan.Sequential)*backgroundShareThreshold {
+		plan.Background = trailing
+	}
+
+	return plan
+}
+
+func groupBlocks(group *hookPlanGroup, hook *config.Hook) bool {
+	for i := range group.Entries {
+		if hookDependsOn(hook, &group.Entries[i].Hook) {
+			return true
+		}
+	}
+	return false
+}
+
+// hookDependsOn reports whether later must wait for earlier to finish.
+// Commands and scripts may touch anything in the worktree, wait hooks exist to block until an earlier
+// hook's service is up, and prompts need the terminal, so all of them are barriers.
+// Extract hooks may read an archive produced by an earlier hook (e.g. a download).
+func hookDependsOn(later, earlier *config.Hook) bool {
+	if isBarrierHook(later) || isBarrierHook(earlier) {
+		return true
+	}
+	if later.Type == config.HookTypeExtract && pathsOverlap(later.From, earlier.To) {
+		return true
+	}
+	if writesGitConfig(later) || writesGitConfig(earlier) {
+		// git takes a lock on the config file, so only serialize the hooks that write it
+		return writesGitConfig(later) && writesGitConfig(earlier)
+	}
+	return pathsOverlap(hookTarget(later), hookTarget(earlier))
+}
+
+// writesGitConfig reports whether hook writes git configuration; git_hooks hooks set the
+// worktree's core.hooksPath.
+func writesGitConfig(hook *config.Hook) bool {
+	return hook.Type == config.HookTypeGitConfig || hook.Type == config.HookTypeGitHooks
+}
+
+func isBarrierHook(hook *config.Hook) bool {
+	return runsShell(hook) || hook.Type == config.HookTypeWait || hook.Type == config.HookTypePrompt
+}
+
+// runsShell reports whether hook is a command or script hook, whose effects cannot be known.
+func runsShell(hook *config.Hook) bool {
+	return hook.Type == config.HookTypeCommand || hook.Type == config.HookTypeScript
+}
+
+// hookTarget returns the path a hook writes to in the new worktree.
+func hookTarget(hook *config.Hook) string {
+	switch hook.Type {
+	case config.HookTypePatch, config.HookTypeEnsureLine:
+		return hook.File
+	case config.HookTypeDirenv:
+		return hook.EnvrcFile()
+	}
+	return hook.To
+}
+
+func pathsOverlap(a, b string) bool {
+	a, b = filepath.Clean(a), filepath.Clean(b)
+	if a == b {
+		return true
+	}
+	sep := string(filepath.Separator)
+	return strings.HasPrefix(a, b+sep) || strings.HasPrefix(b, a+sep)
+}
+
+func describePlanEntry(entry *hookPlanEntry) string {
+	timing := formatBenchDuration(entry.Duration)
+	if !entry.Timed {
+		timing = "no timing"
+	}
+	return fmt.Sprintf("#%d %s: %s (%s)", entry.Number, entry.Hook.Type, describeHook(&entry.Hook), timing)
+}
+
+// describeHook summarizes what a hook does in one line, e.g. the command it runs.
+func describeHook(hook *config.Hook) string {
+	switch hook.Type {
+	case config.HookTypeCommand:
+		return hook.Command
+	case config.HookTypeScript:
+		return describeScript(hook.Run)
+	case config.HookTypeDownload:
+		return fmt.Sprintf("%s → %s", hook.URL, hook.To)
+	case config.HookTypePatch, config.HookTypeEnsureLine:
+		return hook.File
+	case config.HookTypeWait:
+		kind, target := hook.WaitCondition()
+		return kind + " " + target
+	case config.HookTypePrompt:
+		return hook.Register
+	case config.HookTypeGitHooks:
+		mode := hook.Mode
+		if mode == "" {
+			mode = config.GitHooksModeCopy
+		}
+		return fmt.Sprintf("%s (%s)", hook.From, mode)
+	case config.HookTypeDirenv:
+		if hook.From == "" {
+			return hook.EnvrcFile()
+		}
+		return fmt.Sprintf("%s → %s", hook.From, hook.EnvrcFile())
+	case config.HookTypeGitConfig:
+		keys := make([]string, 0, len(hook.GitConfig))
+		for key := range hook.GitConfig {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return strings.Join(keys, ", ")
+	default:
+		return fmt.Sprintf("%s → %s", hook.From, hook.To)
+	}
+}
+
+// describeScript summarizes a script hook by its first line and length, e.g.
+// "set -eu … (12 lines)".
+func describeScript(script string) string {
+	lines := strings.Split(strings.TrimRight(script, "\n"), "\n")
+	if len(lines) == 1 {
+		return lines[0]
+	}
+	return fmt.Sprintf("%s … (%d lines)", lines[0], len(lines))
+}
+
+func writeHookOptimizationPlan(w io.Writer, plan *hookOptimizationPlan, baseline *benchReport) error {
+	if _, err := fmt.Fprintf(w, "Using hook timings from benchmark baseline recorded %s\n\n",
+		baseline.RecordedAt.Local().Format(time.RFC3339)); err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintln(w, "Suggested groups (hooks within a group can run in parallel):"); err != nil {
+		return err
+	}
+	for i, group := range plan.Groups {
+		if _, err := fmt.Fprintf(w, "  Group %d (%s)\n", i+1, formatBenchDuration(group.Duration)); err != nil {
+			return err
+		}
+		for j := range group.Entries {
+			if _, err := fmt.Fprintf(w, "    • %s\n", describePlanEntry(&group.Entries[j])); err != nil {
+				return err
+			}
+		}
+	}
+
+	saved := plan.Sequential - plan.CriticalPath
+	if _, err := fmt.Fprintf(w, "\nSequential: %s, critical path: %s (saves %s)\n",
+		formatBenchDuration(plan.Sequential), formatBenchDuration(plan.CriticalPath),
+		formatBenchDuration(saved)); err != nil {
+		return err
+	}
+
+	if len(plan.Background) > 0 {
+		if _, err := fmt.Fprintln(w, "\nBackground candidates (no later hook depends on them):"); err != nil {
+			return err
+		}
+		for i := range plan.Background {
+			if _, err := fmt.Fprintf(w, "  • %s\n", describePlanEntry(&plan.Background[i])); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := range plan.Entries {
+		if !plan.Entries[i].Timed {
+			_, err := fmt.Fprintln(w, "\nSome hooks have no recorded timing; re-run 'wtp bench --save-baseline'.")
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeHookGroups stores the plan's groups as 'group' fields on the post_create hooks of the
+// repository config file, keeping the rest of the file (including comments) intact. Hooks
+// that end up alone lose any previous group. Hooks from the global or local config are
+// left untouched.
+func writeHookGroups(configPath string, plan *hookOptimizationPlan) (int, error) {
+	// #nosec G304 -- configPath is the repository's .wtp.yml
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return 0, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return 0, err
+	}
+	var postCreate *yaml.Node
+	if len(doc.Content) > 0 {
+		postCreate = yamlMappingValue(yamlMappingValue(doc.Content[0], "hooks"), "post_create")
+	}
+	if postCreate == nil || postCreate.Kind != yaml.SequenceNode {
+		return 0, fmt.Errorf("no post_create hooks in the repository config")
+	}
+	nodes := repoHookNodes(postCreate, plan.Entries, filepath.Base(configPath))
+
+	written := 0
+	for _, group := range plan.Groups {
+		name := ""
+		if len(group.Entries) > 1 {
+			written++
+			name = fmt.Sprintf("group-%d", written)
+		}
+		for i := range group.Entries {
+			if node, ok := nodes[group.Entries[i].Number]; ok {
+				setYAMLString(node, "group", name)
+			}
+		}
+	}
+
+	return written, writeConfigDocument(configPath, &doc)
+}
+
+// repoHookNodes maps the numbers of the merged post_create hooks that came from the
+// repository config file, named source, to their nodes in postCreate. Hooks with an id
+// are found by it. The others can only be appended or prepended, so they keep the order
+// of the file, those with 'merge: prepend' ahead of the rest.
+func repoHookNodes(postCreate *yaml.Node, entries []hookPlanEntry, source string) map[int]*yaml.Node {
+	byID := make(map[string]*yaml.Node)
+	var prepended, appended []*yaml.Node
+	for _, node := range postCreate.Content {
+		id, merge := yamlMappingValue(node, "id"), yamlMappingValue(node, "merge")
+		switch {
+		case id != nil && id.Value != "":
+			byID[id.Value] = node
+		case merge != nil && merge.Value == config.HookMergePrepend:
+			prepended = append(prepended, node)
+		default:
+			appended = append(appended, node)
+		}
+	}
+	ordered := slices.Concat(prepended, appended)
+
+	nodes := make(map[int]*yaml.Node)
+	for i := range entries {
+		hook := &entries[i].Hook
+		switch {
+		case hook.Source != source:
+			continue // defined in the global or local config, or a branch overlay
+		case hook.ID != "":
+			if node, ok := byID[hook.ID]; ok {
+				nodes[entries[i].Number] = node
+			}
+		case len(ordered) > 0:
+			nodes[entries[i].Number], ordered = ordered[0], ordered[1:]
+		}
+	}
+	return nodes
+}
+
+func yamlMappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setYAMLString sets key to value in a mapping node, or removes key when value is empty.
+func setYAMLString(node *yaml.Node, key, value string) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != key {
+			continue
+		}
+		if value == "" {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+		} else {
+			node.Content[i+1] = &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+		}
+		return
+	}
+	if value != "" {
+		node.Content = append(node.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: key}, &yaml.Node{Kind: yaml.ScalarNode, Value: value})
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/hooks"
+)
+
+// Variable to allow mocking in tests
+var hooksExplainGetwd = os.Getwd
+
+// explainedVariables are the variables 'wtp hooks explain' lists with their values.
+var explainedVariables = []string{"BRANCH", "BRANCH_SLUG", "DIRNAME", "PATHNAME", "WORKTREE_PATH", "WORKTREE_DIR"}
+
+func newHooksExplainCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "explain",
+		Usage:     "Show every configured hook as it would run for a branch",
+		UsageText: "wtp hooks explain [--branch <branch>] [--profile <name>]",
+		Description: "Prints the hooks of every phase with their variables expanded for a worktree of " +
+			"the branch, which need not exist, and the configuration file each hook came from, " +
+			"including the branch overlay or profile it is part of. Hooks whose 'when' or 'os' " +
+			"rules them out for the branch are marked as skipped. Nothing runs. Without --branch, " +
+			"the branch of the current worktree is used.\n\n" +
+			"Examples:\n" +
+			"  wtp hooks explain --branch feature/x                      # Hooks for a new feature/x worktree\n" +
+			"  wtp hooks explain --branch feature/x --profile frontend   # With the frontend profile",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "branch",
+				Usage: "Branch to expand the hooks for",
+			},
+			&cli.StringFlag{
+				Name:  "profile",
+				Usage: "Also show the hooks of this profile from the 'profiles' config section",
+			},
+		},
+		Action: hooksExplainCommand,
+	}
+}
+
+func hooksExplainCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	_, cfg, mainRepoPath, err := setupRepoAndConfig()
+	if err != nil {
+		return err
+	}
+	cwd, err := hooksExplainGetwd()
+	if err != nil {
+		return errors.DirectoryAccessFailed("access current", ".", err)
+	}
+
+	executor := command.NewRealExecutor()
+	branch, err := explainBranch(executor, cmd.String("branch"), cwd)
+	if err != nil {
+		return err
+	}
+	return writeHooksExplanation(w, cfg, mainRepoPath, branch, cmd.String("profile"))
+}
+
+// explainBranch returns branch, or without it the branch of the worktree containing cwd.
+func explainBranch(executor command.Executor, branch, cwd string) (string, error) {
+	if branch != "" {
+		return branch, nil
+	}
+	result, err := executor.Execute([]command.Command{command.GitWorktreeList()})
+	if err != nil {
+		return "", errors.GitCommandFailed("git worktree list", err.Error())
+	}
+	wt := findWorktreeContaining(parseWorktreesFromOutput(result.Results[0].Output), cwd)
+	if wt == nil || wt.Branch == "" || wt.Branch == detachedKeyword {
+		return "", fmt.Errorf("the current worktree has no branch checked out; pass --branch")
+	}
+	return wt.Branch, nil
+}
+
+// writeHooksExplanation prints the hooks cfg, with the overlays matching branch and the
+// hooks of profile, configures for a worktree of branch at the path base_dir yields.
+func writeHooksExplanation(w io.Writer, cfg *config.Config, mainRepoPath, branch, profile string) error {
+	withProfile, ok := cfg.ForBranch(branch).ForProfile(profile)
+	if !ok {
+		return errors.UnknownProfile(profile, cfg.ProfileNames())
+	}
+	cfg = withProfile
+	worktreePath := cfg.ResolveWorktreePath(mainRepoPath, branch)
+	if _, err := fmt.Fprintf(w, "Branch:        %s\nWorktree path: %s\n\nVariables:\n", branch, worktreePath); err != nil {
+		return err
+	}
+	for _, name := range explainedVariables {
+		value := cfg.ExpandVariables("${"+name+"}", mainRepoPath, branch)
+		switch name {
+		case "WORKTREE_PATH":
+			value = worktreePath
+		case "WORKTREE_DIR":
+			value = filepath.Base(worktreePath)
+		}
+		if _, err := fmt.Fprintf(w, "  %-18s%s\n", "${"+name+"}", value); err != nil {
+			return err
+		}
+	}
+
+	executor := hooks.NewExecutor(cfg, mainRepoPath)
+	phases := []struct {
+		title string
+		hooks []config.Hook
+	}{
+		{"Post-create hooks", cfg.Hooks.PostCreate},
+		{"Post-checkout hooks", cfg.Hooks.PostCheckout},
+		{"Pre-remove hooks", cfg.Hooks.PreRemove},
+		{"Post-remove hooks", cfg.Hooks.PostRemove},
+		{"Post-prune hooks", cfg.Hooks.PostPrune},
+		{"Maintenance hooks", cfg.Hooks.Maintenance},
+		{"Clean hooks", cfg.Hooks.Clean},
+	}
+	for _, phase := range phases {
+		planned, err := executor.ExplainHooks(phase.hooks, worktreePath, branch)
+		if err != nil {
+			return err
+		}
+		if err := writeExplainedHooks(w, phase.title, planned); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeExplainedHooks prints one phase's hooks with their source and environment.
+func writeExplainedHooks(w io.Writer, title string, planned []hooks.PlannedHook) error {
+	if len(planned) == 0 {
+		_, err := fmt.Fprintf(w, "\n%s: none\n", title)
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "\n%s:\n", title); err != nil {
+		return err
+	}
+	for i := range planned {
+		entry := &planned[i]
+		line := fmt.Sprintf("  #%d %s: %s", entry.Index, entry.Hook.Type, describeHook(&entry.Hook))
+		if entry.Skip != "" {
+			line += fmt.Sprintf("  (skipped, %s)", entry.Skip)
+		}
+		source := entry.Hook.Source
+		if source == "" {
+			source = "unknown"
+		}
+		if _, err := fmt.Fprintf(w, "%s\n      from %s\n", line, source); err != nil {
+			return err
+		}
+		names := make([]string, 0, len(entry.Hook.Env))
+		for name := range entry.Hook.Env {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if _, err := fmt.Fprintf(w, "      env %s=%s\n", name, entry.Hook.Env[name]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func TestWriteHooksExplanation(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+	repo := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(repo, config.ConfigFileName), []byte(`defaults:
+  base_dir: ../worktrees
+hooks:
+  post_create:
+    - type: command
+      command: "echo ${BRANCH}"
+      env:
+        TARGET: "${WORKTREE_DIR}"
+    - type: command
+      command: "make db"
+      when: "branch == main"
+profiles:
+  frontend:
+    hooks:
+      post_create:
+        - type: command
+          command: "npm ci"
+`), 0o644))
+	cfg, err := config.LoadConfig(repo, "")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, writeHooksExplanation(&buf, cfg, repo, "feature/x", "frontend"))
+	out := buf.String()
+
+	worktreePath := filepath.Join(filepath.Dir(repo), "worktrees", "feature", "x")
+	assert.Contains(t, out, "Worktree path: "+worktreePath)
+	assert.Contains(t, out, "${BRANCH_SLUG}    feature-x")
+	assert.Contains(t, out, "#1 command: echo feature/x\n      from .wtp.yml\n      env TARGET=x\n")
+	assert.Contains(t, out, "#2 command: make db  (skipped, when: branch == main)")
+	assert.Contains(t, out, "#3 command: npm ci\n      from .wtp.yml (profiles \"frontend\")")
+	assert.Contains(t, out, "Pre-remove hooks: none")
+
+	err = writeHooksExplanation(&buf, cfg, repo, "feature/x", "backend")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "backend")
+}
+
+func TestExplainBranch(t *testing.T) {
+	executor := &mockCommandExecutor{}
+	branch, err := explainBranch(executor, "feature/x", "/repo")
+	require.NoError(t, err)
+	assert.Equal(t, "feature/x", branch)
+	assert.Empty(t, executor.executedCommands, "an explicit branch needs no git")
+
+	list := "worktree /repo\nHEAD abc\nbranch refs/heads/main\n\n" +
+		"worktree /worktrees/feature/y\nHEAD def\nbranch refs/heads/feature/y\n\n" +
+		"worktree /worktrees/detached\nHEAD 123\ndetached\n\n"
+	listing := &mockSwitchCommandExecutor{listOutput: list}
+	branch, err = explainBranch(listing, "", "/worktrees/feature/y/src")
+	require.NoError(t, err)
+	assert.Equal(t, "feature/y", branch)
+
+	_, err = explainBranch(listing, "", "/worktrees/detached")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pass --branch")
+}
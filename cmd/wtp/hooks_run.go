@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/hooks"
+)
+
+// hooksRunSelection is the subset of post_create hooks 'wtp hooks run' executes; only and
+// hookType both empty selects every hook.
+type hooksRunSelection struct {
+	only     string // "1,3" style hook numbers
+	hookType string
+	// dryRun shows what the hooks would change instead of running them.
+	dryRun bool
+}
+
+// newHooksRunCommand creates the 'hooks run' subcommand definition
+func newHooksRunCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "run",
+		Usage:     "Run post-create hooks again in an existing worktree",
+		UsageText: "wtp hooks run [--only <n>[,<n>...] | --type <type>] [--dry-run] [<worktree-name>]",
+		Description: "Executes the post_create hooks from the current .wtp.yml against a worktree that " +
+			"already exists, e.g. after hooks were added or changed. Hooks run in configuration order " +
+			"with the usual 'when' conditions; results are recorded for 'wtp hooks status'. Without a " +
+			"name, the current worktree is used.\n\n" +
+			"With --dry-run nothing runs; copy, patch, and ensure_line hooks show a unified diff of " +
+			"the files they would change, so drift from the configuration can be reviewed first.",
+		ArgsUsage: "[<worktree-name>]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "only",
+				Usage: "Comma-separated post_create hook numbers to run",
+			},
+			&cli.StringFlag{
+				Name:  "type",
+				Usage: "Run only the hooks of this type, e.g. copy",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Show what the hooks would change without running them",
+			},
+		},
+		ShellComplete: completeWorktreesForCd,
+		Action:        hooksRunCommand,
+	}
+}
+
+func hooksRunCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	selection := hooksRunSelection{only: cmd.String("only"), hookType: cmd.String("type"), dryRun: cmd.Bool("dry-run")}
+	if selection.only != "" && selection.hookType != "" {
+		return errors.InvalidArguments("--only cannot be combined with --type")
+	}
+
+	_, cfg, mainRepoPath, err := setupRepoAndConfig()
+	if err != nil {
+		return err
+	}
+	if !selection.dryRun {
+		if err := ensureWritable(cfg, "run hooks"); err != nil {
+			return err
+		}
+	}
+
+	cwd, err := hooksStatusGetwd()
+	if err != nil {
+		return errors.DirectoryAccessFailed("access current", ".", err)
+	}
+
+	executor := command.NewRealExecutor()
+	return hooksRunCommandWithCommandExecutor(w, executor, cfg, mainRepoPath, cwd, cmd.Args().First(), selection)
+}
+
+func hooksRunCommandWithCommandExecutor(
+	w io.Writer, executor command.Executor, cfg *config.Config, mainRepoPath, cwd, worktreeName string,
+	selection hooksRunSelection,
+) error {
+	target, err := resolveHookWorktree(executor, cfg, cwd, worktreeName)
+	if err != nil {
+		return err
+	}
+	postCreate := target.cfg.Hooks.PostCreate
+	if len(postCreate) == 0 {
+		_, err := fmt.Fprintf(w, "No post-create hooks configured for %s\n", target.name)
+		return err
+	}
+
+	numbers, err := selectHooksToRun(postCreate, selection)
+	if err != nil {
+		return err
+	}
+	if selection.dryRun {
+		return writeHooksRunDryRun(w, target, mainRepoPath, numbers)
+	}
+
+	if _, err := fmt.Fprintf(w, "Running %s in %s...\n", formatHookNumbers(numbers), target.name); err != nil {
+		return err
+	}
+	if err := target.runHooks(w, mainRepoPath, numbers); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, "✓ Hooks completed")
+	return err
+}
+
+// selectHooksToRun returns the 1-based numbers of the post_create hooks selection picks.
+func selectHooksToRun(postCreate []config.Hook, selection hooksRunSelection) ([]int, error) {
+	if selection.only != "" {
+		return parseHookNumbers("--only", selection.only, len(postCreate))
+	}
+
+	var numbers []int
+	for i := range postCreate {
+		if selection.hookType == "" || postCreate[i].Type == selection.hookType {
+			numbers = append(numbers, i+1)
+		}
+	}
+	if len(numbers) == 0 {
+		return nil, fmt.Errorf("no post_create hooks of type '%s' are configured", selection.hookType)
+	}
+	return numbers, nil
+}
+
+// writeHooksRunDryRun prints the post_create hooks with the given numbers as they would run
+// in the worktree, with the diffs of the files they would change.
+func writeHooksRunDryRun(w io.Writer, target *hookWorktree, mainRepoPath string, numbers []int) error {
+	planned, err := hooks.NewExecutor(target.cfg, mainRepoPath).PlanPostCreateHooks(target.worktree.Path, "")
+	if err != nil {
+		return err
+	}
+	selected := make([]hooks.PlannedHook, 0, len(numbers))
+	for _, number := range numbers {
+		selected = append(selected, planned[number-1])
+	}
+
+	if _, err := fmt.Fprintln(w, "Dry run: nothing will be changed"); err != nil {
+		return err
+	}
+	return writePlannedHooks(w, "Post-create hooks for "+target.name, selected)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func TestSelectHooksToRun(t *testing.T) {
+	postCreate := []config.Hook{
+		{Type: config.HookTypeCopy, From: ".env"},
+		{Type: config.HookTypeCommand, Command: "npm ci"},
+		{Type: config.HookTypeCopy, From: ".env.local"},
+	}
+
+	tests := []struct {
+		name        string
+		selection   hooksRunSelection
+		expected    []int
+		expectedErr string
+	}{
+		{name: "all hooks", expected: []int{1, 2, 3}},
+		{name: "by number", selection: hooksRunSelection{only: "3,1"}, expected: []int{3, 1}},
+		{name: "by type", selection: hooksRunSelection{hookType: "copy"}, expected: []int{1, 3}},
+		{
+			name:        "number out of range",
+			selection:   hooksRunSelection{only: "4"},
+			expectedErr: "hook number 4 out of range",
+		},
+		{
+			name:        "invalid number",
+			selection:   hooksRunSelection{only: "x"},
+			expectedErr: "invalid hook number 'x' in --only",
+		},
+		{
+			name:        "type without hooks",
+			selection:   hooksRunSelection{hookType: "symlink"},
+			expectedErr: "no post_create hooks of type 'symlink' are configured",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			numbers, err := selectHooksToRun(postCreate, tt.selection)
+			if tt.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, numbers)
+		})
+	}
+}
+
+func TestHooksRunCommand_RunsSelectedHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+
+	mainPath, worktreePath, listOutput := setupCheckoutTest(t)
+	setupInfoWorktree(t, worktreePath)
+	require.NoError(t, os.WriteFile(filepath.Join(mainPath, ".env"), []byte("A=1\n"), 0o644))
+
+	cfg := &config.Config{
+		Defaults: config.Defaults{BaseDir: "../worktrees"},
+		Hooks: config.Hooks{PostCreate: []config.Hook{
+			{Type: config.HookTypeCopy, From: ".env", To: ".env"},
+			{Type: config.HookTypeCommand, Command: "touch installed"},
+		}},
+	}
+	mockExec := &mockInfoCommandExecutor{listOutput: listOutput}
+
+	var buf bytes.Buffer
+	err := hooksRunCommandWithCommandExecutor(
+		&buf, mockExec, cfg, mainPath, worktreePath, "", hooksRunSelection{hookType: "copy"})
+
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "Running hook #1 in feature/foo...")
+	assert.Contains(t, buf.String(), "✓ Hooks completed")
+	assert.FileExists(t, filepath.Join(worktreePath, ".env"))
+	assert.NoFileExists(t, filepath.Join(worktreePath, "installed"))
+}
+
+func TestHooksRunCommand_DryRunShowsDiffs(t *testing.T) {
+	mainPath, worktreePath, listOutput := setupCheckoutTest(t)
+	setupInfoWorktree(t, worktreePath)
+	require.NoError(t, os.WriteFile(filepath.Join(mainPath, ".env"), []byte("A=1\nB=2\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(worktreePath, ".env"), []byte("A=1\n"), 0o644))
+
+	cfg := &config.Config{
+		Defaults: config.Defaults{BaseDir: "../worktrees"},
+		Hooks: config.Hooks{PostCreate: []config.Hook{
+			{Type: config.HookTypeCommand, Command: "touch installed"},
+			{Type: config.HookTypeCopy, From: ".env", To: ".env"},
+		}},
+	}
+	mockExec := &mockInfoCommandExecutor{listOutput: listOutput}
+
+	var buf bytes.Buffer
+	err := hooksRunCommandWithCommandExecutor(
+		&buf, mockExec, cfg, mainPath, worktreePath, "", hooksRunSelection{dryRun: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Dry run: nothing will be changed\n"+
+		"\nPost-create hooks for feature/foo:\n"+
+		"  #1 command: touch installed\n"+
+		"  #2 copy: .env → .env\n"+
+		"      --- a/.env\n"+
+		"      +++ b/.env\n"+
+		"      @@ -1 +1,2 @@\n"+
+		"       A=1\n"+
+		"      +B=2\n", buf.String())
+	assert.NoFileExists(t, filepath.Join(worktreePath, "installed"))
+	content, err := os.ReadFile(filepath.Join(worktreePath, ".env"))
+	require.NoError(t, err)
+	assert.Equal(t, "A=1\n", string(content))
+}
+
+func TestHooksRunCommand_UpdatesProvisionRecord(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+
+	mainPath, worktreePath, listOutput := setupCheckoutTest(t)
+	setupInfoWorktree(t, worktreePath)
+	require.NoError(t, saveProvisionRecord(&config.Config{}, worktreePath,
+		newProvisionRecord("feature/foo", "main", nil, nil, nil)))
+
+	cfg := &config.Config{
+		Defaults: config.Defaults{BaseDir: "../worktrees"},
+		Hooks: config.Hooks{PostCreate: []config.Hook{
+			{Type: config.HookTypeCommand, Command: "exit 4"},
+		}},
+	}
+	mockExec := &mockInfoCommandExecutor{listOutput: listOutput}
+
+	var buf bytes.Buffer
+	err := hooksRunCommandWithCommandExecutor(
+		&buf, mockExec, cfg, mainPath, mainPath, "feature/foo", hooksRunSelection{only: "1"})
+
+	require.Error(t, err)
+	record, loadErr := loadProvisionRecord(cfg, worktreePath)
+	require.NoError(t, loadErr)
+	require.Len(t, record.Hooks, 1)
+	assert.Equal(t, provisionStatusFailed, record.Hooks[0].Status)
+	require.NotNil(t, record.Hooks[0].ExitCode)
+	assert.Equal(t, 4, *record.Hooks[0].ExitCode)
+	assert.NotEmpty(t, record.Error)
+}
+
+func TestHooksRunCommand_NoHooks(t *testing.T) {
+	mainPath, _, listOutput := setupCheckoutTest(t)
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+
+	var buf bytes.Buffer
+	err := hooksRunCommandWithCommandExecutor(
+		&buf, &mockInfoCommandExecutor{listOutput: listOutput}, cfg, mainPath, mainPath, "feature/foo",
+		hooksRunSelection{})
+
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "No post-create hooks configured for feature/foo")
+}
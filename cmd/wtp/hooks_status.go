@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/git"
+	"github.com/satococoa/wtp/v2/internal/hooks"
+)
+
+// Statuses shown by 'wtp hooks status' for hooks without a recorded result
+const (
+	hookStatusSkipped = "skipped" // not selected, e.g. by a 'when' condition
+	hookStatusNotRun  = "not run" // after a failed hook, so provisioning stopped first
+)
+
+// Variable to allow mocking in tests
+var hooksStatusGetwd = os.Getwd
+
+// newHooksStatusCommand creates the 'hooks status' subcommand definition
+func newHooksStatusCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "status",
+		Usage:     "Show the recorded post-create hook results of a worktree",
+		UsageText: "wtp hooks status [--rerun] [<worktree-name>]",
+		Description: "Lists every post_create hook with the result 'wtp add' recorded for it: status, " +
+			"exit code, duration, and start time. Hooks that failed, or never ran because an earlier " +
+			"hook failed, are pending; --rerun runs only those and updates the record. Without a " +
+			"name, the current worktree is shown.",
+		ArgsUsage: "[<worktree-name>]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "rerun",
+				Usage: "Run the failed and not-run hooks again",
+			},
+		},
+		ShellComplete: completeWorktreesForCd,
+		Action:        hooksStatusCommand,
+	}
+}
+
+func hooksStatusCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	_, cfg, mainRepoPath, err := setupRepoAndConfig()
+	if err != nil {
+		return err
+	}
+	rerun := cmd.Bool("rerun")
+	if rerun {
+		if err := ensureWritable(cfg, "re-run hooks"); err != nil {
+			return err
+		}
+	}
+
+	cwd, err := hooksStatusGetwd()
+	if err != nil {
+		return errors.DirectoryAccessFailed("access current", ".", err)
+	}
+
+	executor := command.NewRealExecutor()
+	return hooksStatusCommandWithCommandExecutor(w, executor, cfg, mainRepoPath, cwd, cmd.Args().First(), rerun)
+}
+
+func hooksStatusCommandWithCommandExecutor(
+	w io.Writer, executor command.Executor, cfg *config.Config, mainRepoPath, cwd, worktreeName string, rerun bool,
+) error {
+	target, err := resolveHookWorktree(executor, cfg, cwd, worktreeName)
+	if err != nil {
+		return err
+	}
+	if target.record == nil {
+		_, err := fmt.Fprintf(w, "No hook results recorded for %s (not created by 'wtp add')\n", target.name)
+		return err
+	}
+
+	pending, err := writeHookStatus(w, target.name, target.record, target.cfg.Hooks.PostCreate)
+	if err != nil || len(pending) == 0 {
+		return err
+	}
+	if !rerun {
+		_, err := fmt.Fprintf(w, "\n%d hook(s) pending; run 'wtp hooks status --rerun %s' to run them again.\n",
+			len(pending), target.name)
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "\nRe-running %s...\n", formatHookNumbers(pending)); err != nil {
+		return err
+	}
+	if err := target.runHooks(w, mainRepoPath, pending); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, "✓ All pending hooks succeeded")
+	return err
+}
+
+// hookWorktree is a worktree whose post_create hooks are inspected or run.
+type hookWorktree struct {
+	worktree *git.Worktree
+	name     string
+	record   *provisionRecord // nil when the worktree was not created by 'wtp add'
+	cfg      *config.Config   // configuration of the branch the worktree was provisioned for
+}
+
+// resolveHookWorktree finds the worktree named worktreeName, or the one containing cwd, and
+// loads its provision record. Hooks are numbered as in the configuration of the branch
+// recorded at creation, falling back to the checked-out branch.
+func resolveHookWorktree(
+	executor command.Executor, cfg *config.Config, cwd, worktreeName string,
+) (*hookWorktree, error) {
+	result, err := executor.Execute([]command.Command{command.GitWorktreeList()})
+	if err != nil {
+		return nil, errors.GitCommandFailed("git worktree list", err.Error())
+	}
+	worktrees := parseWorktreesFromOutput(result.Results[0].Output)
+	mainWorktreePath := findMainWorktreePath(worktrees)
+
+	wt, err := resolveWorktreeTarget(worktrees, cfg, worktreeName, cwd, mainWorktreePath)
+	if err != nil {
+		return nil, err
+	}
+	if wt == nil {
+		if worktreeName == "" {
+			return nil, errors.NotInWorktree("pass a worktree name")
+		}
+		return nil, errors.WorktreeNotFound(worktreeName, managedWorktreeNames(worktrees, cfg, mainWorktreePath))
+	}
+
+	record, err := loadProvisionRecord(cfg, wt.Path)
+	if err != nil {
+		return nil, err
+	}
+	branch := wt.Branch
+	if branch == detachedKeyword {
+		branch = ""
+	}
+	if record != nil && record.Branch != "" {
+		branch = record.Branch
+	}
+	return &hookWorktree{
+		worktree: wt,
+		name:     getWorktreeDisplayName(*wt, cfg, mainWorktreePath),
+		record:   record,
+		cfg:      withRegisteredProfile(cfg.ForBranch(branch), mainWorktreePath, wt.Path),
+	}, nil
+}
+
+// runHooks runs the post_create hooks with the given numbers and stores their results in
+// the provision record, if the worktree has one.
+func (h *hookWorktree) runHooks(w io.Writer, mainRepoPath string, numbers []int) error {
+	executor := hooks.NewExecutor(h.cfg, mainRepoPath)
+	if h.record != nil {
+		executor = executor.WithVariables(h.record.Variables)
+	}
+	timings, hookErr := executor.ExecutePostCreateHooksSelected(w, h.worktree.Path, numbers)
+	if h.record != nil {
+		h.record.applyHookResults(h.cfg.Hooks.PostCreate, timings, hookErr)
+		if err := saveProvisionRecord(h.cfg, h.worktree.Path, h.record); err != nil {
+			return err
+		}
+	}
+	return hookErr
+}
+
+// writeHookStatus prints one row per configured post_create hook and returns the numbers of
+// the pending ones: those that failed or did not run because an earlier hook failed.
+func writeHookStatus(w io.Writer, name string, record *provisionRecord, postCreate []config.Hook) ([]int, error) {
+	if len(postCreate) == 0 {
+		_, err := fmt.Fprintf(w, "No post-create hooks configured for %s\n", name)
+		return nil, err
+	}
+
+	results := make(map[int]*provisionHook, len(record.Hooks))
+	firstFailed := 0
+	for i := range record.Hooks {
+		hook := &record.Hooks[i]
+		results[hook.Index] = hook
+		if hook.Status == provisionStatusFailed && (firstFailed == 0 || hook.Index < firstFailed) {
+			firstFailed = hook.Index
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, "Hook results for %s (provisioned %s):\n",
+		name, record.CreatedAt.Local().Format("2006-01-02 15:04")); err != nil {
+		return nil, err
+	}
+	var pending []int
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:mnd // column padding
+	for i := range postCreate {
+		number := i + 1
+		row := newHookStatusRow(results[number], firstFailed != 0 && number > firstFailed)
+		if row.pending {
+			pending = append(pending, number)
+		}
+		if _, err := fmt.Fprintf(tw, "  #%d\t%s\t%s\t%s\t%s\t%s\n",
+			number, postCreate[i].Type, row.status, row.duration, row.started,
+			describeHook(&postCreate[i])); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return nil, err
+	}
+	if record.Error != "" {
+		if _, err := fmt.Fprintf(w, "  failed: %s\n", record.Error); err != nil {
+			return nil, err
+		}
+	}
+	return pending, nil
+}
+
+// hookStatusRow is the displayed outcome of one hook.
+type hookStatusRow struct {
+	status, duration, started string
+	pending                   bool
+}
+
+// newHookStatusRow describes a hook from its recorded result, which is nil when the hook did
+// not run; afterFailure tells whether an earlier hook failed.
+func newHookStatusRow(result *provisionHook, afterFailure bool) hookStatusRow {
+	if result == nil {
+		if afterFailure {
+			return hookStatusRow{status: hookStatusNotRun, duration: "-", started: "-", pending: true}
+		}
+		return hookStatusRow{status: hookStatusSkipped, duration: "-", started: "-"}
+	}
+
+	row := hookStatusRow{
+		status:   result.Status,
+		duration: formatBenchDuration(result.Duration),
+		started:  "-",
+		pending:  result.Status == provisionStatusFailed,
+	}
+	if result.ExitCode != nil {
+		row.status = fmt.Sprintf("%s (exit %d)", result.Status, *result.ExitCode)
+	}
+	if !result.StartedAt.IsZero() {
+		row.started = result.StartedAt.Local().Format("15:04:05")
+	}
+	return row
+}
+
+// formatHookNumbers renders hook numbers as "hook #2" or "hooks #2, #3".
+func formatHookNumbers(numbers []int) string {
+	labels := make([]string, len(numbers))
+	for i, number := range numbers {
+		labels[i] = fmt.Sprintf("#%d", number)
+	}
+	if len(numbers) == 1 {
+		return "hook " + labels[0]
+	}
+	return "hooks " + strings.Join(labels, ", ")
+}
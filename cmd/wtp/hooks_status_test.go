@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/hooks"
+)
+
+func TestNewHooksCommand_HasStatus(t *testing.T) {
+	cmd := NewHooksCommand()
+
+	var names []string
+	for _, sub := range cmd.Commands {
+		names = append(names, sub.Name)
+	}
+	assert.Contains(t, names, "status")
+}
+
+func TestNewProvisionRecord_RecordsFailures(t *testing.T) {
+	hookList := []config.Hook{
+		{Type: config.HookTypeCopy, From: ".env", To: ".env"},
+		{Type: config.HookTypeCommand, Command: "npm ci"},
+	}
+	timings := []hooks.HookTiming{
+		{Index: 2, Type: "command", Err: fmt.Errorf("boom")},
+		{Index: 1, Type: "copy"},
+	}
+
+	record := newProvisionRecord("feature/foo", "main", hookList, timings, fmt.Errorf("failed to execute hook 2: boom"))
+
+	require.Len(t, record.Hooks, 2)
+	assert.Equal(t, 1, record.Hooks[0].Index, "hooks are sorted by number")
+	assert.Equal(t, provisionStatusOK, record.Hooks[0].Status)
+	assert.Equal(t, ".env → .env", record.Hooks[0].Description)
+	assert.Equal(t, provisionStatusFailed, record.Hooks[1].Status)
+	assert.Equal(t, "npm ci", record.Hooks[1].Description)
+	assert.Equal(t, "boom", record.Hooks[1].Error)
+	assert.Nil(t, record.Hooks[1].ExitCode)
+
+	record.applyHookResults(hookList, []hooks.HookTiming{{Index: 2, Type: "command"}}, nil)
+	require.Len(t, record.Hooks, 2)
+	assert.Equal(t, provisionStatusOK, record.Hooks[1].Status)
+	assert.Empty(t, record.Hooks[1].Error)
+	assert.Empty(t, record.Error)
+}
+
+func TestHooksStatusCommand_ShowsPendingHooks(t *testing.T) {
+	mainPath, worktreePath, listOutput := setupCheckoutTest(t)
+	setupInfoWorktree(t, worktreePath)
+
+	hookList := []config.Hook{
+		{Type: config.HookTypeCommand, Command: "echo ok"},
+		{Type: config.HookTypeCommand, Command: "exit 2"},
+		{Type: config.HookTypeCommand, Command: "echo later"},
+	}
+	exitCode := 2
+	record := newProvisionRecord("feature/foo", "main", hookList,
+		[]hooks.HookTiming{{Index: 1, Type: "command"}}, fmt.Errorf("failed to execute hook 2: exit status 2"))
+	record.Hooks = append(record.Hooks, provisionHook{
+		Index: 2, Type: "command", Status: provisionStatusFailed, ExitCode: &exitCode,
+	})
+	require.NoError(t, saveProvisionRecord(&config.Config{}, worktreePath, record))
+
+	cfg := &config.Config{
+		Defaults: config.Defaults{BaseDir: "../worktrees"},
+		Hooks:    config.Hooks{PostCreate: hookList},
+	}
+	mockExec := &mockInfoCommandExecutor{listOutput: listOutput}
+
+	var buf bytes.Buffer
+	require.NoError(t,
+		hooksStatusCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, mainPath, "feature/foo", false))
+
+	output := buf.String()
+	assert.Contains(t, output, "Hook results for feature/foo")
+	assert.Regexp(t, `#1\s+command\s+ok\s`, output)
+	assert.Regexp(t, `#2\s+command\s+failed \(exit 2\)\s`, output)
+	assert.Regexp(t, `#3\s+command\s+not run\s+-\s+-\s+echo later`, output)
+	assert.Contains(t, output, "failed: failed to execute hook 2: exit status 2")
+	assert.Contains(t, output, "2 hook(s) pending; run 'wtp hooks status --rerun feature/foo'")
+}
+
+func TestHooksStatusCommand_RerunsPendingHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+
+	mainPath, worktreePath, listOutput := setupCheckoutTest(t)
+	setupInfoWorktree(t, worktreePath)
+
+	hookList := []config.Hook{
+		{Type: config.HookTypeCommand, Command: "echo run >> first.log"},
+		{Type: config.HookTypeCommand, Command: "touch second"},
+		{Type: config.HookTypeCommand, Command: "touch third"},
+	}
+	record := newProvisionRecord("feature/foo", "main", hookList,
+		[]hooks.HookTiming{{Index: 1, Type: "command"}, {Index: 2, Type: "command", Err: fmt.Errorf("boom")}},
+		fmt.Errorf("failed to execute hook 2: boom"))
+	require.NoError(t, saveProvisionRecord(&config.Config{}, worktreePath, record))
+
+	cfg := &config.Config{
+		Defaults: config.Defaults{BaseDir: "../worktrees"},
+		Hooks:    config.Hooks{PostCreate: hookList},
+	}
+	mockExec := &mockInfoCommandExecutor{listOutput: listOutput}
+
+	var buf bytes.Buffer
+	require.NoError(t,
+		hooksStatusCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, mainPath, "feature/foo", true))
+
+	assert.Contains(t, buf.String(), "Re-running hooks #2, #3...")
+	assert.Contains(t, buf.String(), "✓ All pending hooks succeeded")
+	assert.FileExists(t, filepath.Join(worktreePath, "second"))
+	assert.FileExists(t, filepath.Join(worktreePath, "third"))
+	_, err := os.Stat(filepath.Join(worktreePath, "first.log"))
+	assert.True(t, os.IsNotExist(err), "hooks that succeeded are not run again")
+
+	updated, err := loadProvisionRecord(cfg, worktreePath)
+	require.NoError(t, err)
+	require.Len(t, updated.Hooks, 3)
+	for _, hook := range updated.Hooks {
+		assert.Equal(t, provisionStatusOK, hook.Status, "hook #%d", hook.Index)
+	}
+	assert.Empty(t, updated.Error)
+}
+
+func TestHooksStatusCommand_NoRecord(t *testing.T) {
+	mainPath, worktreePath, listOutput := setupCheckoutTest(t)
+	setupInfoWorktree(t, worktreePath)
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+
+	var buf bytes.Buffer
+	err := hooksStatusCommandWithCommandExecutor(
+		&buf, &mockInfoCommandExecutor{listOutput: listOutput}, cfg, mainPath, worktreePath, "", false)
+
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "No hook results recorded for feature/foo (not created by 'wtp add')")
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.yaml.in/yaml/v3"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func TestNewHooksCommand(t *testing.T) {
+	cmd := NewHooksCommand()
+
+	assert.Equal(t, "hooks", cmd.Name)
+	var names []string
+	for _, sub := range cmd.Commands {
+		names = append(names, sub.Name)
+	}
+	assert.Contains(t, names, "optimize")
+}
+
+func TestHookDependsOn(t *testing.T) {
+	copyEnv := config.Hook{Type: config.HookTypeCopy, From: ".env", To: ".env"}
+	copyCursor := config.Hook{Type: config.HookTypeCopy, From: ".cursor", To: ".cursor"}
+	linkNested := config.Hook{Type: config.HookTypeSymlink, From: "cache", To: ".cursor/cache"}
+	install := config.Hook{Type: config.HookTypeCommand, Command: "npm install"}
+
+	assert.False(t, hookDependsOn(&copyCursor, &copyEnv))
+	assert.True(t, hookDependsOn(&linkNested, &copyCursor), "nested destination overlaps")
+	assert.True(t, hookDependsOn(&copyEnv, &copyEnv), "identical destination overlaps")
+	assert.True(t, hookDependsOn(&install, &copyEnv), "commands wait for earlier hooks")
+	assert.True(t, hookDependsOn(&copyEnv, &install), "hooks wait for earlier commands")
+	setup := config.Hook{Type: config.HookTypeScript, Run: "npm ci\nnpm run build\n"}
+	assert.True(t, hookDependsOn(&copyEnv, &setup), "scripts are barriers like commands")
+
+	download := config.Hook{Type: config.HookTypeDownload, URL: "https://example.com/c.tgz", To: "c.tgz"}
+	extract := config.Hook{Type: config.HookTypeExtract, From: "c.tgz", To: ".cache"}
+	assert.True(t, hookDependsOn(&extract, &download), "extract reads the downloaded archive")
+	assert.False(t, hookDependsOn(&extract, &copyEnv))
+}
+
+func TestDescribeScript(t *testing.T) {
+	assert.Equal(t, "npm ci", describeScript("npm ci\n"))
+	assert.Equal(t, "set -u … (3 lines)", describeScript("set -u\nnpm ci\nnpm run build\n"))
+}
+
+func TestPlanHookOptimization(t *testing.T) {
+	postCreate := []config.Hook{
+		{Type: config.HookTypeCopy, From: ".env", To: ".env"},
+		{Type: config.HookTypeSymlink, From: ".bin", To: ".bin"},
+		{Type: config.HookTypeCommand, Command: "npm install"},
+		{Type: config.HookTypeCommand, Command: "make db"},
+	}
+	baseline := &benchReport{
+		Phases: []benchPhase{
+			{Name: "hook 1 (copy)", Hook: 1, Mean: 10 * time.Millisecond},
+			{Name: "hook 2 (symlink)", Hook: 2, Mean: 30 * time.Millisecond},
+			{Name: "hook 3 (command)", Hook: 3, Mean: 500 * time.Millisecond},
+			{Name: "hook 4 (command)", Hook: 4, Mean: 200 * time.Millisecond},
+		},
+	}
+
+	plan := planHookOptimization(postCreate, baseline)
+
+	require.Len(t, plan.Groups, 3)
+	assert.Len(t, plan.Groups[0].Entries, 2, "copy and symlink can share a group")
+	assert.Equal(t, 30*time.Millisecond, plan.Groups[0].Duration)
+	assert.Equal(t, 740*time.Millisecond, plan.Sequential)
+	assert.Equal(t, 730*time.Millisecond, plan.CriticalPath)
+
+	require.Len(t, plan.Background, 2)
+	assert.Equal(t, 3, plan.Background[0].Number)
+	assert.Equal(t, 4, plan.Background[1].Number)
+}
+
+func TestPlanHookOptimization_StaleBaseline(t *testing.T) {
+	postCreate := []config.Hook{
+		{Type: config.HookTypeCommand, Command: "npm install"},
+	}
+	baseline := &benchReport{
+		Phases: []benchPhase{{Name: "hook 1 (copy)", Hook: 1, Mean: 10 * time.Millisecond}},
+	}
+
+	plan := planHookOptimization(postCreate, baseline)
+
+	require.Len(t, plan.Entries, 1)
+	assert.False(t, plan.Entries[0].Timed)
+	assert.Empty(t, plan.Background, "untimed hooks are never suggested for background mode")
+}
+
+func TestWriteHookOptimizationPlan(t *testing.T) {
+	postCreate := []config.Hook{
+		{Type: config.HookTypeCopy, From: ".env", To: ".env"},
+		{Type: config.HookTypeCommand, Command: "npm install"},
+	}
+	baseline := &benchReport{
+		RecordedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Phases: []benchPhase{
+			{Name: "hook 2 (command)", Hook: 2, Mean: 400 * time.Millisecond},
+		},
+	}
+
+	var buf bytes.Buffer
+	plan := planHookOptimization(postCreate, baseline)
+	require.NoError(t, writeHookOptimizationPlan(&buf, plan, baseline))
+
+	output := buf.String()
+	assert.Contains(t, output, "Group 1")
+	assert.Contains(t, output, "#1 copy: .env → .env (no timing)")
+	assert.Contains(t, output, "#2 command: npm install (400.0ms)")
+	assert.Contains(t, output, "Background candidates")
+	assert.Contains(t, output, "re-run 'wtp bench --save-baseline'")
+}
+
+func TestWriteHookGroups(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), config.ConfigFileName)
+	original := `# project hooks
+hooks:
+  post_create:
+    - type: copy # secrets
+      from: .env
+    - type: symlink
+      from: .bin
+      to: .bin
+    - type: command
+      command: npm ci
+      group: stale
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(original), 0o600))
+
+	global := config.Hook{Type: config.HookTypeCopy, From: ".gitconfig", To: ".gitconfig", Source: "~/.wtp.yml"}
+	repoHooks := []config.Hook{
+		{Type: config.HookTypeCopy, From: ".env", To: ".env", Source: ".wtp.yml"},
+		{Type: config.HookTypeSymlink, From: ".bin", To: ".bin", Source: ".wtp.yml"},
+		{Type: config.HookTypeCommand, Command: "npm ci", Source: ".wtp.yml"},
+	}
+	plan := planHookOptimization(append([]config.Hook{global}, repoHooks...), &benchReport{})
+
+	written, err := writeHookGroups(configPath, plan)
+	require.NoError(t, err)
+	assert.Equal(t, 1, written)
+
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# project hooks")
+	assert.Contains(t, string(data), "# secrets")
+	assert.NotContains(t, string(data), "stale", "a hook left alone loses its old group")
+
+	var cfg config.Config
+	require.NoError(t, yaml.Unmarshal(data, &cfg))
+	assert.Equal(t, "group-1", cfg.Hooks.PostCreate[0].Group)
+	assert.Equal(t, "group-1", cfg.Hooks.PostCreate[1].Group)
+	assert.Empty(t, cfg.Hooks.PostCreate[2].Group)
+
+	info, err := os.Stat(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestWriteHookGroups_MergePolicies(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), config.ConfigFileName)
+	original := `hooks:
+  post_create:
+    - type: copy
+      from: .bin
+    - id: env
+      merge: replace
+      type: copy
+      from: .env.dev
+      to: .env
+    - type: copy
+      from: .npmrc
+      merge: prepend
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(original), 0o600))
+
+	// The merged order: the prepended hook, the replaced global one, then the appended one
+	postCreate := []config.Hook{
+		{Type: config.HookTypeCopy, From: ".npmrc", To: ".npmrc", Merge: config.HookMergePrepend, Source: ".wtp.yml"},
+		{ID: "env", Type: config.HookTypeCopy, From: ".env.dev", To: ".env", Source: ".wtp.yml"},
+		{Type: config.HookTypeCopy, From: ".bin", To: ".bin", Source: ".wtp.yml"},
+		{Type: config.HookTypeCommand, Command: "npm ci", Source: ".wtp.local.yml"},
+	}
+	plan := planHookOptimization(postCreate, &benchReport{})
+	require.Len(t, plan.Groups, 2)
+
+	written, err := writeHookGroups(configPath, plan)
+	require.NoError(t, err)
+	assert.Equal(t, 1, written)
+
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	var cfg config.Config
+	require.NoError(t, yaml.Unmarshal(data, &cfg))
+	for i, hook := range cfg.Hooks.PostCreate {
+		assert.Equal(t, "group-1", hook.Group, "hook %d of the file", i+1)
+	}
+}
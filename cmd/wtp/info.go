@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+const diskSizeUnit = 1024
+
+// Variable to allow mocking in tests
+var infoGetwd = os.Getwd
+
+// worktreeInfo is everything wtp knows about one worktree; it is also the --json format.
+type worktreeInfo struct {
+	Name      string     `json:"name"`
+	Path      string     `json:"path"`
+	Branch    string     `json:"branch"`
+	HEAD      string     `json:"head"`
+	Main      bool       `json:"main"`
+	Managed   bool       `json:"managed"`
+	Upstream  string     `json:"upstream,omitempty"`
+	Base      string     `json:"base,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	DiskUsage int64      `json:"disk_usage_bytes"`
+	// Provisioning is the record 'wtp add' left; nil for worktrees created outside wtp.
+	Provisioning *provisionRecord `json:"provisioning,omitempty"`
+	// Hibernation is set while 'wtp hibernate' has the worktree's artifacts removed.
+	Hibernation *hibernationState `json:"hibernation,omitempty"`
+}
+
+// NewInfoCommand creates the info command definition
+func NewInfoCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "info",
+		Usage:     "Show details about a worktree",
+		UsageText: "wtp info [<worktree-name>] [--json]",
+		Description: "Prints what wtp knows about a worktree: path, branch, HEAD, upstream, the ref it " +
+			"was created from, creation time, disk usage, and the post-create hooks that ran when " +
+			"'wtp add' provisioned it. Without a name, the current worktree is shown.\n\n" +
+			"Examples:\n" +
+			"  wtp info                  # Current worktree\n" +
+			"  wtp info feature/auth     # A worktree by name\n" +
+			"  wtp info @ --json         # Main worktree as JSON",
+		ArgsUsage:     "[<worktree-name>]",
+		ShellComplete: completeWorktreesForCd,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Print the details as JSON",
+			},
+		},
+		Action: infoCommand,
+	}
+}
+
+func infoCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	_, cfg, _, err := setupRepoAndConfig()
+	if err != nil {
+		return err
+	}
+
+	cwd, err := infoGetwd()
+	if err != nil {
+		return errors.DirectoryAccessFailed("access current", ".", err)
+	}
+
+	executor := command.NewRealExecutor()
+	return infoCommandWithCommandExecutor(w, executor, cfg, cwd, cmd.Args().First(), cmd.Bool("json"))
+}
+
+func infoCommandWithCommandExecutor(
+	w io.Writer, executor command.Executor, cfg *config.Config, cwd, worktreeName string, asJSON bool,
+) error {
+	result, err := executor.Execute([]command.Command{command.GitWorktreeList()})
+	if err != nil {
+		return errors.GitCommandFailed("git worktree list", err.Error())
+	}
+	worktrees := parseWorktreesFromOutput(result.Results[0].Output)
+	mainWorktreePath := findMainWorktreePath(worktrees)
+
+	target, err := resolveWorktreeTarget(worktrees, cfg, worktreeName, cwd, mainWorktreePath)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		if worktreeName == "" {
+			return errors.NotInWorktree("pass a worktree name")
+		}
+		return errors.WorktreeNotFound(worktreeName, managedWorktreeNames(worktrees, cfg, mainWorktreePath))
+	}
+
+	info, err := collectWorktreeInfo(executor, target, cfg, mainWorktreePath)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(info)
+	}
+	return writeWorktreeInfo(w, info)
+}
+
+// resolveWorktreeTarget resolves worktreeName like 'wtp cd', or the worktree containing cwd when
+// no name is given.
+func resolveWorktreeTarget(
+	worktrees []git.Worktree, cfg *config.Config, worktreeName, cwd, mainWorktreePath string,
+) (*git.Worktree, error) {
+	if worktreeName != "" {
+		return resolveWorktreeName(worktreeName, worktrees, cfg, mainWorktreePath)
+	}
+	return findWorktreeContaining(worktrees, cwd), nil
+}
+
+// findWorktreeContaining returns the worktree that contains path, or nil.
+func findWorktreeContaining(worktrees []git.Worktree, path string) *git.Worktree {
+	// Worktrees can be nested (e.g. base_dir inside the main worktree); the deepest match wins
+	var target *git.Worktree
+	for i := range worktrees {
+		if isPathWithin(worktrees[i].Path, path) && (target == nil || len(worktrees[i].Path) > len(target.Path)) {
+			target = &worktrees[i]
+		}
+	}
+	return target
+}
+
+func collectWorktreeInfo(
+	executor command.Executor, wt *git.Worktree, cfg *config.Config, mainWorktreePath string,
+) (*worktreeInfo, error) {
+	record, err := loadProvisionRecord(cfg, wt.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &worktreeInfo{
+		Name:         getWorktreeNameFromPath(wt.Path, cfg, mainWorktreePath, wt.IsMain),
+		Path:         wt.Path,
+		Branch:       formatBranchDisplay(wt.Branch),
+		HEAD:         wt.HEAD,
+		Main:         wt.IsMain,
+		Managed:      isWorktreeManagedCommon(wt.Path, cfg, mainWorktreePath, wt.IsMain),
+		DiskUsage:    diskUsage(wt.Path),
+		Provisioning: record,
+	}
+	if record != nil {
+		info.Base = record.Base
+	}
+	if info.Hibernation, err = loadHibernationState(cfg, wt.Path); err != nil {
+		return nil, err
+	}
+	if createdAt, ok := worktreeCreatedAt(wt.Path, record); ok {
+		info.CreatedAt = &createdAt
+	}
+
+	result, err := executor.Execute([]command.Command{command.GitUpstream(wt.Path)})
+	if err == nil && len(result.Results) > 0 && result.Results[0].Error == nil {
+		info.Upstream = strings.TrimSpace(result.Results[0].Output)
+	}
+	return info, nil
+}
+
+// diskUsage returns the total size of the regular files under path, leaving out the
+// directories in exclude, such as worktrees nested in it. Symlinks are not followed and
+// unreadable entries are skipped.
+func diskUsage(path string, exclude ...string) int64 {
+	var total int64
+	_ = filepath.WalkDir(path, func(current string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // count what can be read
+		}
+		if entry.IsDir() && current != path && slices.Contains(exclude, current) {
+			return filepath.SkipDir
+		}
+		if entry.Type().IsRegular() {
+			if info, infoErr := entry.Info(); infoErr == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total
+}
+
+// formatDiskSize formats a byte count with binary units, e.g. "12.3 MiB".
+func formatDiskSize(size int64) string {
+	if size < diskSizeUnit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value := float64(size)
+	units := []string{"KiB", "MiB", "GiB", "TiB"}
+	unit := ""
+	for _, unit = range units {
+		value /= diskSizeUnit
+		if value < diskSizeUnit {
+			break
+		}
+	}
+	return fmt.Sprintf("%.1f %s", value, unit)
+}
+
+func writeWorktreeInfo(w io.Writer, info *worktreeInfo) error {
+	created := "-"
+	if info.CreatedAt != nil {
+		created = info.CreatedAt.Local().Format(time.RFC3339)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:mnd // column padding
+	rows := [][2]string{
+		{"Name", info.Name},
+		{"Path", info.Path},
+		{"Branch", info.Branch},
+		{"HEAD", info.HEAD},
+		{"Upstream", valueOrDash(info.Upstream)},
+		{"Base", valueOrDash(info.Base)},
+		{"Created", created},
+		{"Disk usage", formatDiskSize(info.DiskUsage)},
+		{"Managed", yesNo(info.Managed)},
+	}
+	if info.Hibernation != nil {
+		rows = append(rows, [2]string{"Hibernated", fmt.Sprintf("since %s (%s freed)",
+			info.Hibernation.HibernatedAt.Local().Format(time.RFC3339), formatDiskSize(info.Hibernation.FreedBytes))})
+	}
+	for _, row := range rows {
+		if _, err := fmt.Fprintf(tw, "%s:\t%s\n", row[0], row[1]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	return writeProvisioning(w, info.Provisioning)
+}
+
+func writeProvisioning(w io.Writer, record *provisionRecord) error {
+	if record == nil {
+		_, err := fmt.Fprintln(w, "\nProvisioning: no record (not created by 'wtp add')")
+		return err
+	}
+	if len(record.Hooks) == 0 && record.Error == "" {
+		_, err := fmt.Fprintln(w, "\nProvisioning: no post-create hooks ran")
+		return err
+	}
+
+	if _, err := fmt.Fprintln(w, "\nProvisioning:"); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:mnd // column padding
+	for _, hook := range record.Hooks {
+		if _, err := fmt.Fprintf(tw, "  #%d\t%s\t%s\t%s\n",
+			hook.Index, hook.Type, hook.Status, formatBenchDuration(hook.Duration)); err != nil {
+			return err
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if record.Error != "" {
+		_, err := fmt.Fprintf(w, "  failed: %s\n", record.Error)
+		return err
+	}
+	return nil
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
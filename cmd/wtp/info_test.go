@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/hooks"
+)
+
+type mockInfoCommandExecutor struct {
+	listOutput string
+	upstream   string
+}
+
+func (m *mockInfoCommandExecutor) Execute(commands []command.Command) (*command.ExecutionResult, error) {
+	results := make([]command.Result, len(commands))
+	for i, cmd := range commands {
+		results[i].Command = cmd
+		switch {
+		case cmd.Args[0] == "worktree":
+			results[i].Output = m.listOutput
+		case m.upstream == "":
+			results[i].Error = errors.New("no upstream configured")
+		default:
+			results[i].Output = m.upstream + "\n"
+		}
+	}
+	return &command.ExecutionResult{Results: results}, nil
+}
+
+func TestNewInfoCommand(t *testing.T) {
+	cmd := NewInfoCommand()
+
+	assert.Equal(t, "info", cmd.Name)
+	assert.NotEmpty(t, cmd.Usage)
+	assert.NotNil(t, cmd.Action)
+	assert.NotNil(t, cmd.ShellComplete)
+}
+
+// setupInfoWorktree gives worktreePath a .git file pointing at a private git directory,
+// like 'git worktree add' does, and returns that directory.
+func setupInfoWorktree(t *testing.T, worktreePath string) string {
+	t.Helper()
+	gitDir := filepath.Join(t.TempDir(), "worktrees", "foo")
+	require.NoError(t, os.MkdirAll(gitDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(worktreePath, ".git"), []byte("gitdir: "+gitDir+"\n"), 0o644))
+	return gitDir
+}
+
+func TestInfoCommand_ShowsProvisioningRecord(t *testing.T) {
+	mainPath, worktreePath, listOutput := setupCheckoutTest(t)
+	setupInfoWorktree(t, worktreePath)
+	require.NoError(t, os.WriteFile(filepath.Join(worktreePath, "data.bin"), make([]byte, 2048), 0o644))
+
+	timings := []hooks.HookTiming{{Index: 1, Type: "copy", Duration: 1500 * time.Microsecond}}
+	record := newProvisionRecord("feature/foo", "main", nil, timings, fmt.Errorf("failed to execute hook 2: boom"))
+	require.NoError(t, saveProvisionRecord(&config.Config{}, worktreePath, record))
+
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+	mockExec := &mockInfoCommandExecutor{listOutput: listOutput, upstream: "origin/feature/foo"}
+
+	var buf bytes.Buffer
+	require.NoError(t, infoCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, "feature/foo", false))
+
+	output := buf.String()
+	assert.Contains(t, output, "Name:")
+	assert.Contains(t, output, "feature/foo")
+	assert.Contains(t, output, worktreePath)
+	assert.Contains(t, output, "origin/feature/foo")
+	assert.Contains(t, output, "Base:        main")
+	assert.Contains(t, output, "Managed:     yes")
+	assert.Contains(t, output, "#1  copy  ok  1.5ms")
+	assert.Contains(t, output, "failed: failed to execute hook 2: boom")
+	assert.NotContains(t, output, "Created:     -")
+}
+
+func TestInfoCommand_JSONForCurrentWorktree(t *testing.T) {
+	mainPath, worktreePath, listOutput := setupCheckoutTest(t)
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+	mockExec := &mockInfoCommandExecutor{listOutput: listOutput}
+
+	var buf bytes.Buffer
+	cwd := filepath.Join(mainPath, "src")
+	require.NoError(t, infoCommandWithCommandExecutor(&buf, mockExec, cfg, cwd, "", true))
+
+	var info worktreeInfo
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &info))
+	assert.Equal(t, "@", info.Name)
+	assert.Equal(t, mainPath, info.Path)
+	assert.True(t, info.Main)
+	assert.Empty(t, info.Upstream)
+	assert.Nil(t, info.Provisioning)
+	assert.NotEqual(t, worktreePath, info.Path)
+}
+
+func TestInfoCommand_NotFound(t *testing.T) {
+	mainPath, _, listOutput := setupCheckoutTest(t)
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+	mockExec := &mockInfoCommandExecutor{listOutput: listOutput}
+
+	var buf bytes.Buffer
+	err := infoCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, "missing", false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing")
+
+	err = infoCommandWithCommandExecutor(&buf, mockExec, cfg, t.TempDir(), "", false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not inside a worktree")
+}
+
+func TestFormatDiskSize(t *testing.T) {
+	assert.Equal(t, "512 B", formatDiskSize(512))
+	assert.Equal(t, "2.0 KiB", formatDiskSize(2048))
+	assert.Equal(t, "1.5 MiB", formatDiskSize(1536*1024))
+	assert.Equal(t, "3.0 GiB", formatDiskSize(3<<30))
+}
+
+func TestAddBaseRef(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		branch        string
+		resolvedTrack string
+		want          string
+	}{
+		{"existing branch", []string{"feature/foo"}, "", "", ""},
+		{"new branch from HEAD", nil, "feature/new", "", "HEAD"},
+		{"new branch from commit", []string{"main"}, "feature/new", "", "main"},
+		{"tracked remote", []string{"feature/foo"}, "", "origin/feature/foo", "origin/feature/foo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := createTestCLICommand(map[string]any{"branch": tt.branch}, tt.args)
+			assert.Equal(t, tt.want, addBaseRef(cmd, tt.resolvedTrack))
+		})
+	}
+}
@@ -25,7 +25,17 @@ func NewInitCommand() *cli.Command {
 		Name:  "init",
 		Usage: "Initialize configuration file",
 		Description: "Creates a .wtp.yml configuration file in the repository root " +
-			"with example hooks and settings.",
+			"with example hooks and settings.\n\n" +
+			"With --interactive, the project type is detected from files such as package.json, " +
+			"go.mod, or Gemfile, post_create hooks that install dependencies are suggested, and " +
+			"base_dir is asked for; the result is shown for confirmation before it is written.",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "interactive",
+				Aliases: []string{"i"},
+				Usage:   "Detect the project type and build the configuration from prompts",
+			},
+		},
 		Action: initCommand,
 	}
 }
@@ -42,11 +52,18 @@ func initCommand(_ context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return errors.NotInGitRepository()
 	}
+	if err := ensureWritableRepo(repo.Path(), "create a configuration file"); err != nil {
+		return err
+	}
 
 	// Check if config file already exists
 	configPath := fmt.Sprintf("%s/%s", repo.Path(), config.ConfigFileName)
-	if _, statErr := os.Stat(configPath); statErr == nil {
-		return errors.ConfigAlreadyExists(configPath)
+	existingPath, findErr := config.FindConfigFile(repo.Path(), config.ConfigFileName)
+	if findErr != nil {
+		return findErr
+	}
+	if _, statErr := os.Stat(existingPath); statErr == nil {
+		return errors.ConfigAlreadyExists(existingPath)
 	}
 
 	repoInfo, repoStatErr := os.Stat(repo.Path())
@@ -62,6 +79,16 @@ func initCommand(_ context.Context, cmd *cli.Command) error {
 		)
 	}
 
+	// Get the writer from cli.Command
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	if cmd.Bool("interactive") {
+		return initInteractive(w, initInput, repo.Path(), configPath)
+	}
+
 	// Create configuration with comments
 	configContent := `# Worktree Plus Configuration
 version: "1.0"
@@ -115,12 +142,6 @@ hooks:
 		return errors.DirectoryAccessFailed("create configuration file", configPath, err)
 	}
 
-	// Get the writer from cli.Command
-	w := cmd.Root().Writer
-	if w == nil {
-		w = os.Stdout
-	}
-
 	if _, printErr := fmt.Fprintf(w, "Configuration file created: %s\n", configPath); printErr != nil {
 		return printErr
 	}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+)
+
+// Variable to allow mocking in tests
+var initInput io.Reader = os.Stdin
+
+// projectDetector recognizes a project type by a marker file in the repository root and
+// suggests the command that installs its dependencies in a new worktree.
+type projectDetector struct {
+	group   string // only the first match of a group is used, e.g. one Node.js package manager
+	name    string
+	marker  string
+	command string
+}
+
+// projectDetectors is ordered so that lock files win over the generic manifest.
+var projectDetectors = []projectDetector{
+	{group: "node", name: "Node.js (pnpm)", marker: "pnpm-lock.yaml", command: "pnpm install --frozen-lockfile"},
+	{group: "node", name: "Node.js (yarn)", marker: "yarn.lock", command: "yarn install --frozen-lockfile"},
+	{group: "node", name: "Node.js (npm)", marker: "package-lock.json", command: "npm ci"},
+	{group: "node", name: "Node.js", marker: "package.json", command: "npm install"},
+	{group: "go", name: "Go", marker: "go.mod", command: "go mod download"},
+	{group: "ruby", name: "Ruby", marker: "Gemfile", command: "bundle install"},
+	{group: "python", name: "Python (uv)", marker: "uv.lock", command: "uv sync"},
+	{group: "python", name: "Python (Poetry)", marker: "poetry.lock", command: "poetry install"},
+	{group: "python", name: "Python (pip)", marker: "requirements.txt", command: "pip install -r requirements.txt"},
+	{group: "rust", name: "Rust", marker: "Cargo.toml", command: "cargo fetch"},
+	{group: "php", name: "PHP", marker: "composer.json", command: "composer install"},
+}
+
+// initCopiedFiles are usually gitignored files a new worktree needs from the main worktree.
+var initCopiedFiles = []string{".env", ".env.local"}
+
+// detectProjectHooks returns the detected project types and the post_create hooks that set
+// up a new worktree for them: copies of local env files first, then dependency installs.
+func detectProjectHooks(repoPath string) (names []string, hooks []config.Hook) {
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(repoPath, name))
+		return err == nil
+	}
+
+	for _, file := range initCopiedFiles {
+		if exists(file) {
+			hooks = append(hooks, config.Hook{Type: config.HookTypeCopy, From: file, To: file})
+		}
+	}
+
+	matched := make(map[string]bool)
+	for _, detector := range projectDetectors {
+		if matched[detector.group] || !exists(detector.marker) {
+			continue
+		}
+		matched[detector.group] = true
+		names = append(names, detector.name)
+		hooks = append(hooks, config.Hook{Type: config.HookTypeCommand, Command: detector.command})
+	}
+	return names, hooks
+}
+
+// initInteractive builds a configuration from the detected project types and the user's
+// answers, shows it, and saves it once confirmed.
+func initInteractive(w io.Writer, input io.Reader, repoPath, configPath string) error {
+	reader := bufio.NewReader(input)
+
+	names, hooks := detectProjectHooks(repoPath)
+	detected := "nothing recognized"
+	if len(names) > 0 {
+		detected = strings.Join(names, ", ")
+	}
+	if _, err := fmt.Fprintf(w, "Detected: %s\n", detected); err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "Base directory for worktrees [%s]: ", config.DefaultBaseDir); err != nil {
+		return err
+	}
+	baseDir, _ := readInitAnswer(reader)
+	if baseDir == "" {
+		baseDir = config.DefaultBaseDir
+	}
+
+	cfg := &config.Config{
+		Version:  config.CurrentVersion,
+		Defaults: config.Defaults{BaseDir: baseDir},
+		Hooks:    config.Hooks{PostCreate: hooks},
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if _, err := fmt.Fprintf(w, "\n%s\nWrite %s? [Y/n]: ", data, configPath); err != nil {
+		return err
+	}
+
+	answer, ok := readInitAnswer(reader)
+	if !ok || (answer != "" && !strings.HasPrefix(strings.ToLower(answer), "y")) {
+		_, err := fmt.Fprintln(w, "\nAborted; nothing was written.")
+		return err
+	}
+
+	if err := config.SaveConfig(repoPath, cfg); err != nil {
+		return errors.DirectoryAccessFailed("create configuration file", configPath, err)
+	}
+	_, err = fmt.Fprintf(w, "Configuration file created: %s\n", configPath)
+	return err
+}
+
+// readInitAnswer reads one trimmed line. ok is false when the input ended without an answer.
+func readInitAnswer(reader *bufio.Reader) (answer string, ok bool) {
+	line, err := reader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", false
+	}
+	return strings.TrimSpace(line), true
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func TestDetectProjectHooks(t *testing.T) {
+	repoPath := t.TempDir()
+	for _, name := range []string{".env", "package.json", "pnpm-lock.yaml", "go.mod"} {
+		require.NoError(t, os.WriteFile(filepath.Join(repoPath, name), nil, 0o644))
+	}
+
+	names, hooks := detectProjectHooks(repoPath)
+
+	assert.Equal(t, []string{"Node.js (pnpm)", "Go"}, names)
+	require.Len(t, hooks, 3)
+	assert.Equal(t, config.Hook{Type: config.HookTypeCopy, From: ".env", To: ".env"}, hooks[0])
+	assert.Equal(t, "pnpm install --frozen-lockfile", hooks[1].Command)
+	assert.Equal(t, "go mod download", hooks[2].Command)
+}
+
+func TestDetectProjectHooks_NothingRecognized(t *testing.T) {
+	names, hooks := detectProjectHooks(t.TempDir())
+
+	assert.Empty(t, names)
+	assert.Empty(t, hooks)
+}
+
+func TestInitInteractive_WritesConfirmedConfig(t *testing.T) {
+	repoPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "Gemfile"), nil, 0o644))
+	configPath := filepath.Join(repoPath, config.ConfigFileName)
+
+	var buf bytes.Buffer
+	require.NoError(t, initInteractive(&buf, strings.NewReader("../wt\n\n"), repoPath, configPath))
+
+	output := buf.String()
+	assert.Contains(t, output, "Detected: Ruby")
+	assert.Contains(t, output, "Base directory for worktrees [../worktrees]: ")
+	assert.Contains(t, output, "command: bundle install")
+	assert.Contains(t, output, "Configuration file created: "+configPath)
+
+	cfg, err := config.LoadConfigFile(configPath)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+	assert.Equal(t, "../wt", cfg.Defaults.BaseDir)
+	require.Len(t, cfg.Hooks.PostCreate, 1)
+	assert.Equal(t, "bundle install", cfg.Hooks.PostCreate[0].Command)
+}
+
+func TestInitInteractive_DefaultsAndDecline(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "declined", input: "\nn\n"},
+		{name: "input ended", input: "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoPath := t.TempDir()
+			configPath := filepath.Join(repoPath, config.ConfigFileName)
+
+			var buf bytes.Buffer
+			require.NoError(t, initInteractive(&buf, strings.NewReader(tt.input), repoPath, configPath))
+
+			assert.Contains(t, buf.String(), "Detected: nothing recognized")
+			assert.Contains(t, buf.String(), "base_dir: ../worktrees")
+			assert.Contains(t, buf.String(), "Aborted; nothing was written.")
+			assert.NoFileExists(t, configPath)
+		})
+	}
+}
+
+func TestInitCommand_Interactive(t *testing.T) {
+	tempDir := t.TempDir()
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	require.NoError(t, os.Chdir(tempDir))
+	if err := exec.Command("git", "init").Run(); err != nil {
+		t.Skip("git not available")
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "go.mod"), []byte("module example\n"), 0o644))
+
+	originalInput := initInput
+	initInput = strings.NewReader("\ny\n")
+	defer func() { initInput = originalInput }()
+
+	var buf bytes.Buffer
+	app := &cli.Command{Commands: []*cli.Command{NewInitCommand()}, Writer: &buf}
+	require.NoError(t, app.Run(context.Background(), []string{"wtp", "init", "--interactive"}))
+
+	content, err := os.ReadFile(filepath.Join(tempDir, config.ConfigFileName))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "go mod download")
+	assert.NotContains(t, string(content), "# Worktree Plus Configuration")
+}
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v3"
 
 	"github.com/satococoa/wtp/v2/internal/config"
@@ -85,6 +86,29 @@ func TestInitCommand_ConfigAlreadyExists(t *testing.T) {
 	assert.Contains(t, err.Error(), "already exists")
 }
 
+func TestInitCommand_TOMLConfigAlreadyExists(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	require.NoError(t, os.Chdir(tempDir))
+
+	gitCmd := exec.Command("git", "init")
+	gitCmd.Dir = tempDir
+	if err := gitCmd.Run(); err != nil {
+		t.Skip("git not available")
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, ".wtp.toml"), []byte("version = \"1.0\"\n"), 0o644))
+
+	app := &cli.Command{Commands: []*cli.Command{NewInitCommand()}}
+	err := app.Run(context.Background(), []string{"wtp", "init"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), ".wtp.toml")
+	assert.NoFileExists(t, filepath.Join(tempDir, config.ConfigFileName))
+}
+
 func TestInitCommand_Success(t *testing.T) {
 	// Create a temporary directory
 	tempDir := t.TempDir()
@@ -136,7 +160,7 @@ func TestInitCommand_Success(t *testing.T) {
 	contentStr := string(content)
 
 	// Check for required sections
-	assert.Contains(t, contentStr, "version: \"1.0\"")
+	assert.Contains(t, contentStr, "version: \""+config.CurrentVersion+"\"")
 	assert.Contains(t, contentStr, "defaults:")
 	assert.Contains(t, contentStr, "base_dir: ../worktrees")
 	assert.Contains(t, contentStr, "hooks:")
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/filelock"
+	"github.com/satococoa/wtp/v2/internal/git"
+	"github.com/satococoa/wtp/v2/internal/hooks"
+)
+
+const lockDirName = "locks"
+
+// lockNamePattern keeps lock names usable as file names on every platform.
+var lockNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// NewInternalCommand creates the internal command definition, which groups helpers meant
+// to be called from hook commands.
+func NewInternalCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "internal",
+		Usage: "Helpers for hook commands",
+		Commands: []*cli.Command{
+			{
+				Name:      "lock",
+				Usage:     "Run a command while holding a named lock",
+				UsageText: "wtp internal lock <name> -- <command> [<args>...]",
+				Description: "Runs the command once no other 'wtp internal lock' with the same name is running " +
+					"in this clone, so that hooks of worktrees provisioned in parallel can share a cache in " +
+					"$WTP_CACHE_DIR without corrupting it. The lock is released when the command exits, even " +
+					"if it crashes, and wtp exits with the command's exit status.\n\n" +
+					"Examples:\n" +
+					"  wtp internal lock npm -- npm ci --cache \"$WTP_CACHE_DIR/npm\"\n" +
+					"  wtp internal lock gradle -- sh -c './gradlew --gradle-user-home \"$WTP_CACHE_DIR/gradle\" build'",
+				ArgsUsage: "<name> -- <command> [<args>...]",
+				// The wrapped command's flags must reach it untouched
+				SkipFlagParsing: true,
+				Action:          internalLockCommand,
+			},
+		},
+	}
+}
+
+func internalLockCommand(ctx context.Context, cmd *cli.Command) error {
+	args := cmd.Args().Slice()
+	// Flag parsing is skipped, so help has to be recognized here
+	if len(args) > 0 && (args[0] == "-h" || args[0] == "--help") {
+		return cli.ShowSubcommandHelp(cmd)
+	}
+
+	name, command, err := parseLockArgs(args)
+	if err != nil {
+		return err
+	}
+
+	cacheDir, err := resolveSharedCacheDir()
+	if err != nil {
+		return err
+	}
+	return runLocked(ctx, filepath.Join(cacheDir, lockDirName, name+".lock"), command, os.Stdout, os.Stderr)
+}
+
+// parseLockArgs splits the arguments of 'wtp internal lock' into the lock name and the
+// command to run. The "--" separator after the name is optional.
+func parseLockArgs(args []string) (name string, command []string, err error) {
+	if len(args) == 0 {
+		return "", nil, errors.InvalidArguments(
+			"lock name required; usage: wtp internal lock <name> -- <command> [<args>...]")
+	}
+	name, command = args[0], args[1:]
+	if !lockNamePattern.MatchString(name) {
+		return "", nil, errors.InvalidArguments("invalid lock name '%s': use letters, digits, '.', '_', and '-'", name)
+	}
+	if len(command) > 0 && command[0] == "--" {
+		command = command[1:]
+	}
+	if len(command) == 0 {
+		return "", nil, errors.InvalidArguments("command required; usage: wtp internal lock <name> -- <command> [<args>...]")
+	}
+	return name, command, nil
+}
+
+// resolveSharedCacheDir returns $WTP_CACHE_DIR when a hook runs us, and otherwise the shared
+// cache directory of the repository containing the current directory.
+func resolveSharedCacheDir() (string, error) {
+	if dir := os.Getenv(hooks.CacheDirEnv); dir != "" {
+		return dir, nil
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", errors.DirectoryAccessFailed("access current", ".", err)
+	}
+	repo, err := git.NewRepository(cwd)
+	if err != nil {
+		return "", errors.NotInGitRepository()
+	}
+	commonDir, err := repo.GetGitCommonDir()
+	if err != nil {
+		return "", errors.GitCommandFailed("git rev-parse --git-common-dir", err.Error())
+	}
+	return hooks.SharedCacheDir(commonDir), nil
+}
+
+// runLocked runs command with the lock at lockPath held. A command that exits with a
+// non-zero status makes wtp exit with the same status and no further message.
+func runLocked(ctx context.Context, lockPath string, command []string, stdout, stderr io.Writer) error {
+	unlock, err := filelock.Lock(lockPath)
+	if err != nil {
+		return err
+	}
+
+	// #nosec G204 -- the command is given by the user on the command line
+	child := exec.CommandContext(ctx, command[0], command[1:]...)
+	child.Stdin = os.Stdin
+	child.Stdout = stdout
+	child.Stderr = stderr
+	runErr := child.Run()
+
+	if err := unlock(); err != nil && runErr == nil {
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+	var exitErr *exec.ExitError
+	if stderrors.As(runErr, &exitErr) {
+		return cli.Exit("", exitErr.ExitCode())
+	}
+	if runErr != nil {
+		return fmt.Errorf("failed to run %s: %w", command[0], runErr)
+	}
+	return nil
+}
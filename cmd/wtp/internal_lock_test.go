@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/hooks"
+)
+
+func TestNewInternalCommand(t *testing.T) {
+	cmd := NewInternalCommand()
+	assert.Equal(t, "internal", cmd.Name)
+	require.Len(t, cmd.Commands, 1)
+	assert.Equal(t, "lock", cmd.Commands[0].Name)
+	assert.True(t, cmd.Commands[0].SkipFlagParsing)
+}
+
+func TestParseLockArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantName    string
+		wantCommand []string
+		wantErr     string
+	}{
+		{"with separator", []string{"npm", "--", "npm", "ci"}, "npm", []string{"npm", "ci"}, ""},
+		{"without separator", []string{"npm", "npm", "ci", "--cache", "x"}, "npm", []string{"npm", "ci", "--cache", "x"}, ""},
+		{"no name", nil, "", nil, "lock name required"},
+		{"no command", []string{"npm", "--"}, "", nil, "command required"},
+		{"path in name", []string{"../npm", "true"}, "", nil, "invalid lock name '../npm'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, command, err := parseLockArgs(tt.args)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantName, name)
+			assert.Equal(t, tt.wantCommand, command)
+		})
+	}
+}
+
+func TestResolveSharedCacheDir_FromEnvironment(t *testing.T) {
+	t.Setenv(hooks.CacheDirEnv, "/shared/cache")
+
+	dir, err := resolveSharedCacheDir()
+	require.NoError(t, err)
+	assert.Equal(t, "/shared/cache", dir)
+}
+
+func TestRunLocked(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping shell command test on Windows")
+	}
+	lockPath := filepath.Join(t.TempDir(), lockDirName, "npm.lock")
+
+	t.Run("runs the command", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		err := runLocked(context.Background(), lockPath, []string{"sh", "-c", "echo installed"}, &stdout, &stderr)
+
+		require.NoError(t, err)
+		assert.Equal(t, "installed\n", stdout.String())
+		assert.FileExists(t, lockPath)
+	})
+
+	t.Run("passes the exit status on", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		err := runLocked(context.Background(), lockPath, []string{"sh", "-c", "echo broken >&2; exit 3"}, &stdout, &stderr)
+
+		var exitCoder cli.ExitCoder
+		require.ErrorAs(t, err, &exitCoder)
+		assert.Equal(t, 3, exitCoder.ExitCode())
+		assert.Empty(t, err.Error())
+		assert.Equal(t, "broken\n", stderr.String())
+	})
+
+	t.Run("missing command", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		err := runLocked(context.Background(), lockPath, []string{"wtp-no-such-command"}, &stdout, &stderr)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to run wtp-no-such-command")
+	})
+}
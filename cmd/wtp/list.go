@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/urfave/cli/v3"
 	"golang.org/x/term"
@@ -16,6 +17,7 @@ import (
 	"github.com/satococoa/wtp/v2/internal/config"
 	"github.com/satococoa/wtp/v2/internal/errors"
 	"github.com/satococoa/wtp/v2/internal/git"
+	"github.com/satococoa/wtp/v2/internal/vcs"
 )
 
 // Display constants
@@ -23,7 +25,9 @@ const (
 	pathHeaderDashes   = 4
 	branchHeaderDashes = 6
 	headDisplayLength  = 8
-	detachedKeyword    = "detached"
+	// idDisplayLength is the width of the ID column plus its separator, e.g. " wt-3f2a".
+	idDisplayLength = 1 + len(worktreeIDPrefix) + worktreeIDDigits
+	detachedKeyword = vcs.DetachedBranch
 )
 
 const (
@@ -59,10 +63,13 @@ var (
 // NewListCommand creates the list command definition
 func NewListCommand() *cli.Command {
 	return &cli.Command{
-		Name:          "list",
-		Aliases:       []string{"ls"},
-		Usage:         "List all worktrees",
-		Description:   "Shows all worktrees with their paths, branches, and HEAD commits.",
+		Name:    "list",
+		Aliases: []string{"ls"},
+		Usage:   "List all worktrees",
+		Description: "Shows all worktrees with their paths, branches, and HEAD commits, and which are locked. " +
+			"With --usage, also shows how much disk space each worktree takes. With --all-repos, lists " +
+			"the worktrees of every clone wtp has created worktrees in, from any directory; clones of " +
+			"one repository are told apart by their paths.",
 		ShellComplete: completeList,
 		Flags: []cli.Flag{
 			&cli.BoolFlag{
@@ -80,12 +87,32 @@ func NewListCommand() *cli.Command {
 				Aliases: []string{"q"},
 				Usage:   "Only display worktree paths",
 			},
+			&cli.BoolFlag{
+				Name:  "usage",
+				Usage: "Show the disk usage of each worktree, measured concurrently",
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Print worktrees as a JSON array, including dirty state and age",
+			},
+			&cli.BoolFlag{
+				Name:  "porcelain",
+				Usage: "Print one tab-separated line per worktree in a stable format for scripts",
+			},
+			&cli.BoolFlag{
+				Name:  "all-repos",
+				Usage: "List the worktrees of every clone wtp has created worktrees in",
+			},
 		},
 		Action: listCommand,
 	}
 }
 
 func listCommand(_ context.Context, cmd *cli.Command) error {
+	if cmd.Bool("all-repos") {
+		return listAllReposCommand(cmd)
+	}
+
 	// Get current working directory (should be a git repository)
 	cwd, err := listGetwd()
 	if err != nil {
@@ -111,10 +138,19 @@ func listCommand(_ context.Context, cmd *cli.Command) error {
 	}
 
 	// Load config to get base_dir
-	cfg, _ := config.LoadConfig(mainRepoPath)
+	cfg, _ := config.LoadConfig(mainRepoPath, "")
 
 	// Resolve display options
 	opts := resolveListDisplayOptions(cmd, w)
+	if cmd.Bool("json") && cmd.Bool("porcelain") {
+		return errors.InvalidArguments("--json cannot be combined with --porcelain")
+	}
+	if opts.Format != "" && cmd.Bool("quiet") {
+		return errors.InvalidArguments("--quiet cannot be combined with --%s", opts.Format)
+	}
+	if opts.Usage && cmd.Bool("quiet") {
+		return errors.InvalidArguments("--quiet cannot be combined with --usage")
+	}
 
 	// Get quiet flag
 	quiet := cmd.Bool("quiet")
@@ -134,15 +170,25 @@ func listCommandWithCommandExecutor(
 		return errors.DirectoryAccessFailed("access current", ".", err)
 	}
 
+	backend, err := vcs.ForConfig(cfg, mainRepoPath)
+	if err != nil {
+		return err
+	}
+
 	// Get worktrees using CommandExecutor
-	listCmd := command.GitWorktreeList()
+	listCmd := backend.ListWorkdirs()
 	result, err := executor.Execute([]command.Command{listCmd})
 	if err != nil {
 		return errors.GitCommandFailed("git worktree list", err.Error())
 	}
 
 	// Parse worktrees from command output
-	worktrees := parseWorktreesFromOutput(result.Results[0].Output)
+	worktrees := backend.ParseWorkdirs(result.Results[0].Output)
+
+	if opts.Format != "" {
+		entries := collectListEntries(executor, backend, worktrees, cwd, cfg, mainRepoPath, time.Now())
+		return displayListEntries(w, entries, worktrees, opts)
+	}
 
 	if len(worktrees) == 0 {
 		if !quiet {
@@ -174,52 +220,35 @@ func listCommandWithCommandExecutor(
 	return nil
 }
 
+// listAllReposCommand runs 'wtp list --all-repos', which needs no repository.
+func listAllReposCommand(cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+	if cmd.Bool("porcelain") {
+		return errors.InvalidArguments("--porcelain cannot be combined with --all-repos")
+	}
+	opts := resolveListDisplayOptions(cmd, w)
+	quiet := cmd.Bool("quiet")
+	if opts.Format != "" && quiet {
+		return errors.InvalidArguments("--quiet cannot be combined with --%s", opts.Format)
+	}
+	if opts.Usage && quiet {
+		return errors.InvalidArguments("--quiet cannot be combined with --usage")
+	}
+	return listAllRepos(w, listNewExecutor(), quiet, opts)
+}
+
 // completeList provides shell completion for the list command (flags only)
 func completeList(_ context.Context, cmd *cli.Command) {
 	current, previous := completionArgsFromCommand(cmd)
 	maybeCompleteFlagSuggestions(cmd, current, previous)
 }
 
+// parseWorktreesFromOutput parses the output of 'git worktree list --porcelain'.
 func parseWorktreesFromOutput(output string) []git.Worktree {
-	lines := strings.Split(strings.TrimSpace(output), "\n")
-	var worktrees []git.Worktree
-	var currentWorktree git.Worktree
-	isFirst := true
-
-	for _, line := range lines {
-		if line == "" {
-			if currentWorktree.Path != "" {
-				// First worktree is always the main worktree
-				if isFirst {
-					currentWorktree.IsMain = true
-					isFirst = false
-				}
-				worktrees = append(worktrees, currentWorktree)
-				currentWorktree = git.Worktree{}
-			}
-			continue
-		}
-
-		if strings.HasPrefix(line, "worktree ") {
-			currentWorktree.Path = strings.TrimPrefix(line, "worktree ")
-		} else if strings.HasPrefix(line, "HEAD ") {
-			currentWorktree.HEAD = strings.TrimPrefix(line, "HEAD ")
-		} else if strings.HasPrefix(line, "branch ") {
-			currentWorktree.Branch = strings.TrimPrefix(line, "branch refs/heads/")
-		} else if line == detachedKeyword {
-			currentWorktree.Branch = detachedKeyword
-		}
-	}
-
-	if currentWorktree.Path != "" {
-		// First worktree is always the main worktree
-		if isFirst {
-			currentWorktree.IsMain = true
-		}
-		worktrees = append(worktrees, currentWorktree)
-	}
-
-	return worktrees
+	return vcs.ParseGitWorktreeList(output)
 }
 
 // isWorktreeManagedList determines if a worktree is managed by wtp (for list command)
@@ -296,37 +325,44 @@ func displayWorktreesRelative(
 		return nil
 	}
 
-	pathWidth, branchWidth, statusWidth := computeListColumnWidths(metrics, termWidth, opts)
+	var usage *listUsageColumn
+	if opts.Usage {
+		usage = newListUsageColumn(worktrees)
+	}
+	pathWidth, branchWidth, statusWidth := computeListColumnWidths(metrics, termWidth-usage.displayWidth(), opts)
 
 	if _, err := fmt.Fprintf(
 		w,
-		"%-*s %-*s %-*s %s\n",
+		"%-*s %-*s %-*s %-*s %s\n",
 		pathWidth, "PATH",
 		branchWidth, "BRANCH",
 		statusWidth, "STATUS",
-		"HEAD",
+		headDisplayLength, "HEAD",
+		usage.withID("ID", "USAGE"),
 	); err != nil {
 		return err
 	}
-	if _, err := fmt.Fprintf(w, "%-*s %-*s %-*s %s\n",
+	if _, err := fmt.Fprintf(w, "%-*s %-*s %-*s %-*s %s\n",
 		pathWidth, strings.Repeat("-", pathHeaderDashes),
 		branchWidth, strings.Repeat("-", branchHeaderDashes),
 		statusWidth, strings.Repeat("-", len("STATUS")),
-		"----"); err != nil {
+		headDisplayLength, "----",
+		usage.withID("--", strings.Repeat("-", len("USAGE")))); err != nil {
 		return err
 	}
 
-	for _, item := range items {
+	for i, item := range items {
 		headShort := item.head
 		if len(headShort) > headDisplayLength {
 			headShort = headShort[:headDisplayLength]
 		}
 
-		if _, err := fmt.Fprintf(w, "%-*s %-*s %-*s %s\n",
+		if _, err := fmt.Fprintf(w, "%-*s %-*s %-*s %-*s %s\n",
 			pathWidth, truncatePath(item.path, pathWidth),
 			branchWidth, truncatePath(item.branch, branchWidth),
 			statusWidth, truncatePath(item.status, statusWidth),
-			headShort); err != nil {
+			headDisplayLength, headShort,
+			usage.withID(item.id, usage.size(i))); err != nil {
 			return err
 		}
 	}
@@ -339,6 +375,7 @@ type listDisplayData struct {
 	branch string
 	head   string
 	status string
+	id     string
 }
 
 type listColumnMetrics struct {
@@ -357,8 +394,9 @@ func collectListDisplayData(
 	}
 
 	items := make([]listDisplayData, 0, len(worktrees))
+	ids := worktreeIDs(worktrees)
 
-	for _, wt := range worktrees {
+	for i, wt := range worktrees {
 		pathDisplay := getWorktreeDisplayName(wt, cfg, mainRepoPath)
 		if wt.Path == currentPath {
 			pathDisplay += "*"
@@ -370,6 +408,9 @@ func collectListDisplayData(
 		if isWorktreeManagedList(wt.Path, cfg, mainRepoPath, wt.IsMain) {
 			statusDisplay = "managed"
 		}
+		if wt.Locked {
+			statusDisplay += ", locked"
+		}
 
 		if len(pathDisplay) > metrics.maxPathLen {
 			metrics.maxPathLen = len(pathDisplay)
@@ -386,6 +427,7 @@ func collectListDisplayData(
 			branch: branchDisplay,
 			head:   wt.HEAD,
 			status: statusDisplay,
+			id:     ids[i],
 		})
 	}
 
@@ -419,7 +461,8 @@ func clampBranchAndStatusWidths(
 	}
 
 	spacingTotal := columnSpacing * columnSpacingSlots
-	maxAvailableForBranch := termWidth - minPathWidth - statusWidth - spacingTotal - headDisplayLength
+	maxAvailableForBranch := termWidth - minPathWidth - statusWidth - spacingTotal - headDisplayLength -
+		idDisplayLength
 	if branchWidth > maxAvailableForBranch {
 		branchWidth = maxAvailableForBranch
 		if branchWidth < branchHeaderWidth {
@@ -433,7 +476,7 @@ func clampBranchAndStatusWidths(
 func derivePathWidth(maxPathLen, branchWidth, statusWidth, termWidth int, opts listDisplayOptions) int {
 	pathHeaderWidth := len("PATH")
 	availableForPath := termWidth - columnSpacing - branchWidth - columnSpacing - statusWidth -
-		columnSpacing - headDisplayLength
+		columnSpacing - headDisplayLength - idDisplayLength
 	availableForPath = max(availableForPath, pathHeaderWidth)
 
 	pathWidth := availableForPath
@@ -470,6 +513,10 @@ type listDisplayOptions struct {
 	Compact      bool
 	MaxPathWidth int
 	OutputIsTTY  bool
+	// Format selects machine-readable output: listFormatJSON, listFormatPorcelain, or "" for the table.
+	Format string
+	// Usage adds the disk usage of each worktree.
+	Usage bool
 }
 
 func resolveListDisplayOptions(cmd *cli.Command, w io.Writer) listDisplayOptions {
@@ -492,9 +539,19 @@ func resolveListDisplayOptions(cmd *cli.Command, w io.Writer) listDisplayOptions
 		outputIsTTY = term.IsTerminal(int(file.Fd()))
 	}
 
+	format := ""
+	switch {
+	case cmd.Bool("json"):
+		format = listFormatJSON
+	case cmd.Bool("porcelain"):
+		format = listFormatPorcelain
+	}
+
 	return listDisplayOptions{
 		Compact:      compact,
 		MaxPathWidth: maxPathWidth,
 		OutputIsTTY:  outputIsTTY,
+		Format:       format,
+		Usage:        cmd.Bool("usage"),
 	}
 }
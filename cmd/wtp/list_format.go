@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/git"
+	"github.com/satococoa/wtp/v2/internal/vcs"
+)
+
+// Machine-readable output formats of 'wtp list'
+const (
+	listFormatJSON      = "json"
+	listFormatPorcelain = "porcelain"
+)
+
+// listEntry is one worktree in 'wtp list --json' output.
+type listEntry struct {
+	// ID is the worktree's stable short ID; see worktreeIDs.
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Branch  string `json:"branch"`
+	HEAD    string `json:"head"`
+	Main    bool   `json:"main"`
+	Managed bool   `json:"managed"`
+	// CreatedByWtp is set when the state registry says 'wtp add' created the worktree,
+	// unlike a worktree added with 'git worktree add' under base_dir.
+	CreatedByWtp bool `json:"created_by_wtp"`
+	Current      bool `json:"current"`
+	Dirty        bool `json:"dirty"`
+	// Locked is set for a worktree locked with 'wtp lock' or 'git worktree lock'.
+	Locked     bool   `json:"locked"`
+	LockReason string `json:"lock_reason,omitempty"`
+	// CreatedAt and AgeSeconds are omitted when the creation time is unknown.
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+	AgeSeconds int64      `json:"age_seconds,omitempty"`
+	// DiskUsage is the size of the worktree's files in bytes, set with --usage.
+	DiskUsage *int64 `json:"disk_usage_bytes,omitempty"`
+}
+
+// collectListEntries gathers the machine-readable view of worktrees. Dirty state comes
+// from one status command of backend per worktree, run as a single batch.
+func collectListEntries(
+	executor command.Executor, backend vcs.Backend, worktrees []git.Worktree, currentPath string, cfg *config.Config,
+	mainRepoPath string, now time.Time,
+) []listEntry {
+	statusCommands := make([]command.Command, len(worktrees))
+	for i := range worktrees {
+		statusCommands[i] = backend.Status(worktrees[i].Path)
+	}
+	var statuses []command.Result
+	if result, err := executor.Execute(statusCommands); err == nil {
+		statuses = result.Results
+	}
+
+	current := findWorktreeContaining(worktrees, currentPath)
+	ids := worktreeIDs(worktrees)
+	registry := loadWorktreeRegistry(mainRepoPath)
+	entries := make([]listEntry, 0, len(worktrees))
+	for i := range worktrees {
+		wt := &worktrees[i]
+		branch := wt.Branch
+		if branch == detachedKeyword {
+			branch = ""
+		}
+		entry := listEntry{
+			ID:      ids[i],
+			Name:    getWorktreeDisplayName(*wt, cfg, mainRepoPath),
+			Path:    wt.Path,
+			Branch:  branch,
+			HEAD:    wt.HEAD,
+			Main:    wt.IsMain,
+			Managed: isWorktreeManagedList(wt.Path, cfg, mainRepoPath, wt.IsMain),
+			Current: current == wt,
+			Locked:  wt.Locked,
+		}
+		entry.LockReason = wt.LockReason
+		entry.CreatedByWtp = registry.Find(wt.Path) != nil
+		if i < len(statuses) && statuses[i].Error == nil {
+			entry.Dirty = strings.TrimSpace(statuses[i].Output) != ""
+		}
+		record, _ := loadProvisionRecord(cfg, wt.Path)
+		if createdAt, ok := worktreeCreatedAt(wt.Path, record); ok {
+			entry.CreatedAt = &createdAt
+			entry.AgeSeconds = int64(now.Sub(createdAt) / time.Second)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// displayListEntries writes entries in the format selected by opts, with the disk usage of
+// worktrees added for --usage.
+func displayListEntries(w io.Writer, entries []listEntry, worktrees []git.Worktree, opts listDisplayOptions) error {
+	if opts.Usage {
+		applyDiskUsage(entries, listDiskUsage(worktrees))
+	}
+	if opts.Format == listFormatJSON {
+		return displayWorktreesJSON(w, entries)
+	}
+	return displayWorktreesPorcelain(w, entries)
+}
+
+// displayWorktreesJSON writes entries as a JSON array.
+func displayWorktreesJSON(w io.Writer, entries []listEntry) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(entries)
+}
+
+// displayWorktreesPorcelain writes one tab-separated line per worktree with the fields
+// name, path, branch, HEAD, managed|unmanaged, dirty|clean, the creation time (RFC 3339,
+// or "-" when unknown), the worktree ID, locked|unlocked, and the disk usage in bytes (or "-"
+// without --usage). A detached HEAD has an empty branch field. The format is stable across
+// releases; new fields are only ever appended.
+func displayWorktreesPorcelain(w io.Writer, entries []listEntry) error {
+	for i := range entries {
+		entry := &entries[i]
+		managed := "unmanaged"
+		if entry.Managed {
+			managed = "managed"
+		}
+		dirty := "clean"
+		if entry.Dirty {
+			dirty = "dirty"
+		}
+		created := "-"
+		if entry.CreatedAt != nil {
+			created = entry.CreatedAt.Format(time.RFC3339)
+		}
+		locked := "unlocked"
+		if entry.Locked {
+			locked = "locked"
+		}
+		usage := "-"
+		if entry.DiskUsage != nil {
+			usage = strconv.FormatInt(*entry.DiskUsage, 10)
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			entry.Name, entry.Path, entry.Branch, entry.HEAD, managed, dirty, created, entry.ID, locked,
+			usage); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+type mockListFormatExecutor struct {
+	listOutput string
+	status     map[string]string // worktree path → 'git status --porcelain' output
+}
+
+func (m *mockListFormatExecutor) Execute(commands []command.Command) (*command.ExecutionResult, error) {
+	results := make([]command.Result, len(commands))
+	for i, cmd := range commands {
+		results[i].Command = cmd
+		if cmd.Args[0] == "worktree" {
+			results[i].Output = m.listOutput
+		} else {
+			results[i].Output = m.status[cmd.WorkDir]
+		}
+	}
+	return &command.ExecutionResult{Results: results}, nil
+}
+
+func setupListFormatTest(t *testing.T) (mainPath, worktreePath string, mockExec *mockListFormatExecutor) {
+	t.Helper()
+	mainPath, worktreePath, listOutput := setupCheckoutTest(t)
+	listOutput += fmt.Sprintf("worktree %s\nHEAD 0123456\ndetached\nlocked on a USB drive\n\n",
+		filepath.Join(mainPath, "..", "scratch"))
+
+	originalGetwd := listGetwd
+	listGetwd = func() (string, error) { return worktreePath, nil }
+	t.Cleanup(func() { listGetwd = originalGetwd })
+
+	mockExec = &mockListFormatExecutor{
+		listOutput: listOutput,
+		status:     map[string]string{worktreePath: " M README.md\n"},
+	}
+	return mainPath, worktreePath, mockExec
+}
+
+func TestListCommand_JSON(t *testing.T) {
+	mainPath, worktreePath, mockExec := setupListFormatTest(t)
+	setupInfoWorktree(t, worktreePath)
+	created := time.Now().Add(-time.Hour).UTC()
+	require.NoError(t, saveProvisionRecord(&config.Config{}, worktreePath,
+		&provisionRecord{CreatedAt: created, Branch: "feature/foo"}))
+
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+	opts := defaultListDisplayOptionsForTests()
+	opts.Format = listFormatJSON
+
+	var buf bytes.Buffer
+	require.NoError(t, listCommandWithCommandExecutor(&cli.Command{}, &buf, mockExec, cfg, mainPath, false, opts))
+
+	var entries []listEntry
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entries))
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, "@", entries[0].Name)
+	assert.Equal(t, "wt-c364", entries[0].ID)
+	assert.True(t, entries[0].Main)
+	assert.False(t, entries[0].Dirty)
+	assert.False(t, entries[0].Current)
+	assert.Nil(t, entries[0].CreatedAt)
+
+	assert.Equal(t, "feature/foo", entries[1].Name)
+	assert.Equal(t, "wt-2c26", entries[1].ID, "the ID comes from the worktree's git directory name")
+	assert.Equal(t, "feature/foo", entries[1].Branch)
+	assert.True(t, entries[1].Managed)
+	assert.True(t, entries[1].Current)
+	assert.True(t, entries[1].Dirty)
+	require.NotNil(t, entries[1].CreatedAt)
+	assert.InDelta(t, time.Hour.Seconds(), float64(entries[1].AgeSeconds), 60)
+
+	assert.False(t, entries[1].Locked)
+
+	assert.Empty(t, entries[2].Branch, "detached HEAD has no branch")
+	assert.False(t, entries[2].Managed)
+	assert.True(t, entries[2].Locked)
+	assert.Equal(t, "on a USB drive", entries[2].LockReason)
+}
+
+func TestListCommand_Porcelain(t *testing.T) {
+	mainPath, worktreePath, mockExec := setupListFormatTest(t)
+	setupInfoWorktree(t, worktreePath)
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+	opts := defaultListDisplayOptionsForTests()
+	opts.Format = listFormatPorcelain
+
+	var buf bytes.Buffer
+	require.NoError(t, listCommandWithCommandExecutor(&cli.Command{}, &buf, mockExec, cfg, mainPath, false, opts))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "@\t"+mainPath+"\tmain\tabc123\tmanaged\tclean\t-\twt-c364\tunlocked\t-", lines[0])
+	assert.Equal(t, "feature/foo\t"+worktreePath+"\tfeature/foo\tdef456\tmanaged\tdirty\t-\twt-2c26\tunlocked\t-",
+		lines[1])
+	fields := strings.Split(lines[2], "\t")
+	require.Len(t, fields, 10)
+	assert.Empty(t, fields[2])
+	assert.Equal(t, "unmanaged", fields[4])
+	assert.Equal(t, "locked", fields[8])
+}
+
+func TestListCommand_JSONNoWorktrees(t *testing.T) {
+	opts := defaultListDisplayOptionsForTests()
+	opts.Format = listFormatJSON
+
+	var buf bytes.Buffer
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+	err := listCommandWithCommandExecutor(&cli.Command{}, &buf, &mockListFormatExecutor{}, cfg, "/test/repo", false, opts)
+	require.NoError(t, err)
+	assert.Equal(t, "[]\n", buf.String())
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/git"
+	"github.com/satococoa/wtp/v2/internal/state"
+	"github.com/satococoa/wtp/v2/internal/vcs"
+)
+
+// listRepo is one clone in 'wtp list --all-repos --json' output.
+type listRepo struct {
+	// Name is the repository name, followed by the clone's path when another clone has
+	// the same name, e.g. two clones of one repository.
+	Name      string      `json:"name"`
+	RemoteURL string      `json:"remote_url,omitempty"`
+	Path      string      `json:"path"`
+	Worktrees []listEntry `json:"worktrees"`
+}
+
+// listedClone is a clone from the clone registry that still exists, with its label.
+type listedClone struct {
+	state.Clone
+	label string
+}
+
+// listAllRepos lists the worktrees of every clone in the clone registry: a table per
+// clone under its label, their worktree paths with quiet, or one JSON array of clones.
+func listAllRepos(w io.Writer, executor command.Executor, quiet bool, opts listDisplayOptions) error {
+	path, err := clonesRegistryPath()
+	if err != nil {
+		return err
+	}
+	registry, err := state.LoadClones(path)
+	if err != nil {
+		return err
+	}
+	clones := labelClones(liveClones(registry.Clones))
+	if len(clones) == 0 && opts.Format == "" && !quiet {
+		_, err := fmt.Fprintln(w, "No repositories found; a clone is recorded when 'wtp add' creates a worktree in it")
+		return err
+	}
+
+	cwd, err := listGetwd()
+	if err != nil {
+		cwd = ""
+	}
+	repos := make([]listRepo, 0, len(clones))
+	for i := range clones {
+		repo, err := listClone(w, executor, &clones[i], cwd, i == 0, quiet, opts)
+		if err != nil {
+			return err
+		}
+		if repo != nil {
+			repos = append(repos, *repo)
+		}
+	}
+	if opts.Format == "" {
+		return nil
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(repos)
+}
+
+// listClone writes the worktrees of clone as a table, or their paths with quiet. With a
+// machine-readable format it writes nothing and returns the clone's entry instead.
+func listClone(
+	w io.Writer, executor command.Executor, clone *listedClone, cwd string, first, quiet bool,
+	opts listDisplayOptions,
+) (*listRepo, error) {
+	cfg, _ := config.LoadConfig(clone.Path, "")
+	worktrees, backend, err := listCloneWorktrees(executor, cfg, clone.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case opts.Format != "":
+		entries := collectListEntries(executor, backend, worktrees, cwd, cfg, clone.Path, time.Now())
+		if opts.Usage {
+			applyDiskUsage(entries, listDiskUsage(worktrees))
+		}
+		return &listRepo{Name: clone.label, RemoteURL: clone.RemoteURL, Path: clone.Path, Worktrees: entries}, nil
+	case quiet:
+		for _, wt := range worktrees {
+			if _, err := fmt.Fprintln(w, wt.Path); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	default:
+		return nil, displayCloneTable(w, clone, worktrees, cwd, cfg, first, opts)
+	}
+}
+
+// liveClones drops the clones that are gone, or whose origin changed since they were
+// recorded: the clone at that path is then another entry.
+func liveClones(clones []state.Clone) []state.Clone {
+	live := make([]state.Clone, 0, len(clones))
+	for _, clone := range clones {
+		if info, err := os.Stat(filepath.Join(clone.Path, ".git")); err != nil || !info.IsDir() {
+			continue
+		}
+		if git.OriginURL(clone.Path) != clone.RemoteURL {
+			continue
+		}
+		live = append(live, clone)
+	}
+	return live
+}
+
+// labelClones names each clone after its repository, and adds the clone's path to the
+// names that more than one clone has. The clones are sorted by label.
+func labelClones(clones []state.Clone) []listedClone {
+	names := make([]string, len(clones))
+	count := map[string]int{}
+	for i := range clones {
+		names[i] = config.RepoNameFromURL(clones[i].RemoteURL)
+		if names[i] == "" {
+			names[i] = filepath.Base(clones[i].Path)
+		}
+		count[names[i]]++
+	}
+
+	labeled := make([]listedClone, len(clones))
+	for i := range clones {
+		label := names[i]
+		if count[label] > 1 {
+			label = fmt.Sprintf("%s (%s)", label, clones[i].Path)
+		}
+		labeled[i] = listedClone{Clone: clones[i], label: label}
+	}
+	sort.SliceStable(labeled, func(i, j int) bool { return labeled[i].label < labeled[j].label })
+	return labeled
+}
+
+// listCloneWorktrees lists the worktrees of the clone at clonePath with the backend its
+// configuration selects.
+func listCloneWorktrees(
+	executor command.Executor, cfg *config.Config, clonePath string,
+) (worktrees []git.Worktree, backend vcs.Backend, err error) {
+	backend, err = vcs.ForConfig(cfg, clonePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	listCmd := backend.ListWorkdirs()
+	listCmd.WorkDir = clonePath
+	result, err := executor.Execute([]command.Command{listCmd})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list the worktrees of %s: %w", clonePath, err)
+	}
+	if res := result.Results[0]; res.Error != nil {
+		return nil, nil, fmt.Errorf("failed to list the worktrees of %s: %s", clonePath, res.Output)
+	}
+	return backend.ParseWorkdirs(result.Results[0].Output), backend, nil
+}
+
+// displayCloneTable writes the label of clone and the table of its worktrees, separated
+// from the clone before it by a blank line unless it is the first.
+func displayCloneTable(
+	w io.Writer, clone *listedClone, worktrees []git.Worktree, cwd string, cfg *config.Config, first bool,
+	opts listDisplayOptions,
+) error {
+	if !first {
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintln(w, clone.label); err != nil {
+		return err
+	}
+	termWidth := getTerminalWidth()
+	if !opts.Compact && (!opts.OutputIsTTY || termWidth >= superWideThreshold) {
+		opts.Compact = true
+	}
+	return displayWorktreesRelative(w, worktrees, cwd, cfg, clone.Path, termWidth, opts)
+}
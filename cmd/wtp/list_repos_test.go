@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/state"
+)
+
+func TestLabelClones(t *testing.T) {
+	const url = "git@github.com:satococoa/wtp.git"
+	now := time.Now()
+	clones := labelClones([]state.Clone{
+		state.NewClone(url, "/work/wtp", now),
+		state.NewClone("https://example.com/app.git", "/src/app", now),
+		state.NewClone(url, "/src/wtp", now),
+		state.NewClone("", "/src/scratch", now),
+	})
+
+	labels := make([]string, len(clones))
+	for i := range clones {
+		labels[i] = clones[i].label
+	}
+	assert.Equal(t, []string{"app", "scratch", "wtp (/src/wtp)", "wtp (/work/wtp)"}, labels,
+		"only clones sharing a name show their paths")
+}
+
+// setupClones clones a repository twice, as app and app-copy, and records both clones in a
+// temporary clone registry.
+func setupClones(t *testing.T) (first, second string) {
+	t.Helper()
+	root := t.TempDir()
+	upstream := filepath.Join(root, "upstream")
+	runGit := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=wtp", "GIT_AUTHOR_EMAIL=wtp@example.com",
+			"GIT_COMMITTER_NAME=wtp", "GIT_COMMITTER_EMAIL=wtp@example.com")
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	runGit("init", "-q", upstream)
+	runGit("-C", upstream, "commit", "-q", "--allow-empty", "-m", "initial")
+	first, second = filepath.Join(root, "app"), filepath.Join(root, "app-copy")
+	runGit("clone", "-q", upstream, first)
+	runGit("clone", "-q", upstream, second)
+
+	useTempClonesRegistry(t)
+	registerClone(first, upstream)
+	registerClone(second, upstream)
+	return first, second
+}
+
+func TestListAllRepos(t *testing.T) {
+	first, second := setupClones(t)
+	var buf bytes.Buffer
+
+	err := listAllRepos(&buf, command.NewRealExecutor(), false, listDisplayOptions{MaxPathWidth: defaultMaxPathWidth})
+
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "upstream ("+first+")\nPATH")
+	assert.Contains(t, buf.String(), "\n\nupstream ("+second+")\nPATH")
+}
+
+func TestListAllRepos_JSONAndStaleClones(t *testing.T) {
+	first, second := setupClones(t)
+	require.NoError(t, os.RemoveAll(second))
+	var buf bytes.Buffer
+
+	opts := listDisplayOptions{Format: listFormatJSON, MaxPathWidth: defaultMaxPathWidth}
+	require.NoError(t, listAllRepos(&buf, command.NewRealExecutor(), false, opts))
+
+	var repos []listRepo
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &repos))
+	require.Len(t, repos, 1, "a clone that is gone is left out")
+	assert.Equal(t, "upstream", repos[0].Name, "a clone with a unique name needs no path")
+	assert.Equal(t, first, repos[0].Path)
+	require.Len(t, repos[0].Worktrees, 1)
+	assert.True(t, repos[0].Worktrees[0].Main)
+}
+
+func TestListAllRepos_Empty(t *testing.T) {
+	useTempClonesRegistry(t)
+	var buf bytes.Buffer
+
+	require.NoError(t, listAllRepos(&buf, command.NewRealExecutor(), false, listDisplayOptions{}))
+	assert.Contains(t, buf.String(), "No repositories found")
+
+	buf.Reset()
+	require.NoError(t, listAllRepos(&buf, command.NewRealExecutor(), true, listDisplayOptions{}))
+	assert.Empty(t, buf.String())
+}
@@ -1310,3 +1310,16 @@ func TestCompleteList_SuggestsQuietFlag(t *testing.T) {
 		assert.Contains(t, buf.String(), "--quiet")
 	})
 }
+
+func TestIsWorktreeManagedCommon_BranchOverlayBaseDir(t *testing.T) {
+	cfg := &config.Config{
+		Defaults: config.Defaults{BaseDir: "../worktrees"},
+		Branches: config.BranchOverlays{
+			{Pattern: "release/*", Defaults: config.Defaults{BaseDir: "../releases"}},
+		},
+	}
+
+	assert.True(t, isWorktreeManagedCommon("/repos/worktrees/feature/a", cfg, "/repos/app", false))
+	assert.True(t, isWorktreeManagedCommon("/repos/releases/release/1.0", cfg, "/repos/app", false))
+	assert.False(t, isWorktreeManagedCommon("/elsewhere/x", cfg, "/repos/app", false))
+}
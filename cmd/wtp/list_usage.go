@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+// listDiskUsage measures the disk usage of each worktree for 'wtp list --usage'; it is a
+// variable to allow mocking in tests.
+var listDiskUsage = measureDiskUsage
+
+// measureDiskUsage returns the disk usage of each worktree, in the order of worktrees. The
+// worktrees are walked concurrently, and a worktree nested in another, such as one under
+// a base_dir inside the main worktree, counts only toward itself.
+func measureDiskUsage(worktrees []git.Worktree) []int64 {
+	sizes := make([]int64, len(worktrees))
+	var wg sync.WaitGroup
+	for i := range worktrees {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sizes[i] = diskUsage(worktrees[i].Path, nestedWorktreePaths(worktrees, worktrees[i].Path)...)
+		}(i)
+	}
+	wg.Wait()
+	return sizes
+}
+
+// nestedWorktreePaths returns the paths of the worktrees inside path.
+func nestedWorktreePaths(worktrees []git.Worktree, path string) []string {
+	var nested []string
+	prefix := filepath.Clean(path) + string(filepath.Separator)
+	for i := range worktrees {
+		if strings.HasPrefix(filepath.Clean(worktrees[i].Path), prefix) {
+			nested = append(nested, filepath.Clean(worktrees[i].Path))
+		}
+	}
+	return nested
+}
+
+// applyDiskUsage sets the disk usage of entries, measured in the same order.
+func applyDiskUsage(entries []listEntry, sizes []int64) {
+	for i := range entries {
+		if i < len(sizes) {
+			entries[i].DiskUsage = &sizes[i]
+		}
+	}
+}
+
+// listUsageColumn is the USAGE column that 'wtp list --usage' adds after the ID column.
+type listUsageColumn struct {
+	sizes []string
+	width int
+}
+
+// newListUsageColumn measures worktrees and formats their sizes for the table.
+func newListUsageColumn(worktrees []git.Worktree) *listUsageColumn {
+	sizes := listDiskUsage(worktrees)
+	column := &listUsageColumn{sizes: make([]string, len(sizes)), width: len("USAGE")}
+	for i, size := range sizes {
+		column.sizes[i] = formatDiskSize(size)
+		column.width = max(column.width, len(column.sizes[i]))
+	}
+	return column
+}
+
+// withID returns the ID column followed by value, right-aligned in the USAGE column. A nil
+// column, as without --usage, returns id alone.
+func (c *listUsageColumn) withID(id, value string) string {
+	if c == nil {
+		return id
+	}
+	return fmt.Sprintf("%-*s %*s", idDisplayLength-1, id, c.width, value)
+}
+
+// size returns the formatted size of the i-th worktree.
+func (c *listUsageColumn) size(i int) string {
+	if c == nil || i >= len(c.sizes) {
+		return ""
+	}
+	return c.sizes[i]
+}
+
+// displayWidth returns how much of the terminal width the column takes.
+func (c *listUsageColumn) displayWidth() int {
+	if c == nil {
+		return 0
+	}
+	return 1 + c.width
+}
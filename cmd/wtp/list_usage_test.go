@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+func TestMeasureDiskUsage(t *testing.T) {
+	root := t.TempDir()
+	mainPath := filepath.Join(root, "repo")
+	nestedPath := filepath.Join(mainPath, ".worktrees", "feature")
+	otherPath := filepath.Join(root, "other")
+	for path, size := range map[string]int{
+		filepath.Join(mainPath, "README.md"):                 100,
+		filepath.Join(nestedPath, "main.go"):                 10,
+		filepath.Join(nestedPath, "node_modules", "pkg.js"):  5,
+		filepath.Join(otherPath, "data.bin"):                 1000,
+		filepath.Join(mainPath, ".worktrees", "notes.txt"):   1,
+		filepath.Join(mainPath, ".worktrees-old", "old.txt"): 2,
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, make([]byte, size), 0o600))
+	}
+
+	sizes := measureDiskUsage([]git.Worktree{{Path: mainPath}, {Path: nestedPath}, {Path: otherPath}})
+	assert.Equal(t, []int64{103, 15, 1000}, sizes, "a nested worktree counts only toward itself")
+}
+
+func mockListDiskUsage(t *testing.T, sizes ...int64) {
+	t.Helper()
+	original := listDiskUsage
+	listDiskUsage = func([]git.Worktree) []int64 { return sizes }
+	t.Cleanup(func() { listDiskUsage = original })
+}
+
+func TestListCommand_UsageTable(t *testing.T) {
+	mainPath, _, mockExec := setupListFormatTest(t)
+	mockListDiskUsage(t, 2048, 1536, 12)
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+	opts := defaultListDisplayOptionsForTests()
+	opts.Usage = true
+
+	var buf bytes.Buffer
+	require.NoError(t, listCommandWithCommandExecutor(&cli.Command{}, &buf, mockExec, cfg, mainPath, false, opts))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 5)
+	assert.True(t, strings.HasSuffix(lines[0], "ID        USAGE"), lines[0])
+	assert.True(t, strings.HasSuffix(lines[1], "--        -----"), lines[1])
+	assert.True(t, strings.HasSuffix(lines[2], " 2.0 KiB"), lines[2])
+	assert.True(t, strings.HasSuffix(lines[3], " 1.5 KiB"), lines[3])
+	assert.True(t, strings.HasSuffix(lines[4], "    12 B"), lines[4])
+}
+
+func TestListCommand_UsagePorcelainAndJSON(t *testing.T) {
+	mainPath, _, mockExec := setupListFormatTest(t)
+	mockListDiskUsage(t, 2048, 1536, 12)
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+	opts := defaultListDisplayOptionsForTests()
+	opts.Usage = true
+
+	opts.Format = listFormatPorcelain
+	var buf bytes.Buffer
+	require.NoError(t, listCommandWithCommandExecutor(&cli.Command{}, &buf, mockExec, cfg, mainPath, false, opts))
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 3)
+	assert.True(t, strings.HasSuffix(lines[1], "\tunlocked\t1536"), lines[1])
+
+	opts.Format = listFormatJSON
+	buf.Reset()
+	require.NoError(t, listCommandWithCommandExecutor(&cli.Command{}, &buf, mockExec, cfg, mainPath, false, opts))
+	assert.Contains(t, buf.String(), `"disk_usage_bytes": 1536`)
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+// NewLockCommand creates the lock command definition
+func NewLockCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "lock",
+		Usage:     "Lock a worktree so that it is not removed or pruned",
+		UsageText: "wtp lock [--reason <text>] [<worktree-name>]",
+		Description: "Locks the worktree with 'git worktree lock'. 'wtp remove' and 'wtp prune' leave a " +
+			"locked worktree alone unless --force is given, and git does not prune its administrative " +
+			"files when it lives on a drive that is not always mounted. Without a name, the current " +
+			"worktree is locked. 'wtp list' shows which worktrees are locked.\n\n" +
+			"Examples:\n" +
+			"  wtp lock feature/auth                         # Lock a worktree\n" +
+			"  wtp lock --reason \"on a USB drive\" feature/x   # ... and say why\n" +
+			"  wtp unlock feature/auth                       # Unlock it again",
+		ArgsUsage: "[<worktree-name>]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "reason",
+				Usage: "Why the worktree is locked, shown by 'wtp list' and when removing it is refused",
+			},
+		},
+		ShellComplete: completeWorktreesForCd,
+		Action:        lockCommand,
+	}
+}
+
+// NewUnlockCommand creates the unlock command definition
+func NewUnlockCommand() *cli.Command {
+	return &cli.Command{
+		Name:          "unlock",
+		Usage:         "Unlock a worktree locked with 'wtp lock'",
+		UsageText:     "wtp unlock [<worktree-name>]",
+		Description:   "Unlocks the worktree with 'git worktree unlock'. Without a name, the current worktree is unlocked.",
+		ArgsUsage:     "[<worktree-name>]",
+		ShellComplete: completeWorktreesForCd,
+		Action:        unlockCommand,
+	}
+}
+
+func lockCommand(_ context.Context, cmd *cli.Command) error {
+	return runLockCommand(cmd, true)
+}
+
+func unlockCommand(_ context.Context, cmd *cli.Command) error {
+	return runLockCommand(cmd, false)
+}
+
+func runLockCommand(cmd *cli.Command, lock bool) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	_, cfg, _, err := setupRepoAndConfig()
+	if err != nil {
+		return err
+	}
+	operation := "unlock worktrees"
+	if lock {
+		operation = "lock worktrees"
+	}
+	if err := ensureWritable(cfg, operation); err != nil {
+		return err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return errors.DirectoryAccessFailed("access current", ".", err)
+	}
+
+	executor := command.NewRealExecutor()
+	if lock {
+		return lockCommandWithCommandExecutor(w, executor, cfg, cwd, cmd.Args().First(), cmd.String("reason"))
+	}
+	return unlockCommandWithCommandExecutor(w, executor, cfg, cwd, cmd.Args().First())
+}
+
+func lockCommandWithCommandExecutor(
+	w io.Writer, executor command.Executor, cfg *config.Config, cwd, worktreeName, reason string,
+) error {
+	wt, name, err := resolveLockTarget(executor, cfg, cwd, worktreeName)
+	if err != nil {
+		return err
+	}
+	if wt.Locked {
+		_, err := fmt.Fprintf(w, "Worktree '%s' is already locked%s\n", name, lockReasonSuffix(wt.LockReason))
+		return err
+	}
+	if err := executeLockCommand(executor, command.GitWorktreeLock(wt.Path, reason)); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "Locked worktree '%s' at %s%s\n", name, wt.Path, lockReasonSuffix(reason))
+	return err
+}
+
+func unlockCommandWithCommandExecutor(
+	w io.Writer, executor command.Executor, cfg *config.Config, cwd, worktreeName string,
+) error {
+	wt, name, err := resolveLockTarget(executor, cfg, cwd, worktreeName)
+	if err != nil {
+		return err
+	}
+	if !wt.Locked {
+		_, err := fmt.Fprintf(w, "Worktree '%s' is not locked\n", name)
+		return err
+	}
+	if err := executeLockCommand(executor, command.GitWorktreeUnlock(wt.Path)); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "Unlocked worktree '%s' at %s\n", name, wt.Path)
+	return err
+}
+
+// resolveLockTarget finds the worktree named worktreeName, or the one containing cwd, and
+// its display name. The main worktree cannot be locked.
+func resolveLockTarget(
+	executor command.Executor, cfg *config.Config, cwd, worktreeName string,
+) (*git.Worktree, string, error) {
+	result, err := executor.Execute([]command.Command{command.GitWorktreeList()})
+	if err != nil {
+		return nil, "", errors.GitCommandFailed("git worktree list", err.Error())
+	}
+	worktrees := parseWorktreesFromOutput(result.Results[0].Output)
+	mainWorktreePath := findMainWorktreePath(worktrees)
+
+	wt, err := resolveWorktreeTarget(worktrees, cfg, worktreeName, cwd, mainWorktreePath)
+	if err != nil {
+		return nil, "", err
+	}
+	if wt == nil {
+		if worktreeName == "" {
+			return nil, "", errors.NotInWorktree("pass a worktree name")
+		}
+		return nil, "", errors.WorktreeNotFound(worktreeName, managedWorktreeNames(worktrees, cfg, mainWorktreePath))
+	}
+	if wt.IsMain {
+		return nil, "", errors.MainWorktreeUnsupported("lock or unlock", "git only locks linked worktrees")
+	}
+	return wt, getWorktreeDisplayName(*wt, cfg, mainWorktreePath), nil
+}
+
+// executeLockCommand runs 'git worktree lock' or 'git worktree unlock'.
+func executeLockCommand(executor command.Executor, lockCmd command.Command) error {
+	name := "git worktree " + lockCmd.Args[1]
+	result, err := executor.Execute([]command.Command{lockCmd})
+	if err != nil {
+		return errors.GitCommandFailed(name, err.Error())
+	}
+	if len(result.Results) > 0 && result.Results[0].Error != nil {
+		return errors.GitCommandFailed(name, result.Results[0].Output)
+	}
+	return nil
+}
+
+// lockReasonSuffix formats the reason of a lock for the end of a message.
+func lockReasonSuffix(reason string) string {
+	if reason == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", reason)
+}
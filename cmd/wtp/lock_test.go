@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+)
+
+type mockLockCommandExecutor struct {
+	executedCommands []command.Command
+	listOutput       string
+}
+
+func (m *mockLockCommandExecutor) Execute(commands []command.Command) (*command.ExecutionResult, error) {
+	m.executedCommands = append(m.executedCommands, commands...)
+	results := make([]command.Result, len(commands))
+	for i, cmd := range commands {
+		results[i].Command = cmd
+		if cmd.Args[1] == "list" {
+			results[i].Output = m.listOutput
+		}
+	}
+	return &command.ExecutionResult{Results: results}, nil
+}
+
+func TestNewLockCommands(t *testing.T) {
+	lock := NewLockCommand()
+	assert.Equal(t, "lock", lock.Name)
+	assert.NotNil(t, lock.Action)
+	assert.Equal(t, "unlock", NewUnlockCommand().Name)
+}
+
+func TestLockCommand(t *testing.T) {
+	mainPath, worktreePath, listOutput := setupCheckoutTest(t)
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+
+	mockExec := &mockLockCommandExecutor{listOutput: listOutput}
+	var buf bytes.Buffer
+	require.NoError(t, lockCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, "feature/foo", "on a USB drive"))
+	assert.Equal(t, command.GitWorktreeLock(worktreePath, "on a USB drive"), mockExec.executedCommands[1])
+	assert.Equal(t, "Locked worktree 'feature/foo' at "+worktreePath+" (on a USB drive)\n", buf.String())
+
+	// Locking a locked worktree changes nothing
+	mockExec = &mockLockCommandExecutor{
+		listOutput: strings.Replace(listOutput, "feature/foo\n", "feature/foo\nlocked old reason\n", 1),
+	}
+	buf.Reset()
+	require.NoError(t, lockCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, "feature/foo", ""))
+	assert.Len(t, mockExec.executedCommands, 1)
+	assert.Equal(t, "Worktree 'feature/foo' is already locked (old reason)\n", buf.String())
+}
+
+func TestLockCommand_CurrentWorktree(t *testing.T) {
+	_, worktreePath, listOutput := setupCheckoutTest(t)
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+
+	mockExec := &mockLockCommandExecutor{listOutput: listOutput}
+	require.NoError(t, lockCommandWithCommandExecutor(&bytes.Buffer{}, mockExec, cfg, worktreePath, "", ""))
+	assert.Equal(t, command.GitWorktreeLock(worktreePath, ""), mockExec.executedCommands[1])
+}
+
+func TestLockCommand_RefusesMainWorktree(t *testing.T) {
+	mainPath, _, listOutput := setupCheckoutTest(t)
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+
+	err := lockCommandWithCommandExecutor(&bytes.Buffer{}, &mockLockCommandExecutor{listOutput: listOutput},
+		cfg, mainPath, "@", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot lock or unlock the main worktree")
+	code, _ := errors.CodeOf(err)
+	assert.Equal(t, errors.CodeMainWorktreeUnsupported, code)
+}
+
+func TestUnlockCommand(t *testing.T) {
+	mainPath, worktreePath, listOutput := setupCheckoutTest(t)
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+
+	mockExec := &mockLockCommandExecutor{
+		listOutput: strings.Replace(listOutput, "feature/foo\n", "feature/foo\nlocked\n", 1),
+	}
+	var buf bytes.Buffer
+	require.NoError(t, unlockCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, "feature/foo"))
+	assert.Equal(t, command.GitWorktreeUnlock(worktreePath), mockExec.executedCommands[1])
+	assert.Equal(t, "Unlocked worktree 'feature/foo' at "+worktreePath+"\n", buf.String())
+
+	mockExec = &mockLockCommandExecutor{listOutput: listOutput}
+	buf.Reset()
+	require.NoError(t, unlockCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, "feature/foo"))
+	assert.Len(t, mockExec.executedCommands, 1)
+	assert.Equal(t, "Worktree 'feature/foo' is not locked\n", buf.String())
+}
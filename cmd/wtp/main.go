@@ -3,7 +3,11 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/events"
 )
 
 // Version information
@@ -27,7 +31,19 @@ func main() {
 
 	args := normalizeCompletionArgs(os.Args)
 	if err := app.Run(context.Background(), args); err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
-		os.Exit(1)
+		os.Exit(reportError(os.Stderr, err))
+	}
+}
+
+// reportError emits err as an error event and writes it to stderr, unless the events go
+// to stderr as well: the error event carries the same message there. It returns the exit
+// status for err.
+func reportError(stderr io.Writer, err error) int {
+	status := errors.ExitStatus(err)
+	code := errors.CodeFor(err)
+	events.Emit(&events.Event{Type: events.TypeError, Error: err.Error(), Code: string(code), ExitCode: status})
+	if !events.Writes(stderr) {
+		_, _ = fmt.Fprintln(stderr, errors.Format(err))
 	}
+	return status
 }
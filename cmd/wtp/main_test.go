@@ -3,11 +3,18 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/events"
 )
 
 func TestMain(t *testing.T) {
@@ -90,6 +97,17 @@ func TestAppRun_Help(t *testing.T) {
 	}
 }
 
+func TestNewApp_LockTimeoutHelpShowsOneDefault(t *testing.T) {
+	var buf bytes.Buffer
+	app := newApp()
+	app.Writer = &buf
+
+	require.NoError(t, app.Run(context.Background(), []string{"wtp", "--help"}))
+
+	assert.Contains(t, buf.String(), "e.g. 2m (default: defaults.lock_timeout, 10m)\n")
+	assert.NotContains(t, buf.String(), "(default: 0s)")
+}
+
 // TestAppRun_InvalidCommand was removed as it was a coverage-driven test
 // that didn't provide meaningful user value. The CLI framework handles
 // invalid commands gracefully by showing help, which is tested elsewhere.
@@ -147,3 +165,45 @@ func createApp() *cli.Command {
 		},
 	}
 }
+
+func TestReportError(t *testing.T) {
+	t.Cleanup(func() { events.Enable(nil) })
+	err := fmt.Errorf("boom")
+
+	var stderr bytes.Buffer
+	assert.Equal(t, 1, reportError(&stderr, err))
+	assert.Equal(t, "[WTP1000] boom\n\nRun 'wtp explain WTP1000' for causes and fixes\n", stderr.String())
+
+	stderr.Reset()
+	events.Enable(&stderr)
+	reportError(&stderr, err)
+	var event events.Event
+	require.NoError(t, json.Unmarshal(stderr.Bytes(), &event), "stderr holds only the error event")
+	assert.Equal(t, "boom", event.Error)
+	assert.Equal(t, string(errors.CodeUnclassified), event.Code)
+
+	stderr.Reset()
+	var stream bytes.Buffer
+	events.Enable(&stream)
+	reportError(&stderr, err)
+	assert.Contains(t, stderr.String(), "[WTP1000] boom", "events in their own stream leave stderr as it is")
+	assert.Contains(t, stream.String(), `"type":"error"`)
+}
+
+func TestEnableJSONEventsFile(t *testing.T) {
+	t.Cleanup(func() { events.Enable(nil) })
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+
+	require.NoError(t, enableJSONEventsFile(context.Background(), nil, path))
+	require.NoError(t, enableJSONEvents(context.Background(), nil, true))
+	assert.False(t, events.Writes(os.Stderr), "--json-events-file takes precedence over stderr")
+	events.Emit(&events.Event{Type: events.TypeWorktreeRemoved, Worktree: "/wt/foo"})
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"type":"worktree_removed"`)
+
+	err = enableJSONEventsFile(context.Background(), nil, filepath.Join(path, "missing", "events.ndjson"))
+	code, _ := errors.CodeOf(err)
+	assert.Equal(t, errors.CodeInvalidArguments, code)
+}
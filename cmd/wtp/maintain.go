@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/git"
+	"github.com/satococoa/wtp/v2/internal/hooks"
+)
+
+const (
+	maintenanceStateFileName = "maintenance.json"
+	maintenanceStateFileMode = 0o644
+)
+
+// maintenanceState is the on-disk record of the last successful 'wtp maintain' run.
+type maintenanceState struct {
+	LastRun time.Time `json:"last_run"`
+}
+
+// NewMaintainCommand creates the maintain command definition
+func NewMaintainCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "maintain",
+		Aliases:   []string{"gc"},
+		Usage:     "Run maintenance hooks across all worktrees",
+		UsageText: "wtp maintain [--force] [--fail-fast]",
+		Description: "Runs the hooks.maintenance entries from .wtp.yml in every worktree, e.g. " +
+			"'git maintenance run', cache pruning, or stale artifact cleanup. When " +
+			"defaults.maintenance_interval is set, runs within the interval do nothing, so " +
+			"'wtp maintain' can be called from cron or a shell startup file.\n\n" +
+			"A worktree whose hooks fail does not stop the others unless --fail-fast is given; " +
+			"the result in each worktree is then shown, and wtp exits with status 3 when " +
+			"maintenance succeeded in some worktrees.\n\n" +
+			"Examples:\n" +
+			"  wtp maintain            # Run maintenance hooks if they are due\n" +
+			"  wtp maintain --force    # Run them now regardless of the interval",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "Run even if maintenance_interval has not elapsed",
+			},
+			newFailFastFlag(),
+		},
+		Action: maintainCommand,
+	}
+}
+
+func maintainCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	repo, cfg, mainRepoPath, err := setupRepoAndConfig()
+	if err != nil {
+		return err
+	}
+	if err := ensureWritable(cfg, "run maintenance hooks"); err != nil {
+		return err
+	}
+
+	statePath, err := maintenanceStatePath(repo)
+	if err != nil {
+		return err
+	}
+
+	executor := command.NewRealExecutor()
+	return maintainCommandWithCommandExecutor(w, executor, cfg, mainRepoPath, statePath,
+		cmd.Bool("force"), cmd.Bool("fail-fast"))
+}
+
+func maintenanceStatePath(repo *git.Repository) (string, error) {
+	commonDir, err := repo.GetGitCommonDir()
+	if err != nil {
+		return "", errors.GitCommandFailed("git rev-parse --git-common-dir", err.Error())
+	}
+	return filepath.Join(commonDir, stateDirName, maintenanceStateFileName), nil
+}
+
+func maintainCommandWithCommandExecutor(
+	w io.Writer, executor command.Executor, cfg *config.Config, mainRepoPath, statePath string, force, failFast bool,
+) error {
+	if !force {
+		due, err := maintenanceDue(w, cfg, statePath, time.Now())
+		if err != nil || !due {
+			return err
+		}
+	}
+
+	result, err := executor.Execute([]command.Command{command.GitWorktreeList()})
+	if err != nil {
+		return errors.GitCommandFailed("git worktree list", err.Error())
+	}
+	worktrees := parseWorktreesFromOutput(result.Results[0].Output)
+
+	report, err := runMaintenanceHooks(w, cfg, mainRepoPath, worktrees, failFast)
+	if err != nil {
+		return err
+	}
+	if !report.complete() {
+		if err := report.write(w); err != nil {
+			return err
+		}
+		return report.err(errors.MaintenanceHooksFailed)
+	}
+	maintained := len(report.results)
+	if maintained == 0 {
+		_, err := fmt.Fprintln(w, "No maintenance hooks configured (add them under hooks.maintenance in .wtp.yml)")
+		return err
+	}
+
+	if err := saveMaintenanceState(statePath, &maintenanceState{LastRun: time.Now().UTC()}); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "\n✓ Maintenance completed in %d worktree(s)\n", maintained)
+	return err
+}
+
+// runMaintenanceHooks runs the maintenance hooks of every worktree that has some and
+// reports the result in each. A failure is reported as a warning and the other worktrees
+// still go ahead, unless failFast skips them.
+func runMaintenanceHooks(
+	w io.Writer, cfg *config.Config, mainRepoPath string, worktrees []git.Worktree, failFast bool,
+) (*batchReport, error) {
+	report := &batchReport{}
+	for i := range worktrees {
+		wt := &worktrees[i]
+		wtCfg, err := maintenanceConfig(mainRepoPath, wt)
+		if err != nil {
+			return nil, err
+		}
+		if wtCfg == nil || !wtCfg.HasMaintenanceHooks() {
+			continue
+		}
+
+		name := getWorktreeNameFromPath(wt.Path, cfg, mainRepoPath, wt.IsMain)
+		if failFast && len(report.names(batchFailed)) > 0 {
+			report.skip(name, failFastReason)
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "\nRunning maintenance hooks in %s...\n", name); err != nil {
+			return nil, err
+		}
+		if err := hooks.NewExecutor(wtCfg, mainRepoPath).ExecuteMaintenanceHooks(w, wt.Path); err != nil {
+			report.fail(name, err)
+			warnErr := writeWarning(w, errors.CodeWarnMaintenanceHooksFailed, "Maintenance hooks failed in %s: %v", name, err)
+			if warnErr != nil {
+				return nil, warnErr
+			}
+			continue
+		}
+		report.succeed(name)
+	}
+	return report, nil
+}
+
+// maintenanceConfig loads the configuration for wt's branch. It returns nil for a
+// prunable worktree whose directory no longer exists.
+func maintenanceConfig(mainRepoPath string, wt *git.Worktree) (*config.Config, error) {
+	if _, err := os.Stat(wt.Path); err != nil {
+		return nil, nil
+	}
+	branch := wt.Branch
+	if branch == detachedKeyword {
+		branch = ""
+	}
+	cfg, err := config.LoadConfig(mainRepoPath, branch)
+	if err != nil {
+		return nil, errors.ConfigLoadFailed(filepath.Join(mainRepoPath, config.ConfigFileName), err)
+	}
+	return cfg, nil
+}
+
+// maintenanceDue reports whether defaults.maintenance_interval has elapsed since the last
+// successful run, and tells the user when the next run is due if it has not.
+func maintenanceDue(w io.Writer, cfg *config.Config, statePath string, now time.Time) (bool, error) {
+	interval := cfg.MaintenanceInterval()
+	if interval == 0 {
+		return true, nil
+	}
+	state, err := loadMaintenanceState(statePath)
+	if err != nil {
+		return false, err
+	}
+	if state == nil {
+		return true, nil
+	}
+
+	elapsed := now.Sub(state.LastRun)
+	if elapsed >= interval {
+		return true, nil
+	}
+	_, err = fmt.Fprintf(w, "Maintenance last ran %s ago; next run is due in %s (use --force to run now)\n",
+		elapsed.Round(time.Second), (interval - elapsed).Round(time.Second))
+	return false, err
+}
+
+func loadMaintenanceState(path string) (*maintenanceState, error) {
+	// #nosec G304 -- path is derived from the repository's git directory
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read maintenance state: %w", err)
+	}
+
+	var state maintenanceState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse maintenance state %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+func saveMaintenanceState(path string, state *maintenanceState) error {
+	if err := os.MkdirAll(filepath.Dir(path), stateDirMode); err != nil {
+		return errors.DirectoryAccessFailed("create", filepath.Dir(path), err)
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode maintenance state: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), maintenanceStateFileMode); err != nil {
+		return fmt.Errorf("failed to write maintenance state: %w", err)
+	}
+	return nil
+}
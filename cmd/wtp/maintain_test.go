@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+)
+
+func TestNewMaintainCommand(t *testing.T) {
+	cmd := NewMaintainCommand()
+
+	assert.Equal(t, "maintain", cmd.Name)
+	assert.Contains(t, cmd.Aliases, "gc")
+	assert.NotEmpty(t, cmd.Usage)
+	assert.NotNil(t, cmd.Action)
+}
+
+func setupMaintainTest(t *testing.T, configYAML string) (mainPath, worktreePath, listOutput, statePath string) {
+	t.Helper()
+	mainPath, worktreePath, listOutput = setupCheckoutTest(t)
+	require.NoError(t, os.WriteFile(filepath.Join(mainPath, config.ConfigFileName), []byte(configYAML), 0o644))
+	statePath = filepath.Join(t.TempDir(), "wtp", maintenanceStateFileName)
+	return mainPath, worktreePath, listOutput, statePath
+}
+
+func TestMaintainCommand_RunsHooksInEveryWorktree(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+	mainPath, worktreePath, listOutput, statePath := setupMaintainTest(t, `version: "1.0"
+defaults:
+  base_dir: ../worktrees
+  maintenance_interval: 24h
+hooks:
+  maintenance:
+    - type: command
+      command: "touch maintained"
+`)
+	cfg, err := config.LoadConfig(mainPath, "")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	mockExec := &mockCheckoutCommandExecutor{listOutput: listOutput}
+	require.NoError(t, maintainCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, statePath, false, false))
+
+	assert.FileExists(t, filepath.Join(mainPath, "maintained"))
+	assert.FileExists(t, filepath.Join(worktreePath, "maintained"))
+	assert.Contains(t, buf.String(), "Running maintenance hooks in feature/foo")
+	assert.Contains(t, buf.String(), "Maintenance completed in 2 worktree(s)")
+	assert.FileExists(t, statePath)
+
+	// A second run within the interval does nothing
+	require.NoError(t, os.Remove(filepath.Join(mainPath, "maintained")))
+	buf.Reset()
+	require.NoError(t, maintainCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, statePath, false, false))
+	assert.Contains(t, buf.String(), "next run is due in")
+	assert.NoFileExists(t, filepath.Join(mainPath, "maintained"))
+
+	// --force ignores the interval
+	buf.Reset()
+	require.NoError(t, maintainCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, statePath, true, false))
+	assert.FileExists(t, filepath.Join(mainPath, "maintained"))
+}
+
+func TestMaintainCommand_FailureDoesNotRecordRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+	mainPath, worktreePath, listOutput, statePath := setupMaintainTest(t, `version: "1.0"
+defaults:
+  base_dir: ../worktrees
+hooks:
+  maintenance:
+    - type: command
+      command: "test ! -f fail-here"
+`)
+	require.NoError(t, os.WriteFile(filepath.Join(worktreePath, "fail-here"), nil, 0o644))
+	cfg, err := config.LoadConfig(mainPath, "")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	mockExec := &mockCheckoutCommandExecutor{listOutput: listOutput}
+	err = maintainCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, statePath, false, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "maintenance hooks failed in 1 worktree(s): feature/foo")
+	assert.Contains(t, buf.String(), "Running maintenance hooks in @")
+	assert.Regexp(t, `feature/foo +✗ failed: `, buf.String())
+	assert.Equal(t, errors.ExitPartialFailure, errors.ExitStatus(err))
+	assert.NoFileExists(t, statePath)
+}
+
+func TestMaintainCommand_NoHooks(t *testing.T) {
+	mainPath, _, listOutput, statePath := setupMaintainTest(t, "version: \"1.0\"\n")
+	cfg, err := config.LoadConfig(mainPath, "")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	mockExec := &mockCheckoutCommandExecutor{listOutput: listOutput}
+	require.NoError(t, maintainCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, statePath, false, false))
+	assert.Contains(t, buf.String(), "No maintenance hooks configured")
+	assert.NoFileExists(t, statePath)
+}
+
+func TestMaintenanceDue(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), maintenanceStateFileName)
+	lastRun := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, saveMaintenanceState(statePath, &maintenanceState{LastRun: lastRun}))
+	cfg := &config.Config{Defaults: config.Defaults{MaintenanceInterval: "24h"}}
+
+	var buf bytes.Buffer
+	due, err := maintenanceDue(&buf, cfg, statePath, lastRun.Add(6*time.Hour))
+	require.NoError(t, err)
+	assert.False(t, due)
+	assert.Contains(t, buf.String(), "Maintenance last ran 6h0m0s ago; next run is due in 18h0m0s")
+
+	due, err = maintenanceDue(&buf, cfg, statePath, lastRun.Add(25*time.Hour))
+	require.NoError(t, err)
+	assert.True(t, due)
+
+	due, err = maintenanceDue(&buf, &config.Config{}, statePath, lastRun)
+	require.NoError(t, err)
+	assert.True(t, due, "no interval means always due")
+}
@@ -0,0 +1,362 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+)
+
+const (
+	// worktreeMapSection is the git config section holding a <branch>.path key per branch.
+	worktreeMapSection = "wtp-worktree"
+	// worktreeMapEnvPrefix starts the name of each branch's variable in the env format.
+	worktreeMapEnvPrefix = "WTP_WORKTREE_"
+	worktreeMapFileMode  = 0o644
+)
+
+// worktreeMapEntry is one worktree in the branch-to-directory map.
+type worktreeMapEntry struct {
+	Name string `json:"name"`
+	// Branch is empty for a worktree with a detached HEAD.
+	Branch string `json:"branch,omitempty"`
+	Path   string `json:"path"`
+	Main   bool   `json:"main"`
+}
+
+// worktreeMap is the branch-to-directory map, as written in the json format.
+type worktreeMap struct {
+	Repository string             `json:"repository"`
+	Worktrees  []worktreeMapEntry `json:"worktrees"`
+}
+
+// NewMapCommand creates the map command definition
+func NewMapCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "map",
+		Usage:     "Export which directory every branch is checked out in",
+		UsageText: "wtp map [--format json|env|gitconfig] [--output <file>] [--git-config]",
+		Description: "Prints the branch-to-directory map of all worktrees for build systems, IDE run " +
+			"configurations, and scripts. With --output it is written to a file instead, and with " +
+			"--git-config a wtp-worktree.<branch>.path key per branch is kept in the repository's " +
+			"git config. Set 'map.file' or 'map.git_config' in .wtp.yml to have wtp add, remove, " +
+			"prune, checkout, and relink keep them up to date.\n\n" +
+			"Examples:\n" +
+			"  wtp map                                   # JSON on stdout\n" +
+			"  wtp map --format env > .env.worktrees     # WTP_WORKTREE_<BRANCH>=<path> lines\n" +
+			"  wtp map --git-config                      # Then: git config --get-regexp '^wtp-worktree'",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "format",
+				Aliases: []string{"f"},
+				Usage:   "Output format: json, env, or gitconfig",
+				Value:   config.MapFormatJSON,
+			},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "Write the map to this file instead of stdout",
+			},
+			&cli.BoolFlag{
+				Name:  "git-config",
+				Usage: "Set a wtp-worktree.<branch>.path key per branch in the repository's git config",
+			},
+		},
+		Action: mapCommand,
+	}
+}
+
+func mapCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+	format := cmd.String("format")
+	if err := config.ValidateMapFormat(format); err != nil {
+		return fmt.Errorf("invalid --format: %w", err)
+	}
+	output, gitConfig := cmd.String("output"), cmd.Bool("git-config")
+
+	_, cfg, mainRepoPath, err := setupRepoAndConfig()
+	if err != nil {
+		return err
+	}
+	if output != "" || gitConfig {
+		if err := ensureWritable(cfg, "write the worktree map"); err != nil {
+			return err
+		}
+	}
+
+	executor := command.NewRealExecutor()
+	m, err := loadWorktreeMap(executor, cfg, mainRepoPath)
+	if err != nil {
+		return err
+	}
+	data, err := renderWorktreeMap(m, format)
+	if err != nil {
+		return err
+	}
+	if output == "" && !gitConfig {
+		_, err := w.Write(data)
+		return err
+	}
+	return writeWorktreeMapExports(w, executor, m, data, output, gitConfig)
+}
+
+// writeWorktreeMapExports writes the rendered map to output, when set, and the map to the
+// git config with gitConfig, reporting each.
+func writeWorktreeMapExports(
+	w io.Writer, executor command.Executor, m *worktreeMap, data []byte, output string, gitConfig bool,
+) error {
+	if output != "" {
+		if err := writeWorktreeMapFile(output, data); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "✓ Wrote the map of %d worktree(s) to %s\n", len(m.Worktrees), output); err != nil {
+			return err
+		}
+	}
+	if !gitConfig {
+		return nil
+	}
+	branches, err := syncWorktreeMapGitConfig(executor, m)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "✓ Set %s.<branch>.path for %d branch(es) in the git config\n",
+		worktreeMapSection, branches)
+	return err
+}
+
+// loadWorktreeMap lists the worktrees, in 'git worktree list' order, for the map.
+func loadWorktreeMap(executor command.Executor, cfg *config.Config, mainRepoPath string) (*worktreeMap, error) {
+	result, err := executor.Execute([]command.Command{command.GitWorktreeList()})
+	if err != nil {
+		return nil, errors.GitCommandFailed("git worktree list", err.Error())
+	}
+	if len(result.Results) == 0 || result.Results[0].Error != nil {
+		var output string
+		if len(result.Results) > 0 {
+			output = result.Results[0].Output
+		}
+		return nil, errors.GitCommandFailed("git worktree list", output)
+	}
+
+	m := &worktreeMap{Repository: mainRepoPath, Worktrees: []worktreeMapEntry{}}
+	for _, wt := range parseWorktreesFromOutput(result.Results[0].Output) {
+		branch := wt.Branch
+		if branch == detachedKeyword {
+			branch = ""
+		}
+		m.Worktrees = append(m.Worktrees, worktreeMapEntry{
+			Name:   getWorktreeDisplayName(wt, cfg, mainRepoPath),
+			Branch: branch,
+			Path:   wt.Path,
+			Main:   wt.IsMain,
+		})
+	}
+	return m, nil
+}
+
+// renderWorktreeMap formats the map as JSON, as env lines for shells and dotenv loaders,
+// or as a git config file to include. The env and gitconfig formats leave out worktrees
+// with a detached HEAD.
+func renderWorktreeMap(m *worktreeMap, format string) ([]byte, error) {
+	var b bytes.Buffer
+	switch format {
+	case config.MapFormatEnv:
+		fmt.Fprintf(&b, "WTP_REPO_ROOT=%s\n", envQuote(m.Repository))
+		for _, entry := range m.Worktrees {
+			if entry.Branch != "" {
+				fmt.Fprintf(&b, "%s=%s\n", worktreeMapEnvName(entry.Branch), envQuote(entry.Path))
+			}
+		}
+	case config.MapFormatGitConfig:
+		for _, entry := range m.Worktrees {
+			if entry.Branch != "" {
+				fmt.Fprintf(&b, "[%s %s]\n\tpath = %s\n", worktreeMapSection, gitConfigQuote(entry.Branch),
+					gitConfigQuote(entry.Path))
+			}
+		}
+	default:
+		data, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode the worktree map: %w", err)
+		}
+		b.Write(data)
+		b.WriteByte('\n')
+	}
+	return b.Bytes(), nil
+}
+
+// worktreeMapEnvName returns the variable holding branch's directory in the env format:
+// "feature/auth" becomes WTP_WORKTREE_FEATURE_AUTH.
+func worktreeMapEnvName(branch string) string {
+	return worktreeMapEnvPrefix + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, branch)
+}
+
+// envQuote single-quotes value when a shell would otherwise split or expand it.
+func envQuote(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\n'\"\\$`#;&|<>()*?[]{}~!") {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// gitConfigQuote double-quotes value for a git config file.
+func gitConfigQuote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// writeWorktreeMapFile replaces the file at path with data. It writes a temporary file
+// first, so that tools reading the map never see half of it.
+func writeWorktreeMapFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, stateDirMode); err != nil {
+		return errors.DirectoryAccessFailed("create", dir, err)
+	}
+	tmp, err := os.CreateTemp(dir, ".wtp-map-*")
+	if err != nil {
+		return fmt.Errorf("failed to write the worktree map: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write the worktree map: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write the worktree map: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), worktreeMapFileMode); err != nil {
+		return fmt.Errorf("failed to write the worktree map: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write the worktree map: %w", err)
+	}
+	return nil
+}
+
+// syncWorktreeMapGitConfig sets wtp-worktree.<branch>.path for every branch in the map
+// and removes the sections of branches no longer checked out. It returns how many
+// branches the git config holds.
+func syncWorktreeMapGitConfig(executor command.Executor, m *worktreeMap) (int, error) {
+	existing, err := worktreeMapGitConfig(executor)
+	if err != nil {
+		return 0, err
+	}
+
+	var commands []command.Command
+	wanted := make(map[string]bool)
+	for _, entry := range m.Worktrees {
+		if entry.Branch == "" {
+			continue
+		}
+		wanted[entry.Branch] = true
+		if existing[entry.Branch] != entry.Path {
+			commands = append(commands, command.GitConfigSet(worktreeMapSection+"."+entry.Branch+".path", entry.Path))
+		}
+	}
+	for branch := range existing {
+		if !wanted[branch] {
+			commands = append(commands, command.GitConfigRemoveSection(worktreeMapSection+"."+branch))
+		}
+	}
+	if len(commands) == 0 {
+		return len(wanted), nil
+	}
+
+	result, err := executor.Execute(commands)
+	if err != nil {
+		return 0, errors.GitCommandFailed("git config", err.Error())
+	}
+	for _, r := range result.Results {
+		if r.Error != nil {
+			return 0, errors.GitCommandFailed("git "+strings.Join(r.Command.Args, " "), r.Output)
+		}
+	}
+	return len(wanted), nil
+}
+
+// worktreeMapGitConfig returns the branch-to-directory map the git config holds.
+func worktreeMapGitConfig(executor command.Executor) (map[string]string, error) {
+	pattern := `^` + strings.ReplaceAll(worktreeMapSection, "-", `\-`) + `\..*\.path$`
+	result, err := executor.Execute([]command.Command{command.GitConfigGetRegexp(pattern)})
+	if err != nil {
+		return nil, errors.GitCommandFailed("git config --get-regexp", err.Error())
+	}
+	paths := make(map[string]string)
+	if len(result.Results) == 0 || result.Results[0].Error != nil {
+		// git config exits with 1 when no key matches
+		return paths, nil
+	}
+	for _, line := range strings.Split(result.Results[0].Output, "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		branch, ok := strings.CutPrefix(key, worktreeMapSection+".")
+		if !ok {
+			continue
+		}
+		if branch, ok = strings.CutSuffix(branch, ".path"); ok && branch != "" {
+			paths[branch] = value
+		}
+	}
+	return paths, nil
+}
+
+// syncWorktreeMap rewrites the exports the 'map' config section asks for after a command
+// added, moved, or removed worktrees or switched a branch. Failures are reported as a
+// warning: the command itself succeeded.
+func syncWorktreeMap(w io.Writer, executor command.Executor, mainRepoPath string) error {
+	cfg, err := config.LoadConfig(mainRepoPath, "")
+	if err != nil || !cfg.Map.Enabled() {
+		return nil
+	}
+	if err := exportWorktreeMap(executor, cfg, mainRepoPath); err != nil {
+		return writeWarning(w, errors.CodeWarnWorktreeMapFailed, "Failed to update the worktree map: %v", err)
+	}
+	return nil
+}
+
+func exportWorktreeMap(executor command.Executor, cfg *config.Config, mainRepoPath string) error {
+	m, err := loadWorktreeMap(executor, cfg, mainRepoPath)
+	if err != nil {
+		return err
+	}
+	if cfg.Map.File != "" {
+		data, err := renderWorktreeMap(m, cfg.Map.FileFormat())
+		if err != nil {
+			return err
+		}
+		path := cfg.Map.File
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(mainRepoPath, path)
+		}
+		if err := writeWorktreeMapFile(path, data); err != nil {
+			return err
+		}
+	}
+	if cfg.Map.GitConfig {
+		if _, err := syncWorktreeMapGitConfig(executor, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
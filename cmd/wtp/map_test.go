@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func TestNewMapCommand(t *testing.T) {
+	cmd := NewMapCommand()
+
+	assert.Equal(t, "map", cmd.Name)
+	assert.NotEmpty(t, cmd.Usage)
+	assert.NotNil(t, cmd.Action)
+}
+
+func testWorktreeMap() *worktreeMap {
+	return &worktreeMap{
+		Repository: "/src/repo",
+		Worktrees: []worktreeMapEntry{
+			{Name: "@", Branch: "main", Path: "/src/repo", Main: true},
+			{Name: "feature/auth", Branch: "feature/auth", Path: "/src/worktrees/feature/auth"},
+			{Name: "spike", Path: "/src/worktrees/spike"},
+			{Name: "it's", Branch: "fix/it's", Path: "/src/worktrees/it's"},
+		},
+	}
+}
+
+func TestLoadWorktreeMap(t *testing.T) {
+	mainPath, worktreePath, listOutput := setupCheckoutTest(t)
+	listOutput += "worktree /elsewhere/spike\nHEAD 999999\ndetached\n\n"
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+
+	m, err := loadWorktreeMap(&mockCheckoutCommandExecutor{listOutput: listOutput}, cfg, mainPath)
+	require.NoError(t, err)
+	assert.Equal(t, mainPath, m.Repository)
+	require.Len(t, m.Worktrees, 3)
+	assert.Equal(t, worktreeMapEntry{Name: "@", Branch: "main", Path: mainPath, Main: true}, m.Worktrees[0])
+	assert.Equal(t, worktreeMapEntry{Name: "feature/foo", Branch: "feature/foo", Path: worktreePath}, m.Worktrees[1])
+	assert.Empty(t, m.Worktrees[2].Branch)
+}
+
+func TestRenderWorktreeMap(t *testing.T) {
+	m := testWorktreeMap()
+
+	data, err := renderWorktreeMap(m, config.MapFormatJSON)
+	require.NoError(t, err)
+	var decoded worktreeMap
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, *m, decoded)
+	assert.NotContains(t, string(data), `"branch": ""`)
+
+	data, err = renderWorktreeMap(m, config.MapFormatEnv)
+	require.NoError(t, err)
+	assert.Equal(t, "WTP_REPO_ROOT=/src/repo\n"+
+		"WTP_WORKTREE_MAIN=/src/repo\n"+
+		"WTP_WORKTREE_FEATURE_AUTH=/src/worktrees/feature/auth\n"+
+		`WTP_WORKTREE_FIX_IT_S='/src/worktrees/it'\''s'`+"\n", string(data))
+
+	data, err = renderWorktreeMap(m, config.MapFormatGitConfig)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "[wtp-worktree \"feature/auth\"]\n\tpath = \"/src/worktrees/feature/auth\"\n")
+	assert.NotContains(t, string(data), "spike")
+}
+
+func TestWriteWorktreeMapFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".wtp", "map.json")
+
+	require.NoError(t, writeWorktreeMapFile(path, []byte("first\n")))
+	require.NoError(t, writeWorktreeMapFile(path, []byte("second\n")))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "second\n", string(data))
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary files should be gone")
+}
+
+func TestSyncWorktreeMapGitConfig(t *testing.T) {
+	repo := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", "-q", repo).Run())
+	t.Chdir(repo)
+	executor := command.NewRealExecutor()
+
+	gitConfig := func() map[string]string {
+		paths, err := worktreeMapGitConfig(executor)
+		require.NoError(t, err)
+		return paths
+	}
+	assert.Empty(t, gitConfig())
+
+	m := testWorktreeMap()
+	branches, err := syncWorktreeMapGitConfig(executor, m)
+	require.NoError(t, err)
+	assert.Equal(t, 3, branches)
+	assert.Equal(t, map[string]string{
+		"main":         "/src/repo",
+		"feature/auth": "/src/worktrees/feature/auth",
+		"fix/it's":     "/src/worktrees/it's",
+	}, gitConfig())
+
+	m.Worktrees = m.Worktrees[:1]
+	m.Worktrees[0].Path = "/moved/repo"
+	_, err = syncWorktreeMapGitConfig(executor, m)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"main": "/moved/repo"}, gitConfig())
+}
+
+func TestSyncWorktreeMap(t *testing.T) {
+	mainPath, _, listOutput := setupCheckoutTest(t)
+	mockExec := &mockCheckoutCommandExecutor{listOutput: listOutput}
+	var buf bytes.Buffer
+
+	// Without a 'map' section nothing is written
+	require.NoError(t, syncWorktreeMap(&buf, mockExec, mainPath))
+	assert.NoFileExists(t, filepath.Join(mainPath, ".wtp", "worktrees.env"))
+
+	require.NoError(t, os.WriteFile(filepath.Join(mainPath, config.ConfigFileName),
+		[]byte("map:\n  file: .wtp/worktrees.env\n  format: env\n"), 0o644))
+	require.NoError(t, syncWorktreeMap(&buf, mockExec, mainPath))
+	data, err := os.ReadFile(filepath.Join(mainPath, ".wtp", "worktrees.env"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "WTP_WORKTREE_FEATURE_FOO=")
+	assert.Empty(t, buf.String())
+
+	// A map that cannot be written is a warning
+	require.NoError(t, os.WriteFile(filepath.Join(mainPath, "blocker"), nil, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(mainPath, config.ConfigFileName),
+		[]byte("map:\n  file: blocker/map.json\n"), 0o644))
+	require.NoError(t, syncWorktreeMap(&buf, mockExec, mainPath))
+	assert.Contains(t, buf.String(), "WTP7012")
+}
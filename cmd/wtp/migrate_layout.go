@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+// layoutMigrationOptions are the flags of 'wtp migrate-layout'.
+type layoutMigrationOptions struct {
+	dryRun bool
+	// all also moves worktrees that wtp did not create and that are not under a base_dir.
+	all bool
+}
+
+// NewMigrateLayoutCommand creates the migrate-layout command definition
+func NewMigrateLayoutCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "migrate-layout",
+		Usage:     "Move existing worktrees to the paths the configuration now yields",
+		UsageText: "wtp migrate-layout [--dry-run] [--all]",
+		Description: "After base_dir, worktree_dir, or the slug settings changed, computes the new path " +
+			"of every worktree wtp created, prints the moves, and performs them with 'git worktree " +
+			"move'. The moves are checked up front and undone together when one fails, so worktrees " +
+			"end up either all in the new layout or all where they were.\n\n" +
+			"Afterwards, symbolic links in the moved worktrees that pointed into a moved worktree, or " +
+			"relative ones that pointed out of it, are re-pointed, emptied directories of the old " +
+			"layout are removed, and the worktree map is updated.\n\n" +
+			"Examples:\n" +
+			"  wtp migrate-layout --dry-run   # Preview the moves\n" +
+			"  wtp migrate-layout             # Move the worktrees\n" +
+			"  wtp migrate-layout --all       # Also move worktrees wtp did not create",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Show the moves without performing them",
+			},
+			&cli.BoolFlag{
+				Name:  "all",
+				Usage: "Also move worktrees that wtp did not create and that are outside every base_dir",
+			},
+		},
+		Action: migrateLayoutCommand,
+	}
+}
+
+func migrateLayoutCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	_, cfg, mainRepoPath, err := setupRepoAndConfig()
+	if err != nil {
+		return err
+	}
+	opts := layoutMigrationOptions{dryRun: cmd.Bool("dry-run"), all: cmd.Bool("all")}
+	if !opts.dryRun {
+		if err := ensureWritable(cfg, "move worktrees"); err != nil {
+			return err
+		}
+	}
+
+	executor := command.NewRealExecutor()
+	return migrateLayoutWithCommandExecutor(w, executor, cfg, mainRepoPath, opts)
+}
+
+func migrateLayoutWithCommandExecutor(
+	w io.Writer, executor command.Executor, cfg *config.Config, mainRepoPath string, opts layoutMigrationOptions,
+) error {
+	result, err := executor.Execute([]command.Command{command.GitWorktreeList()})
+	if err != nil {
+		return errors.GitCommandFailed("git worktree list", err.Error())
+	}
+	worktrees := parseWorktreesFromOutput(result.Results[0].Output)
+	relocations := planWorktreeRelocations(worktrees, cfg, mainRepoPath, opts.all)
+	if len(relocations) == 0 {
+		_, err := fmt.Fprintln(w, "All worktrees are at their configured paths")
+		return err
+	}
+
+	if _, err := fmt.Fprintln(w, "Worktrees to move:"); err != nil {
+		return err
+	}
+	for _, r := range relocations {
+		if _, err := fmt.Fprintf(w, "  %s: %s → %s\n", r.name, r.from, r.to); err != nil {
+			return err
+		}
+	}
+	if err := checkLayoutMigration(w, cfg, mainRepoPath, relocations); err != nil {
+		return err
+	}
+	if opts.dryRun {
+		_, err := fmt.Fprintln(w, "Run 'wtp migrate-layout' without --dry-run to move them.")
+		return err
+	}
+
+	if err := moveWorktreesTogether(w, executor, relocations); err != nil {
+		return err
+	}
+	for _, r := range relocations {
+		_ = moveRegisteredWorktree(mainRepoPath, r.from, r.to)
+	}
+	if err := repointWorktreeSymlinks(w, worktrees, relocations); err != nil {
+		return err
+	}
+	for _, r := range relocations {
+		removeEmptyParents(r.from)
+	}
+	if err := syncWorktreeMap(w, executor, mainRepoPath); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "✓ Moved %d worktree(s) to the new layout\n", len(relocations))
+	return err
+}
+
+// checkLayoutMigration verifies before anything moves that every target path is free,
+// that no two worktrees share one, and that defaults.nested_worktrees allows it.
+func checkLayoutMigration(
+	w io.Writer, cfg *config.Config, mainRepoPath string, relocations []worktreeRelocation,
+) error {
+	claimed := make(map[string]string, len(relocations))
+	for _, r := range relocations {
+		if other, ok := claimed[r.to]; ok {
+			return fmt.Errorf("%s and %s would both move to %s; make their paths differ with "+
+				"worktree_dir or defaults.slug", other, r.name, r.to)
+		}
+		claimed[r.to] = r.name
+		if _, err := os.Lstat(r.to); err == nil {
+			return fmt.Errorf("cannot move %s: %s already exists; nothing was moved", r.name, r.to)
+		}
+		if err := checkNestedWorktree(w, cfg, mainRepoPath, r.to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveWorktreesTogether moves every worktree in relocations. When a move fails, the
+// worktrees already moved are moved back, in reverse order, before the error is returned.
+func moveWorktreesTogether(w io.Writer, executor command.Executor, relocations []worktreeRelocation) error {
+	for i, r := range relocations {
+		err := moveWorktree(executor, r)
+		if err == nil {
+			if _, err := fmt.Fprintf(w, "Moved %s\n", r.name); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var stranded []string
+		for j := i - 1; j >= 0; j-- {
+			back := worktreeRelocation{name: relocations[j].name, from: relocations[j].to, to: relocations[j].from}
+			if moveErr := moveWorktree(executor, back); moveErr != nil {
+				stranded = append(stranded, back.name)
+				continue
+			}
+			removeEmptyParents(back.from)
+		}
+		return errors.LayoutMigrationFailed(r.name, err, stranded)
+	}
+	return nil
+}
+
+// repointWorktreeSymlinks rewrites the symbolic links in linked worktrees that the moves
+// broke: links into a moved worktree, and relative links out of a worktree that moved.
+// Links that cannot be rewritten are reported with a warning.
+func repointWorktreeSymlinks(w io.Writer, worktrees []git.Worktree, relocations []worktreeRelocation) error {
+	for i := range worktrees {
+		if worktrees[i].IsMain {
+			continue
+		}
+		root := worktrees[i].Path
+		oldRoot := root
+		for _, r := range relocations {
+			if sameDirectory(r.from, root) {
+				root = r.to
+			}
+		}
+
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() && entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			if entry.Type()&fs.ModeSymlink == 0 {
+				return nil
+			}
+			oldPath := filepath.Join(oldRoot, relativePath(root, path))
+			repointed, err := repointSymlink(path, oldPath, relocations)
+			if err != nil || !repointed {
+				return err
+			}
+			_, err = fmt.Fprintf(w, "  Re-pointed %s\n", path)
+			return err
+		})
+		if err != nil {
+			if warnErr := writeWarning(w, errors.CodeWarnSymlinkRepointFailed,
+				"could not re-point the symbolic links in %s: %v", root, err); warnErr != nil {
+				return warnErr
+			}
+		}
+	}
+	return nil
+}
+
+// repointSymlink rewrites the link at path, which was at oldPath before the moves, so that
+// it points where it did before, following moved worktrees. Relative links stay relative.
+func repointSymlink(path, oldPath string, relocations []worktreeRelocation) (bool, error) {
+	target, err := os.Readlink(path)
+	if err != nil {
+		return false, err
+	}
+	resolved := target
+	if !filepath.IsAbs(target) {
+		resolved = filepath.Join(filepath.Dir(oldPath), target)
+	}
+	for _, r := range relocations {
+		if isWithinBaseDir(resolved, r.from) {
+			resolved = filepath.Join(r.to, relativePath(r.from, resolved))
+			break
+		}
+	}
+
+	newTarget := filepath.Clean(resolved)
+	if !filepath.IsAbs(target) {
+		if newTarget, err = filepath.Rel(filepath.Dir(path), resolved); err != nil {
+			return false, err
+		}
+	}
+	if newTarget == filepath.Clean(target) {
+		return false, nil
+	}
+	if err := os.Remove(path); err != nil {
+		return false, err
+	}
+	return true, os.Symlink(newTarget, path)
+}
+
+// mustRel returns target relative to base, which contains it.
+func relativePath(base, target string) string {
+	rel, err := filepath.Rel(base, target)
+	if err != nil {
+		return "."
+	}
+	return rel
+}
+
+// removeEmptyParents removes the directories above a moved worktree's old path that the
+// move left empty, such as ../worktrees/feature after feature/a moved.
+func removeEmptyParents(path string) {
+	for dir := filepath.Dir(path); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			return
+		}
+	}
+}
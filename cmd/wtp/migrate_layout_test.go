@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+)
+
+// setupLayoutMigrationTest creates a repository at <root>/repo with worktrees for
+// feature/a and fix/b under <root>/worktrees, and changes into it.
+func setupLayoutMigrationTest(t *testing.T) (root, mainPath string) {
+	t.Helper()
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	mainPath = filepath.Join(root, "repo")
+	if err := exec.Command("git", "init", "-q", mainPath).Run(); err != nil {
+		t.Skip("git not available")
+	}
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@example.com"},
+			args...)...)
+		cmd.Dir = mainPath
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("commit", "-q", "--allow-empty", "-m", "init")
+	git("worktree", "add", "-q", "-b", "feature/a", filepath.Join(root, "worktrees", "feature", "a"))
+	git("worktree", "add", "-q", "-b", "fix/b", filepath.Join(root, "worktrees", "fix", "b"))
+	t.Chdir(mainPath)
+	return root, mainPath
+}
+
+func TestNewMigrateLayoutCommand(t *testing.T) {
+	cmd := NewMigrateLayoutCommand()
+
+	assert.Equal(t, "migrate-layout", cmd.Name)
+	assert.NotEmpty(t, cmd.Usage)
+	assert.NotNil(t, cmd.Action)
+}
+
+func TestMigrateLayout(t *testing.T) {
+	root, mainPath := setupLayoutMigrationTest(t)
+	oldA, oldB := filepath.Join(root, "worktrees", "feature", "a"), filepath.Join(root, "worktrees", "fix", "b")
+	newA, newB := filepath.Join(root, "worktrees", "feature-a"), filepath.Join(root, "worktrees", "fix-b")
+	require.NoError(t, os.WriteFile(filepath.Join(oldA, "data.txt"), []byte("a\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(mainPath, ".env"), []byte("X=1\n"), 0o644))
+	// Into another worktree, relative out of the worktree, and relative within it
+	require.NoError(t, os.Symlink(filepath.Join(oldA, "data.txt"), filepath.Join(oldB, "a-data")))
+	require.NoError(t, os.Symlink("../../../repo/.env", filepath.Join(oldB, ".env")))
+	require.NoError(t, os.Symlink("a-data", filepath.Join(oldB, "alias")))
+
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees", WorktreeDir: "${BRANCH_SLUG}"}}
+	executor := command.NewRealExecutor()
+
+	var buf bytes.Buffer
+	opts := layoutMigrationOptions{dryRun: true}
+	require.NoError(t, migrateLayoutWithCommandExecutor(&buf, executor, cfg, mainPath, opts))
+	assert.Contains(t, buf.String(), "feature/a: "+oldA+" → "+newA)
+	assert.Contains(t, buf.String(), "fix/b: "+oldB+" → "+newB)
+	assert.DirExists(t, oldA)
+
+	buf.Reset()
+	opts.dryRun = false
+	require.NoError(t, migrateLayoutWithCommandExecutor(&buf, executor, cfg, mainPath, opts))
+	assert.Contains(t, buf.String(), "✓ Moved 2 worktree(s) to the new layout")
+	assert.NoDirExists(t, filepath.Join(root, "worktrees", "feature"), "emptied directories are removed")
+	assert.NoDirExists(t, filepath.Join(root, "worktrees", "fix"))
+
+	target, err := os.Readlink(filepath.Join(newB, "a-data"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(newA, "data.txt"), target)
+	target, err = os.Readlink(filepath.Join(newB, ".env"))
+	require.NoError(t, err)
+	assert.Equal(t, "../../repo/.env", target)
+	target, err = os.Readlink(filepath.Join(newB, "alias"))
+	require.NoError(t, err)
+	assert.Equal(t, "a-data", target)
+	content, err := os.ReadFile(filepath.Join(newB, "alias"))
+	require.NoError(t, err)
+	assert.Equal(t, "a\n", string(content))
+
+	buf.Reset()
+	require.NoError(t, migrateLayoutWithCommandExecutor(&buf, executor, cfg, mainPath, opts))
+	assert.Equal(t, "All worktrees are at their configured paths\n", buf.String())
+}
+
+func TestMigrateLayout_RefusesBeforeMoving(t *testing.T) {
+	root, mainPath := setupLayoutMigrationTest(t)
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees", WorktreeDir: "${BRANCH_SLUG}"}}
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "worktrees", "fix-b"), 0o755))
+
+	var buf bytes.Buffer
+	err := migrateLayoutWithCommandExecutor(&buf, command.NewRealExecutor(), cfg, mainPath, layoutMigrationOptions{})
+	assert.ErrorContains(t, err, "cannot move fix/b: "+filepath.Join(root, "worktrees", "fix-b")+" already exists")
+	assert.DirExists(t, filepath.Join(root, "worktrees", "feature", "a"), "nothing is moved")
+
+	cfg.Defaults.WorktreeDir = "shared"
+	err = migrateLayoutWithCommandExecutor(&buf, command.NewRealExecutor(), cfg, mainPath, layoutMigrationOptions{})
+	assert.ErrorContains(t, err, "feature/a and fix/b would both move to")
+}
+
+// failingMoveExecutor runs commands for real, except that moving the worktree at failFrom fails.
+type failingMoveExecutor struct {
+	command.Executor
+	failFrom string
+}
+
+func (e *failingMoveExecutor) Execute(commands []command.Command) (*command.ExecutionResult, error) {
+	for _, cmd := range commands {
+		if len(cmd.Args) > 2 && cmd.Args[1] == "move" && cmd.Args[2] == e.failFrom {
+			return &command.ExecutionResult{Results: []command.Result{
+				{Command: cmd, Output: "fatal: cannot move a locked working tree", Error: assert.AnError},
+			}}, nil
+		}
+	}
+	return e.Executor.Execute(commands)
+}
+
+func TestMigrateLayout_RollsBack(t *testing.T) {
+	root, mainPath := setupLayoutMigrationTest(t)
+	oldA, oldB := filepath.Join(root, "worktrees", "feature", "a"), filepath.Join(root, "worktrees", "fix", "b")
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees", WorktreeDir: "${BRANCH_SLUG}"}}
+	executor := &failingMoveExecutor{Executor: command.NewRealExecutor(), failFrom: oldB}
+
+	var buf bytes.Buffer
+	err := migrateLayoutWithCommandExecutor(&buf, executor, cfg, mainPath, layoutMigrationOptions{})
+	code, _ := errors.CodeOf(err)
+	assert.Equal(t, errors.CodeLayoutMigrationFailed, code)
+	assert.ErrorContains(t, err, "failed to move fix/b to the new layout")
+	assert.ErrorContains(t, err, "nothing changed")
+	assert.DirExists(t, oldA, "feature/a is moved back")
+	assert.DirExists(t, oldB)
+	assert.NoDirExists(t, filepath.Join(root, "worktrees", "feature-a"))
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/git"
+	"github.com/satococoa/wtp/v2/internal/runtimedir"
+)
+
+// Variable to allow mocking in tests
+var moveGetwd = os.Getwd
+
+// NewMoveCommand creates the move command definition
+func NewMoveCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "move",
+		Usage:     "Move a worktree to another path",
+		UsageText: "wtp move <worktree-name> <new-path>",
+		Description: "Moves a worktree with 'git worktree move' and updates what wtp keeps about it: the " +
+			"state registry, the runtime directory, and the worktree map. Symbolic links that pointed " +
+			"into the worktree, such as those of symlink hooks using from_worktree, and relative links " +
+			"in it that pointed out of it are re-pointed.\n\n" +
+			"Examples:\n" +
+			"  wtp move feature/auth ../review/auth   # Move the feature/auth worktree",
+		ArgsUsage:     "<worktree-name> <new-path>",
+		ShellComplete: completeWorktreesForCd,
+		Action:        moveCommand,
+	}
+}
+
+func moveCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	if cmd.Args().Len() != 2 { //nolint:mnd // worktree name and path
+		return errors.InvalidArguments(`worktree name and new path are required
+
+Usage: wtp move <worktree-name> <new-path>
+
+Tip: Run 'wtp list' to see available worktrees`)
+	}
+
+	_, cfg, _, err := setupRepoAndConfig()
+	if err != nil {
+		return err
+	}
+	if err := ensureWritable(cfg, "move worktrees"); err != nil {
+		return err
+	}
+	cwd, err := moveGetwd()
+	if err != nil {
+		return errors.DirectoryAccessFailed("access current", ".", err)
+	}
+
+	executor := command.NewRealExecutor()
+	return moveCommandWithCommandExecutor(w, executor, cfg, cwd, cmd.Args().Get(0), cmd.Args().Get(1))
+}
+
+func moveCommandWithCommandExecutor(
+	w io.Writer, executor command.Executor, cfg *config.Config, cwd, worktreeName, newPath string,
+) error {
+	worktrees, target, err := resolveWorktreeToChange(executor, cfg, worktreeName, "move")
+	if err != nil {
+		return err
+	}
+	mainWorktreePath := findMainWorktreePath(worktrees)
+
+	if !filepath.IsAbs(newPath) {
+		newPath = filepath.Join(cwd, newPath)
+	}
+	r := worktreeRelocation{name: worktreeName, from: target.Path, to: filepath.Clean(newPath)}
+	if sameDirectory(r.from, r.to) {
+		return fmt.Errorf("worktree '%s' is already at %s", worktreeName, target.Path)
+	}
+	if err := checkWorktreeMove(w, cfg, mainWorktreePath, r); err != nil {
+		return err
+	}
+	if err := relocateWorktree(w, executor, cfg, mainWorktreePath, worktrees, r); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "Moved worktree '%s': %s → %s\n", worktreeName, r.from, r.to); err != nil {
+		return err
+	}
+	if err := syncWorktreeMap(w, executor, mainWorktreePath); err != nil {
+		return err
+	}
+	if isPathWithin(r.from, cwd) {
+		_, err := fmt.Fprintf(w, "Your shell is still in the old path; run 'cd %s'\n", r.to)
+		return err
+	}
+	return nil
+}
+
+// resolveWorktreeToChange lists the worktrees and resolves worktreeName among them, asking
+// to confirm a loose fuzzy match (see resolveWorktreeNameToChange). The main
+// worktree cannot be moved or renamed through wtp, so it is refused.
+func resolveWorktreeToChange(
+	executor command.Executor, cfg *config.Config, worktreeName, action string,
+) (worktrees []git.Worktree, target *git.Worktree, err error) {
+	result, err := executor.Execute([]command.Command{command.GitWorktreeList()})
+	if err != nil {
+		return nil, nil, errors.GitCommandFailed("git worktree list", err.Error())
+	}
+	worktrees = parseWorktreesFromOutput(result.Results[0].Output)
+	mainWorktreePath := findMainWorktreePath(worktrees)
+
+	target, err = resolveWorktreeNameToChange(worktreeName, worktrees, cfg, mainWorktreePath, action)
+	if err != nil {
+		return nil, nil, err
+	}
+	if target == nil {
+		return nil, nil, errors.WorktreeNotFound(worktreeName, managedWorktreeNames(worktrees, cfg, mainWorktreePath))
+	}
+	if target.IsMain {
+		return nil, nil, errors.MainWorktreeUnsupported(action, "it is the repository itself at "+target.Path)
+	}
+	return worktrees, target, nil
+}
+
+// checkWorktreeMove verifies that the worktree in r can move: nothing exists at its new
+// path, which is not inside the worktree itself, and defaults.nested_worktrees allows it.
+func checkWorktreeMove(w io.Writer, cfg *config.Config, mainRepoPath string, r worktreeRelocation) error {
+	if _, err := os.Lstat(r.to); err == nil {
+		return fmt.Errorf("cannot move %s: %s already exists", r.name, r.to)
+	}
+	if isPathWithin(r.from, r.to) {
+		return fmt.Errorf("cannot move %s into itself: %s is inside %s", r.name, r.to, r.from)
+	}
+	return checkWorktreeLocation(w, cfg, mainRepoPath, r.to)
+}
+
+// relocateWorktree moves one worktree with 'git worktree move' and carries along what wtp
+// keeps about it: its entry in the state registry, its runtime directory when
+// defaults.runtime_dir keeps that outside the worktree, and the symbolic links of
+// worktrees that pointed into it. Directories the move left empty are removed.
+func relocateWorktree(
+	w io.Writer, executor command.Executor, cfg *config.Config, mainRepoPath string,
+	worktrees []git.Worktree, r worktreeRelocation,
+) error {
+	oldRuntimeDir, runtimeDirErr := runtimedir.Dir(cfg, r.from)
+	if err := moveWorktree(executor, r); err != nil {
+		return fmt.Errorf("could not move %s: %w", r.name, err)
+	}
+	if runtimeDirErr == nil {
+		moveRuntimeDir(cfg, oldRuntimeDir, r.to)
+	}
+	_ = moveRegisteredWorktree(mainRepoPath, r.from, r.to)
+	if err := repointWorktreeSymlinks(w, worktrees, []worktreeRelocation{r}); err != nil {
+		return err
+	}
+	removeEmptyParents(r.from)
+	return nil
+}
+
+// moveRuntimeDir renames the runtime directory of a worktree that moved to path when its
+// location depends on the worktree's path, as it does under defaults.runtime_dir. One
+// inside the worktree has already moved with it.
+func moveRuntimeDir(cfg *config.Config, oldDir, path string) {
+	newDir, err := runtimedir.Dir(cfg, path)
+	if err != nil || newDir == oldDir {
+		return
+	}
+	if _, err := os.Stat(oldDir); err != nil {
+		return
+	}
+	if _, err := os.Lstat(newDir); err == nil {
+		return
+	}
+	_ = os.Rename(oldDir, newDir)
+}
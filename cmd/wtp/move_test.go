@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/runtimedir"
+	"github.com/satococoa/wtp/v2/internal/state"
+)
+
+func TestNewMoveCommand(t *testing.T) {
+	cmd := NewMoveCommand()
+
+	assert.Equal(t, "move", cmd.Name)
+	assert.NotEmpty(t, cmd.Usage)
+	assert.NotNil(t, cmd.Action)
+}
+
+func TestMoveCommand(t *testing.T) {
+	root, mainPath := setupLayoutMigrationTest(t)
+	oldA, b := filepath.Join(root, "worktrees", "feature", "a"), filepath.Join(root, "worktrees", "fix", "b")
+	newA := filepath.Join(root, "review", "a")
+	require.NoError(t, os.WriteFile(filepath.Join(oldA, "data.txt"), []byte("a\n"), 0o644))
+	// Like a symlink hook with from_worktree: feature/a
+	require.NoError(t, os.Symlink(filepath.Join(oldA, "data.txt"), filepath.Join(b, "a-data")))
+	registryPath, err := state.Path(mainPath)
+	require.NoError(t, err)
+	require.NoError(t, state.Update(registryPath, func(r *state.Registry) {
+		r.Add(&state.Worktree{Path: oldA, Branch: "feature/a"})
+	}))
+
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+	var buf bytes.Buffer
+	err = moveCommandWithCommandExecutor(&buf, command.NewRealExecutor(), cfg, root, "feature/a", "review/a")
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "Moved worktree 'feature/a': "+oldA+" → "+newA)
+	assert.NotContains(t, buf.String(), "Your shell is still in the old path")
+	assert.FileExists(t, filepath.Join(newA, "data.txt"))
+	assert.NoDirExists(t, filepath.Join(root, "worktrees", "feature"), "emptied directories are removed")
+
+	out, err := exec.Command("git", "-C", mainPath, "worktree", "list").Output()
+	require.NoError(t, err)
+	assert.Contains(t, string(out), newA)
+	target, err := os.Readlink(filepath.Join(b, "a-data"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(newA, "data.txt"), target)
+	assert.True(t, isWorktreeRegistered(mainPath, newA))
+}
+
+func TestMoveCommand_RuntimeDir(t *testing.T) {
+	root, mainPath := setupLayoutMigrationTest(t)
+	oldA := filepath.Join(root, "worktrees", "feature", "a")
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees", RuntimeDir: filepath.Join(root, "runtime")}}
+	require.NoError(t, saveProvisionRecord(cfg, oldA, &provisionRecord{Branch: "feature/a"}))
+
+	var buf bytes.Buffer
+	newA := filepath.Join(root, "moved")
+	require.NoError(t, moveCommandWithCommandExecutor(&buf, command.NewRealExecutor(), cfg, mainPath, "feature/a", newA))
+
+	record, err := loadProvisionRecord(cfg, newA)
+	require.NoError(t, err)
+	require.NotNil(t, record, "the runtime directory follows the worktree")
+	assert.Equal(t, "feature/a", record.Branch)
+	newDir, err := runtimedir.Dir(cfg, newA)
+	require.NoError(t, err)
+	entries, err := os.ReadDir(filepath.Dir(newDir))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "the old runtime directory is gone")
+}
+
+func TestMoveCommand_Refuses(t *testing.T) {
+	root, mainPath := setupLayoutMigrationTest(t)
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+	executor := command.NewRealExecutor()
+	b := filepath.Join(root, "worktrees", "fix", "b")
+
+	tests := []struct {
+		name     string
+		worktree string
+		path     string
+		want     string
+	}{
+		{name: "main worktree", worktree: "@", path: "elsewhere", want: "cannot move the main worktree"},
+		{name: "existing path", worktree: "feature/a", path: b, want: b + " already exists"},
+		{name: "into itself", worktree: "fix/b", path: filepath.Join(b, "sub"), want: "cannot move fix/b into itself"},
+		{name: "same path", worktree: "fix/b", path: b, want: "is already at"},
+		{name: "unknown worktree", worktree: "nope", path: "elsewhere", want: "nope"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := moveCommandWithCommandExecutor(&buf, executor, cfg, mainPath, tt.worktree, tt.path)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+	assert.DirExists(t, filepath.Join(root, "worktrees", "feature", "a"), "nothing is moved")
+}
+
+func TestMoveCommand_FromInside(t *testing.T) {
+	root, _ := setupLayoutMigrationTest(t)
+	oldA := filepath.Join(root, "worktrees", "feature", "a")
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+
+	var buf bytes.Buffer
+	newA := filepath.Join(root, "moved")
+	require.NoError(t, moveCommandWithCommandExecutor(&buf, command.NewRealExecutor(), cfg, oldA, "feature/a", newA))
+	assert.True(t, strings.HasSuffix(buf.String(), "Your shell is still in the old path; run 'cd "+newA+"'\n"))
+}
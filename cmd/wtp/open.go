@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+// Variable to allow mocking in tests
+var createWorktreeToOpen = runAddCommand
+
+// NewOpenCommand creates the open command definition
+func NewOpenCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "open",
+		Usage:     "Open a worktree in an editor",
+		UsageText: "wtp open [--editor <command>] [--create] <worktree-name>",
+		Description: "Opens a worktree in the editor given with --editor, else defaults.editor, else " +
+			"$VISUAL or $EDITOR. The editor is run as a command with the worktree's path appended, " +
+			"such as 'code', 'idea', or 'vim', and wtp waits for it to exit. With --create, a " +
+			"worktree that does not exist yet is first created for the branch of that name, as " +
+			"'wtp add <branch>' would.\n\n" +
+			"Examples:\n" +
+			"  wtp open feature/auth                  # In the configured editor\n" +
+			"  wtp open --editor idea feature/auth    # In IntelliJ IDEA\n" +
+			"  wtp open --create feature/new          # Create the worktree first if needed",
+		ArgsUsage: "<worktree-name>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "editor",
+				Aliases: []string{"e"},
+				Usage:   "Editor command to open the worktree with (default: defaults.editor, $VISUAL, or $EDITOR)",
+			},
+			&cli.BoolFlag{
+				Name:    "create",
+				Aliases: []string{"c"},
+				Usage:   "Create the worktree with 'wtp add' first when it does not exist",
+			},
+		},
+		ShellComplete: completeWorktreesForCd,
+		Action:        openCommand,
+	}
+}
+
+func openCommand(ctx context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	if cmd.Args().Len() != 1 {
+		return errors.InvalidArguments(`worktree name is required
+
+Usage: wtp open [--editor <command>] [--create] <worktree-name>
+
+Tip: Run 'wtp list' to see available worktrees`)
+	}
+
+	_, cfg, _, err := setupRepoAndConfig()
+	if err != nil {
+		return err
+	}
+
+	executor := command.NewRealExecutor()
+	path, created, err := openWorktreePath(ctx, w, executor, cfg, cmd.Args().First(), cmd.Bool("create"))
+	if err != nil {
+		return err
+	}
+	if created && cfg.Defaults.AfterAdd == config.AfterAddOpenEditor && !cmd.IsSet("editor") {
+		return nil // 'wtp add' has opened it already
+	}
+	editor := cmd.String("editor")
+	if editor == "" {
+		editor = cfg.Defaults.Editor
+	}
+	return openInEditor(editor, path)
+}
+
+// openWorktreePath resolves worktreeName like 'wtp cd' and returns the worktree's path.
+// With create, a worktree that no exact name matches is created for the branch of that
+// name instead of settling for a fuzzy match, and created is true.
+func openWorktreePath(
+	ctx context.Context, w io.Writer, executor command.Executor, cfg *config.Config, worktreeName string, create bool,
+) (path string, created bool, err error) {
+	target, worktrees, err := findWorktreeToOpen(executor, cfg, worktreeName, create)
+	if err != nil {
+		return "", false, err
+	}
+	if target != nil {
+		return target.Path, false, nil
+	}
+	if !create {
+		mainWorktreePath := findMainWorktreePath(worktrees)
+		return "", false, errors.WorktreeNotFound(worktreeName, managedWorktreeNames(worktrees, cfg, mainWorktreePath))
+	}
+
+	if err := createWorktreeToOpen(ctx, w, worktreeName); err != nil {
+		return "", false, err
+	}
+	target, _, err = findWorktreeToOpen(executor, cfg, worktreeName, true)
+	if err != nil {
+		return "", false, err
+	}
+	if target == nil {
+		return "", false, fmt.Errorf("created a worktree for %s but could not find it; run 'wtp list'", worktreeName)
+	}
+	return target.Path, true, nil
+}
+
+// findWorktreeToOpen lists the worktrees and resolves worktreeName among them, only by
+// exact names and IDs when exact is set; the worktree is nil when none matches.
+func findWorktreeToOpen(
+	executor command.Executor, cfg *config.Config, worktreeName string, exact bool,
+) (target *git.Worktree, worktrees []git.Worktree, err error) {
+	result, err := executor.Execute([]command.Command{command.GitWorktreeList()})
+	if err != nil {
+		return nil, nil, errors.GitCommandFailed("git worktree list", err.Error())
+	}
+	worktrees = parseWorktreesFromOutput(result.Results[0].Output)
+	resolve := resolveWorktreeName
+	if exact {
+		resolve = resolveExactWorktreeName
+	}
+	target, err = resolve(worktreeName, worktrees, cfg, findMainWorktreePath(worktrees))
+	if err != nil {
+		return nil, nil, err
+	}
+	return target, worktrees, nil
+}
+
+// runAddCommand creates the worktree of branch as 'wtp add <branch>' would.
+func runAddCommand(ctx context.Context, w io.Writer, branch string) error {
+	add := NewAddCommand()
+	add.Writer = w
+	return add.Run(ctx, []string{"add", branch})
+}
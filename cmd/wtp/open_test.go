@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+const openTestWorktrees = "worktree /repo\nHEAD abc\nbranch refs/heads/main\n\n" +
+	"worktree /worktrees/feature/auth\nHEAD def\nbranch refs/heads/feature/auth\n\n"
+
+// mockOpenCommandExecutor lists the worktrees of listOutputs in turn, the last one from
+// then on, as if worktrees were added in between.
+type mockOpenCommandExecutor struct {
+	listOutputs []string
+	lists       int
+}
+
+func (m *mockOpenCommandExecutor) Execute(commands []command.Command) (*command.ExecutionResult, error) {
+	results := make([]command.Result, len(commands))
+	for i, cmd := range commands {
+		results[i].Command = cmd
+		results[i].Output = m.listOutputs[min(m.lists, len(m.listOutputs)-1)]
+		m.lists++
+	}
+	return &command.ExecutionResult{Results: results}, nil
+}
+
+func mockCreateWorktreeToOpen(t *testing.T, err error) *[]string {
+	t.Helper()
+	original := createWorktreeToOpen
+	t.Cleanup(func() { createWorktreeToOpen = original })
+
+	var created []string
+	createWorktreeToOpen = func(_ context.Context, _ io.Writer, branch string) error {
+		created = append(created, branch)
+		return err
+	}
+	return &created
+}
+
+func TestOpenWorktreePath(t *testing.T) {
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+
+	t.Run("existing worktree", func(t *testing.T) {
+		created := mockCreateWorktreeToOpen(t, nil)
+		executor := &mockOpenCommandExecutor{listOutputs: []string{openTestWorktrees}}
+
+		path, wasCreated, err := openWorktreePath(context.Background(), io.Discard, executor, cfg, "auth", false)
+		require.NoError(t, err)
+		assert.Equal(t, "/worktrees/feature/auth", path, "names match fuzzily like 'wtp cd'")
+		assert.False(t, wasCreated)
+		assert.Empty(t, *created)
+	})
+
+	t.Run("missing worktree", func(t *testing.T) {
+		created := mockCreateWorktreeToOpen(t, nil)
+		executor := &mockOpenCommandExecutor{listOutputs: []string{openTestWorktrees}}
+
+		_, _, err := openWorktreePath(context.Background(), io.Discard, executor, cfg, "feature/new", false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "worktree 'feature/new' not found")
+		assert.Empty(t, *created)
+	})
+
+	t.Run("create", func(t *testing.T) {
+		created := mockCreateWorktreeToOpen(t, nil)
+		executor := &mockOpenCommandExecutor{listOutputs: []string{
+			openTestWorktrees,
+			openTestWorktrees + "worktree /worktrees/feature/au\nHEAD 123\nbranch refs/heads/feature/au\n\n",
+		}}
+
+		path, wasCreated, err := openWorktreePath(context.Background(), io.Discard, executor, cfg, "feature/au", true)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"feature/au"}, *created, "a fuzzy match does not stop the creation")
+		assert.Equal(t, "/worktrees/feature/au", path)
+		assert.True(t, wasCreated)
+	})
+
+	t.Run("create existing", func(t *testing.T) {
+		created := mockCreateWorktreeToOpen(t, nil)
+		executor := &mockOpenCommandExecutor{listOutputs: []string{openTestWorktrees}}
+
+		path, wasCreated, err := openWorktreePath(context.Background(), io.Discard, executor, cfg, "feature/auth", true)
+		require.NoError(t, err)
+		assert.Equal(t, "/worktrees/feature/auth", path)
+		assert.False(t, wasCreated)
+		assert.Empty(t, *created)
+	})
+
+	t.Run("create fails", func(t *testing.T) {
+		mockCreateWorktreeToOpen(t, fmt.Errorf("branch 'feature/new' not found"))
+		executor := &mockOpenCommandExecutor{listOutputs: []string{openTestWorktrees}}
+
+		_, _, err := openWorktreePath(context.Background(), io.Discard, executor, cfg, "feature/new", true)
+		assert.EqualError(t, err, "branch 'feature/new' not found")
+	})
+}
+
+func TestRunEditor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping sh test on Windows")
+	}
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "false")
+
+	require.NoError(t, runEditor("true --wait", "/wt"), "the configured editor wins over EDITOR")
+	err := runEditor("", "/wt")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "false failed")
+}
+
+func TestNewOpenCommand(t *testing.T) {
+	cmd := NewOpenCommand()
+
+	assert.Equal(t, "open", cmd.Name)
+	var names []string
+	for _, flag := range cmd.Flags {
+		names = append(names, flag.Names()[0])
+	}
+	assert.Equal(t, []string{"editor", "create"}, names)
+}
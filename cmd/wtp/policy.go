@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+// checkWorktreeLocation refuses a worktree path already holding a worktree of another
+// clone, then applies defaults.nested_worktrees to it.
+func checkWorktreeLocation(w io.Writer, cfg *config.Config, mainRepoPath, workTreePath string) error {
+	if owner, ok := git.WorktreeOwnerRepo(workTreePath); ok && !sameDirectory(owner, mainRepoPath) {
+		return errors.WorktreePathInOtherClone(workTreePath, owner)
+	}
+	return checkNestedWorktree(w, cfg, mainRepoPath, workTreePath)
+}
+
+// checkNestedWorktree applies defaults.nested_worktrees to a worktree about to be created
+// at workTreePath: a worktree inside another checkout is refused with "error", reported
+// with a warning by default, and let through with "allow".
+func checkNestedWorktree(w io.Writer, cfg *config.Config, mainRepoPath, workTreePath string) error {
+	if cfg.Defaults.NestedWorktrees == config.NestedWorktreesAllow {
+		return nil
+	}
+	where := nestedWorktreeHazard(mainRepoPath, workTreePath)
+	if where == "" {
+		return nil
+	}
+	if cfg.Defaults.NestedWorktrees == config.NestedWorktreesError {
+		return errors.NestedWorktree(workTreePath, where)
+	}
+	return writeWarning(w, errors.CodeWarnNestedWorktree,
+		"worktree path %s is %s; nested checkouts confuse git and tools that scan directories "+
+			"(set defaults.nested_worktrees to 'error' or 'allow')", workTreePath, where)
+}
+
+// nestedWorktreeHazard describes the checkout a worktree at workTreePath would be nested
+// in, such as "inside the main worktree /src/app", or returns "" when there is none.
+func nestedWorktreeHazard(mainRepoPath, workTreePath string) string {
+	container, ok := git.EnclosingCheckout(workTreePath)
+	if !ok {
+		return ""
+	}
+	owner, ok := git.WorktreeOwnerRepo(container)
+	switch {
+	case !ok || !sameDirectory(owner, mainRepoPath):
+		return "inside the git repository " + container
+	case sameDirectory(container, mainRepoPath):
+		return "inside the main worktree " + container
+	default:
+		return "inside the worktree " + container
+	}
+}
+
+// checkAddPolicy enforces the 'policy' section before 'wtp add' creates a worktree. Only
+// branches created with -b must carry a required prefix; existing branches were named
+// elsewhere.
+func checkAddPolicy(w io.Writer, cmd *cli.Command, executor command.Executor, cfg *config.Config) error {
+	policy := &cfg.Policy
+	if !policy.Enabled() {
+		return nil
+	}
+
+	if cmd.Bool("ignore-policy") {
+		if !policy.AllowOverride {
+			return fmt.Errorf("--ignore-policy is not permitted: set 'policy.allow_override: true' in .wtp.yml to allow it")
+		}
+		return writeWarning(w, errors.CodeWarnPolicySkipped, "skipping policy checks (--ignore-policy)")
+	}
+
+	if newBranch := cmd.String("branch"); newBranch != "" && !policy.HasBranchPrefix(newBranch) {
+		return errors.BranchPrefixRequired(newBranch, policy.RequiredBranchPrefixes, policy.AllowOverride)
+	}
+
+	if policy.MaxWorktreesPerRepo > 0 {
+		result, err := executor.Execute([]command.Command{command.GitWorktreeList()})
+		if err != nil {
+			return errors.GitCommandFailed("git worktree list", err.Error())
+		}
+		count := 0
+		for _, wt := range parseWorktreesFromOutput(result.Results[0].Output) {
+			if !wt.IsMain {
+				count++
+			}
+		}
+		if count >= policy.MaxWorktreesPerRepo {
+			return errors.WorktreeLimitReached(count, policy.MaxWorktreesPerRepo, policy.AllowOverride)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+)
+
+const policyTestWorktreeList = "worktree /repo\nHEAD abc123\nbranch refs/heads/main\n\n" +
+	"worktree /worktrees/feature/a\nHEAD def456\nbranch refs/heads/feature/a\n\n" +
+	"worktree /worktrees/feature/b\nHEAD 789abc\nbranch refs/heads/feature/b\n\n"
+
+func TestCheckAddPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      config.Policy
+		flags       map[string]any
+		args        []string
+		expectError string
+		expectList  bool
+	}{
+		{
+			name:  "no policy",
+			flags: map[string]any{"branch": "anything"},
+		},
+		{
+			name:   "new branch with required prefix",
+			policy: config.Policy{RequiredBranchPrefixes: []string{"feature/", "fix/"}},
+			flags:  map[string]any{"branch": "fix/login"},
+		},
+		{
+			name:        "new branch without required prefix",
+			policy:      config.Policy{RequiredBranchPrefixes: []string{"feature/", "fix/"}},
+			flags:       map[string]any{"branch": "login"},
+			expectError: "branch 'login' must start with one of: feature/, fix/",
+		},
+		{
+			name:   "existing branch is not held to prefixes",
+			policy: config.Policy{RequiredBranchPrefixes: []string{"feature/"}},
+			args:   []string{"release/1.0"},
+		},
+		{
+			name:       "below worktree limit",
+			policy:     config.Policy{MaxWorktreesPerRepo: 3},
+			flags:      map[string]any{"branch": "feature/c"},
+			expectList: true,
+		},
+		{
+			name:        "worktree limit reached",
+			policy:      config.Policy{MaxWorktreesPerRepo: 2},
+			flags:       map[string]any{"branch": "feature/c"},
+			expectError: "already has 2 of at most 2 worktrees",
+			expectList:  true,
+		},
+		{
+			name:   "override allowed",
+			policy: config.Policy{MaxWorktreesPerRepo: 1, AllowOverride: true},
+			flags:  map[string]any{"branch": "feature/c", "ignore-policy": true},
+		},
+		{
+			name:        "override not allowed",
+			policy:      config.Policy{MaxWorktreesPerRepo: 1},
+			flags:       map[string]any{"branch": "feature/c", "ignore-policy": true},
+			expectError: "--ignore-policy is not permitted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExec := &mockRemoveCommandExecutor{results: []command.Result{{Output: policyTestWorktreeList}}}
+			cfg := &config.Config{Policy: tt.policy}
+			cmd := createTestCLICommand(tt.flags, tt.args)
+			var buf bytes.Buffer
+
+			err := checkAddPolicy(&buf, cmd, mockExec, cfg)
+
+			if tt.expectError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+			} else {
+				assert.NoError(t, err)
+			}
+			if tt.expectList {
+				assert.Equal(t, []command.Command{command.GitWorktreeList()}, mockExec.executedCommands)
+			} else {
+				assert.Empty(t, mockExec.executedCommands)
+			}
+		})
+	}
+}
+
+func TestCheckAddPolicy_OverrideWarns(t *testing.T) {
+	cfg := &config.Config{Policy: config.Policy{MaxWorktreesPerRepo: 1, AllowOverride: true}}
+	cmd := createTestCLICommand(map[string]any{"branch": "feature/c", "ignore-policy": true}, nil)
+	var buf bytes.Buffer
+
+	assert.NoError(t, checkAddPolicy(&buf, cmd, &mockRemoveCommandExecutor{}, cfg))
+	assert.Contains(t, buf.String(), "skipping policy checks")
+}
+
+func TestCheckNestedWorktree(t *testing.T) {
+	root := t.TempDir()
+	mainPath := filepath.Join(root, "repo")
+	if err := exec.Command("git", "init", "-q", mainPath).Run(); err != nil {
+		t.Skip("git not available")
+	}
+	linked := filepath.Join(root, "worktrees", "feature", "a")
+	gitCommit := exec.Command("git", "-c", "user.name=t", "-c", "user.email=t@example.com",
+		"commit", "-q", "--allow-empty", "-m", "init")
+	gitCommit.Dir = mainPath
+	require.NoError(t, gitCommit.Run())
+	gitWorktreeAdd := exec.Command("git", "worktree", "add", "-q", "-b", "feature/a", linked)
+	gitWorktreeAdd.Dir = mainPath
+	require.NoError(t, gitWorktreeAdd.Run())
+	other := filepath.Join(root, "other")
+	require.NoError(t, os.MkdirAll(filepath.Join(other, ".git"), 0o755))
+
+	assert.Empty(t, nestedWorktreeHazard(mainPath, filepath.Join(root, "worktrees", "feature", "b")))
+	assert.Equal(t, "inside the main worktree "+mainPath,
+		nestedWorktreeHazard(mainPath, filepath.Join(mainPath, ".worktrees", "b")))
+	assert.Equal(t, "inside the worktree "+linked, nestedWorktreeHazard(mainPath, filepath.Join(linked, "sub")))
+	assert.Equal(t, "inside the git repository "+other, nestedWorktreeHazard(mainPath, filepath.Join(other, "b")))
+
+	nested := filepath.Join(mainPath, ".worktrees", "b")
+	var buf bytes.Buffer
+	cfg := &config.Config{}
+	require.NoError(t, checkNestedWorktree(&buf, cfg, mainPath, nested))
+	assert.Contains(t, buf.String(), "WTP7013")
+
+	buf.Reset()
+	cfg.Defaults.NestedWorktrees = config.NestedWorktreesAllow
+	require.NoError(t, checkNestedWorktree(&buf, cfg, mainPath, nested))
+	assert.Empty(t, buf.String())
+
+	cfg.Defaults.NestedWorktrees = config.NestedWorktreesError
+	code, _ := errors.CodeOf(checkNestedWorktree(&buf, cfg, mainPath, nested))
+	assert.Equal(t, errors.CodeNestedWorktree, code)
+	assert.NoError(t, checkNestedWorktree(&buf, cfg, mainPath, filepath.Join(root, "worktrees", "b")))
+}
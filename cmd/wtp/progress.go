@@ -0,0 +1,32 @@
+package main
+
+import (
+	"os"
+
+	"golang.org/x/term"
+
+	"github.com/satococoa/wtp/v2/internal/events"
+	"github.com/satococoa/wtp/v2/internal/progress"
+)
+
+// Variables to allow mocking in tests
+var (
+	progressIsTerminal = func() bool { return term.IsTerminal(int(os.Stderr.Fd())) }
+	progressWidth      = func() int {
+		width, _, err := term.GetSize(int(os.Stderr.Fd()))
+		if err != nil {
+			return 0
+		}
+		return width
+	}
+)
+
+// newProgressDisplay returns the display of running hooks on stderr, or nil when it would
+// get in the way: with --quiet, when stderr is not a terminal or a dumb one, and when
+// --json-events writes to stderr itself. NO_COLOR turns its colors off.
+func newProgressDisplay(quiet bool) *progress.Display {
+	if quiet || events.Writes(os.Stderr) || os.Getenv("TERM") == "dumb" || !progressIsTerminal() {
+		return nil
+	}
+	return progress.New(os.Stderr, os.Getenv("NO_COLOR") == "", progressWidth)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/satococoa/wtp/v2/internal/events"
+)
+
+func mockProgressIsTerminal(t *testing.T, terminal bool) {
+	t.Helper()
+	original := progressIsTerminal
+	progressIsTerminal = func() bool { return terminal }
+	t.Cleanup(func() { progressIsTerminal = original })
+}
+
+func TestNewProgressDisplay(t *testing.T) {
+	t.Setenv("TERM", "xterm-256color")
+
+	mockProgressIsTerminal(t, true)
+	assert.NotNil(t, newProgressDisplay(false))
+	assert.Nil(t, newProgressDisplay(true), "--quiet hides progress")
+
+	t.Setenv("NO_COLOR", "1")
+	assert.NotNil(t, newProgressDisplay(false), "NO_COLOR only turns colors off")
+
+	t.Setenv("TERM", "dumb")
+	assert.Nil(t, newProgressDisplay(false))
+}
+
+func TestNewProgressDisplay_NotTerminal(t *testing.T) {
+	t.Setenv("TERM", "xterm-256color")
+	mockProgressIsTerminal(t, false)
+	assert.Nil(t, newProgressDisplay(false))
+}
+
+func TestNewProgressDisplay_JSONEvents(t *testing.T) {
+	t.Setenv("TERM", "xterm-256color")
+	mockProgressIsTerminal(t, true)
+	t.Cleanup(func() { events.Enable(nil) })
+
+	events.Enable(os.Stderr)
+	assert.Nil(t, newProgressDisplay(false), "--json-events owns stderr")
+
+	events.Enable(io.Discard)
+	assert.NotNil(t, newProgressDisplay(false), "--json-events-file leaves stderr alone")
+}
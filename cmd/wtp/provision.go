@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/git"
+	"github.com/satococoa/wtp/v2/internal/hooks"
+	"github.com/satococoa/wtp/v2/internal/runtimedir"
+)
+
+const (
+	provisionRecordFileName = "wtp-provision.json"
+	provisionRecordFileMode = 0o644
+	provisionStatusOK       = "ok"
+	provisionStatusFailed   = "failed"
+)
+
+// provisionRecord describes how 'wtp add' set up a worktree. It is stored in the
+// worktree's runtime directory (see runtimedir), so it disappears together with the worktree.
+type provisionRecord struct {
+	CreatedAt time.Time `json:"created_at"`
+	Branch    string    `json:"branch"`
+	// Base is the ref the worktree was started from; empty when an existing branch was checked out.
+	Base string `json:"base,omitempty"`
+	// Profile is the 'wtp add --profile' whose hooks ran after the top-level ones.
+	Profile string `json:"profile,omitempty"`
+	// Variables are the extra hook variables, e.g. PR_NUMBER for 'wtp add --pr', so that
+	// rerun hooks see them too.
+	Variables map[string]string `json:"variables,omitempty"`
+	Hooks     []provisionHook   `json:"hooks,omitempty"`
+	// Error is the post_create failure, if any; hooks after the failing one did not run.
+	Error string `json:"error,omitempty"`
+}
+
+// provisionHook is the outcome of one post_create hook.
+type provisionHook struct {
+	Index int    `json:"index"` // 1-based post_create number
+	Type  string `json:"type"`
+	// Description is what the hook did, e.g. the command it ran.
+	Description string        `json:"description,omitempty"`
+	Status      string        `json:"status"`
+	StartedAt   time.Time     `json:"started_at"`
+	Duration    time.Duration `json:"duration_ns"`
+	// ExitCode is set for command hooks that exited with an error.
+	ExitCode *int   `json:"exit_code,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+func newProvisionRecord(
+	branch, base string, hookList []config.Hook, timings []hooks.HookTiming, hookErr error,
+) *provisionRecord {
+	record := &provisionRecord{
+		CreatedAt: time.Now().UTC(),
+		Branch:    branch,
+		Base:      base,
+	}
+	record.applyHookResults(hookList, timings, hookErr)
+	return record
+}
+
+// applyHookResults stores the outcome of the hooks in timings, replacing earlier results
+// of the same hooks, and records hookErr as the outcome of the whole run.
+func (r *provisionRecord) applyHookResults(hookList []config.Hook, timings []hooks.HookTiming, hookErr error) {
+	for _, timing := range timings {
+		result := provisionHook{
+			Index:     timing.Index,
+			Type:      timing.Type,
+			Status:    provisionStatusOK,
+			StartedAt: timing.StartedAt.UTC(),
+			Duration:  timing.Duration,
+		}
+		if timing.Index >= 1 && timing.Index <= len(hookList) {
+			result.Description = describeHook(&hookList[timing.Index-1])
+		}
+		if timing.Err != nil {
+			result.Status = provisionStatusFailed
+			result.Error = timing.Err.Error()
+			if code, ok := hooks.ExitCode(timing.Err); ok {
+				result.ExitCode = &code
+			}
+		}
+
+		replaced := false
+		for i := range r.Hooks {
+			if r.Hooks[i].Index == result.Index {
+				r.Hooks[i] = result
+				replaced = true
+			}
+		}
+		if !replaced {
+			r.Hooks = append(r.Hooks, result)
+		}
+	}
+	sort.Slice(r.Hooks, func(i, j int) bool { return r.Hooks[i].Index < r.Hooks[j].Index })
+
+	r.Error = ""
+	if hookErr != nil {
+		r.Error = hookErr.Error()
+	}
+}
+
+// saveProvisionRecord writes record to the runtime directory of the worktree at path. A
+// worktree without a git directory (e.g. in tests that do not run git) is skipped.
+func saveProvisionRecord(cfg *config.Config, path string, record *provisionRecord) error {
+	if _, err := git.WorktreeGitDir(path); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode provisioning record: %w", err)
+	}
+	if err := runtimedir.WriteFile(cfg, path, provisionRecordFileName, append(data, '\n'),
+		provisionRecordFileMode); err != nil {
+		return fmt.Errorf("failed to write provisioning record: %w", err)
+	}
+	return nil
+}
+
+// loadProvisionRecord reads the record 'wtp add' left for the worktree at path. It returns
+// nil when the worktree was not created by wtp.
+func loadProvisionRecord(cfg *config.Config, path string) (*provisionRecord, error) {
+	recordPath, err := runtimedir.Path(cfg, path, provisionRecordFileName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// #nosec G304 -- path is derived from the worktree's runtime directory
+	data, err := os.ReadFile(recordPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read provisioning record: %w", err)
+	}
+
+	var record provisionRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to parse provisioning record %s: %w", recordPath, err)
+	}
+	return &record, nil
+}
+
+// worktreeCreatedAt returns when the worktree at path was created: the provisioning
+// record's time, else the modification time of the 'commondir' file git writes once when
+// it adds a linked worktree. ok is false for the main worktree and unknown worktrees.
+func worktreeCreatedAt(path string, record *provisionRecord) (createdAt time.Time, ok bool) {
+	if record != nil {
+		return record.CreatedAt, true
+	}
+	gitDir, err := git.WorktreeGitDir(path)
+	if err != nil {
+		return time.Time{}, false
+	}
+	info, err := os.Stat(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return info.ModTime().UTC(), true
+}
+
+// sameDirectory reports whether a and b name the same directory once symlinks are resolved.
+func sameDirectory(a, b string) bool {
+	resolve := func(path string) string {
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			return resolved
+		}
+		return filepath.Clean(path)
+	}
+	return resolve(a) == resolve(b)
+}
@@ -0,0 +1,456 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v3"
+	"golang.org/x/term"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/events"
+	"github.com/satococoa/wtp/v2/internal/git"
+	"github.com/satococoa/wtp/v2/internal/hooks"
+	"github.com/satococoa/wtp/v2/internal/vcs"
+)
+
+const (
+	upstreamGoneTrack = "[gone]"
+	hoursPerDay       = 24
+)
+
+// Variables to allow mocking in tests
+var (
+	pruneInput      io.Reader = os.Stdin
+	pruneIsTerminal           = func() bool { return term.IsTerminal(int(os.Stdin.Fd())) }
+)
+
+// pruneCandidate is a worktree 'wtp prune' offers to remove, and why.
+type pruneCandidate struct {
+	worktree *git.Worktree
+	name     string
+	reason   string
+}
+
+// pruneBranches is what git knows about the local branches that decides whether their
+// worktrees are stale.
+type pruneBranches struct {
+	commits map[string]string
+	// gone maps branches whose upstream was deleted, e.g. after a merged pull request, to
+	// that upstream.
+	gone map[string]string
+	// merged holds the branches whose commits all reached target.
+	merged map[string]bool
+	target string
+}
+
+// NewPruneCommand creates the prune command definition
+func NewPruneCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "prune",
+		Usage:     "Remove worktrees whose branch is merged, deleted upstream, or untouched",
+		UsageText: "wtp prune [--dry-run] [--yes] [--force] [--fail-fast]",
+		Description: "Finds the worktrees under base_dir whose branch was merged into the default branch, " +
+			"whose upstream branch was deleted (run 'git fetch --prune' first), or which have not been " +
+			"touched for defaults.prune_after (e.g. \"30d\"). After showing them, asks for confirmation " +
+			"and removes them like 'wtp remove', running pre_remove and post_remove hooks, then runs the " +
+			"post_prune hooks once. The main worktree and the current one are never pruned, and " +
+			"locked worktrees ('wtp lock') only with --force.\n\n" +
+			"A worktree that cannot be removed does not stop the others unless --fail-fast is " +
+			"given; the result for each worktree is then shown, and wtp exits with status 3 when " +
+			"some were removed.\n\n" +
+			"Examples:\n" +
+			"  wtp prune --dry-run    # Show what would be removed\n" +
+			"  wtp prune              # Remove them after confirmation\n" +
+			"  wtp prune --yes        # Remove them without asking, e.g. from a script",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Show the worktrees that would be removed without removing them",
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Usage:   "Remove without asking for confirmation",
+				Aliases: []string{"y"},
+			},
+			&cli.BoolFlag{
+				Name:    "force",
+				Usage:   "Remove dirty and locked worktrees, and continue when a pre_remove hook fails",
+				Aliases: []string{"f"},
+			},
+			newFailFastFlag(),
+		},
+		Action: pruneCommand,
+	}
+}
+
+func pruneCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return errors.DirectoryAccessFailed("access current", ".", err)
+	}
+	_, cfg, mainRepoPath, err := setupRepoAndConfig()
+	if err != nil {
+		return err
+	}
+	dryRun := cmd.Bool("dry-run")
+	if !dryRun {
+		if err := ensureWritable(cfg, "prune worktrees"); err != nil {
+			return err
+		}
+		release, err := acquireRepoLock(w, cmd, cfg, mainRepoPath, "wtp prune")
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+
+	executor := command.NewRealExecutor()
+	candidates, err := findPruneCandidates(executor, cfg, mainRepoPath, cwd, time.Now())
+	if err != nil {
+		return err
+	}
+	opts := pruneOptions{yes: cmd.Bool("yes"), force: cmd.Bool("force"), failFast: cmd.Bool("fail-fast")}
+	candidates, err = skipLockedCandidates(w, candidates, opts.force)
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 || dryRun {
+		return writePruneSummary(w, candidates, dryRun)
+	}
+	pruned, err := pruneWorktrees(w, executor, cwd, candidates, opts)
+	if hookErr := executePostPruneHooks(w, cfg, mainRepoPath, pruned); hookErr != nil {
+		return hookErr
+	}
+	return err
+}
+
+// findPruneCandidates returns the managed worktrees whose branch is gone upstream or merged,
+// or which were not touched for prune_after, in 'git worktree list' order.
+func findPruneCandidates(
+	executor command.Executor, cfg *config.Config, mainRepoPath, cwd string, now time.Time,
+) ([]pruneCandidate, error) {
+	result, err := executor.Execute([]command.Command{command.GitWorktreeList()})
+	if err != nil {
+		return nil, errors.GitCommandFailed("git worktree list", err.Error())
+	}
+	worktrees := parseWorktreesFromOutput(result.Results[0].Output)
+	branches := loadPruneBranches(executor, cfg, mainRepoPath)
+
+	var candidates []pruneCandidate
+	for i := range worktrees {
+		wt := &worktrees[i]
+		if wt.IsMain || isPathWithin(wt.Path, cwd) || !isWorktreeManagedCommon(wt.Path, cfg, mainRepoPath, false) {
+			continue
+		}
+		reason := branches.reason(wt.Branch)
+		if reason == "" {
+			reason = staleReason(executor, cfg.ForBranch(wt.Branch), wt.Path, now)
+		}
+		if reason != "" {
+			candidates = append(candidates, pruneCandidate{
+				worktree: wt, name: getWorktreeDisplayName(*wt, cfg, mainRepoPath), reason: reason,
+			})
+		}
+	}
+	return candidates, nil
+}
+
+// loadPruneBranches reads the local branches and which of them are merged into the default
+// branch, preferring the remote's copy of it. Failures leave the affected part empty, so
+// that the other reasons still apply.
+func loadPruneBranches(executor command.Executor, cfg *config.Config, mainRepoPath string) *pruneBranches {
+	branches := &pruneBranches{commits: map[string]string{}, gone: map[string]string{}, merged: map[string]bool{}}
+
+	defaultBranch := cfg.ExpandVariables("${DEFAULT_BRANCH}", mainRepoPath, "")
+	targets := []string{defaultBranch}
+	if remote := cfg.ExpandVariables("${REMOTE}", mainRepoPath, ""); remote != "" {
+		targets = []string{remote + "/" + defaultBranch, defaultBranch}
+	}
+	commands := []command.Command{command.GitBranchList()}
+	for _, target := range targets {
+		commands = append(commands, command.GitRevParseVerify(target))
+	}
+	result, err := executor.Execute(commands)
+	if err != nil || len(result.Results) != len(commands) {
+		return branches
+	}
+	if result.Results[0].Error == nil {
+		branches.parse(result.Results[0].Output)
+	}
+
+	for i, target := range targets {
+		if res := result.Results[i+1]; res.Error == nil && strings.TrimSpace(res.Output) != "" {
+			branches.target = target
+			break
+		}
+	}
+	if branches.target != "" {
+		branches.addMerged(executor, defaultBranch)
+	}
+	return branches
+}
+
+// addMerged marks the branches merged into target that had commits of their own, except
+// the default branch itself.
+func (b *pruneBranches) addMerged(executor command.Executor, defaultBranch string) {
+	result, err := executor.Execute([]command.Command{command.GitBranchesMerged(b.target)})
+	if err != nil || len(result.Results) == 0 || result.Results[0].Error != nil {
+		return
+	}
+	var reachable []string
+	for _, branch := range strings.Fields(result.Results[0].Output) {
+		if branch != defaultBranch {
+			reachable = append(reachable, branch)
+		}
+	}
+	b.addDivergedBranches(executor, reachable)
+}
+
+// addDivergedBranches marks the branches of reachable, whose tips target reaches, as merged
+// when they had commits of their own. A branch created from the default branch without any
+// commits is reachable too, as soon as the default branch moves on. It is told apart by its
+// reflog, which holds nothing but its creation, and by its tip, a commit the default branch
+// itself pointed to. A branch that moved since it was created, or whose tip came in through
+// a merge, was worked on.
+func (b *pruneBranches) addDivergedBranches(executor command.Executor, reachable []string) {
+	if len(reachable) == 0 {
+		return
+	}
+	commands := make([]command.Command, 0, len(reachable)+1)
+	for _, branch := range reachable {
+		commands = append(commands, command.GitBranchReflog(branch))
+	}
+	commands = append(commands, command.GitRevListFirstParent(b.target))
+	result, err := executor.Execute(commands)
+	if err != nil || len(result.Results) != len(commands) {
+		return
+	}
+	history := result.Results[len(reachable)]
+	if history.Error != nil {
+		return
+	}
+	mainline := map[string]bool{}
+	for _, commit := range strings.Fields(history.Output) {
+		mainline[commit] = true
+	}
+	for i, branch := range reachable {
+		res := result.Results[i]
+		moved := res.Error == nil && len(strings.Fields(res.Output)) > 1
+		if moved || !mainline[b.commits[branch]] {
+			b.merged[branch] = true
+		}
+	}
+}
+
+// parse reads the output of command.GitBranchList.
+func (b *pruneBranches) parse(output string) {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 4 || fields[0] == "" {
+			continue
+		}
+		b.commits[fields[0]] = fields[1]
+		if fields[3] == upstreamGoneTrack {
+			b.gone[fields[0]] = fields[2]
+		}
+	}
+}
+
+// reason explains why the worktree of branch can be pruned; it is empty when it cannot.
+func (b *pruneBranches) reason(branch string) string {
+	switch {
+	case branch == "" || branch == detachedKeyword:
+		return ""
+	case b.gone[branch] != "":
+		return fmt.Sprintf("upstream %s was deleted", b.gone[branch])
+	case b.merged[branch]:
+		return "merged into " + b.target
+	default:
+		return ""
+	}
+}
+
+// staleReason reports a worktree that was not touched for prune_after: neither committed to
+// nor staged in, going by its last commit and the modification time of its index.
+func staleReason(executor command.Executor, cfg *config.Config, worktreePath string, now time.Time) string {
+	pruneAfter := cfg.PruneAfter()
+	if pruneAfter == 0 {
+		return ""
+	}
+
+	var touched time.Time
+	if gitDir, err := git.WorktreeGitDir(worktreePath); err == nil {
+		if info, err := os.Stat(filepath.Join(gitDir, "index")); err == nil {
+			touched = info.ModTime()
+		}
+	}
+	result, err := executor.Execute([]command.Command{command.GitLastCommitTime(worktreePath)})
+	if err == nil && len(result.Results) > 0 && result.Results[0].Error == nil {
+		if seconds, err := strconv.ParseInt(strings.TrimSpace(result.Results[0].Output), 10, 64); err == nil {
+			if committed := time.Unix(seconds, 0); committed.After(touched) {
+				touched = committed
+			}
+		}
+	}
+	if touched.IsZero() || now.Sub(touched) < pruneAfter {
+		return ""
+	}
+	return fmt.Sprintf("not touched for %d days", int(now.Sub(touched).Hours()/hoursPerDay))
+}
+
+// skipLockedCandidates leaves the locked worktrees out of candidates, saying so, unless
+// force is set; then they are kept and marked as locked.
+func skipLockedCandidates(w io.Writer, candidates []pruneCandidate, force bool) ([]pruneCandidate, error) {
+	kept := make([]pruneCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		wt := candidate.worktree
+		switch {
+		case !wt.Locked:
+			kept = append(kept, candidate)
+		case force:
+			candidate.reason += ", locked" + lockReasonSuffix(wt.LockReason)
+			kept = append(kept, candidate)
+		default:
+			if _, err := fmt.Fprintf(w, "Skipping locked worktree '%s'%s; pass --force to prune it\n",
+				candidate.name, lockReasonSuffix(wt.LockReason)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return kept, nil
+}
+
+func writePruneSummary(w io.Writer, candidates []pruneCandidate, dryRun bool) error {
+	if len(candidates) == 0 {
+		_, err := fmt.Fprintln(w, "Nothing to prune")
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "Found %d worktree(s) to prune:\n", len(candidates)); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:mnd // column padding
+	for _, candidate := range candidates {
+		if _, err := fmt.Fprintf(tw, "  %s\t%s\n", candidate.name, candidate.reason); err != nil {
+			return err
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if dryRun {
+		_, err := fmt.Fprintln(w, "Run 'wtp prune' without --dry-run to remove them.")
+		return err
+	}
+	return nil
+}
+
+// pruneOptions are how pruneWorktrees confirms and removes the candidates.
+type pruneOptions struct {
+	// yes removes them without asking.
+	yes bool
+	// force removes dirty worktrees and goes on when a pre_remove hook fails, like 'wtp remove --force'.
+	force bool
+	// failFast stops at the first candidate that cannot be removed.
+	failFast bool
+}
+
+// pruneWorktrees confirms and removes candidates one by one, like 'wtp remove', and
+// returns the worktrees it removed. A failed removal is reported and, unless
+// opts.failFast, the others still go ahead; the result for each candidate is then shown.
+func pruneWorktrees(
+	w io.Writer, executor command.Executor, cwd string, candidates []pruneCandidate, opts pruneOptions,
+) ([]*git.Worktree, error) {
+	if err := writePruneSummary(w, candidates, false); err != nil {
+		return nil, err
+	}
+	if !opts.yes {
+		if !pruneIsTerminal() {
+			return nil, errors.TerminalRequired("confirming the removal", "pass --yes to prune non-interactively")
+		}
+		if _, err := fmt.Fprintf(w, "Remove %d worktree(s)? [y/N]: ", len(candidates)); err != nil {
+			return nil, err
+		}
+		answer, _ := bufio.NewReader(pruneInput).ReadString('\n')
+		if !strings.HasPrefix(strings.ToLower(strings.TrimSpace(answer)), "y") {
+			_, err := fmt.Fprintln(w, "Aborted; nothing was removed.")
+			return nil, err
+		}
+	}
+
+	var pruned []*git.Worktree
+	report := &batchReport{}
+	for _, candidate := range candidates {
+		if opts.failFast && len(report.names(batchFailed)) > 0 {
+			report.skip(candidate.name, failFastReason)
+			continue
+		}
+		// Candidates come from 'git worktree list', so they are removed as git worktrees
+		err := removeCommandWithCommandExecutor(nil, w, executor, vcs.Git{}, cwd, candidate.worktree.Path,
+			opts.force, false, false)
+		if err != nil {
+			report.fail(candidate.name, err)
+			continue
+		}
+		report.succeed(candidate.name)
+		pruned = append(pruned, candidate.worktree)
+		events.Emit(&events.Event{
+			Type: events.TypeWorktreePruned, Worktree: candidate.worktree.Path, Branch: eventBranch(candidate.worktree.Branch),
+			Reason: candidate.reason,
+		})
+	}
+	if report.complete() {
+		return pruned, nil
+	}
+	if err := report.write(w); err != nil {
+		return pruned, err
+	}
+	return pruned, report.err(func(failed []string) error {
+		return fmt.Errorf("%d of %d worktree(s) could not be pruned: %s",
+			len(failed), len(candidates), strings.Join(failed, ", "))
+	})
+}
+
+// executePostPruneHooks runs the configured post_prune hooks once in the main worktree
+// after pruned were removed; nothing runs when no worktree was. A failing hook is
+// reported as a warning.
+func executePostPruneHooks(w io.Writer, cfg *config.Config, mainRepoPath string, pruned []*git.Worktree) error {
+	if len(pruned) == 0 || !cfg.HasPostPruneHooks() {
+		return nil
+	}
+
+	paths := make([]string, 0, len(pruned))
+	var branches []string
+	for _, wt := range pruned {
+		paths = append(paths, wt.Path)
+		if wt.Branch != "" && wt.Branch != detachedKeyword {
+			branches = append(branches, wt.Branch)
+		}
+	}
+
+	if _, err := fmt.Fprintln(w, "Executing post-prune hooks..."); err != nil {
+		return err
+	}
+	executor := hooks.NewExecutor(cfg, mainRepoPath)
+	if err := executor.ExecutePostPruneHooks(w, paths, branches); err != nil {
+		return writeWarning(w, errors.CodeWarnPostRemoveHookFailed, "Post-prune hook failed: %v", err)
+	}
+	_, err := fmt.Fprintln(w, "✓ All post-prune hooks executed successfully")
+	return err
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/events"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+type mockPruneCommandExecutor struct {
+	executedCommands []command.Command
+	listOutput       string
+	branchOutput     string
+	mergedOutput     string
+	targetCommit     string
+	reflogs          map[string]string
+	mainline         string
+	commitTimes      map[string]time.Time
+}
+
+func (m *mockPruneCommandExecutor) Execute(commands []command.Command) (*command.ExecutionResult, error) {
+	m.executedCommands = append(m.executedCommands, commands...)
+	results := make([]command.Result, len(commands))
+	for i, cmd := range commands {
+		results[i].Command = cmd
+		switch cmd.Args[0] {
+		case "worktree":
+			results[i].Output = m.listOutput
+		case "for-each-ref":
+			if strings.HasPrefix(cmd.Args[1], "--merged") {
+				results[i].Output = m.mergedOutput
+			} else {
+				results[i].Output = m.branchOutput
+			}
+		case "rev-parse":
+			if m.targetCommit == "" {
+				results[i].Error = errors.New("exit status 1")
+			}
+			results[i].Output = m.targetCommit
+		case "rev-list":
+			results[i].Output = m.mainline
+		case "reflog":
+			branch := strings.TrimPrefix(cmd.Args[3], "refs/heads/")
+			results[i].Output = m.reflogs[branch]
+		case "log":
+			if committed, ok := m.commitTimes[cmd.WorkDir]; ok {
+				results[i].Output = strconv.FormatInt(committed.Unix(), 10)
+			}
+		}
+	}
+	return &command.ExecutionResult{Results: results}, nil
+}
+
+func TestNewPruneCommand(t *testing.T) {
+	cmd := NewPruneCommand()
+
+	assert.Equal(t, "prune", cmd.Name)
+	assert.NotEmpty(t, cmd.Usage)
+	assert.NotNil(t, cmd.Action)
+	flagNames := map[string]bool{}
+	for _, flag := range cmd.Flags {
+		flagNames[flag.Names()[0]] = true
+	}
+	assert.True(t, flagNames["dry-run"])
+	assert.True(t, flagNames["yes"])
+	assert.True(t, flagNames["force"])
+}
+
+func TestPruneBranches_Reason(t *testing.T) {
+	branches := &pruneBranches{commits: map[string]string{}, gone: map[string]string{}, merged: map[string]bool{}}
+	branches.parse("main\taaa\torigin/main\t\n" +
+		"feature/gone\tbbb\torigin/feature/gone\t[gone]\n" +
+		"feature/ahead\tccc\torigin/feature/ahead\t[ahead 1]\n" +
+		"local\tddd\t\t\n")
+	branches.merged["feature/merged"] = true
+	branches.target = "origin/main"
+
+	assert.Equal(t, "aaa", branches.commits["main"])
+	assert.Equal(t, "upstream origin/feature/gone was deleted", branches.reason("feature/gone"))
+	assert.Equal(t, "merged into origin/main", branches.reason("feature/merged"))
+	assert.Empty(t, branches.reason("feature/ahead"))
+	assert.Empty(t, branches.reason("local"))
+	assert.Empty(t, branches.reason(detachedKeyword))
+}
+
+func TestFindPruneCandidates(t *testing.T) {
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(t.TempDir(), "gitconfig"))
+	mainRepoPath := t.TempDir()
+	worktreesDir := filepath.Join(mainRepoPath, ".worktrees")
+	path := func(name string) string { return filepath.Join(worktreesDir, name) }
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	executor := &mockPruneCommandExecutor{
+		listOutput: "worktree " + mainRepoPath + "\nHEAD aaa\nbranch refs/heads/main\n\n" +
+			"worktree " + path("gone") + "\nHEAD bbb\nbranch refs/heads/gone\n\n" +
+			"worktree " + path("merged") + "\nHEAD ccc\nbranch refs/heads/merged\n\n" +
+			"worktree " + path("ff") + "\nHEAD aaa\nbranch refs/heads/ff\n\n" +
+			"worktree " + path("fresh") + "\nHEAD aaa\nbranch refs/heads/fresh\n\n" +
+			"worktree " + path("idle") + "\nHEAD 888\nbranch refs/heads/idle\n\n" +
+			"worktree " + path("old") + "\nHEAD ddd\nbranch refs/heads/old\n\n" +
+			"worktree " + path("current") + "\nHEAD eee\nbranch refs/heads/current\n\n" +
+			"worktree " + filepath.Join(t.TempDir(), "elsewhere") + "\nHEAD fff\nbranch refs/heads/elsewhere\n\n",
+		branchOutput: "main\taaa\t\t\ngone\tbbb\torigin/gone\t[gone]\nmerged\tccc\t\t\n" +
+			"ff\taaa\t\t\nfresh\taaa\t\t\nidle\t888\t\t\nold\tddd\t\t\ncurrent\teee\torigin/current\t[gone]\n" +
+			"elsewhere\tfff\torigin/elsewhere\t[gone]\n",
+		mergedOutput: "main\nmerged\nff\nfresh\nidle\n",
+		targetCommit: "aaa",
+		// idle was created from main at 888 and never committed to; main has moved on since
+		reflogs: map[string]string{
+			"ff":    "aaa\n999\n",
+			"fresh": "aaa\n",
+			"idle":  "888\n",
+		},
+		mainline: "aaa\n888\n777\n",
+		commitTimes: map[string]time.Time{
+			path("old"):   now.Add(-45 * 24 * time.Hour),
+			path("fresh"): now.Add(-time.Hour),
+		},
+	}
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: ".worktrees", PruneAfter: "30d"}}
+
+	candidates, err := findPruneCandidates(executor, cfg, mainRepoPath, path("current"), now)
+	require.NoError(t, err)
+
+	reasons := map[string]string{}
+	for _, candidate := range candidates {
+		reasons[candidate.name] = candidate.reason
+	}
+	assert.Equal(t, map[string]string{
+		"gone":   "upstream origin/gone was deleted",
+		"merged": "merged into main",
+		"ff":     "merged into main",
+		"old":    "not touched for 45 days",
+	}, reasons)
+
+	// Without prune_after, age alone never makes a worktree prunable
+	cfg.Defaults.PruneAfter = ""
+	candidates, err = findPruneCandidates(executor, cfg, mainRepoPath, path("current"), now)
+	require.NoError(t, err)
+	assert.Len(t, candidates, 3)
+}
+
+func TestWritePruneSummary(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writePruneSummary(&buf, nil, true))
+	assert.Equal(t, "Nothing to prune\n", buf.String())
+
+	buf.Reset()
+	candidates := []pruneCandidate{
+		{name: "feature/foo", reason: "merged into origin/main"},
+		{name: "bar", reason: "not touched for 40 days"},
+	}
+	require.NoError(t, writePruneSummary(&buf, candidates, true))
+	output := buf.String()
+	assert.Contains(t, output, "Found 2 worktree(s) to prune:")
+	assert.Contains(t, output, "  feature/foo  merged into origin/main")
+	assert.Contains(t, output, "  bar          not touched for 40 days")
+	assert.Contains(t, output, "without --dry-run")
+}
+
+func TestSkipLockedCandidates(t *testing.T) {
+	candidates := func() []pruneCandidate {
+		return []pruneCandidate{
+			{worktree: &git.Worktree{Path: "/wt/a"}, name: "a", reason: "merged into main"},
+			{worktree: &git.Worktree{Path: "/wt/b", Locked: true, LockReason: "on a USB drive"}, name: "b",
+				reason: "merged into main"},
+		}
+	}
+
+	var buf bytes.Buffer
+	kept, err := skipLockedCandidates(&buf, candidates(), false)
+	require.NoError(t, err)
+	require.Len(t, kept, 1)
+	assert.Equal(t, "a", kept[0].name)
+	assert.Equal(t, "Skipping locked worktree 'b' (on a USB drive); pass --force to prune it\n", buf.String())
+
+	buf.Reset()
+	kept, err = skipLockedCandidates(&buf, candidates(), true)
+	require.NoError(t, err)
+	require.Len(t, kept, 2)
+	assert.Equal(t, "merged into main, locked (on a USB drive)", kept[1].reason)
+	assert.Empty(t, buf.String())
+}
+
+func TestPruneWorktrees_Confirmation(t *testing.T) {
+	origInput, origIsTerminal := pruneInput, pruneIsTerminal
+	t.Cleanup(func() { pruneInput, pruneIsTerminal = origInput, origIsTerminal })
+
+	candidates := []pruneCandidate{{name: "foo", reason: "merged into main"}}
+
+	t.Run("refuses without a terminal", func(t *testing.T) {
+		pruneIsTerminal = func() bool { return false }
+		executor := &mockPruneCommandExecutor{}
+
+		var buf bytes.Buffer
+		_, err := pruneWorktrees(&buf, executor, "/repo", candidates, pruneOptions{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--yes")
+		assert.Empty(t, executor.executedCommands)
+	})
+
+	t.Run("aborts unless confirmed", func(t *testing.T) {
+		pruneIsTerminal = func() bool { return true }
+		pruneInput = strings.NewReader("n\n")
+		executor := &mockPruneCommandExecutor{}
+
+		var buf bytes.Buffer
+		pruned, err := pruneWorktrees(&buf, executor, "/repo", candidates, pruneOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, pruned)
+		assert.Contains(t, buf.String(), "Remove 1 worktree(s)? [y/N]: ")
+		assert.Contains(t, buf.String(), "Aborted; nothing was removed.")
+		assert.Empty(t, executor.executedCommands)
+	})
+}
+
+func TestPruneWorktrees_EmitsEvents(t *testing.T) {
+	mainPath, worktreePath, worktreeList := setupPreRemoveHookRepo(t, "true")
+	var stream bytes.Buffer
+	events.Enable(&stream)
+	t.Cleanup(func() { events.Enable(nil) })
+
+	executor := &mockRemoveCommandExecutor{results: []command.Result{{Output: worktreeList}, {Output: ""}}}
+	candidates := []pruneCandidate{{
+		worktree: &git.Worktree{Path: worktreePath, Branch: "feature/foo"},
+		name:     "feature/foo",
+		reason:   "merged into main",
+	}}
+	pruned, err := pruneWorktrees(&bytes.Buffer{}, executor, mainPath, candidates, pruneOptions{yes: true})
+
+	require.NoError(t, err)
+	require.Len(t, pruned, 1)
+	lines := strings.Split(strings.TrimSuffix(stream.String(), "\n"), "\n")
+	require.GreaterOrEqual(t, len(lines), 2, "the pre_remove hook's events come first")
+	var removed, prunedEvent events.Event
+	require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-2]), &removed))
+	require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &prunedEvent))
+	assert.Equal(t, events.TypeWorktreeRemoved, removed.Type)
+	assert.Equal(t, worktreePath, removed.Worktree)
+	assert.Equal(t, "feature/foo", removed.Branch)
+	assert.Equal(t, events.TypeWorktreePruned, prunedEvent.Type)
+	assert.Equal(t, "merged into main", prunedEvent.Reason)
+}
+
+func TestExecutePostPruneHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+	mainRepoPath := t.TempDir()
+	cfg := &config.Config{Hooks: config.Hooks{PostPrune: []config.Hook{
+		{Type: config.HookTypeCommand, Command: `echo "pruned $GIT_WTP_PRUNED_COUNT: $GIT_WTP_PRUNED_BRANCHES"`},
+	}}}
+
+	var buf bytes.Buffer
+	require.NoError(t, executePostPruneHooks(&buf, cfg, mainRepoPath, nil))
+	assert.Empty(t, buf.String(), "nothing runs when no worktree was pruned")
+
+	require.NoError(t, executePostPruneHooks(&buf, cfg, mainRepoPath, []*git.Worktree{
+		{Path: "/wt/old", Branch: "feature/old"},
+		{Path: "/wt/spike", Branch: detachedKeyword},
+	}))
+	assert.Contains(t, buf.String(), "pruned 2: feature/old\n")
+	assert.Contains(t, buf.String(), "✓ All post-prune hooks executed successfully")
+}
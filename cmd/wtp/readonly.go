@@ -0,0 +1,35 @@
+package main
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+)
+
+// readOnlyEnvVar enables read-only mode when set to a true value such as "1".
+const readOnlyEnvVar = "WTP_READONLY"
+
+// ensureWritable fails when read-only mode is enabled through WTP_READONLY or
+// defaults.readonly. Commands that change worktrees, branches, or files call it before
+// doing anything; action describes what was refused, e.g. "create worktrees". cfg may be nil.
+func ensureWritable(cfg *config.Config, action string) error {
+	if enabled, err := strconv.ParseBool(os.Getenv(readOnlyEnvVar)); err == nil && enabled {
+		return errors.ReadOnlyMode(action, readOnlyEnvVar)
+	}
+	if cfg != nil && cfg.Defaults.ReadOnly {
+		return errors.ReadOnlyMode(action, "defaults.readonly")
+	}
+	return nil
+}
+
+// ensureWritableRepo is ensureWritable for commands that have not loaded the configuration
+// of repoPath yet. A configuration that fails to load is left for the command to report.
+func ensureWritableRepo(repoPath, action string) error {
+	cfg, err := config.LoadConfig(repoPath, "")
+	if err != nil {
+		cfg = nil
+	}
+	return ensureWritable(cfg, action)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func TestEnsureWritable(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         string
+		readOnly    bool
+		expectError string
+	}{
+		{name: "writable"},
+		{name: "env enabled", env: "1", expectError: "read-only mode (WTP_READONLY)"},
+		{name: "env true", env: "true", expectError: "read-only mode (WTP_READONLY)"},
+		{name: "env disabled", env: "0"},
+		{name: "config enabled", readOnly: true, expectError: "read-only mode (defaults.readonly)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(readOnlyEnvVar, tt.env)
+			cfg := &config.Config{Defaults: config.Defaults{ReadOnly: tt.readOnly}}
+
+			err := ensureWritable(cfg, "create worktrees")
+
+			if tt.expectError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "cannot create worktrees")
+			assert.Contains(t, err.Error(), tt.expectError)
+		})
+	}
+}
+
+func TestEnsureWritableRepo(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(readOnlyEnvVar, "")
+	repoPath := t.TempDir()
+
+	assert.NoError(t, ensureWritableRepo(repoPath, "remove worktrees"))
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, config.LocalConfigFileName),
+		[]byte("defaults:\n  readonly: true\n"), 0o600))
+	err := ensureWritableRepo(repoPath, "remove worktrees")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot remove worktrees")
+}
+
+func TestReadOnlyMode_BlocksMutatingCommands(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(readOnlyEnvVar, "1")
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	require.NoError(t, os.Chdir(tempDir))
+	if err := exec.Command("git", "init", "-q").Run(); err != nil {
+		t.Skip("git not available")
+	}
+
+	app := &cli.Command{Commands: []*cli.Command{NewInitCommand(), NewAddCommand(), NewRemoveCommand()}}
+	var buf bytes.Buffer
+	app.Writer = &buf
+	ctx := context.Background()
+
+	for _, args := range [][]string{
+		{"wtp", "init"},
+		{"wtp", "add", "-b", "feature/x"},
+		{"wtp", "remove", "feature/x"},
+	} {
+		err := app.Run(ctx, args)
+		require.Error(t, err, args)
+		assert.Contains(t, err.Error(), "read-only mode", args)
+	}
+	assert.NoFileExists(t, filepath.Join(tempDir, config.ConfigFileName))
+}
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/git"
+	"github.com/satococoa/wtp/v2/internal/runtimedir"
+)
+
+const (
+	remoteStateFileName = "remote.json"
+	remoteStateFileMode = 0o644
+	defaultRelinkRemote = "origin"
+)
+
+// remoteState is the remote URL 'wtp relink' saw last; a different URL on the next run
+// means the repository was transferred or renamed.
+type remoteState struct {
+	Remote     string    `json:"remote"`
+	URL        string    `json:"url"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// relinkOptions are the flags of 'wtp relink'.
+type relinkOptions struct {
+	remote   string
+	relocate bool
+	dryRun   bool
+}
+
+// worktreeRelocation is a worktree whose path differs from the one base_dir now yields.
+type worktreeRelocation struct {
+	name string
+	from string
+	to   string
+}
+
+// NewRelinkCommand creates the relink command definition
+func NewRelinkCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "relink",
+		Usage:     "Reconcile worktrees after the repository was moved, renamed, or transferred",
+		UsageText: "wtp relink [--remote <name>] [--relocate] [--dry-run]",
+		Description: "Records the remote URL and reports when it changed since the last run, repairs " +
+			"the links between the repository and its worktrees ('git worktree repair'), and lists " +
+			"worktrees whose path no longer matches base_dir, e.g. because it uses ${DIRNAME} and the " +
+			"clone was renamed. With --relocate those worktrees are moved with 'git worktree move'.\n\n" +
+			"Examples:\n" +
+			"  wtp relink                 # Report and repair\n" +
+			"  wtp relink --relocate      # Also move worktrees to their configured paths\n" +
+			"  wtp relink --dry-run       # Report only; change nothing",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "remote",
+				Usage: "Remote whose URL is tracked",
+				Value: defaultRelinkRemote,
+			},
+			&cli.BoolFlag{
+				Name:  "relocate",
+				Usage: "Move worktrees to the paths base_dir now yields",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Show what would change without repairing, moving, or recording anything",
+			},
+		},
+		Action: relinkCommand,
+	}
+}
+
+func relinkCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	repo, cfg, mainRepoPath, err := setupRepoAndConfig()
+	if err != nil {
+		return err
+	}
+	opts := relinkOptions{remote: cmd.String("remote"), relocate: cmd.Bool("relocate"), dryRun: cmd.Bool("dry-run")}
+	if !opts.dryRun {
+		if err := ensureWritable(cfg, "relink worktrees"); err != nil {
+			return err
+		}
+	}
+
+	statePath, err := remoteStatePath(repo)
+	if err != nil {
+		return err
+	}
+
+	executor := command.NewRealExecutor()
+	return relinkCommandWithCommandExecutor(w, executor, cfg, mainRepoPath, statePath, opts)
+}
+
+func remoteStatePath(repo *git.Repository) (string, error) {
+	commonDir, err := repo.GetGitCommonDir()
+	if err != nil {
+		return "", errors.GitCommandFailed("git rev-parse --git-common-dir", err.Error())
+	}
+	return filepath.Join(commonDir, stateDirName, remoteStateFileName), nil
+}
+
+func relinkCommandWithCommandExecutor(
+	w io.Writer, executor command.Executor, cfg *config.Config, mainRepoPath, statePath string, opts relinkOptions,
+) error {
+	url, err := relinkRemoteURL(executor, opts.remote)
+	if err != nil {
+		return err
+	}
+	previous, err := loadRemoteState(statePath)
+	if err != nil {
+		return err
+	}
+	if err := writeRemoteChange(w, opts.remote, previous, url); err != nil {
+		return err
+	}
+
+	if !opts.dryRun {
+		if err := repairWorktreeLinks(w, executor); err != nil {
+			return err
+		}
+		if opts.remote == defaultRelinkRemote && previous != nil && previous.Remote == opts.remote &&
+			previous.URL != url {
+			relinkClone(cfg, mainRepoPath, previous.URL, url)
+		}
+	}
+
+	result, err := executor.Execute([]command.Command{command.GitWorktreeList()})
+	if err != nil {
+		return errors.GitCommandFailed("git worktree list", err.Error())
+	}
+	worktrees := parseWorktreesFromOutput(result.Results[0].Output)
+	relocations := planWorktreeRelocations(worktrees, cfg, mainRepoPath, false)
+	relocateErr := relocateWorktrees(w, executor, mainRepoPath, relocations, opts)
+
+	if opts.dryRun {
+		return relocateErr
+	}
+	// Repaired and moved worktrees may be at new paths, even when some moves failed
+	if err := syncWorktreeMap(w, executor, mainRepoPath); err != nil {
+		return err
+	}
+	if relocateErr != nil {
+		return relocateErr
+	}
+	return saveRemoteState(statePath, &remoteState{Remote: opts.remote, URL: url, RecordedAt: time.Now().UTC()})
+}
+
+// relinkClone carries along what wtp keys by the clone's origin URL after it changed from
+// previousURL to url: the clone's directory under defaults.runtime_dir, when there is one,
+// and its entry in the clone registry.
+func relinkClone(cfg *config.Config, mainRepoPath, previousURL, url string) {
+	if oldDir := runtimedir.CloneDir(cfg, mainRepoPath, previousURL); oldDir != "" {
+		newDir := runtimedir.CloneDir(cfg, mainRepoPath, url)
+		if _, err := os.Lstat(newDir); os.IsNotExist(err) {
+			_ = os.Rename(oldDir, newDir)
+		}
+	}
+	unregisterClone(mainRepoPath, previousURL)
+	registerClone(mainRepoPath, url)
+}
+
+func relinkRemoteURL(executor command.Executor, remote string) (string, error) {
+	result, err := executor.Execute([]command.Command{command.GitRemoteGetURL(remote)})
+	if err != nil {
+		return "", errors.GitCommandFailed("git remote get-url "+remote, err.Error())
+	}
+	if res := result.Results[0]; res.Error != nil {
+		return "", errors.GitCommandFailed("git remote get-url "+remote, strings.TrimSpace(res.Output))
+	}
+	return strings.TrimSpace(result.Results[0].Output), nil
+}
+
+func writeRemoteChange(w io.Writer, remote string, previous *remoteState, url string) error {
+	var err error
+	switch {
+	case previous == nil || previous.Remote != remote:
+		_, err = fmt.Fprintf(w, "Remote %s: %s (recorded for the first time)\n", remote, url)
+	case previous.URL == url:
+		_, err = fmt.Fprintf(w, "Remote %s: %s (unchanged)\n", remote, url)
+	default:
+		_, err = fmt.Fprintf(w, "Remote %s changed: %s → %s\n", remote, previous.URL, url)
+	}
+	return err
+}
+
+func repairWorktreeLinks(w io.Writer, executor command.Executor) error {
+	result, err := executor.Execute([]command.Command{command.GitWorktreeRepair()})
+	if err != nil {
+		return errors.GitCommandFailed("git worktree repair", err.Error())
+	}
+	res := result.Results[0]
+	if res.Error != nil {
+		return errors.GitCommandFailed("git worktree repair", strings.TrimSpace(res.Output))
+	}
+	output := strings.TrimSpace(res.Output)
+	if output == "" {
+		_, err := fmt.Fprintln(w, "Worktree links: ok")
+		return err
+	}
+	if _, err := fmt.Fprintln(w, "Worktree links repaired:"); err != nil {
+		return err
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if _, err := fmt.Fprintf(w, "  %s\n", line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// planWorktreeRelocations finds the worktrees created by wtp, or inside a base_dir, whose
+// path differs from the one base_dir (including branch overlays) yields for their branch;
+// includeUnmanaged considers every linked worktree. The branch recorded at creation is
+// preferred, since 'wtp checkout' may have switched the worktree since.
+func planWorktreeRelocations(
+	worktrees []git.Worktree, cfg *config.Config, mainRepoPath string, includeUnmanaged bool,
+) []worktreeRelocation {
+	var relocations []worktreeRelocation
+	for i := range worktrees {
+		wt := &worktrees[i]
+		if wt.IsMain {
+			continue
+		}
+		record, _ := loadProvisionRecord(cfg, wt.Path)
+		if record == nil && !includeUnmanaged && !isWorktreeManagedCommon(wt.Path, cfg, mainRepoPath, wt.IsMain) {
+			continue
+		}
+		branch := wt.Branch
+		if record != nil && record.Branch != "" {
+			branch = record.Branch
+		}
+		if branch == "" || branch == detachedKeyword {
+			continue
+		}
+		target := filepath.Clean(cfg.ForBranch(branch).ResolveWorktreePath(mainRepoPath, branch))
+		if target != filepath.Clean(wt.Path) {
+			relocations = append(relocations, worktreeRelocation{
+				name: branch, from: wt.Path, to: target,
+			})
+		}
+	}
+	return relocations
+}
+
+func relocateWorktrees(
+	w io.Writer, executor command.Executor, mainRepoPath string, relocations []worktreeRelocation, opts relinkOptions,
+) error {
+	if len(relocations) == 0 {
+		_, err := fmt.Fprintln(w, "All worktrees are at their configured paths")
+		return err
+	}
+	if !opts.relocate || opts.dryRun {
+		if _, err := fmt.Fprintln(w, "Worktrees not at their configured path:"); err != nil {
+			return err
+		}
+		for _, r := range relocations {
+			if _, err := fmt.Fprintf(w, "  %s: %s → %s\n", r.name, r.from, r.to); err != nil {
+				return err
+			}
+		}
+		if opts.relocate {
+			return nil
+		}
+		_, err := fmt.Fprintln(w, "Run 'wtp relink --relocate' to move them.")
+		return err
+	}
+
+	var failed []string
+	for _, r := range relocations {
+		if err := moveWorktree(executor, r); err != nil {
+			failed = append(failed, r.name)
+			warnErr := writeWarning(w, errors.CodeWarnWorktreeMoveFailed, "could not move %s: %v", r.name, err)
+			if warnErr != nil {
+				return warnErr
+			}
+			continue
+		}
+		_ = moveRegisteredWorktree(mainRepoPath, r.from, r.to)
+		if _, err := fmt.Fprintf(w, "Moved %s: %s → %s\n", r.name, r.from, r.to); err != nil {
+			return err
+		}
+	}
+	if len(failed) > 0 {
+		return errors.WorktreeRelocationFailed(failed)
+	}
+	return nil
+}
+
+func moveWorktree(executor command.Executor, r worktreeRelocation) error {
+	if _, err := os.Lstat(r.to); err == nil {
+		return fmt.Errorf("%s already exists", r.to)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.to), stateDirMode); err != nil {
+		return err
+	}
+	result, err := executor.Execute([]command.Command{command.GitWorktreeMove(r.from, r.to)})
+	if err != nil {
+		return err
+	}
+	if res := result.Results[0]; res.Error != nil {
+		return fmt.Errorf("%w: %s", res.Error, strings.TrimSpace(res.Output))
+	}
+	return nil
+}
+
+func loadRemoteState(path string) (*remoteState, error) {
+	// #nosec G304 -- path is derived from the repository's git directory
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read remote state: %w", err)
+	}
+
+	var state remoteState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse remote state %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+func saveRemoteState(path string, state *remoteState) error {
+	if err := os.MkdirAll(filepath.Dir(path), stateDirMode); err != nil {
+		return errors.DirectoryAccessFailed("create", filepath.Dir(path), err)
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode remote state: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), remoteStateFileMode); err != nil {
+		return fmt.Errorf("failed to write remote state: %w", err)
+	}
+	return nil
+}
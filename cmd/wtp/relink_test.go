@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/runtimedir"
+	"github.com/satococoa/wtp/v2/internal/state"
+)
+
+type mockRelinkCommandExecutor struct {
+	executedCommands []command.Command
+	remoteURL        string
+	repairOutput     string
+	listOutput       string
+}
+
+func (m *mockRelinkCommandExecutor) Execute(commands []command.Command) (*command.ExecutionResult, error) {
+	m.executedCommands = append(m.executedCommands, commands...)
+	results := make([]command.Result, len(commands))
+	for i, cmd := range commands {
+		results[i].Command = cmd
+		switch {
+		case cmd.Args[0] == "remote" && m.remoteURL == "":
+			results[i].Output = "error: No such remote 'origin'"
+			results[i].Error = errors.New("exit status 2")
+		case cmd.Args[0] == "remote":
+			results[i].Output = m.remoteURL + "\n"
+		case cmd.Args[1] == "repair":
+			results[i].Output = m.repairOutput
+		case cmd.Args[1] == "list":
+			results[i].Output = m.listOutput
+		}
+	}
+	return &command.ExecutionResult{Results: results}, nil
+}
+
+func (m *mockRelinkCommandExecutor) ran(subcommand string) bool {
+	for _, cmd := range m.executedCommands {
+		if len(cmd.Args) > 1 && cmd.Args[0] == "worktree" && cmd.Args[1] == subcommand {
+			return true
+		}
+	}
+	return false
+}
+
+func TestNewRelinkCommand(t *testing.T) {
+	cmd := NewRelinkCommand()
+
+	assert.Equal(t, "relink", cmd.Name)
+	assert.NotEmpty(t, cmd.Usage)
+	assert.NotNil(t, cmd.Action)
+}
+
+func TestRelinkCommand_RecordsAndDetectsRemoteChange(t *testing.T) {
+	mainPath, _, listOutput := setupCheckoutTest(t)
+	statePath := filepath.Join(t.TempDir(), "wtp", remoteStateFileName)
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+	opts := relinkOptions{remote: "origin"}
+	mockExec := &mockRelinkCommandExecutor{remoteURL: "git@github.com:old/repo.git", listOutput: listOutput}
+
+	var buf bytes.Buffer
+	require.NoError(t, relinkCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, statePath, opts))
+	assert.Contains(t, buf.String(), "Remote origin: git@github.com:old/repo.git (recorded for the first time)")
+	assert.Contains(t, buf.String(), "Worktree links: ok")
+	assert.Contains(t, buf.String(), "All worktrees are at their configured paths")
+
+	buf.Reset()
+	require.NoError(t, relinkCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, statePath, opts))
+	assert.Contains(t, buf.String(), "(unchanged)")
+
+	mockExec.remoteURL = "git@github.com:new-org/repo.git"
+	mockExec.repairOutput = "repair: gitdir incorrect: /old/.git/worktrees/foo/gitdir\n"
+	buf.Reset()
+	require.NoError(t, relinkCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, statePath, opts))
+	assert.Contains(t, buf.String(),
+		"Remote origin changed: git@github.com:old/repo.git → git@github.com:new-org/repo.git")
+	assert.Contains(t, buf.String(), "Worktree links repaired:\n  repair: gitdir incorrect")
+
+	recorded, err := loadRemoteState(statePath)
+	require.NoError(t, err)
+	assert.Equal(t, "git@github.com:new-org/repo.git", recorded.URL)
+}
+
+func TestRelinkCommand_MovesCloneStateOnRemoteChange(t *testing.T) {
+	clonesPath := useTempClonesRegistry(t)
+	mainPath, _, listOutput := setupCheckoutTest(t)
+	statePath := filepath.Join(t.TempDir(), "wtp", remoteStateFileName)
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees", RuntimeDir: t.TempDir()}}
+	opts := relinkOptions{remote: "origin"}
+	const oldURL, newURL = "git@github.com:old/repo.git", "git@github.com:new-org/repo.git"
+	mockExec := &mockRelinkCommandExecutor{remoteURL: oldURL, listOutput: listOutput}
+
+	var buf bytes.Buffer
+	require.NoError(t, relinkCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, statePath, opts))
+	registerClone(mainPath, oldURL)
+	oldDir := runtimedir.CloneDir(cfg, mainPath, oldURL)
+	require.NoError(t, os.MkdirAll(filepath.Join(oldDir, "wt-0123456789abcdef"), 0o755))
+
+	mockExec.remoteURL = newURL
+	require.NoError(t, relinkCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, statePath, opts))
+
+	assert.NoDirExists(t, oldDir)
+	assert.DirExists(t, filepath.Join(runtimedir.CloneDir(cfg, mainPath, newURL), "wt-0123456789abcdef"),
+		"runtime directories follow the clone to its new key")
+	clones, err := state.LoadClones(clonesPath)
+	require.NoError(t, err)
+	require.Len(t, clones.Clones, 1)
+	assert.Equal(t, newURL, clones.Clones[0].RemoteURL)
+}
+
+func TestRelinkCommand_Relocate(t *testing.T) {
+	mainPath, worktreePath, listOutput := setupCheckoutTest(t)
+	setupInfoWorktree(t, worktreePath)
+	record := newProvisionRecord("feature/foo", "main", nil, nil, nil)
+	require.NoError(t, saveProvisionRecord(&config.Config{}, worktreePath, record))
+
+	statePath := filepath.Join(t.TempDir(), "wtp", remoteStateFileName)
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../${DIRNAME}-worktrees"}}
+	target := filepath.Join(filepath.Dir(mainPath), "repo-worktrees", "feature", "foo")
+
+	// Without --relocate the moves are only listed
+	mockExec := &mockRelinkCommandExecutor{remoteURL: "https://example.com/repo.git", listOutput: listOutput}
+	var buf bytes.Buffer
+	require.NoError(t, relinkCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, statePath,
+		relinkOptions{remote: "origin"}))
+	assert.Contains(t, buf.String(), "  feature/foo: "+worktreePath+" → "+target)
+	assert.Contains(t, buf.String(), "wtp relink --relocate")
+	assert.False(t, mockExec.ran("move"))
+
+	buf.Reset()
+	require.NoError(t, relinkCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, statePath,
+		relinkOptions{remote: "origin", relocate: true}))
+	assert.Contains(t, buf.String(), "Moved feature/foo: "+worktreePath+" → "+target)
+	assert.True(t, mockExec.ran("move"))
+	assert.DirExists(t, filepath.Dir(target))
+}
+
+func TestRelinkCommand_RelocateRefusesExistingTarget(t *testing.T) {
+	mainPath, worktreePath, listOutput := setupCheckoutTest(t)
+	setupInfoWorktree(t, worktreePath)
+	require.NoError(t, saveProvisionRecord(&config.Config{}, worktreePath,
+		newProvisionRecord("feature/foo", "", nil, nil, nil)))
+	target := filepath.Join(filepath.Dir(mainPath), "moved", "feature", "foo")
+	require.NoError(t, os.MkdirAll(target, 0o755))
+
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../moved"}}
+	mockExec := &mockRelinkCommandExecutor{remoteURL: "https://example.com/repo.git", listOutput: listOutput}
+
+	var buf bytes.Buffer
+	err := relinkCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath,
+		filepath.Join(t.TempDir(), remoteStateFileName), relinkOptions{remote: "origin", relocate: true})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to relocate 1 worktree(s): feature/foo")
+	assert.Contains(t, buf.String(), "already exists")
+	assert.False(t, mockExec.ran("move"))
+}
+
+func TestRelinkCommand_DryRunChangesNothing(t *testing.T) {
+	mainPath, _, listOutput := setupCheckoutTest(t)
+	statePath := filepath.Join(t.TempDir(), "wtp", remoteStateFileName)
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+	mockExec := &mockRelinkCommandExecutor{remoteURL: "https://example.com/repo.git", listOutput: listOutput}
+
+	var buf bytes.Buffer
+	require.NoError(t, relinkCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, statePath,
+		relinkOptions{remote: "origin", relocate: true, dryRun: true}))
+
+	assert.False(t, mockExec.ran("repair"))
+	assert.NoFileExists(t, statePath)
+}
+
+func TestRelinkCommand_MissingRemote(t *testing.T) {
+	mainPath, _, listOutput := setupCheckoutTest(t)
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+	mockExec := &mockRelinkCommandExecutor{listOutput: listOutput}
+
+	err := relinkCommandWithCommandExecutor(&bytes.Buffer{}, mockExec, cfg, mainPath,
+		filepath.Join(t.TempDir(), remoteStateFileName), relinkOptions{remote: "origin"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "git remote get-url origin")
+}
+
+func TestSaveRemoteState_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wtp", remoteStateFileName)
+	saved := &remoteState{Remote: "origin", URL: "https://example.com/repo.git", RecordedAt: time.Now().UTC()}
+
+	require.NoError(t, saveRemoteState(path, saved))
+	loaded, err := loadRemoteState(path)
+	require.NoError(t, err)
+	assert.Equal(t, saved.URL, loaded.URL)
+	assert.True(t, saved.RecordedAt.Equal(loaded.RecordedAt))
+}
@@ -15,7 +15,11 @@ import (
 	"github.com/satococoa/wtp/v2/internal/command"
 	"github.com/satococoa/wtp/v2/internal/config"
 	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/events"
 	"github.com/satococoa/wtp/v2/internal/git"
+	"github.com/satococoa/wtp/v2/internal/hooks"
+	"github.com/satococoa/wtp/v2/internal/runtimedir"
+	"github.com/satococoa/wtp/v2/internal/vcs"
 )
 
 // Variable to allow mocking in tests
@@ -32,17 +36,23 @@ func NewRemoveCommand() *cli.Command {
 		Name:      "remove",
 		Aliases:   []string{"rm"},
 		Usage:     "Remove a worktree",
-		UsageText: "wtp remove <worktree-name>",
-		Description: "Removes the worktree with the specified directory name.\n\n" +
+		UsageText: "wtp remove <worktree-name|path>",
+		Description: "Removes the worktree with the specified name, or the worktree at the given path. " +
+			"Parent directories under base_dir that become empty are removed as well.\n\n" +
+			"Any hooks.pre_remove entries in .wtp.yml run first, inside the worktree being removed. " +
+			"A failing hook aborts the removal unless --force is given. hooks.post_remove entries run " +
+			"afterwards in the main worktree. A worktree locked with 'wtp lock' is only removed with " +
+			"--force.\n\n" +
 			"Examples:\n" +
 			"  wtp remove feature-old                  # Remove worktree\n" +
+			"  wtp remove ../worktrees/feature/old     # Remove worktree by path\n" +
 			"  wtp remove -f feature-dirty             # Force remove dirty worktree\n" +
 			"  wtp remove --with-branch feature-done   # Also delete the associated branch",
 		ShellComplete: completeWorktrees,
 		Flags: []cli.Flag{
 			&cli.BoolFlag{
 				Name:    "force",
-				Usage:   "Force removal even if worktree is dirty",
+				Usage:   "Force removal even if worktree is dirty or locked",
 				Aliases: []string{"f"},
 			},
 			&cli.BoolFlag{
@@ -82,43 +92,117 @@ func removeCommand(_ context.Context, cmd *cli.Command) error {
 	}
 
 	// Initialize repository to check if we're in a git repo
-	_, err = git.NewRepository(cwd)
+	repo, err := git.NewRepository(cwd)
 	if err != nil {
 		return errors.NotInGitRepository()
 	}
+	mainRepoPath, err := repo.GetMainWorktreePath()
+	if err != nil {
+		mainRepoPath = repo.Path()
+	}
+	if err := ensureWritableRepo(mainRepoPath, "remove worktrees"); err != nil {
+		return err
+	}
+	cfg, err := config.LoadConfig(mainRepoPath, "")
+	if err != nil {
+		return errors.ConfigLoadFailed(filepath.Join(mainRepoPath, config.ConfigFileName), err)
+	}
+	backend, err := vcs.ForConfig(cfg, mainRepoPath)
+	if err != nil {
+		return err
+	}
+	release, err := acquireRepoLock(w, cmd, cfg, mainRepoPath, "wtp remove")
+	if err != nil {
+		return err
+	}
+	defer release()
 
 	// Use CommandExecutor-based implementation
 	executor := command.NewRealExecutor()
-	return removeCommandWithCommandExecutor(cmd, w, executor, cwd, worktreeName, force, withBranch, forceBranch)
+	return removeCommandWithCommandExecutor(cmd, w, executor, backend, cwd, worktreeName, force, withBranch, forceBranch)
 }
 
 func removeCommandWithCommandExecutor(
 	_ *cli.Command,
 	w io.Writer,
 	executor command.Executor,
+	backend vcs.Backend,
 	cwd string,
 	worktreeName string,
 	force, withBranch, forceBranch bool,
 ) error {
 	// Get worktrees using CommandExecutor
-	listCmd := command.GitWorktreeList()
+	listCmd := backend.ListWorkdirs()
 	result, err := executor.Execute([]command.Command{listCmd})
 	if err != nil {
 		return errors.GitCommandFailed("git worktree list", err.Error())
 	}
 
 	// Parse worktrees from command output
-	worktrees := parseWorktreesFromOutput(result.Results[0].Output)
+	worktrees := backend.ParseWorkdirs(result.Results[0].Output)
 
 	// Find target worktree
-	targetWorktree, err := findTargetWorktreeFromList(worktrees, worktreeName)
+	targetWorktree, err := findRemoveTarget(worktrees, worktreeName, cwd)
 	if err != nil {
 		return err
 	}
 
-	absTargetPath, err := filepath.Abs(targetWorktree.Path)
+	if err := checkRemovable(targetWorktree, worktreeName, cwd, force); err != nil {
+		return err
+	}
+
+	cfg, mainRepoPath, branch, err := loadRemoveConfig(worktrees, targetWorktree.Path)
 	if err != nil {
-		return errors.WorktreeRemovalFailed(targetWorktree.Path, err)
+		return err
+	}
+	if err := executePreRemoveHooks(w, cfg, mainRepoPath, targetWorktree.Path, worktreeName, force); err != nil {
+		return err
+	}
+
+	// The runtime directory is found through the worktree's git directory, which goes too
+	cleanupRuntimeDir := runtimeDirCleanup(worktrees, targetWorktree.Path)
+
+	if err := removeWorkdir(executor, backend, targetWorktree, force); err != nil {
+		return err
+	}
+	removed := worktreeLabel(targetWorktree, cfg, mainRepoPath)
+	if _, err := fmt.Fprintf(w, "Removed worktree '%s' at %s\n", removed, targetWorktree.Path); err != nil {
+		return err
+	}
+	events.Emit(&events.Event{
+		Type: events.TypeWorktreeRemoved, Worktree: targetWorktree.Path, Branch: eventBranch(branch),
+	})
+	if err := cleanUpAfterRemoval(w, executor, worktrees, targetWorktree.Path, cleanupRuntimeDir); err != nil {
+		return err
+	}
+	if err := executePostRemoveHooks(w, cfg, mainRepoPath, targetWorktree.Path, branch); err != nil {
+		return err
+	}
+
+	// Remove branch if requested
+	if withBranch && targetWorktree.Branch != "" {
+		if err := removeBranchWithCommandExecutor(w, executor, backend, targetWorktree.Branch, forceBranch); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// eventBranch is branch as events name it: empty for a detached HEAD.
+func eventBranch(branch string) string {
+	if branch == detachedKeyword {
+		return ""
+	}
+	return branch
+}
+
+// checkRemovable refuses to remove the worktree containing cwd, and a locked worktree
+// unless force is set.
+func checkRemovable(target *git.Worktree, worktreeName, cwd string, force bool) error {
+	absTargetPath, err := filepath.Abs(target.Path)
+	if err != nil {
+		return errors.WorktreeRemovalFailed(target.Path, err)
 	}
 
 	absCwd, err := filepath.Abs(cwd)
@@ -129,41 +213,246 @@ func removeCommandWithCommandExecutor(
 	if isPathWithin(absTargetPath, absCwd) {
 		return errors.CannotRemoveCurrentWorktree(worktreeName, absTargetPath)
 	}
+	if target.Locked && !force {
+		return errors.WorktreeLocked(worktreeName, target.LockReason)
+	}
+	return nil
+}
 
-	// Remove worktree using CommandExecutor
-	removeCmd := command.GitWorktreeRemove(targetWorktree.Path, force)
-	result, err = executor.Execute([]command.Command{removeCmd})
+// removeWorkdir runs backend's commands removing the worktree wt one by one, such as
+// 'git worktree remove', and deletes the files the backend leaves behind. A locked
+// worktree is unlocked first, which git requires even with --force.
+func removeWorkdir(executor command.Executor, backend vcs.Backend, wt *git.Worktree, force bool) error {
+	path := wt.Path
+	if wt.Locked {
+		if err := executeLockCommand(executor, command.GitWorktreeUnlock(path)); err != nil {
+			return errors.WorktreeRemovalFailed(path, err)
+		}
+	}
+	for _, removeCmd := range backend.RemoveWorkdir(path, force) {
+		result, err := executor.Execute([]command.Command{removeCmd})
+		if err != nil {
+			return errors.WorktreeRemovalFailed(path, err)
+		}
+		if len(result.Results) > 0 && result.Results[0].Error != nil {
+			gitOutput := result.Results[0].Output
+			if gitOutput != "" {
+				combinedError := fmt.Errorf("%w: %s", result.Results[0].Error, gitOutput)
+				return errors.WorktreeRemovalFailed(path, combinedError)
+			}
+			return errors.WorktreeRemovalFailed(path, result.Results[0].Error)
+		}
+	}
+	if backend.KeepsFiles() {
+		if err := os.RemoveAll(path); err != nil {
+			return errors.WorktreeRemovalFailed(path, err)
+		}
+	}
+	return nil
+}
+
+// cleanUpAfterRemoval removes what a removed worktree leaves behind: empty parent
+// directories and its runtime directory. It then updates the worktree map.
+func cleanUpAfterRemoval(
+	w io.Writer, executor command.Executor, worktrees []git.Worktree, worktreePath string,
+	cleanupRuntimeDir func() error,
+) error {
+	pruneEmptyWorktreeParents(worktrees, worktreePath)
+	_ = cleanupRuntimeDir()
+	_ = unregisterWorktree(findMainWorktreePath(worktrees), worktreePath)
+	return syncWorktreeMap(w, executor, findMainWorktreePath(worktrees))
+}
+
+// loadRemoveConfig loads the configuration for removing the worktree at workTreePath,
+// with the overlays of its branch and the profile it was created with, and returns it with
+// the main worktree's path and the branch.
+func loadRemoveConfig(
+	worktrees []git.Worktree, workTreePath string,
+) (cfg *config.Config, mainRepoPath, branch string, err error) {
+	for _, wt := range worktrees {
+		if wt.IsMain {
+			mainRepoPath = wt.Path
+		}
+		if wt.Path == workTreePath {
+			branch = wt.Branch
+		}
+	}
+
+	cfg, err = config.LoadConfig(mainRepoPath, branch)
 	if err != nil {
-		return errors.WorktreeRemovalFailed(targetWorktree.Path, err)
+		return nil, "", "", errors.ConfigLoadFailed(filepath.Join(mainRepoPath, config.ConfigFileName), err)
 	}
-	if len(result.Results) > 0 && result.Results[0].Error != nil {
-		gitOutput := result.Results[0].Output
-		if gitOutput != "" {
-			combinedError := fmt.Errorf("%w: %s", result.Results[0].Error, gitOutput)
-			return errors.WorktreeRemovalFailed(targetWorktree.Path, combinedError)
+	return withRegisteredProfile(cfg, mainRepoPath, workTreePath), mainRepoPath, branch, nil
+}
+
+// executePreRemoveHooks runs the configured pre_remove hooks before a worktree is deleted.
+// With force, a failing hook is reported as a warning and removal continues.
+func executePreRemoveHooks(
+	w io.Writer, cfg *config.Config, mainRepoPath, workTreePath, worktreeName string, force bool,
+) error {
+	if !cfg.HasPreRemoveHooks() {
+		return nil
+	}
+
+	if _, err := fmt.Fprintln(w, "Executing pre-remove hooks..."); err != nil {
+		return err
+	}
+
+	executor := hooks.NewExecutor(cfg, mainRepoPath)
+	if err := executor.ExecutePreRemoveHooks(w, workTreePath); err != nil {
+		if !force {
+			return errors.PreRemoveHookFailed(worktreeName, err)
 		}
-		return errors.WorktreeRemovalFailed(targetWorktree.Path, result.Results[0].Error)
+		return writeWarning(w, errors.CodeWarnPreRemoveHookFailed,
+			"Pre-remove hook failed: %v\nContinuing because --force was given", err)
 	}
-	if _, err := fmt.Fprintf(w, "Removed worktree '%s' at %s\n", worktreeName, targetWorktree.Path); err != nil {
+
+	_, err := fmt.Fprintln(w, "✓ All pre-remove hooks executed successfully")
+	return err
+}
+
+// executePostRemoveHooks runs the configured post_remove hooks in the main worktree once
+// the worktree at removedPath is gone. A failing hook is reported as a warning.
+func executePostRemoveHooks(w io.Writer, cfg *config.Config, mainRepoPath, removedPath, removedBranch string) error {
+	if !cfg.HasPostRemoveHooks() {
+		return nil
+	}
+
+	if _, err := fmt.Fprintln(w, "Executing post-remove hooks..."); err != nil {
 		return err
 	}
 
-	// Remove branch if requested
-	if withBranch && targetWorktree.Branch != "" {
-		if err := removeBranchWithCommandExecutor(w, executor, targetWorktree.Branch, forceBranch); err != nil {
-			return err
+	if removedBranch == detachedKeyword {
+		removedBranch = ""
+	}
+	executor := hooks.NewExecutor(cfg, mainRepoPath)
+	if err := executor.ExecutePostRemoveHooks(w, removedPath, removedBranch); err != nil {
+		return writeWarning(w, errors.CodeWarnPostRemoveHookFailed, "Post-remove hook failed: %v", err)
+	}
+
+	_, err := fmt.Fprintln(w, "✓ All post-remove hooks executed successfully")
+	return err
+}
+
+// findRemoveTarget finds the worktree to remove by name, falling back to its path, its ID,
+// and then to a fuzzy match, which is confirmed first when it is a loose one (see
+// fuzzyResolveWorktreeToChange). The main worktree is never a candidate.
+func findRemoveTarget(worktrees []git.Worktree, worktreeName, cwd string) (*git.Worktree, error) {
+	targetWorktree, err := findTargetWorktreeFromList(worktrees, worktreeName)
+	if err == nil {
+		return targetWorktree, nil
+	}
+	if byPath := findTargetWorktreeByPath(worktrees, worktreeName, cwd); byPath != nil {
+		return byPath, nil
+	}
+
+	mainWorktreePath := findMainWorktreePath(worktrees)
+	cfg, cfgErr := config.LoadConfig(mainWorktreePath, "")
+	if cfgErr != nil {
+		cfg = nil // isWorktreeManaged falls back to the default base_dir
+	}
+	removable := make([]git.Worktree, 0, len(worktrees))
+	for i := range worktrees {
+		if !worktrees[i].IsMain {
+			removable = append(removable, worktrees[i])
 		}
 	}
+	if byID, idErr := resolveWorktreeID(worktreeName, removable, cfg, mainWorktreePath); byID != nil || idErr != nil {
+		return byID, idErr
+	}
+	byFuzzy, fuzzyErr := fuzzyResolveWorktreeToChange(worktreeName, removable, cfg, mainWorktreePath, "remove")
+	if fuzzyErr != nil {
+		return nil, fuzzyErr
+	}
+	if byFuzzy != nil {
+		return byFuzzy, nil
+	}
+	return nil, err
+}
+
+// findTargetWorktreeByPath finds the managed worktree at path, which may be relative to cwd.
+func findTargetWorktreeByPath(worktrees []git.Worktree, path, cwd string) *git.Worktree {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cwd, path)
+	}
+	path = filepath.Clean(path)
 
+	mainWorktreePath := findMainWorktreePath(worktrees)
+	cfg, err := config.LoadConfig(mainWorktreePath, "")
+	if err != nil {
+		cfg = nil // isWorktreeManaged falls back to the default base_dir
+	}
+	for i := range worktrees {
+		wt := &worktrees[i]
+		if wt.IsMain || filepath.Clean(wt.Path) != path {
+			continue
+		}
+		if isWorktreeManaged(wt.Path, cfg, mainWorktreePath, wt.IsMain) {
+			return wt
+		}
+	}
 	return nil
 }
 
+// pruneEmptyWorktreeParents removes the directories left empty between a removed worktree
+// and the base_dir containing it, e.g. worktrees/feature/ after removing feature/auth.
+// base_dir itself is kept. Pruning is best effort: removal already succeeded.
+func pruneEmptyWorktreeParents(worktrees []git.Worktree, worktreePath string) {
+	mainWorktreePath, branch := "", ""
+	for i := range worktrees {
+		if worktrees[i].IsMain {
+			mainWorktreePath = worktrees[i].Path
+		}
+		if worktrees[i].Path == worktreePath {
+			branch = worktrees[i].Branch
+		}
+	}
+	cfg, err := config.LoadConfig(mainWorktreePath, branch)
+	if err != nil {
+		return
+	}
+
+	absWorktreePath, err := filepath.Abs(worktreePath)
+	if err != nil {
+		return
+	}
+	baseDir := ""
+	for _, dir := range cfg.BaseDirs() {
+		scoped := *cfg
+		scoped.Defaults.BaseDir = dir
+		resolved := filepath.Clean(scoped.ResolveWorktreePath(mainWorktreePath, ""))
+		if isWithinBaseDir(absWorktreePath, resolved) && len(resolved) > len(baseDir) {
+			baseDir = resolved
+		}
+	}
+	if baseDir == "" {
+		return
+	}
+
+	for dir := filepath.Dir(absWorktreePath); dir != baseDir && isPathWithin(baseDir, dir); dir = filepath.Dir(dir) {
+		// os.Remove only deletes empty directories
+		if os.Remove(dir) != nil {
+			return
+		}
+	}
+}
+
+// runtimeDirCleanup returns the function that deletes the worktree's runtime directory when
+// defaults.runtime_dir keeps it outside the worktree. Cleanup is best effort, like pruning.
+func runtimeDirCleanup(worktrees []git.Worktree, worktreePath string) func() error {
+	cfg, err := config.LoadConfig(findMainWorktreePath(worktrees), "")
+	if err != nil {
+		return func() error { return nil }
+	}
+	return runtimedir.Cleanup(cfg, worktreePath)
+}
+
 func validateRemoveInput(worktreeName string, withBranch, forceBranch bool) error {
 	if worktreeName == "" {
 		return errors.WorktreeNameRequiredForRemove()
 	}
 	if forceBranch && !withBranch {
-		return fmt.Errorf("--force-branch requires --with-branch")
+		return errors.InvalidArguments("--force-branch requires --with-branch")
 	}
 	return nil
 }
@@ -188,10 +477,11 @@ func isPathWithin(basePath, targetPath string) bool {
 func removeBranchWithCommandExecutor(
 	w io.Writer,
 	executor command.Executor,
+	backend vcs.Backend,
 	branchName string,
 	forceBranch bool,
 ) error {
-	branchCmd := command.GitBranchDelete(branchName, forceBranch)
+	branchCmd := backend.DeleteBranch(branchName, forceBranch)
 	result, err := executor.Execute([]command.Command{branchCmd})
 	if err != nil {
 		return errors.BranchRemovalFailed(branchName, err, forceBranch)
@@ -222,7 +512,7 @@ func findTargetWorktreeFromList(worktrees []git.Worktree, worktreeName string) (
 	}
 
 	// Load config for consistent worktree naming
-	cfg, err := config.LoadConfig(mainWorktreePath)
+	cfg, err := config.LoadConfig(mainWorktreePath, "")
 	if err != nil {
 		// If config can't be loaded, use default config
 		cfg = &config.Config{
@@ -320,7 +610,7 @@ func getWorktreesForRemove(w io.Writer) error {
 	}
 
 	// Load config
-	cfg, err := config.LoadConfig(mainRepoPath)
+	cfg, err := config.LoadConfig(mainRepoPath, "")
 	if err != nil {
 		return err
 	}
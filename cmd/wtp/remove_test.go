@@ -6,12 +6,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/urfave/cli/v3"
 
 	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/vcs"
 )
 
 // ===== Command Structure Tests =====
@@ -219,7 +222,7 @@ func TestRemoveCommand_CommandConstruction(t *testing.T) {
 			forceFlag := tt.flags["force"] == true
 			branchFlag := tt.flags["branch"] == true
 			err := removeCommandWithCommandExecutor(
-				cmd, &buf, mockExec, "/test/repo", tt.worktreeName, forceFlag, branchFlag, false,
+				cmd, &buf, mockExec, vcs.Git{}, "/test/repo", tt.worktreeName, forceFlag, branchFlag, false,
 			)
 
 			assert.NoError(t, err)
@@ -286,7 +289,9 @@ func TestRemoveCommand_SuccessMessage(t *testing.T) {
 			var buf bytes.Buffer
 
 			branchFlag := tt.branchFlag
-			err := removeCommandWithCommandExecutor(cmd, &buf, mockExec, "/test/repo", tt.worktreeName, false, branchFlag, false)
+			err := removeCommandWithCommandExecutor(
+				cmd, &buf, mockExec, vcs.Git{}, "/test/repo", tt.worktreeName, false, branchFlag, false,
+			)
 
 			assert.NoError(t, err)
 			output := buf.String()
@@ -363,7 +368,9 @@ func TestRemoveCommand_WorktreeNotFound(t *testing.T) {
 	cmd := createRemoveTestCLICommand(map[string]any{}, []string{"nonexistent"})
 	var buf bytes.Buffer
 
-	err := removeCommandWithCommandExecutor(cmd, &buf, mockExec, "/test/repo", "nonexistent", false, false, false)
+	err := removeCommandWithCommandExecutor(
+		cmd, &buf, mockExec, vcs.Git{}, "/test/repo", "nonexistent", false, false, false,
+	)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "worktree 'nonexistent' not found")
@@ -383,7 +390,7 @@ func TestRemoveCommand_WorktreeNotFound_ShowsConsistentNames(t *testing.T) {
 	cmd := createRemoveTestCLICommand(map[string]any{}, []string{"nonexistent"})
 	var buf bytes.Buffer
 
-	err := removeCommandWithCommandExecutor(cmd, &buf, mockExec, "/repo", "nonexistent", false, false, false)
+	err := removeCommandWithCommandExecutor(cmd, &buf, mockExec, vcs.Git{}, "/repo", "nonexistent", false, false, false)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "worktree 'nonexistent' not found")
@@ -427,7 +434,7 @@ func TestRemoveCommand_FailsWhenRemovingCurrentWorktree(t *testing.T) {
 			cmd := createRemoveTestCLICommand(map[string]any{}, []string{"feature/foo"})
 			var buf bytes.Buffer
 
-			err := removeCommandWithCommandExecutor(cmd, &buf, mockExec, tt.cwd, "feature/foo", false, false, false)
+			err := removeCommandWithCommandExecutor(cmd, &buf, mockExec, vcs.Git{}, tt.cwd, "feature/foo", false, false, false)
 
 			assert.Error(t, err)
 			assert.Contains(t, err.Error(), "cannot remove worktree 'feature/foo'")
@@ -441,6 +448,300 @@ func TestRemoveCommand_FailsWhenRemovingCurrentWorktree(t *testing.T) {
 	}
 }
 
+func setupPreRemoveHookRepo(t *testing.T, hookCommand string) (mainPath, worktreePath, worktreeList string) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	root := t.TempDir()
+	mainPath = filepath.Join(root, "repo")
+	worktreePath = filepath.Join(root, "worktrees", "feature", "foo")
+	assert.NoError(t, os.MkdirAll(mainPath, 0o755))
+	assert.NoError(t, os.MkdirAll(worktreePath, 0o755))
+
+	cfg := fmt.Sprintf("defaults:\n  base_dir: ../worktrees\nhooks:\n  pre_remove:\n"+
+		"    - type: command\n      command: %q\n", hookCommand)
+	assert.NoError(t, os.WriteFile(filepath.Join(mainPath, ".wtp.yml"), []byte(cfg), 0o600))
+
+	worktreeList = fmt.Sprintf(
+		"worktree %s\nHEAD abc123\nbranch refs/heads/main\n\n"+
+			"worktree %s\nHEAD def456\nbranch refs/heads/feature/foo\n\n",
+		mainPath, worktreePath,
+	)
+	return mainPath, worktreePath, worktreeList
+}
+
+func TestRemoveCommand_RunsPreRemoveHooks(t *testing.T) {
+	mainPath, worktreePath, worktreeList := setupPreRemoveHookRepo(t, "pwd > \"$GIT_WTP_REPO_ROOT/removed.txt\"")
+
+	mockExec := &mockRemoveCommandExecutor{
+		results: []command.Result{{Output: worktreeList}, {Output: ""}},
+	}
+	cmd := createRemoveTestCLICommand(map[string]any{}, []string{"feature/foo"})
+	var buf bytes.Buffer
+
+	err := removeCommandWithCommandExecutor(cmd, &buf, mockExec, vcs.Git{}, mainPath, "feature/foo", false, false, false)
+
+	assert.NoError(t, err)
+	content, readErr := os.ReadFile(filepath.Join(mainPath, "removed.txt"))
+	assert.NoError(t, readErr)
+	resolvedWorktree, _ := filepath.EvalSymlinks(worktreePath)
+	assert.Contains(t, []string{worktreePath, resolvedWorktree}, strings.TrimSpace(string(content)),
+		"hook should run inside the worktree being removed")
+
+	output := buf.String()
+	assert.Less(t, strings.Index(output, "Executing pre-remove hooks"), strings.Index(output, "Removed worktree"))
+	assert.Len(t, mockExec.executedCommands, 2)
+}
+
+func TestRemoveCommand_PreRemoveHookFailureAbortsRemoval(t *testing.T) {
+	mainPath, _, worktreeList := setupPreRemoveHookRepo(t, "exit 3")
+
+	mockExec := &mockRemoveCommandExecutor{
+		results: []command.Result{{Output: worktreeList}, {Output: ""}},
+	}
+	cmd := createRemoveTestCLICommand(map[string]any{}, []string{"feature/foo"})
+	var buf bytes.Buffer
+
+	err := removeCommandWithCommandExecutor(cmd, &buf, mockExec, vcs.Git{}, mainPath, "feature/foo", false, false, false)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "the worktree was not removed")
+	assert.Len(t, mockExec.executedCommands, 1, "git worktree remove must not run")
+}
+
+func TestRemoveCommand_PreRemoveHookFailureWithForce(t *testing.T) {
+	mainPath, _, worktreeList := setupPreRemoveHookRepo(t, "exit 3")
+
+	mockExec := &mockRemoveCommandExecutor{
+		results: []command.Result{{Output: worktreeList}, {Output: ""}},
+	}
+	cmd := createRemoveTestCLICommand(map[string]any{}, []string{"feature/foo"})
+	var buf bytes.Buffer
+
+	err := removeCommandWithCommandExecutor(cmd, &buf, mockExec, vcs.Git{}, mainPath, "feature/foo", true, false, false)
+
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "Warning [WTP7005]: Pre-remove hook failed")
+	assert.Contains(t, buf.String(), "Removed worktree")
+	assert.Len(t, mockExec.executedCommands, 2)
+}
+
+func TestRemoveCommand_LockedWorktree(t *testing.T) {
+	mainPath, worktreePath, worktreeList := setupPreRemoveHookRepo(t, "true")
+	worktreeList = strings.Replace(worktreeList, "branch refs/heads/feature/foo\n",
+		"branch refs/heads/feature/foo\nlocked on a USB drive\n", 1)
+
+	t.Run("refused without force", func(t *testing.T) {
+		mockExec := &mockRemoveCommandExecutor{results: []command.Result{{Output: worktreeList}}}
+		var buf bytes.Buffer
+
+		err := removeCommandWithCommandExecutor(nil, &buf, mockExec, vcs.Git{}, mainPath, "feature/foo", false, false, false)
+
+		assert.Error(t, err)
+		code, _ := errors.CodeOf(err)
+		assert.Equal(t, errors.CodeWorktreeLocked, code)
+		assert.Contains(t, err.Error(), "Reason: on a USB drive")
+		assert.Len(t, mockExec.executedCommands, 1, "nothing may run but 'git worktree list'")
+		assert.NotContains(t, buf.String(), "pre-remove")
+	})
+
+	t.Run("unlocked and removed with force", func(t *testing.T) {
+		mockExec := &mockRemoveCommandExecutor{results: []command.Result{{Output: worktreeList}}}
+		var buf bytes.Buffer
+
+		err := removeCommandWithCommandExecutor(nil, &buf, mockExec, vcs.Git{}, mainPath, "feature/foo", true, false, false)
+
+		assert.NoError(t, err)
+		if assert.GreaterOrEqual(t, len(mockExec.executedCommands), 3) {
+			assert.Equal(t, command.GitWorktreeUnlock(worktreePath), mockExec.executedCommands[1])
+			assert.Equal(t, command.GitWorktreeRemove(worktreePath, true), mockExec.executedCommands[2])
+		}
+	})
+}
+
+func TestRemoveCommand_RunsPostRemoveHooks(t *testing.T) {
+	mainPath, worktreePath, worktreeList := setupPreRemoveHookRepo(t, "true")
+	cfg := "defaults:\n  base_dir: ../worktrees\nhooks:\n  post_remove:\n" +
+		"    - type: command\n      command: 'echo \"$GIT_WTP_REMOVED_BRANCH $GIT_WTP_REMOVED_PATH\" > removed.txt'\n" +
+		"    - type: command\n      command: exit 3\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(mainPath, ".wtp.yml"), []byte(cfg), 0o600))
+
+	mockExec := &mockRemoveCommandExecutor{
+		results: []command.Result{{Output: worktreeList}, {Output: ""}},
+	}
+	cmd := createRemoveTestCLICommand(map[string]any{}, []string{"feature/foo"})
+	var buf bytes.Buffer
+
+	err := removeCommandWithCommandExecutor(cmd, &buf, mockExec, vcs.Git{}, mainPath, "feature/foo", false, false, false)
+
+	assert.NoError(t, err, "a failing post_remove hook only warns")
+	content, readErr := os.ReadFile(filepath.Join(mainPath, "removed.txt"))
+	assert.NoError(t, readErr, "post_remove hooks run in the main worktree")
+	assert.Equal(t, "feature/foo "+worktreePath+"\n", string(content))
+
+	output := buf.String()
+	assert.Less(t, strings.Index(output, "Removed worktree"), strings.Index(output, "Executing post-remove hooks"))
+	assert.Contains(t, output, "Warning [WTP7015]: Post-remove hook failed")
+}
+
+func TestRemoveCommand_JujutsuWorkspace(t *testing.T) {
+	mainPath, worktreePath, _ := setupPreRemoveHookRepo(t, "true")
+	assert.NoError(t, os.WriteFile(filepath.Join(worktreePath, "notes.txt"), []byte("wip\n"), 0o600))
+
+	mockExec := &mockRemoveCommandExecutor{
+		results: []command.Result{{Output: "default\tabc123\tmain\t\n../worktrees/feature/foo\tdef456\t\tfeature/foo\n"}},
+	}
+	cmd := createRemoveTestCLICommand(map[string]any{}, []string{"feature/foo"})
+	var buf bytes.Buffer
+
+	backend := vcs.NewJujutsu(mainPath)
+	err := removeCommandWithCommandExecutor(cmd, &buf, mockExec, backend, mainPath, "feature/foo", false, true, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []command.Command{
+		backend.ListWorkdirs(),
+		{Name: "jj", Args: []string{"status"}, WorkDir: worktreePath},
+		{Name: "jj", Args: []string{"workspace", "forget", "../worktrees/feature/foo"}, WorkDir: mainPath},
+		{Name: "jj", Args: []string{"bookmark", "delete", "feature/foo"}, WorkDir: mainPath},
+	}, mockExec.executedCommands)
+	assert.NoDirExists(t, worktreePath, "the files jj leaves behind are deleted")
+	assert.Contains(t, buf.String(), "Removed branch 'feature/foo'")
+}
+
+func TestRemoveCommand_ByPath(t *testing.T) {
+	mainPath, worktreePath, worktreeList := setupPreRemoveHookRepo(t, "true")
+
+	tests := []struct {
+		name string
+		arg  string
+	}{
+		{name: "absolute path", arg: worktreePath},
+		{name: "path relative to cwd", arg: filepath.Join("..", "worktrees", "feature", "foo")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExec := &mockRemoveCommandExecutor{
+				results: []command.Result{{Output: worktreeList}, {Output: ""}},
+			}
+			cmd := createRemoveTestCLICommand(map[string]any{}, []string{tt.arg})
+			var buf bytes.Buffer
+
+			err := removeCommandWithCommandExecutor(cmd, &buf, mockExec, vcs.Git{}, mainPath, tt.arg, false, false, false)
+
+			assert.NoError(t, err)
+			assert.Equal(t, command.GitWorktreeRemove(worktreePath, false), mockExec.executedCommands[1])
+		})
+	}
+}
+
+func TestRemoveCommand_FuzzyName(t *testing.T) {
+	mainPath, worktreePath, worktreeList := setupPreRemoveHookRepo(t, "true")
+
+	t.Run("unique match is removed", func(t *testing.T) {
+		mockExec := &mockRemoveCommandExecutor{
+			results: []command.Result{{Output: worktreeList}, {Output: ""}},
+		}
+		cmd := createRemoveTestCLICommand(map[string]any{}, []string{"fo"})
+		var buf bytes.Buffer
+
+		err := removeCommandWithCommandExecutor(cmd, &buf, mockExec, vcs.Git{}, mainPath, "fo", false, false, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, command.GitWorktreeRemove(worktreePath, false), mockExec.executedCommands[1])
+		assert.Contains(t, buf.String(), "Removed worktree 'feature/foo' at "+worktreePath,
+			"the worktree removed is named, not the query")
+	})
+
+	t.Run("loose match is not removed without a terminal", func(t *testing.T) {
+		withPickerTerminal(t, false, "")
+		mockExec := &mockRemoveCommandExecutor{results: []command.Result{{Output: worktreeList}}}
+		cmd := createRemoveTestCLICommand(map[string]any{"force": true}, []string{"ffo"})
+		var buf bytes.Buffer
+
+		err := removeCommandWithCommandExecutor(cmd, &buf, mockExec, vcs.Git{}, mainPath, "ffo", true, false, false)
+
+		code, _ := errors.CodeOf(err)
+		assert.Equal(t, errors.CodeUnconfirmedWorktreeMatch, code)
+		assert.Len(t, mockExec.executedCommands, 1, "only the worktree list is read")
+		assert.DirExists(t, worktreePath)
+	})
+
+	t.Run("loose match is removed once confirmed", func(t *testing.T) {
+		prompt := withPickerTerminal(t, true, "y\n")
+		mockExec := &mockRemoveCommandExecutor{
+			results: []command.Result{{Output: worktreeList}, {Output: ""}},
+		}
+		cmd := createRemoveTestCLICommand(map[string]any{}, []string{"oo"})
+		var buf bytes.Buffer
+
+		err := removeCommandWithCommandExecutor(cmd, &buf, mockExec, vcs.Git{}, mainPath, "oo", false, false, false)
+
+		assert.NoError(t, err)
+		assert.Contains(t, prompt.String(), "'oo' matches feature/foo; remove it? [y/N]: ")
+		assert.Equal(t, command.GitWorktreeRemove(worktreePath, false), mockExec.executedCommands[1])
+	})
+
+	t.Run("main worktree is never matched", func(t *testing.T) {
+		mockExec := &mockRemoveCommandExecutor{results: []command.Result{{Output: worktreeList}}}
+		cmd := createRemoveTestCLICommand(map[string]any{}, []string{"mai"})
+		var buf bytes.Buffer
+
+		err := removeCommandWithCommandExecutor(cmd, &buf, mockExec, vcs.Git{}, mainPath, "mai", false, false, false)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+		assert.Len(t, mockExec.executedCommands, 1)
+	})
+}
+
+func TestRemoveCommand_ByPathRejectsUnmanagedWorktree(t *testing.T) {
+	mainPath, _, _ := setupPreRemoveHookRepo(t, "true")
+	outsidePath := filepath.Join(filepath.Dir(mainPath), "elsewhere")
+	worktreeList := fmt.Sprintf("worktree %s\nHEAD abc123\nbranch refs/heads/main\n\n"+
+		"worktree %s\nHEAD def456\nbranch refs/heads/other\n\n", mainPath, outsidePath)
+
+	mockExec := &mockRemoveCommandExecutor{results: []command.Result{{Output: worktreeList}}}
+	cmd := createRemoveTestCLICommand(map[string]any{}, []string{outsidePath})
+	var buf bytes.Buffer
+
+	err := removeCommandWithCommandExecutor(cmd, &buf, mockExec, vcs.Git{}, mainPath, outsidePath, false, false, false)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+	assert.Len(t, mockExec.executedCommands, 1)
+}
+
+func TestPruneEmptyWorktreeParents(t *testing.T) {
+	t.Run("removes empty parents up to base_dir", func(t *testing.T) {
+		mainPath, worktreePath, _ := setupPreRemoveHookRepo(t, "true")
+		worktrees := parseWorktreesFromOutput(fmt.Sprintf(
+			"worktree %s\nHEAD abc123\nbranch refs/heads/main\n\n"+
+				"worktree %s\nHEAD def456\nbranch refs/heads/feature/foo\n\n", mainPath, worktreePath))
+		assert.NoError(t, os.RemoveAll(worktreePath))
+
+		pruneEmptyWorktreeParents(worktrees, worktreePath)
+
+		baseDir := filepath.Join(filepath.Dir(mainPath), "worktrees")
+		assert.NoDirExists(t, filepath.Join(baseDir, "feature"))
+		assert.DirExists(t, baseDir, "base_dir itself is kept")
+	})
+
+	t.Run("keeps parents that still hold other worktrees", func(t *testing.T) {
+		mainPath, worktreePath, _ := setupPreRemoveHookRepo(t, "true")
+		sibling := filepath.Join(filepath.Dir(worktreePath), "bar")
+		assert.NoError(t, os.MkdirAll(sibling, 0o755))
+		worktrees := parseWorktreesFromOutput(fmt.Sprintf(
+			"worktree %s\nHEAD abc123\nbranch refs/heads/main\n\n"+
+				"worktree %s\nHEAD def456\nbranch refs/heads/feature/foo\n\n", mainPath, worktreePath))
+		assert.NoError(t, os.RemoveAll(worktreePath))
+
+		pruneEmptyWorktreeParents(worktrees, worktreePath)
+
+		assert.DirExists(t, sibling)
+	})
+}
+
 func TestRemoveCommand_ExecutionError(t *testing.T) {
 	mockExec := &mockRemoveCommandExecutor{
 		results: []command.Result{
@@ -457,7 +758,9 @@ func TestRemoveCommand_ExecutionError(t *testing.T) {
 	cmd := createRemoveTestCLICommand(map[string]any{}, []string{"feature-branch"})
 	var buf bytes.Buffer
 
-	err := removeCommandWithCommandExecutor(cmd, &buf, mockExec, "/test/repo", "feature-branch", false, false, false)
+	err := removeCommandWithCommandExecutor(
+		cmd, &buf, mockExec, vcs.Git{}, "/test/repo", "feature-branch", false, false, false,
+	)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to remove worktree")
@@ -531,7 +834,7 @@ func TestRemoveCommand_DirtyWorktree(t *testing.T) {
 			var buf bytes.Buffer
 
 			err := removeCommandWithCommandExecutor(
-				cmd, &buf, mockExec, "/test/repo", "dirty-feature", tt.forceFlag, false, false)
+				cmd, &buf, mockExec, vcs.Git{}, "/test/repo", "dirty-feature", tt.forceFlag, false, false)
 
 			if tt.shouldSucceed {
 				assert.NoError(t, err)
@@ -627,7 +930,7 @@ func TestRemoveCommand_BranchRemovalWithUnmergedCommits(t *testing.T) {
 			var buf bytes.Buffer
 
 			err := removeCommandWithCommandExecutor(
-				cmd, &buf, mockExec, "/test/repo", "feature-unmerged", false, true, tt.forceBranchFlag)
+				cmd, &buf, mockExec, vcs.Git{}, "/test/repo", "feature-unmerged", false, true, tt.forceBranchFlag)
 
 			if tt.shouldSucceed {
 				assert.NoError(t, err)
@@ -695,7 +998,9 @@ func TestRemoveCommand_InternationalCharacters(t *testing.T) {
 			cmd := createRemoveTestCLICommand(map[string]any{}, []string{worktreeName})
 			var buf bytes.Buffer
 
-			err := removeCommandWithCommandExecutor(cmd, &buf, mockExec, "/test/repo", worktreeName, false, false, false)
+			err := removeCommandWithCommandExecutor(
+				cmd, &buf, mockExec, vcs.Git{}, "/test/repo", worktreeName, false, false, false,
+			)
 
 			assert.NoError(t, err)
 			assert.Contains(t, buf.String(), "Removed worktree")
@@ -724,7 +1029,9 @@ func TestRemoveCommand_PathWithSpaces(t *testing.T) {
 	cmd := createRemoveTestCLICommand(map[string]any{}, []string{"feature branch"})
 	var buf bytes.Buffer
 
-	err := removeCommandWithCommandExecutor(cmd, &buf, mockExec, "/path/to/main", "feature branch", false, false, false)
+	err := removeCommandWithCommandExecutor(
+		cmd, &buf, mockExec, vcs.Git{}, "/path/to/main", "feature branch", false, false, false,
+	)
 
 	assert.NoError(t, err)
 	// Verify the correct path was passed to git command
@@ -780,7 +1087,7 @@ branch refs/heads/test-feature
 			cmd := createRemoveTestCLICommand(map[string]any{}, []string{tt.input})
 			var buf bytes.Buffer
 
-			err := removeCommandWithCommandExecutor(cmd, &buf, mockExec, "/test/repo", tt.input, false, false, false)
+			err := removeCommandWithCommandExecutor(cmd, &buf, mockExec, vcs.Git{}, "/test/repo", tt.input, false, false, false)
 
 			assert.NoError(t, err)
 			// Verify the correct worktree was targeted
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/git"
+	"github.com/satococoa/wtp/v2/internal/state"
+)
+
+// NewRenameCommand creates the rename command definition
+func NewRenameCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "rename",
+		Usage:     "Rename the branch of a worktree",
+		UsageText: "wtp rename <worktree-name> <new-branch> [--keep-path]",
+		Description: "Renames the branch checked out in a worktree with 'git branch -m' and records the " +
+			"new name in the state registry. When the worktree is at the path base_dir yields for " +
+			"the old branch, it is moved to the one it yields for the new branch, as 'wtp move' " +
+			"would; --keep-path leaves it where it is.\n\n" +
+			"Examples:\n" +
+			"  wtp rename feature/auth feature/login               # Rename and move the worktree\n" +
+			"  wtp rename feature/auth feature/login --keep-path   # Rename the branch only",
+		ArgsUsage:     "<worktree-name> <new-branch>",
+		ShellComplete: completeWorktreesForCd,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "keep-path",
+				Usage: "Keep the worktree at its path instead of moving it to the one the new branch yields",
+			},
+		},
+		Action: renameCommand,
+	}
+}
+
+func renameCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	if cmd.Args().Len() != 2 { //nolint:mnd // worktree name and branch
+		return errors.InvalidArguments(`worktree name and new branch are required
+
+Usage: wtp rename <worktree-name> <new-branch>
+
+Tip: Run 'wtp list' to see available worktrees`)
+	}
+
+	_, cfg, _, err := setupRepoAndConfig()
+	if err != nil {
+		return err
+	}
+	if err := ensureWritable(cfg, "rename branches"); err != nil {
+		return err
+	}
+
+	executor := command.NewRealExecutor()
+	return renameCommandWithCommandExecutor(w, executor, cfg, cmd.Args().Get(0), cmd.Args().Get(1), cmd.Bool("keep-path"))
+}
+
+func renameCommandWithCommandExecutor(
+	w io.Writer, executor command.Executor, cfg *config.Config, worktreeName, newBranch string, keepPath bool,
+) error {
+	worktrees, target, err := resolveWorktreeToChange(executor, cfg, worktreeName, "rename")
+	if err != nil {
+		return err
+	}
+	mainWorktreePath := findMainWorktreePath(worktrees)
+	oldBranch := target.Branch
+	if oldBranch == "" || oldBranch == detachedKeyword {
+		return fmt.Errorf("worktree '%s' has no branch checked out (detached HEAD), so there is nothing to rename",
+			worktreeName)
+	}
+
+	rename := command.GitBranchRename(oldBranch, newBranch)
+	result, err := executor.Execute([]command.Command{rename})
+	if err != nil {
+		return errors.GitCommandFailed("git branch -m "+oldBranch+" "+newBranch, err.Error())
+	}
+	if res := result.Results[0]; res.Error != nil {
+		return errors.GitCommandFailed("git branch -m "+oldBranch+" "+newBranch, res.Output)
+	}
+	renameRecordedBranch(cfg, mainWorktreePath, target.Path, newBranch)
+	if _, err := fmt.Fprintf(w, "Renamed branch '%s' to '%s' in %s\n", oldBranch, newBranch, target.Path); err != nil {
+		return err
+	}
+
+	if !keepPath {
+		if err := followRenamedBranch(w, executor, cfg, mainWorktreePath, worktrees, target, newBranch); err != nil {
+			return err
+		}
+	}
+	return syncWorktreeMap(w, executor, mainWorktreePath)
+}
+
+// renameRecordedBranch records the new branch of the worktree at path in the state
+// registry and in its provisioning record, which 'wtp relink' uses to find its path.
+func renameRecordedBranch(cfg *config.Config, mainRepoPath, path, branch string) {
+	if registryPath, ok, _ := worktreeRegistryPath(mainRepoPath); ok {
+		_ = state.Update(registryPath, func(r *state.Registry) { r.Rename(path, branch) })
+	}
+	if record, err := loadProvisionRecord(cfg, path); err == nil && record != nil {
+		record.Branch = branch
+		_ = saveProvisionRecord(cfg, path, record)
+	}
+}
+
+// followRenamedBranch moves a worktree at the path base_dir yields for its old branch to
+// the path it yields for newBranch. A worktree elsewhere stays put. The branch is already
+// renamed, so a move that cannot happen is reported with a warning.
+func followRenamedBranch(
+	w io.Writer, executor command.Executor, cfg *config.Config, mainRepoPath string,
+	worktrees []git.Worktree, target *git.Worktree, newBranch string,
+) error {
+	oldPath := cfg.ForBranch(target.Branch).ResolveWorktreePath(mainRepoPath, target.Branch)
+	if !sameDirectory(oldPath, target.Path) {
+		return nil
+	}
+	r := worktreeRelocation{
+		name: newBranch,
+		from: target.Path,
+		to:   filepath.Clean(cfg.ForBranch(newBranch).ResolveWorktreePath(mainRepoPath, newBranch)),
+	}
+	if sameDirectory(r.from, r.to) {
+		return nil
+	}
+
+	err := checkWorktreeMove(w, cfg, mainRepoPath, r)
+	if err == nil {
+		err = relocateWorktree(w, executor, cfg, mainRepoPath, worktrees, r)
+	}
+	if err != nil {
+		return writeWarning(w, errors.CodeWarnWorktreeMoveFailed,
+			"kept the worktree at %s: %v; move it with 'wtp move %s <new-path>'", r.from, err, newBranch)
+	}
+	_, err = fmt.Fprintf(w, "Moved worktree '%s': %s → %s\n", newBranch, r.from, r.to)
+	return err
+}
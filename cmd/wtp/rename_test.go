@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/state"
+)
+
+func TestNewRenameCommand(t *testing.T) {
+	cmd := NewRenameCommand()
+
+	assert.Equal(t, "rename", cmd.Name)
+	assert.NotEmpty(t, cmd.Usage)
+	assert.NotNil(t, cmd.Action)
+}
+
+// branchExists reports whether the repository at repoPath has a local branch named branch.
+func branchExists(t *testing.T, repoPath, branch string) bool {
+	t.Helper()
+	return exec.Command("git", "-C", repoPath, "rev-parse", "--verify", "-q", "refs/heads/"+branch).Run() == nil
+}
+
+func TestRenameCommand(t *testing.T) {
+	root, mainPath := setupLayoutMigrationTest(t)
+	oldA, newA := filepath.Join(root, "worktrees", "feature", "a"), filepath.Join(root, "worktrees", "feature", "c")
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+	registryPath, err := state.Path(mainPath)
+	require.NoError(t, err)
+	require.NoError(t, state.Update(registryPath, func(r *state.Registry) {
+		r.Add(&state.Worktree{Path: oldA, Branch: "feature/a"})
+	}))
+	require.NoError(t, saveProvisionRecord(cfg, oldA, &provisionRecord{Branch: "feature/a"}))
+
+	var buf bytes.Buffer
+	err = renameCommandWithCommandExecutor(&buf, command.NewRealExecutor(), cfg, "feature/a", "feature/c", false)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "Renamed branch 'feature/a' to 'feature/c' in "+oldA)
+	assert.Contains(t, buf.String(), "Moved worktree 'feature/c': "+oldA+" → "+newA)
+	assert.True(t, branchExists(t, mainPath, "feature/c"))
+	assert.False(t, branchExists(t, mainPath, "feature/a"))
+	assert.NoDirExists(t, oldA)
+
+	registered := loadWorktreeRegistry(mainPath).Find(newA)
+	require.NotNil(t, registered)
+	assert.Equal(t, "feature/c", registered.Branch)
+	record, err := loadProvisionRecord(cfg, newA)
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, "feature/c", record.Branch)
+}
+
+func TestRenameCommand_KeepPath(t *testing.T) {
+	root, mainPath := setupLayoutMigrationTest(t)
+	b := filepath.Join(root, "worktrees", "fix", "b")
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+
+	var buf bytes.Buffer
+	require.NoError(t, renameCommandWithCommandExecutor(&buf, command.NewRealExecutor(), cfg, "fix/b", "fix/d", true))
+	assert.NotContains(t, buf.String(), "Moved worktree")
+	assert.True(t, branchExists(t, mainPath, "fix/d"))
+	assert.DirExists(t, b)
+}
+
+func TestRenameCommand_OutsideLayout(t *testing.T) {
+	root, mainPath := setupLayoutMigrationTest(t)
+	// base_dir yields worktrees/shared for every branch, so fix/b is not where it yields
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees", WorktreeDir: "shared"}}
+
+	var buf bytes.Buffer
+	require.NoError(t, renameCommandWithCommandExecutor(&buf, command.NewRealExecutor(), cfg, "fix/b", "fix/d", false))
+	assert.NotContains(t, buf.String(), "Moved worktree")
+	assert.DirExists(t, filepath.Join(root, "worktrees", "fix", "b"))
+	assert.True(t, branchExists(t, mainPath, "fix/d"))
+}
+
+func TestRenameCommand_TargetTaken(t *testing.T) {
+	root, mainPath := setupLayoutMigrationTest(t)
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "worktrees", "fix", "d"), 0o755))
+
+	var buf bytes.Buffer
+	require.NoError(t, renameCommandWithCommandExecutor(&buf, command.NewRealExecutor(), cfg, "fix/b", "fix/d", false))
+	assert.Contains(t, buf.String(), "kept the worktree at "+filepath.Join(root, "worktrees", "fix", "b"))
+	assert.True(t, branchExists(t, mainPath, "fix/d"), "the branch is renamed regardless")
+}
+
+func TestRenameCommand_Refuses(t *testing.T) {
+	_, mainPath := setupLayoutMigrationTest(t)
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+	executor := command.NewRealExecutor()
+
+	var buf bytes.Buffer
+	err := renameCommandWithCommandExecutor(&buf, executor, cfg, "@", "trunk", false)
+	assert.ErrorContains(t, err, "cannot rename the main worktree")
+
+	err = renameCommandWithCommandExecutor(&buf, executor, cfg, "feature/a", "fix/b", false)
+	assert.ErrorContains(t, err, "git branch -m feature/a fix/b")
+	assert.True(t, branchExists(t, mainPath, "feature/a"))
+
+	cmd := exec.Command("git", "checkout", "-q", "--detach")
+	cmd.Dir = filepath.Join(filepath.Dir(mainPath), "worktrees", "fix", "b")
+	require.NoError(t, cmd.Run())
+	err = renameCommandWithCommandExecutor(&buf, executor, cfg, "fix/b", "fix/d", false)
+	assert.ErrorContains(t, err, "no branch checked out")
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/filelock"
+	"github.com/satococoa/wtp/v2/internal/state"
+)
+
+const (
+	// noLockEnvVar turns the repository's operation lock off, like --no-lock.
+	noLockEnvVar = "WTP_NO_LOCK"
+	// lockHeldEnvVar names the operation lock the wtp command that runs a hook holds, so
+	// that wtp commands the hook runs do not wait for their own parent.
+	lockHeldEnvVar = "WTP_LOCK_HELD"
+)
+
+// Variables to allow mocking in tests
+var repoLockPollInterval = 200 * time.Millisecond
+
+// enableNoLock exports --no-lock as WTP_NO_LOCK, so it also applies to wtp commands that
+// hooks or 'wtp open --create' run.
+func enableNoLock(_ context.Context, _ *cli.Command, noLock bool) error {
+	if !noLock {
+		return nil
+	}
+	return os.Setenv(noLockEnvVar, "true")
+}
+
+// acquireRepoLock takes the operation lock of the repository at mainRepoPath, so that
+// commands creating or removing worktrees, and their hooks, do not race each other. It
+// waits for --lock-timeout, else defaults.lock_timeout, and then fails. The lock is an
+// advisory lock the operating system releases when wtp exits, so a crash never leaves it
+// held. release gives it up.
+func acquireRepoLock(
+	w io.Writer, cmd *cli.Command, cfg *config.Config, mainRepoPath, operation string,
+) (release func(), err error) {
+	noop := func() {}
+	if noLock, _ := strconv.ParseBool(os.Getenv(noLockEnvVar)); noLock {
+		return noop, nil
+	}
+	path, err := state.OperationLockPath(mainRepoPath)
+	if err != nil {
+		return nil, err
+	}
+	if os.Getenv(lockHeldEnvVar) == path {
+		return noop, nil
+	}
+
+	timeout := cmd.Root().Duration("lock-timeout")
+	if timeout <= 0 {
+		timeout = cfg.LockTimeout()
+	}
+	unlock, err := waitForRepoLock(w, path, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if unlock == nil {
+		return nil, errors.RepositoryBusy(operation, timeout, path)
+	}
+	if err := os.Setenv(lockHeldEnvVar, path); err != nil {
+		_ = unlock()
+		return nil, err
+	}
+	return func() {
+		_ = os.Unsetenv(lockHeldEnvVar)
+		_ = unlock()
+	}, nil
+}
+
+// waitForRepoLock tries to take the lock at path until timeout passes, saying once that
+// it waits; unlock is nil when it timed out.
+func waitForRepoLock(w io.Writer, path string, timeout time.Duration) (unlock func() error, err error) {
+	deadline := time.Now().Add(timeout)
+	waiting := false
+	for {
+		unlock, ok, err := filelock.TryLock(path)
+		if err != nil || ok {
+			return unlock, err
+		}
+		if time.Now().After(deadline) {
+			return nil, nil
+		}
+		if !waiting {
+			if _, err := fmt.Fprintf(w, "Waiting for another wtp command in this repository to finish "+
+				"(up to %s)...\n", timeout); err != nil {
+				return nil, err
+			}
+			waiting = true
+		}
+		time.Sleep(repoLockPollInterval)
+	}
+}
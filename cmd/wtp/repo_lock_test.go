@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/filelock"
+	"github.com/satococoa/wtp/v2/internal/state"
+)
+
+func setupRepoLockTest(t *testing.T) (repo, lockPath string) {
+	t.Helper()
+	repo = t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(repo, ".git"), 0o755))
+	t.Setenv(noLockEnvVar, "")
+	t.Setenv(lockHeldEnvVar, "")
+
+	original := repoLockPollInterval
+	repoLockPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { repoLockPollInterval = original })
+
+	lockPath, err := state.OperationLockPath(repo)
+	require.NoError(t, err)
+	return repo, lockPath
+}
+
+func TestAcquireRepoLock_HoldsLockUntilReleased(t *testing.T) {
+	repo, lockPath := setupRepoLockTest(t)
+
+	release, err := acquireRepoLock(&bytes.Buffer{}, &cli.Command{}, &config.Config{}, repo, "wtp add")
+	require.NoError(t, err)
+	assert.Equal(t, lockPath, os.Getenv(lockHeldEnvVar), "hooks must see the lock they run under")
+
+	held, err := filelock.Held(lockPath)
+	require.NoError(t, err)
+	assert.True(t, held)
+
+	release()
+	held, err = filelock.Held(lockPath)
+	require.NoError(t, err)
+	assert.False(t, held)
+	assert.Empty(t, os.Getenv(lockHeldEnvVar))
+}
+
+func TestAcquireRepoLock_TimesOut(t *testing.T) {
+	repo, lockPath := setupRepoLockTest(t)
+	unlock, err := filelock.Lock(lockPath)
+	require.NoError(t, err)
+	defer func() { _ = unlock() }()
+
+	cfg := &config.Config{Defaults: config.Defaults{LockTimeout: "50ms"}}
+	var buf bytes.Buffer
+	_, err = acquireRepoLock(&buf, &cli.Command{}, cfg, repo, "wtp remove")
+
+	require.Error(t, err)
+	code, _ := errors.CodeOf(err)
+	assert.Equal(t, errors.CodeRepositoryBusy, code)
+	assert.Contains(t, err.Error(), "wtp remove gave up after waiting 50ms")
+	assert.Contains(t, buf.String(), "Waiting for another wtp command in this repository to finish")
+}
+
+func TestAcquireRepoLock_WaitsForRelease(t *testing.T) {
+	repo, lockPath := setupRepoLockTest(t)
+	unlock, err := filelock.Lock(lockPath)
+	require.NoError(t, err)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = unlock()
+	}()
+
+	release, err := acquireRepoLock(&bytes.Buffer{}, &cli.Command{}, &config.Config{}, repo, "wtp add")
+	require.NoError(t, err)
+	release()
+}
+
+func TestAcquireRepoLock_Skipped(t *testing.T) {
+	repo, lockPath := setupRepoLockTest(t)
+	unlock, err := filelock.Lock(lockPath)
+	require.NoError(t, err)
+	defer func() { _ = unlock() }()
+	cfg := &config.Config{Defaults: config.Defaults{LockTimeout: "10ms"}}
+
+	t.Run("no-lock", func(t *testing.T) {
+		t.Setenv(noLockEnvVar, "1")
+		release, err := acquireRepoLock(&bytes.Buffer{}, &cli.Command{}, cfg, repo, "wtp add")
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("run by a hook of the lock holder", func(t *testing.T) {
+		t.Setenv(lockHeldEnvVar, lockPath)
+		release, err := acquireRepoLock(&bytes.Buffer{}, &cli.Command{}, cfg, repo, "wtp add")
+		require.NoError(t, err)
+		release()
+		assert.Equal(t, lockPath, os.Getenv(lockHeldEnvVar), "the holder's lock stays marked as held")
+	})
+}
@@ -8,6 +8,8 @@ import (
 	"os/exec"
 
 	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/errors"
 )
 
 var allowedShells = map[string]struct{}{
@@ -18,7 +20,7 @@ var allowedShells = map[string]struct{}{
 
 var runCompletionCommand = func(shell string) ([]byte, error) {
 	if _, ok := allowedShells[shell]; !ok {
-		return nil, fmt.Errorf("unsupported shell: %s", shell)
+		return nil, errors.UnsupportedShell(shell, nil)
 	}
 
 	exe, err := os.Executable()
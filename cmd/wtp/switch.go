@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+// switchCanceledStatus is the exit status of a canceled 'wtp switch', as for a shell
+// command interrupted with Ctrl-C.
+const switchCanceledStatus = 130
+
+// Variables to allow mocking in tests
+var (
+	switchIsTerminal = func() bool { return pickerIsTerminal() }
+	runSwitchUI      = runSwitchPicker
+)
+
+// switchItem is a worktree offered by 'wtp switch'.
+type switchItem struct {
+	name   string
+	branch string
+	path   string
+	// commit summarizes the last commit, e.g. "1a2b3c4 Fix login (2 days ago)".
+	commit  string
+	dirty   bool
+	current bool
+}
+
+// NewSwitchCommand creates the switch command definition
+func NewSwitchCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "switch",
+		Usage:     "Pick a worktree interactively and change to it",
+		UsageText: "wtp switch [<filter>]",
+		Description: "Lists the worktrees with their branch, uncommitted changes (*), and last commit, " +
+			"and lets you pick one with the arrow keys (or Ctrl-P/Ctrl-N) while typing filters the " +
+			"list by name and branch. Enter picks the highlighted worktree; Esc or Ctrl-C cancels " +
+			"with exit status 130.\n\n" +
+			"With the shell hook from 'wtp hook <shell>', your shell changes to the picked worktree. " +
+			"Without it, its path is printed, so cd \"$(wtp switch)\" works too.\n\n" +
+			"Examples:\n" +
+			"  wtp switch          # Pick from every worktree\n" +
+			"  wtp switch auth     # Start with the filter 'auth'",
+		ArgsUsage: "[<filter>]",
+		Action:    switchCommand,
+	}
+}
+
+func switchCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	if !switchIsTerminal() {
+		return errors.TerminalRequired("wtp switch", "use 'wtp cd <worktree>' in scripts")
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return errors.DirectoryAccessFailed("access current", ".", err)
+	}
+	_, cfg, mainRepoPath, err := setupRepoAndConfig()
+	if err != nil {
+		return err
+	}
+
+	items, err := collectSwitchItems(command.NewRealExecutor(), cfg, mainRepoPath, cwd)
+	if err != nil {
+		return err
+	}
+	chosen, err := runSwitchUI(items, strings.Join(cmd.Args().Slice(), " "))
+	if err != nil {
+		return err
+	}
+	if chosen == nil {
+		return cli.Exit("", switchCanceledStatus)
+	}
+	return writeSwitchTarget(w, chosen.path)
+}
+
+// writeSwitchTarget hands path to the shell hook when it is loaded, and prints it otherwise.
+func writeSwitchTarget(w io.Writer, path string) error {
+	if os.Getenv(cdFileEnv) != "" {
+		return writeCdFile(path)
+	}
+	_, err := fmt.Fprintln(w, path)
+	return err
+}
+
+// collectSwitchItems returns the worktrees 'wtp cd' can change to, in 'git worktree list'
+// order. Dirty state and last commits are read with one batch of git commands each.
+func collectSwitchItems(
+	executor command.Executor, cfg *config.Config, mainRepoPath, cwd string,
+) ([]switchItem, error) {
+	result, err := executor.Execute([]command.Command{command.GitWorktreeList()})
+	if err != nil {
+		return nil, errors.GitCommandFailed("git worktree list", err.Error())
+	}
+	var worktrees []git.Worktree
+	for _, wt := range parseWorktreesFromOutput(result.Results[0].Output) {
+		if isWorktreeManagedCd(wt.Path, cfg, mainRepoPath, wt.IsMain) {
+			worktrees = append(worktrees, wt)
+		}
+	}
+
+	commands := make([]command.Command, 0, 2*len(worktrees)) //nolint:mnd // status and log per worktree
+	for i := range worktrees {
+		commands = append(commands, command.GitStatusPorcelain(worktrees[i].Path),
+			command.GitLastCommitSummary(worktrees[i].Path))
+	}
+	var details []command.Result
+	if result, err := executor.Execute(commands); err == nil {
+		details = result.Results
+	}
+
+	current := findWorktreeContaining(worktrees, cwd)
+	items := make([]switchItem, 0, len(worktrees))
+	for i := range worktrees {
+		wt := &worktrees[i]
+		item := switchItem{
+			name:    getWorktreeDisplayName(*wt, cfg, mainRepoPath),
+			branch:  wt.Branch,
+			path:    wt.Path,
+			current: current == wt,
+		}
+		if status := 2 * i; status+1 < len(details) { //nolint:mnd // status and log per worktree
+			if details[status].Error == nil {
+				item.dirty = strings.TrimSpace(details[status].Output) != ""
+			}
+			if details[status+1].Error == nil {
+				item.commit = strings.TrimSpace(details[status+1].Output)
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
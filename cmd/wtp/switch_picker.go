@@ -0,0 +1,299 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// Keys the picker reacts to
+const (
+	switchKeyRune = iota
+	switchKeyUp
+	switchKeyDown
+	switchKeyEnter
+	switchKeyCancel
+	switchKeyBackspace
+	switchKeyClear
+)
+
+// Control characters and escape sequences read from the terminal in raw mode
+const (
+	keyCtrlC     = 0x03
+	keyCtrlG     = 0x07
+	keyBackspace = 0x08
+	keyNewline   = '\n'
+	keyCtrlK     = 0x0b
+	keyReturn    = '\r'
+	keyCtrlN     = 0x0e
+	keyCtrlP     = 0x10
+	keyCtrlU     = 0x15
+	keyEscape    = 0x1b
+	keyDelete    = 0x7f
+	// csiFinalMin and csiFinalMax bound the byte that ends an escape sequence.
+	csiFinalMin = 0x40
+	csiFinalMax = 0x7e
+)
+
+// ANSI sequences that draw the picker on the alternate screen, leaving the scrollback intact
+const (
+	ansiEnterAltScreen = "\x1b[?1049h\x1b[?25l"
+	ansiLeaveAltScreen = "\x1b[?25h\x1b[?1049l"
+	ansiClearScreen    = "\x1b[H\x1b[2J"
+	ansiReverse        = "\x1b[7m"
+	ansiDim            = "\x1b[2m"
+	ansiReset          = "\x1b[0m"
+)
+
+// Fallback terminal size, and the lines around the list: the prompt and the key help
+const (
+	defaultTerminalWidth  = 80
+	defaultTerminalHeight = 24
+	switchChromeLines     = 2
+	switchReadBufferSize  = 64
+)
+
+type switchKey struct {
+	kind int
+	r    rune
+}
+
+// switchPicker is the state of the 'wtp switch' list: the filter typed so far, the items
+// matching it, best first, and the highlighted one.
+type switchPicker struct {
+	items   []switchItem
+	query   []rune
+	matches []int
+	cursor  int
+}
+
+func newSwitchPicker(items []switchItem, query string) *switchPicker {
+	p := &switchPicker{items: items, query: []rune(query)}
+	p.refilter()
+	return p
+}
+
+// refilter matches the query against the worktree and branch names like fuzzy worktree
+// names do, keeping the list order within a tier, and moves the highlight to the top.
+func (p *switchPicker) refilter() {
+	query := strings.ToLower(string(p.query))
+	tiers := make(map[int]int, len(p.items))
+	p.matches = p.matches[:0]
+	for i := range p.items {
+		tier := fuzzyTierPrefix
+		if query != "" {
+			tier = max(fuzzyMatchTier(query, p.items[i].name), fuzzyMatchTier(query, p.items[i].branch))
+		}
+		if tier != fuzzyTierNone {
+			tiers[i] = tier
+			p.matches = append(p.matches, i)
+		}
+	}
+	sort.SliceStable(p.matches, func(a, b int) bool { return tiers[p.matches[a]] > tiers[p.matches[b]] })
+	p.cursor = 0
+}
+
+// handle applies key and returns the chosen item once the picker is done; done with a nil
+// item means it was canceled.
+func (p *switchPicker) handle(key switchKey) (chosen *switchItem, done bool) {
+	switch key.kind {
+	case switchKeyRune:
+		p.query = append(p.query, key.r)
+		p.refilter()
+	case switchKeyBackspace:
+		if len(p.query) > 0 {
+			p.query = p.query[:len(p.query)-1]
+			p.refilter()
+		}
+	case switchKeyClear:
+		p.query = p.query[:0]
+		p.refilter()
+	case switchKeyUp:
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case switchKeyDown:
+		if p.cursor < len(p.matches)-1 {
+			p.cursor++
+		}
+	case switchKeyEnter:
+		if len(p.matches) > 0 {
+			return &p.items[p.matches[p.cursor]], true
+		}
+	case switchKeyCancel:
+		return nil, true
+	}
+	return nil, false
+}
+
+// render draws the picker into a width × height screen.
+func (p *switchPicker) render(w io.Writer, width, height int) error {
+	var b strings.Builder
+	b.WriteString(ansiClearScreen)
+	b.WriteString(truncateRunes(fmt.Sprintf("Switch to: %s  (%d/%d)", string(p.query), len(p.matches),
+		len(p.items)), width))
+
+	nameWidth, branchWidth := 0, 0
+	for _, i := range p.matches {
+		nameWidth = max(nameWidth, utf8.RuneCountInString(p.items[i].name))
+		branchWidth = max(branchWidth, utf8.RuneCountInString(p.items[i].branch))
+	}
+	rows := max(height-switchChromeLines, 1)
+	first := max(p.cursor-rows+1, 0)
+	for row := first; row < len(p.matches) && row < first+rows; row++ {
+		item := &p.items[p.matches[row]]
+		marker, dirty := "  ", " "
+		if row == p.cursor {
+			marker = "> "
+		}
+		if item.dirty {
+			dirty = "*"
+		}
+		line := fmt.Sprintf("%s%-*s  %-*s %s %s", marker, nameWidth, item.name, branchWidth, item.branch,
+			dirty, item.commit)
+		if item.current {
+			line += " (current)"
+		}
+		line = truncateRunes(line, width)
+		b.WriteString("\r\n")
+		if row == p.cursor {
+			b.WriteString(ansiReverse + line + ansiReset)
+		} else {
+			b.WriteString(line)
+		}
+	}
+	if len(p.matches) == 0 {
+		b.WriteString("\r\n  No matching worktrees")
+	}
+	b.WriteString("\r\n" + ansiDim + truncateRunes("↑/↓ move · type to filter · enter switch · esc cancel", width) +
+		ansiReset)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// parseSwitchKeys decodes what one read from a terminal in raw mode returned. Escape
+// sequences other than the arrow keys are skipped; a lone Escape cancels.
+func parseSwitchKeys(data []byte) []switchKey {
+	var keys []switchKey
+	for len(data) > 0 {
+		switch data[0] {
+		case keyReturn, keyNewline:
+			keys = append(keys, switchKey{kind: switchKeyEnter})
+		case keyCtrlC, keyCtrlG:
+			keys = append(keys, switchKey{kind: switchKeyCancel})
+		case keyCtrlP, keyCtrlK:
+			keys = append(keys, switchKey{kind: switchKeyUp})
+		case keyCtrlN:
+			keys = append(keys, switchKey{kind: switchKeyDown})
+		case keyBackspace, keyDelete:
+			keys = append(keys, switchKey{kind: switchKeyBackspace})
+		case keyCtrlU:
+			keys = append(keys, switchKey{kind: switchKeyClear})
+		case keyEscape:
+			key, size := parseEscapeSequence(data)
+			if key != nil {
+				keys = append(keys, *key)
+			}
+			data = data[size:]
+			continue
+		default:
+			r, size := utf8.DecodeRune(data)
+			if r >= ' ' && r != utf8.RuneError {
+				keys = append(keys, switchKey{kind: switchKeyRune, r: r})
+			}
+			data = data[size:]
+			continue
+		}
+		data = data[1:]
+	}
+	return keys
+}
+
+// parseEscapeSequence decodes the escape sequence at the start of data and returns its key,
+// if the picker uses it, and its length.
+func parseEscapeSequence(data []byte) (key *switchKey, size int) {
+	if len(data) == 1 || (data[1] != '[' && data[1] != 'O') {
+		return &switchKey{kind: switchKeyCancel}, 1
+	}
+	end := 2
+	for end < len(data) && (data[end] < csiFinalMin || data[end] > csiFinalMax) {
+		end++
+	}
+	if end == len(data) {
+		return nil, len(data)
+	}
+	switch data[end] {
+	case 'A':
+		return &switchKey{kind: switchKeyUp}, end + 1
+	case 'B':
+		return &switchKey{kind: switchKeyDown}, end + 1
+	default:
+		return nil, end + 1
+	}
+}
+
+// pickSwitchItem runs the picker until it is done, reading keys from in and drawing with
+// draw after every change.
+func pickSwitchItem(p *switchPicker, in io.Reader, draw func(*switchPicker) error) (*switchItem, error) {
+	buf := make([]byte, switchReadBufferSize)
+	for {
+		if err := draw(p); err != nil {
+			return nil, err
+		}
+		n, err := in.Read(buf)
+		for _, key := range parseSwitchKeys(buf[:n]) {
+			if chosen, done := p.handle(key); done {
+				return chosen, nil
+			}
+		}
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// runSwitchPicker shows the picker on the terminal: keys are read from stdin in raw mode and
+// the list is drawn on stderr, so that stdout only carries the chosen path. It returns nil
+// when the picker was canceled.
+func runSwitchPicker(items []switchItem, query string) (*switchItem, error) {
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up the terminal: %w", err)
+	}
+	defer func() { _ = term.Restore(fd, state) }()
+
+	out := os.Stderr
+	if _, err := io.WriteString(out, ansiEnterAltScreen); err != nil {
+		return nil, err
+	}
+	defer func() { _, _ = io.WriteString(out, ansiLeaveAltScreen) }()
+
+	return pickSwitchItem(newSwitchPicker(items, query), os.Stdin, func(p *switchPicker) error {
+		width, height, err := term.GetSize(int(out.Fd()))
+		if err != nil || width <= 0 || height <= 0 {
+			width, height = defaultTerminalWidth, defaultTerminalHeight
+		}
+		return p.render(out, width, height)
+	})
+}
+
+// truncateRunes shortens s to at most width characters.
+func truncateRunes(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	if width <= 1 {
+		return string(runes[:max(width, 0)])
+	}
+	return string(runes[:width-1]) + "…"
+}
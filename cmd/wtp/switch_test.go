@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+type mockSwitchCommandExecutor struct {
+	listOutput string
+	dirty      map[string]bool
+}
+
+func (m *mockSwitchCommandExecutor) Execute(commands []command.Command) (*command.ExecutionResult, error) {
+	results := make([]command.Result, len(commands))
+	for i, cmd := range commands {
+		results[i].Command = cmd
+		switch cmd.Args[0] {
+		case "worktree":
+			results[i].Output = m.listOutput
+		case "status":
+			if m.dirty[cmd.WorkDir] {
+				results[i].Output = " M main.go\n"
+			}
+		case "log":
+			results[i].Output = "abc1234 Initial commit (3 days ago)\n"
+		}
+	}
+	return &command.ExecutionResult{Results: results}, nil
+}
+
+func switchTestItems() []switchItem {
+	return []switchItem{
+		{name: "@", branch: "main", path: "/repo", current: true},
+		{name: "feature/auth", branch: "feature/auth", path: "/worktrees/feature/auth", dirty: true},
+		{name: "fix/login", branch: "fix/login", path: "/worktrees/fix/login"},
+	}
+}
+
+func TestNewSwitchCommand(t *testing.T) {
+	cmd := NewSwitchCommand()
+
+	assert.Equal(t, "switch", cmd.Name)
+	assert.NotEmpty(t, cmd.Usage)
+	assert.NotNil(t, cmd.Action)
+}
+
+func TestCollectSwitchItems(t *testing.T) {
+	mainPath, worktreePath, listOutput := setupCheckoutTest(t)
+	unmanaged := filepath.Join(t.TempDir(), "elsewhere")
+	executor := &mockSwitchCommandExecutor{
+		listOutput: listOutput + "worktree " + unmanaged + "\nHEAD 999999\nbranch refs/heads/other\n\n",
+		dirty:      map[string]bool{worktreePath: true},
+	}
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+
+	items, err := collectSwitchItems(executor, cfg, mainPath, worktreePath)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, switchItem{name: "@", branch: "main", path: mainPath,
+		commit: "abc1234 Initial commit (3 days ago)"}, items[0])
+	assert.Equal(t, switchItem{name: "feature/foo", branch: "feature/foo", path: worktreePath,
+		commit: "abc1234 Initial commit (3 days ago)", dirty: true, current: true}, items[1])
+}
+
+func TestSwitchPicker_Filter(t *testing.T) {
+	p := newSwitchPicker(switchTestItems(), "")
+	assert.Equal(t, []int{0, 1, 2}, p.matches)
+
+	for _, key := range parseSwitchKeys([]byte("lo")) {
+		p.handle(key)
+	}
+	// "fix/login" matches by segment prefix, ahead of "feature/auth" as a subsequence
+	assert.Equal(t, []int{2}, p.matches)
+
+	p.handle(switchKey{kind: switchKeyBackspace})
+	assert.Equal(t, "l", string(p.query))
+	assert.Equal(t, []int{2}, p.matches)
+
+	p.handle(switchKey{kind: switchKeyClear})
+	assert.Len(t, p.matches, 3)
+
+	p = newSwitchPicker(switchTestItems(), "zzz")
+	chosen, done := p.handle(switchKey{kind: switchKeyEnter})
+	assert.False(t, done, "enter does nothing without matches")
+	assert.Nil(t, chosen)
+}
+
+func TestParseSwitchKeys(t *testing.T) {
+	keys := parseSwitchKeys([]byte("a\x1b[B\x1bOA\x0e\x10\x7f\x1b[1;5C\r"))
+
+	kinds := make([]int, 0, len(keys))
+	for _, key := range keys {
+		kinds = append(kinds, key.kind)
+	}
+	assert.Equal(t, []int{
+		switchKeyRune, switchKeyDown, switchKeyUp, switchKeyDown, switchKeyUp, switchKeyBackspace, switchKeyEnter,
+	}, kinds)
+	assert.Equal(t, 'a', keys[0].r)
+
+	assert.Equal(t, []switchKey{{kind: switchKeyCancel}}, parseSwitchKeys([]byte{keyEscape}))
+	assert.Equal(t, []switchKey{{kind: switchKeyCancel}}, parseSwitchKeys([]byte{keyCtrlC}))
+}
+
+func TestPickSwitchItem(t *testing.T) {
+	draws := 0
+	draw := func(*switchPicker) error {
+		draws++
+		return nil
+	}
+
+	chosen, err := pickSwitchItem(newSwitchPicker(switchTestItems(), ""),
+		strings.NewReader("\x1b[B\x1b[B\x1b[A\r"), draw)
+	require.NoError(t, err)
+	require.NotNil(t, chosen)
+	assert.Equal(t, "/worktrees/feature/auth", chosen.path)
+	assert.Equal(t, 1, draws)
+
+	chosen, err = pickSwitchItem(newSwitchPicker(switchTestItems(), ""), strings.NewReader("\x1b"), draw)
+	require.NoError(t, err)
+	assert.Nil(t, chosen)
+
+	chosen, err = pickSwitchItem(newSwitchPicker(switchTestItems(), ""), strings.NewReader("fix"), draw)
+	require.NoError(t, err)
+	assert.Nil(t, chosen, "end of input cancels")
+}
+
+func TestSwitchPicker_Render(t *testing.T) {
+	p := newSwitchPicker(switchTestItems(), "")
+	p.handle(switchKey{kind: switchKeyDown})
+
+	var buf bytes.Buffer
+	require.NoError(t, p.render(&buf, 80, 24))
+	output := buf.String()
+	assert.Contains(t, output, "Switch to:   (3/3)")
+	assert.Contains(t, output, ansiReverse+"> feature/auth  feature/auth *")
+	assert.Contains(t, output, "  @             main          ")
+	assert.Contains(t, output, "(current)")
+
+	// Only the rows that fit are drawn, keeping the highlighted one visible
+	buf.Reset()
+	p.handle(switchKey{kind: switchKeyDown})
+	require.NoError(t, p.render(&buf, 20, 3))
+	output = buf.String()
+	assert.Contains(t, output, "> fix/login")
+	assert.NotContains(t, output, "feature/auth")
+	for _, line := range strings.Split(output, "\r\n") {
+		assert.LessOrEqual(t, len([]rune(strings.NewReplacer(ansiClearScreen, "", ansiReverse, "", ansiDim, "",
+			ansiReset, "").Replace(line))), 20)
+	}
+
+	buf.Reset()
+	require.NoError(t, newSwitchPicker(switchTestItems(), "zzz").render(&buf, 80, 24))
+	assert.Contains(t, buf.String(), "No matching worktrees")
+}
+
+func TestWriteSwitchTarget(t *testing.T) {
+	t.Setenv(cdFileEnv, "")
+	var buf bytes.Buffer
+	require.NoError(t, writeSwitchTarget(&buf, "/worktrees/feature/auth"))
+	assert.Equal(t, "/worktrees/feature/auth\n", buf.String())
+
+	cdFile := filepath.Join(t.TempDir(), "cd")
+	t.Setenv(cdFileEnv, cdFile)
+	buf.Reset()
+	require.NoError(t, writeSwitchTarget(&buf, "/worktrees/feature/auth"))
+	assert.Empty(t, buf.String())
+	content, err := os.ReadFile(cdFile)
+	require.NoError(t, err)
+	assert.Equal(t, "/worktrees/feature/auth", string(content))
+}
+
+func TestSwitchCommand_RequiresTerminal(t *testing.T) {
+	origIsTerminal := switchIsTerminal
+	t.Cleanup(func() { switchIsTerminal = origIsTerminal })
+	switchIsTerminal = func() bool { return false }
+
+	err := switchCommand(t.Context(), NewSwitchCommand())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "wtp cd")
+}
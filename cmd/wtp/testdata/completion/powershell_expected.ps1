@@ -0,0 +1,42 @@
+# wtp PowerShell completion
+
+Register-ArgumentCompleter -Native -CommandName wtp -ScriptBlock {
+	param($wordToComplete, $commandAst, $cursorPosition)
+
+	$words = @($commandAst.CommandElements |
+		Where-Object { $_.Extent.EndOffset -le $cursorPosition } |
+		ForEach-Object { $_.Extent.Text })
+	$arguments = @()
+	if ($words.Count -gt 1) {
+		$arguments = @($words[1..($words.Count - 1)])
+	}
+	if ($wordToComplete -and -not $wordToComplete.StartsWith('-') -and $arguments.Count -gt 0) {
+		$arguments = @($arguments | Select-Object -SkipLast 1)
+	}
+	$arguments += '--generate-shell-completion'
+
+	$env:WTP_SHELL_COMPLETION = '1'
+	try {
+		$raw = & wtp @arguments 2>$null
+	} finally {
+		Remove-Item Env:WTP_SHELL_COMPLETION -ErrorAction SilentlyContinue
+	}
+
+	foreach ($line in $raw) {
+		if (-not $line) {
+			continue
+		}
+
+		$value = $line
+		$tooltip = $line
+		$parts = $line -split ':', 2
+		if ($parts.Count -gt 1 -and $parts[1].Contains(' ')) {
+			$value = $parts[0]
+			$tooltip = $parts[1]
+		}
+
+		if ($value -like "$wordToComplete*") {
+			[System.Management.Automation.CompletionResult]::new($value, $value, 'ParameterValue', $tooltip)
+		}
+	}
+}
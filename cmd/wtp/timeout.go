@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+)
+
+// newTimeoutFlag returns the --timeout flag of a long-running command.
+func newTimeoutFlag() *cli.DurationFlag {
+	return &cli.DurationFlag{
+		Name:  "timeout",
+		Usage: "Stop git, hooks, and commands still running after this long, e.g. 30m (default: defaults.operation_timeout)",
+	}
+}
+
+// operationTimeout is the cause of an operation context whose timeout passed.
+type operationTimeout struct {
+	operation string
+	timeout   time.Duration
+}
+
+func (t *operationTimeout) Error() string {
+	return fmt.Sprintf("%s timed out after %s", t.operation, t.timeout)
+}
+
+// operationContext bounds ctx by the command's --timeout, else by
+// defaults.operation_timeout. Without either, ctx only ends when it is canceled.
+func operationContext(
+	ctx context.Context, cmd *cli.Command, cfg *config.Config, operation string,
+) (context.Context, context.CancelFunc) {
+	timeout := cmd.Duration("timeout")
+	if timeout == 0 {
+		timeout = cfg.OperationTimeout()
+	}
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeoutCause(ctx, timeout, &operationTimeout{operation: operation, timeout: timeout})
+}
+
+// operationTimedOut returns the error reporting that ctx's timeout passed, with progress
+// describing what got done before, or nil while the operation may go on.
+func operationTimedOut(ctx context.Context, progress ...string) error {
+	var timeout *operationTimeout
+	if !stderrors.As(context.Cause(ctx), &timeout) {
+		return nil
+	}
+	return errors.OperationTimedOut(timeout.operation, timeout.timeout, progress)
+}
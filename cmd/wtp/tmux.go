@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+// Variables to allow mocking in tests
+var (
+	runTmux    = execTmux
+	tmuxOutput = execTmuxOutput
+)
+
+// tmuxTarget is the session, or window, 'wtp tmux' opens a worktree in.
+type tmuxTarget struct {
+	name   string
+	path   string
+	window bool
+	// layout holds the commands of integrations.tmux.layout, split into arguments.
+	layout [][]string
+}
+
+// NewTmuxCommand creates the tmux command definition
+func NewTmuxCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "tmux",
+		Usage:     "Open a worktree in a tmux session or window",
+		UsageText: "wtp tmux [--window] [--detach] <worktree-name>",
+		Description: "Attaches to the tmux session named after the worktree's branch, creating it " +
+			"with its working directory set to the worktree first. Inside tmux, the client " +
+			"switches to the session instead. With --window, or integrations.tmux.mode set to " +
+			"'window', a window in the current session is used instead of a session.\n\n" +
+			"The name is integrations.tmux.name, \"${BRANCH_SLUG}\" by default, and the tmux " +
+			"commands of integrations.tmux.layout run in a session or window wtp creates.\n\n" +
+			"Examples:\n" +
+			"  wtp tmux feature/auth            # Attach to, or switch to, session feature-auth\n" +
+			"  wtp tmux --window feature/auth   # A window in the current session\n" +
+			"  wtp tmux --detach feature/auth   # Only create the session",
+		ArgsUsage: "<worktree-name>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "window",
+				Aliases: []string{"w"},
+				Usage:   "Open a window in the current tmux session instead of a session",
+			},
+			&cli.BoolFlag{
+				Name:    "detach",
+				Aliases: []string{"d"},
+				Usage:   "Create the session or window without attaching to it, and print its name",
+			},
+		},
+		ShellComplete: completeWorktreesForCd,
+		Action:        tmuxCommand,
+	}
+}
+
+func tmuxCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	if cmd.Args().Len() != 1 {
+		return errors.InvalidArguments(`worktree name is required
+
+Usage: wtp tmux [--window] [--detach] <worktree-name>
+
+Tip: Run 'wtp list' to see available worktrees`)
+	}
+	worktreeName := cmd.Args().First()
+
+	_, cfg, mainRepoPath, err := setupRepoAndConfig()
+	if err != nil {
+		return err
+	}
+
+	target, worktrees, err := findWorktreeToOpen(command.NewRealExecutor(), cfg, worktreeName, false)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		mainWorktreePath := findMainWorktreePath(worktrees)
+		return errors.WorktreeNotFound(worktreeName, managedWorktreeNames(worktrees, cfg, mainWorktreePath))
+	}
+
+	window := cmd.Bool("window") || cfg.Integrations.Tmux.Mode == config.TmuxModeWindow
+	tmux, err := newTmuxTarget(cfg, mainRepoPath, target, window)
+	if err != nil {
+		return err
+	}
+	return openTmux(w, tmux, cmd.Bool("detach"), os.Getenv("TMUX") != "")
+}
+
+// newTmuxTarget names the session or window of worktree after integrations.tmux.name,
+// expanded for its branch, or for its name when it has none.
+func newTmuxTarget(cfg *config.Config, mainRepoPath string, worktree *git.Worktree, window bool) (*tmuxTarget, error) {
+	branch := worktree.Branch
+	if branch == "" || branch == detachedKeyword {
+		branch = getWorktreeNameFromPath(worktree.Path, cfg, mainRepoPath, worktree.IsMain)
+	}
+
+	tmux := &tmuxTarget{
+		name:   cfg.ExpandVariables(cfg.Integrations.Tmux.NameTemplate(), mainRepoPath, branch),
+		path:   worktree.Path,
+		window: window,
+	}
+	if !window {
+		// tmux would replace these itself, and then not find the session by its name
+		tmux.name = strings.NewReplacer(".", "_", ":", "_").Replace(tmux.name)
+	}
+	if tmux.name == "" {
+		return nil, fmt.Errorf("integrations.tmux.name '%s' expands to an empty name", cfg.Integrations.Tmux.Name)
+	}
+
+	for i, line := range cfg.Integrations.Tmux.Layout {
+		args, err := splitTmuxCommand(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integrations.tmux.layout command %d: %w", i+1, err)
+		}
+		for j := range args {
+			args[j] = cfg.ExpandVariables(args[j], mainRepoPath, branch)
+		}
+		if len(args) > 0 {
+			tmux.layout = append(tmux.layout, args)
+		}
+	}
+	return tmux, nil
+}
+
+// openTmux attaches to the session or window of t, creating it first when it does not
+// exist. insideTmux tells whether wtp runs in a tmux client, which switches sessions
+// instead of attaching.
+func openTmux(w io.Writer, t *tmuxTarget, detach, insideTmux bool) error {
+	if t.window && !insideTmux {
+		return fmt.Errorf("tmux windows are opened in the current session; run 'wtp tmux' inside tmux, " +
+			"or without --window and integrations.tmux.mode 'window'")
+	}
+
+	windowID, exists := findTmuxTarget(t)
+	if !exists {
+		if err := runTmux(t.path, t.createArgs(detach)...); err != nil {
+			return err
+		}
+	}
+	if detach {
+		_, err := fmt.Fprintln(w, t.name)
+		return err
+	}
+	switch {
+	case t.window && exists:
+		return runTmux("", "select-window", "-t", windowID)
+	case t.window:
+		return nil // new windows are selected
+	case insideTmux:
+		return runTmux("", "switch-client", "-t", "="+t.name)
+	default:
+		return runTmux("", "attach-session", "-t", "="+t.name)
+	}
+}
+
+// findTmuxTarget reports whether the session or window of t exists, and the ID of the
+// window.
+func findTmuxTarget(t *tmuxTarget) (windowID string, exists bool) {
+	if !t.window {
+		_, err := tmuxOutput("has-session", "-t", "="+t.name)
+		return "", err == nil
+	}
+
+	output, err := tmuxOutput("list-windows", "-F", "#{window_id}\t#{window_name}")
+	if err != nil {
+		return "", false
+	}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if id, name, ok := strings.Cut(line, "\t"); ok && name == t.name {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// createArgs returns the tmux arguments that create the session or window of t and then
+// run its layout commands in it; tmux runs commands separated by ";" one after another,
+// targeting what the first one created.
+func (t *tmuxTarget) createArgs(detach bool) []string {
+	var args []string
+	if t.window {
+		args = []string{"new-window", "-n", t.name, "-c", t.path}
+		if detach {
+			args = append(args, "-d")
+		}
+	} else {
+		args = []string{"new-session", "-d", "-s", t.name, "-c", t.path}
+	}
+	for _, layout := range t.layout {
+		args = append(append(args, ";"), layout...)
+	}
+	return args
+}
+
+// splitTmuxCommand splits a tmux command line into its arguments the way tmux does for
+// simple cases: at spaces, except within single or double quotes, and after a backslash.
+func splitTmuxCommand(line string) ([]string, error) {
+	var (
+		args    tmuxArgs
+		quote   rune
+		escaped bool
+	)
+	for _, r := range line {
+		switch {
+		case escaped:
+			args.add(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, args.started = true, true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			args.add(r)
+		case r == '\'' || r == '"':
+			quote, args.started = r, true
+		case r == ' ' || r == '\t':
+			args.end()
+		default:
+			args.add(r)
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape in '%s'", line)
+	}
+	args.end()
+	return args.list, nil
+}
+
+// tmuxArgs collects the arguments splitTmuxCommand splits off.
+type tmuxArgs struct {
+	list    []string
+	current strings.Builder
+	// started is set once the current argument has begun, which it has with an empty
+	// pair of quotes too.
+	started bool
+}
+
+func (a *tmuxArgs) add(r rune) {
+	a.current.WriteRune(r)
+	a.started = true
+}
+
+// end ends the current argument, if one has begun.
+func (a *tmuxArgs) end() {
+	if a.started {
+		a.list = append(a.list, a.current.String())
+		a.current.Reset()
+		a.started = false
+	}
+}
+
+// execTmux runs tmux in dir, or the current directory when it is empty, attached to the
+// terminal, as attaching to a session needs. Panes split off one whose shell has not
+// started yet start in dir.
+func execTmux(dir string, args ...string) error {
+	cmd := exec.Command("tmux", args...)
+	cmd.Dir = dir
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("tmux %s failed: %w", args[0], err)
+	}
+	return nil
+}
+
+// execTmuxOutput runs tmux and returns its output.
+func execTmuxOutput(args ...string) (string, error) {
+	output, err := exec.Command("tmux", args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("tmux %s failed: %w", args[0], err)
+	}
+	return string(output), nil
+}
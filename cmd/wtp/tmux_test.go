@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+// mockTmux records the tmux commands run, answering has-session and list-windows with
+// the sessions and windows given.
+type mockTmux struct {
+	sessions []string
+	windows  string
+	calls    []string
+}
+
+func newMockTmux(t *testing.T, sessions []string, windows string) *mockTmux {
+	t.Helper()
+	originalRun, originalOutput := runTmux, tmuxOutput
+	t.Cleanup(func() { runTmux, tmuxOutput = originalRun, originalOutput })
+
+	m := &mockTmux{sessions: sessions, windows: windows}
+	runTmux = func(_ string, args ...string) error {
+		m.calls = append(m.calls, strings.Join(args, " "))
+		return nil
+	}
+	tmuxOutput = func(args ...string) (string, error) {
+		switch args[0] {
+		case "has-session":
+			for _, session := range m.sessions {
+				if args[2] == "="+session {
+					return "", nil
+				}
+			}
+			return "", fmt.Errorf("can't find session")
+		case "list-windows":
+			return m.windows, nil
+		}
+		return "", fmt.Errorf("unexpected tmux %s", args[0])
+	}
+	return m
+}
+
+func TestNewTmuxTarget(t *testing.T) {
+	cfg := &config.Config{
+		Defaults: config.Defaults{BaseDir: "../worktrees"},
+		Integrations: config.Integrations{Tmux: config.Tmux{
+			Name:   "${DIRNAME}:${BRANCH_SLUG}",
+			Layout: []string{"split-window -h", `send-keys "echo ${BRANCH}" Enter`, "  "},
+		}},
+	}
+	worktree := &git.Worktree{Path: "/worktrees/feature/v1.2", Branch: "feature/v1.2"}
+
+	session, err := newTmuxTarget(cfg, "/repo", worktree, false)
+	require.NoError(t, err)
+	assert.Equal(t, "repo_feature-v1_2", session.name, "tmux does not allow '.' and ':' in session names")
+	assert.Equal(t, [][]string{{"split-window", "-h"}, {"send-keys", "echo feature/v1.2", "Enter"}}, session.layout)
+
+	window, err := newTmuxTarget(cfg, "/repo", worktree, true)
+	require.NoError(t, err)
+	assert.Equal(t, "repo:feature-v1.2", window.name)
+
+	detached := &git.Worktree{Path: "/worktrees/review", Branch: detachedKeyword}
+	cfg.Integrations.Tmux.Name = ""
+	target, err := newTmuxTarget(cfg, "/repo", detached, false)
+	require.NoError(t, err)
+	assert.Equal(t, "review", target.name, "worktrees without a branch are named after their directory")
+
+	cfg.Integrations.Tmux.Layout = []string{"send-keys 'make"}
+	_, err = newTmuxTarget(cfg, "/repo", worktree, false)
+	assert.ErrorContains(t, err, "invalid integrations.tmux.layout command 1")
+}
+
+func TestOpenTmux(t *testing.T) {
+	session := &tmuxTarget{
+		name: "feature-auth", path: "/worktrees/feature/auth", layout: [][]string{{"split-window", "-h"}},
+	}
+	window := &tmuxTarget{name: "feature-auth", path: "/worktrees/feature/auth", window: true}
+
+	tests := []struct {
+		name       string
+		target     *tmuxTarget
+		sessions   []string
+		windows    string
+		detach     bool
+		insideTmux bool
+		wantCalls  []string
+		wantOutput string
+	}{
+		{
+			name:   "new session",
+			target: session,
+			wantCalls: []string{
+				"new-session -d -s feature-auth -c /worktrees/feature/auth ; split-window -h",
+				"attach-session -t =feature-auth",
+			},
+		},
+		{
+			name:      "existing session",
+			target:    session,
+			sessions:  []string{"feature-auth"},
+			wantCalls: []string{"attach-session -t =feature-auth"},
+		},
+		{
+			name:       "switch inside tmux",
+			target:     session,
+			sessions:   []string{"feature-auth"},
+			insideTmux: true,
+			wantCalls:  []string{"switch-client -t =feature-auth"},
+		},
+		{
+			name:       "detach",
+			target:     session,
+			detach:     true,
+			wantCalls:  []string{"new-session -d -s feature-auth -c /worktrees/feature/auth ; split-window -h"},
+			wantOutput: "feature-auth\n",
+		},
+		{
+			name:       "new window",
+			target:     window,
+			windows:    "@1\tzsh\n",
+			insideTmux: true,
+			wantCalls:  []string{"new-window -n feature-auth -c /worktrees/feature/auth"},
+		},
+		{
+			name:       "existing window",
+			target:     window,
+			windows:    "@1\tzsh\n@4\tfeature-auth\n",
+			insideTmux: true,
+			wantCalls:  []string{"select-window -t @4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmux := newMockTmux(t, tt.sessions, tt.windows)
+			var buf bytes.Buffer
+
+			require.NoError(t, openTmux(&buf, tt.target, tt.detach, tt.insideTmux))
+			assert.Equal(t, tt.wantCalls, tmux.calls)
+			assert.Equal(t, tt.wantOutput, buf.String())
+		})
+	}
+
+	t.Run("window outside tmux", func(t *testing.T) {
+		tmux := newMockTmux(t, nil, "")
+		err := openTmux(&bytes.Buffer{}, window, false, false)
+		assert.ErrorContains(t, err, "run 'wtp tmux' inside tmux")
+		assert.Empty(t, tmux.calls)
+	})
+}
+
+func TestSplitTmuxCommand(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"split-window -h -p 30", []string{"split-window", "-h", "-p", "30"}},
+		{`send-keys 'npm run dev' Enter`, []string{"send-keys", "npm run dev", "Enter"}},
+		{`send-keys "say \"hi\"" ''`, []string{"send-keys", `say "hi"`, ""}},
+		{`rename-window my\ window`, []string{"rename-window", "my window"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		got, err := splitTmuxCommand(tt.line)
+		require.NoError(t, err, tt.line)
+		assert.Equal(t, tt.want, got, tt.line)
+	}
+
+	_, err := splitTmuxCommand(`send-keys "make`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/hooks"
+)
+
+// Variable to allow mocking in tests
+var verifyGetwd = os.Getwd
+
+// NewVerifyCommand creates the verify command definition
+func NewVerifyCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "verify",
+		Usage:     "Run the configured environment checks in a worktree",
+		UsageText: "wtp verify [<worktree-name>]",
+		Description: "Runs the checks in the 'verify' section of .wtp.yml (files that must exist, commands " +
+			"that must succeed, ports that must be free or in use) and prints a pass/fail report. " +
+			"'wtp add' runs the same checks after the post-create hooks. Without a name, the current " +
+			"worktree is checked. Exits with an error when any check fails.\n\n" +
+			"Examples:\n" +
+			"  wtp verify                # Current worktree\n" +
+			"  wtp verify feature/auth",
+		ArgsUsage:     "[<worktree-name>]",
+		ShellComplete: completeWorktreesForCd,
+		Action:        verifyCommand,
+	}
+}
+
+func verifyCommand(_ context.Context, cmd *cli.Command) error {
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	_, cfg, mainRepoPath, err := setupRepoAndConfig()
+	if err != nil {
+		return err
+	}
+
+	cwd, err := verifyGetwd()
+	if err != nil {
+		return errors.DirectoryAccessFailed("access current", ".", err)
+	}
+
+	executor := command.NewRealExecutor()
+	return verifyCommandWithCommandExecutor(w, executor, cfg, mainRepoPath, cwd, cmd.Args().First())
+}
+
+func verifyCommandWithCommandExecutor(
+	w io.Writer, executor command.Executor, cfg *config.Config, mainRepoPath, cwd, worktreeName string,
+) error {
+	result, err := executor.Execute([]command.Command{command.GitWorktreeList()})
+	if err != nil {
+		return errors.GitCommandFailed("git worktree list", err.Error())
+	}
+	worktrees := parseWorktreesFromOutput(result.Results[0].Output)
+	mainWorktreePath := findMainWorktreePath(worktrees)
+
+	target, err := resolveWorktreeTarget(worktrees, cfg, worktreeName, cwd, mainWorktreePath)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		if worktreeName == "" {
+			return errors.NotInWorktree("pass a worktree name")
+		}
+		return errors.WorktreeNotFound(worktreeName, managedWorktreeNames(worktrees, cfg, mainWorktreePath))
+	}
+	name := getWorktreeDisplayName(*target, cfg, mainWorktreePath)
+
+	if !cfg.HasVerifyChecks() {
+		_, err := fmt.Fprintln(w, "No verify checks configured; add a 'verify' section to .wtp.yml.")
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "Verifying %s...\n", name); err != nil {
+		return err
+	}
+	results := hooks.NewExecutor(cfg, mainRepoPath).Verify(target.Path)
+	failed, err := writeVerifyReport(w, results)
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return errors.VerificationFailed(name, failed, len(results))
+	}
+	return nil
+}
+
+// runPostCreateVerify runs the 'verify' checks after 'wtp add' provisioned a worktree. A
+// failure is reported but does not undo the worktree.
+func runPostCreateVerify(w io.Writer, cfg *config.Config, mainRepoPath, workTreePath string) error {
+	if !cfg.HasVerifyChecks() {
+		return nil
+	}
+	if _, err := fmt.Fprintln(w, "\nVerifying worktree..."); err != nil {
+		return err
+	}
+	results := hooks.NewExecutor(cfg, mainRepoPath).Verify(workTreePath)
+	failed, err := writeVerifyReport(w, results)
+	if err != nil || failed == 0 {
+		return err
+	}
+	return writeWarning(w, errors.CodeWarnVerifyChecksFailed,
+		"%d verify check(s) failed; run 'wtp verify' after fixing the environment", failed)
+}
+
+// writeVerifyReport prints one line per check, with the reason under failed checks, and a
+// summary. It returns the number of failed checks.
+func writeVerifyReport(w io.Writer, results []hooks.CheckResult) (int, error) {
+	failed := 0
+	for i := range results {
+		result := &results[i]
+		duration := result.Duration.Round(time.Millisecond)
+		if result.Err == nil {
+			if _, err := fmt.Fprintf(w, "  ✓ %s (%s)\n", result.Name, duration); err != nil {
+				return failed, err
+			}
+			continue
+		}
+		failed++
+		if _, err := fmt.Fprintf(w, "  ✗ %s (%s)\n", result.Name, duration); err != nil {
+			return failed, err
+		}
+		for _, line := range strings.Split(result.Err.Error(), "\n") {
+			if _, err := fmt.Fprintf(w, "      %s\n", line); err != nil {
+				return failed, err
+			}
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d of %d check(s) passed\n", len(results)-failed, len(results))
+	return failed, err
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func TestNewVerifyCommand(t *testing.T) {
+	cmd := NewVerifyCommand()
+
+	assert.Equal(t, "verify", cmd.Name)
+	assert.NotEmpty(t, cmd.Usage)
+	assert.NotNil(t, cmd.Action)
+	assert.NotNil(t, cmd.ShellComplete)
+}
+
+func TestVerifyCommand_ReportsPassAndFail(t *testing.T) {
+	mainPath, worktreePath, listOutput := setupCheckoutTest(t)
+	require.NoError(t, os.WriteFile(filepath.Join(worktreePath, ".env"), []byte("A=1\n"), 0o644))
+
+	cfg := &config.Config{
+		Defaults: config.Defaults{BaseDir: "../worktrees"},
+		Verify: []config.Check{
+			{Name: "env file", File: ".env"},
+			{Name: "dependencies", File: "node_modules"},
+		},
+	}
+	mockExec := &mockInfoCommandExecutor{listOutput: listOutput}
+
+	var buf bytes.Buffer
+	err := verifyCommandWithCommandExecutor(&buf, mockExec, cfg, mainPath, mainPath, "feature/foo")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "verification failed for worktree 'feature/foo': 1 of 2 check(s) failed")
+	output := buf.String()
+	assert.Contains(t, output, "Verifying feature/foo...")
+	assert.Contains(t, output, "✓ env file")
+	assert.Contains(t, output, "✗ dependencies")
+	assert.Contains(t, output, "node_modules does not exist")
+	assert.Contains(t, output, "1 of 2 check(s) passed")
+}
+
+func TestVerifyCommand_CurrentWorktreePasses(t *testing.T) {
+	_, worktreePath, listOutput := setupCheckoutTest(t)
+
+	cfg := &config.Config{
+		Defaults: config.Defaults{BaseDir: "../worktrees"},
+		Verify:   []config.Check{{Command: "true"}},
+	}
+	mockExec := &mockInfoCommandExecutor{listOutput: listOutput}
+
+	var buf bytes.Buffer
+	require.NoError(t, verifyCommandWithCommandExecutor(&buf, mockExec, cfg, worktreePath, worktreePath, ""))
+	assert.Contains(t, buf.String(), "✓ command true")
+	assert.Contains(t, buf.String(), "1 of 1 check(s) passed")
+}
+
+func TestVerifyCommand_NoChecks(t *testing.T) {
+	mainPath, _, listOutput := setupCheckoutTest(t)
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+
+	var buf bytes.Buffer
+	err := verifyCommandWithCommandExecutor(
+		&buf, &mockInfoCommandExecutor{listOutput: listOutput}, cfg, mainPath, mainPath, "feature/foo")
+
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "No verify checks configured")
+}
+
+func TestVerifyCommand_NotFound(t *testing.T) {
+	mainPath, _, listOutput := setupCheckoutTest(t)
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+
+	var buf bytes.Buffer
+	err := verifyCommandWithCommandExecutor(
+		&buf, &mockInfoCommandExecutor{listOutput: listOutput}, cfg, mainPath, mainPath, "missing")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing")
+}
+
+func TestRunPostCreateVerify_WarnsOnFailure(t *testing.T) {
+	cfg := &config.Config{Verify: []config.Check{{File: "missing.txt"}}}
+
+	var buf bytes.Buffer
+	require.NoError(t, runPostCreateVerify(&buf, cfg, t.TempDir(), t.TempDir()))
+	assert.Contains(t, buf.String(), "Verifying worktree...")
+	assert.Contains(t, buf.String(), "Warning [WTP7003]: 1 verify check(s) failed")
+}
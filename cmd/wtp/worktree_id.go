@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+const (
+	// worktreeIDPrefix starts every worktree ID, e.g. "wt-3f2a".
+	worktreeIDPrefix = "wt-"
+	// worktreeIDDigits is how many hex digits an ID shows unless two worktrees share them.
+	worktreeIDDigits = 4
+)
+
+// worktreeIDDigest returns the hex digest a worktree's ID is a prefix of; see
+// git.WorktreeDigest.
+func worktreeIDDigest(wt *git.Worktree) string {
+	return git.WorktreeDigest(wt.Path, wt.IsMain)
+}
+
+// worktreeIDs returns the ID of every worktree, in order. IDs have worktreeIDDigits digits,
+// and more only where that is needed to tell two worktrees apart.
+func worktreeIDs(worktrees []git.Worktree) []string {
+	digests := make([]string, len(worktrees))
+	for i := range worktrees {
+		digests[i] = worktreeIDDigest(&worktrees[i])
+	}
+
+	ids := make([]string, len(worktrees))
+	for i, digest := range digests {
+		length := worktreeIDDigits
+		for j, other := range digests {
+			if i != j && other != digest {
+				length = max(length, commonPrefixLength(digest, other)+1)
+			}
+		}
+		ids[i] = worktreeIDPrefix + digest[:min(length, len(digest))]
+	}
+	return ids
+}
+
+func commonPrefixLength(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// matchWorktreeID returns the worktrees whose ID starts with query, which must look like an
+// ID ("wt-" and at least worktreeIDDigits hex digits); otherwise it returns nil.
+func matchWorktreeID(query string, worktrees []git.Worktree) []*git.Worktree {
+	digits, ok := strings.CutPrefix(strings.ToLower(query), worktreeIDPrefix)
+	if !ok || len(digits) < worktreeIDDigits || strings.Trim(digits, "0123456789abcdef") != "" {
+		return nil
+	}
+	var matches []*git.Worktree
+	for i := range worktrees {
+		if strings.HasPrefix(worktreeIDDigest(&worktrees[i]), digits) {
+			matches = append(matches, &worktrees[i])
+		}
+	}
+	return matches
+}
+
+// resolveWorktreeID resolves a worktree ID such as "wt-3f2a". It returns nil when query is
+// not the ID of any of worktrees.
+func resolveWorktreeID(
+	query string, worktrees []git.Worktree, cfg *config.Config, mainWorktreePath string,
+) (*git.Worktree, error) {
+	matches := matchWorktreeID(query, worktrees)
+	switch len(matches) {
+	case 0:
+		return nil, nil
+	case 1:
+		return matches[0], nil
+	}
+	return nil, errors.AmbiguousWorktree(query, worktreeLabels(matches, cfg, mainWorktreePath))
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+func TestWorktreeIDs(t *testing.T) {
+	_, worktreePath, _ := setupCheckoutTest(t)
+	setupInfoWorktree(t, worktreePath)
+	worktrees := []git.Worktree{
+		{Path: "/repo", Branch: "main", IsMain: true},
+		{Path: worktreePath, Branch: "feature/foo"},
+	}
+
+	assert.Equal(t, []string{"wt-c364", "wt-2c26"}, worktreeIDs(worktrees))
+
+	// Renaming the branch keeps the ID
+	worktrees[1].Branch = "feature/renamed"
+	assert.Equal(t, "wt-2c26", worktreeIDs(worktrees)[1])
+}
+
+func TestWorktreeIDs_Unique(t *testing.T) {
+	worktrees := parseWorktreesFromOutput(fuzzyWorktreeList)
+	ids := worktreeIDs(worktrees)
+
+	seen := map[string]bool{}
+	for _, id := range ids {
+		assert.False(t, seen[id], "IDs must be unique: %v", ids)
+		seen[id] = true
+		assert.GreaterOrEqual(t, len(id), len(worktreeIDPrefix)+worktreeIDDigits)
+	}
+	assert.Equal(t, 3, commonPrefixLength("3f2a", "3f2b"))
+}
+
+func TestMatchWorktreeID(t *testing.T) {
+	worktrees := parseWorktreesFromOutput(fuzzyWorktreeList)
+	id := worktreeIDs(worktrees)[1]
+
+	matches := matchWorktreeID(id, worktrees)
+	require.Len(t, matches, 1)
+	assert.Equal(t, worktrees[1].Path, matches[0].Path)
+
+	upper := matchWorktreeID("WT-"+id[len(worktreeIDPrefix):], worktrees)
+	require.Len(t, upper, 1)
+
+	assert.Nil(t, matchWorktreeID("wt-ab", worktrees), "too short to be an ID")
+	assert.Nil(t, matchWorktreeID("wt-zzzz", worktrees), "not hex")
+	assert.Nil(t, matchWorktreeID("feature/auth", worktrees))
+}
+
+func TestResolveWorktreeName_ByID(t *testing.T) {
+	worktrees := parseWorktreesFromOutput(fuzzyWorktreeList)
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: config.DefaultBaseDir}}
+	id := worktreeIDs(worktrees)[2]
+
+	wt, err := resolveWorktreeName(id, worktrees, cfg, "/repo")
+	require.NoError(t, err)
+	require.NotNil(t, wt)
+	assert.Equal(t, worktrees[2].Path, wt.Path)
+}
+
+func TestAliasPathCommand(t *testing.T) {
+	mainPath, worktreePath, listOutput := setupCheckoutTest(t)
+	setupInfoWorktree(t, worktreePath)
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+	mockExec := &mockInfoCommandExecutor{listOutput: listOutput}
+
+	t.Run("lists every worktree", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, aliasPathCommandWithCommandExecutor(&buf, mockExec, cfg, "", false))
+		assert.Contains(t, buf.String(), "wt-c364  @            "+mainPath+"\n")
+		assert.Contains(t, buf.String(), "wt-2c26  feature/foo  "+worktreePath+"\n")
+	})
+
+	t.Run("prints the path of an ID", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, aliasPathCommandWithCommandExecutor(&buf, mockExec, cfg, "wt-2c26", false))
+		assert.Equal(t, worktreePath+"\n", buf.String())
+	})
+
+	t.Run("prints the ID of a name", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, aliasPathCommandWithCommandExecutor(&buf, mockExec, cfg, "feature/foo", true))
+		assert.Equal(t, "wt-2c26\n", buf.String())
+	})
+
+	t.Run("unknown ID", func(t *testing.T) {
+		err := aliasPathCommandWithCommandExecutor(&bytes.Buffer{}, mockExec, cfg, "wt-0000", false)
+		require.Error(t, err)
+	})
+}
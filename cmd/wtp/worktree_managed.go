@@ -23,14 +23,25 @@ func isWorktreeManagedCommon(worktreePath string, cfg *config.Config, mainRepoPa
 		}
 	}
 
-	baseDir := cfg.ResolveWorktreePath(mainRepoPath, "")
-	baseDir = strings.TrimSuffix(baseDir, string(filepath.Separator))
-
 	absWorktreePath, err := filepath.Abs(worktreePath)
 	if err != nil {
 		return false
 	}
 
+	// Worktrees created for a branch overlay live under that overlay's base_dir
+	for _, baseDir := range cfg.BaseDirs() {
+		scoped := *cfg
+		scoped.Defaults.BaseDir = baseDir
+		if isWithinBaseDir(absWorktreePath, scoped.ResolveWorktreePath(mainRepoPath, "")) {
+			return true
+		}
+	}
+	return false
+}
+
+func isWithinBaseDir(absWorktreePath, baseDir string) bool {
+	baseDir = strings.TrimSuffix(baseDir, string(filepath.Separator))
+
 	absBaseDir, err := filepath.Abs(baseDir)
 	if err != nil {
 		return false
@@ -0,0 +1,130 @@
+package main
+
+import (
+	stderrors "errors"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/git"
+	"github.com/satococoa/wtp/v2/internal/state"
+)
+
+// clonesRegistryPath is a variable to allow mocking in tests.
+var clonesRegistryPath = state.ClonesPath
+
+// registerWorktree records in the state registry that wtp created the worktree at path,
+// with the configuration it used and record, the outcome of its post_create hooks, and
+// records the clone in the clone registry. A repository without a git directory (e.g. in
+// tests that do not run git) is skipped.
+func registerWorktree(cfg *config.Config, mainRepoPath, path string, record *provisionRecord) error {
+	registryPath, ok, err := worktreeRegistryPath(mainRepoPath)
+	if !ok {
+		return err
+	}
+	registerClone(mainRepoPath, git.OriginURL(mainRepoPath))
+
+	// git records the real path of a new worktree, so the registry uses it too
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	wt := state.Worktree{
+		Path:       path,
+		Branch:     record.Branch,
+		Profile:    record.Profile,
+		CreatedAt:  time.Now().UTC(),
+		ConfigHash: state.ConfigHash(cfg),
+	}
+	for _, hook := range record.Hooks {
+		wt.Hooks = append(wt.Hooks, state.HookResult{
+			Index: hook.Index, Type: hook.Type, Status: hook.Status, Error: hook.Error,
+		})
+	}
+	return state.Update(registryPath, func(r *state.Registry) { r.Add(&wt) })
+}
+
+// unregisterWorktree drops the removed worktree at path from the state registry.
+func unregisterWorktree(mainRepoPath, path string) error {
+	registryPath, ok, err := worktreeRegistryPath(mainRepoPath)
+	if !ok {
+		return err
+	}
+	return state.Update(registryPath, func(r *state.Registry) { r.Remove(path) })
+}
+
+// moveRegisteredWorktree updates the state registry after the worktree at from was moved to to.
+func moveRegisteredWorktree(mainRepoPath, from, to string) error {
+	registryPath, ok, err := worktreeRegistryPath(mainRepoPath)
+	if !ok {
+		return err
+	}
+	return state.Update(registryPath, func(r *state.Registry) { r.Move(from, to) })
+}
+
+// registerClone records the clone at mainRepoPath, whose origin is remoteURL, in the clone
+// registry every repository on the machine shares, for 'wtp list --all-repos'. Failures
+// are ignored: the registry only serves that listing.
+func registerClone(mainRepoPath, remoteURL string) {
+	path, err := clonesRegistryPath()
+	if err != nil {
+		return
+	}
+	clone := state.NewClone(remoteURL, mainRepoPath, time.Now().UTC())
+	_ = state.UpdateClones(path, func(c *state.Clones) { c.Add(&clone) })
+}
+
+// unregisterClone drops the clone at mainRepoPath whose origin was remoteURL from the
+// clone registry.
+func unregisterClone(mainRepoPath, remoteURL string) {
+	path, err := clonesRegistryPath()
+	if err != nil {
+		return
+	}
+	key := git.CloneKey(remoteURL, mainRepoPath)
+	_ = state.UpdateClones(path, func(c *state.Clones) { c.Remove(key) })
+}
+
+// loadWorktreeRegistry returns the state registry of the repository at mainRepoPath; it is
+// empty when there is none or it cannot be read.
+func loadWorktreeRegistry(mainRepoPath string) *state.Registry {
+	empty := &state.Registry{Version: state.Version}
+	registryPath, ok, _ := worktreeRegistryPath(mainRepoPath)
+	if !ok {
+		return empty
+	}
+	registry, err := state.Load(registryPath)
+	if err != nil {
+		return empty
+	}
+	return registry
+}
+
+// isWorktreeRegistered reports whether wtp created the worktree at path.
+func isWorktreeRegistered(mainRepoPath, path string) bool {
+	return loadWorktreeRegistry(mainRepoPath).Find(path) != nil
+}
+
+// withRegisteredProfile returns cfg with the hooks of the profile the worktree at path was
+// created with, so that its pre_remove or rerun post_create hooks match. A profile that
+// is no longer configured is ignored.
+func withRegisteredProfile(cfg *config.Config, mainRepoPath, path string) *config.Config {
+	wt := loadWorktreeRegistry(mainRepoPath).Find(path)
+	if wt == nil {
+		return cfg
+	}
+	if withProfile, ok := cfg.ForProfile(wt.Profile); ok {
+		return withProfile
+	}
+	return cfg
+}
+
+// worktreeRegistryPath returns the registry file of the repository at mainRepoPath; ok is
+// false, with a nil error, when the repository has no git directory.
+func worktreeRegistryPath(mainRepoPath string) (path string, ok bool, err error) {
+	path, err = state.Path(mainRepoPath)
+	if stderrors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	}
+	return path, err == nil, err
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/hooks"
+	"github.com/satococoa/wtp/v2/internal/state"
+)
+
+// useTempClonesRegistry points the clone registry at a file in a temporary directory for
+// the rest of the test and returns it.
+func useTempClonesRegistry(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), state.ClonesFileName)
+	original := clonesRegistryPath
+	clonesRegistryPath = func() (string, error) { return path, nil }
+	t.Cleanup(func() { clonesRegistryPath = original })
+	return path
+}
+
+func TestWorktreeRegistry(t *testing.T) {
+	clonesPath := useTempClonesRegistry(t)
+	mainRepoPath := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(mainRepoPath, ".git"), 0o755))
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: ".worktrees"}}
+	path := filepath.Join(t.TempDir(), "elsewhere")
+	require.NoError(t, os.Mkdir(path, 0o755))
+	assert.False(t, isWorktreeRegistered(mainRepoPath, path))
+
+	timings := []hooks.HookTiming{
+		{Index: 1, Type: config.HookTypeCopy},
+		{Index: 2, Type: config.HookTypeCommand, Err: fmt.Errorf("exit status 1")},
+	}
+	record := newProvisionRecord("feature/a", "", nil, timings, nil)
+	record.Profile = "frontend"
+	require.NoError(t, registerWorktree(cfg, mainRepoPath, path, record))
+	assert.True(t, isWorktreeRegistered(mainRepoPath, path))
+
+	wt := loadWorktreeRegistry(mainRepoPath).Find(path)
+	require.NotNil(t, wt)
+	assert.Equal(t, "feature/a", wt.Branch)
+	assert.Equal(t, "frontend", wt.Profile)
+	assert.Equal(t, state.ConfigHash(cfg), wt.ConfigHash)
+	assert.Equal(t, []state.HookResult{
+		{Index: 1, Type: config.HookTypeCopy, Status: state.HookOK},
+		{Index: 2, Type: config.HookTypeCommand, Status: state.HookFailed, Error: "exit status 1"},
+	}, wt.Hooks)
+
+	clones, err := state.LoadClones(clonesPath)
+	require.NoError(t, err)
+	require.Len(t, clones.Clones, 1, "the clone is recorded in the clone registry")
+	assert.Equal(t, mainRepoPath, clones.Clones[0].Path)
+
+	moved := filepath.Join(filepath.Dir(path), "moved")
+	require.NoError(t, os.Rename(path, moved))
+	require.NoError(t, moveRegisteredWorktree(mainRepoPath, wt.Path, moved))
+	assert.True(t, isWorktreeRegistered(mainRepoPath, moved))
+
+	require.NoError(t, unregisterWorktree(mainRepoPath, moved))
+	assert.False(t, isWorktreeRegistered(mainRepoPath, moved))
+}
+
+func TestWorktreeRegistry_NoGitDirectory(t *testing.T) {
+	mainRepoPath := t.TempDir()
+
+	assert.NoError(t, registerWorktree(nil, mainRepoPath, filepath.Join(mainRepoPath, "wt"), &provisionRecord{}))
+	assert.NoError(t, unregisterWorktree(mainRepoPath, filepath.Join(mainRepoPath, "wt")))
+	assert.Empty(t, loadWorktreeRegistry(mainRepoPath).Worktrees)
+	assert.NoDirExists(t, filepath.Join(mainRepoPath, state.DirName))
+}
+
+func TestWithRegisteredProfile(t *testing.T) {
+	useTempClonesRegistry(t)
+	mainRepoPath := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(mainRepoPath, ".git"), 0o755))
+	cfg := &config.Config{Profiles: map[string]config.Profile{
+		"backend": {Hooks: config.Hooks{PreRemove: []config.Hook{{Type: config.HookTypeCommand, Command: "make down"}}}},
+	}}
+	path := t.TempDir()
+
+	assert.Same(t, cfg, withRegisteredProfile(cfg, mainRepoPath, path))
+
+	require.NoError(t, registerWorktree(cfg, mainRepoPath, path, &provisionRecord{Profile: "backend"}))
+	assert.Len(t, withRegisteredProfile(cfg, mainRepoPath, path).Hooks.PreRemove, 1)
+
+	// A profile removed from the configuration since leaves the hooks as they are
+	require.NoError(t, registerWorktree(cfg, mainRepoPath, path, &provisionRecord{Profile: "gone"}))
+	assert.Same(t, cfg, withRegisteredProfile(cfg, mainRepoPath, path))
+}
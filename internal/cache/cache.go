@@ -0,0 +1,241 @@
+// Package cache keeps track of the entries of wtp's shared caches and evicts them. A cache
+// is a directory whose top-level children are its entries, such as "npm" in $WTP_CACHE_DIR
+// or "sha256-<hex>" in the download cache. An index next to them records when each entry
+// was last used, and by which worktrees. An entry is referenced while one of those
+// worktrees still exists, and referenced entries are never evicted by Select.
+package cache
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/satococoa/wtp/v2/internal/filelock"
+)
+
+const (
+	indexFileName = ".wtp-cache.json"
+	lockFileName  = ".wtp-cache.lock"
+	indexFileMode = 0o644
+)
+
+// Entry is one entry of a cache.
+type Entry struct {
+	Name string
+	Path string
+	// Size is the total size of the entry's regular files.
+	Size int64
+	// LastUsed is the later of the last recorded use and the newest modification inside
+	// the entry, which covers tools that write to the cache without wtp knowing.
+	LastUsed time.Time
+	// Refs are the existing worktrees that used the entry.
+	Refs []string
+}
+
+// Limits bound the size of a cache and the age of its entries; zero means no limit.
+type Limits struct {
+	MaxSize int64
+	MaxAge  time.Duration
+}
+
+// index is the content of the index file, keyed by entry name.
+type index struct {
+	Entries map[string]*indexEntry `json:"entries"`
+}
+
+type indexEntry struct {
+	LastUsed  time.Time `json:"last_used"`
+	Worktrees []string  `json:"worktrees,omitempty"`
+}
+
+// Use records that the worktree at worktreePath used the entry name of the cache in root.
+// The entry need not exist yet.
+func Use(root, name, worktreePath string, now time.Time) error {
+	return update(root, func(idx *index) error {
+		entry := idx.Entries[name]
+		if entry == nil {
+			entry = &indexEntry{}
+			idx.Entries[name] = entry
+		}
+		entry.LastUsed = now
+		if !slices.Contains(entry.Worktrees, worktreePath) {
+			entry.Worktrees = append(entry.Worktrees, worktreePath)
+		}
+		return nil
+	})
+}
+
+// List returns the entries of the cache in root, least recently used first. Children whose
+// name starts with "." or is listed in skip are not entries. A missing root is an empty
+// cache.
+func List(root string, skip ...string) ([]Entry, error) {
+	children, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	idx := readIndex(root)
+
+	entries := make([]Entry, 0, len(children))
+	for _, child := range children {
+		name := child.Name()
+		if strings.HasPrefix(name, ".") || slices.Contains(skip, name) {
+			continue
+		}
+		entry := Entry{Name: name, Path: filepath.Join(root, name)}
+		entry.Size, entry.LastUsed = usage(entry.Path)
+		if recorded := idx.Entries[name]; recorded != nil {
+			if recorded.LastUsed.After(entry.LastUsed) {
+				entry.LastUsed = recorded.LastUsed
+			}
+			entry.Refs = liveWorktrees(recorded.Worktrees)
+		}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].LastUsed.Before(entries[j].LastUsed) })
+	return entries, nil
+}
+
+// Select returns the entries to evict to keep the cache within limits: the unreferenced
+// entries unused for longer than MaxAge, then the least recently used unreferenced ones
+// until the rest fits in MaxSize. entries must be ordered as List returns them.
+func Select(entries []Entry, limits Limits, now time.Time) []Entry {
+	var total int64
+	for i := range entries {
+		total += entries[i].Size
+	}
+
+	var evict []Entry
+	for i := range entries {
+		entry := &entries[i]
+		if len(entry.Refs) > 0 {
+			continue
+		}
+		expired := limits.MaxAge > 0 && now.Sub(entry.LastUsed) > limits.MaxAge
+		overBudget := limits.MaxSize > 0 && total > limits.MaxSize
+		if expired || overBudget {
+			evict = append(evict, *entry)
+			total -= entry.Size
+		}
+	}
+	return evict
+}
+
+// Evict deletes entries from the cache in root and returns the ones it deleted. An entry
+// used again since it was listed is kept. The index also forgets worktrees that no longer
+// exist and entries that are gone.
+func Evict(root string, entries []Entry) ([]Entry, error) {
+	var evicted []Entry
+	err := update(root, func(idx *index) error {
+		var errs []error
+		for i := range entries {
+			entry := &entries[i]
+			if recorded := idx.Entries[entry.Name]; recorded != nil && recorded.LastUsed.After(entry.LastUsed) {
+				continue
+			}
+			if err := os.RemoveAll(entry.Path); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			delete(idx.Entries, entry.Name)
+			evicted = append(evicted, *entry)
+		}
+		idx.compact(root)
+		return stderrors.Join(errs...)
+	})
+	return evicted, err
+}
+
+// compact drops the worktrees that no longer exist and the entries that are gone and
+// unreferenced.
+func (idx *index) compact(root string) {
+	for name, entry := range idx.Entries {
+		entry.Worktrees = liveWorktrees(entry.Worktrees)
+		if _, err := os.Lstat(filepath.Join(root, name)); os.IsNotExist(err) && len(entry.Worktrees) == 0 {
+			delete(idx.Entries, name)
+		}
+	}
+}
+
+// update changes the index of the cache in root while holding its lock, which serializes
+// wtp processes using the same cache.
+func update(root string, change func(*index) error) (err error) {
+	unlock, err := filelock.Lock(filepath.Join(root, lockFileName))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if unlockErr := unlock(); err == nil {
+			err = unlockErr
+		}
+	}()
+
+	idx := readIndex(root)
+	changeErr := change(idx)
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(root, indexFileName+".tmp")
+	if err := os.WriteFile(tmp, data, indexFileMode); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(root, indexFileName)); err != nil {
+		return err
+	}
+	return changeErr
+}
+
+// readIndex reads the index of the cache in root; a missing or unreadable index is empty.
+func readIndex(root string) *index {
+	idx := &index{}
+	// #nosec G304 -- the index path is chosen by wtp
+	if data, err := os.ReadFile(filepath.Join(root, indexFileName)); err == nil {
+		_ = json.Unmarshal(data, idx)
+	}
+	if idx.Entries == nil {
+		idx.Entries = map[string]*indexEntry{}
+	}
+	return idx
+}
+
+// usage returns the total size of the regular files under path and the newest
+// modification time of anything in it. Symlinks are not followed.
+func usage(path string) (size int64, newest time.Time) {
+	_ = filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // count what can be read
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		if entry.Type().IsRegular() {
+			size += info.Size()
+		}
+		if info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+		return nil
+	})
+	return size, newest
+}
+
+// liveWorktrees returns the worktrees of paths that still exist.
+func liveWorktrees(paths []string) []string {
+	var live []string
+	for _, path := range paths {
+		if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
+			live = append(live, path)
+		}
+	}
+	return live
+}
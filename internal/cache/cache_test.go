@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeEntry creates the entry name in root holding size bytes, last modified at modTime.
+func writeEntry(t *testing.T, root, name string, size int, modTime time.Time) {
+	t.Helper()
+	dir := filepath.Join(root, name)
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	file := filepath.Join(dir, "data")
+	require.NoError(t, os.WriteFile(file, make([]byte, size), 0o644))
+	require.NoError(t, os.Chtimes(file, modTime, modTime))
+	require.NoError(t, os.Chtimes(dir, modTime, modTime))
+}
+
+// liveWorktree creates a directory that looks like a worktree.
+func liveWorktree(t *testing.T) string {
+	t.Helper()
+	path := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(path, ".git"), []byte("gitdir: /elsewhere\n"), 0o644))
+	return path
+}
+
+func TestList(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now().Truncate(time.Second)
+	writeEntry(t, root, "npm", 100, now.Add(-48*time.Hour))
+	writeEntry(t, root, "pip", 50, now.Add(-time.Hour))
+	writeEntry(t, root, "locks", 1, now)
+	worktree := liveWorktree(t)
+	require.NoError(t, Use(root, "npm", worktree, now))
+	require.NoError(t, Use(root, "npm", filepath.Join(t.TempDir(), "removed"), now))
+
+	entries, err := List(root, "locks")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "pip", entries[0].Name)
+	assert.Equal(t, int64(50), entries[0].Size)
+	assert.Empty(t, entries[0].Refs)
+	// A recorded use counts even when nothing in the entry changed
+	assert.Equal(t, "npm", entries[1].Name)
+	assert.True(t, entries[1].LastUsed.Equal(now))
+	assert.Equal(t, []string{worktree}, entries[1].Refs)
+
+	entries, err = List(filepath.Join(root, "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestSelect(t *testing.T) {
+	now := time.Now()
+	entries := []Entry{
+		{Name: "oldest", Size: 40, LastUsed: now.Add(-90 * 24 * time.Hour)},
+		{Name: "referenced", Size: 40, LastUsed: now.Add(-60 * 24 * time.Hour), Refs: []string{"/wt"}},
+		{Name: "older", Size: 30, LastUsed: now.Add(-20 * 24 * time.Hour)},
+		{Name: "recent", Size: 30, LastUsed: now.Add(-time.Hour)},
+	}
+
+	names := func(selected []Entry) []string {
+		var result []string
+		for _, entry := range selected {
+			result = append(result, entry.Name)
+		}
+		return result
+	}
+
+	assert.Empty(t, Select(entries, Limits{}, now))
+	assert.Equal(t, []string{"oldest"}, names(Select(entries, Limits{MaxAge: 30 * 24 * time.Hour}, now)))
+	assert.Equal(t, []string{"oldest", "older"}, names(Select(entries, Limits{MaxSize: 90}, now)))
+	// Referenced entries stay even when the budget cannot be met without them
+	assert.Equal(t, []string{"oldest", "older", "recent"}, names(Select(entries, Limits{MaxSize: 10}, now)))
+}
+
+func TestEvict(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now()
+	writeEntry(t, root, "npm", 10, now)
+	writeEntry(t, root, "pip", 10, now)
+	require.NoError(t, Use(root, "gone", filepath.Join(t.TempDir(), "removed"), now))
+
+	entries, err := List(root)
+	require.NoError(t, err)
+	// pip is used again after it was listed
+	require.NoError(t, Use(root, "pip", liveWorktree(t), now.Add(time.Minute)))
+
+	evicted, err := Evict(root, entries)
+	require.NoError(t, err)
+	require.Len(t, evicted, 1)
+	assert.Equal(t, "npm", evicted[0].Name)
+	assert.NoDirExists(t, filepath.Join(root, "npm"))
+	assert.DirExists(t, filepath.Join(root, "pip"))
+
+	idx := readIndex(root)
+	assert.NotContains(t, idx.Entries, "gone")
+	assert.Contains(t, idx.Entries, "pip")
+}
@@ -1,12 +1,22 @@
 // Package command provides helpers to build and execute git commands.
 package command
 
+import "strconv"
+
 // GitWorktreeAddOptions represents options for git worktree add command
 type GitWorktreeAddOptions struct {
 	Force  bool
 	Detach bool
 	Branch string
 	Track  string
+	// NoTrack creates the branch without an upstream, even when it starts from Track or
+	// another remote branch.
+	NoTrack bool
+	// NoCheckout creates the worktree without checking out any file.
+	NoCheckout bool
+	// Sparse lists the directories a sparse checkout of the new worktree includes; backends
+	// turn it into the commands setting it up. Empty checks out everything.
+	Sparse []string
 }
 
 // GitWorktreeAdd builds a git worktree add command
@@ -20,11 +30,19 @@ func GitWorktreeAdd(path, commitish string, opts GitWorktreeAddOptions) Command
 	if opts.Detach {
 		args = append(args, "--detach")
 	}
+	if opts.NoCheckout {
+		args = append(args, "--no-checkout")
+	}
 	if opts.Branch != "" {
 		args = append(args, "-b", opts.Branch)
 	}
-	if opts.Track != "" {
+	switch {
+	case opts.NoTrack:
+		args = append(args, "--no-track")
+	case opts.Track != "":
 		args = append(args, "--track")
+	}
+	if opts.Track != "" {
 		if !opts.Detach && opts.Branch == "" {
 			// When tracking without explicit branch, create branch with same name
 			args = append(args, "-b", extractBranchName(commitish))
@@ -87,6 +105,269 @@ func GitWorktreeList() Command {
 	}
 }
 
+// GitCheckout builds a git checkout command that switches the worktree at path to branch
+func GitCheckout(path, branch string) Command {
+	return Command{
+		Name:    "git",
+		Args:    []string{"checkout", branch},
+		WorkDir: path,
+	}
+}
+
+// GitSparseCheckoutSet builds a command that limits the worktree at path to the
+// directories paths, in cone mode
+func GitSparseCheckoutSet(path string, paths []string) Command {
+	return Command{
+		Name:    "git",
+		Args:    append([]string{"sparse-checkout", "set", "--"}, paths...),
+		WorkDir: path,
+	}
+}
+
+// GitCheckoutFiles builds a git checkout command that fills the worktree at path, created
+// with --no-checkout, with the files of HEAD
+func GitCheckoutFiles(path string) Command {
+	return Command{
+		Name:    "git",
+		Args:    []string{"checkout"},
+		WorkDir: path,
+	}
+}
+
+// GitSubmoduleUpdate builds a command that initializes and checks out the submodules of
+// the worktree at path, and with recursive those nested in them
+func GitSubmoduleUpdate(path string, recursive bool) Command {
+	args := []string{"submodule", "update", "--init"}
+	if recursive {
+		args = append(args, "--recursive")
+	}
+	return Command{
+		Name:    "git",
+		Args:    args,
+		WorkDir: path,
+	}
+}
+
+// GitUpstream builds a command that prints the upstream branch of the worktree at path
+func GitUpstream(path string) Command {
+	return Command{
+		Name:    "git",
+		Args:    []string{"rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}"},
+		WorkDir: path,
+	}
+}
+
+// GitStatusPorcelain builds a command that lists uncommitted changes in the worktree at path
+func GitStatusPorcelain(path string) Command {
+	return Command{
+		Name:    "git",
+		Args:    []string{"status", "--porcelain"},
+		WorkDir: path,
+	}
+}
+
+// GitRevListLeftRightCount builds a command that prints the number of commits only in
+// left and only in right, separated by a tab
+func GitRevListLeftRightCount(left, right string) Command {
+	return Command{
+		Name: "git",
+		Args: []string{"rev-list", "--left-right", "--count", left + "..." + right},
+	}
+}
+
+// GitRemoteGetURL builds a command that prints the fetch URL of remote
+func GitRemoteGetURL(remote string) Command {
+	return Command{
+		Name: "git",
+		Args: []string{"remote", "get-url", remote},
+	}
+}
+
+// GitWorktreeRepair builds a command that repairs the links between the repository and
+// its worktrees, e.g. after the main worktree was moved
+func GitWorktreeRepair() Command {
+	return Command{
+		Name: "git",
+		Args: []string{"worktree", "repair"},
+	}
+}
+
+// GitWorktreeMove builds a command that moves the worktree at from to to
+func GitWorktreeMove(from, to string) Command {
+	return Command{
+		Name: "git",
+		Args: []string{"worktree", "move", from, to},
+	}
+}
+
+// GitWorktreeLock builds a command that locks the worktree at path, giving reason unless
+// it is empty
+func GitWorktreeLock(path, reason string) Command {
+	args := []string{"worktree", "lock"}
+	if reason != "" {
+		args = append(args, "--reason", reason)
+	}
+	return Command{
+		Name: "git",
+		Args: append(args, path),
+	}
+}
+
+// GitWorktreeUnlock builds a command that unlocks the worktree at path
+func GitWorktreeUnlock(path string) Command {
+	return Command{
+		Name: "git",
+		Args: []string{"worktree", "unlock", path},
+	}
+}
+
+// GitBranchRename builds a command that renames branch from to to, also in the worktree
+// that has it checked out
+func GitBranchRename(from, to string) Command {
+	return Command{
+		Name: "git",
+		Args: []string{"branch", "-m", from, to},
+	}
+}
+
+// GitCheckIgnore builds a command that prints which of paths, relative to the worktree at
+// path, git ignores, one per line. It exits with status 1 when none of them is ignored
+func GitCheckIgnore(path string, paths []string) Command {
+	return Command{
+		Name:    "git",
+		Args:    append([]string{"-c", "core.quotePath=false", "check-ignore", "--"}, paths...),
+		WorkDir: path,
+	}
+}
+
+// GitBranchList builds a command that prints every local branch as its name, commit,
+// upstream, and upstream tracking state (e.g. "[gone]"), separated by tabs
+func GitBranchList() Command {
+	return Command{
+		Name: "git",
+		Args: []string{
+			"for-each-ref", "--format=%(refname:short)%09%(objectname)%09%(upstream:short)%09%(upstream:track)",
+			"refs/heads",
+		},
+	}
+}
+
+// GitBranchesMerged builds a command that prints the local branches whose tip is reachable
+// from target, one per line
+func GitBranchesMerged(target string) Command {
+	return Command{
+		Name: "git",
+		Args: []string{"for-each-ref", "--merged=" + target, "--format=%(refname:short)", "refs/heads"},
+	}
+}
+
+// GitRevParseVerify builds a command that prints the commit ref points to, and fails
+// quietly when there is none
+func GitRevParseVerify(ref string) Command {
+	return Command{
+		Name: "git",
+		Args: []string{"rev-parse", "--verify", "--quiet", ref + "^{commit}"},
+	}
+}
+
+// GitBranchReflog builds a command that prints the commits the local branch has pointed to,
+// newest first, one per line
+func GitBranchReflog(branch string) Command {
+	return Command{
+		Name: "git",
+		Args: []string{"reflog", "show", "--format=%H", "refs/heads/" + branch, "--"},
+	}
+}
+
+// GitRevListFirstParent builds a command that prints the commits on the first-parent history
+// of ref, the commits ref itself pointed to rather than ones merged into it, one per line
+func GitRevListFirstParent(ref string) Command {
+	return Command{
+		Name: "git",
+		Args: []string{"rev-list", "--first-parent", ref, "--"},
+	}
+}
+
+// GitLastCommitTime builds a command that prints the Unix time of the last commit in the
+// worktree at path
+func GitLastCommitTime(path string) Command {
+	return Command{
+		Name:    "git",
+		Args:    []string{"log", "-1", "--format=%ct"},
+		WorkDir: path,
+	}
+}
+
+// GitLastCommitSummary builds a command that prints the short hash, subject, and relative
+// date of the last commit in the worktree at path
+func GitLastCommitSummary(path string) Command {
+	return Command{
+		Name:    "git",
+		Args:    []string{"log", "-1", "--format=%h %s (%cr)"},
+		WorkDir: path,
+	}
+}
+
+// GitConfigGetRegexp builds a command that prints the repository config entries whose key
+// matches pattern, one "<key> <value>" line each; it exits with 1 when none does
+func GitConfigGetRegexp(pattern string) Command {
+	return Command{
+		Name: "git",
+		Args: []string{"config", "--local", "--get-regexp", pattern},
+	}
+}
+
+// GitConfigSet builds a command that sets key to value in the repository config
+func GitConfigSet(key, value string) Command {
+	return Command{
+		Name: "git",
+		Args: []string{"config", "--local", "--replace-all", key, value},
+	}
+}
+
+// GitConfigRemoveSection builds a command that removes a section, such as
+// "wtp-worktree.feature/auth", with all its keys from the repository config
+func GitConfigRemoveSection(section string) Command {
+	return Command{
+		Name: "git",
+		Args: []string{"config", "--local", "--remove-section", section},
+	}
+}
+
+// GitVersion builds a command that prints the installed git version
+func GitVersion() Command {
+	return Command{
+		Name: "git",
+		Args: []string{"--version"},
+	}
+}
+
+// GitFetchRef builds a command that fetches ref from remote into the local ref dest. It
+// is not forced, so a dest with commits of its own is left alone.
+func GitFetchRef(remote, ref, dest string) Command {
+	return Command{
+		Name: "git",
+		Args: []string{"fetch", remote, ref + ":" + dest},
+	}
+}
+
+// GitHubPRView builds a gh command that prints the number, title, and head branch of
+// GitHub pull request number as JSON
+func GitHubPRView(number int) Command {
+	return Command{
+		Name: "gh",
+		Args: []string{"pr", "view", strconv.Itoa(number), "--json", "number,title,headRefName"},
+	}
+}
+
+// GitLabMRView builds a glab command that prints GitLab merge request number as JSON
+func GitLabMRView(number int) Command {
+	return Command{
+		Name: "glab",
+		Args: []string{"mr", "view", strconv.Itoa(number), "--output", "json"},
+	}
+}
+
 // extractBranchName extracts branch name from a remote reference
 // e.g., "origin/feature" -> "feature"
 func extractBranchName(ref string) string {
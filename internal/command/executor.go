@@ -1,5 +1,7 @@
 package command
 
+import "context"
+
 // executor implements CommandExecutor interface
 type executor struct {
 	shell ShellExecutor
@@ -19,6 +21,14 @@ func NewRealExecutor() Executor {
 	}
 }
 
+// NewRealExecutorContext creates a command executor with real shell execution whose
+// commands are interrupted once ctx is done.
+func NewRealExecutorContext(ctx context.Context) Executor {
+	return &executor{
+		shell: NewRealShellExecutorContext(ctx),
+	}
+}
+
 // Execute executes the given commands in sequence and returns the results
 func (e *executor) Execute(commands []Command) (*ExecutionResult, error) {
 	result := &ExecutionResult{
@@ -1,7 +1,10 @@
 package command
 
 import (
+	"context"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -177,6 +180,47 @@ func TestCommandBuilder(t *testing.T) {
 			cmd.Args)
 	})
 
+	t.Run("should build git worktree add command with or without an upstream", func(t *testing.T) {
+		tracked := GitWorktreeAdd("../worktrees/feature/x", "origin/feature/x", GitWorktreeAddOptions{
+			Branch: "feature/x",
+			Track:  "origin/feature/x",
+		})
+		assert.Equal(t,
+			[]string{"worktree", "add", "-b", "feature/x", "--track", "../worktrees/feature/x", "origin/feature/x"},
+			tracked.Args)
+
+		untracked := GitWorktreeAdd("../worktrees/feature/x", "origin/feature/x", GitWorktreeAddOptions{
+			Branch:  "feature/x",
+			Track:   "origin/feature/x",
+			NoTrack: true,
+		})
+		assert.Equal(t,
+			[]string{"worktree", "add", "-b", "feature/x", "--no-track", "../worktrees/feature/x", "origin/feature/x"},
+			untracked.Args)
+	})
+
+	t.Run("should build sparse checkout commands", func(t *testing.T) {
+		cmd := GitWorktreeAdd("../worktrees/feature", "feature", GitWorktreeAddOptions{NoCheckout: true})
+		assert.Equal(t, []string{"worktree", "add", "--no-checkout", "../worktrees/feature", "feature"}, cmd.Args)
+
+		set := GitSparseCheckoutSet("../worktrees/feature", []string{"apps/web", "libs"})
+		assert.Equal(t, []string{"sparse-checkout", "set", "--", "apps/web", "libs"}, set.Args)
+		assert.Equal(t, "../worktrees/feature", set.WorkDir)
+
+		checkout := GitCheckoutFiles("../worktrees/feature")
+		assert.Equal(t, []string{"checkout"}, checkout.Args)
+		assert.Equal(t, "../worktrees/feature", checkout.WorkDir)
+	})
+
+	t.Run("should build git submodule update command", func(t *testing.T) {
+		top := GitSubmoduleUpdate("../worktrees/feature", false)
+		assert.Equal(t, []string{"submodule", "update", "--init"}, top.Args)
+		assert.Equal(t, "../worktrees/feature", top.WorkDir)
+
+		recursive := GitSubmoduleUpdate("../worktrees/feature", true)
+		assert.Equal(t, []string{"submodule", "update", "--init", "--recursive"}, recursive.Args)
+	})
+
 	t.Run("should build git worktree remove command", func(t *testing.T) {
 		// Given: a worktree path to remove
 		path := "../worktrees/feature"
@@ -227,6 +271,173 @@ func TestCommandBuilder(t *testing.T) {
 		assert.Equal(t, "git", cmd.Name)
 		assert.Equal(t, []string{"branch", "-D", "old-feature"}, cmd.Args)
 	})
+
+	t.Run("should build git checkout command in worktree", func(t *testing.T) {
+		// When: building a checkout command for a worktree
+		cmd := GitCheckout("../worktrees/feature", "feature/next")
+
+		// Then: command should run inside the worktree
+		assert.Equal(t, "git", cmd.Name)
+		assert.Equal(t, []string{"checkout", "feature/next"}, cmd.Args)
+		assert.Equal(t, "../worktrees/feature", cmd.WorkDir)
+	})
+}
+
+func TestGitStatusPorcelain(t *testing.T) {
+	cmd := GitStatusPorcelain("../worktrees/feature")
+
+	assert.Equal(t, "git", cmd.Name)
+	assert.Equal(t, []string{"status", "--porcelain"}, cmd.Args)
+	assert.Equal(t, "../worktrees/feature", cmd.WorkDir)
+}
+
+func TestGitRevListLeftRightCount(t *testing.T) {
+	cmd := GitRevListLeftRightCount("main", "feature/foo")
+
+	assert.Equal(t, "git", cmd.Name)
+	assert.Equal(t, []string{"rev-list", "--left-right", "--count", "main...feature/foo"}, cmd.Args)
+	assert.Empty(t, cmd.WorkDir)
+}
+
+func TestGitUpstream(t *testing.T) {
+	cmd := GitUpstream("../worktrees/feature")
+
+	assert.Equal(t, "git", cmd.Name)
+	assert.Equal(t, []string{"rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}"}, cmd.Args)
+	assert.Equal(t, "../worktrees/feature", cmd.WorkDir)
+}
+
+func TestGitRemoteGetURL(t *testing.T) {
+	cmd := GitRemoteGetURL("origin")
+
+	assert.Equal(t, "git", cmd.Name)
+	assert.Equal(t, []string{"remote", "get-url", "origin"}, cmd.Args)
+}
+
+func TestGitWorktreeRepair(t *testing.T) {
+	cmd := GitWorktreeRepair()
+
+	assert.Equal(t, "git", cmd.Name)
+	assert.Equal(t, []string{"worktree", "repair"}, cmd.Args)
+}
+
+func TestGitWorktreeMove(t *testing.T) {
+	cmd := GitWorktreeMove("/old/feature", "/new/feature")
+
+	assert.Equal(t, "git", cmd.Name)
+	assert.Equal(t, []string{"worktree", "move", "/old/feature", "/new/feature"}, cmd.Args)
+}
+
+func TestGitWorktreeLock(t *testing.T) {
+	cmd := GitWorktreeLock("/wt/feature", "")
+	assert.Equal(t, "git", cmd.Name)
+	assert.Equal(t, []string{"worktree", "lock", "/wt/feature"}, cmd.Args)
+
+	cmd = GitWorktreeLock("/wt/feature", "on a USB drive")
+	assert.Equal(t, []string{"worktree", "lock", "--reason", "on a USB drive", "/wt/feature"}, cmd.Args)
+
+	cmd = GitWorktreeUnlock("/wt/feature")
+	assert.Equal(t, []string{"worktree", "unlock", "/wt/feature"}, cmd.Args)
+}
+
+func TestGitBranchRename(t *testing.T) {
+	cmd := GitBranchRename("feature/old", "feature/new")
+
+	assert.Equal(t, "git", cmd.Name)
+	assert.Equal(t, []string{"branch", "-m", "feature/old", "feature/new"}, cmd.Args)
+}
+
+func TestGitConfigCommands(t *testing.T) {
+	cmd := GitConfigGetRegexp(`^wtp-worktree\.`)
+	assert.Equal(t, "git", cmd.Name)
+	assert.Equal(t, []string{"config", "--local", "--get-regexp", `^wtp-worktree\.`}, cmd.Args)
+
+	cmd = GitConfigSet("wtp-worktree.feature/a.path", "/worktrees/feature/a")
+	assert.Equal(t, []string{"config", "--local", "--replace-all", "wtp-worktree.feature/a.path",
+		"/worktrees/feature/a"}, cmd.Args)
+
+	cmd = GitConfigRemoveSection("wtp-worktree.feature/a")
+	assert.Equal(t, []string{"config", "--local", "--remove-section", "wtp-worktree.feature/a"}, cmd.Args)
+}
+
+func TestGitVersion(t *testing.T) {
+	cmd := GitVersion()
+
+	assert.Equal(t, "git", cmd.Name)
+	assert.Equal(t, []string{"--version"}, cmd.Args)
+}
+
+func TestGitFetchRef(t *testing.T) {
+	cmd := GitFetchRef("origin", "refs/pull/42/head", "refs/heads/pr/42")
+
+	assert.Equal(t, "git", cmd.Name)
+	assert.Equal(t, []string{"fetch", "origin", "refs/pull/42/head:refs/heads/pr/42"}, cmd.Args)
+}
+
+func TestPullRequestView(t *testing.T) {
+	gh := GitHubPRView(42)
+	assert.Equal(t, "gh", gh.Name)
+	assert.Equal(t, []string{"pr", "view", "42", "--json", "number,title,headRefName"}, gh.Args)
+
+	glab := GitLabMRView(42)
+	assert.Equal(t, "glab", glab.Name)
+	assert.Equal(t, []string{"mr", "view", "42", "--output", "json"}, glab.Args)
+}
+
+func TestGitCheckIgnore(t *testing.T) {
+	cmd := GitCheckIgnore("/worktrees/feature", []string{"node_modules", "dist"})
+
+	assert.Equal(t, "git", cmd.Name)
+	assert.Equal(t, []string{"-c", "core.quotePath=false", "check-ignore", "--", "node_modules", "dist"}, cmd.Args)
+	assert.Equal(t, "/worktrees/feature", cmd.WorkDir)
+}
+
+func TestGitBranchList(t *testing.T) {
+	cmd := GitBranchList()
+
+	assert.Equal(t, "git", cmd.Name)
+	assert.Equal(t, []string{
+		"for-each-ref", "--format=%(refname:short)%09%(objectname)%09%(upstream:short)%09%(upstream:track)",
+		"refs/heads",
+	}, cmd.Args)
+}
+
+func TestGitBranchesMerged(t *testing.T) {
+	cmd := GitBranchesMerged("origin/main")
+
+	assert.Equal(t, []string{"for-each-ref", "--merged=origin/main", "--format=%(refname:short)", "refs/heads"}, cmd.Args)
+}
+
+func TestGitRevParseVerify(t *testing.T) {
+	cmd := GitRevParseVerify("origin/main")
+
+	assert.Equal(t, []string{"rev-parse", "--verify", "--quiet", "origin/main^{commit}"}, cmd.Args)
+}
+
+func TestGitBranchReflog(t *testing.T) {
+	cmd := GitBranchReflog("feature/auth")
+
+	assert.Equal(t, []string{"reflog", "show", "--format=%H", "refs/heads/feature/auth", "--"}, cmd.Args)
+}
+
+func TestGitRevListFirstParent(t *testing.T) {
+	cmd := GitRevListFirstParent("origin/main")
+
+	assert.Equal(t, []string{"rev-list", "--first-parent", "origin/main", "--"}, cmd.Args)
+}
+
+func TestGitLastCommitTime(t *testing.T) {
+	cmd := GitLastCommitTime("/worktrees/feature")
+
+	assert.Equal(t, []string{"log", "-1", "--format=%ct"}, cmd.Args)
+	assert.Equal(t, "/worktrees/feature", cmd.WorkDir)
+}
+
+func TestGitLastCommitSummary(t *testing.T) {
+	cmd := GitLastCommitSummary("/worktrees/feature")
+
+	assert.Equal(t, []string{"log", "-1", "--format=%h %s (%cr)"}, cmd.Args)
+	assert.Equal(t, "/worktrees/feature", cmd.WorkDir)
 }
 
 // Test real executor functions
@@ -336,6 +547,24 @@ func TestRealShellExecutor(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, "test", output) // TrimSpace removes newlines and spaces
 	})
+
+	t.Run("should interrupt the command once the context is done", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("uses sleep")
+		}
+		// Given: a shell executor whose context times out quickly
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		shell := NewRealShellExecutorContext(ctx)
+
+		// When: executing a command that runs much longer
+		start := time.Now()
+		_, err := shell.Execute("sleep", []string{"10"}, "")
+
+		// Then: should stop it and report the deadline
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), InterruptGracePeriod)
+	})
 }
 
 // Mock implementation for testing
@@ -1,26 +1,59 @@
 package command
 
 import (
+	"context"
+	"os"
 	"os/exec"
 	"strings"
+	"time"
 )
 
+// InterruptGracePeriod is how long a command stopped through its context gets to exit
+// after the interrupt before it is killed.
+const InterruptGracePeriod = 5 * time.Second
+
 // realShellExecutor implements ShellExecutor using os/exec
-type realShellExecutor struct{}
+type realShellExecutor struct {
+	ctx context.Context
+}
 
 // NewRealShellExecutor creates a new shell executor that executes real commands
 func NewRealShellExecutor() ShellExecutor {
-	return &realShellExecutor{}
+	return &realShellExecutor{ctx: context.Background()}
+}
+
+// NewRealShellExecutorContext creates a shell executor whose commands are interrupted once
+// ctx is done, e.g. when an operation's timeout passes.
+func NewRealShellExecutorContext(ctx context.Context) ShellExecutor {
+	return &realShellExecutor{ctx: ctx}
 }
 
 // Execute runs the command using os/exec
-func (*realShellExecutor) Execute(name string, args []string, workDir string) (string, error) {
-	cmd := exec.Command(name, args...)
+func (e *realShellExecutor) Execute(name string, args []string, workDir string) (string, error) {
+	cmd := exec.CommandContext(e.ctx, name, args...)
+	InterruptOnCancel(cmd)
 
 	if workDir != "" {
 		cmd.Dir = workDir
 	}
 
 	output, err := cmd.CombinedOutput()
+	if ctxErr := e.ctx.Err(); ctxErr != nil && err != nil {
+		err = ctxErr
+	}
 	return strings.TrimSpace(string(output)), err
 }
+
+// InterruptOnCancel makes cmd, created with exec.CommandContext, receive an interrupt like
+// Ctrl-C sends when its context is done, so that git can remove its lock files, and kills
+// it if it is still running InterruptGracePeriod later. Where interrupts cannot be sent,
+// as on Windows, it is killed right away.
+func InterruptOnCancel(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		if err := cmd.Process.Signal(os.Interrupt); err != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+	cmd.WaitDelay = InterruptGracePeriod
+}
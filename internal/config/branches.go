@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// BranchOverlay is a partial configuration that applies to branches matching Pattern.
+type BranchOverlay struct {
+	Pattern  string   `yaml:"-"`
+	Defaults Defaults `yaml:"defaults,omitempty"`
+	Hooks    Hooks    `yaml:"hooks,omitempty"`
+}
+
+// BranchOverlays is the 'branches' section of a config file. It is written as a mapping
+// from glob pattern to overlay and keeps the order of the file, which is the order
+// matching overlays are applied in.
+type BranchOverlays []BranchOverlay
+
+// UnmarshalYAML decodes a pattern → overlay mapping while preserving its order.
+func (b *BranchOverlays) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: 'branches' must map branch patterns to configuration", node.Line)
+	}
+
+	overlays := make(BranchOverlays, 0, len(node.Content)/2) //nolint:mnd // key/value pairs
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		overlay := BranchOverlay{}
+		if err := node.Content[i+1].Decode(&overlay); err != nil {
+			return err
+		}
+		overlay.Pattern = node.Content[i].Value
+		overlays = append(overlays, overlay)
+	}
+	*b = overlays
+	return nil
+}
+
+// MarshalYAML encodes the overlays back into a pattern → overlay mapping.
+func (b BranchOverlays) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for i := range b {
+		value := &yaml.Node{}
+		if err := value.Encode(&b[i]); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: b[i].Pattern}, value)
+	}
+	return node, nil
+}
+
+// MatchBranchPattern reports whether branch matches a 'branches' glob pattern.
+// Patterns use path.Match syntax, so '*' stops at '/'; a trailing "/**" matches
+// everything below a prefix (e.g. "feature/**" matches "feature/a/b").
+func MatchBranchPattern(pattern, branch string) bool {
+	if matched, err := path.Match(pattern, branch); err == nil && matched {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+		return strings.HasPrefix(branch, prefix+"/")
+	}
+	return false
+}
+
+// ForBranch returns the configuration with every overlay matching branch merged on top,
+// in the order they are listed. The overlays themselves are kept on the result.
+func (c *Config) ForBranch(branch string) *Config {
+	result := c
+	if branch == "" {
+		return result
+	}
+	for i := range c.Branches {
+		overlay := &c.Branches[i]
+		if !MatchBranchPattern(overlay.Pattern, branch) {
+			continue
+		}
+		origins := c.movedOrigins(map[string]string{"branches." + overlay.Pattern + ".": ""})
+		result = MergeConfig(result, &Config{Defaults: overlay.Defaults, Hooks: overlay.Hooks, origins: origins})
+	}
+	return result
+}
+
+// BaseDirs returns the default base_dir followed by the base_dir of every overlay that sets one.
+func (c *Config) BaseDirs() []string {
+	dirs := []string{c.Defaults.BaseDir}
+	for i := range c.Branches {
+		if baseDir := c.Branches[i].Defaults.BaseDir; baseDir != "" {
+			dirs = append(dirs, baseDir)
+		}
+	}
+	return dirs
+}
+
+func (b BranchOverlays) validate() error {
+	for i := range b {
+		overlay := &b[i]
+		if overlay.Pattern == "" {
+			return fmt.Errorf("branch pattern must not be empty")
+		}
+		if _, err := path.Match(overlay.Pattern, ""); err != nil {
+			return fmt.Errorf("invalid branch pattern %q: %w", overlay.Pattern, err)
+		}
+		if err := overlay.Defaults.validate(); err != nil {
+			return fmt.Errorf("branches %q: %w", overlay.Pattern, err)
+		}
+		if overlay.Defaults.RuntimeDir != "" {
+			// State must be found again after 'wtp checkout' switches the worktree's branch
+			return fmt.Errorf("branches %q: defaults.runtime_dir cannot be set per branch", overlay.Pattern)
+		}
+		if err := overlay.Hooks.validate(); err != nil {
+			return fmt.Errorf("branches %q: %w", overlay.Pattern, err)
+		}
+	}
+	return nil
+}
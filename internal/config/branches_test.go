@@ -0,0 +1,157 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+)
+
+func TestMatchBranchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		branch  string
+		want    bool
+	}{
+		{"release/*", "release/1.0", true},
+		{"release/*", "release/1.0/hotfix", false},
+		{"feature/**", "feature/a/b", true},
+		{"feature/**", "feature", false},
+		{"main", "main", true},
+		{"*", "main", true},
+		{"*", "feature/x", false},
+		{"hotfix-[0-9]*", "hotfix-42", true},
+	}
+
+	for _, tt := range tests {
+		if got := MatchBranchPattern(tt.pattern, tt.branch); got != tt.want {
+			t.Errorf("MatchBranchPattern(%q, %q) = %v, want %v", tt.pattern, tt.branch, got, tt.want)
+		}
+	}
+}
+
+func TestLoadConfig_BranchOverlays(t *testing.T) {
+	repoDir := t.TempDir()
+	original := userHomeDir
+	userHomeDir = func() (string, error) { return t.TempDir(), nil }
+	t.Cleanup(func() { userHomeDir = original })
+
+	content := `version: "1.0"
+defaults:
+  base_dir: "../worktrees"
+hooks:
+  post_create:
+    - type: command
+      command: "npm ci"
+branches:
+  release/*:
+    defaults:
+      base_dir: "../releases"
+    hooks:
+      post_create:
+        - type: command
+          command: "make release-env"
+  "**/wip":
+    hooks:
+      post_create:
+        - type: command
+          command: "never"
+  release/**:
+    hooks:
+      pre_remove:
+        - type: command
+          command: "make archive"
+`
+	if err := os.WriteFile(filepath.Join(repoDir, ConfigFileName), []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := LoadConfig(repoDir, "release/2.0")
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.Defaults.BaseDir != "../releases" {
+		t.Errorf("Expected overlay base_dir, got %s", cfg.Defaults.BaseDir)
+	}
+	if len(cfg.Hooks.PostCreate) != 2 || cfg.Hooks.PostCreate[1].Command != "make release-env" {
+		t.Errorf("Expected overlay hook appended after base hooks, got %+v", cfg.Hooks.PostCreate)
+	}
+	if len(cfg.Hooks.PreRemove) != 1 {
+		t.Errorf("Expected every matching overlay to apply, got %+v", cfg.Hooks.PreRemove)
+	}
+
+	cfg, err = LoadConfig(repoDir, "feature/login")
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.Defaults.BaseDir != "../worktrees" || len(cfg.Hooks.PostCreate) != 1 {
+		t.Errorf("Expected no overlay for feature/login, got %+v", cfg)
+	}
+	if got := cfg.BaseDirs(); len(got) != 2 || got[1] != "../releases" {
+		t.Errorf("BaseDirs() = %v", got)
+	}
+}
+
+func TestLoadConfig_InvalidBranchOverlay(t *testing.T) {
+	repoDir := t.TempDir()
+	original := userHomeDir
+	userHomeDir = func() (string, error) { return t.TempDir(), nil }
+	t.Cleanup(func() { userHomeDir = original })
+
+	content := `branches:
+  release/*:
+    hooks:
+      post_create:
+        - type: copy
+`
+	if err := os.WriteFile(filepath.Join(repoDir, ConfigFileName), []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	// Overlays are validated even when they do not match the branch
+	_, err := LoadConfig(repoDir, "main")
+	if err == nil || !strings.Contains(err.Error(), `branches "release/*"`) {
+		t.Errorf("Expected overlay validation error, got %v", err)
+	}
+}
+
+func TestBranchOverlays_RoundTripKeepsOrder(t *testing.T) {
+	cfg := &Config{Branches: BranchOverlays{
+		{Pattern: "release/*", Defaults: Defaults{BaseDir: "../releases"}},
+		{Pattern: "hotfix/*", Hooks: Hooks{PostCreate: []Hook{{Type: HookTypeCommand, Command: "echo"}}}},
+	}}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if strings.Index(string(data), "release/*") > strings.Index(string(data), "hotfix/*") {
+		t.Errorf("Expected overlays to keep their order:\n%s", data)
+	}
+
+	var decoded Config
+	if err := yaml.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if len(decoded.Branches) != 2 || decoded.Branches[0].Pattern != "release/*" ||
+		decoded.Branches[1].Hooks.PostCreate[0].Command != "echo" {
+		t.Errorf("Unexpected round trip result: %+v", decoded.Branches)
+	}
+}
+
+func TestBranchOverlays_RejectsList(t *testing.T) {
+	var cfg Config
+	if err := yaml.Unmarshal([]byte("branches:\n  - release/*\n"), &cfg); err == nil {
+		t.Error("Expected error for a list-valued 'branches' section")
+	}
+}
+
+func TestBranchOverlays_RejectRuntimeDir(t *testing.T) {
+	overlays := BranchOverlays{{Pattern: "release/*", Defaults: Defaults{RuntimeDir: "/tmp/runtime"}}}
+	err := overlays.validate()
+	if err == nil || !strings.Contains(err.Error(), "runtime_dir cannot be set per branch") {
+		t.Errorf("Expected runtime_dir overlay error, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const sizeUnit = 1024
+
+// sizeSuffixes maps the units accepted in cache.max_size to their multiplier. Decimal and
+// binary spellings both mean powers of 1024, like the sizes wtp prints.
+var sizeSuffixes = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"TIB", sizeUnit * sizeUnit * sizeUnit * sizeUnit},
+	{"GIB", sizeUnit * sizeUnit * sizeUnit},
+	{"MIB", sizeUnit * sizeUnit},
+	{"KIB", sizeUnit},
+	{"TB", sizeUnit * sizeUnit * sizeUnit * sizeUnit},
+	{"GB", sizeUnit * sizeUnit * sizeUnit},
+	{"MB", sizeUnit * sizeUnit},
+	{"KB", sizeUnit},
+	{"T", sizeUnit * sizeUnit * sizeUnit * sizeUnit},
+	{"G", sizeUnit * sizeUnit * sizeUnit},
+	{"M", sizeUnit * sizeUnit},
+	{"K", sizeUnit},
+	{"B", 1},
+}
+
+// Cache limits the shared caches: the directory command hooks get in $WTP_CACHE_DIR and
+// the download cache. 'wtp cache gc' evicts the least recently used entries no live
+// worktree refers to until both limits hold; the zero value keeps everything.
+type Cache struct {
+	// MaxSize is the size budget of each cache, e.g. "10GB"; empty means no limit.
+	MaxSize string `yaml:"max_size,omitempty"`
+	// MaxAge evicts entries unused for longer, e.g. "30d" or "720h"; empty means no limit.
+	MaxAge string `yaml:"max_age,omitempty"`
+}
+
+// MaxSizeBytes returns cache.max_size in bytes, or 0 when it is unset.
+func (c *Cache) MaxSizeBytes() int64 {
+	size, _ := parseSize(c.MaxSize) // validated when loaded
+	return size
+}
+
+// MaxAgeDuration returns cache.max_age, or 0 when it is unset.
+func (c *Cache) MaxAgeDuration() time.Duration {
+	d, _ := parseAge(c.MaxAge) // validated when loaded
+	return d
+}
+
+func (c *Cache) validate() error {
+	if _, err := parseSize(c.MaxSize); err != nil {
+		return fmt.Errorf("invalid cache.max_size: %w", err)
+	}
+	if _, err := parseAge(c.MaxAge); err != nil {
+		return fmt.Errorf("invalid cache.max_age: %w", err)
+	}
+	return nil
+}
+
+// mergeCache applies the fields override sets on top of base.
+func mergeCache(base, override Cache) Cache {
+	result := base
+	if override.MaxSize != "" {
+		result.MaxSize = override.MaxSize
+	}
+	if override.MaxAge != "" {
+		result.MaxAge = override.MaxAge
+	}
+	return result
+}
+
+// parseSize accepts a byte count with an optional unit, such as "500MB", "10G", or "1.5GiB".
+func parseSize(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	number, multiplier := strings.TrimSpace(s), int64(1)
+	upper := strings.ToUpper(number)
+	for _, unit := range sizeSuffixes {
+		if strings.HasSuffix(upper, unit.suffix) {
+			number, multiplier = strings.TrimSpace(number[:len(number)-len(unit.suffix)]), unit.multiplier
+			break
+		}
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: use a number with an optional unit such as MB or GB", s)
+	}
+	if value <= 0 {
+		return 0, fmt.Errorf("must be positive, got %s", s)
+	}
+	return int64(value * float64(multiplier)), nil
+}
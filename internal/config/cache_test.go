@@ -0,0 +1,54 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"512", 512, false},
+		{"10KB", 10 * 1024, false},
+		{"500mb", 500 * 1024 * 1024, false},
+		{"2G", 2 * 1024 * 1024 * 1024, false},
+		{"1.5GiB", 3 * 512 * 1024 * 1024, false},
+		{"0GB", 0, true},
+		{"lots", 0, true},
+		{"10 XB", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseSize(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSize(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseSize(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_Cache(t *testing.T) {
+	base := &Config{Cache: Cache{MaxSize: "10GB", MaxAge: "30d"}}
+	merged := MergeConfig(base, &Config{Cache: Cache{MaxAge: "7d"}})
+	if merged.Cache.MaxSizeBytes() != 10*1024*1024*1024 {
+		t.Errorf("MaxSizeBytes() = %d, want 10 GiB", merged.Cache.MaxSizeBytes())
+	}
+	if merged.Cache.MaxAgeDuration() != 7*24*time.Hour {
+		t.Errorf("MaxAgeDuration() = %s, want 168h", merged.Cache.MaxAgeDuration())
+	}
+
+	for _, cache := range []Cache{{MaxSize: "big"}, {MaxAge: "-1d"}} {
+		cfg := &Config{Defaults: Defaults{BaseDir: DefaultBaseDir}, Cache: cache}
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Validate() should reject %+v", cache)
+		}
+	}
+}
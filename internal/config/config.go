@@ -2,10 +2,15 @@
 package config
 
 import (
+	"encoding/hex"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"go.yaml.in/yaml/v3"
 )
@@ -15,32 +20,293 @@ type Config struct {
 	Version  string   `yaml:"version"`
 	Defaults Defaults `yaml:"defaults,omitempty"`
 	Hooks    Hooks    `yaml:"hooks,omitempty"`
+	Policy   Policy   `yaml:"policy,omitempty"`
+	// Verify lists the checks run after the post_create hooks and by 'wtp verify'.
+	Verify []Check `yaml:"verify,omitempty"`
+	// Branches holds per-branch overlays; see ForBranch.
+	Branches BranchOverlays `yaml:"branches,omitempty"`
+	// Hibernate lists globs, relative to the worktree, of regenerable artifacts (e.g.
+	// "node_modules") that 'wtp hibernate' removes.
+	Hibernate []string `yaml:"hibernate,omitempty"`
+	// Cache limits the shared caches 'wtp cache gc' trims.
+	Cache Cache `yaml:"cache,omitempty"`
+	// Map exports the branch-to-directory map for other tools.
+	Map WorktreeMap `yaml:"map,omitempty"`
+	// Integrations configures the commands that hand worktrees to other tools, such as
+	// 'wtp tmux' and 'wtp code'.
+	Integrations Integrations `yaml:"integrations,omitempty"`
+	// Profiles holds named hook sets selected with 'wtp add --profile'; see ForProfile.
+	Profiles map[string]Profile `yaml:"profiles,omitempty"`
+
+	// unknownKeys are the keys of the file this was decoded from that name no setting;
+	// LoadConfig reports them in strict mode.
+	unknownKeys []UnknownKey
+	// origins maps the dotted keys of the values set in the files this was loaded from,
+	// and of their list items, to the file each came from; see Origin.
+	origins map[string]string
 }
 
 // Defaults represents default configuration values
 type Defaults struct {
 	BaseDir string `yaml:"base_dir,omitempty"`
+	// HookTimeout is the default 'timeout' for command hooks (e.g. "10m"); empty means no limit.
+	HookTimeout string `yaml:"hook_timeout,omitempty"`
+	// OperationTimeout is the default '--timeout' of 'wtp add' and 'wtp exec' (e.g. "30m"):
+	// once it passes, running git commands, hooks, and commands are stopped. Empty means no limit.
+	OperationTimeout string `yaml:"operation_timeout,omitempty"`
+	// LockTimeout is how long 'wtp add', 'wtp remove', and 'wtp prune' wait for another wtp
+	// command working on the repository to finish (e.g. "2m"); empty means DefaultLockTimeout.
+	LockTimeout string `yaml:"lock_timeout,omitempty"`
+	// HookConcurrency caps how many hooks of one group run at once; 0 means no limit.
+	HookConcurrency int `yaml:"hook_concurrency,omitempty"`
+	// MaxConcurrentProvisions caps how many wtp processes on this machine run post_create
+	// hooks at once; others wait in line. 0 means no limit.
+	MaxConcurrentProvisions int `yaml:"max_concurrent_provisions,omitempty"`
+	// MaintenanceInterval is the minimum time between two 'wtp maintain' runs (e.g. "24h");
+	// empty means every run executes the maintenance hooks.
+	MaintenanceInterval string `yaml:"maintenance_interval,omitempty"`
+	// ReadOnly makes every command that changes worktrees, branches, or files fail.
+	ReadOnly bool `yaml:"readonly,omitempty"`
+	// Strict makes keys that name no setting, such as a misspelled hook list, an error
+	// instead of being ignored. The --strict flag and WTP_STRICT do the same.
+	Strict bool `yaml:"strict,omitempty"`
+	// Slug controls ${BRANCH_SLUG} and, when set, worktree directory names.
+	Slug SlugPolicy `yaml:"slug,omitempty"`
+	// WorktreeDir is the template for a worktree's directory under base_dir, such as
+	// "${BRANCH_SLUG}"; empty means the branch name (or its slug, with defaults.slug set).
+	WorktreeDir string `yaml:"worktree_dir,omitempty"`
+	// RuntimeDir moves the directory wtp keeps each worktree's logs, manifests, and state
+	// in out of the worktree: <runtime_dir>/<worktree key>. Empty means <worktree>/.wtp.
+	RuntimeDir string `yaml:"runtime_dir,omitempty"`
+	// HookLogDir is where command hooks with 'output: file' write their log files, relative
+	// to the worktree. Empty means the "logs" directory of the runtime directory.
+	HookLogDir string `yaml:"hook_log_dir,omitempty"`
+	// PruneAfter is how long a worktree may go untouched before 'wtp prune' offers to
+	// remove it, e.g. "30d" or "720h"; empty means never.
+	PruneAfter string `yaml:"prune_after,omitempty"`
+	// Env is merged into the env of every hook; a hook's own env wins for the same name.
+	Env map[string]string `yaml:"env,omitempty"`
+	// AfterAdd is what 'wtp add' does once the worktree is ready; see the AfterAdd constants.
+	// Empty only prints the 'wtp cd' hint.
+	AfterAdd string `yaml:"after_add,omitempty"`
+	// Editor is the command 'wtp open' and after_add open-editor run with the worktree's
+	// path appended, e.g. "code" or "idea"; empty means $VISUAL, else $EDITOR.
+	Editor string `yaml:"editor,omitempty"`
+	// Sparse lists the directories new worktrees check out, with git sparse-checkout in
+	// cone mode; empty checks out everything. A profile's sparse and 'wtp add --sparse'
+	// replace it.
+	Sparse []string `yaml:"sparse,omitempty"`
+	// AutoTrack makes a branch 'wtp add' creates from a remote branch track it as its
+	// upstream; nil means true. The --track and --no-track flags of 'wtp add' override it.
+	AutoTrack *bool `yaml:"auto_track,omitempty"`
+	// Shell runs command hooks; see the Shell constants. Empty means sh, or PowerShell on Windows.
+	Shell string `yaml:"shell,omitempty"`
+	// NestedWorktrees is what 'wtp add' does when the new worktree would be inside another
+	// worktree or git repository; see the NestedWorktrees constants. Empty means warn.
+	NestedWorktrees string `yaml:"nested_worktrees,omitempty"`
+	// Submodules is how 'wtp add' initializes the submodules of a new worktree before its
+	// hooks run; see the Submodules constants. Empty means none.
+	Submodules string `yaml:"submodules,omitempty"`
+	// VCS is the version control system worktrees are created with; see the VCS constants.
+	// Empty means git.
+	VCS string `yaml:"vcs,omitempty"`
 }
 
-// Hooks represents the post-create hooks configuration
+// Actions for defaults.after_add
+const (
+	AfterAddPrintPath  = "print-path"
+	AfterAddCopyPath   = "copy-path"
+	AfterAddCd         = "cd"
+	AfterAddOpenEditor = "open-editor"
+)
+
+// Where a command hook's output goes, set with its 'output'.
+const (
+	// HookOutputInherit streams the output to the terminal as it is written, the default.
+	HookOutputInherit = "inherit"
+	// HookOutputCapture holds the output back and shows it only when the hook fails.
+	HookOutputCapture = "capture"
+	// HookOutputFile writes the output to a log file in defaults.hook_log_dir.
+	HookOutputFile = "file"
+)
+
+// Values of defaults.nested_worktrees
+const (
+	NestedWorktreesWarn  = "warn"
+	NestedWorktreesError = "error"
+	NestedWorktreesAllow = "allow"
+)
+
+// Values of defaults.submodules and 'wtp add --submodules'
+const (
+	// SubmodulesRecursive runs 'git submodule update --init --recursive'.
+	SubmodulesRecursive = "recursive"
+	// SubmodulesTop runs 'git submodule update --init', leaving nested submodules alone.
+	SubmodulesTop = "top"
+	// SubmodulesNone leaves the submodules uninitialized, the default.
+	SubmodulesNone = "none"
+)
+
+// Values of defaults.vcs. VCSJujutsu creates jj workspaces instead of git worktrees; it
+// is experimental and needs a jj repository colocated with git.
+const (
+	VCSGit     = "git"
+	VCSJujutsu = "jj"
+)
+
+// Operating systems a hook's 'os' can list, by their Go names.
+const (
+	OSLinux   = "linux"
+	OSDarwin  = "darwin"
+	OSWindows = "windows"
+	OSFreeBSD = "freebsd"
+)
+
+// Shells for defaults.shell and a command or script hook's 'shell'. PowerShell is "pwsh" (PowerShell 7)
+// or "powershell" (Windows PowerShell 5.1).
+const (
+	ShellSh         = "sh"
+	ShellBash       = "bash"
+	ShellPwsh       = "pwsh"
+	ShellPowerShell = "powershell"
+	ShellCmd        = "cmd"
+)
+
+// Hooks represents the lifecycle hooks configuration
 type Hooks struct {
-	PostCreate []Hook `yaml:"post_create,omitempty"`
+	PostCreate   []Hook `yaml:"post_create,omitempty"`
+	PreRemove    []Hook `yaml:"pre_remove,omitempty"`
+	PostCheckout []Hook `yaml:"post_checkout,omitempty"`
+	// PostRemove hooks run in the main worktree after 'wtp remove' or 'wtp prune' removed
+	// a worktree.
+	PostRemove []Hook `yaml:"post_remove,omitempty"`
+	// PostPrune hooks run once in the main worktree after 'wtp prune' removed worktrees.
+	PostPrune []Hook `yaml:"post_prune,omitempty"`
+	// Maintenance hooks run in every worktree when 'wtp maintain' is due.
+	Maintenance []Hook `yaml:"maintenance,omitempty"`
+	// Clean hooks run in a worktree on 'wtp clean', e.g. to delete node_modules or target
+	// and reclaim disk space without removing the worktree.
+	Clean []Hook `yaml:"clean,omitempty"`
 }
 
 // Hook represents a single hook configuration
 type Hook struct {
-	Type    string            `yaml:"type"` // "copy", "command", or "symlink"
-	From    string            `yaml:"from,omitempty"`
-	To      string            `yaml:"to,omitempty"`
-	Command string            `yaml:"command,omitempty"`
-	Env     map[string]string `yaml:"env,omitempty"`
-	WorkDir string            `yaml:"work_dir,omitempty"`
+	// ID names the hook so a later configuration layer, branch overlay, or profile can
+	// replace or disable it; see Merge.
+	ID string `yaml:"id,omitempty"`
+	// Merge is how the hook combines with the hooks of earlier layers; see the HookMerge
+	// constants. Empty means HookMergeAppend.
+	Merge   string `yaml:"merge,omitempty"`
+	Type    string `yaml:"type"` // see the HookType constants
+	From    string `yaml:"from,omitempty"`
+	To      string `yaml:"to,omitempty"`
+	Command string `yaml:"command,omitempty"`
+	// Run is the multi-line shell script a script hook runs.
+	Run string            `yaml:"run,omitempty"`
+	Env map[string]string `yaml:"env,omitempty"`
+	// EnvFrom loads more variables for a command or script hook when it runs; 'env' wins
+	// for the same name.
+	EnvFrom *EnvFrom `yaml:"env_from,omitempty"`
+	WorkDir string   `yaml:"work_dir,omitempty"`
+	// FromRef makes a copy hook read 'from' out of a git ref (e.g. "main") instead of the main worktree.
+	FromRef string `yaml:"from_ref,omitempty"`
+	// FromWorktree makes a copy hook read 'from' relative to another worktree (e.g. "@" or "feature/x").
+	FromWorktree string `yaml:"from_worktree,omitempty"`
+	// COW decides whether a copy hook writes copy-on-write clones; see the CopyCOW constants.
+	// Empty means CopyCOWAuto.
+	COW string `yaml:"cow,omitempty"`
+	// URL is the http(s) source of a download hook.
+	URL string `yaml:"url,omitempty"`
+	// Checksum pins a download hook's content as "sha256:<hex>"; pinned downloads are cached.
+	Checksum string `yaml:"checksum,omitempty"`
+	// AuthHeaderEnv names an environment variable whose value is sent as the Authorization header.
+	AuthHeaderEnv string `yaml:"auth_header_env,omitempty"`
+	// StripComponents drops that many leading path elements from extract hook entries.
+	StripComponents int `yaml:"strip_components,omitempty"`
+	// Overwrite lets an extract hook replace files that already exist in the destination.
+	Overwrite bool `yaml:"overwrite,omitempty"`
+	// GitConfig holds the key/value pairs a gitconfig hook applies.
+	GitConfig map[string]string `yaml:"config,omitempty"`
+	// Scope selects where a gitconfig hook writes: "local" (default) or "worktree".
+	Scope string `yaml:"scope,omitempty"`
+	// Mode selects how a git_hooks hook installs hook scripts, or a direnv hook its .envrc:
+	// "copy" (default) or "symlink".
+	Mode string `yaml:"mode,omitempty"`
+	// File is the file a patch or ensure_line hook edits, the file a wait hook waits for, or
+	// the env file a prompt hook saves its answer to, relative to the new worktree.
+	File string `yaml:"file,omitempty"`
+	// Format forces a patch hook's file format ("json", "yaml", or "toml") instead of using the extension.
+	Format string `yaml:"format,omitempty"`
+	// Set maps patch selectors (e.g. ".server.port") to the values a patch hook writes.
+	Set map[string]interface{} `yaml:"set,omitempty"`
+	// Delete lists patch selectors whose values a patch hook removes.
+	Delete []string `yaml:"delete,omitempty"`
+	// Line is the line, or block of lines, an ensure_line hook keeps present in 'file'.
+	Line string `yaml:"line,omitempty"`
+	// Match is a regular expression selecting the line an ensure_line hook replaces, or the
+	// one a prompt hook's answer must match.
+	Match string `yaml:"match,omitempty"`
+	// Marker names the '# BEGIN/END' comment pair an ensure_line hook manages its block between.
+	Marker string `yaml:"marker,omitempty"`
+	// TCP is the host:port a wait hook waits to accept connections (e.g. "localhost:${PORT}").
+	TCP string `yaml:"tcp,omitempty"`
+	// HTTP is the URL a wait hook polls until it answers with a 2xx or 3xx status.
+	HTTP string `yaml:"http,omitempty"`
+	// Message is the question a prompt hook asks.
+	Message string `yaml:"message,omitempty"`
+	// Default is the answer a prompt hook uses when the user enters nothing.
+	Default string `yaml:"default,omitempty"`
+	// Secret hides a prompt hook's input while it is typed.
+	Secret bool `yaml:"secret,omitempty"`
+	// Group names a parallel group: consecutive hooks with the same group run concurrently.
+	Group string `yaml:"group,omitempty"`
+	// Timeout limits how long a command or wait hook may run (e.g. "90s"); it overrides defaults.hook_timeout.
+	Timeout string `yaml:"timeout,omitempty"`
+	// Retry re-runs a failed command or download hook according to its policy (see Retry).
+	Retry *Retry `yaml:"retry,omitempty"`
+	// Retries is a shorthand for a retry policy re-running the hook up to that many times,
+	// RetryDelay apart (default DefaultRetryDelay); it cannot be combined with 'retry'.
+	Retries    int    `yaml:"retries,omitempty"`
+	RetryDelay string `yaml:"retry_delay,omitempty"`
+	// Output is where a command hook's output goes; see the HookOutput constants. Empty
+	// means HookOutputInherit.
+	Output string `yaml:"output,omitempty"`
+	// OnError decides what a failure of the hook does to the hooks after it; see the
+	// OnError constants. Empty means OnErrorFail.
+	OnError string `yaml:"on_error,omitempty"`
+	// Register names a variable that receives a command hook's trimmed stdout or a prompt
+	// hook's answer; later hooks can reference it as ${NAME} and commands also see it in
+	// their environment.
+	Register string `yaml:"register,omitempty"`
+	// When is an optional condition (see ParseCondition); the hook is skipped when it is false.
+	When string `yaml:"when,omitempty"`
+	// OS limits the hook to the listed operating systems (see the OS constants); empty
+	// means every one.
+	OS []string `yaml:"os,omitempty"`
+	// ClearEnv runs a command hook with a minimal environment (PATH, HOME, and a few
+	// others) instead of inheriting wtp's; env, defaults.env, and wtp's variables are still set.
+	ClearEnv bool `yaml:"clear_env,omitempty"`
+	// OncePerRepo runs the hook only for the first worktree of the repository; completion
+	// is recorded in the repository's shared git directory.
+	OncePerRepo bool `yaml:"once_per_repo,omitempty"`
+	// Shell runs a command hook with another shell than defaults.shell (see the Shell constants).
+	Shell string `yaml:"shell,omitempty"`
+	// Source is the configuration file LoadConfig read the hook from, e.g. ".wtp.yml", with
+	// the branch overlay or profile it is part of; empty for hooks from elsewhere.
+	Source string `yaml:"-" json:"-"`
 }
 
+// DefaultLockTimeout is how long commands wait for the repository's operation lock when
+// defaults.lock_timeout is unset.
+const DefaultLockTimeout = 10 * time.Minute
+
 const (
 	// ConfigFileName is the default filename for the wtp configuration.
 	ConfigFileName = ".wtp.yml"
-	// CurrentVersion represents the current configuration version written to disk.
+	// LocalConfigFileName is the personal, untracked configuration merged after ConfigFileName.
+	LocalConfigFileName = ".wtp.local.yml"
+	// CurrentVersion represents the current configuration version written to disk. Files
+	// declaring an older one are migrated when loaded; see Migrate.
 	CurrentVersion = "1.0"
 	// DefaultBaseDir is the default directory for new worktrees relative to a repository.
 	DefaultBaseDir = "../worktrees"
@@ -48,15 +314,79 @@ const (
 	HookTypeCopy = "copy"
 	// HookTypeCommand identifies a hook that executes a command.
 	HookTypeCommand = "command"
+	// HookTypeScript identifies a hook that runs a multi-line shell script.
+	HookTypeScript = "script"
 	// HookTypeSymlink identifies a hook that creates symlinks.
-	HookTypeSymlink       = "symlink"
+	HookTypeSymlink = "symlink"
+	// HookTypeHardlink identifies a hook that hard links files, or whole directory trees.
+	HookTypeHardlink = "hardlink"
+	// HookTypeDownload identifies a hook that fetches a file over HTTP(S).
+	HookTypeDownload = "download"
+	// HookTypeExtract identifies a hook that unpacks a tar or zip archive.
+	HookTypeExtract = "extract"
+	// HookTypeGitConfig identifies a hook that sets git configuration values.
+	HookTypeGitConfig = "gitconfig"
+	// HookTypePatch identifies a hook that edits values in a JSON, YAML, or TOML file.
+	HookTypePatch = "patch"
+	// HookTypeEnsureLine identifies a hook that keeps a line or block present in a file.
+	HookTypeEnsureLine = "ensure_line"
+	// HookTypeWait identifies a hook that blocks until a port, URL, or file is ready.
+	HookTypeWait = "wait"
+	// HookTypePrompt identifies a hook that asks the user for a value once per worktree.
+	HookTypePrompt = "prompt"
+	// HookTypeGitHooks identifies a hook that installs a repository-managed git hooks directory
+	// into the worktree.
+	HookTypeGitHooks = "git_hooks"
+	// HookTypeDirenv identifies a hook that installs an .envrc into the worktree and allows it
+	// with direnv.
+	HookTypeDirenv = "direnv"
+	// DefaultEnvrc is the file a direnv hook allows when it has no 'to'.
+	DefaultEnvrc = ".envrc"
+	// GitConfigScopeLocal writes to the repository config shared by all worktrees.
+	GitConfigScopeLocal = "local"
+	// GitConfigScopeWorktree writes to the per-worktree config (enables extensions.worktreeConfig).
+	GitConfigScopeWorktree = "worktree"
+	// GitHooksModeCopy copies hook scripts, refreshing only the ones that changed.
+	GitHooksModeCopy = "copy"
+	// GitHooksModeSymlink links hook scripts to the source directory.
+	GitHooksModeSymlink = "symlink"
+	// CopyCOWAuto clones files copy-on-write where the file system supports it (APFS,
+	// btrfs, XFS) and copies them elsewhere.
+	CopyCOWAuto = "auto"
+	// CopyCOWAlways requires clones and fails where the file system cannot make them.
+	CopyCOWAlways = "always"
+	// CopyCOWNever always copies file contents.
+	CopyCOWNever          = "never"
 	configFilePermissions = 0o600
+	checksumPrefixSHA256  = "sha256:"
+	sha256HexLength       = 64
 )
 
 // userHomeDir is a package-level variable for testability.
 var userHomeDir = os.UserHomeDir
 
-// loadConfigFromFile reads and unmarshals a config file.
+// LoadConfigFile reads a single configuration file as written, without merging other
+// layers, applying defaults, or validating. It returns nil when the file does not exist.
+func LoadConfigFile(path string) (*Config, error) {
+	return loadConfigFromFile(path)
+}
+
+// CheckConfigFile loads the configuration file at path on its own, as LoadConfigFile does,
+// and validates it. It returns the keys of the file that name no setting, which LoadConfig
+// only rejects in strict mode, and the error that makes the file invalid, if any.
+func CheckConfigFile(path string) ([]UnknownKey, error) {
+	cfg, err := loadConfigFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, fmt.Errorf("%s does not exist", path)
+	}
+	cfg.ApplyDefaults()
+	return cfg.unknownKeys, cfg.Validate()
+}
+
+// loadConfigFromFile reads and unmarshals a YAML, JSON, or TOML config file, chosen by extension.
 // Returns nil, nil if the file does not exist.
 func loadConfigFromFile(path string) (*Config, error) {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
@@ -70,7 +400,7 @@ func loadConfigFromFile(path string) (*Config, error) {
 	}
 
 	var config Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
+	if err := decodeConfig(path, data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
@@ -78,8 +408,13 @@ func loadConfigFromFile(path string) (*Config, error) {
 }
 
 // MergeConfig merges override into base and returns the result.
-// Scalar fields (Version, BaseDir) use override when non-empty.
-// Hooks.PostCreate is concatenated: base hooks first, then override hooks.
+// Scalar fields (Version and the defaults, such as BaseDir or HookTimeout), the slug policy,
+// and policy fields use override when set. defaults.env is merged key by key, override
+// winning. Verify checks, branch overlays, and hibernate patterns are concatenated: base
+// entries first, then override entries. Hook lists are too, unless a hook's 'merge' policy
+// says otherwise (see HookMergeReplace). integrations.tmux.layout is replaced as a whole.
+// Profiles are merged by name.
+// The origin of each value (see Origin) is kept track of along the way.
 func MergeConfig(base, override *Config) *Config {
 	result := *base
 
@@ -87,23 +422,146 @@ func MergeConfig(base, override *Config) *Config {
 		result.Version = override.Version
 	}
 
-	if override.Defaults.BaseDir != "" {
-		result.Defaults.BaseDir = override.Defaults.BaseDir
+	result.Defaults = mergeDefaults(&base.Defaults, &override.Defaults)
+	result.Hooks = mergeHooks(&base.Hooks, &override.Hooks)
+	result.Policy = mergePolicy(base.Policy, override.Policy)
+	result.Cache = mergeCache(base.Cache, override.Cache)
+	result.Map = mergeWorktreeMap(base.Map, override.Map)
+	result.Integrations = mergeIntegrations(&base.Integrations, &override.Integrations)
+	if len(override.Verify) > 0 {
+		result.Verify = append(append([]Check{}, base.Verify...), override.Verify...)
 	}
 
-	if len(override.Hooks.PostCreate) > 0 {
-		merged := make([]Hook, 0, len(base.Hooks.PostCreate)+len(override.Hooks.PostCreate))
-		merged = append(merged, base.Hooks.PostCreate...)
-		merged = append(merged, override.Hooks.PostCreate...)
-		result.Hooks.PostCreate = merged
+	if len(override.Branches) > 0 {
+		result.Branches = append(append(BranchOverlays{}, base.Branches...), override.Branches...)
+	}
+
+	result.Profiles = mergeProfiles(base.Profiles, override.Profiles)
+	result.origins = mergeOrigins(base, override, &result)
+
+	if len(override.Hibernate) > 0 {
+		result.Hibernate = append(append([]string{}, base.Hibernate...), override.Hibernate...)
 	}
 
 	return &result
 }
 
-// LoadConfig loads configuration from ~/.wtp.yml (global) and <repoRoot>/.wtp.yml (repo),
-// merging them with repo config taking precedence for scalar fields.
-func LoadConfig(repoRoot string) (*Config, error) {
+// mergeHooks merges each hook list of override into that of base; see mergeHookLists.
+func mergeHooks(base, override *Hooks) Hooks {
+	result := *base
+	result.PostCreate = mergeHookLists(base.PostCreate, override.PostCreate)
+	result.PreRemove = mergeHookLists(base.PreRemove, override.PreRemove)
+	result.PostCheckout = mergeHookLists(base.PostCheckout, override.PostCheckout)
+	result.PostRemove = mergeHookLists(base.PostRemove, override.PostRemove)
+	result.PostPrune = mergeHookLists(base.PostPrune, override.PostPrune)
+	result.Maintenance = mergeHookLists(base.Maintenance, override.Maintenance)
+	result.Clean = mergeHookLists(base.Clean, override.Clean)
+	return result
+}
+
+// mergeDefaults applies the fields override sets on top of base; env is merged key by key.
+func mergeDefaults(base, override *Defaults) Defaults {
+	result := *base
+	mergeDefaultPaths(&result, override)
+	mergeDefaultLimits(&result, override)
+	if override.ReadOnly {
+		result.ReadOnly = true
+	}
+	if override.Strict {
+		result.Strict = true
+	}
+	if override.Slug.IsSet() {
+		result.Slug = override.Slug
+	}
+	if override.AfterAdd != "" {
+		result.AfterAdd = override.AfterAdd
+	}
+	if override.Editor != "" {
+		result.Editor = override.Editor
+	}
+	if override.AutoTrack != nil {
+		result.AutoTrack = override.AutoTrack
+	}
+	if override.Shell != "" {
+		result.Shell = override.Shell
+	}
+	if override.PruneAfter != "" {
+		result.PruneAfter = override.PruneAfter
+	}
+	if override.NestedWorktrees != "" {
+		result.NestedWorktrees = override.NestedWorktrees
+	}
+	if override.VCS != "" {
+		result.VCS = override.VCS
+	}
+	if override.Submodules != "" {
+		result.Submodules = override.Submodules
+	}
+	if len(override.Env) > 0 {
+		result.Env = mergeEnv(base.Env, override.Env)
+	}
+	return result
+}
+
+// mergeDefaultPaths applies the directories override sets.
+func mergeDefaultPaths(result, override *Defaults) {
+	if override.BaseDir != "" {
+		result.BaseDir = override.BaseDir
+	}
+	if override.WorktreeDir != "" {
+		result.WorktreeDir = override.WorktreeDir
+	}
+	if override.RuntimeDir != "" {
+		result.RuntimeDir = override.RuntimeDir
+	}
+	if override.HookLogDir != "" {
+		result.HookLogDir = override.HookLogDir
+	}
+	if len(override.Sparse) > 0 {
+		result.Sparse = override.Sparse
+	}
+}
+
+// mergeDefaultLimits applies the timeouts, concurrency limits, and intervals override sets.
+func mergeDefaultLimits(result, override *Defaults) {
+	if override.HookTimeout != "" {
+		result.HookTimeout = override.HookTimeout
+	}
+	if override.OperationTimeout != "" {
+		result.OperationTimeout = override.OperationTimeout
+	}
+	if override.LockTimeout != "" {
+		result.LockTimeout = override.LockTimeout
+	}
+	if override.HookConcurrency != 0 {
+		result.HookConcurrency = override.HookConcurrency
+	}
+	if override.MaxConcurrentProvisions != 0 {
+		result.MaxConcurrentProvisions = override.MaxConcurrentProvisions
+	}
+	if override.MaintenanceInterval != "" {
+		result.MaintenanceInterval = override.MaintenanceInterval
+	}
+}
+
+// mergeEnv returns the variables of base and override, override winning for the same name.
+func mergeEnv(base, override map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(override))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range override {
+		merged[key] = value
+	}
+	return merged
+}
+
+// LoadConfig loads configuration from ~/.wtp.yml (global), <repoRoot>/.wtp.yml (repo), and
+// <repoRoot>/.wtp.local.yml (local, meant to be gitignored), merging them in that order so
+// later layers take precedence for scalar fields. Each layer may instead be a .json or .toml
+// file (see FindConfigFile). When branch is not empty, the 'branches' overlays matching it
+// are merged on top (see ForBranch).
+func LoadConfig(repoRoot, branch string) (*Config, error) {
 	cleanedRoot := filepath.Clean(repoRoot)
 	if !filepath.IsAbs(cleanedRoot) {
 		absRoot, err := filepath.Abs(cleanedRoot)
@@ -116,27 +574,36 @@ func LoadConfig(repoRoot string) (*Config, error) {
 	// Load global config from ~/.wtp.yml
 	var globalCfg *Config
 	if home, err := userHomeDir(); err == nil {
-		globalPath := filepath.Join(home, ConfigFileName)
-		globalCfg, err = loadConfigFromFile(globalPath)
+		globalCfg, err = loadLayer(home, ConfigFileName, GlobalSourcePrefix)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load global config: %w", err)
 		}
 	}
 
 	// Load repo config from <repoRoot>/.wtp.yml
-	repoPath := filepath.Join(cleanedRoot, ConfigFileName)
-	repoCfg, err := loadConfigFromFile(repoPath)
+	repoCfg, err := loadLayer(cleanedRoot, ConfigFileName, "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to load repo config: %w", err)
 	}
 
-	// Start with defaults, layer global, then repo
+	// Load personal overrides from <repoRoot>/.wtp.local.yml
+	localCfg, err := loadLayer(cleanedRoot, LocalConfigFileName, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load local config: %w", err)
+	}
+
+	// Start with defaults, layer global, repo, then local
 	result := &Config{}
-	if globalCfg != nil {
-		result = MergeConfig(result, globalCfg)
+	var unknownKeys []UnknownKey
+	for _, layer := range []*Config{globalCfg, repoCfg, localCfg} {
+		if layer != nil {
+			result = MergeConfig(result, layer)
+			unknownKeys = append(unknownKeys, layer.unknownKeys...)
+		}
 	}
-	if repoCfg != nil {
-		result = MergeConfig(result, repoCfg)
+	result = result.ForBranch(branch)
+	if (result.Defaults.Strict || strictFromEnv()) && len(unknownKeys) > 0 {
+		return nil, &UnknownKeysError{Keys: unknownKeys}
 	}
 
 	// Apply defaults, then validate configuration.
@@ -148,8 +615,23 @@ func LoadConfig(repoRoot string) (*Config, error) {
 	return result, nil
 }
 
+// loadLayer loads the config file named like name in dir in whichever format is present.
+// Its hooks get the file's name, after sourcePrefix, as their source.
+func loadLayer(dir, name, sourcePrefix string) (*Config, error) {
+	path, err := FindConfigFile(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := loadConfigFromFile(path)
+	if cfg != nil {
+		cfg.setSource(sourcePrefix + filepath.Base(path))
+	}
+	return cfg, err
+}
+
 // SaveConfig saves configuration to .git-worktree-plus.yml in the repository root
 func SaveConfig(repoRoot string, config *Config) error {
+	config.Version = CurrentVersion // the fields are written in the current schema
 	config.ApplyDefaults()
 	if err := config.Validate(); err != nil {
 		return fmt.Errorf("invalid configuration: %w", err)
@@ -179,25 +661,157 @@ func (c *Config) ApplyDefaults() {
 		c.Defaults.BaseDir = DefaultBaseDir
 	}
 
-	for i := range c.Hooks.PostCreate {
-		c.Hooks.PostCreate[i].ApplyDefaults()
+	c.Hooks.applyDefaults()
+	for i := range c.Branches {
+		c.Branches[i].Hooks.applyDefaults()
+	}
+	for name := range c.Profiles {
+		profile := c.Profiles[name]
+		profile.Hooks.applyDefaults()
+		c.Profiles[name] = profile
 	}
 }
 
 // Validate validates the configuration without mutating it.
 func (c *Config) Validate() error {
-	for i := range c.Hooks.PostCreate {
-		if err := c.Hooks.PostCreate[i].Validate(); err != nil {
+	if err := c.Defaults.validate(); err != nil {
+		return err
+	}
+	if err := c.Policy.validate(); err != nil {
+		return err
+	}
+	if err := c.Cache.validate(); err != nil {
+		return err
+	}
+	if err := c.Map.validate(); err != nil {
+		return err
+	}
+	if err := c.Integrations.Tmux.validate(); err != nil {
+		return err
+	}
+	for i := range c.Verify {
+		if err := c.Verify[i].validate(); err != nil {
+			return fmt.Errorf("invalid verify check %d: %w", i+1, err)
+		}
+	}
+	for i, pattern := range c.Hibernate {
+		if err := validateHibernatePattern(pattern); err != nil {
+			return fmt.Errorf("invalid hibernate pattern %d: %w", i+1, err)
+		}
+	}
+	if err := c.Hooks.validate(); err != nil {
+		return err
+	}
+	if err := c.Branches.validate(); err != nil {
+		return err
+	}
+	return c.validateProfiles()
+}
+
+func (d *Defaults) validate() error {
+	if err := ValidateSparsePaths(d.Sparse); err != nil {
+		return fmt.Errorf("invalid defaults.sparse: %w", err)
+	}
+	if _, err := parseHookTimeout(d.HookTimeout); err != nil {
+		return fmt.Errorf("invalid defaults.hook_timeout: %w", err)
+	}
+	if _, err := parseHookTimeout(d.OperationTimeout); err != nil {
+		return fmt.Errorf("invalid defaults.operation_timeout: %w", err)
+	}
+	if _, err := parseHookTimeout(d.LockTimeout); err != nil {
+		return fmt.Errorf("invalid defaults.lock_timeout: %w", err)
+	}
+	if d.HookConcurrency < 0 {
+		return fmt.Errorf("invalid defaults.hook_concurrency: must not be negative")
+	}
+	if d.MaxConcurrentProvisions < 0 {
+		return fmt.Errorf("invalid defaults.max_concurrent_provisions: must not be negative")
+	}
+	if err := d.validateChoices(); err != nil {
+		return err
+	}
+	if err := validateWorktreeDir(d.WorktreeDir); err != nil {
+		return err
+	}
+	if _, err := parseMaintenanceInterval(d.MaintenanceInterval); err != nil {
+		return fmt.Errorf("invalid defaults.maintenance_interval: %w", err)
+	}
+	if _, err := parseAge(d.PruneAfter); err != nil {
+		return fmt.Errorf("invalid defaults.prune_after: %w", err)
+	}
+	if err := d.Slug.validate(); err != nil {
+		return fmt.Errorf("invalid defaults.slug: %w", err)
+	}
+	return nil
+}
+
+func (h *Hooks) applyDefaults() {
+	for i := range h.PostCreate {
+		h.PostCreate[i].ApplyDefaults()
+	}
+	for i := range h.PreRemove {
+		h.PreRemove[i].ApplyDefaults()
+	}
+	for i := range h.PostCheckout {
+		h.PostCheckout[i].ApplyDefaults()
+	}
+	for i := range h.PostRemove {
+		h.PostRemove[i].ApplyDefaults()
+	}
+	for i := range h.PostPrune {
+		h.PostPrune[i].ApplyDefaults()
+	}
+	for i := range h.Maintenance {
+		h.Maintenance[i].ApplyDefaults()
+	}
+	for i := range h.Clean {
+		h.Clean[i].ApplyDefaults()
+	}
+}
+
+func (h *Hooks) validate() error {
+	for i := range h.PostCreate {
+		if err := h.PostCreate[i].Validate(); err != nil {
 			return fmt.Errorf("invalid hook %d: %w", i+1, err)
 		}
 	}
+	for i := range h.PreRemove {
+		if err := h.PreRemove[i].Validate(); err != nil {
+			return fmt.Errorf("invalid pre_remove hook %d: %w", i+1, err)
+		}
+	}
+	for i := range h.PostCheckout {
+		if err := h.PostCheckout[i].Validate(); err != nil {
+			return fmt.Errorf("invalid post_checkout hook %d: %w", i+1, err)
+		}
+	}
+	for i := range h.PostRemove {
+		if err := h.PostRemove[i].Validate(); err != nil {
+			return fmt.Errorf("invalid post_remove hook %d: %w", i+1, err)
+		}
+	}
+	for i := range h.PostPrune {
+		if err := h.PostPrune[i].Validate(); err != nil {
+			return fmt.Errorf("invalid post_prune hook %d: %w", i+1, err)
+		}
+	}
+	for i := range h.Maintenance {
+		if err := h.Maintenance[i].Validate(); err != nil {
+			return fmt.Errorf("invalid maintenance hook %d: %w", i+1, err)
+		}
+	}
+	for i := range h.Clean {
+		if err := h.Clean[i].Validate(); err != nil {
+			return fmt.Errorf("invalid clean hook %d: %w", i+1, err)
+		}
+	}
 
 	return nil
 }
 
 // ApplyDefaults applies default values to a single hook in-place.
 func (h *Hook) ApplyDefaults() {
-	if h.Type != HookTypeCopy {
+	if h.Type != HookTypeCopy && h.Type != HookTypeHardlink {
 		return
 	}
 	if h.To != "" || h.From == "" {
@@ -207,40 +821,564 @@ func (h *Hook) ApplyDefaults() {
 	if filepath.IsAbs(h.From) {
 		return
 	}
+	if IsGlobPattern(h.From) {
+		// Matches keep their place relative to the pattern's fixed leading directories
+		h.To = GlobBase(h.From)
+		return
+	}
 	h.To = h.From
 }
 
+// IsGlobPattern reports whether path contains glob metacharacters.
+func IsGlobPattern(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// GlobBase returns the leading directories of pattern that contain no glob
+// metacharacters, e.g. "config" for "config/*.example". It returns "." when the
+// first element is already a pattern.
+func GlobBase(pattern string) string {
+	base := filepath.Dir(pattern)
+	for IsGlobPattern(base) {
+		base = filepath.Dir(base)
+	}
+	return base
+}
+
 // Validate validates a single hook configuration without mutating it.
 func (h *Hook) Validate() error {
-	switch h.Type {
-	case HookTypeCopy:
-		if h.From == "" {
-			return fmt.Errorf("copy hook requires 'from' field")
+	if h.Merge == HookMergeDisable {
+		return h.validateDisable()
+	}
+	return h.validateRunnable()
+}
+
+// hookValidators holds the checks of the fields each hook type requires, by type.
+var hookValidators = map[string]func(*Hook) error{
+	HookTypeCopy:       (*Hook).validateCopy,
+	HookTypeCommand:    (*Hook).validateCommand,
+	HookTypeScript:     (*Hook).validateScript,
+	HookTypeSymlink:    (*Hook).validateSymlink,
+	HookTypeHardlink:   (*Hook).validateHardlink,
+	HookTypeDownload:   (*Hook).validateDownload,
+	HookTypeExtract:    (*Hook).validateExtract,
+	HookTypeGitConfig:  (*Hook).validateGitConfig,
+	HookTypePatch:      (*Hook).validatePatch,
+	HookTypeEnsureLine: (*Hook).validateEnsureLine,
+	HookTypeWait:       (*Hook).validateWait,
+	HookTypePrompt:     (*Hook).validatePrompt,
+	HookTypeGitHooks:   (*Hook).validateGitHooks,
+	HookTypeDirenv:     (*Hook).validateDirenv,
+}
+
+// validateRunnable validates a hook that runs, i.e. any but one disabling an earlier hook.
+func (h *Hook) validateRunnable() error {
+	if err := h.validateCommonFields(); err != nil {
+		return err
+	}
+
+	validate, ok := hookValidators[h.Type]
+	if !ok {
+		return fmt.Errorf("invalid hook type '%s', must be 'copy', 'command', 'script', 'symlink', 'hardlink', "+
+			"'download', 'extract', 'gitconfig', 'patch', 'ensure_line', 'wait', 'prompt', 'git_hooks', or 'direnv'",
+			h.Type)
+	}
+	if err := validate(h); err != nil {
+		return err
+	}
+
+	return h.validateTypeSpecificFields()
+}
+
+// validateCommonFields checks the fields every hook type accepts.
+func (h *Hook) validateCommonFields() error {
+	if err := h.validateMerge(); err != nil {
+		return err
+	}
+	if h.When != "" {
+		if _, err := ParseCondition(h.When); err != nil {
+			return fmt.Errorf("invalid 'when' condition: %w", err)
+		}
+	}
+	if err := validateHookOS(h.OS); err != nil {
+		return fmt.Errorf("invalid 'os': %w", err)
+	}
+	if h.OncePerRepo && h.Register != "" {
+		return fmt.Errorf("hook with 'once_per_repo' cannot use 'register': later worktrees would not get the value")
+	}
+	if err := validateOnError(h.OnError); err != nil {
+		return fmt.Errorf("invalid 'on_error': %w", err)
+	}
+	return h.validateRetryFields()
+}
+
+// validateTypeSpecificFields rejects fields that only apply to other hook types.
+func (h *Hook) validateTypeSpecificFields() error {
+	fields := []struct {
+		owners []string
+		set    bool
+		names  string
+	}{
+		{[]string{HookTypeExtract}, h.StripComponents != 0 || h.Overwrite, "'strip_components' or 'overwrite' fields"},
+		{[]string{HookTypeDownload}, h.URL != "" || h.Checksum != "" || h.AuthHeaderEnv != "",
+			"'url', 'checksum', or 'auth_header_env' fields"},
+		{[]string{HookTypeCopy}, h.hasCopyOnlyFields(), "'from_ref', 'from_worktree', or 'cow' fields"},
+		{[]string{HookTypeGitConfig}, len(h.GitConfig) > 0 || h.Scope != "", "'config' or 'scope' fields"},
+		{[]string{HookTypeGitHooks, HookTypeDirenv}, h.Mode != "", "'mode' field"},
+		{[]string{HookTypeCommand, HookTypeScript, HookTypeWait}, h.Timeout != "", "'timeout' field"},
+		{[]string{HookTypeCommand, HookTypeScript}, h.hasCommandOnlyFields(),
+			"'clear_env', 'shell', 'output', or 'env_from' fields"},
+		{[]string{HookTypeCommand, HookTypeScript, HookTypeDownload}, h.hasRetryFields(),
+			"'retry', 'retries', or 'retry_delay' fields"},
+		{[]string{HookTypeScript}, h.Run != "", "'run' field"},
+		{[]string{HookTypePatch, HookTypeEnsureLine, HookTypeWait, HookTypePrompt}, h.File != "", "'file' field"},
+		{[]string{HookTypeWait}, h.TCP != "" || h.HTTP != "", "'tcp' or 'http' fields"},
+		{[]string{HookTypeCommand, HookTypeScript, HookTypePrompt}, h.Register != "", "'register' field"},
+		{[]string{HookTypePatch}, h.Format != "" || len(h.Set) > 0 || len(h.Delete) > 0,
+			"'format', 'set', or 'delete' fields"},
+		{[]string{HookTypeEnsureLine}, h.Line != "" || h.Marker != "", "'line' or 'marker' fields"},
+		{[]string{HookTypeEnsureLine, HookTypePrompt}, h.Match != "", "'match' field"},
+		{[]string{HookTypePrompt}, h.Message != "" || h.Default != "" || h.Secret,
+			"'message', 'default', or 'secret' fields"},
+	}
+	for _, field := range fields {
+		if field.set && !slices.Contains(field.owners, h.Type) {
+			return fmt.Errorf("%s hook should not have %s", h.Type, field.names)
+		}
+	}
+	return nil
+}
+
+// hasCommandOnlyFields reports whether fields that only affect how a command runs are set.
+func (h *Hook) hasCommandOnlyFields() bool {
+	return h.ClearEnv || h.Shell != "" || h.Output != "" || h.EnvFrom != nil
+}
+
+// hasCopyOnlyFields reports whether fields that only affect how a copy hook reads or
+// writes files are set.
+func (h *Hook) hasCopyOnlyFields() bool {
+	return h.FromRef != "" || h.FromWorktree != "" || h.COW != ""
+}
+
+// hasRetryFields reports whether a retry policy or its shorthand is set.
+func (h *Hook) hasRetryFields() bool {
+	return h.Retry != nil || h.Retries != 0 || h.RetryDelay != ""
+}
+
+func (h *Hook) validateCopy() error {
+	if h.From == "" {
+		return fmt.Errorf("copy hook requires 'from' field")
+	}
+	if h.To == "" && filepath.IsAbs(h.From) {
+		return fmt.Errorf("copy hook with absolute 'from' requires 'to' field")
+	}
+	if h.Command != "" {
+		return fmt.Errorf("copy hook should not have 'command' field")
+	}
+	if h.FromRef != "" && h.FromWorktree != "" {
+		return fmt.Errorf("copy hook cannot have both 'from_ref' and 'from_worktree' fields")
+	}
+	if (h.FromRef != "" || h.FromWorktree != "") && filepath.IsAbs(h.From) {
+		return fmt.Errorf("copy hook with 'from_ref' or 'from_worktree' requires a relative 'from' path")
+	}
+	if IsGlobPattern(h.From) {
+		if h.FromRef != "" {
+			return fmt.Errorf("copy hook with 'from_ref' does not support glob patterns in 'from'")
 		}
-		if h.To == "" && filepath.IsAbs(h.From) {
-			return fmt.Errorf("copy hook with absolute 'from' requires 'to' field")
+		if _, err := filepath.Match(h.From, ""); err != nil {
+			return fmt.Errorf("copy hook has invalid glob pattern in 'from': %w", err)
 		}
-		if h.Command != "" {
-			return fmt.Errorf("copy hook should not have 'command' field")
+	}
+	return h.validateCOW()
+}
+
+// validateCOW checks the 'cow' field of a copy hook.
+func (h *Hook) validateCOW() error {
+	switch h.COW {
+	case "", CopyCOWAuto, CopyCOWNever:
+		return nil
+	case CopyCOWAlways:
+		if h.FromRef != "" {
+			return fmt.Errorf("copy hook with 'from_ref' cannot use 'cow: always': files read from a ref are not cloned")
 		}
-	case HookTypeCommand:
-		if h.Command == "" {
-			return fmt.Errorf("command hook requires 'command' field")
+		return nil
+	default:
+		return fmt.Errorf("copy hook 'cow' must be '%s', '%s', or '%s'", CopyCOWAuto, CopyCOWAlways, CopyCOWNever)
+	}
+}
+
+func (h *Hook) validateCommand() error {
+	if h.Command == "" {
+		return fmt.Errorf("command hook requires 'command' field")
+	}
+	if h.From != "" || h.To != "" {
+		return fmt.Errorf("command hook should not have 'from' or 'to' fields")
+	}
+	return h.validateRunOptions()
+}
+
+// validateRunOptions checks the fields that control how a command or script hook runs.
+func (h *Hook) validateRunOptions() error {
+	if _, err := parseHookTimeout(h.Timeout); err != nil {
+		return fmt.Errorf("invalid 'timeout': %w", err)
+	}
+	if h.Register != "" && !registerNamePattern.MatchString(h.Register) {
+		return fmt.Errorf("%s hook 'register' must be a variable name like API_TOKEN, got '%s'", h.Type, h.Register)
+	}
+	if err := validateShell(h.Shell); err != nil {
+		return fmt.Errorf("invalid 'shell': %w", err)
+	}
+	if h.EnvFrom != nil {
+		if err := h.EnvFrom.validate(); err != nil {
+			return fmt.Errorf("invalid 'env_from': %w", err)
 		}
-		if h.From != "" || h.To != "" {
-			return fmt.Errorf("command hook should not have 'from' or 'to' fields")
+	}
+	return validateHookOutput(h.Output)
+}
+
+func (h *Hook) validatePrompt() error {
+	if h.Register == "" {
+		return fmt.Errorf("prompt hook requires 'register' field naming the variable to set")
+	}
+	if !registerNamePattern.MatchString(h.Register) {
+		return fmt.Errorf("prompt hook 'register' must be a variable name like API_TOKEN, got '%s'", h.Register)
+	}
+	if h.From != "" || h.To != "" || h.Command != "" {
+		return fmt.Errorf("prompt hook should not have 'from', 'to', or 'command' fields")
+	}
+	if h.Group != "" {
+		return fmt.Errorf("prompt hook cannot run in a parallel 'group'")
+	}
+	if h.Match != "" {
+		pattern, err := regexp.Compile(h.Match)
+		if err != nil {
+			return fmt.Errorf("invalid 'match' pattern: %w", err)
 		}
-	case HookTypeSymlink:
-		if h.From == "" || h.To == "" {
-			return fmt.Errorf("symlink hook requires both 'from' and 'to' fields")
+		if h.Default != "" && !pattern.MatchString(h.Default) {
+			return fmt.Errorf("prompt hook 'default' does not match '%s'", h.Match)
 		}
-		if h.Command != "" {
-			return fmt.Errorf("symlink hook should not have 'command' field")
+	}
+	return nil
+}
+
+// registerNamePattern matches the variable names a command hook can register.
+var registerNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validateWorktreeDir rejects worktree_dir templates that would place worktrees outside
+// base_dir.
+func validateWorktreeDir(template string) error {
+	if template == "" {
+		return nil
+	}
+	if filepath.IsAbs(template) || strings.HasPrefix(template, "/") {
+		return fmt.Errorf("invalid defaults.worktree_dir '%s': must be relative to base_dir", template)
+	}
+	for _, segment := range strings.FieldsFunc(template, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if segment == ".." {
+			return fmt.Errorf("invalid defaults.worktree_dir '%s': must not contain '..'", template)
+		}
+	}
+	return nil
+}
+
+func validateAfterAdd(action string) error {
+	switch action {
+	case "", AfterAddPrintPath, AfterAddCopyPath, AfterAddCd, AfterAddOpenEditor:
+		return nil
+	default:
+		return fmt.Errorf("invalid defaults.after_add '%s', must be '%s', '%s', '%s', or '%s'",
+			action, AfterAddPrintPath, AfterAddCopyPath, AfterAddCd, AfterAddOpenEditor)
+	}
+}
+
+// validateChoices checks the defaults that take one of a fixed set of values.
+func (d *Defaults) validateChoices() error {
+	if err := validateAfterAdd(d.AfterAdd); err != nil {
+		return err
+	}
+	if err := validateShell(d.Shell); err != nil {
+		return fmt.Errorf("invalid defaults.shell: %w", err)
+	}
+	if err := validateNestedWorktrees(d.NestedWorktrees); err != nil {
+		return err
+	}
+	if err := ValidateSubmodules(d.Submodules); err != nil {
+		return fmt.Errorf("invalid defaults.submodules: %w", err)
+	}
+	return validateVCS(d.VCS)
+}
+
+// ValidateSubmodules checks a defaults.submodules or 'wtp add --submodules' value.
+func ValidateSubmodules(mode string) error {
+	switch mode {
+	case "", SubmodulesRecursive, SubmodulesTop, SubmodulesNone:
+		return nil
+	default:
+		return fmt.Errorf("'%s' must be '%s', '%s', or '%s'", mode, SubmodulesRecursive, SubmodulesTop, SubmodulesNone)
+	}
+}
+
+func validateNestedWorktrees(action string) error {
+	switch action {
+	case "", NestedWorktreesWarn, NestedWorktreesError, NestedWorktreesAllow:
+		return nil
+	default:
+		return fmt.Errorf("invalid defaults.nested_worktrees '%s', must be '%s', '%s', or '%s'",
+			action, NestedWorktreesWarn, NestedWorktreesError, NestedWorktreesAllow)
+	}
+}
+
+func validateVCS(name string) error {
+	switch name {
+	case "", VCSGit, VCSJujutsu:
+		return nil
+	default:
+		return fmt.Errorf("invalid defaults.vcs '%s', must be '%s' or '%s'", name, VCSGit, VCSJujutsu)
+	}
+}
+
+func validateHookOS(systems []string) error {
+	for _, name := range systems {
+		switch name {
+		case OSLinux, OSDarwin, OSWindows, OSFreeBSD:
+		case "macos", "osx":
+			return fmt.Errorf("unknown operating system '%s', use '%s' for macOS", name, OSDarwin)
+		default:
+			return fmt.Errorf("unknown operating system '%s', must be '%s', '%s', '%s', or '%s'",
+				name, OSLinux, OSDarwin, OSWindows, OSFreeBSD)
 		}
+	}
+	return nil
+}
+
+// RunsOn reports whether the hook's 'os' allows it to run on goos, a runtime.GOOS value.
+func (h *Hook) RunsOn(goos string) bool {
+	return len(h.OS) == 0 || slices.Contains(h.OS, goos)
+}
+
+func validateShell(shell string) error {
+	switch shell {
+	case "", ShellSh, ShellBash, ShellPwsh, ShellPowerShell, ShellCmd:
+		return nil
 	default:
-		return fmt.Errorf("invalid hook type '%s', must be 'copy', 'command', or 'symlink'", h.Type)
+		return fmt.Errorf("unknown shell '%s', must be '%s', '%s', '%s', '%s', or '%s'",
+			shell, ShellSh, ShellBash, ShellPwsh, ShellPowerShell, ShellCmd)
+	}
+}
+
+func validateHookOutput(output string) error {
+	switch output {
+	case "", HookOutputInherit, HookOutputCapture, HookOutputFile:
+		return nil
+	default:
+		return fmt.Errorf("invalid 'output' '%s', must be '%s', '%s', or '%s'",
+			output, HookOutputInherit, HookOutputCapture, HookOutputFile)
+	}
+}
+
+// CommandShell returns the shell a command or script hook runs with: its own 'shell', else
+// defaults.shell. Empty leaves the choice to the platform.
+func (c *Config) CommandShell(h *Hook) string {
+	if h.Shell != "" {
+		return h.Shell
+	}
+	return c.Defaults.Shell
+}
+
+// HookEnv returns the env a hook runs with: defaults.env overlaid with the hook's own env.
+func (c *Config) HookEnv(h *Hook) map[string]string {
+	if len(c.Defaults.Env) == 0 {
+		return h.Env
+	}
+	return mergeEnv(c.Defaults.Env, h.Env)
+}
+
+// CommandTimeout returns how long a command hook may run: its own 'timeout', else
+// defaults.hook_timeout. Zero means no limit.
+func (c *Config) CommandTimeout(h *Hook) time.Duration {
+	timeout := h.Timeout
+	if timeout == "" {
+		timeout = c.Defaults.HookTimeout
+	}
+	d, _ := parseHookTimeout(timeout) // validated when the configuration was loaded
+	return d
+}
+
+// OperationTimeout returns defaults.operation_timeout, how long 'wtp add' and 'wtp exec'
+// may run without '--timeout'. Zero means no limit.
+func (c *Config) OperationTimeout() time.Duration {
+	d, _ := parseHookTimeout(c.Defaults.OperationTimeout) // validated when the configuration was loaded
+	return d
+}
+
+// LockTimeout returns defaults.lock_timeout, how long commands that create or remove
+// worktrees wait for the repository's operation lock, or DefaultLockTimeout when unset.
+func (c *Config) LockTimeout() time.Duration {
+	d, _ := parseHookTimeout(c.Defaults.LockTimeout) // validated when the configuration was loaded
+	if d == 0 {
+		return DefaultLockTimeout
+	}
+	return d
+}
+
+// AutoTrack returns defaults.auto_track, whether a branch 'wtp add' creates from a remote
+// branch tracks it; true unless set to false.
+func (c *Config) AutoTrack() bool {
+	return c.Defaults.AutoTrack == nil || *c.Defaults.AutoTrack
+}
+
+func parseHookTimeout(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("timeout must be positive, got %s", s)
+	}
+	return d, nil
+}
+
+func (h *Hook) validateSymlink() error {
+	if h.From == "" || h.To == "" {
+		return fmt.Errorf("symlink hook requires both 'from' and 'to' fields")
+	}
+	if h.Command != "" {
+		return fmt.Errorf("symlink hook should not have 'command' field")
+	}
+	return nil
+}
+
+func (h *Hook) validateHardlink() error {
+	if h.From == "" {
+		return fmt.Errorf("hardlink hook requires 'from' field")
+	}
+	if h.To == "" && filepath.IsAbs(h.From) {
+		return fmt.Errorf("hardlink hook with absolute 'from' requires 'to' field")
+	}
+	if h.Command != "" {
+		return fmt.Errorf("hardlink hook should not have 'command' field")
+	}
+	if IsGlobPattern(h.From) {
+		return fmt.Errorf("hardlink hook does not support glob patterns in 'from'; link the directory instead")
+	}
+	return nil
+}
+
+func (h *Hook) validateExtract() error {
+	if h.From == "" || h.To == "" {
+		return fmt.Errorf("extract hook requires both 'from' and 'to' fields")
+	}
+	if h.Command != "" {
+		return fmt.Errorf("extract hook should not have 'command' field")
+	}
+	if h.StripComponents < 0 {
+		return fmt.Errorf("extract hook 'strip_components' must not be negative")
+	}
+	return nil
+}
+
+// gitConfigKeyPattern matches section[.subsection].name as accepted by 'git config'.
+var gitConfigKeyPattern = regexp.MustCompile(`^[A-Za-z0-9-]+(\.[^\n]+)?\.[A-Za-z][A-Za-z0-9-]*$`)
+
+func (h *Hook) validateGitConfig() error {
+	if len(h.GitConfig) == 0 {
+		return fmt.Errorf("gitconfig hook requires a non-empty 'config' map")
+	}
+	if h.From != "" || h.To != "" || h.Command != "" {
+		return fmt.Errorf("gitconfig hook should not have 'from', 'to', or 'command' fields")
+	}
+	if h.Scope != "" && h.Scope != GitConfigScopeLocal && h.Scope != GitConfigScopeWorktree {
+		return fmt.Errorf("gitconfig hook 'scope' must be '%s' or '%s'", GitConfigScopeLocal, GitConfigScopeWorktree)
+	}
+	for key, value := range h.GitConfig {
+		if !gitConfigKeyPattern.MatchString(key) {
+			return fmt.Errorf("invalid git config key '%s', expected 'section.name' or 'section.subsection.name'", key)
+		}
+		if strings.ContainsRune(value, 0) {
+			return fmt.Errorf("git config value for '%s' must not contain NUL bytes", key)
+		}
+	}
+	return nil
+}
+
+func (h *Hook) validateGitHooks() error {
+	if h.From == "" {
+		return fmt.Errorf("git_hooks hook requires 'from' field naming the hooks directory")
+	}
+	if h.To != "" || h.Command != "" {
+		return fmt.Errorf("git_hooks hook should not have 'to' or 'command' fields")
+	}
+	if h.Mode != "" && h.Mode != GitHooksModeCopy && h.Mode != GitHooksModeSymlink {
+		return fmt.Errorf("git_hooks hook 'mode' must be '%s' or '%s'", GitHooksModeCopy, GitHooksModeSymlink)
+	}
+	return nil
+}
+
+func (h *Hook) validateDirenv() error {
+	if h.Command != "" {
+		return fmt.Errorf("direnv hook should not have 'command' field")
+	}
+	if h.Mode != "" && h.From == "" {
+		return fmt.Errorf("direnv hook 'mode' only applies with 'from' naming the .envrc to install")
+	}
+	if h.Mode != "" && h.Mode != GitHooksModeCopy && h.Mode != GitHooksModeSymlink {
+		return fmt.Errorf("direnv hook 'mode' must be '%s' or '%s'", GitHooksModeCopy, GitHooksModeSymlink)
 	}
+	if IsGlobPattern(h.From) {
+		return fmt.Errorf("direnv hook 'from' must name a single file")
+	}
+	return nil
+}
+
+// EnvrcFile returns the .envrc a direnv hook allows, relative to the worktree.
+func (h *Hook) EnvrcFile() string {
+	if h.To == "" {
+		return DefaultEnvrc
+	}
+	return h.To
+}
+
+func (h *Hook) validateEnsureLine() error {
+	if h.File == "" || h.Line == "" {
+		return fmt.Errorf("ensure_line hook requires both 'file' and 'line' fields")
+	}
+	if h.From != "" || h.To != "" || h.Command != "" {
+		return fmt.Errorf("ensure_line hook should not have 'from', 'to', or 'command' fields")
+	}
+	if strings.Contains(h.Marker, "\n") {
+		return fmt.Errorf("ensure_line hook 'marker' must be a single line")
+	}
+	if h.Match != "" {
+		if h.Marker != "" || strings.Contains(h.Line, "\n") {
+			return fmt.Errorf("ensure_line hook 'match' only applies to a single 'line' without 'marker'")
+		}
+		if _, err := regexp.Compile(h.Match); err != nil {
+			return fmt.Errorf("invalid ensure_line 'match' pattern: %w", err)
+		}
+	}
+	return nil
+}
 
+func (h *Hook) validateDownload() error {
+	if h.URL == "" || h.To == "" {
+		return fmt.Errorf("download hook requires both 'url' and 'to' fields")
+	}
+	parsed, err := url.Parse(h.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("download hook 'url' must be an http or https URL: %s", h.URL)
+	}
+	if h.From != "" || h.Command != "" {
+		return fmt.Errorf("download hook should not have 'from' or 'command' fields")
+	}
+	if h.Checksum != "" {
+		digest, found := strings.CutPrefix(h.Checksum, checksumPrefixSHA256)
+		if _, err := hex.DecodeString(digest); !found || err != nil || len(digest) != sha256HexLength {
+			return fmt.Errorf("download hook 'checksum' must be in the form 'sha256:<64 hex characters>'")
+		}
+	}
 	return nil
 }
 
@@ -249,9 +1387,34 @@ func (c *Config) HasHooks() bool {
 	return len(c.Hooks.PostCreate) > 0
 }
 
-// slugify converts a branch name to a slug (replaces / with -)
-func slugify(s string) string {
-	return strings.ReplaceAll(s, "/", "-")
+// HasPreRemoveHooks returns true if the configuration has any pre-remove hooks
+func (c *Config) HasPreRemoveHooks() bool {
+	return len(c.Hooks.PreRemove) > 0
+}
+
+// HasMaintenanceHooks returns true if the configuration has any maintenance hooks
+func (c *Config) HasMaintenanceHooks() bool {
+	return len(c.Hooks.Maintenance) > 0
+}
+
+// HasCleanHooks returns true if the configuration has any clean hooks
+func (c *Config) HasCleanHooks() bool {
+	return len(c.Hooks.Clean) > 0
+}
+
+// HasPostRemoveHooks returns true if the configuration has any post-remove hooks
+func (c *Config) HasPostRemoveHooks() bool {
+	return len(c.Hooks.PostRemove) > 0
+}
+
+// HasPostPruneHooks returns true if the configuration has any post-prune hooks
+func (c *Config) HasPostPruneHooks() bool {
+	return len(c.Hooks.PostPrune) > 0
+}
+
+// HasPostCheckoutHooks returns true if the configuration has any post-checkout hooks
+func (c *Config) HasPostCheckoutHooks() bool {
+	return len(c.Hooks.PostCheckout) > 0
 }
 
 // ExpandVariables expands placeholder variables in the given string.
@@ -260,7 +1423,26 @@ func slugify(s string) string {
 //   - ${PATHNAME} - Absolute path of the repository root
 //   - ${BRANCH} - Target branch name (alias: ${TARGET_BRANCH})
 //   - ${BRANCH_SLUG} - Slugified branch name (alias: ${TARGET_SLUG})
+//   - ${COMMIT} - Short SHA of the branch, or of HEAD before the branch exists
+//   - ${REMOTE} - Remote the branch tracks, else origin, else the first remote
+//   - ${DEFAULT_BRANCH} - Branch the remote's HEAD points to
+//   - ${REPO_NAME} - Repository name from the origin URL
+//   - ${env:VAR} - Environment variable VAR; ${env:VAR:-default} falls back to
+//     default when VAR is unset or empty
+//
+// ${BRANCH_SLUG} uses the default slug policy; see Config.ExpandVariables. The git
+// variables are only resolved, with git, when s contains them.
 func ExpandVariables(s, repoRoot, branchName string) string {
+	return expandVariables(s, repoRoot, branchName, slugify(branchName))
+}
+
+// ExpandVariables is like the package-level ExpandVariables, but slugs the branch with
+// defaults.slug.
+func (c *Config) ExpandVariables(s, repoRoot, branchName string) string {
+	return expandVariables(s, repoRoot, branchName, c.Defaults.Slug.Slugify(branchName))
+}
+
+func expandVariables(s, repoRoot, branchName, branchSlug string) string {
 	// Get absolute path of repoRoot
 	absRepoRoot, err := filepath.Abs(repoRoot)
 	if err != nil {
@@ -270,9 +1452,6 @@ func ExpandVariables(s, repoRoot, branchName string) string {
 	// Get directory name (basename)
 	dirName := filepath.Base(absRepoRoot)
 
-	// Create slug from branch name
-	branchSlug := slugify(branchName)
-
 	// Replace variables
 	result := s
 	result = strings.ReplaceAll(result, "${DIRNAME}", dirName)
@@ -281,19 +1460,50 @@ func ExpandVariables(s, repoRoot, branchName string) string {
 	result = strings.ReplaceAll(result, "${TARGET_BRANCH}", branchName)
 	result = strings.ReplaceAll(result, "${BRANCH_SLUG}", branchSlug)
 	result = strings.ReplaceAll(result, "${TARGET_SLUG}", branchSlug)
+	result = expandGitVariables(result, absRepoRoot, branchName)
+	result = expandEnvReferences(result)
 
 	return result
 }
 
-// ResolveWorktreePath resolves the full path for a worktree given a name
+// envReferencePattern matches ${env:VAR} and ${env:VAR:-default}.
+var envReferencePattern = regexp.MustCompile(`\$\{env:([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+func expandEnvReferences(s string) string {
+	return envReferencePattern.ReplaceAllStringFunc(s, func(ref string) string {
+		match := envReferencePattern.FindStringSubmatch(ref)
+		if value := os.Getenv(match[1]); value != "" || match[2] == "" {
+			return value
+		}
+		return match[3]
+	})
+}
+
+// ResolveWorktreePath resolves the full path for a worktree given a name. The directory under
+// base_dir is defaults.worktree_dir expanded for the name when set; otherwise, with a slug
+// policy configured, the slugged name instead of the (possibly nested) name. An empty name
+// resolves to base_dir itself.
 func (c *Config) ResolveWorktreePath(repoRoot, worktreeName string) string {
-	baseDir := c.Defaults.BaseDir
+	return c.ResolveRefWorktreePath(repoRoot, worktreeName, "branch")
+}
 
-	// Expand variables in baseDir
-	baseDir = ExpandVariables(baseDir, repoRoot, worktreeName)
+// ResolveRefWorktreePath is like ResolveWorktreePath for a worktree named after ref, a
+// branch, tag, or commit as refType says. base_dir and worktree_dir see them as ${REF} and
+// ${REF_TYPE}.
+func (c *Config) ResolveRefWorktreePath(repoRoot, ref, refType string) string {
+	refVars := strings.NewReplacer("${REF}", ref, "${REF_TYPE}", refType)
+	baseDir := c.ExpandVariables(refVars.Replace(c.Defaults.BaseDir), repoRoot, ref)
 
 	if !filepath.IsAbs(baseDir) {
 		baseDir = filepath.Join(repoRoot, baseDir)
 	}
-	return filepath.Join(baseDir, worktreeName)
+	if ref != "" && c.Defaults.WorktreeDir != "" {
+		if dir := c.ExpandVariables(refVars.Replace(c.Defaults.WorktreeDir), repoRoot, ref); dir != "" {
+			return filepath.Join(baseDir, dir)
+		}
+	}
+	if c.Defaults.Slug.IsSet() {
+		return filepath.Join(baseDir, c.Defaults.Slug.Slugify(ref))
+	}
+	return filepath.Join(baseDir, ref)
 }
@@ -3,7 +3,10 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestMergeConfig(t *testing.T) {
@@ -90,6 +93,97 @@ func TestMergeConfig(t *testing.T) {
 	})
 }
 
+func TestMergeConfig_PostRemoveHooks(t *testing.T) {
+	base := &Config{Hooks: Hooks{
+		PostRemove: []Hook{{Type: HookTypeCommand, Command: "dropdb A"}},
+		PostPrune:  []Hook{{Type: HookTypeCommand, Command: "docker system prune"}},
+	}}
+	override := &Config{Hooks: Hooks{PostRemove: []Hook{{Type: HookTypeCommand, Command: "dropdb B"}}}}
+	result := MergeConfig(base, override)
+	if len(result.Hooks.PostRemove) != 2 || len(result.Hooks.PostPrune) != 1 {
+		t.Fatalf("Expected 2 post_remove and 1 post_prune hook, got %+v", result.Hooks)
+	}
+	if !result.HasPostRemoveHooks() || !result.HasPostPruneHooks() {
+		t.Error("Expected HasPostRemoveHooks and HasPostPruneHooks to report the hooks")
+	}
+
+	invalid := &Config{Hooks: Hooks{PostPrune: []Hook{{Type: HookTypeCommand}}}}
+	if err := invalid.Validate(); err == nil || !strings.Contains(err.Error(), "invalid post_prune hook 1") {
+		t.Errorf("Expected post_prune hooks to be validated, got %v", err)
+	}
+}
+
+func TestMergeConfig_LifecycleHooks(t *testing.T) {
+	t.Run("pre_remove hooks concatenated independently", func(t *testing.T) {
+		base := &Config{
+			Hooks: Hooks{
+				PostCreate: []Hook{{Type: HookTypeCommand, Command: "echo create"}},
+				PreRemove:  []Hook{{Type: HookTypeCommand, Command: "echo A"}},
+			},
+		}
+		override := &Config{
+			Hooks: Hooks{
+				PreRemove: []Hook{{Type: HookTypeCommand, Command: "echo B"}},
+			},
+		}
+		result := MergeConfig(base, override)
+		if len(result.Hooks.PostCreate) != 1 {
+			t.Fatalf("Expected 1 post_create hook, got %d", len(result.Hooks.PostCreate))
+		}
+		if len(result.Hooks.PreRemove) != 2 {
+			t.Fatalf("Expected 2 pre_remove hooks, got %d", len(result.Hooks.PreRemove))
+		}
+		if result.Hooks.PreRemove[0].Command != "echo A" || result.Hooks.PreRemove[1].Command != "echo B" {
+			t.Errorf("Unexpected pre_remove order: %+v", result.Hooks.PreRemove)
+		}
+	})
+
+	t.Run("post_checkout hooks concatenated", func(t *testing.T) {
+		base := &Config{Hooks: Hooks{PostCheckout: []Hook{{Type: HookTypeCommand, Command: "echo A"}}}}
+		override := &Config{Hooks: Hooks{PostCheckout: []Hook{{Type: HookTypeCommand, Command: "echo B"}}}}
+		result := MergeConfig(base, override)
+		if len(result.Hooks.PostCheckout) != 2 {
+			t.Fatalf("Expected 2 post_checkout hooks, got %d", len(result.Hooks.PostCheckout))
+		}
+		if len(base.Hooks.PostCheckout) != 1 {
+			t.Errorf("MergeConfig must not modify base hooks")
+		}
+	})
+
+	t.Run("maintenance hooks and interval", func(t *testing.T) {
+		base := &Config{
+			Defaults: Defaults{MaintenanceInterval: "24h"},
+			Hooks:    Hooks{Maintenance: []Hook{{Type: HookTypeCommand, Command: "git maintenance run"}}},
+		}
+		override := &Config{
+			Defaults: Defaults{MaintenanceInterval: "168h"},
+			Hooks:    Hooks{Maintenance: []Hook{{Type: HookTypeCommand, Command: "rm -rf tmp"}}},
+		}
+		result := MergeConfig(base, override)
+		if len(result.Hooks.Maintenance) != 2 {
+			t.Fatalf("Expected 2 maintenance hooks, got %d", len(result.Hooks.Maintenance))
+		}
+		if result.MaintenanceInterval() != 168*time.Hour {
+			t.Errorf("Expected override interval, got %s", result.MaintenanceInterval())
+		}
+		if err := (&Config{Defaults: Defaults{MaintenanceInterval: "daily"}}).Validate(); err == nil {
+			t.Error("Expected error for invalid defaults.maintenance_interval")
+		}
+	})
+
+	t.Run("clean hooks concatenated", func(t *testing.T) {
+		base := &Config{Hooks: Hooks{Clean: []Hook{{Type: HookTypeCommand, Command: "rm -rf node_modules"}}}}
+		override := &Config{Hooks: Hooks{Clean: []Hook{{Type: HookTypeCommand, Command: "cargo clean"}}}}
+		result := MergeConfig(base, override)
+		if len(result.Hooks.Clean) != 2 || !result.HasCleanHooks() {
+			t.Fatalf("Expected 2 clean hooks, got %d", len(result.Hooks.Clean))
+		}
+		if err := (&Config{Hooks: Hooks{Clean: []Hook{{Type: "bogus"}}}}).Validate(); err == nil {
+			t.Error("Expected error for an invalid clean hook")
+		}
+	})
+}
+
 func TestLoadConfig_GlobalOnly(t *testing.T) {
 	globalDir := t.TempDir()
 	repoDir := t.TempDir() // no config file here
@@ -110,7 +204,7 @@ hooks:
 	userHomeDir = func() (string, error) { return globalDir, nil }
 	t.Cleanup(func() { userHomeDir = original })
 
-	config, err := LoadConfig(repoDir)
+	config, err := LoadConfig(repoDir, "")
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -149,7 +243,7 @@ defaults:
 	userHomeDir = func() (string, error) { return globalDir, nil }
 	t.Cleanup(func() { userHomeDir = original })
 
-	config, err := LoadConfig(repoDir)
+	config, err := LoadConfig(repoDir, "")
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -159,6 +253,74 @@ defaults:
 	}
 }
 
+func TestLoadConfig_LocalLayer(t *testing.T) {
+	globalDir := t.TempDir()
+	repoDir := t.TempDir()
+
+	files := map[string]string{
+		filepath.Join(globalDir, ConfigFileName): `hooks:
+  post_create:
+    - type: command
+      command: "echo global"
+`,
+		filepath.Join(repoDir, ConfigFileName): `defaults:
+  base_dir: "../repo-wt"
+hooks:
+  post_create:
+    - type: command
+      command: "echo repo"
+`,
+		filepath.Join(repoDir, LocalConfigFileName): `defaults:
+  base_dir: "../my-wt"
+hooks:
+  post_create:
+    - type: command
+      command: "echo local"
+`,
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	original := userHomeDir
+	userHomeDir = func() (string, error) { return globalDir, nil }
+	t.Cleanup(func() { userHomeDir = original })
+
+	config, err := LoadConfig(repoDir, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if config.Defaults.BaseDir != "../my-wt" {
+		t.Errorf("Expected local base_dir '../my-wt', got %s", config.Defaults.BaseDir)
+	}
+	var commands []string
+	for _, hook := range config.Hooks.PostCreate {
+		commands = append(commands, hook.Command)
+	}
+	if strings.Join(commands, ",") != "echo global,echo repo,echo local" {
+		t.Errorf("Expected global, repo, then local hooks, got %v", commands)
+	}
+}
+
+func TestLoadConfig_InvalidLocalConfig(t *testing.T) {
+	repoDir := t.TempDir()
+	original := userHomeDir
+	userHomeDir = func() (string, error) { return t.TempDir(), nil }
+	t.Cleanup(func() { userHomeDir = original })
+
+	if err := os.WriteFile(filepath.Join(repoDir, LocalConfigFileName), []byte("hooks: ["), 0o644); err != nil {
+		t.Fatalf("Failed to write local config: %v", err)
+	}
+
+	_, err := LoadConfig(repoDir, "")
+	if err == nil || !strings.Contains(err.Error(), "failed to load local config") {
+		t.Errorf("Expected local config error, got %v", err)
+	}
+}
+
 func TestLoadConfig_HooksConcatenated(t *testing.T) {
 	globalDir := t.TempDir()
 	repoDir := t.TempDir()
@@ -186,7 +348,7 @@ func TestLoadConfig_HooksConcatenated(t *testing.T) {
 	userHomeDir = func() (string, error) { return globalDir, nil }
 	t.Cleanup(func() { userHomeDir = original })
 
-	config, err := LoadConfig(repoDir)
+	config, err := LoadConfig(repoDir, "")
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -205,6 +367,53 @@ func TestLoadConfig_HooksConcatenated(t *testing.T) {
 	}
 }
 
+func TestLoadConfig_PreRemoveHooks(t *testing.T) {
+	globalDir := t.TempDir()
+	repoDir := t.TempDir()
+
+	globalConfig := `hooks:
+  pre_remove:
+    - type: command
+      command: "echo global"
+`
+	repoConfig := `hooks:
+  pre_remove:
+    - type: copy
+      from: "dist"
+`
+	if err := os.WriteFile(filepath.Join(globalDir, ConfigFileName), []byte(globalConfig), 0o644); err != nil {
+		t.Fatalf("Failed to write global config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, ConfigFileName), []byte(repoConfig), 0o644); err != nil {
+		t.Fatalf("Failed to write repo config: %v", err)
+	}
+
+	original := userHomeDir
+	userHomeDir = func() (string, error) { return globalDir, nil }
+	t.Cleanup(func() { userHomeDir = original })
+
+	config, err := LoadConfig(repoDir, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !config.HasPreRemoveHooks() {
+		t.Fatal("Expected pre_remove hooks to be loaded")
+	}
+	if config.HasHooks() {
+		t.Error("pre_remove hooks should not count as post_create hooks")
+	}
+	if len(config.Hooks.PreRemove) != 2 {
+		t.Fatalf("Expected 2 pre_remove hooks, got %d", len(config.Hooks.PreRemove))
+	}
+	if config.Hooks.PreRemove[0].Command != "echo global" {
+		t.Errorf("Expected global hook first, got %+v", config.Hooks.PreRemove[0])
+	}
+	if config.Hooks.PreRemove[1].To != "dist" {
+		t.Errorf("Expected copy hook 'to' to default to 'dist', got %q", config.Hooks.PreRemove[1].To)
+	}
+}
+
 func TestLoadConfig_GlobalHooksOnlyWhenRepoHasNone(t *testing.T) {
 	globalDir := t.TempDir()
 	repoDir := t.TempDir()
@@ -228,7 +437,7 @@ func TestLoadConfig_GlobalHooksOnlyWhenRepoHasNone(t *testing.T) {
 	userHomeDir = func() (string, error) { return globalDir, nil }
 	t.Cleanup(func() { userHomeDir = original })
 
-	config, err := LoadConfig(repoDir)
+	config, err := LoadConfig(repoDir, "")
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -252,7 +461,7 @@ func TestLoadConfig_NeitherExists(t *testing.T) {
 	userHomeDir = func() (string, error) { return globalDir, nil }
 	t.Cleanup(func() { userHomeDir = original })
 
-	config, err := LoadConfig(repoDir)
+	config, err := LoadConfig(repoDir, "")
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -280,7 +489,7 @@ func TestLoadConfig_NonExistentFile(t *testing.T) {
 	stubHomeDir(t)
 	tempDir := t.TempDir()
 
-	config, err := LoadConfig(tempDir)
+	config, err := LoadConfig(tempDir, "")
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -319,13 +528,14 @@ hooks:
 		t.Fatalf("Failed to write test config: %v", err)
 	}
 
-	config, err := LoadConfig(tempDir)
+	config, err := LoadConfig(tempDir, "")
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
 
-	if config.Version != "1.0" {
-		t.Errorf("Expected version '1.0', got %s", config.Version)
+	// Loading migrates the file to the current version
+	if config.Version != CurrentVersion {
+		t.Errorf("Expected version '%s', got %s", CurrentVersion, config.Version)
 	}
 
 	if config.Defaults.BaseDir != "../my-worktrees" {
@@ -366,7 +576,7 @@ hooks:
 		t.Fatalf("Failed to write test config: %v", err)
 	}
 
-	config, err := LoadConfig(tempDir)
+	config, err := LoadConfig(tempDir, "")
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -400,7 +610,7 @@ hooks:
 		t.Fatalf("Failed to write test config: %v", err)
 	}
 
-	_, err = LoadConfig(tempDir)
+	_, err = LoadConfig(tempDir, "")
 	if err == nil {
 		t.Error("Expected error for invalid YAML, got nil")
 	}
@@ -438,7 +648,7 @@ func TestSaveConfig(t *testing.T) {
 	}
 
 	// Load it back and verify
-	loadedConfig, err := LoadConfig(tempDir)
+	loadedConfig, err := LoadConfig(tempDir, "")
 	if err != nil {
 		t.Fatalf("Failed to load saved config: %v", err)
 	}
@@ -508,6 +718,34 @@ func TestConfigValidate(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "invalid pre_remove hook - missing command",
+			config: &Config{
+				Version: "1.0",
+				Hooks: Hooks{
+					PreRemove: []Hook{
+						{
+							Type: HookTypeCommand,
+						},
+					},
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid post_checkout hook - unknown type",
+			config: &Config{
+				Version: "1.0",
+				Hooks: Hooks{
+					PostCheckout: []Hook{
+						{
+							Type: "bogus",
+						},
+					},
+				},
+			},
+			expectError: true,
+		},
 		{
 			name: "invalid command hook - missing command",
 			config: &Config{
@@ -665,122 +903,520 @@ func TestHookValidate(t *testing.T) {
 			},
 			expectError: true,
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			err := tt.hook.Validate()
-			if tt.expectError && err == nil {
-				t.Error("Expected error but got nil")
-			}
-			if !tt.expectError && err != nil {
-				t.Errorf("Expected no error but got: %v", err)
-			}
-		})
-	}
-}
-
-func TestHookValidate_DoesNotMutateTo(t *testing.T) {
-	hook := Hook{
-		Type: HookTypeCopy,
-		From: ".env",
-	}
-
-	if err := hook.Validate(); err != nil {
-		t.Fatalf("Expected no error but got: %v", err)
-	}
-
-	if hook.To != "" {
-		t.Errorf("Expected hook.To to remain empty, got %q", hook.To)
-	}
-}
-
-func TestHookApplyDefaults_CopyToDefaultsToFrom(t *testing.T) {
-	hook := Hook{
-		Type: HookTypeCopy,
-		From: ".env",
-	}
-
-	hook.ApplyDefaults()
-
-	if hook.To != hook.From {
-		t.Errorf("Expected hook.To to default to %q, got %q", hook.From, hook.To)
-	}
-
-	if err := hook.Validate(); err != nil {
-		t.Fatalf("Expected no error but got: %v", err)
-	}
-}
-
-func TestConfigApplyDefaults_CopyToDefaultsToFrom(t *testing.T) {
-	config := &Config{
-		Version: "1.0",
-		Hooks: Hooks{
-			PostCreate: []Hook{
-				{
-					Type: HookTypeCopy,
-					From: ".env",
-				},
+		{
+			name: "copy hook from ref",
+			hook: Hook{
+				Type:    HookTypeCopy,
+				From:    ".vscode/settings.json",
+				FromRef: "main",
 			},
+			expectError: false,
 		},
-	}
-
-	config.ApplyDefaults()
-
-	if err := config.Validate(); err != nil {
-		t.Fatalf("Expected no error but got: %v", err)
-	}
-
-	if got := config.Hooks.PostCreate[0].To; got != ".env" {
-		t.Errorf("Expected hook.To to default to %q, got %q", ".env", got)
-	}
-}
-
-func TestConfigValidate_CopyAbsoluteFromRequiresTo(t *testing.T) {
-	config := &Config{
-		Version: "1.0",
-		Hooks: Hooks{
-			PostCreate: []Hook{
-				{
-					Type: HookTypeCopy,
-					From: filepath.Join(string(os.PathSeparator), "tmp", "source.txt"),
-				},
+		{
+			name: "copy hook from worktree",
+			hook: Hook{
+				Type:         HookTypeCopy,
+				From:         ".env.local",
+				FromWorktree: "@",
 			},
+			expectError: false,
 		},
-	}
-
-	config.ApplyDefaults()
-
-	if err := config.Validate(); err == nil {
-		t.Fatalf("Expected error but got nil")
-	}
-}
-
-func TestResolveWorktreePath(t *testing.T) {
-	tests := []struct {
-		name         string
-		config       *Config
-		repoRoot     string
-		worktreeName string
-		expected     string
-	}{
 		{
-			name: "relative base_dir",
-			config: &Config{
-				Defaults: Defaults{
-					BaseDir: "../worktrees",
-				},
+			name: "copy hook with both from_ref and from_worktree",
+			hook: Hook{
+				Type:         HookTypeCopy,
+				From:         ".env",
+				FromRef:      "main",
+				FromWorktree: "@",
 			},
-			repoRoot:     "/home/user/project",
-			worktreeName: "feature/auth",
-			expected:     "/home/user/worktrees/feature/auth",
+			expectError: true,
 		},
 		{
-			name: "absolute base_dir",
-			config: &Config{
-				Defaults: Defaults{
-					BaseDir: "/tmp/worktrees",
-				},
+			name: "copy hook from ref with absolute from",
+			hook: Hook{
+				Type:    HookTypeCopy,
+				From:    "/etc/hosts",
+				To:      "hosts",
+				FromRef: "main",
+			},
+			expectError: true,
+		},
+		{
+			name:        "copy hook with cow",
+			hook:        Hook{Type: HookTypeCopy, From: "node_modules", COW: CopyCOWAlways},
+			expectError: false,
+		},
+		{
+			name:        "copy hook with invalid cow",
+			hook:        Hook{Type: HookTypeCopy, From: "node_modules", COW: "reflink"},
+			expectError: true,
+		},
+		{
+			name:        "copy hook from ref with cow always",
+			hook:        Hook{Type: HookTypeCopy, From: ".env", FromRef: "main", COW: CopyCOWAlways},
+			expectError: true,
+		},
+		{
+			name:        "valid hardlink hook",
+			hook:        Hook{Type: HookTypeHardlink, From: "fixtures", To: "fixtures"},
+			expectError: false,
+		},
+		{
+			name:        "hardlink hook missing from",
+			hook:        Hook{Type: HookTypeHardlink, To: "fixtures"},
+			expectError: true,
+		},
+		{
+			name:        "hardlink hook with absolute from and no to",
+			hook:        Hook{Type: HookTypeHardlink, From: "/srv/fixtures"},
+			expectError: true,
+		},
+		{
+			name:        "hardlink hook with glob from",
+			hook:        Hook{Type: HookTypeHardlink, From: "fixtures/*", To: "fixtures"},
+			expectError: true,
+		},
+		{
+			name:        "symlink hook with cow",
+			hook:        Hook{Type: HookTypeSymlink, From: ".bin", To: ".bin", COW: CopyCOWAuto},
+			expectError: true,
+		},
+		{
+			name: "symlink hook with from_worktree",
+			hook: Hook{
+				Type:         HookTypeSymlink,
+				From:         ".bin",
+				To:           ".bin",
+				FromWorktree: "@",
+			},
+			expectError: true,
+		},
+		{
+			name: "valid download hook",
+			hook: Hook{
+				Type:          HookTypeDownload,
+				URL:           "https://artifacts.example.com/seed.sql.gz",
+				To:            "db/seed.sql.gz",
+				Checksum:      "sha256:abababababababababababababababababababababababababababababababab",
+				AuthHeaderEnv: "ARTIFACT_TOKEN",
+			},
+			expectError: false,
+		},
+		{
+			name: "download hook missing to",
+			hook: Hook{
+				Type: HookTypeDownload,
+				URL:  "https://artifacts.example.com/seed.sql.gz",
+			},
+			expectError: true,
+		},
+		{
+			name: "download hook with non-http url",
+			hook: Hook{
+				Type: HookTypeDownload,
+				URL:  "file:///etc/passwd",
+				To:   "passwd",
+			},
+			expectError: true,
+		},
+		{
+			name: "download hook with malformed checksum",
+			hook: Hook{
+				Type:     HookTypeDownload,
+				URL:      "https://artifacts.example.com/tool",
+				To:       "bin/tool",
+				Checksum: "md5:abc",
+			},
+			expectError: true,
+		},
+		{
+			name: "valid extract hook",
+			hook: Hook{
+				Type:            HookTypeExtract,
+				From:            "cache.tgz",
+				To:              ".cache",
+				StripComponents: 1,
+				Overwrite:       true,
+			},
+			expectError: false,
+		},
+		{
+			name: "extract hook missing to",
+			hook: Hook{
+				Type: HookTypeExtract,
+				From: "cache.tgz",
+			},
+			expectError: true,
+		},
+		{
+			name: "extract hook with negative strip_components",
+			hook: Hook{
+				Type:            HookTypeExtract,
+				From:            "cache.tgz",
+				To:              ".cache",
+				StripComponents: -1,
+			},
+			expectError: true,
+		},
+		{
+			name: "copy hook with overwrite",
+			hook: Hook{
+				Type:      HookTypeCopy,
+				From:      ".env",
+				To:        ".env",
+				Overwrite: true,
+			},
+			expectError: true,
+		},
+		{
+			name: "command hook with url",
+			hook: Hook{
+				Type:    HookTypeCommand,
+				Command: "echo",
+				URL:     "https://example.com",
+			},
+			expectError: true,
+		},
+		{
+			name: "valid gitconfig hook",
+			hook: Hook{
+				Type: HookTypeGitConfig,
+				GitConfig: map[string]string{
+					"pull.rebase":           "true",
+					"remote.origin.pushurl": "git@example.com:me/repo.git",
+					"branch.feat/x.remote":  "origin",
+				},
+				Scope: GitConfigScopeWorktree,
+			},
+			expectError: false,
+		},
+		{
+			name: "valid git_hooks hook",
+			hook: Hook{Type: HookTypeGitHooks, From: ".githooks", Mode: GitHooksModeSymlink},
+		},
+		{
+			name:        "git_hooks hook without from",
+			hook:        Hook{Type: HookTypeGitHooks},
+			expectError: true,
+		},
+		{
+			name:        "git_hooks hook with invalid mode",
+			hook:        Hook{Type: HookTypeGitHooks, From: ".githooks", Mode: "hardlink"},
+			expectError: true,
+		},
+		{
+			name: "valid direnv hook",
+			hook: Hook{Type: HookTypeDirenv},
+		},
+		{
+			name: "valid direnv hook installing an envrc",
+			hook: Hook{Type: HookTypeDirenv, From: ".envrc.example", To: "api/.envrc", Mode: GitHooksModeSymlink},
+		},
+		{
+			name:        "direnv hook with mode but no from",
+			hook:        Hook{Type: HookTypeDirenv, Mode: GitHooksModeCopy},
+			expectError: true,
+		},
+		{
+			name:        "direnv hook with glob from",
+			hook:        Hook{Type: HookTypeDirenv, From: "envrc/*"},
+			expectError: true,
+		},
+		{
+			name:        "copy hook with mode",
+			hook:        Hook{Type: HookTypeCopy, From: "a", To: "b", Mode: GitHooksModeCopy},
+			expectError: true,
+		},
+		{
+			name:        "gitconfig hook without config",
+			hook:        Hook{Type: HookTypeGitConfig},
+			expectError: true,
+		},
+		{
+			name:        "gitconfig hook with key missing section",
+			hook:        Hook{Type: HookTypeGitConfig, GitConfig: map[string]string{"rebase": "true"}},
+			expectError: true,
+		},
+		{
+			name:        "gitconfig hook with invalid variable name",
+			hook:        Hook{Type: HookTypeGitConfig, GitConfig: map[string]string{"pull.1rebase": "true"}},
+			expectError: true,
+		},
+		{
+			name: "gitconfig hook with unknown scope",
+			hook: Hook{
+				Type:      HookTypeGitConfig,
+				GitConfig: map[string]string{"pull.rebase": "true"},
+				Scope:     "global",
+			},
+			expectError: true,
+		},
+		{
+			name: "valid patch hook",
+			hook: Hook{
+				Type:   HookTypePatch,
+				File:   "config/dev.yml",
+				Set:    map[string]interface{}{".server.port": "${WTP_PORT_BASE}", `.plugins[0]."a.b"`: true},
+				Delete: []string{".debug"},
+			},
+			expectError: false,
+		},
+		{
+			name:        "patch hook without file",
+			hook:        Hook{Type: HookTypePatch, Set: map[string]interface{}{".port": 1}},
+			expectError: true,
+		},
+		{
+			name:        "patch hook without edits",
+			hook:        Hook{Type: HookTypePatch, File: "app.json"},
+			expectError: true,
+		},
+		{
+			name:        "patch hook with unknown extension",
+			hook:        Hook{Type: HookTypePatch, File: "app.ini", Set: map[string]interface{}{".port": 1}},
+			expectError: true,
+		},
+		{
+			name: "patch hook with explicit format",
+			hook: Hook{
+				Type: HookTypePatch, File: ".eslintrc", Format: PatchFormatJSON,
+				Set: map[string]interface{}{".root": true},
+			},
+			expectError: false,
+		},
+		{
+			name:        "patch hook with malformed selector",
+			hook:        Hook{Type: HookTypePatch, File: "app.json", Delete: []string{".items[x]"}},
+			expectError: true,
+		},
+		{
+			name:        "copy hook with set",
+			hook:        Hook{Type: HookTypeCopy, From: "a", To: "b", Set: map[string]interface{}{".a": 1}},
+			expectError: true,
+		},
+		{
+			name:        "command hook with timeout",
+			hook:        Hook{Type: HookTypeCommand, Command: "npm ci", Timeout: "5m"},
+			expectError: false,
+		},
+		{
+			name:        "command hook with malformed timeout",
+			hook:        Hook{Type: HookTypeCommand, Command: "npm ci", Timeout: "five minutes"},
+			expectError: true,
+		},
+		{
+			name:        "command hook with negative timeout",
+			hook:        Hook{Type: HookTypeCommand, Command: "npm ci", Timeout: "-1s"},
+			expectError: true,
+		},
+		{
+			name:        "copy hook with timeout",
+			hook:        Hook{Type: HookTypeCopy, From: ".env", Timeout: "1s"},
+			expectError: true,
+		},
+		{
+			name:        "valid ensure_line hook",
+			hook:        Hook{Type: HookTypeEnsureLine, File: "/etc/hosts", Line: "127.0.0.1 app.local", Match: `app\.local$`},
+			expectError: false,
+		},
+		{
+			name:        "ensure_line hook without line",
+			hook:        Hook{Type: HookTypeEnsureLine, File: ".gitignore"},
+			expectError: true,
+		},
+		{
+			name: "ensure_line hook with match and marker",
+			hook: Hook{
+				Type: HookTypeEnsureLine, File: ".env", Line: "A=1", Match: "^A=", Marker: "vars",
+			},
+			expectError: true,
+		},
+		{
+			name:        "ensure_line hook with invalid match",
+			hook:        Hook{Type: HookTypeEnsureLine, File: ".env", Line: "A=1", Match: "("},
+			expectError: true,
+		},
+		{
+			name:        "patch hook with line",
+			hook:        Hook{Type: HookTypePatch, File: "a.json", Set: map[string]interface{}{".a": 1}, Line: "x"},
+			expectError: true,
+		},
+		{
+			name: "command hook with config",
+			hook: Hook{
+				Type:      HookTypeCommand,
+				Command:   "echo",
+				GitConfig: map[string]string{"pull.rebase": "true"},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.hook.Validate()
+			if tt.expectError && err == nil {
+				t.Error("Expected error but got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestHookValidate_DoesNotMutateTo(t *testing.T) {
+	hook := Hook{
+		Type: HookTypeCopy,
+		From: ".env",
+	}
+
+	if err := hook.Validate(); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	if hook.To != "" {
+		t.Errorf("Expected hook.To to remain empty, got %q", hook.To)
+	}
+}
+
+func TestHookApplyDefaults_CopyToDefaultsToFrom(t *testing.T) {
+	hook := Hook{
+		Type: HookTypeCopy,
+		From: ".env",
+	}
+
+	hook.ApplyDefaults()
+
+	if hook.To != hook.From {
+		t.Errorf("Expected hook.To to default to %q, got %q", hook.From, hook.To)
+	}
+
+	if err := hook.Validate(); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+}
+
+func TestHookApplyDefaults_HardlinkToDefaultsToFrom(t *testing.T) {
+	hook := Hook{
+		Type: HookTypeHardlink,
+		From: "fixtures",
+	}
+
+	hook.ApplyDefaults()
+
+	if hook.To != hook.From {
+		t.Errorf("Expected hook.To to default to %q, got %q", hook.From, hook.To)
+	}
+
+	if err := hook.Validate(); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+}
+
+func TestHookApplyDefaults_CopyGlobDefaultsToBase(t *testing.T) {
+	tests := []struct {
+		from   string
+		wantTo string
+	}{
+		{"config/*.example", "config"},
+		{"packages/*/.env", "packages"},
+		{"*.local", "."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from, func(t *testing.T) {
+			hook := Hook{Type: HookTypeCopy, From: tt.from}
+			hook.ApplyDefaults()
+			if hook.To != tt.wantTo {
+				t.Errorf("Expected hook.To = %q, got %q", tt.wantTo, hook.To)
+			}
+			if err := hook.Validate(); err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+		})
+	}
+
+	invalid := []Hook{
+		{Type: HookTypeCopy, From: "config/[a-", To: "config"},
+		{Type: HookTypeCopy, From: "config/*.json", To: "config", FromRef: "main"},
+	}
+	for i := range invalid {
+		if err := invalid[i].Validate(); err == nil {
+			t.Errorf("Expected error for %+v", invalid[i])
+		}
+	}
+}
+
+func TestConfigApplyDefaults_CopyToDefaultsToFrom(t *testing.T) {
+	config := &Config{
+		Version: "1.0",
+		Hooks: Hooks{
+			PostCreate: []Hook{
+				{
+					Type: HookTypeCopy,
+					From: ".env",
+				},
+			},
+		},
+	}
+
+	config.ApplyDefaults()
+
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	if got := config.Hooks.PostCreate[0].To; got != ".env" {
+		t.Errorf("Expected hook.To to default to %q, got %q", ".env", got)
+	}
+}
+
+func TestConfigValidate_CopyAbsoluteFromRequiresTo(t *testing.T) {
+	config := &Config{
+		Version: "1.0",
+		Hooks: Hooks{
+			PostCreate: []Hook{
+				{
+					Type: HookTypeCopy,
+					From: filepath.Join(string(os.PathSeparator), "tmp", "source.txt"),
+				},
+			},
+		},
+	}
+
+	config.ApplyDefaults()
+
+	if err := config.Validate(); err == nil {
+		t.Fatalf("Expected error but got nil")
+	}
+}
+
+func TestResolveWorktreePath(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       *Config
+		repoRoot     string
+		worktreeName string
+		expected     string
+	}{
+		{
+			name: "relative base_dir",
+			config: &Config{
+				Defaults: Defaults{
+					BaseDir: "../worktrees",
+				},
+			},
+			repoRoot:     "/home/user/project",
+			worktreeName: "feature/auth",
+			expected:     "/home/user/worktrees/feature/auth",
+		},
+		{
+			name: "absolute base_dir",
+			config: &Config{
+				Defaults: Defaults{
+					BaseDir: "/tmp/worktrees",
+				},
 			},
 			repoRoot:     "/home/user/project",
 			worktreeName: "feature/auth",
@@ -885,6 +1521,33 @@ func TestExpandVariables(t *testing.T) {
 	}
 }
 
+func TestExpandVariables_Env(t *testing.T) {
+	t.Setenv("WTP_TEST_CACHE", "/var/cache/wtp")
+	t.Setenv("WTP_TEST_EMPTY", "")
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"${env:WTP_TEST_CACHE}/${BRANCH_SLUG}", "/var/cache/wtp/feature-auth"},
+		{"${env:WTP_TEST_UNSET}", ""},
+		{"${env:WTP_TEST_UNSET:-../worktrees}", "../worktrees"},
+		{"${env:WTP_TEST_EMPTY:-fallback}", "fallback"},
+		{"${env:WTP_TEST_CACHE:-fallback}", "/var/cache/wtp"},
+		{"${env:WTP_TEST_UNSET:-../${DIRNAME}}", "../myproject"},
+		{"${env:not-a-name}", "${env:not-a-name}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := ExpandVariables(tt.input, "/home/user/myproject", "feature/auth")
+			if result != tt.expected {
+				t.Errorf("ExpandVariables(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestSlugify(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -948,6 +1611,25 @@ func TestResolveWorktreePath_WithVariables(t *testing.T) {
 	}
 }
 
+func TestResolveRefWorktreePath(t *testing.T) {
+	cfg := &Config{Defaults: Defaults{BaseDir: "../worktrees/${REF_TYPE}"}}
+
+	got := cfg.ResolveRefWorktreePath("/home/user/project", "v1.2.3", "tag")
+	if got != "/home/user/worktrees/tag/v1.2.3" {
+		t.Errorf("Expected the tag directory, got %s", got)
+	}
+	got = cfg.ResolveWorktreePath("/home/user/project", "feature/auth")
+	if got != "/home/user/worktrees/branch/feature/auth" {
+		t.Errorf("Expected ${REF_TYPE} to be branch for ResolveWorktreePath, got %s", got)
+	}
+
+	cfg = &Config{Defaults: Defaults{BaseDir: "../worktrees", WorktreeDir: "${REF_TYPE}-${REF}"}}
+	got = cfg.ResolveRefWorktreePath("/home/user/project", "3b20f2c", "commit")
+	if got != "/home/user/worktrees/commit-3b20f2c" {
+		t.Errorf("Expected worktree_dir to see the ref, got %s", got)
+	}
+}
+
 func TestHasHooks(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -992,3 +1674,372 @@ func TestHasHooks(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_CommandTimeout(t *testing.T) {
+	cfg := &Config{Defaults: Defaults{HookTimeout: "10m"}}
+
+	if got := cfg.CommandTimeout(&Hook{Type: HookTypeCommand, Timeout: "30s"}); got != 30*time.Second {
+		t.Errorf("Expected hook timeout to win, got %v", got)
+	}
+	if got := cfg.CommandTimeout(&Hook{Type: HookTypeCommand}); got != 10*time.Minute {
+		t.Errorf("Expected defaults.hook_timeout, got %v", got)
+	}
+	if got := (&Config{}).CommandTimeout(&Hook{Type: HookTypeCommand}); got != 0 {
+		t.Errorf("Expected no timeout, got %v", got)
+	}
+
+	invalid := &Config{Defaults: Defaults{HookTimeout: "soon"}}
+	if err := invalid.Validate(); err == nil {
+		t.Error("Expected error for invalid defaults.hook_timeout")
+	}
+}
+
+func TestConfig_OperationTimeout(t *testing.T) {
+	base := &Config{Defaults: Defaults{OperationTimeout: "30m"}}
+	if got := base.OperationTimeout(); got != 30*time.Minute {
+		t.Errorf("Expected 30m, got %v", got)
+	}
+	if got := (&Config{}).OperationTimeout(); got != 0 {
+		t.Errorf("Expected no timeout, got %v", got)
+	}
+
+	merged := mergeDefaults(&base.Defaults, &Defaults{OperationTimeout: "1h"})
+	if merged.OperationTimeout != "1h" {
+		t.Errorf("Expected the override to win, got %q", merged.OperationTimeout)
+	}
+
+	for _, value := range []string{"soon", "-5m"} {
+		invalid := &Config{Defaults: Defaults{OperationTimeout: value}}
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("Expected error for defaults.operation_timeout %q", value)
+		}
+	}
+}
+
+func TestConfig_LockTimeout(t *testing.T) {
+	if got := (&Config{}).LockTimeout(); got != DefaultLockTimeout {
+		t.Errorf("Expected the default lock timeout, got %v", got)
+	}
+	base := &Config{Defaults: Defaults{LockTimeout: "30s"}}
+	if got := base.LockTimeout(); got != 30*time.Second {
+		t.Errorf("Expected 30s, got %v", got)
+	}
+
+	merged := mergeDefaults(&base.Defaults, &Defaults{LockTimeout: "2m"})
+	if merged.LockTimeout != "2m" {
+		t.Errorf("Expected the override to win, got %q", merged.LockTimeout)
+	}
+
+	invalid := &Config{Defaults: Defaults{LockTimeout: "soon"}}
+	if err := invalid.Validate(); err == nil {
+		t.Error("Expected error for invalid defaults.lock_timeout")
+	}
+}
+
+func TestHook_ValidateRegisterAndPrompt(t *testing.T) {
+	tests := []struct {
+		name    string
+		hook    Hook
+		wantErr bool
+	}{
+		{"command", Hook{Type: HookTypeCommand, Command: "make token", Register: "API_TOKEN"}, false},
+		{"invalid name", Hook{Type: HookTypeCommand, Command: "make token", Register: "api-token"}, true},
+		{"not a command", Hook{Type: HookTypeCopy, From: ".env", To: ".env", Register: "ENV"}, true},
+		{"prompt", Hook{Type: HookTypePrompt, Register: "API_KEY", Match: "^sk-", Default: "sk-dev", Secret: true}, false},
+		{"prompt without register", Hook{Type: HookTypePrompt, Message: "API key"}, true},
+		{"prompt default mismatch", Hook{Type: HookTypePrompt, Register: "API_KEY", Match: "^sk-", Default: "x"}, true},
+		{"prompt in group", Hook{Type: HookTypePrompt, Register: "API_KEY", Group: "setup"}, true},
+		{"message on command", Hook{Type: HookTypeCommand, Command: "true", Message: "hi"}, true},
+		{"once per repo", Hook{Type: HookTypeCommand, Command: "lefthook install", OncePerRepo: true}, false},
+		{"once per repo register",
+			Hook{Type: HookTypeCommand, Command: "make token", Register: "TOKEN", OncePerRepo: true}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.hook.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMergeConfig_ReadOnly(t *testing.T) {
+	merged := MergeConfig(&Config{Defaults: Defaults{ReadOnly: true}}, &Config{})
+	if !merged.Defaults.ReadOnly {
+		t.Error("Expected unset override to keep readonly")
+	}
+	merged = MergeConfig(&Config{}, &Config{Defaults: Defaults{ReadOnly: true}})
+	if !merged.Defaults.ReadOnly {
+		t.Error("Expected override to enable readonly")
+	}
+}
+
+func TestMergeConfig_DefaultsEnv(t *testing.T) {
+	base := &Config{Defaults: Defaults{Env: map[string]string{"NODE_ENV": "development", "CI": "1"}}}
+	override := &Config{Defaults: Defaults{Env: map[string]string{"NODE_ENV": "test"}}}
+
+	merged := MergeConfig(base, override)
+	want := map[string]string{"NODE_ENV": "test", "CI": "1"}
+	if !reflect.DeepEqual(merged.Defaults.Env, want) {
+		t.Errorf("Expected merged env %v, got %v", want, merged.Defaults.Env)
+	}
+	if base.Defaults.Env["NODE_ENV"] != "development" {
+		t.Error("Expected base env to be left untouched")
+	}
+}
+
+func TestConfig_HookEnv(t *testing.T) {
+	cfg := &Config{Defaults: Defaults{Env: map[string]string{"NODE_ENV": "development", "CI": "1"}}}
+	hook := &Hook{Type: HookTypeCommand, Command: "npm test", Env: map[string]string{"NODE_ENV": "test"}}
+
+	want := map[string]string{"NODE_ENV": "test", "CI": "1"}
+	if got := cfg.HookEnv(hook); !reflect.DeepEqual(got, want) {
+		t.Errorf("HookEnv() = %v, want %v", got, want)
+	}
+	if got := (&Config{}).HookEnv(hook); !reflect.DeepEqual(got, hook.Env) {
+		t.Errorf("HookEnv() without defaults.env = %v, want %v", got, hook.Env)
+	}
+}
+
+func TestHook_ValidateClearEnv(t *testing.T) {
+	command := Hook{Type: HookTypeCommand, Command: "make", ClearEnv: true}
+	if err := command.Validate(); err != nil {
+		t.Errorf("Expected clear_env to be valid on a command hook, got %v", err)
+	}
+	copyHook := Hook{Type: HookTypeCopy, From: ".env", ClearEnv: true}
+	if err := copyHook.Validate(); err == nil {
+		t.Error("Expected error for clear_env on a copy hook")
+	}
+}
+
+func TestHook_ValidateOutput(t *testing.T) {
+	for _, output := range []string{"", HookOutputInherit, HookOutputCapture, HookOutputFile} {
+		hook := Hook{Type: HookTypeCommand, Command: "npm ci", Output: output}
+		if err := hook.Validate(); err != nil {
+			t.Errorf("Expected output %q to be valid, got %v", output, err)
+		}
+	}
+	if err := (&Hook{Type: HookTypeCommand, Command: "npm ci", Output: "quiet"}).Validate(); err == nil {
+		t.Error("Expected error for output 'quiet'")
+	}
+	if err := (&Hook{Type: HookTypeCopy, From: ".env", Output: HookOutputFile}).Validate(); err == nil {
+		t.Error("Expected error for output on a copy hook")
+	}
+
+	merged := MergeConfig(&Config{Defaults: Defaults{HookLogDir: "logs"}}, &Config{})
+	if merged.Defaults.HookLogDir != "logs" {
+		t.Errorf("Expected unset override to keep hook_log_dir, got '%s'", merged.Defaults.HookLogDir)
+	}
+	merged = MergeConfig(merged, &Config{Defaults: Defaults{HookLogDir: "/tmp/wtp-logs"}})
+	if merged.Defaults.HookLogDir != "/tmp/wtp-logs" {
+		t.Errorf("Expected override to win for hook_log_dir, got '%s'", merged.Defaults.HookLogDir)
+	}
+}
+
+func TestHook_ValidateScript(t *testing.T) {
+	valid := []Hook{
+		{Type: HookTypeScript, Run: "set -u\nnpm ci\n"},
+		{Type: HookTypeScript, Run: "npm ci", Shell: ShellPwsh, Timeout: "5m", Retries: 2, Register: "OUT"},
+	}
+	for _, hook := range valid {
+		if err := hook.Validate(); err != nil {
+			t.Errorf("Expected %+v to be valid, got %v", hook, err)
+		}
+	}
+
+	invalid := map[string]Hook{
+		"script hook requires 'run' field":   {Type: HookTypeScript},
+		"should not have 'from', 'to', or":   {Type: HookTypeScript, Run: "make", Command: "make"},
+		"cannot use shell 'cmd'":             {Type: HookTypeScript, Run: "make", Shell: ShellCmd},
+		"must be a variable name":            {Type: HookTypeScript, Run: "make", Register: "not-a-name"},
+		"command hook should not have 'run'": {Type: HookTypeCommand, Command: "make", Run: "make"},
+	}
+	for want, hook := range invalid {
+		err := hook.Validate()
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error containing %q for %+v, got %v", want, hook, err)
+		}
+	}
+}
+
+func TestConfig_CommandShell(t *testing.T) {
+	cfg := &Config{Defaults: Defaults{Shell: ShellPwsh}}
+	if got := cfg.CommandShell(&Hook{Type: HookTypeCommand, Shell: ShellCmd}); got != ShellCmd {
+		t.Errorf("Expected hook shell to win, got %q", got)
+	}
+	if got := cfg.CommandShell(&Hook{Type: HookTypeCommand}); got != ShellPwsh {
+		t.Errorf("Expected defaults.shell, got %q", got)
+	}
+	merged := MergeConfig(cfg, &Config{Defaults: Defaults{Shell: ShellBash}})
+	if merged.Defaults.Shell != ShellBash {
+		t.Errorf("Expected override to set defaults.shell, got %q", merged.Defaults.Shell)
+	}
+
+	if err := (&Config{Defaults: Defaults{Shell: "fish"}}).Validate(); err == nil {
+		t.Error("Expected error for unknown defaults.shell")
+	}
+	if err := (&Hook{Type: HookTypeCommand, Command: "make", Shell: "zsh"}).Validate(); err == nil {
+		t.Error("Expected error for unknown hook shell")
+	}
+	if err := (&Hook{Type: HookTypeCopy, From: ".env", Shell: ShellCmd}).Validate(); err == nil {
+		t.Error("Expected error for shell on a copy hook")
+	}
+}
+
+func TestConfig_ValidateHookConcurrency(t *testing.T) {
+	cfg := &Config{Defaults: Defaults{HookConcurrency: -1}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for negative defaults.hook_concurrency")
+	}
+
+	merged := MergeConfig(&Config{Defaults: Defaults{HookConcurrency: 4}}, &Config{})
+	if merged.Defaults.HookConcurrency != 4 {
+		t.Errorf("Expected unset override to keep hook_concurrency, got %d", merged.Defaults.HookConcurrency)
+	}
+}
+
+func TestConfig_ValidateMaxConcurrentProvisions(t *testing.T) {
+	cfg := &Config{Defaults: Defaults{MaxConcurrentProvisions: -1}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for negative defaults.max_concurrent_provisions")
+	}
+
+	merged := MergeConfig(&Config{Defaults: Defaults{MaxConcurrentProvisions: 3}}, &Config{})
+	if merged.Defaults.MaxConcurrentProvisions != 3 {
+		t.Errorf("Expected unset override to keep max_concurrent_provisions, got %d",
+			merged.Defaults.MaxConcurrentProvisions)
+	}
+}
+
+func TestConfig_NestedWorktrees(t *testing.T) {
+	for _, action := range []string{"", NestedWorktreesWarn, NestedWorktreesError, NestedWorktreesAllow} {
+		if err := (&Config{Defaults: Defaults{NestedWorktrees: action}}).Validate(); err != nil {
+			t.Errorf("Expected nested_worktrees '%s' to be valid, got %v", action, err)
+		}
+	}
+	if err := (&Config{Defaults: Defaults{NestedWorktrees: "refuse"}}).Validate(); err == nil {
+		t.Error("Expected error for unknown defaults.nested_worktrees")
+	}
+
+	merged := MergeConfig(&Config{Defaults: Defaults{NestedWorktrees: NestedWorktreesError}},
+		&Config{Defaults: Defaults{NestedWorktrees: NestedWorktreesAllow}})
+	if merged.Defaults.NestedWorktrees != NestedWorktreesAllow {
+		t.Errorf("Expected override nested_worktrees 'allow', got '%s'", merged.Defaults.NestedWorktrees)
+	}
+}
+
+func TestConfig_VCS(t *testing.T) {
+	for _, name := range []string{"", VCSGit, VCSJujutsu} {
+		if err := (&Config{Defaults: Defaults{VCS: name}}).Validate(); err != nil {
+			t.Errorf("Expected vcs '%s' to be valid, got %v", name, err)
+		}
+	}
+	if err := (&Config{Defaults: Defaults{VCS: "sapling"}}).Validate(); err == nil {
+		t.Error("Expected error for unknown defaults.vcs")
+	}
+
+	merged := MergeConfig(&Config{Defaults: Defaults{VCS: VCSJujutsu}}, &Config{})
+	if merged.Defaults.VCS != VCSJujutsu {
+		t.Errorf("Expected unset override to keep vcs 'jj', got '%s'", merged.Defaults.VCS)
+	}
+}
+
+func TestConfig_ValidateAfterAdd(t *testing.T) {
+	for _, action := range []string{"", AfterAddPrintPath, AfterAddCopyPath, AfterAddCd, AfterAddOpenEditor} {
+		if err := (&Config{Defaults: Defaults{AfterAdd: action}}).Validate(); err != nil {
+			t.Errorf("Expected after_add '%s' to be valid, got %v", action, err)
+		}
+	}
+	if err := (&Config{Defaults: Defaults{AfterAdd: "open"}}).Validate(); err == nil {
+		t.Error("Expected error for unknown defaults.after_add")
+	}
+
+	merged := MergeConfig(&Config{Defaults: Defaults{AfterAdd: AfterAddCd}}, &Config{})
+	if merged.Defaults.AfterAdd != AfterAddCd {
+		t.Errorf("Expected unset override to keep after_add, got '%s'", merged.Defaults.AfterAdd)
+	}
+}
+
+func TestMergeConfig_Editor(t *testing.T) {
+	merged := MergeConfig(&Config{Defaults: Defaults{Editor: "vim"}}, &Config{})
+	if merged.Defaults.Editor != "vim" {
+		t.Errorf("Expected unset override to keep editor, got '%s'", merged.Defaults.Editor)
+	}
+	merged = MergeConfig(merged, &Config{Defaults: Defaults{Editor: "code --new-window"}})
+	if merged.Defaults.Editor != "code --new-window" {
+		t.Errorf("Expected override editor, got '%s'", merged.Defaults.Editor)
+	}
+}
+
+func TestConfig_ValidateSubmodules(t *testing.T) {
+	for _, mode := range []string{"", SubmodulesRecursive, SubmodulesTop, SubmodulesNone} {
+		if err := (&Config{Defaults: Defaults{Submodules: mode}}).Validate(); err != nil {
+			t.Errorf("Expected submodules '%s' to be valid, got %v", mode, err)
+		}
+	}
+	err := (&Config{Defaults: Defaults{Submodules: "all"}}).Validate()
+	if err == nil || !strings.Contains(err.Error(), "invalid defaults.submodules") {
+		t.Errorf("Expected error for unknown defaults.submodules, got %v", err)
+	}
+
+	merged := MergeConfig(&Config{Defaults: Defaults{Submodules: SubmodulesTop}}, &Config{})
+	if merged.Defaults.Submodules != SubmodulesTop {
+		t.Errorf("Expected unset override to keep submodules, got '%s'", merged.Defaults.Submodules)
+	}
+}
+
+func TestConfig_AutoTrack(t *testing.T) {
+	if !(&Config{}).AutoTrack() {
+		t.Error("Expected auto_track to default to true")
+	}
+
+	off := false
+	base := &Config{Defaults: Defaults{AutoTrack: &off}}
+	if base.AutoTrack() {
+		t.Error("Expected auto_track: false to turn tracking off")
+	}
+	if merged := MergeConfig(base, &Config{}); merged.AutoTrack() {
+		t.Error("Expected unset override to keep auto_track: false")
+	}
+
+	on := true
+	if merged := MergeConfig(base, &Config{Defaults: Defaults{AutoTrack: &on}}); !merged.AutoTrack() {
+		t.Error("Expected auto_track: true to override false")
+	}
+}
+
+func TestResolveWorktreePath_WorktreeDir(t *testing.T) {
+	cfg := &Config{Defaults: Defaults{
+		BaseDir:     "../worktrees",
+		WorktreeDir: "${DIRNAME}-${BRANCH_SLUG}",
+		Slug:        SlugPolicy{Lowercase: true},
+	}}
+
+	want := "/home/user/worktrees/myapp-feature-auth"
+	if got := cfg.ResolveWorktreePath("/home/user/myapp", "feature/Auth"); got != want {
+		t.Errorf("ResolveWorktreePath() = %s, want %s", got, want)
+	}
+	if got := cfg.ResolveWorktreePath("/home/user/myapp", ""); got != "/home/user/worktrees" {
+		t.Errorf("ResolveWorktreePath() with an empty name = %s, want the base_dir", got)
+	}
+}
+
+func TestConfig_ValidateWorktreeDir(t *testing.T) {
+	for _, template := range []string{"${BRANCH_SLUG}", "wt/${BRANCH}"} {
+		if err := (&Config{Defaults: Defaults{WorktreeDir: template}}).Validate(); err != nil {
+			t.Errorf("Expected worktree_dir '%s' to be valid, got %v", template, err)
+		}
+	}
+	for _, template := range []string{"/tmp/${BRANCH_SLUG}", "../${BRANCH_SLUG}", `a\..\b`} {
+		if err := (&Config{Defaults: Defaults{WorktreeDir: template}}).Validate(); err == nil {
+			t.Errorf("Expected error for worktree_dir '%s'", template)
+		}
+	}
+
+	merged := MergeConfig(&Config{Defaults: Defaults{WorktreeDir: "${BRANCH_SLUG}"}}, &Config{})
+	if merged.Defaults.WorktreeDir != "${BRANCH_SLUG}" {
+		t.Errorf("Expected unset override to keep worktree_dir, got '%s'", merged.Defaults.WorktreeDir)
+	}
+}
@@ -0,0 +1,31 @@
+package config
+
+import "fmt"
+
+// EnvFrom loads a command or script hook's secrets when it runs instead of storing them in
+// the configuration: from a dotenv file or from the output of a command such as a password
+// manager's CLI. Exactly one of File and Command is set.
+type EnvFrom struct {
+	// File is a dotenv file of NAME=value lines, relative to the main worktree.
+	File string `yaml:"file,omitempty"`
+	// Command is run in the main worktree with the hook's shell; its output is read as
+	// NAME=value lines.
+	Command string `yaml:"command,omitempty"`
+	// Name makes the trimmed output of Command the value of this one variable, for commands
+	// such as 'op read' that print a bare secret.
+	Name string `yaml:"name,omitempty"`
+}
+
+func (e *EnvFrom) validate() error {
+	switch {
+	case e.File == "" && e.Command == "":
+		return fmt.Errorf("requires 'file' or 'command'")
+	case e.File != "" && e.Command != "":
+		return fmt.Errorf("cannot set both 'file' and 'command'")
+	case e.Name != "" && e.Command == "":
+		return fmt.Errorf("'name' requires 'command'")
+	case e.Name != "" && !registerNamePattern.MatchString(e.Name):
+		return fmt.Errorf("'name' must be a variable name like API_TOKEN, got '%s'", e.Name)
+	}
+	return nil
+}
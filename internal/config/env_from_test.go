@@ -0,0 +1,60 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHookValidate_EnvFrom(t *testing.T) {
+	tests := []struct {
+		name    string
+		hook    Hook
+		wantErr string
+	}{
+		{
+			name: "file",
+			hook: Hook{Type: HookTypeCommand, Command: "make", EnvFrom: &EnvFrom{File: ".env.secrets"}},
+		},
+		{
+			name: "command with name",
+			hook: Hook{Type: HookTypeScript, Run: "make", EnvFrom: &EnvFrom{Command: "op read op://a/b/c", Name: "TOKEN"}},
+		},
+		{
+			name:    "neither file nor command",
+			hook:    Hook{Type: HookTypeCommand, Command: "make", EnvFrom: &EnvFrom{}},
+			wantErr: "invalid 'env_from': requires 'file' or 'command'",
+		},
+		{
+			name:    "both file and command",
+			hook:    Hook{Type: HookTypeCommand, Command: "make", EnvFrom: &EnvFrom{File: "a", Command: "b"}},
+			wantErr: "cannot set both",
+		},
+		{
+			name:    "name without command",
+			hook:    Hook{Type: HookTypeCommand, Command: "make", EnvFrom: &EnvFrom{File: "a", Name: "TOKEN"}},
+			wantErr: "'name' requires 'command'",
+		},
+		{
+			name:    "invalid name",
+			hook:    Hook{Type: HookTypeCommand, Command: "make", EnvFrom: &EnvFrom{Command: "b", Name: "my-token"}},
+			wantErr: "must be a variable name",
+		},
+		{
+			name:    "other hook type",
+			hook:    Hook{Type: HookTypeCopy, From: ".env", EnvFrom: &EnvFrom{File: "a"}},
+			wantErr: "copy hook should not have 'clear_env', 'shell', 'output', or 'env_from' fields",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.hook.Validate()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Validate() = %v, want no error", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
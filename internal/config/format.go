@@ -0,0 +1,104 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"go.yaml.in/yaml/v3"
+)
+
+// configExtensions lists the accepted config file extensions in lookup order; the first
+// one is the format 'wtp init' writes.
+var configExtensions = []string{".yml", ".json", ".toml"}
+
+// FindConfigFile returns the path of the config file named like name (ConfigFileName or
+// LocalConfigFileName) in dir, trying each supported extension. When none exists it
+// returns the path with name's own extension; when more than one exists it fails rather
+// than guess which is meant.
+func FindConfigFile(dir, name string) (string, error) {
+	stem := strings.TrimSuffix(name, filepath.Ext(name))
+
+	var found []string
+	for _, ext := range configExtensions {
+		path := filepath.Join(dir, stem+ext)
+		if _, err := os.Stat(path); err == nil {
+			found = append(found, path)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return filepath.Join(dir, name), nil
+	case 1:
+		return found[0], nil
+	default:
+		names := make([]string, len(found))
+		for i, path := range found {
+			names[i] = filepath.Base(path)
+		}
+		return "", fmt.Errorf("found %s in %s; keep only one of them", strings.Join(names, " and "), dir)
+	}
+}
+
+// IsYAMLConfigFile reports whether path is parsed as YAML, the only format wtp rewrites
+// in place.
+func IsYAMLConfigFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext != ".json" && ext != ".toml"
+}
+
+// decodeConfig parses data into cfg using the format implied by path's extension, after
+// migrating it to CurrentVersion (see Migrate). Keys that name no setting are kept in
+// cfg.unknownKeys. cfg.Version stays empty unless the file declares a version, so the
+// version a migration adds is not reported as set by the file.
+func decodeConfig(path string, data []byte, cfg *Config) error {
+	doc, err := ParseConfigDocument(path, data)
+	if err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 {
+		return nil // an empty file
+	}
+	declared := yamlMappingValue(doc.Content[0], "version") != nil
+	if _, err := Migrate(doc); err != nil {
+		return err
+	}
+	if err := doc.Decode(cfg); err != nil {
+		return err
+	}
+	if !declared {
+		cfg.Version = ""
+	}
+	cfg.unknownKeys = findUnknownKeys(path, doc)
+	return nil
+}
+
+// ParseConfigDocument parses data as a YAML node tree using the format implied by path's
+// extension. JSON and TOML documents are converted to YAML first so every format shares
+// the yaml field names and custom unmarshalers.
+func ParseConfigDocument(path string, data []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	var values map[string]any
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return &doc, yaml.Unmarshal(data, &doc)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	converted, err := yaml.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	return &doc, yaml.Unmarshal(converted, &doc)
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func useEmptyHome(t *testing.T) {
+	t.Helper()
+	original := userHomeDir
+	home := t.TempDir()
+	userHomeDir = func() (string, error) { return home, nil }
+	t.Cleanup(func() { userHomeDir = original })
+}
+
+func TestLoadConfig_TOMLAndJSONLayers(t *testing.T) {
+	useEmptyHome(t)
+	repoDir := t.TempDir()
+
+	files := map[string]string{
+		filepath.Join(repoDir, ".wtp.toml"): `version = "1.0"
+
+[defaults]
+base_dir = "../repo-wt"
+hook_concurrency = 4
+
+[[hooks.post_create]]
+type = "copy"
+from = ".env"
+
+[[hooks.post_create]]
+type = "command"
+command = "npm ci"
+timeout = "90s"
+env = { NODE_ENV = "development" }
+`,
+		filepath.Join(repoDir, ".wtp.local.json"): `{
+	"defaults": {"base_dir": "../my-wt"},
+	"hooks": {"post_create": [{"type": "command", "command": "echo local"}]}
+}`,
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	config, err := LoadConfig(repoDir, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if config.Defaults.BaseDir != "../my-wt" {
+		t.Errorf("Expected local base_dir '../my-wt', got %s", config.Defaults.BaseDir)
+	}
+	if config.Defaults.HookConcurrency != 4 {
+		t.Errorf("Expected hook_concurrency 4, got %d", config.Defaults.HookConcurrency)
+	}
+	if len(config.Hooks.PostCreate) != 3 {
+		t.Fatalf("Expected 3 post_create hooks, got %d", len(config.Hooks.PostCreate))
+	}
+	if config.Hooks.PostCreate[0].To != ".env" {
+		t.Errorf("Expected copy hook 'to' to default to '.env', got %q", config.Hooks.PostCreate[0].To)
+	}
+	command := config.Hooks.PostCreate[1]
+	if command.Timeout != "90s" || command.Env["NODE_ENV"] != "development" {
+		t.Errorf("Expected TOML command hook fields to be decoded, got %+v", command)
+	}
+	if config.Hooks.PostCreate[2].Command != "echo local" {
+		t.Errorf("Expected JSON local hook last, got %q", config.Hooks.PostCreate[2].Command)
+	}
+}
+
+func TestLoadConfig_AmbiguousConfigFiles(t *testing.T) {
+	useEmptyHome(t)
+	repoDir := t.TempDir()
+
+	for _, name := range []string{ConfigFileName, ".wtp.toml"} {
+		if err := os.WriteFile(filepath.Join(repoDir, name), []byte(""), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	_, err := LoadConfig(repoDir, "")
+	if err == nil || !strings.Contains(err.Error(), "found .wtp.yml and .wtp.toml") {
+		t.Errorf("Expected ambiguous config error, got %v", err)
+	}
+}
+
+func TestLoadConfig_InvalidJSON(t *testing.T) {
+	useEmptyHome(t)
+	repoDir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(repoDir, ".wtp.json"), []byte(`{"hooks": [`), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := LoadConfig(repoDir, "")
+	if err == nil || !strings.Contains(err.Error(), "failed to parse config file") {
+		t.Errorf("Expected parse error, got %v", err)
+	}
+}
+
+func TestFindConfigFile(t *testing.T) {
+	dir := t.TempDir()
+
+	path, err := FindConfigFile(dir, LocalConfigFileName)
+	if err != nil || path != filepath.Join(dir, LocalConfigFileName) {
+		t.Errorf("Expected default path when nothing exists, got %q, %v", path, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ".wtp.local.toml"), []byte(""), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	path, err = FindConfigFile(dir, LocalConfigFileName)
+	if err != nil || path != filepath.Join(dir, ".wtp.local.toml") {
+		t.Errorf("Expected .wtp.local.toml, got %q, %v", path, err)
+	}
+	if IsYAMLConfigFile(path) {
+		t.Errorf("Expected %s not to be treated as YAML", path)
+	}
+	if !IsYAMLConfigFile(filepath.Join(dir, ConfigFileName)) {
+		t.Errorf("Expected %s to be treated as YAML", ConfigFileName)
+	}
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	defaultRemote         = "origin"
+	fallbackDefaultBranch = "main"
+)
+
+// runGit runs git in dir and returns its trimmed output. It is a variable to allow mocking
+// in tests.
+var runGit = func(dir string, args ...string) (string, error) {
+	// #nosec G204 -- arguments are built by wtp
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	return strings.TrimSpace(string(output)), err
+}
+
+// gitVariables are the placeholders whose values are read from the repository. Each is only
+// resolved when the string uses it, so configurations without them never run git.
+var gitVariables = []struct {
+	placeholder string
+	resolve     func(repoRoot, branchName string) string
+}{
+	{"${COMMIT}", gitCommit},
+	{"${REMOTE}", gitRemote},
+	{"${DEFAULT_BRANCH}", gitDefaultBranch},
+	{"${REPO_NAME}", gitRepoName},
+}
+
+func expandGitVariables(s, repoRoot, branchName string) string {
+	for _, variable := range gitVariables {
+		if strings.Contains(s, variable.placeholder) {
+			s = strings.ReplaceAll(s, variable.placeholder, variable.resolve(repoRoot, branchName))
+		}
+	}
+	return s
+}
+
+// gitCommit returns the short SHA of the branch, or of HEAD when the branch does not exist
+// yet because 'wtp add' is about to create it.
+func gitCommit(repoRoot, branchName string) string {
+	if branchName != "" {
+		if sha, err := runGit(repoRoot, "rev-parse", "--short", "--verify", "--quiet",
+			"refs/heads/"+branchName+"^{commit}"); err == nil && sha != "" {
+			return sha
+		}
+	}
+	sha, _ := runGit(repoRoot, "rev-parse", "--short", "HEAD")
+	return sha
+}
+
+// gitRemote returns the remote the branch tracks, falling back to origin, then to the first
+// remote. It is empty for repositories without remotes.
+func gitRemote(repoRoot, branchName string) string {
+	if branchName != "" {
+		if remote, err := runGit(repoRoot, "config", "--get", "branch."+branchName+".remote"); err == nil &&
+			remote != "" && remote != "." {
+			return remote
+		}
+	}
+	output, err := runGit(repoRoot, "remote")
+	if err != nil || output == "" {
+		return ""
+	}
+	remotes := strings.Fields(output)
+	for _, remote := range remotes {
+		if remote == defaultRemote {
+			return remote
+		}
+	}
+	return remotes[0]
+}
+
+// gitDefaultBranch returns the branch the remote's HEAD points to, falling back to
+// init.defaultBranch and then to "main".
+func gitDefaultBranch(repoRoot, branchName string) string {
+	if remote := gitRemote(repoRoot, branchName); remote != "" {
+		if ref, err := runGit(repoRoot, "symbolic-ref", "--quiet", "--short",
+			"refs/remotes/"+remote+"/HEAD"); err == nil && ref != "" {
+			return strings.TrimPrefix(ref, remote+"/")
+		}
+	}
+	if branch, err := runGit(repoRoot, "config", "--get", "init.defaultBranch"); err == nil && branch != "" {
+		return branch
+	}
+	return fallbackDefaultBranch
+}
+
+// gitRepoName returns the repository name from the origin URL, e.g. "wtp" for
+// git@github.com:satococoa/wtp.git, falling back to the directory name of repoRoot.
+func gitRepoName(repoRoot, _ string) string {
+	if url, err := runGit(repoRoot, "remote", "get-url", defaultRemote); err == nil {
+		if name := RepoNameFromURL(url); name != "" {
+			return name
+		}
+	}
+	return filepath.Base(repoRoot)
+}
+
+// RepoNameFromURL extracts the last path segment of a git URL without its ".git" suffix.
+func RepoNameFromURL(url string) string {
+	url = strings.TrimSuffix(strings.TrimRight(url, "/"), ".git")
+	if i := strings.LastIndexAny(url, "/:\\"); i >= 0 {
+		url = url[i+1:]
+	}
+	return url
+}
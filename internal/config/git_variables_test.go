@@ -0,0 +1,101 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeGit answers git commands from a map keyed by the space-joined arguments and records
+// every call.
+func fakeGit(t *testing.T, answers map[string]string) *[]string {
+	t.Helper()
+	var calls []string
+	original := runGit
+	runGit = func(_ string, args ...string) (string, error) {
+		key := strings.Join(args, " ")
+		calls = append(calls, key)
+		if answer, ok := answers[key]; ok {
+			return answer, nil
+		}
+		return "", errors.New("exit status 1")
+	}
+	t.Cleanup(func() { runGit = original })
+	return &calls
+}
+
+func TestExpandVariables_GitVariables(t *testing.T) {
+	fakeGit(t, map[string]string{
+		"rev-parse --short --verify --quiet refs/heads/feature/auth^{commit}": "abc1234",
+		"rev-parse --short HEAD":                  "def5678",
+		"config --get branch.feature/auth.remote": "upstream",
+		"remote": "origin\nupstream",
+		"symbolic-ref --quiet --short refs/remotes/upstream/HEAD": "upstream/develop",
+		"symbolic-ref --quiet --short refs/remotes/origin/HEAD":   "origin/main",
+		"remote get-url origin":                                   "git@github.com:satococoa/wtp.git",
+	})
+
+	tests := []struct {
+		name     string
+		input    string
+		branch   string
+		expected string
+	}{
+		{name: "commit of the branch", input: "${COMMIT}", branch: "feature/auth", expected: "abc1234"},
+		{name: "commit of HEAD for a new branch", input: "${COMMIT}", branch: "feature/new", expected: "def5678"},
+		{name: "tracked remote", input: "${REMOTE}", branch: "feature/auth", expected: "upstream"},
+		{name: "origin without upstream", input: "${REMOTE}", branch: "feature/new", expected: "origin"},
+		{name: "default branch of tracked remote", input: "${DEFAULT_BRANCH}", branch: "feature/auth", expected: "develop"},
+		{name: "default branch of origin", input: "${DEFAULT_BRANCH}", branch: "feature/new", expected: "main"},
+		{
+			name:     "repo name from origin",
+			input:    "../worktrees/${REPO_NAME}-${COMMIT}",
+			branch:   "feature/auth",
+			expected: "../worktrees/wtp-abc1234",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExpandVariables(tt.input, "/home/user/myproject", tt.branch); got != tt.expected {
+				t.Errorf("ExpandVariables(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExpandVariables_GitVariablesAreLazy(t *testing.T) {
+	calls := fakeGit(t, nil)
+
+	ExpandVariables("../worktrees/${BRANCH_SLUG}", "/home/user/myproject", "feature/auth")
+
+	if len(*calls) != 0 {
+		t.Errorf("expected no git calls, got %v", *calls)
+	}
+}
+
+func TestExpandVariables_GitVariablesFallbacks(t *testing.T) {
+	fakeGit(t, map[string]string{"config --get init.defaultBranch": "trunk"})
+
+	got := ExpandVariables("${REMOTE}|${DEFAULT_BRANCH}|${REPO_NAME}", "/home/user/myproject", "feature/auth")
+
+	if got != "|trunk|myproject" {
+		t.Errorf("ExpandVariables() = %q, want %q", got, "|trunk|myproject")
+	}
+}
+
+func TestRepoNameFromURL(t *testing.T) {
+	tests := map[string]string{
+		"git@github.com:satococoa/wtp.git":      "wtp",
+		"https://github.com/satococoa/wtp.git/": "wtp",
+		"https://example.com/scm/team/app":      "app",
+		"/srv/git/project.git":                  "project",
+		`C:\repos\tool.git`:                     "tool",
+	}
+
+	for url, expected := range tests {
+		if got := RepoNameFromURL(url); got != expected {
+			t.Errorf("RepoNameFromURL(%q) = %q, want %q", url, got, expected)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// HasHibernatePatterns reports whether the 'hibernate' section lists any paths.
+func (c *Config) HasHibernatePatterns() bool {
+	return len(c.Hibernate) > 0
+}
+
+// validateHibernatePattern checks one 'hibernate' entry: a glob relative to the worktree
+// that cannot reach outside it.
+func validateHibernatePattern(pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return fmt.Errorf("pattern must not be empty")
+	}
+	if filepath.IsAbs(pattern) {
+		return fmt.Errorf("'%s' must be relative to the worktree", pattern)
+	}
+	cleaned := filepath.Clean(pattern)
+	if cleaned == "." || slices.Contains(strings.Split(filepath.ToSlash(cleaned), "/"), "..") {
+		return fmt.Errorf("'%s' must name paths inside the worktree", pattern)
+	}
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return fmt.Errorf("'%s' is not a valid glob: %w", pattern, err)
+	}
+	return nil
+}
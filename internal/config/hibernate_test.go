@@ -0,0 +1,80 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateHibernatePattern(t *testing.T) {
+	tests := []struct {
+		pattern     string
+		expectedErr string
+	}{
+		{pattern: "node_modules"},
+		{pattern: "packages/*/node_modules"},
+		{pattern: ".next/cache"},
+		{pattern: "", expectedErr: "must not be empty"},
+		{pattern: "/tmp/cache", expectedErr: "must be relative"},
+		{pattern: "../shared", expectedErr: "inside the worktree"},
+		{pattern: ".", expectedErr: "inside the worktree"},
+		{pattern: "build/[", expectedErr: "not a valid glob"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			err := validateHibernatePattern(tt.pattern)
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_HibernatePatterns(t *testing.T) {
+	repoDir := t.TempDir()
+	original := userHomeDir
+	userHomeDir = func() (string, error) { return t.TempDir(), nil }
+	t.Cleanup(func() { userHomeDir = original })
+
+	repoContent := "hibernate:\n  - node_modules\n"
+	localContent := "hibernate:\n  - .venv\n"
+	if err := os.WriteFile(filepath.Join(repoDir, ConfigFileName), []byte(repoContent), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, LocalConfigFileName), []byte(localContent), 0o644); err != nil {
+		t.Fatalf("Failed to write local config: %v", err)
+	}
+
+	config, err := LoadConfig(repoDir, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !config.HasHibernatePatterns() || strings.Join(config.Hibernate, ",") != "node_modules,.venv" {
+		t.Errorf("Unexpected hibernate patterns: %v", config.Hibernate)
+	}
+}
+
+func TestLoadConfig_InvalidHibernatePattern(t *testing.T) {
+	repoDir := t.TempDir()
+	original := userHomeDir
+	userHomeDir = func() (string, error) { return t.TempDir(), nil }
+	t.Cleanup(func() { userHomeDir = original })
+
+	content := "hibernate:\n  - node_modules\n  - ../elsewhere\n"
+	if err := os.WriteFile(filepath.Join(repoDir, ConfigFileName), []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := LoadConfig(repoDir, "")
+	if err == nil || !strings.Contains(err.Error(), "invalid hibernate pattern 2") {
+		t.Errorf("Expected invalid hibernate pattern error, got %v", err)
+	}
+}
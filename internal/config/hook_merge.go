@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+const (
+	// HookMergeAppend adds the hook after the hooks of earlier layers, which is the
+	// default. An earlier hook with the same id is dropped.
+	HookMergeAppend = "append"
+	// HookMergePrepend adds the hook before the hooks of earlier layers. An earlier hook
+	// with the same id is dropped.
+	HookMergePrepend = "prepend"
+	// HookMergeReplace puts the hook in the place of the earlier hook with the same id,
+	// or appends it when there is none.
+	HookMergeReplace = "replace"
+	// HookMergeDisable removes the earlier hook with the same id; the hook itself only
+	// names the id and never runs.
+	HookMergeDisable = "disable"
+)
+
+// mergeHookLists returns base with the hooks of override merged in, one by one, according
+// to their 'merge' policy; by default they are appended. base is left untouched.
+func mergeHookLists(base, override []Hook) []Hook {
+	if len(override) == 0 {
+		return base
+	}
+	merged := make([]Hook, 0, len(base)+len(override))
+	merged = append(merged, base...)
+	prepended := 0 // prepended hooks keep their order
+	for i := range override {
+		hook := &override[i]
+		if index := hookIndex(merged, hook.ID); index >= 0 {
+			if hook.Merge == HookMergeReplace {
+				merged[index] = *hook
+				continue
+			}
+			merged = slices.Delete(merged, index, index+1)
+			if index < prepended {
+				prepended--
+			}
+		}
+		switch hook.Merge {
+		case HookMergeDisable:
+		case HookMergePrepend:
+			merged = slices.Insert(merged, prepended, *hook)
+			prepended++
+		default:
+			merged = append(merged, *hook)
+		}
+	}
+	return merged
+}
+
+// hookIndex returns the index of the hook with id in hooks, or -1. Hooks without an id
+// never match.
+func hookIndex(hooks []Hook, id string) int {
+	if id == "" {
+		return -1
+	}
+	return slices.IndexFunc(hooks, func(h Hook) bool { return h.ID == id })
+}
+
+// validateMerge checks the 'merge' policy and that the policies acting on an earlier
+// hook name one with 'id'.
+func (h *Hook) validateMerge() error {
+	switch h.Merge {
+	case "", HookMergeAppend, HookMergePrepend:
+		return nil
+	case HookMergeReplace, HookMergeDisable:
+		if h.ID == "" {
+			return fmt.Errorf("hook with 'merge: %s' needs an 'id' naming the hook it acts on", h.Merge)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid 'merge' policy '%s', must be '%s', '%s', '%s', or '%s'", h.Merge,
+			HookMergeAppend, HookMergePrepend, HookMergeReplace, HookMergeDisable)
+	}
+}
+
+// validateDisable checks a hook with 'merge: disable', which takes nothing but its id.
+func (h *Hook) validateDisable() error {
+	if err := h.validateMerge(); err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(*h, Hook{ID: h.ID, Merge: h.Merge, Source: h.Source}) {
+		return fmt.Errorf("hook '%s' with 'merge: disable' only takes 'id'", h.ID)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMergeHookLists_Policies(t *testing.T) {
+	base := []Hook{
+		{ID: "env", Type: HookTypeCopy, From: ".env"},
+		{Type: HookTypeCommand, Command: "make"},
+		{ID: "install", Type: HookTypeCommand, Command: "npm install"},
+	}
+
+	tests := []struct {
+		name     string
+		override []Hook
+		want     []string
+	}{
+		{
+			name:     "append by default",
+			override: []Hook{{Type: HookTypeCommand, Command: "echo done"}},
+			want:     []string{".env", "make", "npm install", "echo done"},
+		},
+		{
+			name:     "same id moves the hook to the end",
+			override: []Hook{{ID: "env", Type: HookTypeCopy, From: ".env.local"}},
+			want:     []string{"make", "npm install", ".env.local"},
+		},
+		{
+			name: "prepend keeps its order",
+			override: []Hook{
+				{Merge: HookMergePrepend, Type: HookTypeCommand, Command: "first"},
+				{Merge: HookMergePrepend, Type: HookTypeCommand, Command: "second"},
+			},
+			want: []string{"first", "second", ".env", "make", "npm install"},
+		},
+		{
+			name:     "replace keeps the position",
+			override: []Hook{{ID: "install", Merge: HookMergeReplace, Type: HookTypeCommand, Command: "pnpm install"}},
+			want:     []string{".env", "make", "pnpm install"},
+		},
+		{
+			name:     "replace without a match appends",
+			override: []Hook{{ID: "lint", Merge: HookMergeReplace, Type: HookTypeCommand, Command: "lint"}},
+			want:     []string{".env", "make", "npm install", "lint"},
+		},
+		{
+			name:     "disable removes the hook",
+			override: []Hook{{ID: "env", Merge: HookMergeDisable}, {ID: "missing", Merge: HookMergeDisable}},
+			want:     []string{"make", "npm install"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged := mergeHookLists(base, tt.override)
+			var got []string
+			for i := range merged {
+				got = append(got, merged[i].From+merged[i].Command)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("merged hooks = %v, want %v", got, tt.want)
+			}
+			if len(base) != 3 || base[0].From != ".env" || base[2].Command != "npm install" {
+				t.Errorf("base was modified: %+v", base)
+			}
+		})
+	}
+}
+
+func TestHookValidate_Merge(t *testing.T) {
+	tests := []struct {
+		name    string
+		hook    Hook
+		wantErr string
+	}{
+		{"disable with id", Hook{ID: "env", Merge: HookMergeDisable}, ""},
+		{"disable without id", Hook{Merge: HookMergeDisable}, "needs an 'id'"},
+		{"disable with other fields", Hook{ID: "env", Merge: HookMergeDisable, Type: HookTypeCopy}, "only takes 'id'"},
+		{"replace without id", Hook{Merge: HookMergeReplace, Type: HookTypeCommand, Command: "make"}, "needs an 'id'"},
+		{"prepend", Hook{Merge: HookMergePrepend, Type: HookTypeCommand, Command: "make"}, ""},
+		{"unknown policy", Hook{Merge: "override", Type: HookTypeCommand, Command: "make"}, "invalid 'merge' policy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.hook.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want no error", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_HookMergeAcrossLayers(t *testing.T) {
+	repoDir := t.TempDir()
+	homeDir := t.TempDir()
+	original := userHomeDir
+	userHomeDir = func() (string, error) { return homeDir, nil }
+	t.Cleanup(func() { userHomeDir = original })
+
+	files := map[string]string{
+		filepath.Join(homeDir, ConfigFileName): `hooks:
+  post_create:
+    - id: editor
+      type: copy
+      from: .vscode/settings.json
+    - id: install
+      type: command
+      command: npm install
+`,
+		filepath.Join(repoDir, ConfigFileName): `hooks:
+  post_create:
+    - id: install
+      merge: replace
+      type: command
+      command: pnpm install
+    - type: command
+      command: make db
+profiles:
+  lite:
+    hooks:
+      post_create:
+        - id: install
+          merge: disable
+`,
+		filepath.Join(repoDir, LocalConfigFileName): `hooks:
+  post_create:
+    - id: editor
+      merge: disable
+`,
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	cfg, err := LoadConfig(repoDir, "")
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	hooks := cfg.Hooks.PostCreate
+	if len(hooks) != 2 || hooks[0].Command != "pnpm install" || hooks[1].Command != "make db" {
+		t.Fatalf("post_create hooks = %+v, want pnpm install and make db", hooks)
+	}
+	if hooks[0].Source != ".wtp.yml" {
+		t.Errorf("replacing hook source = %q, want .wtp.yml", hooks[0].Source)
+	}
+	if got, _ := cfg.Origin("hooks.post_create.1.command"); got != ".wtp.yml" {
+		t.Errorf("Origin of the second hook = %q, want .wtp.yml", got)
+	}
+
+	lite, _ := cfg.ForProfile("lite")
+	if len(lite.Hooks.PostCreate) != 1 || lite.Hooks.PostCreate[0].Command != "make db" {
+		t.Errorf("post_create hooks with the lite profile = %+v, want only make db", lite.Hooks.PostCreate)
+	}
+}
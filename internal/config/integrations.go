@@ -0,0 +1,108 @@
+package config
+
+import "fmt"
+
+// Modes of 'wtp tmux'
+const (
+	TmuxModeSession = "session"
+	TmuxModeWindow  = "window"
+)
+
+// DefaultTmuxName is the name 'wtp tmux' gives sessions and windows when
+// integrations.tmux.name is unset.
+const DefaultTmuxName = "${BRANCH_SLUG}"
+
+// DefaultVSCodeCommand is the command 'wtp code' runs VS Code with when
+// integrations.vscode.command is unset.
+const DefaultVSCodeCommand = "code"
+
+// DefaultVSCodeWorkspaceFile is where 'wtp code --workspace' writes the workspace file,
+// relative to the main worktree, when integrations.vscode.workspace_file is unset.
+const DefaultVSCodeWorkspaceFile = "../${DIRNAME}.code-workspace"
+
+// Integrations configures the commands that hand worktrees to other tools.
+type Integrations struct {
+	// Tmux configures 'wtp tmux'.
+	Tmux Tmux `yaml:"tmux,omitempty"`
+	// VSCode configures 'wtp code'.
+	VSCode VSCode `yaml:"vscode,omitempty"`
+}
+
+// VSCode configures how 'wtp code' runs VS Code and where it keeps the multi-root
+// workspace of all worktrees.
+type VSCode struct {
+	// Command runs VS Code with a worktree or workspace file appended, such as
+	// "code-insiders" or "cursor"; empty means DefaultVSCodeCommand.
+	Command string `yaml:"command,omitempty"`
+	// WorkspaceFile is the .code-workspace file 'wtp code --workspace' generates; a
+	// relative path is relative to the main worktree. Empty means DefaultVSCodeWorkspaceFile.
+	WorkspaceFile string `yaml:"workspace_file,omitempty"`
+}
+
+// EditorCommand returns the command VS Code runs with.
+func (v *VSCode) EditorCommand() string {
+	if v.Command == "" {
+		return DefaultVSCodeCommand
+	}
+	return v.Command
+}
+
+// WorkspaceFileTemplate returns the template of the workspace file path.
+func (v *VSCode) WorkspaceFileTemplate() string {
+	if v.WorkspaceFile == "" {
+		return DefaultVSCodeWorkspaceFile
+	}
+	return v.WorkspaceFile
+}
+
+// Tmux configures the tmux sessions, or windows, 'wtp tmux' opens worktrees in.
+type Tmux struct {
+	// Name is the template of the session or window name, such as "${DIRNAME}/${BRANCH_SLUG}";
+	// empty means DefaultTmuxName.
+	Name string `yaml:"name,omitempty"`
+	// Mode is "session" (the default) for a session per worktree, or "window" for a window
+	// per worktree in the current session.
+	Mode string `yaml:"mode,omitempty"`
+	// Layout lists the tmux commands run in a session or window once it is created, such as
+	// "split-window -h" or "send-keys 'npm run dev' Enter". Later layers replace the list.
+	Layout []string `yaml:"layout,omitempty"`
+}
+
+// NameTemplate returns the template of the session or window name.
+func (t *Tmux) NameTemplate() string {
+	if t.Name == "" {
+		return DefaultTmuxName
+	}
+	return t.Name
+}
+
+func (t *Tmux) validate() error {
+	switch t.Mode {
+	case "", TmuxModeSession, TmuxModeWindow:
+		return nil
+	default:
+		return fmt.Errorf("invalid integrations.tmux.mode '%s': must be '%s' or '%s'",
+			t.Mode, TmuxModeSession, TmuxModeWindow)
+	}
+}
+
+// mergeIntegrations applies the fields override sets on top of base.
+func mergeIntegrations(base, override *Integrations) Integrations {
+	result := *base
+	if override.Tmux.Name != "" {
+		result.Tmux.Name = override.Tmux.Name
+	}
+	if override.Tmux.Mode != "" {
+		result.Tmux.Mode = override.Tmux.Mode
+	}
+	if len(override.Tmux.Layout) > 0 {
+		result.Tmux.Layout = override.Tmux.Layout
+	}
+	if override.VSCode.Command != "" {
+		result.VSCode.Command = override.VSCode.Command
+	}
+	if override.VSCode.WorkspaceFile != "" {
+		result.VSCode.WorkspaceFile = override.VSCode.WorkspaceFile
+	}
+	return result
+}
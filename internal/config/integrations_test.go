@@ -0,0 +1,42 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConfig_Integrations(t *testing.T) {
+	if got := (&Tmux{}).NameTemplate(); got != DefaultTmuxName {
+		t.Errorf("NameTemplate() = %q, want %q", got, DefaultTmuxName)
+	}
+
+	base := &Config{Integrations: Integrations{Tmux: Tmux{
+		Name:   "${DIRNAME}/${BRANCH_SLUG}",
+		Layout: []string{"split-window -h", "send-keys 'npm run dev' Enter"},
+	}}}
+	merged := MergeConfig(base, &Config{Integrations: Integrations{Tmux: Tmux{
+		Mode:   TmuxModeWindow,
+		Layout: []string{"split-window -v"},
+	}}})
+	tmux := merged.Integrations.Tmux
+	if tmux.Name != "${DIRNAME}/${BRANCH_SLUG}" || tmux.Mode != TmuxModeWindow {
+		t.Errorf("merged tmux = %+v, want the base name and the override mode", tmux)
+	}
+	if !reflect.DeepEqual(tmux.Layout, []string{"split-window -v"}) {
+		t.Errorf("merged layout = %v, want the override layout only", tmux.Layout)
+	}
+
+	vscode := MergeConfig(&Config{Integrations: Integrations{VSCode: VSCode{Command: "cursor"}}},
+		&Config{Integrations: Integrations{VSCode: VSCode{WorkspaceFile: "all.code-workspace"}}}).Integrations.VSCode
+	if vscode.EditorCommand() != "cursor" || vscode.WorkspaceFileTemplate() != "all.code-workspace" {
+		t.Errorf("merged vscode = %+v, want the base command and the override workspace file", vscode)
+	}
+	if (&VSCode{}).EditorCommand() != DefaultVSCodeCommand {
+		t.Errorf("EditorCommand() of an empty section = %q", (&VSCode{}).EditorCommand())
+	}
+
+	cfg := &Config{Defaults: Defaults{BaseDir: DefaultBaseDir}, Integrations: Integrations{Tmux: Tmux{Mode: "pane"}}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject integrations.tmux.mode 'pane'")
+	}
+}
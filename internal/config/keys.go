@@ -0,0 +1,253 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Setting is one value of a configuration file flattened to a dotted key, such as
+// defaults.base_dir or hooks.post_create.0.command.
+type Setting struct {
+	Key   string
+	Value string
+}
+
+// ErrUnknownKey is returned for a dotted key that names no setting of Config.
+var ErrUnknownKey = errors.New("unknown key")
+
+// settingType resolves a dotted key against the fields of Config by their yaml names and
+// returns the Go type it names. Entries of a map, such as defaults.env.NODE_ENV, are keys
+// too; list items and branch overlays are not addressable.
+func settingType(key string) (reflect.Type, error) {
+	if key == "" {
+		return nil, fmt.Errorf("empty key")
+	}
+	t := reflect.TypeOf(Config{})
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		if part == "" {
+			return nil, fmt.Errorf("invalid key '%s'", key)
+		}
+		switch {
+		case t == reflect.TypeOf(BranchOverlays{}):
+			return nil, fmt.Errorf("'%s' is a branch overlay; edit the configuration file instead",
+				strings.Join(parts[:i], "."))
+		case t.Kind() == reflect.Struct:
+			field, ok := yamlField(t, part)
+			if !ok {
+				return nil, fmt.Errorf("%w '%s'", ErrUnknownKey, strings.Join(parts[:i+1], "."))
+			}
+			t = field.Type
+		case t.Kind() == reflect.Map:
+			t = t.Elem()
+		case t.Kind() == reflect.Slice:
+			return nil, fmt.Errorf("'%s' is a list; set or unset it as a whole", strings.Join(parts[:i], "."))
+		default:
+			return nil, fmt.Errorf("%w '%s'", ErrUnknownKey, strings.Join(parts[:i+1], "."))
+		}
+	}
+	return t, nil
+}
+
+// yamlField finds the field of struct type t whose yaml name is name.
+func yamlField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if tag == name && field.IsExported() {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// ValidateKey checks that key names a setting, or a section of settings, of Config.
+func ValidateKey(key string) error {
+	_, err := settingType(key)
+	return err
+}
+
+// settingNode converts value to the YAML node stored for a setting of type t. Lists of
+// strings are given comma-separated; other lists and sections are edited by hand.
+func settingNode(key string, t reflect.Type, value string) (*yaml.Node, error) {
+	scalar := func(tag, value string) *yaml.Node {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+	}
+	// A pointer tells an unset setting from its zero value, e.g. defaults.auto_track
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return scalar("!!str", value), nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false, got '%s'", key, value)
+		}
+		return scalar("!!bool", strconv.FormatBool(b)), nil
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a whole number, got '%s'", key, value)
+		}
+		return scalar("!!int", strconv.Itoa(n)), nil
+	case reflect.Slice:
+		if t.Elem().Kind() != reflect.String {
+			break
+		}
+		list := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list.Content = append(list.Content, scalar("!!str", item))
+			}
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("'%s' is a section; edit the configuration file to change it", key)
+}
+
+// SetKey sets key to value in a configuration document parsed into a YAML node, creating
+// the document and any sections on the way. The value is converted to the setting's type,
+// so defaults.readonly accepts "true" but not "yes please".
+func SetKey(doc *yaml.Node, key, value string) error {
+	t, err := settingType(key)
+	if err != nil {
+		return err
+	}
+	valueNode, err := settingNode(key, t, value)
+	if err != nil {
+		return err
+	}
+
+	if doc.Kind == 0 {
+		doc.Kind = yaml.DocumentNode
+	}
+	if len(doc.Content) == 0 {
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	node := doc.Content[0]
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		if node.Kind != yaml.MappingNode {
+			return fmt.Errorf("cannot set %s: '%s' is not a section", key, strings.Join(parts[:i], "."))
+		}
+		existing := yamlMappingValue(node, part)
+		if i == len(parts)-1 {
+			if existing != nil {
+				valueNode.LineComment = existing.LineComment
+				*existing = *valueNode
+			} else {
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: part}, valueNode)
+			}
+			break
+		}
+		if existing == nil {
+			existing = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: part}, existing)
+		}
+		node = existing
+	}
+	return nil
+}
+
+// UnsetKey removes key, which may be a whole section, from a configuration document and
+// drops the sections left empty. It reports whether the key was set.
+func UnsetKey(doc *yaml.Node, key string) (bool, error) {
+	if err := ValidateKey(key); err != nil {
+		return false, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return false, nil
+	}
+	return unsetKey(doc.Content[0], strings.Split(key, ".")), nil
+}
+
+func unsetKey(node *yaml.Node, parts []string) bool {
+	if node.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != parts[0] {
+			continue
+		}
+		value := node.Content[i+1]
+		if len(parts) > 1 {
+			if !unsetKey(value, parts[1:]) {
+				return false
+			}
+			if len(value.Content) > 0 {
+				return true
+			}
+		}
+		node.Content = append(node.Content[:i], node.Content[i+2:]...)
+		return true
+	}
+	return false
+}
+
+// LookupKey returns the node key names in a document or node tree, or nil when it is not
+// set. Besides the keys SetKey accepts, it follows list indexes, as in
+// hooks.post_create.0.command.
+func LookupKey(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil
+		}
+		node = node.Content[0]
+	}
+	for _, part := range strings.Split(key, ".") {
+		switch node.Kind {
+		case yaml.MappingNode:
+			node = yamlMappingValue(node, part)
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(node.Content) {
+				return nil
+			}
+			node = node.Content[i]
+		default:
+			return nil
+		}
+		if node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// FlattenSettings lists every value set in a document or node tree as a dotted key, in
+// document order. List items are numbered from 0.
+func FlattenSettings(node *yaml.Node) []Setting {
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil
+		}
+		node = node.Content[0]
+	}
+	var settings []Setting
+	flattenSettings(node, "", &settings)
+	return settings
+}
+
+func flattenSettings(node *yaml.Node, prefix string, settings *[]Setting) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			flattenSettings(node.Content[i+1], joinKey(prefix, node.Content[i].Value), settings)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			flattenSettings(item, joinKey(prefix, strconv.Itoa(i)), settings)
+		}
+	case yaml.AliasNode:
+		flattenSettings(node.Alias, prefix, settings)
+	case yaml.ScalarNode:
+		*settings = append(*settings, Setting{Key: prefix, Value: node.Value})
+	}
+}
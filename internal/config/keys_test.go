@@ -0,0 +1,159 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+)
+
+func TestValidateKey(t *testing.T) {
+	valid := []string{"version", "defaults", "defaults.base_dir", "defaults.env.NODE_ENV",
+		"defaults.slug.max_length", "hooks.post_create", "policy.required_branch_prefixes", "map.git_config"}
+	for _, key := range valid {
+		if err := ValidateKey(key); err != nil {
+			t.Errorf("ValidateKey(%q) = %v, want nil", key, err)
+		}
+	}
+
+	invalid := map[string]string{
+		"defaults.basedir":            "unknown key 'defaults.basedir'",
+		"defaults.base_dir.x":         "unknown key 'defaults.base_dir.x'",
+		"hooks.post_create.0.command": "'hooks.post_create' is a list",
+		"branches.main.defaults":      "'branches' is a branch overlay",
+		"defaults..base_dir":          "invalid key",
+	}
+	for key, want := range invalid {
+		err := ValidateKey(key)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ValidateKey(%q) = %v, want an error containing %q", key, err, want)
+		}
+	}
+	if err := ValidateKey("nope"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("ValidateKey(nope) = %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestSetKey(t *testing.T) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte("defaults:\n  base_dir: ../wt # sibling\n"), &doc); err != nil {
+		t.Fatal(err)
+	}
+	for _, kv := range [][2]string{
+		{"defaults.base_dir", "../worktrees"},
+		{"defaults.readonly", "true"},
+		{"defaults.hook_concurrency", "4"},
+		{"defaults.auto_track", "false"},
+		{"defaults.env.PORT", "3001"},
+		{"policy.required_branch_prefixes", "feature/, fix/,"},
+	} {
+		if err := SetKey(&doc, kv[0], kv[1]); err != nil {
+			t.Fatalf("SetKey(%s) failed: %v", kv[0], err)
+		}
+	}
+
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "defaults:\n    base_dir: ../worktrees # sibling\n    readonly: true\n    hook_concurrency: 4\n" +
+		"    auto_track: false\n    env:\n        PORT: \"3001\"\n" +
+		"policy:\n    required_branch_prefixes:\n        - feature/\n        - fix/\n"
+	if string(out) != want {
+		t.Errorf("SetKey produced:\n%s\nwant:\n%s", out, want)
+	}
+
+	var cfg Config
+	if err := doc.Decode(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Defaults.ReadOnly || cfg.Defaults.HookConcurrency != 4 || cfg.Defaults.Env["PORT"] != "3001" ||
+		cfg.AutoTrack() {
+		t.Errorf("decoded config = %+v", cfg.Defaults)
+	}
+
+	for key, value := range map[string]string{
+		"defaults.readonly":         "yes please",
+		"defaults.hook_concurrency": "four",
+		"defaults.slug":             "x",
+		"defaults.base_dir.x":       "x",
+	} {
+		if err := SetKey(&doc, key, value); err == nil {
+			t.Errorf("SetKey(%s, %s) succeeded, want an error", key, value)
+		}
+	}
+}
+
+func TestSetKey_EmptyDocument(t *testing.T) {
+	var doc yaml.Node
+	if err := SetKey(&doc, "map.file", ".wtp/map.json"); err != nil {
+		t.Fatal(err)
+	}
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "map:\n    file: .wtp/map.json\n" {
+		t.Errorf("SetKey produced %q", out)
+	}
+}
+
+func TestUnsetKey(t *testing.T) {
+	var doc yaml.Node
+	data := "version: \"1.0\"\ndefaults:\n  env:\n    PORT: \"3001\"\nhooks:\n  post_create:\n    - type: copy\n"
+	if err := yaml.Unmarshal([]byte(data), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"defaults.env.PORT", "hooks.post_create"} {
+		if removed, err := UnsetKey(&doc, key); err != nil || !removed {
+			t.Errorf("UnsetKey(%s) = %v, %v, want true", key, removed, err)
+		}
+	}
+	if removed, err := UnsetKey(&doc, "defaults.base_dir"); err != nil || removed {
+		t.Errorf("UnsetKey of a missing key = %v, %v, want false", removed, err)
+	}
+	if _, err := UnsetKey(&doc, "defaults.nope"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("UnsetKey of an unknown key = %v, want ErrUnknownKey", err)
+	}
+
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "version: \"1.0\"\n" {
+		t.Errorf("empty sections should be dropped, got %q", out)
+	}
+}
+
+func TestLookupKeyAndFlattenSettings(t *testing.T) {
+	var doc yaml.Node
+	data := "defaults:\n  base_dir: ../wt\nhooks:\n  post_create:\n    - type: command\n      command: make\n"
+	if err := yaml.Unmarshal([]byte(data), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if node := LookupKey(&doc, "hooks.post_create.0.command"); node == nil || node.Value != "make" {
+		t.Errorf("LookupKey(hooks.post_create.0.command) = %v", node)
+	}
+	if node := LookupKey(&doc, "hooks.post_create.1"); node != nil {
+		t.Errorf("LookupKey past the end of a list = %v, want nil", node)
+	}
+	if node := LookupKey(&doc, "defaults.base_dir.x"); node != nil {
+		t.Errorf("LookupKey below a scalar = %v, want nil", node)
+	}
+
+	want := []Setting{
+		{Key: "defaults.base_dir", Value: "../wt"},
+		{Key: "hooks.post_create.0.type", Value: "command"},
+		{Key: "hooks.post_create.0.command", Value: "make"},
+	}
+	if got := FlattenSettings(&doc); !reflect.DeepEqual(got, want) {
+		t.Errorf("FlattenSettings() = %v, want %v", got, want)
+	}
+	if got := FlattenSettings(&yaml.Node{}); got != nil {
+		t.Errorf("FlattenSettings of an empty document = %v, want nil", got)
+	}
+}
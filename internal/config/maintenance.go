@@ -0,0 +1,27 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaintenanceInterval returns defaults.maintenance_interval. Zero means maintenance is
+// always due.
+func (c *Config) MaintenanceInterval() time.Duration {
+	d, _ := parseMaintenanceInterval(c.Defaults.MaintenanceInterval) // validated when loaded
+	return d
+}
+
+func parseMaintenanceInterval(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("interval must be positive, got %s", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// oldestVersion is the version assumed for a config file without 'version'.
+const oldestVersion = "1.0"
+
+// migration upgrades a configuration document written for version from to version to.
+// apply edits the document's top-level mapping in place and describes each change.
+type migration struct {
+	from  string
+	to    string
+	apply func(root *yaml.Node) []string
+}
+
+// migrations are applied in order to files declaring an older version than
+// CurrentVersion; each one's 'to' is the next one's 'from'. A migration is added here,
+// together with a new CurrentVersion, when a setting is renamed or moved.
+var migrations []migration
+
+// MigrationResult describes how Migrate changed a configuration document.
+type MigrationResult struct {
+	// From is the version the document declared, or the oldest one when it declared none.
+	From string
+	// Changes describes each field that was moved or renamed.
+	Changes []string
+	// to is the version the document was migrated to.
+	to string
+}
+
+// Migrated reports whether the document was written for an older version.
+func (r *MigrationResult) Migrated() bool {
+	return r.From != r.to
+}
+
+// Migrate upgrades a configuration document, as parsed by yaml.Unmarshal into a node, to
+// CurrentVersion and sets its 'version' accordingly. A document declaring a newer version
+// than this wtp knows is an error, since its fields may mean something else.
+func Migrate(doc *yaml.Node) (*MigrationResult, error) {
+	return migrate(doc, CurrentVersion, migrations)
+}
+
+// migrate upgrades doc to version current by applying steps, as Migrate does.
+func migrate(doc *yaml.Node, current string, steps []migration) (*MigrationResult, error) {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return &MigrationResult{From: current, to: current}, nil
+	}
+	root := doc.Content[0]
+
+	version := oldestVersion
+	if node := yamlMappingValue(root, "version"); node != nil && node.Value != "" {
+		version = node.Value
+	}
+	from, err := parseConfigVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	target, _ := parseConfigVersion(current)
+	if compareConfigVersions(from, target) > 0 {
+		return nil, fmt.Errorf("config version %s is newer than this wtp supports (%s); upgrade wtp",
+			version, current)
+	}
+
+	result := &MigrationResult{From: formatConfigVersion(from), to: formatConfigVersion(target)}
+	for _, m := range steps {
+		if step, _ := parseConfigVersion(m.from); compareConfigVersions(step, from) >= 0 {
+			result.Changes = append(result.Changes, m.apply(root)...)
+		}
+	}
+	if result.Migrated() {
+		setVersion(root, result.to)
+	}
+	return result, nil
+}
+
+// renameYAMLKey renames key from to key to in a mapping node, for migrations that rename
+// a setting. When to is already set, from is dropped instead. It reports whether the
+// mapping changed.
+func renameYAMLKey(node *yaml.Node, from, to string) bool {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != from {
+			continue
+		}
+		if yamlMappingValue(node, to) != nil {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+		} else {
+			node.Content[i].Value = to
+		}
+		return true
+	}
+	return false
+}
+
+func yamlMappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setVersion sets 'version' in the top-level mapping, adding it first when missing.
+func setVersion(root *yaml.Node, version string) {
+	if node := yamlMappingValue(root, "version"); node != nil {
+		node.Value, node.Tag = version, "!!str"
+		return
+	}
+	root.Content = append([]*yaml.Node{
+		{Kind: yaml.ScalarNode, Value: "version"},
+		{Kind: yaml.ScalarNode, Tag: "!!str", Style: yaml.DoubleQuotedStyle, Value: version},
+	}, root.Content...)
+}
+
+// parseConfigVersion parses "<major>" or "<major>.<minor>".
+func parseConfigVersion(s string) ([2]int, error) {
+	var version [2]int
+	parts := strings.Split(strings.TrimSpace(s), ".")
+	if len(parts) > len(version) {
+		return version, fmt.Errorf("invalid config version '%s', expected a version such as %s", s, CurrentVersion)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return version, fmt.Errorf("invalid config version '%s', expected a version such as %s", s, CurrentVersion)
+		}
+		version[i] = n
+	}
+	return version, nil
+}
+
+func compareConfigVersions(a, b [2]int) int {
+	if a[0] != b[0] {
+		return a[0] - b[0]
+	}
+	return a[1] - b[1]
+}
+
+func formatConfigVersion(v [2]int) string {
+	return fmt.Sprintf("%d.%d", v[0], v[1])
+}
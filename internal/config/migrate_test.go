@@ -0,0 +1,117 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// renameOldKeyStep is a migration to version 1.1 that renames defaults.old_key to
+// defaults.new_key, for testing the migration steps without a real one.
+var renameOldKeyStep = migration{from: "1.0", to: "1.1", apply: func(root *yaml.Node) []string {
+	if renameYAMLKey(yamlMappingValue(root, "defaults"), "old_key", "new_key") {
+		return []string{"renamed defaults.old_key to defaults.new_key"}
+	}
+	return nil
+}}
+
+func migrateString(t *testing.T, content, current string, steps ...migration) (*MigrationResult, string, error) {
+	t.Helper()
+	doc, err := ParseConfigDocument(ConfigFileName, []byte(content))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	result, err := migrate(doc, current, steps)
+	if err != nil {
+		return nil, "", err
+	}
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	return result, string(out), nil
+}
+
+func TestMigrate_AppliesSteps(t *testing.T) {
+	result, out, err := migrateString(t, `# team settings
+version: "1.0"
+defaults:
+  old_key: a # no changes here
+  base_dir: ../wt
+`, "1.1", renameOldKeyStep)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !result.Migrated() || result.From != "1.0" {
+		t.Errorf("Expected a migration from 1.0, got %+v", result)
+	}
+	if len(result.Changes) != 1 {
+		t.Errorf("Expected 1 change, got %v", result.Changes)
+	}
+	for _, want := range []string{"# team settings", `version: "1.1"`, "new_key: a # no changes here"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected migrated document to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "old_key") {
+		t.Errorf("Expected old_key to be gone, got:\n%s", out)
+	}
+
+	// A file without a version is the oldest one
+	result, out, err = migrateString(t, "defaults:\n  base_dir: ../wt\n", "1.1", renameOldKeyStep)
+	if err != nil || result.From != "1.0" || !strings.HasPrefix(out, `version: "1.1"`) {
+		t.Errorf("Expected version to be added, got %+v, %v:\n%s", result, err, out)
+	}
+
+	// A current file is left alone
+	result, out, err = migrateString(t, "version: \"1.1\"\ndefaults:\n  old_key: a\n", "1.1", renameOldKeyStep)
+	if err != nil || result.Migrated() || !strings.Contains(out, "old_key") {
+		t.Errorf("Expected a current file to be left alone, got %+v, %v:\n%s", result, err, out)
+	}
+}
+
+func TestMigrate_Versions(t *testing.T) {
+	result, out, err := migrateString(t, "defaults:\n  readonly: true\n", CurrentVersion, migrations...)
+	if err != nil || result.Migrated() || strings.Contains(out, "version") || !strings.Contains(out, "readonly") {
+		t.Errorf("Expected a file of the current version to be left alone, got %+v, %v:\n%s", result, err, out)
+	}
+
+	result, _, err = migrateString(t, "version: 1\n", CurrentVersion, migrations...)
+	if err != nil || result.From != "1.0" {
+		t.Errorf("Expected version 1 to mean 1.0, got %+v, %v", result, err)
+	}
+
+	if _, _, err := migrateString(t, "version: \"2.0\"\n", CurrentVersion); err == nil ||
+		!strings.Contains(err.Error(), "upgrade wtp") {
+		t.Errorf("Expected error for a newer version, got %v", err)
+	}
+	if _, _, err := migrateString(t, "version: latest\n", CurrentVersion); err == nil {
+		t.Error("Expected error for an invalid version")
+	}
+}
+
+func TestLoadConfig_ReadOnlyAndUndeclaredVersion(t *testing.T) {
+	stubHomeDir(t)
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, ".wtp.json"),
+		[]byte(`{"defaults": {"readonly": true}}`), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := LoadConfig(tempDir, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !cfg.Defaults.ReadOnly || cfg.Version != CurrentVersion {
+		t.Errorf("Expected a read-only config, got version %s, readonly %v", cfg.Version, cfg.Defaults.ReadOnly)
+	}
+	if origin, ok := cfg.Origin("version"); ok {
+		t.Errorf("Expected no origin for a version the file does not set, got %s", origin)
+	}
+	if origin, _ := cfg.Origin("defaults.readonly"); origin != ".wtp.json" {
+		t.Errorf("Expected defaults.readonly to come from .wtp.json, got %q", origin)
+	}
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Patch file formats accepted in a patch hook's 'format' field.
+const (
+	PatchFormatJSON = "json"
+	PatchFormatYAML = "yaml"
+	PatchFormatTOML = "toml"
+)
+
+// PatchStep is one element of a patch selector: a mapping key or a sequence index.
+type PatchStep struct {
+	Key     string
+	Index   int
+	IsIndex bool
+}
+
+// ParsePatchSelector parses a yq-like selector such as `.server.port`, `.plugins[0].name`,
+// or `."key.with.dots"` into its steps. The leading dot is optional.
+func ParsePatchSelector(selector string) ([]PatchStep, error) {
+	var steps []PatchStep
+	s := selector
+	for i := 0; s != ""; i++ {
+		var (
+			step PatchStep
+			err  error
+		)
+		switch {
+		case s[0] == '[':
+			step, s, err = parsePatchBracket(s)
+		case s[0] == '.':
+			step, s, err = parsePatchKey(s[1:])
+		case i == 0:
+			step, s, err = parsePatchKey(s)
+		default:
+			err = fmt.Errorf("expected '.' or '[' at %q", s)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %w", selector, err)
+		}
+		steps = append(steps, step)
+	}
+
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("invalid selector %q: selector must name at least one key", selector)
+	}
+	return steps, nil
+}
+
+func parsePatchKey(s string) (PatchStep, string, error) {
+	if strings.HasPrefix(s, `"`) {
+		key, rest, err := parsePatchQuoted(s)
+		return PatchStep{Key: key}, rest, err
+	}
+
+	end := strings.IndexAny(s, ".[")
+	if end < 0 {
+		end = len(s)
+	}
+	if end == 0 {
+		return PatchStep{}, "", fmt.Errorf("empty key at %q", s)
+	}
+	if strings.ContainsAny(s[:end], `]"`) {
+		return PatchStep{}, "", fmt.Errorf("key %q must be quoted", s[:end])
+	}
+	return PatchStep{Key: s[:end]}, s[end:], nil
+}
+
+func parsePatchBracket(s string) (PatchStep, string, error) {
+	inner := s[1:]
+	if strings.HasPrefix(inner, `"`) {
+		key, rest, err := parsePatchQuoted(inner)
+		if err != nil {
+			return PatchStep{}, "", err
+		}
+		if !strings.HasPrefix(rest, "]") {
+			return PatchStep{}, "", fmt.Errorf("missing ']' after %q", key)
+		}
+		return PatchStep{Key: key}, rest[1:], nil
+	}
+
+	end := strings.IndexByte(inner, ']')
+	if end < 0 {
+		return PatchStep{}, "", fmt.Errorf("missing ']' in %q", s)
+	}
+	index, err := strconv.Atoi(inner[:end])
+	if err != nil || index < 0 {
+		return PatchStep{}, "", fmt.Errorf("index %q must be a non-negative integer", inner[:end])
+	}
+	return PatchStep{Index: index, IsIndex: true}, inner[end+1:], nil
+}
+
+func parsePatchQuoted(s string) (key, rest string, err error) {
+	end := strings.IndexByte(s[1:], '"')
+	if end < 0 {
+		return "", "", fmt.Errorf("unterminated quoted key in %q", s)
+	}
+	return s[1 : end+1], s[end+2:], nil
+}
+
+// PatchFormat returns the format a patch hook edits its file with: the explicit
+// 'format' field, or one inferred from the file extension.
+func (h *Hook) PatchFormat() (string, error) {
+	if h.Format != "" {
+		return h.Format, nil
+	}
+	switch strings.ToLower(filepath.Ext(h.File)) {
+	case ".json":
+		return PatchFormatJSON, nil
+	case ".yml", ".yaml":
+		return PatchFormatYAML, nil
+	case ".toml":
+		return PatchFormatTOML, nil
+	default:
+		return "", fmt.Errorf("cannot infer patch format from %q, set 'format' to json, yaml, or toml", h.File)
+	}
+}
+
+func (h *Hook) validatePatch() error {
+	if h.File == "" {
+		return fmt.Errorf("patch hook requires 'file' field")
+	}
+	if h.From != "" || h.To != "" || h.Command != "" {
+		return fmt.Errorf("patch hook should not have 'from', 'to', or 'command' fields")
+	}
+	if len(h.Set) == 0 && len(h.Delete) == 0 {
+		return fmt.Errorf("patch hook requires 'set' or 'delete' entries")
+	}
+
+	format, err := h.PatchFormat()
+	if err != nil {
+		return err
+	}
+	if format != PatchFormatJSON && format != PatchFormatYAML && format != PatchFormatTOML {
+		return fmt.Errorf("patch hook 'format' must be json, yaml, or toml, got '%s'", format)
+	}
+
+	for selector := range h.Set {
+		if _, err := ParsePatchSelector(selector); err != nil {
+			return err
+		}
+	}
+	for _, selector := range h.Delete {
+		if _, err := ParsePatchSelector(selector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePatchSelector(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     []PatchStep
+	}{
+		{".port", []PatchStep{{Key: "port"}}},
+		{"server.port", []PatchStep{{Key: "server"}, {Key: "port"}}},
+		{".plugins[2].name", []PatchStep{{Key: "plugins"}, {Index: 2, IsIndex: true}, {Key: "name"}}},
+		{`."editor.tabSize"`, []PatchStep{{Key: "editor.tabSize"}}},
+		{`.settings["files.exclude"]`, []PatchStep{{Key: "settings"}, {Key: "files.exclude"}}},
+		{"[0]", []PatchStep{{Index: 0, IsIndex: true}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			got, err := ParsePatchSelector(tt.selector)
+			if err != nil {
+				t.Fatalf("ParsePatchSelector(%q) failed: %v", tt.selector, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePatchSelector(%q) = %+v, want %+v", tt.selector, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePatchSelector_Errors(t *testing.T) {
+	for _, selector := range []string{"", ".", "..a", ".a[", ".a[-1]", `."open`, ".a]b", `.["x"`} {
+		t.Run(selector, func(t *testing.T) {
+			if _, err := ParsePatchSelector(selector); err == nil {
+				t.Errorf("ParsePatchSelector(%q) expected error", selector)
+			}
+		})
+	}
+}
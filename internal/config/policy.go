@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Policy restricts what 'wtp add' may create. It is meant for shared machines where
+// forgotten worktrees pile up; the zero value allows everything.
+type Policy struct {
+	// MaxWorktreesPerRepo caps the number of linked worktrees (the main worktree is not
+	// counted); 0 means no limit.
+	MaxWorktreesPerRepo int `yaml:"max_worktrees_per_repo,omitempty"`
+	// RequiredBranchPrefixes lists the prefixes a new branch name must start with, e.g. "feature/".
+	RequiredBranchPrefixes []string `yaml:"required_branch_prefixes,omitempty"`
+	// AllowOverride lets 'wtp add --ignore-policy' skip the checks.
+	AllowOverride bool `yaml:"allow_override,omitempty"`
+}
+
+// Enabled reports whether the policy restricts anything.
+func (p *Policy) Enabled() bool {
+	return p.MaxWorktreesPerRepo > 0 || len(p.RequiredBranchPrefixes) > 0
+}
+
+// HasBranchPrefix reports whether branch starts with one of the required prefixes, or
+// whether no prefix is required.
+func (p *Policy) HasBranchPrefix(branch string) bool {
+	if len(p.RequiredBranchPrefixes) == 0 {
+		return true
+	}
+	for _, prefix := range p.RequiredBranchPrefixes {
+		if strings.HasPrefix(branch, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) validate() error {
+	if p.MaxWorktreesPerRepo < 0 {
+		return fmt.Errorf("invalid policy.max_worktrees_per_repo: must not be negative")
+	}
+	for i, prefix := range p.RequiredBranchPrefixes {
+		if strings.TrimSpace(prefix) == "" {
+			return fmt.Errorf("invalid policy.required_branch_prefixes[%d]: must not be empty", i)
+		}
+	}
+	return nil
+}
+
+// mergePolicy applies the fields override sets on top of base.
+func mergePolicy(base, override Policy) Policy {
+	result := base
+	if override.MaxWorktreesPerRepo != 0 {
+		result.MaxWorktreesPerRepo = override.MaxWorktreesPerRepo
+	}
+	if len(override.RequiredBranchPrefixes) > 0 {
+		result.RequiredBranchPrefixes = override.RequiredBranchPrefixes
+	}
+	if override.AllowOverride {
+		result.AllowOverride = true
+	}
+	return result
+}
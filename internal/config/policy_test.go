@@ -0,0 +1,84 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfig_PolicyMergedFromGlobalAndRepo(t *testing.T) {
+	globalDir := t.TempDir()
+	repoDir := t.TempDir()
+
+	globalConfig := `policy:
+  max_worktrees_per_repo: 20
+  allow_override: true
+`
+	repoConfig := `policy:
+  required_branch_prefixes: ["feature/", "fix/"]
+`
+	if err := os.WriteFile(filepath.Join(globalDir, ConfigFileName), []byte(globalConfig), 0o644); err != nil {
+		t.Fatalf("Failed to write global config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, ConfigFileName), []byte(repoConfig), 0o644); err != nil {
+		t.Fatalf("Failed to write repo config: %v", err)
+	}
+
+	original := userHomeDir
+	userHomeDir = func() (string, error) { return globalDir, nil }
+	t.Cleanup(func() { userHomeDir = original })
+
+	config, err := LoadConfig(repoDir, "feature/x")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := Policy{
+		MaxWorktreesPerRepo:    20,
+		RequiredBranchPrefixes: []string{"feature/", "fix/"},
+		AllowOverride:          true,
+	}
+	if !reflect.DeepEqual(config.Policy, expected) {
+		t.Errorf("Expected policy %+v, got %+v", expected, config.Policy)
+	}
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		wantErr bool
+	}{
+		{name: "empty", policy: Policy{}},
+		{name: "valid", policy: Policy{MaxWorktreesPerRepo: 5, RequiredBranchPrefixes: []string{"feature/"}}},
+		{name: "negative limit", policy: Policy{MaxWorktreesPerRepo: -1}, wantErr: true},
+		{name: "empty prefix", policy: Policy{RequiredBranchPrefixes: []string{"feature/", " "}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&Config{Policy: tt.policy}).Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicy_HasBranchPrefix(t *testing.T) {
+	policy := &Policy{RequiredBranchPrefixes: []string{"feature/", "fix/"}}
+
+	if !policy.HasBranchPrefix("feature/login") {
+		t.Error("Expected feature/login to have a required prefix")
+	}
+	if policy.HasBranchPrefix("login") {
+		t.Error("Expected login to lack a required prefix")
+	}
+	if !(&Policy{}).HasBranchPrefix("login") {
+		t.Error("Expected any branch to pass when no prefix is required")
+	}
+	if (&Policy{}).Enabled() || !policy.Enabled() {
+		t.Error("Expected Enabled to reflect whether the policy restricts anything")
+	}
+}
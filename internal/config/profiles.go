@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Profile is a named set of hooks that 'wtp add --profile' runs in addition to the
+// top-level hooks, e.g. "frontend" or "backend" in a monorepo.
+type Profile struct {
+	Hooks Hooks `yaml:"hooks,omitempty"`
+	// Sparse replaces defaults.sparse for worktrees created with the profile.
+	Sparse []string `yaml:"sparse,omitempty"`
+}
+
+// ProfileNames returns the names of the configured profiles, sorted.
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ForProfile returns the configuration with the hooks of profile name appended to the
+// top-level hooks and its sparse paths, if any, in place of defaults.sparse; false when
+// there is no such profile. An empty name returns c itself.
+func (c *Config) ForProfile(name string) (*Config, bool) {
+	if name == "" {
+		return c, true
+	}
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return nil, false
+	}
+	origins := c.movedOrigins(map[string]string{
+		"profiles." + name + ".hooks.":  "hooks.",
+		"profiles." + name + ".sparse.": "defaults.sparse.",
+	})
+	overlay := &Config{Defaults: Defaults{Sparse: profile.Sparse}, Hooks: profile.Hooks, origins: origins}
+	return MergeConfig(c, overlay), true
+}
+
+func (c *Config) validateProfiles() error {
+	for _, name := range c.ProfileNames() {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("profile name must not be empty")
+		}
+		profile := c.Profiles[name]
+		if err := profile.Hooks.validate(); err != nil {
+			return fmt.Errorf("profiles %q: %w", name, err)
+		}
+		if err := ValidateSparsePaths(profile.Sparse); err != nil {
+			return fmt.Errorf("profiles %q: invalid sparse: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// mergeProfiles adds the profiles of override to those of base; the hooks of a profile
+// both define are concatenated, base hooks first, and override's sparse paths win.
+func mergeProfiles(base, override map[string]Profile) map[string]Profile {
+	if len(override) == 0 {
+		return base
+	}
+	result := make(map[string]Profile, len(base)+len(override))
+	for name := range base {
+		result[name] = base[name]
+	}
+	for name := range override {
+		profile := override[name]
+		if existing, ok := result[name]; ok {
+			profile.Hooks = mergeHooks(&existing.Hooks, &profile.Hooks)
+			if len(profile.Sparse) == 0 {
+				profile.Sparse = existing.Sparse
+			}
+		}
+		result[name] = profile
+	}
+	return result
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig_Profiles(t *testing.T) {
+	repoDir := t.TempDir()
+	original := userHomeDir
+	userHomeDir = func() (string, error) { return t.TempDir(), nil }
+	t.Cleanup(func() { userHomeDir = original })
+
+	content := `hooks:
+  post_create:
+    - type: command
+      command: "make env"
+profiles:
+  frontend:
+    hooks:
+      post_create:
+        - type: command
+          command: "npm ci"
+  backend:
+    hooks:
+      post_create:
+        - type: command
+          command: "go mod download"
+      pre_remove:
+        - type: command
+          command: "docker compose down"
+`
+	local := `profiles:
+  frontend:
+    hooks:
+      post_create:
+        - type: command
+          command: "npm run build"
+`
+	if err := os.WriteFile(filepath.Join(repoDir, ConfigFileName), []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, LocalConfigFileName), []byte(local), 0o644); err != nil {
+		t.Fatalf("Failed to write local config: %v", err)
+	}
+
+	cfg, err := LoadConfig(repoDir, "")
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if got := cfg.ProfileNames(); strings.Join(got, ",") != "backend,frontend" {
+		t.Errorf("ProfileNames() = %v", got)
+	}
+
+	frontend, ok := cfg.ForProfile("frontend")
+	if !ok {
+		t.Fatalf("ForProfile(frontend) found no profile")
+	}
+	var commands []string
+	for _, hook := range frontend.Hooks.PostCreate {
+		commands = append(commands, hook.Command)
+	}
+	if strings.Join(commands, ",") != "make env,npm ci,npm run build" {
+		t.Errorf("Expected profile hooks appended after the top-level hooks, got %v", commands)
+	}
+	if len(cfg.Hooks.PostCreate) != 1 {
+		t.Errorf("ForProfile changed the configuration it was called on: %+v", cfg.Hooks.PostCreate)
+	}
+
+	backend, _ := cfg.ForProfile("backend")
+	if len(backend.Hooks.PreRemove) != 1 || len(backend.Hooks.PostCreate) != 2 {
+		t.Errorf("Expected the backend hooks, got %+v", backend.Hooks)
+	}
+
+	if same, ok := cfg.ForProfile(""); !ok || same != cfg {
+		t.Errorf("ForProfile(\"\") should return the configuration itself")
+	}
+	if _, ok := cfg.ForProfile("mobile"); ok {
+		t.Errorf("ForProfile(mobile) should report an unknown profile")
+	}
+}
+
+func TestLoadConfig_InvalidProfile(t *testing.T) {
+	repoDir := t.TempDir()
+	original := userHomeDir
+	userHomeDir = func() (string, error) { return t.TempDir(), nil }
+	t.Cleanup(func() { userHomeDir = original })
+
+	content := `profiles:
+  frontend:
+    hooks:
+      post_create:
+        - type: copy
+`
+	if err := os.WriteFile(filepath.Join(repoDir, ConfigFileName), []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := LoadConfig(repoDir, "")
+	if err == nil || !strings.Contains(err.Error(), `profiles "frontend"`) {
+		t.Errorf("Expected profile validation error, got %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+// PruneAfter returns defaults.prune_after: how long a worktree may go untouched before
+// 'wtp prune' offers to remove it. Zero means worktrees are never pruned for their age.
+func (c *Config) PruneAfter() time.Duration {
+	d, _ := parseAge(c.Defaults.PruneAfter) // validated when loaded
+	return d
+}
+
+// parseAge accepts Go durations such as "720h" and whole days such as "30d".
+func parseAge(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	var d time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number of days in %q", s)
+		}
+		d = time.Duration(n) * day
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, err
+		}
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("must be positive, got %s", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"30d", 30 * 24 * time.Hour, false},
+		{"720h", 720 * time.Hour, false},
+		{"0d", 0, true},
+		{"-1h", 0, true},
+		{"xd", 0, true},
+		{"soon", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseAge(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAge(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseAge(%q) = %s, want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_PruneAfter(t *testing.T) {
+	cfg := &Config{Defaults: Defaults{BaseDir: DefaultBaseDir, PruneAfter: "14d"}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got := cfg.PruneAfter(); got != 14*24*time.Hour {
+		t.Errorf("PruneAfter() = %s, want 336h", got)
+	}
+
+	cfg.Defaults.PruneAfter = "two weeks"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject an invalid prune_after")
+	}
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Backoff strategies a command hook's retry policy can use.
+const (
+	BackoffConstant    = "constant"
+	BackoffExponential = "exponential"
+)
+
+// What a failing hook does to the hooks after it, set with a hook's 'on_error'.
+const (
+	// OnErrorFail stops the run at the failing hook, which is the default.
+	OnErrorFail = "fail"
+	// OnErrorContinue notes the failure and runs the next hook.
+	OnErrorContinue = "continue"
+	// OnErrorWarn prints the failure as a warning and runs the next hook.
+	OnErrorWarn = "warn"
+)
+
+// DefaultRetryDelay is the wait before the first retry when 'delay' is not set.
+const DefaultRetryDelay = time.Second
+
+// Retry is a command or download hook's retry policy, for steps such as package installs
+// that fail intermittently when a registry is overloaded.
+type Retry struct {
+	// Attempts is how many times the command runs at most, counting the first run.
+	Attempts int `yaml:"attempts"`
+	// Backoff is "exponential" (default), doubling the wait after every failure, or "constant".
+	Backoff string `yaml:"backoff,omitempty"`
+	// Delay is the wait before the first retry (e.g. "2s"); it defaults to DefaultRetryDelay.
+	Delay string `yaml:"delay,omitempty"`
+	// MaxDelay caps the wait between two attempts (e.g. "2m"); empty means no cap.
+	MaxDelay string `yaml:"max_delay,omitempty"`
+	// OnExitCodes limits a command hook's retries to these exit statuses; empty retries
+	// every failure, including timeouts.
+	OnExitCodes []int `yaml:"on_exit_codes,omitempty"`
+}
+
+// RetryPolicy returns the hook's retry policy: 'retry', or the one 'retries' and
+// 'retry_delay' describe. It is nil when a failure is not retried.
+func (h *Hook) RetryPolicy() *Retry {
+	if h.Retry != nil || h.Retries == 0 {
+		return h.Retry
+	}
+	return &Retry{Attempts: h.Retries + 1, Backoff: BackoffConstant, Delay: h.RetryDelay}
+}
+
+// ContinuesOnError reports whether the hooks after this one still run when it fails.
+func (h *Hook) ContinuesOnError() bool {
+	return h.OnError == OnErrorContinue || h.OnError == OnErrorWarn
+}
+
+// DelayBefore returns how long to wait before the given retry (1 for the second attempt).
+func (r *Retry) DelayBefore(retry int) time.Duration {
+	delay := DefaultRetryDelay
+	if r.Delay != "" {
+		delay, _ = time.ParseDuration(r.Delay) // validated when the configuration was loaded
+	}
+	maxDelay, _ := time.ParseDuration(r.MaxDelay)
+
+	if r.Backoff != BackoffConstant {
+		for i := 1; i < retry; i++ {
+			if maxDelay > 0 && delay >= maxDelay {
+				break
+			}
+			delay *= 2
+		}
+	}
+	if maxDelay > 0 && delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
+
+// RetriesFailure reports whether a failed attempt should be retried. exitCode is the
+// command's exit status, and hasExitCode is false when it did not exit on its own
+// (e.g. it timed out or could not be started).
+func (r *Retry) RetriesFailure(exitCode int, hasExitCode bool) bool {
+	if len(r.OnExitCodes) == 0 {
+		return true
+	}
+	return hasExitCode && slices.Contains(r.OnExitCodes, exitCode)
+}
+
+func (r *Retry) validate() error {
+	if r.Attempts < 1 {
+		return fmt.Errorf("'attempts' must be at least 1, got %d", r.Attempts)
+	}
+	if r.Backoff != "" && r.Backoff != BackoffConstant && r.Backoff != BackoffExponential {
+		return fmt.Errorf("'backoff' must be '%s' or '%s', got '%s'", BackoffConstant, BackoffExponential, r.Backoff)
+	}
+	durations := []struct{ name, value string }{{"delay", r.Delay}, {"max_delay", r.MaxDelay}}
+	for _, field := range durations {
+		if field.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(field.value)
+		if err != nil {
+			return fmt.Errorf("invalid '%s': %w", field.name, err)
+		}
+		if d < 0 {
+			return fmt.Errorf("'%s' must not be negative, got %s", field.name, field.value)
+		}
+	}
+	for _, code := range r.OnExitCodes {
+		if code < 1 || code > 255 {
+			return fmt.Errorf("'on_exit_codes' entries must be between 1 and 255, got %d", code)
+		}
+	}
+	return nil
+}
+
+// validateRetryFields checks 'retry' and its 'retries' and 'retry_delay' shorthand.
+func (h *Hook) validateRetryFields() error {
+	if h.Retry != nil && (h.Retries != 0 || h.RetryDelay != "") {
+		return fmt.Errorf("hook cannot have both 'retry' and 'retries' or 'retry_delay' fields")
+	}
+	if h.Retries < 0 {
+		return fmt.Errorf("'retries' must not be negative, got %d", h.Retries)
+	}
+	if h.RetryDelay != "" && h.Retries == 0 {
+		return fmt.Errorf("'retry_delay' requires 'retries'")
+	}
+	if h.RetryDelay != "" {
+		if d, err := time.ParseDuration(h.RetryDelay); err != nil || d < 0 {
+			return fmt.Errorf("'retry_delay' must be a duration like '5s', got '%s'", h.RetryDelay)
+		}
+	}
+	if h.Retry == nil {
+		return nil
+	}
+	if err := h.Retry.validate(); err != nil {
+		return fmt.Errorf("invalid 'retry': %w", err)
+	}
+	if len(h.Retry.OnExitCodes) > 0 && h.Type != HookTypeCommand && h.Type != HookTypeScript {
+		return fmt.Errorf("%s hook should not have 'on_exit_codes' in 'retry'", h.Type)
+	}
+	return nil
+}
+
+func validateOnError(onError string) error {
+	switch onError {
+	case "", OnErrorFail, OnErrorContinue, OnErrorWarn:
+		return nil
+	default:
+		return fmt.Errorf("must be '%s', '%s', or '%s', got '%s'", OnErrorFail, OnErrorContinue, OnErrorWarn, onError)
+	}
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHook_ValidateRetry(t *testing.T) {
+	tests := []struct {
+		name    string
+		hook    Hook
+		wantErr bool
+	}{
+		{"full policy", Hook{Type: HookTypeCommand, Command: "npm ci", Retry: &Retry{
+			Attempts: 5, Backoff: BackoffExponential, MaxDelay: "2m", OnExitCodes: []int{1, 7},
+		}}, false},
+		{"constant", Hook{Type: HookTypeCommand, Command: "npm ci", Retry: &Retry{
+			Attempts: 3, Backoff: BackoffConstant, Delay: "10s",
+		}}, false},
+		{"no attempts", Hook{Type: HookTypeCommand, Command: "npm ci", Retry: &Retry{}}, true},
+		{"unknown backoff", Hook{Type: HookTypeCommand, Command: "npm ci", Retry: &Retry{
+			Attempts: 3, Backoff: "linear",
+		}}, true},
+		{"bad max_delay", Hook{Type: HookTypeCommand, Command: "npm ci", Retry: &Retry{
+			Attempts: 3, MaxDelay: "soon",
+		}}, true},
+		{"exit code 0", Hook{Type: HookTypeCommand, Command: "npm ci", Retry: &Retry{
+			Attempts: 3, OnExitCodes: []int{0},
+		}}, true},
+		{"retry on copy hook", Hook{Type: HookTypeCopy, From: ".env", Retry: &Retry{Attempts: 3}}, true},
+		{"retry on download hook", Hook{Type: HookTypeDownload, URL: "https://example.com/a", To: "a",
+			Retry: &Retry{Attempts: 3}}, false},
+		{"exit codes on download hook", Hook{Type: HookTypeDownload, URL: "https://example.com/a", To: "a",
+			Retry: &Retry{Attempts: 3, OnExitCodes: []int{1}}}, true},
+		{"retries", Hook{Type: HookTypeCommand, Command: "npm ci", Retries: 2, RetryDelay: "5s"}, false},
+		{"retries and retry", Hook{Type: HookTypeCommand, Command: "npm ci", Retries: 2,
+			Retry: &Retry{Attempts: 3}}, true},
+		{"negative retries", Hook{Type: HookTypeCommand, Command: "npm ci", Retries: -1}, true},
+		{"retry_delay without retries", Hook{Type: HookTypeCommand, Command: "npm ci", RetryDelay: "5s"}, true},
+		{"bad retry_delay", Hook{Type: HookTypeCommand, Command: "npm ci", Retries: 2, RetryDelay: "5"}, true},
+		{"retries on symlink hook", Hook{Type: HookTypeSymlink, From: "a", To: "b", Retries: 2}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.hook.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetry_DelayBefore(t *testing.T) {
+	exponential := &Retry{Attempts: 6, Delay: "2s", MaxDelay: "10s"}
+	want := []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, w := range want {
+		if got := exponential.DelayBefore(i + 1); got != w {
+			t.Errorf("DelayBefore(%d) = %s, want %s", i+1, got, w)
+		}
+	}
+
+	constant := &Retry{Attempts: 3, Backoff: BackoffConstant}
+	if got := constant.DelayBefore(2); got != DefaultRetryDelay {
+		t.Errorf("DelayBefore(2) = %s, want %s", got, DefaultRetryDelay)
+	}
+}
+
+func TestRetry_RetriesFailure(t *testing.T) {
+	every := &Retry{Attempts: 3}
+	if !every.RetriesFailure(2, true) || !every.RetriesFailure(0, false) {
+		t.Error("a policy without on_exit_codes should retry every failure")
+	}
+
+	selected := &Retry{Attempts: 3, OnExitCodes: []int{1, 7}}
+	if !selected.RetriesFailure(7, true) {
+		t.Error("exit status 7 should be retried")
+	}
+	if selected.RetriesFailure(2, true) || selected.RetriesFailure(0, false) {
+		t.Error("only the listed exit statuses should be retried")
+	}
+}
+
+func TestHook_RetryPolicy(t *testing.T) {
+	if policy := (&Hook{Type: HookTypeCommand}).RetryPolicy(); policy != nil {
+		t.Errorf("RetryPolicy() without retries = %+v, want nil", policy)
+	}
+
+	policy := (&Hook{Type: HookTypeCommand, Retries: 2, RetryDelay: "5s"}).RetryPolicy()
+	want := Retry{Attempts: 3, Backoff: BackoffConstant, Delay: "5s"}
+	if policy == nil || policy.Attempts != want.Attempts || policy.Backoff != want.Backoff || policy.Delay != want.Delay {
+		t.Errorf("RetryPolicy() = %+v, want %+v", policy, want)
+	}
+
+	retry := &Retry{Attempts: 4}
+	if policy := (&Hook{Type: HookTypeCommand, Retry: retry}).RetryPolicy(); policy != retry {
+		t.Errorf("RetryPolicy() = %+v, want the hook's retry", policy)
+	}
+}
+
+func TestHook_ValidateOnError(t *testing.T) {
+	for _, onError := range []string{"", OnErrorFail, OnErrorContinue, OnErrorWarn} {
+		hook := Hook{Type: HookTypeCommand, Command: "make", OnError: onError}
+		if err := hook.Validate(); err != nil {
+			t.Errorf("Validate() with on_error %q = %v", onError, err)
+		}
+	}
+	hook := Hook{Type: HookTypeCopy, From: ".env", OnError: "ignore"}
+	if err := hook.Validate(); err == nil {
+		t.Error("Validate() accepted on_error 'ignore'")
+	}
+	if !(&Hook{OnError: OnErrorWarn}).ContinuesOnError() || (&Hook{}).ContinuesOnError() {
+		t.Error("ContinuesOnError() is wrong")
+	}
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// SchemaURL identifies the JSON Schema draft Schema is written for.
+const SchemaURL = "https://json-schema.org/draft/2020-12/schema"
+
+// schemaEnums lists the values fields taking one of a fixed set accept, keyed by the Go
+// type name and the yaml name of the field.
+var schemaEnums = map[string][]string{
+	"Defaults.after_add":        {AfterAddPrintPath, AfterAddCopyPath, AfterAddCd, AfterAddOpenEditor},
+	"Defaults.shell":            {ShellSh, ShellBash, ShellPwsh, ShellPowerShell, ShellCmd},
+	"Defaults.nested_worktrees": {NestedWorktreesWarn, NestedWorktreesError, NestedWorktreesAllow},
+	"Defaults.submodules":       {SubmodulesRecursive, SubmodulesTop, SubmodulesNone},
+	"Defaults.vcs":              {VCSGit, VCSJujutsu},
+	"Hook.type": {HookTypeCopy, HookTypeCommand, HookTypeScript, HookTypeSymlink, HookTypeHardlink, HookTypeDownload,
+		HookTypeExtract, HookTypeGitConfig, HookTypePatch, HookTypeEnsureLine, HookTypeWait, HookTypePrompt,
+		HookTypeGitHooks, HookTypeDirenv},
+	"Hook.shell":         {ShellSh, ShellBash, ShellPwsh, ShellPowerShell, ShellCmd},
+	"Hook.os":            {OSLinux, OSDarwin, OSWindows, OSFreeBSD},
+	"Hook.scope":         {GitConfigScopeLocal, GitConfigScopeWorktree},
+	"Hook.mode":          {GitHooksModeCopy, GitHooksModeSymlink},
+	"Hook.cow":           {CopyCOWAuto, CopyCOWAlways, CopyCOWNever},
+	"Hook.format":        {PatchFormatJSON, PatchFormatYAML, PatchFormatTOML},
+	"Hook.output":        {HookOutputInherit, HookOutputCapture, HookOutputFile},
+	"Hook.on_error":      {OnErrorFail, OnErrorContinue, OnErrorWarn},
+	"Hook.merge":         {HookMergeAppend, HookMergePrepend, HookMergeReplace, HookMergeDisable},
+	"Retry.backoff":      {BackoffExponential, BackoffConstant},
+	"Tmux.mode":          {TmuxModeSession, TmuxModeWindow},
+	"WorktreeMap.format": MapFormats,
+}
+
+// schemaRequired lists the fields that must be set, by Go type name.
+var schemaRequired = map[string][]string{
+	"Hook":  {"type"},
+	"Retry": {"attempts"},
+}
+
+// Schema returns a JSON Schema for configuration files, derived from the yaml names and
+// types of Config, so editors can complete keys and flag misspelled ones. It describes
+// each file on its own; checks spanning fields, such as a hook's required 'from', are
+// left to Validate and 'wtp config validate'.
+func Schema() map[string]any {
+	schema := schemaFor(reflect.TypeOf(Config{}))
+	schema["$schema"] = SchemaURL
+	schema["title"] = "wtp configuration (" + ConfigFileName + ")"
+	return schema
+}
+
+func schemaFor(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(BranchOverlays{}) {
+		// A mapping from branch glob to overlay; see BranchOverlays.UnmarshalYAML
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(reflect.TypeOf(BranchOverlay{}))}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return structSchema(t)
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem())}
+	case reflect.Map:
+		if t.Elem().Kind() == reflect.Interface {
+			return map[string]any{"type": "object"}
+		}
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem())}
+	case reflect.String:
+		// Like the loader, accept any scalar, so "PORT: 3001" or "version: 1.1" is fine
+		return map[string]any{"type": []string{"string", "number", "boolean"}}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int:
+		return map[string]any{"type": "integer"}
+	default:
+		return map[string]any{}
+	}
+}
+
+func structSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+		property := schemaFor(field.Type)
+		if values, ok := schemaEnums[t.Name()+"."+name]; ok {
+			if items, isList := property["items"].(map[string]any); isList {
+				items["enum"] = values
+			} else {
+				property["enum"] = values
+			}
+		}
+		properties[name] = property
+	}
+
+	schema := map[string]any{"type": "object", "properties": properties, "additionalProperties": false}
+	if required, ok := schemaRequired[t.Name()]; ok {
+		schema["required"] = required
+	}
+	if t == reflect.TypeOf(Hook{}) {
+		// A hook disabling an earlier one only names its id; see HookMergeDisable
+		schema["if"] = map[string]any{
+			"properties": map[string]any{"merge": map[string]any{"const": HookMergeDisable}},
+			"required":   []string{"merge"},
+		}
+		schema["then"] = map[string]any{"required": []string{"id"}}
+		schema["else"] = map[string]any{"required": schema["required"]}
+		delete(schema, "required")
+	}
+	return schema
+}
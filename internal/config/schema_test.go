@@ -0,0 +1,73 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	schema := Schema()
+	if schema["$schema"] != SchemaURL || schema["additionalProperties"] != false {
+		t.Errorf("root schema = %v", schema)
+	}
+	if _, err := json.Marshal(schema); err != nil {
+		t.Fatalf("schema does not encode as JSON: %v", err)
+	}
+
+	properties := schema["properties"].(map[string]any)
+	for _, key := range []string{"version", "defaults", "hooks", "branches", "policy", "map"} {
+		if _, ok := properties[key]; !ok {
+			t.Errorf("schema has no property %q", key)
+		}
+	}
+
+	hooks := properties["hooks"].(map[string]any)["properties"].(map[string]any)
+	hook := hooks["post_create"].(map[string]any)["items"].(map[string]any)
+	if hookElse, _ := hook["else"].(map[string]any); !reflect.DeepEqual(hookElse["required"], []string{"type"}) {
+		t.Errorf("hook required = %v, want type unless the hook disables another one", hook["else"])
+	}
+	hookType := hook["properties"].(map[string]any)["type"].(map[string]any)
+	if enum, _ := hookType["enum"].([]string); len(enum) == 0 || enum[0] != HookTypeCopy {
+		t.Errorf("hook type enum = %v", hookType["enum"])
+	}
+	hookOS := hook["properties"].(map[string]any)["os"].(map[string]any)
+	if items, _ := hookOS["items"].(map[string]any); hookOS["enum"] != nil || items["enum"] == nil {
+		t.Errorf("hook os schema = %v, want the enum on its items", hookOS)
+	}
+
+	branches := properties["branches"].(map[string]any)
+	overlay := branches["additionalProperties"].(map[string]any)
+	if _, ok := overlay["properties"].(map[string]any)["defaults"]; !ok || branches["type"] != "object" {
+		t.Errorf("branches schema = %v", branches)
+	}
+
+	env := properties["defaults"].(map[string]any)["properties"].(map[string]any)["env"].(map[string]any)
+	if env["type"] != "object" || env["additionalProperties"] == nil {
+		t.Errorf("defaults.env schema = %v", env)
+	}
+}
+
+func TestCheckConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".wtp.yml")
+	data := "defaults:\n  base_dri: ../wt\nhooks:\n  post_create:\n    - type: copy\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := CheckConfigFile(path)
+	if err == nil || !strings.Contains(err.Error(), "from") {
+		t.Errorf("CheckConfigFile() error = %v, want the copy hook's missing 'from'", err)
+	}
+	if len(keys) != 1 || keys[0].Key != "defaults.base_dri" || keys[0].Line != 2 {
+		t.Errorf("CheckConfigFile() unknown keys = %+v", keys)
+	}
+
+	if _, err := CheckConfigFile(filepath.Join(dir, "missing.yml")); err == nil {
+		t.Error("CheckConfigFile() of a missing file succeeded")
+	}
+}
@@ -0,0 +1,18 @@
+package config
+
+import "fmt"
+
+func (h *Hook) validateScript() error {
+	if h.Run == "" {
+		return fmt.Errorf("script hook requires 'run' field")
+	}
+	if h.From != "" || h.To != "" || h.Command != "" {
+		return fmt.Errorf("script hook should not have 'from', 'to', or 'command' fields")
+	}
+	if h.Shell == ShellCmd {
+		// cmd /c only runs a single line
+		return fmt.Errorf("script hook cannot use shell '%s'; use '%s', '%s', '%s', or '%s'",
+			ShellCmd, ShellSh, ShellBash, ShellPwsh, ShellPowerShell)
+	}
+	return h.validateRunOptions()
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultSlugReplacement replaces path separators and unsafe characters in slugs.
+const defaultSlugReplacement = "-"
+
+// unsafeSlugCharacters are the characters Windows forbids in file names, besides '/'.
+const unsafeSlugCharacters = `\:*?"<>|`
+
+// SlugPolicy controls how branch names become ${BRANCH_SLUG} and, once any field is set,
+// the worktree directory names under base_dir.
+type SlugPolicy struct {
+	// Replacement substitutes '/', control characters, and the characters Windows forbids
+	// in file names; empty means "-".
+	Replacement string `yaml:"replacement,omitempty"`
+	// Lowercase folds the slug to lower case.
+	Lowercase bool `yaml:"lowercase,omitempty"`
+	// MaxLength truncates the slug to this many characters; 0 means no limit.
+	MaxLength int `yaml:"max_length,omitempty"`
+	// StripPrefixes removes the first matching prefix, e.g. "feature/", before slugging.
+	StripPrefixes []string `yaml:"strip_prefixes,omitempty"`
+}
+
+// IsSet reports whether the policy was configured; an unset policy keeps worktree
+// directories named after the branch itself.
+func (p *SlugPolicy) IsSet() bool {
+	return p.Replacement != "" || p.Lowercase || p.MaxLength != 0 || len(p.StripPrefixes) > 0
+}
+
+// Slugify converts a branch name into a single path-safe directory name. A nil policy
+// uses the defaults.
+func (p *SlugPolicy) Slugify(branch string) string {
+	if p == nil {
+		p = &SlugPolicy{}
+	}
+	for _, prefix := range p.StripPrefixes {
+		if stripped, ok := strings.CutPrefix(branch, prefix); ok && stripped != "" {
+			branch = stripped
+			break
+		}
+	}
+
+	replacement := p.Replacement
+	if replacement == "" {
+		replacement = defaultSlugReplacement
+	}
+
+	var b strings.Builder
+	for _, r := range branch {
+		if r == '/' || unicode.IsControl(r) || strings.ContainsRune(unsafeSlugCharacters, r) {
+			b.WriteString(replacement)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	slug := b.String()
+
+	if p.Lowercase {
+		slug = strings.ToLower(slug)
+	}
+	if p.MaxLength > 0 && utf8.RuneCountInString(slug) > p.MaxLength {
+		slug = string([]rune(slug)[:p.MaxLength])
+		// Windows drops trailing dots and spaces, so a truncated name must not end in one
+		slug = strings.TrimRight(slug, ". "+replacement)
+	}
+	return slug
+}
+
+func (p *SlugPolicy) validate() error {
+	if strings.ContainsAny(p.Replacement, "/"+unsafeSlugCharacters) {
+		return fmt.Errorf("replacement must not contain '/' or any of %s", unsafeSlugCharacters)
+	}
+	if p.MaxLength < 0 {
+		return fmt.Errorf("max_length must not be negative")
+	}
+	for _, prefix := range p.StripPrefixes {
+		if prefix == "" {
+			return fmt.Errorf("strip_prefixes entries must not be empty")
+		}
+	}
+	return nil
+}
+
+// slugify converts a branch name to a slug with the default policy (replaces / with -)
+func slugify(s string) string {
+	return (&SlugPolicy{}).Slugify(s)
+}
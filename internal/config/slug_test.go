@@ -0,0 +1,128 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSlugPolicy_Slugify(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   SlugPolicy
+		input    string
+		expected string
+	}{
+		{name: "default", input: "feature/auth", expected: "feature-auth"},
+		{name: "windows characters", input: `fix/a:b*c?"d"<e>|f\g`, expected: "fix-a-b-c--d--e--f-g"},
+		{name: "replacement", policy: SlugPolicy{Replacement: "_"}, input: "feature/a:b", expected: "feature_a_b"},
+		{name: "lowercase", policy: SlugPolicy{Lowercase: true}, input: "Feature/JIRA-12", expected: "feature-jira-12"},
+		{
+			name:     "strip prefix",
+			policy:   SlugPolicy{StripPrefixes: []string{"bugfix/", "feature/"}},
+			input:    "feature/auth/login",
+			expected: "auth-login",
+		},
+		{
+			name:     "prefix is the whole name",
+			policy:   SlugPolicy{StripPrefixes: []string{"feature/"}},
+			input:    "feature/",
+			expected: "feature-",
+		},
+		{
+			name:     "max length trims trailing separators",
+			policy:   SlugPolicy{MaxLength: 8},
+			input:    "feature/very-long-name",
+			expected: "feature",
+		},
+		{
+			name:     "max length counts characters",
+			policy:   SlugPolicy{MaxLength: 3},
+			input:    "日本語ブランチ",
+			expected: "日本語",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.policy.Slugify(tt.input); result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestSlugPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      SlugPolicy
+		expectedErr string
+	}{
+		{name: "empty"},
+		{name: "valid", policy: SlugPolicy{Replacement: "_", MaxLength: 40, StripPrefixes: []string{"feature/"}}},
+		{name: "slash replacement", policy: SlugPolicy{Replacement: "/"}, expectedErr: "replacement must not contain"},
+		{name: "colon replacement", policy: SlugPolicy{Replacement: ":"}, expectedErr: "replacement must not contain"},
+		{name: "negative length", policy: SlugPolicy{MaxLength: -1}, expectedErr: "max_length must not be negative"},
+		{name: "empty prefix", policy: SlugPolicy{StripPrefixes: []string{""}}, expectedErr: "must not be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Defaults: Defaults{Slug: tt.policy}}
+			err := cfg.Validate()
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestResolveWorktreePath_SlugPolicy(t *testing.T) {
+	repoRoot := t.TempDir()
+	cfg := &Config{Defaults: Defaults{BaseDir: "../worktrees"}}
+
+	expected := filepath.Join(repoRoot, "..", "worktrees", "feature", "Auth")
+	if path := cfg.ResolveWorktreePath(repoRoot, "feature/Auth"); path != filepath.Clean(expected) {
+		t.Errorf("Expected nested path %s without a slug policy, got %s", expected, path)
+	}
+
+	cfg.Defaults.Slug = SlugPolicy{Lowercase: true, StripPrefixes: []string{"feature/"}}
+	expected = filepath.Join(repoRoot, "..", "worktrees", "auth")
+	if path := cfg.ResolveWorktreePath(repoRoot, "feature/Auth"); path != filepath.Clean(expected) {
+		t.Errorf("Expected slugged path %s, got %s", expected, path)
+	}
+	if result := cfg.ExpandVariables("db_${BRANCH_SLUG}", repoRoot, "feature/Auth"); result != "db_auth" {
+		t.Errorf("Expected ${BRANCH_SLUG} to follow the slug policy, got %s", result)
+	}
+}
+
+func TestMergeConfig_SlugPolicy(t *testing.T) {
+	base := &Config{Defaults: Defaults{Slug: SlugPolicy{Replacement: "_"}}}
+
+	merged := MergeConfig(base, &Config{})
+	if merged.Defaults.Slug.Replacement != "_" {
+		t.Errorf("Expected base slug policy to be kept, got %+v", merged.Defaults.Slug)
+	}
+
+	merged = MergeConfig(base, &Config{Defaults: Defaults{Slug: SlugPolicy{Lowercase: true}}})
+	if merged.Defaults.Slug.Replacement != "" || !merged.Defaults.Slug.Lowercase {
+		t.Errorf("Expected override slug policy to replace the base one, got %+v", merged.Defaults.Slug)
+	}
+}
+
+func TestCondition_BranchSlugUsesPolicy(t *testing.T) {
+	cond, err := ParseCondition(`${BRANCH_SLUG} == "auth"`)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	ctx := ConditionContext{Branch: "feature/auth", Slug: &SlugPolicy{StripPrefixes: []string{"feature/"}}}
+	if ok, err := cond.Evaluate(ctx); err != nil || !ok {
+		t.Errorf("Expected condition to match the policy slug, got %v, %v", ok, err)
+	}
+}
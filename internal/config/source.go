@@ -0,0 +1,198 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// GlobalSourcePrefix starts the source of settings from the global configuration file in
+// the home directory, e.g. "~/.wtp.yml".
+const GlobalSourcePrefix = "~/"
+
+// setSource records source, the configuration file c was read from, as the source of
+// every setting and hook in it. Those of a branch overlay or profile also name the
+// overlay or profile.
+func (c *Config) setSource(source string) {
+	c.Hooks.setSource(source)
+	c.origins = settingOrigins(c, source)
+
+	// Shorter patterns first, so a pattern that extends another one labels its own keys
+	patterns := make([]string, 0, len(c.Branches))
+	for i := range c.Branches {
+		overlay := &c.Branches[i]
+		label := fmt.Sprintf("%s (branches %q)", source, overlay.Pattern)
+		overlay.Hooks.setSource(label)
+		patterns = append(patterns, overlay.Pattern)
+	}
+	sort.SliceStable(patterns, func(i, j int) bool { return len(patterns[i]) < len(patterns[j]) })
+	for _, pattern := range patterns {
+		c.labelOrigins("branches."+pattern+".", fmt.Sprintf("%s (branches %q)", source, pattern))
+	}
+
+	for name := range c.Profiles {
+		label := fmt.Sprintf("%s (profiles %q)", source, name)
+		profile := c.Profiles[name]
+		profile.Hooks.setSource(label)
+		c.Profiles[name] = profile
+		c.labelOrigins("profiles."+name+".", label)
+	}
+}
+
+func (h *Hooks) setSource(source string) {
+	for _, list := range [][]Hook{
+		h.PostCreate, h.PreRemove, h.PostCheckout, h.PostRemove, h.PostPrune, h.Maintenance, h.Clean,
+	} {
+		for i := range list {
+			list[i].Source = source
+		}
+	}
+}
+
+// Origin returns the configuration file the value at key, a dotted key as FlattenSettings
+// lists it, came from. Keys inside a list item, such as hooks.post_create.0.shell, take
+// the origin of the item. It returns false for values no file set, such as defaults.
+func (c *Config) Origin(key string) (string, bool) {
+	for {
+		if source, ok := c.origins[key]; ok {
+			return source, true
+		}
+		i := strings.LastIndex(key, ".")
+		if i < 0 {
+			return "", false
+		}
+		key = key[:i]
+	}
+}
+
+// settingOrigins maps the key of every value c sets, and of every list item, to source.
+func settingOrigins(c *Config, source string) map[string]string {
+	var node yaml.Node
+	if err := node.Encode(c); err != nil {
+		return nil
+	}
+	origins := make(map[string]string)
+	recordOrigins(&node, "", source, origins)
+	return origins
+}
+
+func recordOrigins(node *yaml.Node, prefix, source string, origins map[string]string) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			recordOrigins(node.Content[i+1], joinKey(prefix, node.Content[i].Value), source, origins)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			key := joinKey(prefix, strconv.Itoa(i))
+			origins[key] = source
+			recordOrigins(item, key, source, origins)
+		}
+	case yaml.AliasNode:
+		recordOrigins(node.Alias, prefix, source, origins)
+	case yaml.ScalarNode:
+		if node.Value != "" { // unset, like the fields MergeConfig leaves alone
+			origins[prefix] = source
+		}
+	}
+}
+
+// labelOrigins replaces the source of the keys under prefix with label.
+func (c *Config) labelOrigins(prefix, label string) {
+	for key := range c.origins {
+		if strings.HasPrefix(key, prefix) {
+			c.origins[key] = label
+		}
+	}
+}
+
+// movedOrigins returns the origins of the keys under from, with from replaced by to. It
+// carries the origins of a branch overlay or profile over to the settings it applies.
+func (c *Config) movedOrigins(moves map[string]string) map[string]string {
+	origins := make(map[string]string)
+	for key, source := range c.origins {
+		for from, to := range moves {
+			if rest, ok := strings.CutPrefix(key, from); ok {
+				origins[to+rest] = source
+			}
+		}
+	}
+	return origins
+}
+
+// mergeOrigins returns the origins of result, MergeConfig(base, override): those of
+// override win, and the items override appends to a list of base are numbered after the
+// items of base. Hooks take the origin their Source names.
+func mergeOrigins(base, override, result *Config) map[string]string {
+	if len(base.origins) == 0 && len(override.origins) == 0 {
+		return nil
+	}
+	merged := make(map[string]string, len(base.origins)+len(override.origins))
+	for key, source := range base.origins {
+		merged[key] = source
+	}
+	offsets := map[string]int{"verify": len(base.Verify), "hibernate": len(base.Hibernate)}
+	for key, source := range override.origins {
+		merged[shiftListItem(key, offsets)] = source
+	}
+	result.setHookOrigins(merged)
+	return merged
+}
+
+// setHookOrigins sets the origins of the hooks of c, and of its profiles, in origins from
+// their Source. The keys of merged hooks cannot be derived from those of the layers, as
+// a hook's 'merge' policy may have replaced, dropped, or moved hooks.
+func (c *Config) setHookOrigins(origins map[string]string) {
+	lists := map[string]*Hooks{"hooks.": &c.Hooks}
+	for name := range c.Profiles {
+		profile := c.Profiles[name]
+		lists["profiles."+name+".hooks."] = &profile.Hooks
+	}
+	for key := range origins {
+		for prefix := range lists {
+			if strings.HasPrefix(key, prefix) {
+				delete(origins, key)
+				break
+			}
+		}
+	}
+	for prefix, hooks := range lists {
+		phases := map[string][]Hook{
+			"post_create": hooks.PostCreate, "pre_remove": hooks.PreRemove, "post_checkout": hooks.PostCheckout,
+			"post_remove": hooks.PostRemove, "post_prune": hooks.PostPrune, "maintenance": hooks.Maintenance,
+			"clean": hooks.Clean,
+		}
+		for phase, list := range phases {
+			for i := range list {
+				if list[i].Source != "" {
+					origins[prefix+phase+"."+strconv.Itoa(i)] = list[i].Source
+				}
+			}
+		}
+	}
+}
+
+// shiftListItem renumbers key when it lies in an item of one of lists, adding the number
+// of items the list already has.
+func shiftListItem(key string, lists map[string]int) string {
+	for list, offset := range lists {
+		rest, ok := strings.CutPrefix(key, list+".")
+		if !ok || offset == 0 {
+			continue
+		}
+		index, tail, hasTail := strings.Cut(rest, ".")
+		i, err := strconv.Atoi(index)
+		if err != nil {
+			continue
+		}
+		shifted := list + "." + strconv.Itoa(i+offset)
+		if hasTail {
+			shifted += "." + tail
+		}
+		return shifted
+	}
+	return key
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfig_HookSources(t *testing.T) {
+	repoDir := t.TempDir()
+	homeDir := t.TempDir()
+	original := userHomeDir
+	userHomeDir = func() (string, error) { return homeDir, nil }
+	t.Cleanup(func() { userHomeDir = original })
+
+	files := map[string]string{
+		filepath.Join(homeDir, ConfigFileName): `hooks:
+  post_create:
+    - type: command
+      command: "echo global"
+`,
+		filepath.Join(repoDir, ConfigFileName): `hooks:
+  post_create:
+    - type: command
+      command: "echo repo"
+  pre_remove:
+    - type: command
+      command: "echo bye"
+branches:
+  release/*:
+    hooks:
+      post_create:
+        - type: command
+          command: "echo release"
+profiles:
+  frontend:
+    hooks:
+      post_create:
+        - type: command
+          command: "npm ci"
+`,
+		filepath.Join(repoDir, LocalConfigFileName): `hooks:
+  post_create:
+    - type: command
+      command: "echo local"
+`,
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	cfg, err := LoadConfig(repoDir, "release/1.0")
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	cfg, _ = cfg.ForProfile("frontend")
+
+	want := []string{
+		"~/.wtp.yml",
+		".wtp.yml",
+		".wtp.local.yml",
+		`.wtp.yml (branches "release/*")`,
+		`.wtp.yml (profiles "frontend")`,
+	}
+	if len(cfg.Hooks.PostCreate) != len(want) {
+		t.Fatalf("Expected %d post_create hooks, got %+v", len(want), cfg.Hooks.PostCreate)
+	}
+	for i, source := range want {
+		if got := cfg.Hooks.PostCreate[i].Source; got != source {
+			t.Errorf("Hook %d (%s): source = %q, want %q", i+1, cfg.Hooks.PostCreate[i].Command, got, source)
+		}
+	}
+	if got := cfg.Hooks.PreRemove[0].Source; got != ".wtp.yml" {
+		t.Errorf("pre_remove source = %q, want .wtp.yml", got)
+	}
+}
+
+func TestLoadConfig_Origins(t *testing.T) {
+	repoDir := t.TempDir()
+	homeDir := t.TempDir()
+	original := userHomeDir
+	userHomeDir = func() (string, error) { return homeDir, nil }
+	t.Cleanup(func() { userHomeDir = original })
+
+	files := map[string]string{
+		filepath.Join(homeDir, ConfigFileName): `defaults:
+  hook_timeout: 5m
+hooks:
+  post_create:
+    - type: copy
+      from: .env
+hibernate: [node_modules]
+`,
+		filepath.Join(repoDir, ConfigFileName): `defaults:
+  base_dir: ../wt
+  env:
+    PORT: "3000"
+hooks:
+  post_create:
+    - type: command
+      command: npm ci
+hibernate: [dist]
+branches:
+  release/*:
+    defaults:
+      base_dir: ../releases
+profiles:
+  frontend:
+    hooks:
+      post_create:
+        - type: command
+          command: npm run dev
+`,
+		filepath.Join(repoDir, LocalConfigFileName): `defaults:
+  base_dir: ../mine
+  env:
+    DEBUG: "1"
+profiles:
+  frontend:
+    hooks:
+      post_create:
+        - type: command
+          command: open http://localhost:3000
+`,
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	cfg, err := LoadConfig(repoDir, "")
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	tests := map[string]string{
+		"defaults.hook_timeout":                         "~/.wtp.yml",
+		"defaults.base_dir":                             ".wtp.local.yml",
+		"defaults.env.PORT":                             ".wtp.yml",
+		"defaults.env.DEBUG":                            ".wtp.local.yml",
+		"hooks.post_create.0.from":                      "~/.wtp.yml",
+		"hooks.post_create.0.to":                        "~/.wtp.yml", // defaulted within the hook
+		"hooks.post_create.1.command":                   ".wtp.yml",
+		"hibernate.0":                                   "~/.wtp.yml",
+		"hibernate.1":                                   ".wtp.yml",
+		"branches.release/*.defaults.base_dir":          `.wtp.yml (branches "release/*")`,
+		"profiles.frontend.hooks.post_create.0.command": `.wtp.yml (profiles "frontend")`,
+		"profiles.frontend.hooks.post_create.1.command": `.wtp.local.yml (profiles "frontend")`,
+	}
+	for key, want := range tests {
+		if got, ok := cfg.Origin(key); !ok || got != want {
+			t.Errorf("Origin(%q) = %q, %v; want %q", key, got, ok, want)
+		}
+	}
+	if got, ok := cfg.Origin("defaults.operation_timeout"); ok {
+		t.Errorf("Origin of a default = %q, want none", got)
+	}
+
+	release := cfg.ForBranch("release/1.0")
+	if got, _ := release.Origin("defaults.base_dir"); got != `.wtp.yml (branches "release/*")` {
+		t.Errorf("Origin of an overlay setting = %q", got)
+	}
+	withProfile, _ := cfg.ForProfile("frontend")
+	if got, _ := withProfile.Origin("hooks.post_create.3.command"); got != `.wtp.local.yml (profiles "frontend")` {
+		t.Errorf("Origin of a profile hook = %q", got)
+	}
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ValidateSparsePaths checks the directories of a sparse checkout, from defaults.sparse,
+// a profile's sparse, or 'wtp add --sparse': each must be a path inside the repository.
+func ValidateSparsePaths(paths []string) error {
+	for _, path := range paths {
+		if strings.TrimSpace(path) == "" {
+			return fmt.Errorf("sparse paths must not be empty")
+		}
+		if filepath.IsAbs(path) || strings.HasPrefix(path, "/") {
+			return fmt.Errorf("sparse path '%s' must be relative to the repository root", path)
+		}
+		for _, part := range strings.Split(filepath.ToSlash(path), "/") {
+			if part == ".." {
+				return fmt.Errorf("sparse path '%s' must not leave the repository", path)
+			}
+		}
+	}
+	return nil
+}
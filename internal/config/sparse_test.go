@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateSparsePaths(t *testing.T) {
+	if err := ValidateSparsePaths([]string{"services/api", "libs/shared", "docs"}); err != nil {
+		t.Errorf("Expected valid sparse paths, got %v", err)
+	}
+
+	for _, path := range []string{"", "  ", "/etc", "../other", "libs/../../x"} {
+		if err := ValidateSparsePaths([]string{path}); err == nil {
+			t.Errorf("Expected an error for sparse path %q", path)
+		}
+	}
+}
+
+func TestLoadConfig_Sparse(t *testing.T) {
+	repoDir := t.TempDir()
+	original := userHomeDir
+	userHomeDir = func() (string, error) { return t.TempDir(), nil }
+	t.Cleanup(func() { userHomeDir = original })
+
+	content := `defaults:
+  sparse: [libs/shared]
+profiles:
+  frontend:
+    sparse: [apps/web, libs/ui]
+  backend:
+    hooks:
+      post_create:
+        - type: command
+          command: "go mod download"
+`
+	if err := os.WriteFile(filepath.Join(repoDir, ConfigFileName), []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := LoadConfig(repoDir, "")
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if got := strings.Join(cfg.Defaults.Sparse, ","); got != "libs/shared" {
+		t.Errorf("defaults.sparse = %s", got)
+	}
+
+	frontend, _ := cfg.ForProfile("frontend")
+	if got := strings.Join(frontend.Defaults.Sparse, ","); got != "apps/web,libs/ui" {
+		t.Errorf("Expected the profile's sparse paths to replace defaults.sparse, got %s", got)
+	}
+	backend, _ := cfg.ForProfile("backend")
+	if got := strings.Join(backend.Defaults.Sparse, ","); got != "libs/shared" {
+		t.Errorf("Expected a profile without sparse paths to keep defaults.sparse, got %s", got)
+	}
+}
+
+func TestLoadConfig_InvalidSparse(t *testing.T) {
+	repoDir := t.TempDir()
+	original := userHomeDir
+	userHomeDir = func() (string, error) { return t.TempDir(), nil }
+	t.Cleanup(func() { userHomeDir = original })
+
+	for content, want := range map[string]string{
+		"defaults:\n  sparse: [../outside]\n":     "invalid defaults.sparse",
+		"profiles:\n  web:\n    sparse: [/abs]\n": `profiles "web": invalid sparse`,
+	} {
+		if err := os.WriteFile(filepath.Join(repoDir, ConfigFileName), []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+		if _, err := LoadConfig(repoDir, ""); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error containing %q, got %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// StrictEnvVar turns on strict mode when set to a true value, as the --strict flag does.
+const StrictEnvVar = "WTP_STRICT"
+
+// maxSuggestionDistance is how many edits apart a known key may be from an unknown one to
+// be suggested in its place.
+const maxSuggestionDistance = 2
+
+// UnknownKey is a key of a configuration file that names no setting, such as a misspelled
+// post_craete. Outside strict mode such keys are ignored.
+type UnknownKey struct {
+	File string
+	// Key is the dotted path of the key, e.g. hooks.post_craete.
+	Key string
+	// Line and Column locate the key in a YAML file; they are 0 for JSON and TOML files.
+	Line   int
+	Column int
+	// Suggestion is the known key closest to the misspelled one, if any is close.
+	Suggestion string
+}
+
+func (k UnknownKey) String() string {
+	location := k.File
+	if k.Line > 0 {
+		location = fmt.Sprintf("%s:%d:%d", k.File, k.Line, k.Column)
+	}
+	msg := fmt.Sprintf("%s: unknown key '%s'", location, k.Key)
+	if k.Suggestion != "" {
+		msg += fmt.Sprintf(" (did you mean '%s'?)", k.Suggestion)
+	}
+	return msg
+}
+
+// UnknownKeysError is returned by LoadConfig in strict mode when any configuration file
+// has keys that name no setting.
+type UnknownKeysError struct {
+	Keys []UnknownKey
+}
+
+func (e *UnknownKeysError) Error() string {
+	lines := make([]string, len(e.Keys))
+	for i, key := range e.Keys {
+		lines[i] = "  " + key.String()
+	}
+	return "strict mode: unknown keys in the configuration:\n" + strings.Join(lines, "\n")
+}
+
+// strictFromEnv reports whether StrictEnvVar turns on strict mode.
+func strictFromEnv() bool {
+	strict, err := strconv.ParseBool(os.Getenv(StrictEnvVar))
+	return err == nil && strict
+}
+
+// findUnknownKeys lists the keys of a configuration document, after migration, that no
+// field of Config decodes. Positions are only reported for YAML files, since JSON and
+// TOML are converted before they are parsed.
+func findUnknownKeys(path string, doc *yaml.Node) []UnknownKey {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil
+	}
+	var keys []UnknownKey
+	findUnknownKeysIn(doc.Content[0], reflect.TypeOf(Config{}), "", &keys)
+	for i := range keys {
+		keys[i].File = path
+		if !IsYAMLConfigFile(path) {
+			keys[i].Line, keys[i].Column = 0, 0
+		}
+	}
+	return keys
+}
+
+func findUnknownKeysIn(node *yaml.Node, t reflect.Type, prefix string, keys *[]UnknownKey) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	switch {
+	case t == reflect.TypeOf(BranchOverlays{}) && node.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			findUnknownKeysIn(node.Content[i+1], reflect.TypeOf(BranchOverlay{}),
+				joinKey(prefix, node.Content[i].Value), keys)
+		}
+	case t.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		findUnknownFields(node, t, prefix, keys)
+	case t.Kind() == reflect.Slice && node.Kind == yaml.SequenceNode:
+		for i, item := range node.Content {
+			findUnknownKeysIn(item, t.Elem(), joinKey(prefix, strconv.Itoa(i)), keys)
+		}
+	case t.Kind() == reflect.Map && node.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			findUnknownKeysIn(node.Content[i+1], t.Elem(), joinKey(prefix, node.Content[i].Value), keys)
+		}
+	}
+}
+
+// findUnknownFields checks the keys of a mapping decoded into struct type t.
+func findUnknownFields(node *yaml.Node, t reflect.Type, prefix string, keys *[]UnknownKey) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		if key.Tag == "!!merge" {
+			continue
+		}
+		field, ok := yamlField(t, key.Value)
+		if !ok {
+			*keys = append(*keys, UnknownKey{
+				Key:        joinKey(prefix, key.Value),
+				Line:       key.Line,
+				Column:     key.Column,
+				Suggestion: suggestField(t, key.Value),
+			})
+			continue
+		}
+		findUnknownKeysIn(node.Content[i+1], field.Type, joinKey(prefix, key.Value), keys)
+	}
+}
+
+// joinKey appends key to the dotted path prefix.
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// suggestField returns the yaml name of the field of struct type t closest to name, or ""
+// when none is within maxSuggestionDistance edits.
+func suggestField(t reflect.Type, name string) string {
+	best, bestDistance := "", maxSuggestionDistance+1
+	for i := 0; i < t.NumField(); i++ {
+		tag, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if tag == "" || tag == "-" || !t.Field(i).IsExported() {
+			continue
+		}
+		if d := editDistance(name, tag); d < bestDistance {
+			best, bestDistance = tag, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindUnknownKeys(t *testing.T) {
+	data := `version: "1.0"
+defaults:
+  base_dir: ../worktrees
+  env:
+    ANY_NAME: ok
+hooks:
+  post_craete:
+    - type: copy
+  post_create:
+    - type: command
+      comand: make
+      retry:
+        attempts: 2
+        dealy: 1s
+branches:
+  "release/*":
+    defaults:
+      readonly_mode: true
+colour: blue
+`
+	var cfg Config
+	if err := decodeConfig(".wtp.yml", []byte(data), &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []UnknownKey{
+		{File: ".wtp.yml", Key: "hooks.post_craete", Line: 7, Column: 3, Suggestion: "post_create"},
+		{File: ".wtp.yml", Key: "hooks.post_create.0.comand", Line: 11, Column: 7, Suggestion: "command"},
+		{File: ".wtp.yml", Key: "hooks.post_create.0.retry.dealy", Line: 14, Column: 9, Suggestion: "delay"},
+		{File: ".wtp.yml", Key: "branches.release/*.defaults.readonly_mode", Line: 18, Column: 7},
+		{File: ".wtp.yml", Key: "colour", Line: 19, Column: 1},
+	}
+	if !reflect.DeepEqual(cfg.unknownKeys, want) {
+		t.Errorf("unknown keys = %+v\nwant %+v", cfg.unknownKeys, want)
+	}
+	if got := want[0].String(); got != ".wtp.yml:7:3: unknown key 'hooks.post_craete' (did you mean 'post_create'?)" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestFindUnknownKeys_JSONHasNoPositions(t *testing.T) {
+	var cfg Config
+	if err := decodeConfig(".wtp.json", []byte(`{"defaults": {"base_dri": "x"}}`), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	want := []UnknownKey{{File: ".wtp.json", Key: "defaults.base_dri", Suggestion: "base_dir"}}
+	if !reflect.DeepEqual(cfg.unknownKeys, want) {
+		t.Errorf("unknown keys = %+v, want %+v", cfg.unknownKeys, want)
+	}
+	if got := want[0].String(); got != ".wtp.json: unknown key 'defaults.base_dri' (did you mean 'base_dir'?)" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestLoadConfig_Strict(t *testing.T) {
+	repoDir := t.TempDir()
+	original := userHomeDir
+	userHomeDir = func() (string, error) { return t.TempDir(), nil }
+	t.Cleanup(func() { userHomeDir = original })
+	t.Setenv(StrictEnvVar, "")
+
+	repoConfig := "defaults:\n  base_dir: ../worktrees\nhooks:\n  post_craete: []\n"
+	if err := os.WriteFile(filepath.Join(repoDir, ConfigFileName), []byte(repoConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadConfig(repoDir, ""); err != nil {
+		t.Fatalf("unknown keys should be ignored outside strict mode, got %v", err)
+	}
+
+	t.Setenv(StrictEnvVar, "true")
+	_, err := LoadConfig(repoDir, "")
+	var unknown *UnknownKeysError
+	if !errors.As(err, &unknown) || len(unknown.Keys) != 1 || unknown.Keys[0].Key != "hooks.post_craete" {
+		t.Fatalf("expected an UnknownKeysError for hooks.post_craete, got %v", err)
+	}
+
+	// defaults.strict in any layer turns strict mode on as well
+	t.Setenv(StrictEnvVar, "")
+	local := "defaults:\n  strict: true\n"
+	if err := os.WriteFile(filepath.Join(repoDir, LocalConfigFileName), []byte(local), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = LoadConfig(repoDir, "")
+	if err == nil || !strings.Contains(err.Error(), "did you mean 'post_create'?") {
+		t.Errorf("expected a strict mode error, got %v", err)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"post_craete", "post_create", 2},
+		{"base_dir", "base_dir", 0},
+		{"", "abc", 3},
+		{"comand", "command", 1},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Kinds of verify check, as returned by Check.Kind.
+const (
+	CheckKindFile      = "file"
+	CheckKindCommand   = "command"
+	CheckKindPortFree  = "port_free"
+	CheckKindPortInUse = "port_in_use"
+)
+
+// Check is one entry of the 'verify' section, run after the post_create hooks and by
+// 'wtp verify'. Exactly one of File, Command, PortFree, or PortInUse is set.
+type Check struct {
+	// Name labels the check in the report; it defaults to the kind and target.
+	Name string `yaml:"name,omitempty"`
+	// File must exist; relative paths are resolved against the worktree.
+	File string `yaml:"file,omitempty"`
+	// Command must exit with status 0; it runs in the worktree like a command hook.
+	Command string `yaml:"command,omitempty"`
+	// PortFree is a port, or host:port, nothing may be listening on.
+	PortFree string `yaml:"port_free,omitempty"`
+	// PortInUse is a port, or host:port, something must be listening on.
+	PortInUse string `yaml:"port_in_use,omitempty"`
+	// Timeout limits how long a command check may run (e.g. "30s"); it overrides defaults.hook_timeout.
+	Timeout string `yaml:"timeout,omitempty"`
+}
+
+// Kind returns what the check verifies and its (unexpanded) target.
+func (c *Check) Kind() (kind, target string) {
+	switch {
+	case c.File != "":
+		return CheckKindFile, c.File
+	case c.Command != "":
+		return CheckKindCommand, c.Command
+	case c.PortFree != "":
+		return CheckKindPortFree, c.PortFree
+	default:
+		return CheckKindPortInUse, c.PortInUse
+	}
+}
+
+// CheckAddress turns a port check target into host:port; a bare port means localhost.
+func CheckAddress(target string) string {
+	if _, err := strconv.Atoi(target); err == nil {
+		return net.JoinHostPort("localhost", target)
+	}
+	return target
+}
+
+// HasVerifyChecks reports whether the 'verify' section has any checks.
+func (c *Config) HasVerifyChecks() bool {
+	return len(c.Verify) > 0
+}
+
+func (c *Check) validate() error {
+	count := 0
+	for _, target := range []string{c.File, c.Command, c.PortFree, c.PortInUse} {
+		if target != "" {
+			count++
+		}
+	}
+	if count != 1 {
+		return fmt.Errorf("check requires exactly one of 'file', 'command', 'port_free', or 'port_in_use' fields")
+	}
+
+	if kind, target := c.Kind(); kind == CheckKindPortFree || kind == CheckKindPortInUse {
+		if !strings.Contains(target, "${") { // expanded when the check runs
+			if _, _, err := net.SplitHostPort(CheckAddress(target)); err != nil {
+				return fmt.Errorf("'%s' must be a port or host:port: %w", kind, err)
+			}
+		}
+	}
+	if c.Timeout != "" && c.Command == "" {
+		return fmt.Errorf("'timeout' is only supported for 'command' checks")
+	}
+	if _, err := parseHookTimeout(c.Timeout); err != nil {
+		return fmt.Errorf("invalid 'timeout': %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheck_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		check   Check
+		wantErr string
+	}{
+		{name: "file", check: Check{File: ".env"}},
+		{name: "command with timeout", check: Check{Command: "make check", Timeout: "30s"}},
+		{name: "bare port", check: Check{PortFree: "3000"}},
+		{name: "host and port", check: Check{PortInUse: "db.local:5432"}},
+		{name: "port with variable", check: Check{PortFree: "${PORT}"}},
+		{name: "no target", check: Check{Name: "empty"}, wantErr: "exactly one"},
+		{name: "two targets", check: Check{File: ".env", Command: "true"}, wantErr: "exactly one"},
+		{name: "invalid port", check: Check{PortFree: "localhost"}, wantErr: "must be a port or host:port"},
+		{name: "timeout on file", check: Check{File: ".env", Timeout: "5s"}, wantErr: "only supported for 'command'"},
+		{name: "invalid timeout", check: Check{Command: "true", Timeout: "soon"}, wantErr: "invalid 'timeout'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.check.validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCheck_Kind(t *testing.T) {
+	tests := []struct {
+		check      Check
+		wantKind   string
+		wantTarget string
+	}{
+		{Check{File: ".env"}, CheckKindFile, ".env"},
+		{Check{Command: "make check"}, CheckKindCommand, "make check"},
+		{Check{PortFree: "3000"}, CheckKindPortFree, "3000"},
+		{Check{PortInUse: "5432"}, CheckKindPortInUse, "5432"},
+	}
+
+	for _, tt := range tests {
+		kind, target := tt.check.Kind()
+		if kind != tt.wantKind || target != tt.wantTarget {
+			t.Errorf("Kind() = (%q, %q), want (%q, %q)", kind, target, tt.wantKind, tt.wantTarget)
+		}
+	}
+}
+
+func TestCheckAddress(t *testing.T) {
+	if got := CheckAddress("3000"); got != "localhost:3000" {
+		t.Errorf("Expected localhost:3000, got %q", got)
+	}
+	if got := CheckAddress("127.0.0.1:8080"); got != "127.0.0.1:8080" {
+		t.Errorf("Expected 127.0.0.1:8080, got %q", got)
+	}
+}
+
+func TestLoadConfig_VerifyChecks(t *testing.T) {
+	repoDir := t.TempDir()
+	original := userHomeDir
+	userHomeDir = func() (string, error) { return t.TempDir(), nil }
+	t.Cleanup(func() { userHomeDir = original })
+
+	content := `verify:
+  - name: env file
+    file: .env
+  - command: make check
+    timeout: 1m
+  - port_free: 3000
+`
+	if err := os.WriteFile(filepath.Join(repoDir, ConfigFileName), []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	config, err := LoadConfig(repoDir, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(config.Verify) != 3 {
+		t.Fatalf("Expected 3 verify checks, got %d", len(config.Verify))
+	}
+	if config.Verify[0].Name != "env file" || config.Verify[2].PortFree != "3000" {
+		t.Errorf("Unexpected verify checks: %+v", config.Verify)
+	}
+}
+
+func TestLoadConfig_InvalidVerifyCheck(t *testing.T) {
+	repoDir := t.TempDir()
+	original := userHomeDir
+	userHomeDir = func() (string, error) { return t.TempDir(), nil }
+	t.Cleanup(func() { userHomeDir = original })
+
+	content := `verify:
+  - file: .env
+    command: "true"
+`
+	if err := os.WriteFile(filepath.Join(repoDir, ConfigFileName), []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := LoadConfig(repoDir, "")
+	if err == nil || !strings.Contains(err.Error(), "invalid verify check 1") {
+		t.Errorf("Expected invalid verify check error, got %v", err)
+	}
+}
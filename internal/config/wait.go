@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// DefaultWaitTimeout is how long a wait hook polls when neither its own 'timeout' nor
+// defaults.hook_timeout is set.
+const DefaultWaitTimeout = time.Minute
+
+// Kinds of condition a wait hook can wait for, as returned by WaitCondition.
+const (
+	WaitKindTCP  = "tcp"
+	WaitKindHTTP = "http"
+	WaitKindFile = "file"
+)
+
+// WaitCondition returns which condition a wait hook waits for and its (unexpanded) target.
+func (h *Hook) WaitCondition() (kind, target string) {
+	switch {
+	case h.TCP != "":
+		return WaitKindTCP, h.TCP
+	case h.HTTP != "":
+		return WaitKindHTTP, h.HTTP
+	default:
+		return WaitKindFile, h.File
+	}
+}
+
+// WaitTimeout returns how long a wait hook polls before giving up: its own 'timeout',
+// else defaults.hook_timeout, else DefaultWaitTimeout.
+func (c *Config) WaitTimeout(h *Hook) time.Duration {
+	if d := c.CommandTimeout(h); d > 0 {
+		return d
+	}
+	return DefaultWaitTimeout
+}
+
+func (h *Hook) validateWait() error {
+	if h.From != "" || h.To != "" || h.Command != "" {
+		return fmt.Errorf("wait hook should not have 'from', 'to', or 'command' fields")
+	}
+
+	count := 0
+	for _, target := range []string{h.TCP, h.HTTP, h.File} {
+		if target != "" {
+			count++
+		}
+	}
+	if count != 1 {
+		return fmt.Errorf("wait hook requires exactly one of 'tcp', 'http', or 'file' fields")
+	}
+
+	if h.TCP != "" {
+		if _, _, err := net.SplitHostPort(h.TCP); err != nil {
+			return fmt.Errorf("wait hook 'tcp' must be host:port: %w", err)
+		}
+	}
+	if h.HTTP != "" {
+		lower := strings.ToLower(h.HTTP)
+		if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") {
+			return fmt.Errorf("wait hook 'http' must be an http or https URL")
+		}
+	}
+	if _, err := parseHookTimeout(h.Timeout); err != nil {
+		return fmt.Errorf("invalid 'timeout': %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHook_ValidateWait(t *testing.T) {
+	tests := []struct {
+		name    string
+		hook    Hook
+		wantErr bool
+	}{
+		{"tcp", Hook{Type: HookTypeWait, TCP: "localhost:${PORT}"}, false},
+		{"http", Hook{Type: HookTypeWait, HTTP: "http://localhost:8080/health", Timeout: "2m"}, false},
+		{"file", Hook{Type: HookTypeWait, File: "tmp/ready"}, false},
+		{"no target", Hook{Type: HookTypeWait}, true},
+		{"two targets", Hook{Type: HookTypeWait, TCP: "localhost:5432", File: "ready"}, true},
+		{"tcp without port", Hook{Type: HookTypeWait, TCP: "localhost"}, true},
+		{"non-http url", Hook{Type: HookTypeWait, HTTP: "ftp://example.com"}, true},
+		{"bad timeout", Hook{Type: HookTypeWait, TCP: "localhost:5432", Timeout: "soon"}, true},
+		{"command field", Hook{Type: HookTypeWait, TCP: "localhost:5432", Command: "true"}, true},
+		{"tcp on command hook", Hook{Type: HookTypeCommand, Command: "true", TCP: "localhost:5432"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.hook.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_WaitTimeout(t *testing.T) {
+	cfg := &Config{}
+	hook := &Hook{Type: HookTypeWait, TCP: "localhost:5432"}
+	if got := cfg.WaitTimeout(hook); got != DefaultWaitTimeout {
+		t.Errorf("WaitTimeout() = %s, want %s", got, DefaultWaitTimeout)
+	}
+
+	cfg.Defaults.HookTimeout = "5m"
+	if got := cfg.WaitTimeout(hook); got != 5*time.Minute {
+		t.Errorf("WaitTimeout() = %s, want 5m", got)
+	}
+
+	hook.Timeout = "10s"
+	if got := cfg.WaitTimeout(hook); got != 10*time.Second {
+		t.Errorf("WaitTimeout() = %s, want 10s", got)
+	}
+}
@@ -0,0 +1,367 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// ConditionContext supplies the values a 'when' expression can refer to.
+type ConditionContext struct {
+	Branch string
+	OS     string
+	Arch   string
+	// Slug is the policy ${BRANCH_SLUG} is derived with; nil means the default.
+	Slug *SlugPolicy
+	// Getenv looks up environment variables; nil means no variables are set.
+	Getenv func(string) string
+}
+
+// Condition is a parsed 'when' expression.
+//
+// Grammar (operators bind in this order, loosest first):
+//
+//	expr    := and ('||' and)*
+//	and     := unary ('&&' unary)*
+//	unary   := '!' unary | '(' expr ')' | operand [('==' | '!=' | '=~' | '!~') operand]
+//	operand := "quoted" | 'quoted' | ${NAME} | os | arch | branch | env.NAME | bare-word
+//
+// ${BRANCH}, ${BRANCH_SLUG}, ${OS} and ${ARCH} are built in; any other ${NAME} reads the
+// environment. A lone operand is true when it is non-empty and not "false" or "0".
+type Condition struct {
+	source string
+	root   conditionNode
+}
+
+// ParseCondition parses a 'when' expression.
+func ParseCondition(source string) (*Condition, error) {
+	tokens, err := tokenizeCondition(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &conditionParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected %q in condition %q", p.peek().text, source)
+	}
+	return &Condition{source: source, root: root}, nil
+}
+
+// String returns the expression as written in the configuration.
+func (c *Condition) String() string {
+	return c.source
+}
+
+// Evaluate reports whether the condition holds in ctx.
+func (c *Condition) Evaluate(ctx ConditionContext) (bool, error) {
+	return c.root.eval(ctx)
+}
+
+type conditionTokenKind int
+
+const (
+	tokenOperand conditionTokenKind = iota
+	tokenOperator
+)
+
+type conditionToken struct {
+	kind   conditionTokenKind
+	text   string
+	quoted bool
+}
+
+var conditionOperators = []string{"==", "!=", "=~", "!~", "&&", "||", "!", "(", ")"}
+
+func tokenizeCondition(source string) ([]conditionToken, error) {
+	var tokens []conditionToken
+	for i := 0; i < len(source); {
+		ch := rune(source[i])
+		if unicode.IsSpace(ch) {
+			i++
+			continue
+		}
+
+		if ch == '"' || ch == '\'' {
+			end := strings.IndexRune(source[i+1:], ch)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string in condition %q", source)
+			}
+			tokens = append(tokens, conditionToken{kind: tokenOperand, text: source[i+1 : i+1+end], quoted: true})
+			i += end + 2 //nolint:mnd // both quotes
+			continue
+		}
+
+		if op := matchConditionOperator(source[i:]); op != "" {
+			tokens = append(tokens, conditionToken{kind: tokenOperator, text: op})
+			i += len(op)
+			continue
+		}
+
+		start := i
+		for i < len(source) && !unicode.IsSpace(rune(source[i])) && source[i] != '(' && source[i] != ')' {
+			if i > start && matchConditionOperator(source[i:]) != "" && source[i] != '!' {
+				break
+			}
+			i++
+		}
+		tokens = append(tokens, conditionToken{kind: tokenOperand, text: source[start:i]})
+	}
+
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty condition")
+	}
+	return tokens, nil
+}
+
+func matchConditionOperator(s string) string {
+	for _, op := range conditionOperators {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+type conditionParser struct {
+	tokens []conditionToken
+	pos    int
+}
+
+func (p *conditionParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *conditionParser) peek() conditionToken {
+	return p.tokens[p.pos]
+}
+
+func (p *conditionParser) acceptOperator(op string) bool {
+	if !p.done() && p.peek().kind == tokenOperator && p.peek().text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *conditionParser) parseOr() (conditionNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOperator("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{or: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseAnd() (conditionNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOperator("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseUnary() (conditionNode, error) {
+	if p.done() {
+		return nil, fmt.Errorf("unexpected end of condition")
+	}
+	if p.acceptOperator("!") {
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{inner: inner}, nil
+	}
+	if p.acceptOperator("(") {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.acceptOperator(")") {
+			return nil, fmt.Errorf("missing ')' in condition")
+		}
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *conditionParser) parseComparison() (conditionNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if p.done() || p.peek().kind != tokenOperator {
+		return &truthyNode{value: left}, nil
+	}
+
+	op := p.peek().text
+	switch op {
+	case "==", "!=", "=~", "!~":
+		p.pos++
+	default:
+		return &truthyNode{value: left}, nil
+	}
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	node := &compareNode{op: op, left: left, right: right}
+	if (op == "=~" || op == "!~") && right.literal {
+		if node.pattern, err = regexp.Compile(right.text); err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %w", right.text, err)
+		}
+	}
+	return node, nil
+}
+
+func (p *conditionParser) parseOperand() (operand, error) {
+	if p.done() {
+		return operand{}, fmt.Errorf("expected a value at end of condition")
+	}
+	tok := p.peek()
+	if tok.kind != tokenOperand {
+		return operand{}, fmt.Errorf("expected a value but found %q", tok.text)
+	}
+	p.pos++
+	if tok.quoted {
+		return operand{text: tok.text, literal: !strings.Contains(tok.text, "${")}, nil
+	}
+	switch {
+	case tok.text == "os", tok.text == "arch", tok.text == "branch", strings.HasPrefix(tok.text, "env."):
+		return operand{text: tok.text, identifier: true}, nil
+	default:
+		return operand{text: tok.text, literal: !strings.Contains(tok.text, "${")}, nil
+	}
+}
+
+type conditionNode interface {
+	eval(ctx ConditionContext) (bool, error)
+}
+
+type operand struct {
+	text       string
+	identifier bool
+	literal    bool // no identifiers or ${...} references, value is known at parse time
+}
+
+var conditionVariablePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+func (o operand) resolve(ctx ConditionContext) string {
+	if o.identifier {
+		switch o.text {
+		case "os":
+			return ctx.OS
+		case "arch":
+			return ctx.Arch
+		case "branch":
+			return ctx.Branch
+		default:
+			return ctx.lookupEnv(strings.TrimPrefix(o.text, "env."))
+		}
+	}
+	if o.literal {
+		return o.text
+	}
+	return conditionVariablePattern.ReplaceAllStringFunc(o.text, func(ref string) string {
+		name := conditionVariablePattern.FindStringSubmatch(ref)[1]
+		switch name {
+		case "BRANCH":
+			return ctx.Branch
+		case "BRANCH_SLUG":
+			return ctx.Slug.Slugify(ctx.Branch)
+		case "OS":
+			return ctx.OS
+		case "ARCH":
+			return ctx.Arch
+		default:
+			return ctx.lookupEnv(name)
+		}
+	})
+}
+
+func (ctx ConditionContext) lookupEnv(name string) string {
+	if ctx.Getenv == nil {
+		return ""
+	}
+	return ctx.Getenv(name)
+}
+
+type truthyNode struct {
+	value operand
+}
+
+func (n *truthyNode) eval(ctx ConditionContext) (bool, error) {
+	v := n.value.resolve(ctx)
+	return v != "" && v != "false" && v != "0", nil
+}
+
+type notNode struct {
+	inner conditionNode
+}
+
+func (n *notNode) eval(ctx ConditionContext) (bool, error) {
+	v, err := n.inner.eval(ctx)
+	return !v, err
+}
+
+type logicalNode struct {
+	or          bool
+	left, right conditionNode
+}
+
+func (n *logicalNode) eval(ctx ConditionContext) (bool, error) {
+	left, err := n.left.eval(ctx)
+	if err != nil {
+		return false, err
+	}
+	if left == n.or {
+		return left, nil
+	}
+	return n.right.eval(ctx)
+}
+
+type compareNode struct {
+	op          string
+	left, right operand
+	pattern     *regexp.Regexp
+}
+
+func (n *compareNode) eval(ctx ConditionContext) (bool, error) {
+	left := n.left.resolve(ctx)
+	switch n.op {
+	case "==":
+		return left == n.right.resolve(ctx), nil
+	case "!=":
+		return left != n.right.resolve(ctx), nil
+	}
+
+	pattern := n.pattern
+	if pattern == nil {
+		var err error
+		if pattern, err = regexp.Compile(n.right.resolve(ctx)); err != nil {
+			return false, fmt.Errorf("invalid regular expression: %w", err)
+		}
+	}
+	matched := pattern.MatchString(left)
+	if n.op == "!~" {
+		return !matched, nil
+	}
+	return matched, nil
+}
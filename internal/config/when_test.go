@@ -0,0 +1,132 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCondition_Evaluate(t *testing.T) {
+	ctx := ConditionContext{
+		Branch: "feature/login",
+		OS:     "darwin",
+		Arch:   "arm64",
+		Getenv: func(name string) string {
+			return map[string]string{"CI": "true", "EMPTY": "", "DISABLED": "0"}[name]
+		},
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`${BRANCH} =~ ^feature/`, true},
+		{`${BRANCH} =~ ^fix/`, false},
+		{`${BRANCH} !~ ^fix/`, true},
+		{`branch == "feature/login"`, true},
+		{`${BRANCH_SLUG} == feature-login`, true},
+		{`os == "darwin"`, true},
+		{`os == 'linux'`, false},
+		{`os != darwin`, false},
+		{`arch == arm64 && os == darwin`, true},
+		{`os == linux || ${ARCH} == arm64`, true},
+		{`!(os == linux)`, true},
+		{`${CI}`, true},
+		{`env.CI == true`, true},
+		{`${EMPTY}`, false},
+		{`${DISABLED}`, false},
+		{`!${UNSET}`, true},
+		{`os == linux || branch =~ "^(feature|fix)/" && ${CI}`, true},
+		{`(os == linux || branch =~ "^(feature|fix)/") && ${EMPTY}`, false},
+		{`"${BRANCH}-x" == "feature/login-x"`, true},
+		{`env.CI==true`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			cond, err := ParseCondition(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCondition(%q) failed: %v", tt.expr, err)
+			}
+			got, err := cond.Evaluate(ctx)
+			if err != nil {
+				t.Fatalf("Evaluate(%q) failed: %v", tt.expr, err)
+			}
+			if got != tt.want {
+				t.Errorf("Evaluate(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCondition_Errors(t *testing.T) {
+	tests := []string{
+		"",
+		"   ",
+		`os == "darwin`,
+		"os ==",
+		"(os == darwin",
+		"os == darwin)",
+		"&& os",
+		`branch =~ "["`,
+	}
+
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := ParseCondition(expr); err == nil {
+				t.Errorf("ParseCondition(%q) expected error", expr)
+			}
+		})
+	}
+}
+
+func TestCondition_DynamicRegexError(t *testing.T) {
+	cond, err := ParseCondition(`branch =~ ${PATTERN}`)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	ctx := ConditionContext{Getenv: func(string) string { return "[" }}
+	if _, err := cond.Evaluate(ctx); err == nil {
+		t.Error("expected error for invalid regular expression from environment")
+	}
+}
+
+func TestHookValidate_When(t *testing.T) {
+	valid := Hook{Type: HookTypeCommand, Command: "brew bundle", When: `os == "darwin"`}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("expected valid hook, got %v", err)
+	}
+
+	invalid := Hook{Type: HookTypeCommand, Command: "brew bundle", When: `os ==`}
+	if err := invalid.Validate(); err == nil {
+		t.Error("expected error for malformed condition")
+	}
+}
+
+func TestHookValidate_OS(t *testing.T) {
+	valid := Hook{Type: HookTypeCommand, Command: "brew bundle", OS: []string{OSDarwin, OSLinux}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("expected valid hook, got %v", err)
+	}
+
+	macos := Hook{Type: HookTypeCommand, Command: "brew bundle", OS: []string{"macos"}}
+	if err := macos.Validate(); err == nil || !strings.Contains(err.Error(), "use 'darwin' for macOS") {
+		t.Errorf("expected a hint to use darwin, got %v", err)
+	}
+
+	unknown := Hook{Type: HookTypeCommand, Command: "brew bundle", OS: []string{"plan9"}}
+	if err := unknown.Validate(); err == nil || !strings.Contains(err.Error(), "invalid 'os'") {
+		t.Errorf("expected error for unknown operating system, got %v", err)
+	}
+}
+
+func TestHook_RunsOn(t *testing.T) {
+	everywhere := Hook{Type: HookTypeCommand, Command: "make"}
+	if !everywhere.RunsOn(OSWindows) {
+		t.Error("expected a hook without 'os' to run everywhere")
+	}
+
+	unix := Hook{Type: HookTypeCommand, Command: "make", OS: []string{OSLinux, OSDarwin}}
+	if !unix.RunsOn(OSDarwin) || unix.RunsOn(OSWindows) {
+		t.Errorf("expected %v to run on darwin only of darwin and windows", unix.OS)
+	}
+}
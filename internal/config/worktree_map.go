@@ -0,0 +1,75 @@
+package config
+
+import "fmt"
+
+// Formats of the branch-to-directory map 'wtp map' exports
+const (
+	MapFormatJSON      = "json"
+	MapFormatEnv       = "env"
+	MapFormatGitConfig = "gitconfig"
+)
+
+// MapFormats lists the formats of the branch-to-directory map, the default first.
+var MapFormats = []string{MapFormatJSON, MapFormatEnv, MapFormatGitConfig}
+
+// WorktreeMap keeps an export of which directory every branch is checked out in up to
+// date for other tools, such as build systems and IDE run configurations. It is rewritten
+// whenever a wtp command adds, moves, or removes a worktree or switches its branch.
+type WorktreeMap struct {
+	// File receives the map; a relative path is relative to the main worktree. Empty
+	// writes no file.
+	File string `yaml:"file,omitempty"`
+	// Format of File: json (the default), env, or gitconfig.
+	Format string `yaml:"format,omitempty"`
+	// GitConfig also keeps a wtp-worktree.<branch>.path key per branch in the repository's
+	// git config, where 'git config --get-regexp' finds them.
+	GitConfig bool `yaml:"git_config,omitempty"`
+}
+
+// Enabled reports whether the map is exported anywhere.
+func (m *WorktreeMap) Enabled() bool {
+	return m.File != "" || m.GitConfig
+}
+
+// FileFormat returns the format of File.
+func (m *WorktreeMap) FileFormat() string {
+	if m.Format == "" {
+		return MapFormatJSON
+	}
+	return m.Format
+}
+
+func (m *WorktreeMap) validate() error {
+	if err := ValidateMapFormat(m.Format); err != nil {
+		return fmt.Errorf("invalid map.format: %w", err)
+	}
+	if m.Format != "" && m.File == "" {
+		return fmt.Errorf("invalid map.format: set map.file to the file to write")
+	}
+	return nil
+}
+
+// ValidateMapFormat checks that format is one of MapFormats; empty means the default.
+func ValidateMapFormat(format string) error {
+	switch format {
+	case "", MapFormatJSON, MapFormatEnv, MapFormatGitConfig:
+		return nil
+	default:
+		return fmt.Errorf("unsupported format '%s': must be json, env, or gitconfig", format)
+	}
+}
+
+// mergeWorktreeMap applies the fields override sets on top of base.
+func mergeWorktreeMap(base, override WorktreeMap) WorktreeMap {
+	result := base
+	if override.File != "" {
+		result.File = override.File
+	}
+	if override.Format != "" {
+		result.Format = override.Format
+	}
+	if override.GitConfig {
+		result.GitConfig = true
+	}
+	return result
+}
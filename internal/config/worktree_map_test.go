@@ -0,0 +1,32 @@
+package config
+
+import "testing"
+
+func TestConfig_WorktreeMap(t *testing.T) {
+	if (&WorktreeMap{}).Enabled() {
+		t.Error("an empty map section should export nothing")
+	}
+
+	base := &Config{Map: WorktreeMap{File: ".wtp/map.json"}}
+	merged := MergeConfig(base, &Config{Map: WorktreeMap{GitConfig: true}})
+	if !merged.Map.Enabled() || merged.Map.File != ".wtp/map.json" || !merged.Map.GitConfig {
+		t.Errorf("merged map = %+v, want the file and git_config", merged.Map)
+	}
+	if merged.Map.FileFormat() != MapFormatJSON {
+		t.Errorf("FileFormat() = %q, want json", merged.Map.FileFormat())
+	}
+
+	for _, m := range []WorktreeMap{{File: "map.txt", Format: "xml"}, {Format: MapFormatEnv}} {
+		cfg := &Config{Defaults: Defaults{BaseDir: DefaultBaseDir}, Map: m}
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Validate() should reject %+v", m)
+		}
+	}
+	valid := &Config{
+		Defaults: Defaults{BaseDir: DefaultBaseDir},
+		Map:      WorktreeMap{File: ".env.worktrees", Format: MapFormatEnv},
+	}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Code is a stable identifier attached to wtp errors and warnings, such as "WTP1001".
+// Codes are never reused or renumbered, so scripts and documentation can rely on them.
+//
+// Ranges: 1xxx environment, git, and command-line usage, 2xxx worktrees, 3xxx branches, 4xxx configuration
+// and policy, 5xxx hooks, 6xxx shell integration, 7xxx warnings.
+type Code string
+
+// Error codes.
+const (
+	CodeUnclassified                Code = "WTP1000"
+	CodeNotInGitRepository          Code = "WTP1001"
+	CodeGitCommandFailed            Code = "WTP1002"
+	CodeDirectoryAccessFailed       Code = "WTP1003"
+	CodeReadOnlyMode                Code = "WTP1004"
+	CodeOperationTimedOut           Code = "WTP1005"
+	CodeRepositoryBusy              Code = "WTP1006"
+	CodeInvalidArguments            Code = "WTP1007"
+	CodeTerminalRequired            Code = "WTP1008"
+	CodeWorktreeNameRequired        Code = "WTP2001"
+	CodeWorktreeNotFound            Code = "WTP2002"
+	CodeWorktreeCreationFailed      Code = "WTP2003"
+	CodeWorktreeRemovalFailed       Code = "WTP2004"
+	CodeCannotRemoveCurrentWorktree Code = "WTP2005"
+	CodeWorktreeRelocationFailed    Code = "WTP2006"
+	CodeWorktreePathInOtherClone    Code = "WTP2007"
+	CodeVerificationFailed          Code = "WTP2008"
+	CodeWorktreeAlreadyExists       Code = "WTP2009"
+	CodePathAlreadyExists           Code = "WTP2010"
+	CodeAmbiguousWorktree           Code = "WTP2011"
+	CodeExecFailed                  Code = "WTP2012"
+	CodeNestedWorktree              Code = "WTP2013"
+	CodeLayoutMigrationFailed       Code = "WTP2014"
+	CodeWorktreeLocked              Code = "WTP2015"
+	CodeUnconfirmedWorktreeMatch    Code = "WTP2016"
+	CodeNotInWorktree               Code = "WTP2017"
+	CodeMainWorktreeUnsupported     Code = "WTP2018"
+	CodeBranchNameRequired          Code = "WTP3001"
+	CodeInvalidBranchName           Code = "WTP3002"
+	CodeBranchRemovalFailed         Code = "WTP3003"
+	CodeBranchNotFound              Code = "WTP3004"
+	CodeMultipleBranchesFound       Code = "WTP3005"
+	CodeBranchAlreadyExists         Code = "WTP3006"
+	CodeRefNotFound                 Code = "WTP3007"
+	CodeConfigLoadFailed            Code = "WTP4001"
+	CodeConfigAlreadyExists         Code = "WTP4002"
+	CodeWorktreeLimitReached        Code = "WTP4003"
+	CodeBranchPrefixRequired        Code = "WTP4004"
+	CodeUnknownProfile              Code = "WTP4005"
+	CodeHookExecutionFailed         Code = "WTP5001"
+	CodePreRemoveHookFailed         Code = "WTP5002"
+	CodeMaintenanceHooksFailed      Code = "WTP5003"
+	CodeCleanHooksFailed            Code = "WTP5004"
+	CodeShellIntegrationRequired    Code = "WTP6001"
+	CodeUnsupportedShell            Code = "WTP6002"
+	CodeWarnPostCreateHookFailed    Code = "WTP7001"
+	CodeWarnProvisionRecordFailed   Code = "WTP7002"
+	CodeWarnVerifyChecksFailed      Code = "WTP7003"
+	CodeWarnMaintenanceHooksFailed  Code = "WTP7004"
+	CodeWarnPreRemoveHookFailed     Code = "WTP7005"
+	CodeWarnWorktreeMoveFailed      Code = "WTP7006"
+	CodeWarnPolicySkipped           Code = "WTP7007"
+	CodeWarnNoTimingHistory         Code = "WTP7008"
+	CodeWarnPostCheckoutHookFailed  Code = "WTP7009"
+	CodeWarnAfterAddFailed          Code = "WTP7010"
+	CodeWarnCacheOverBudget         Code = "WTP7011"
+	CodeWarnWorktreeMapFailed       Code = "WTP7012"
+	CodeWarnNestedWorktree          Code = "WTP7013"
+	CodeWarnSymlinkRepointFailed    Code = "WTP7014"
+	CodeWarnPostRemoveHookFailed    Code = "WTP7015"
+	CodeWarnCleanHooksFailed        Code = "WTP7016"
+	CodeWarnHookFailed              Code = "WTP7017"
+)
+
+// codePrefix starts every code; 'wtp explain' accepts codes without it.
+const codePrefix = "WTP"
+
+// Explanation is the long-form help 'wtp explain' prints for a code.
+type Explanation struct {
+	Summary string
+	Causes  []string
+	Fixes   []string
+}
+
+// codedError is an error message tagged with its code.
+type codedError struct {
+	code Code
+	msg  string
+}
+
+func (e *codedError) Error() string { return e.msg }
+
+// ErrorCode returns the code the error was created with.
+func (e *codedError) ErrorCode() Code { return e.code }
+
+// withCode returns an error with message msg tagged with code.
+func withCode(code Code, msg string) error {
+	return &codedError{code: code, msg: msg}
+}
+
+// CodeOf returns the code of err or of an error it wraps, and false when none has one.
+// Any error type can take part by implementing 'ErrorCode() Code'.
+func CodeOf(err error) (Code, bool) {
+	var coded interface{ ErrorCode() Code }
+	if errors.As(err, &coded) {
+		return coded.ErrorCode(), true
+	}
+	return "", false
+}
+
+// CodeFor returns the code of err like CodeOf, and CodeUnclassified when it has none, so
+// that every error wtp reports carries a code.
+func CodeFor(err error) Code {
+	if code, ok := CodeOf(err); ok {
+		return code
+	}
+	return CodeUnclassified
+}
+
+// Format renders err for the terminal: it is prefixed with its code (see CodeFor) and ends
+// with a pointer to 'wtp explain'.
+func Format(err error) string {
+	code := CodeFor(err)
+	return fmt.Sprintf("[%s] %s\n\nRun 'wtp explain %s' for causes and fixes", code, err.Error(), code)
+}
+
+// Warning formats a warning line (without a trailing newline) tagged with code.
+func Warning(code Code, format string, args ...any) string {
+	return fmt.Sprintf("Warning [%s]: %s", code, fmt.Sprintf(format, args...))
+}
+
+// ParseCode normalizes user input such as "wtp1001" or "1001" to a registered code.
+func ParseCode(s string) (Code, bool) {
+	code := Code(strings.ToUpper(strings.TrimSpace(s)))
+	if !strings.HasPrefix(string(code), codePrefix) {
+		code = codePrefix + code
+	}
+	_, ok := registry[code]
+	return code, ok
+}
+
+// Explain returns the registered explanation for code.
+func Explain(code Code) (Explanation, bool) {
+	explanation, ok := registry[code]
+	return explanation, ok
+}
+
+// Codes returns every registered code in ascending order.
+func Codes() []Code {
+	codes := make([]Code, 0, len(registry))
+	for code := range registry {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+	return codes
+}
@@ -0,0 +1,115 @@
+package errors
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodesAreRegistered(t *testing.T) {
+	gitErr := fmt.Errorf("boom")
+	constructed := []error{
+		NotInGitRepository(),
+		InvalidArguments("--a cannot be combined with --b"),
+		TerminalRequired("wtp switch", "use 'wtp cd' in scripts"),
+		NotInWorktree("pass a worktree name"),
+		MainWorktreeUnsupported("lock", "it is the repository itself"),
+		GitCommandFailed("git status", ""),
+		BranchNameRequired("wtp add"),
+		WorktreeNameRequiredForRemove(),
+		InvalidBranchName("a..b"),
+		WorktreeNotFound("x", nil),
+		AmbiguousWorktree("x", []string{"x1", "x2"}),
+		UnconfirmedWorktreeMatch("x", "ax"),
+		WorktreeCreationFailed("/p", "b", gitErr),
+		WorktreeRemovalFailed("/p", gitErr),
+		CannotRemoveCurrentWorktree("x", "/p"),
+		WorktreeLocked("x", ""),
+		BranchRemovalFailed("b", gitErr, false),
+		PreRemoveHookFailed("x", gitErr),
+		MaintenanceHooksFailed([]string{"x"}),
+		CleanHooksFailed([]string{"x"}),
+		WorktreeRelocationFailed([]string{"x"}),
+		ExecFailed([]string{"x"}),
+		OperationTimedOut("wtp exec", time.Minute, []string{"x"}),
+		RepositoryBusy("wtp add", time.Minute, "/p/.git/wtp/operation.lock"),
+		WorktreePathInOtherClone("/p", "/other"),
+		NestedWorktree("/p", "inside the git repository /"),
+		LayoutMigrationFailed("x", gitErr, nil),
+		WorktreeLimitReached(1, 1, false),
+		BranchPrefixRequired("b", []string{"feature/"}, false),
+		UnknownProfile("x", nil),
+		VerificationFailed("x", 1, 1),
+		ReadOnlyMode("remove worktrees", "WTP_READONLY"),
+		ConfigLoadFailed("/p/.wtp.yml", gitErr),
+		ConfigAlreadyExists("/p/.wtp.yml"),
+		DirectoryAccessFailed("read", "/p", gitErr),
+		ShellIntegrationRequired(),
+		UnsupportedShell("csh", nil),
+		BranchNotFound("b"),
+		RefNotFound("v1"),
+		MultipleBranchesFound("b", []string{"origin", "upstream"}),
+		HookExecutionFailed(0, "command", gitErr),
+	}
+
+	seen := map[Code]bool{}
+	for _, err := range constructed {
+		code, ok := CodeOf(err)
+		require.True(t, ok, "error has no code: %v", err)
+		_, registered := Explain(code)
+		assert.True(t, registered, "code %s is not registered", code)
+		assert.False(t, seen[code], "code %s is used by two constructors", code)
+		seen[code] = true
+	}
+}
+
+func TestRegistryEntriesAreComplete(t *testing.T) {
+	pattern := regexp.MustCompile(`^WTP[1-7]\d{3}$`)
+	for _, code := range Codes() {
+		explanation, _ := Explain(code)
+		assert.Regexp(t, pattern, string(code))
+		assert.NotEmpty(t, explanation.Summary, code)
+		assert.NotEmpty(t, explanation.Causes, code)
+		assert.NotEmpty(t, explanation.Fixes, code)
+	}
+}
+
+func TestCodeOf_Wrapped(t *testing.T) {
+	err := fmt.Errorf("while adding: %w", BranchNotFound("feature/x"))
+
+	code, ok := CodeOf(err)
+	assert.True(t, ok)
+	assert.Equal(t, CodeBranchNotFound, code)
+
+	_, ok = CodeOf(fmt.Errorf("plain"))
+	assert.False(t, ok)
+}
+
+func TestFormat(t *testing.T) {
+	formatted := Format(NotInGitRepository())
+	assert.Regexp(t, `^\[WTP1001\] not in a git repository`, formatted)
+	assert.Contains(t, formatted, "Run 'wtp explain WTP1001' for causes and fixes")
+
+	assert.Equal(t, "[WTP1000] plain\n\nRun 'wtp explain WTP1000' for causes and fixes", Format(fmt.Errorf("plain")),
+		"an error without a code of its own is unclassified")
+}
+
+func TestWarning(t *testing.T) {
+	assert.Equal(t, "Warning [WTP7007]: skipping 2 checks",
+		Warning(CodeWarnPolicySkipped, "skipping %d checks", 2))
+}
+
+func TestParseCode(t *testing.T) {
+	for _, input := range []string{"WTP5001", "wtp5001", " 5001 "} {
+		code, ok := ParseCode(input)
+		assert.True(t, ok, input)
+		assert.Equal(t, CodeHookExecutionFailed, code, input)
+	}
+
+	_, ok := ParseCode("WTP9999")
+	assert.False(t, ok)
+}
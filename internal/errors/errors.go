@@ -2,9 +2,9 @@
 package errors
 
 import (
-	"errors"
 	"fmt"
 	"strings"
+	"time"
 )
 
 // NotInGitRepository returns an error indicating the command must run inside a Git repository.
@@ -15,7 +15,7 @@ Solutions:
   • Run 'git init' to create a new repository
   • Navigate to an existing git repository
   • Check if you're in the correct directory`
-	return errors.New(msg)
+	return withCode(CodeNotInGitRepository, msg)
 }
 
 // GitCommandFailed wraps an error from a git command with the command and output details.
@@ -31,7 +31,7 @@ func GitCommandFailed(command, output string) error {
 Details: %s
 
 Tip: Try running the git command manually to see the full error`, command, cleanOutput)
-	return errors.New(msg)
+	return withCode(CodeGitCommandFailed, msg)
 }
 
 // BranchNameRequired reports that a branch name argument is missing.
@@ -43,8 +43,32 @@ Usage: %s
 Examples:
   • wtp add feature/auth
   • wtp add -b new-feature
-  • wtp add --track origin/main main`, commandExample)
-	return errors.New(msg)
+  • wtp add origin/feature/auth`, commandExample)
+	return withCode(CodeBranchNameRequired, msg)
+}
+
+// InvalidArguments reports arguments or flags a command cannot run with: missing ones,
+// flags that cannot be combined, or values out of range.
+func InvalidArguments(format string, args ...any) error {
+	return withCode(CodeInvalidArguments, fmt.Sprintf(format, args...))
+}
+
+// TerminalRequired reports that what needs an interactive terminal, e.g. to confirm a
+// change; alternative says how to do without one.
+func TerminalRequired(what, alternative string) error {
+	return withCode(CodeTerminalRequired, fmt.Sprintf("%s needs an interactive terminal; %s", what, alternative))
+}
+
+// NotInWorktree reports that a command defaulting to the current worktree ran outside
+// every worktree; alternative says how to name one instead.
+func NotInWorktree(alternative string) error {
+	return withCode(CodeNotInWorktree, "the current directory is not inside a worktree; "+alternative)
+}
+
+// MainWorktreeUnsupported reports that action, e.g. "hibernate", does not apply to the
+// main worktree, for reason.
+func MainWorktreeUnsupported(action, reason string) error {
+	return withCode(CodeMainWorktreeUnsupported, fmt.Sprintf("cannot %s the main worktree: %s", action, reason))
 }
 
 // WorktreeNameRequiredForRemove reports that a worktree name is required for removal.
@@ -59,7 +83,7 @@ Examples:
   • wtp remove --force feature/auth
 
 Tip: Run 'wtp list' to see available worktrees`
-	return errors.New(msg)
+	return withCode(CodeWorktreeNameRequired, msg)
 }
 
 // InvalidBranchName reports that the provided branch name violates git naming rules.
@@ -72,7 +96,7 @@ Branch names cannot contain:
   • Control characters
 
 See 'git check-ref-format --help' for full rules`, branchName)
-	return errors.New(msg)
+	return withCode(CodeInvalidBranchName, msg)
 }
 
 // WorktreeNotFound returns an error when the requested worktree is not present.
@@ -89,7 +113,26 @@ func WorktreeNotFound(name string, availableWorktrees []string) error {
 	}
 
 	msg += "\n\nTip: Run 'wtp list' to see all worktrees"
-	return errors.New(msg)
+	return withCode(CodeWorktreeNotFound, msg)
+}
+
+// AmbiguousWorktree returns an error when a loose name matches more than one worktree.
+func AmbiguousWorktree(name string, candidates []string) error {
+	msg := fmt.Sprintf("worktree name '%s' is ambiguous", name)
+	msg += "\n\nMatching worktrees:"
+	for _, candidate := range candidates {
+		msg += fmt.Sprintf("\n  • %s", candidate)
+	}
+	msg += "\n\nTip: Type more of the branch name to pick one"
+	return withCode(CodeAmbiguousWorktree, msg)
+}
+
+// UnconfirmedWorktreeMatch returns an error when a loose name matches one worktree, which
+// is about to be removed or relocated, and the match was not confirmed.
+func UnconfirmedWorktreeMatch(name, candidate string) error {
+	msg := fmt.Sprintf("worktree name '%s' only loosely matches '%s', which was not confirmed", name, candidate)
+	msg += "\n\nTip: Use the worktree's name or a prefix of it, or confirm the match on an interactive terminal"
+	return withCode(CodeUnconfirmedWorktreeMatch, msg)
 }
 
 // WorktreeCreationFailed wraps a git error encountered while creating a worktree.
@@ -121,7 +164,7 @@ Solutions:
 	}
 
 	msg += fmt.Sprintf("\n\nOriginal error: %v", gitError)
-	return errors.New(msg)
+	return withCode(CodeWorktreeCreationFailed, msg)
 }
 
 // WorktreeRemovalFailed returns a descriptive error when a worktree cannot be removed.
@@ -157,7 +200,7 @@ func WorktreeRemovalFailed(path string, gitError error) error {
 	}
 
 	msg += fmt.Sprintf("\n\nOriginal error: %v", gitError)
-	return errors.New(msg)
+	return withCode(CodeWorktreeRemovalFailed, msg)
 }
 
 // CannotRemoveCurrentWorktree indicates the user is trying to delete the active worktree.
@@ -165,7 +208,22 @@ func CannotRemoveCurrentWorktree(worktreeName, path string) error {
 	msg := fmt.Sprintf("cannot remove worktree '%s' while you are currently inside it", worktreeName)
 	msg += fmt.Sprintf("\n\nCurrent location: %s", path)
 	msg += "\n\nTip: Run 'wtp cd @' or 'wtp cd <another-worktree>' to switch before removing."
-	return errors.New(msg)
+	return withCode(CodeCannotRemoveCurrentWorktree, msg)
+}
+
+// WorktreeLocked reports that the worktree to remove is locked; reason is the lock's reason
+// and may be empty.
+func WorktreeLocked(worktreeName, reason string) error {
+	msg := fmt.Sprintf("worktree '%s' is locked", worktreeName)
+	if reason != "" {
+		msg += fmt.Sprintf("\n\nReason: %s", reason)
+	}
+	msg += fmt.Sprintf(`
+
+Solutions:
+  • Run 'wtp unlock %s' first
+  • Use '--force' to remove it anyway`, worktreeName)
+	return withCode(CodeWorktreeLocked, msg)
 }
 
 // BranchRemovalFailed wraps errors that occur when deleting a git branch.
@@ -191,7 +249,218 @@ Solution: Switch to a different branch first`
 	}
 
 	msg += fmt.Sprintf("\n\nOriginal error: %v", gitError)
-	return errors.New(msg)
+	return withCode(CodeBranchRemovalFailed, msg)
+}
+
+// PreRemoveHookFailed reports a pre_remove hook failure that prevented a worktree from being removed.
+func PreRemoveHookFailed(worktreeName string, hookError error) error {
+	msg := fmt.Sprintf("pre-remove hooks failed for worktree '%s'; the worktree was not removed", worktreeName)
+	msg += `
+
+Solutions:
+  • Fix the failing hook under 'hooks.pre_remove' in .wtp.yml
+  • Use '--force' to remove the worktree anyway`
+	msg += fmt.Sprintf("\n\nOriginal error: %v", hookError)
+	return withCode(CodePreRemoveHookFailed, msg)
+}
+
+// MaintenanceHooksFailed reports the worktrees whose maintenance hooks failed during 'wtp maintain'.
+func MaintenanceHooksFailed(worktreeNames []string) error {
+	msg := fmt.Sprintf("maintenance hooks failed in %d worktree(s): %s",
+		len(worktreeNames), strings.Join(worktreeNames, ", "))
+	msg += `
+
+Solutions:
+  • Fix the failing hook under 'hooks.maintenance' in .wtp.yml
+  • Run 'wtp maintain' again; the interval is not reset after a failure`
+	return withCode(CodeMaintenanceHooksFailed, msg)
+}
+
+// CleanHooksFailed reports the worktrees whose clean hooks failed during 'wtp clean'.
+func CleanHooksFailed(worktreeNames []string) error {
+	msg := fmt.Sprintf("clean hooks failed in %d worktree(s): %s",
+		len(worktreeNames), strings.Join(worktreeNames, ", "))
+	msg += `
+
+Solutions:
+  • Fix the failing hook under 'hooks.clean' in .wtp.yml
+  • Run 'wtp clean <worktree>' again for the worktrees that failed`
+	return withCode(CodeCleanHooksFailed, msg)
+}
+
+// ExecFailed reports the worktrees in which the command of 'wtp exec' failed.
+func ExecFailed(worktreeNames []string) error {
+	msg := fmt.Sprintf("command failed in %d worktree(s): %s",
+		len(worktreeNames), strings.Join(worktreeNames, ", "))
+	msg += `
+
+Solutions:
+  • Check the output above, prefixed with the worktree's name
+  • Re-run it in one worktree with 'wtp exec --branch <branch> -- <command>'`
+	return withCode(CodeExecFailed, msg)
+}
+
+// WorktreeRelocationFailed reports the worktrees 'wtp relink --relocate' could not move.
+func WorktreeRelocationFailed(worktreeNames []string) error {
+	msg := fmt.Sprintf("failed to relocate %d worktree(s): %s",
+		len(worktreeNames), strings.Join(worktreeNames, ", "))
+	msg += `
+
+Solutions:
+  • Check that the target paths are free and writable
+  • Move the worktree manually with 'git worktree move <old> <new>'
+  • Run 'wtp relink --relocate' again; worktrees already moved are skipped`
+	return withCode(CodeWorktreeRelocationFailed, msg)
+}
+
+// WorktreePathInOtherClone reports that the path 'wtp add' resolved already holds a worktree
+// of another clone, typically because two clones of the same repository share a base_dir.
+func WorktreePathInOtherClone(path, ownerRepo string) error {
+	msg := fmt.Sprintf("worktree path '%s' belongs to another clone: %s", path, ownerRepo)
+	msg += `
+
+Solutions:
+  • Include the clone in base_dir, e.g. base_dir: "../worktrees/${DIRNAME}" or "${PATHNAME}-worktrees"
+  • Set a per-clone base_dir in .wtp.local.yml
+  • Run 'wtp list' in the other clone to see its worktrees`
+	return withCode(CodeWorktreePathInOtherClone, msg)
+}
+
+// NestedWorktree reports that 'wtp add' refused to create a worktree inside another
+// checkout, described by where, because defaults.nested_worktrees is "error".
+func NestedWorktree(path, where string) error {
+	msg := fmt.Sprintf("worktree path '%s' is %s", path, where)
+	msg += `
+
+Nested checkouts confuse git (untracked directories, 'git clean', submodule detection) and
+tools that scan the directory tree, such as file watchers, test runners, and search.
+
+Solutions:
+  • Point base_dir outside every checkout, e.g. base_dir: "../worktrees"
+  • Set defaults.nested_worktrees to 'warn' or 'allow' if the layout is intended`
+	return withCode(CodeNestedWorktree, msg)
+}
+
+// LayoutMigrationFailed reports that 'wtp migrate-layout' could not move worktreeName. The
+// worktrees moved before it were moved back, except those listed in stranded.
+func LayoutMigrationFailed(worktreeName string, cause error, stranded []string) error {
+	msg := fmt.Sprintf("failed to move %s to the new layout: %v", worktreeName, cause)
+	if len(stranded) == 0 {
+		msg += "\n\nThe worktrees moved before it were moved back; nothing changed."
+	} else {
+		msg += fmt.Sprintf("\n\nThese worktrees could not be moved back and stay at their new path: %s",
+			strings.Join(stranded, ", "))
+	}
+	msg += `
+
+Solutions:
+  • Check that the target path is free and writable, and that the worktree is not locked
+  • Run 'wtp migrate-layout --dry-run' to review the moves, then run it again`
+	return withCode(CodeLayoutMigrationFailed, msg)
+}
+
+// WorktreeLimitReached reports that 'wtp add' was refused by policy.max_worktrees_per_repo.
+func WorktreeLimitReached(count, limit int, canOverride bool) error {
+	msg := fmt.Sprintf("policy violation: the repository already has %d of at most %d worktrees", count, limit)
+	msg += `
+
+Solutions:
+  • Remove worktrees you no longer need ('wtp list', then 'wtp remove <name>')
+  • Raise 'policy.max_worktrees_per_repo' in .wtp.yml`
+	return withCode(CodeWorktreeLimitReached, msg+policyOverrideHint(canOverride))
+}
+
+// BranchPrefixRequired reports that 'wtp add' was refused by policy.required_branch_prefixes.
+func BranchPrefixRequired(branchName string, prefixes []string, canOverride bool) error {
+	msg := fmt.Sprintf("policy violation: branch '%s' must start with one of: %s",
+		branchName, strings.Join(prefixes, ", "))
+	msg += fmt.Sprintf(`
+
+Solutions:
+  • Choose a branch name such as '%s%s'
+  • Change 'policy.required_branch_prefixes' in .wtp.yml`, prefixes[0], branchName)
+	return withCode(CodeBranchPrefixRequired, msg+policyOverrideHint(canOverride))
+}
+
+// UnknownProfile reports that 'wtp add --profile' named a profile that is not configured.
+func UnknownProfile(name string, available []string) error {
+	msg := fmt.Sprintf("unknown profile '%s'", name)
+	if len(available) > 0 {
+		msg += "\n\nAvailable profiles: " + strings.Join(available, ", ")
+	}
+	msg += `
+
+Solutions:
+  • Check the spelling of the profile name
+  • Define the profile under 'profiles' in .wtp.yml`
+	return withCode(CodeUnknownProfile, msg)
+}
+
+func policyOverrideHint(canOverride bool) string {
+	if canOverride {
+		return "\n  • Use '--ignore-policy' to create the worktree anyway"
+	}
+	return "\n  • '--ignore-policy' is disabled unless 'policy.allow_override' is true"
+}
+
+// VerificationFailed reports that 'verify' checks failed for a worktree.
+func VerificationFailed(worktreeName string, failed, total int) error {
+	msg := fmt.Sprintf("verification failed for worktree '%s': %d of %d check(s) failed", worktreeName, failed, total)
+	msg += `
+
+Solutions:
+  • Fix the environment (e.g. re-run the setup the failing check depends on)
+  • Adjust the checks under 'verify' in .wtp.yml
+  • Run 'wtp verify' again once fixed`
+	return withCode(CodeVerificationFailed, msg)
+}
+
+// ReadOnlyMode reports that a mutating command was refused because read-only mode is
+// enabled by source (the environment variable or config setting).
+func ReadOnlyMode(action, source string) error {
+	msg := fmt.Sprintf("cannot %s: wtp is in read-only mode (%s)", action, source)
+	msg += `
+
+Read-only mode blocks commands that change worktrees, branches, or files.
+Commands such as 'wtp list', 'wtp info', and 'wtp graph' keep working.
+
+Solutions:
+  • Unset WTP_READONLY in the environment
+  • Remove 'defaults.readonly' from .wtp.yml, .wtp.local.yml, or ~/.wtp.yml`
+	return withCode(CodeReadOnlyMode, msg)
+}
+
+// OperationTimedOut reports that operation, such as "wtp add", was stopped once timeout
+// passed. progress lists what it got done, and what it stopped, before that.
+func OperationTimedOut(operation string, timeout time.Duration, progress []string) error {
+	msg := fmt.Sprintf("%s timed out after %s and was stopped", operation, timeout)
+	if len(progress) > 0 {
+		msg += "\n\nProgress:"
+		for _, line := range progress {
+			msg += "\n  • " + line
+		}
+	}
+	msg += `
+
+Solutions:
+  • Look at the output above for the step that was still running
+  • Raise the limit with --timeout or 'defaults.operation_timeout' in .wtp.yml`
+	return withCode(CodeOperationTimedOut, msg)
+}
+
+// RepositoryBusy reports that operation, such as "wtp add", gave up waiting for the
+// repository's operation lock at lockPath after timeout.
+func RepositoryBusy(operation string, timeout time.Duration, lockPath string) error {
+	msg := fmt.Sprintf("%s gave up after waiting %s for another wtp command in this repository to finish", operation,
+		timeout)
+	msg += fmt.Sprintf("\n\nLock file: %s", lockPath)
+	msg += `
+
+Solutions:
+  • Wait for the other command to finish, then run this one again
+  • Raise the limit with --lock-timeout or 'defaults.lock_timeout' in .wtp.yml
+  • Pass --no-lock if the commands cannot interfere with each other`
+	return withCode(CodeRepositoryBusy, msg)
 }
 
 // ConfigLoadFailed reports a failure to read or parse the configuration file.
@@ -199,7 +468,14 @@ func ConfigLoadFailed(configPath string, parseError error) error {
 	msg := fmt.Sprintf("failed to load configuration from '%s'", configPath)
 
 	parseErrorStr := parseError.Error()
-	if strings.Contains(parseErrorStr, "yaml") || strings.Contains(parseErrorStr, "unmarshal") {
+	if strings.Contains(parseErrorStr, "strict mode: unknown keys") {
+		msg += `
+
+Cause: Strict mode rejects keys that name no setting
+Solutions:
+  • Fix the spelling of the keys listed below, or remove them
+  • Turn strict mode off: drop defaults.strict, --strict, and WTP_STRICT`
+	} else if strings.Contains(parseErrorStr, "yaml") || strings.Contains(parseErrorStr, "unmarshal") {
 		msg += `
 
 Cause: YAML syntax error in configuration file
@@ -220,7 +496,7 @@ Solution: Check file permissions with 'ls -la .wtp.yml'`
 	}
 
 	msg += fmt.Sprintf("\n\nOriginal error: %v", parseError)
-	return errors.New(msg)
+	return withCode(CodeConfigLoadFailed, msg)
 }
 
 // ConfigAlreadyExists indicates that a configuration file already exists at the target path.
@@ -231,7 +507,7 @@ Options:
   • Edit the existing file manually
   • Delete it and run 'wtp init' again
   • Use 'wtp init --force' to overwrite (if that flag exists)`, configPath)
-	return errors.New(msg)
+	return withCode(CodeConfigAlreadyExists, msg)
 }
 
 // DirectoryAccessFailed reports errors encountered while interacting with a directory path.
@@ -258,7 +534,7 @@ Solutions:
 	}
 
 	msg += fmt.Sprintf("\n\nOriginal error: %v", originalError)
-	return errors.New(msg)
+	return withCode(CodeDirectoryAccessFailed, msg)
 }
 
 // ShellIntegrationRequired indicates shell integration is needed for the requested command.
@@ -270,7 +546,7 @@ Setup:
   • Other installs: eval "$(wtp shell-init <shell>)" in your shell profile (~/.bashrc, ~/.zshrc, etc.)
 
 Help: Run 'wtp shell-init --help' for more details`
-	return errors.New(msg)
+	return withCode(CodeShellIntegrationRequired, msg)
 }
 
 // UnsupportedShell reports that the requested shell is not supported for integration.
@@ -285,7 +561,7 @@ func UnsupportedShell(shell string, supportedShells []string) error {
 	}
 
 	msg += "\n\nWorkaround: You can still use wtp without shell integration"
-	return errors.New(msg)
+	return withCode(CodeUnsupportedShell, msg)
 }
 
 // BranchNotFound reports that a branch cannot be found locally or remotely.
@@ -296,8 +572,21 @@ Suggestions:
   • Check the branch name spelling
   • Run 'git branch -a' to see all branches
   • Create a new branch with 'wtp add -b %s'
-  • Fetch latest changes with 'git fetch'`, branchName, branchName)
-	return errors.New(msg)
+  • Check out a tag or commit with 'wtp add --detach %s'
+  • Fetch latest changes with 'git fetch'`, branchName, branchName, branchName)
+	return withCode(CodeBranchNotFound, msg)
+}
+
+// RefNotFound reports that 'wtp add --detach' was given something that is neither a
+// branch, a tag, nor a commit.
+func RefNotFound(ref string) error {
+	msg := fmt.Sprintf(`'%s' is not a branch, tag, or commit
+
+Suggestions:
+  • Check the spelling
+  • Run 'git tag' or 'git log --oneline' to see tags and commits
+  • Fetch latest changes with 'git fetch --tags'`, ref)
+	return withCode(CodeRefNotFound, msg)
 }
 
 // MultipleBranchesFound reports that a branch name matches multiple remotes and needs a track specifier.
@@ -306,13 +595,13 @@ func MultipleBranchesFound(branchName string, remotes []string) error {
 	msg += fmt.Sprintf(`
 
 Solution: Specify the remote explicitly:
-  • wtp add --track %s/%s %s`, remotes[0], branchName, branchName)
+  • wtp add %s/%s`, remotes[0], branchName)
 
 	if len(remotes) > 1 {
-		msg += fmt.Sprintf("\n  • wtp add --track %s/%s %s", remotes[1], branchName, branchName)
+		msg += fmt.Sprintf("\n  • wtp add %s/%s", remotes[1], branchName)
 	}
 
-	return errors.New(msg)
+	return withCode(CodeMultipleBranchesFound, msg)
 }
 
 // HookExecutionFailed wraps an error that occurred while executing a configured hook.
@@ -347,5 +636,5 @@ Solutions:
 	}
 
 	msg += fmt.Sprintf("\n\nOriginal error: %v", originalError)
-	return errors.New(msg)
+	return withCode(CodeHookExecutionFailed, msg)
 }
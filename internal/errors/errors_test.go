@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -143,6 +144,22 @@ func TestWorktreeNotFound(t *testing.T) {
 	}
 }
 
+func TestAmbiguousWorktree(t *testing.T) {
+	err := AmbiguousWorktree("auth", []string{"feature/auth-api", "feature/auth-ui"})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "worktree name 'auth' is ambiguous")
+	assert.Contains(t, err.Error(), "• feature/auth-api")
+	assert.Contains(t, err.Error(), "• feature/auth-ui")
+}
+
+func TestUnconfirmedWorktreeMatch(t *testing.T) {
+	err := UnconfirmedWorktreeMatch("auth", "feature/user-auth")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "worktree name 'auth' only loosely matches 'feature/user-auth'")
+}
+
 func TestWorktreeCreationFailed(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -211,6 +228,152 @@ func TestCannotRemoveCurrentWorktree(t *testing.T) {
 	assert.Contains(t, err.Error(), "wtp cd @")
 }
 
+func TestWorktreeLocked(t *testing.T) {
+	err := WorktreeLocked("feature/foo", "on a USB drive")
+
+	assert.Contains(t, err.Error(), "worktree 'feature/foo' is locked")
+	assert.Contains(t, err.Error(), "Reason: on a USB drive")
+	assert.Contains(t, err.Error(), "wtp unlock feature/foo")
+	assert.Contains(t, err.Error(), "--force")
+
+	assert.NotContains(t, WorktreeLocked("feature/foo", "").Error(), "Reason:")
+}
+
+func TestPreRemoveHookFailed(t *testing.T) {
+	err := PreRemoveHookFailed("feature/foo", fmt.Errorf("exit status 1"))
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "pre-remove hooks failed for worktree 'feature/foo'")
+	assert.Contains(t, err.Error(), "--force")
+	assert.Contains(t, err.Error(), "Original error: exit status 1")
+}
+
+func TestMaintenanceHooksFailed(t *testing.T) {
+	err := MaintenanceHooksFailed([]string{"@", "feature/foo"})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "maintenance hooks failed in 2 worktree(s): @, feature/foo")
+	assert.Contains(t, err.Error(), "hooks.maintenance")
+}
+
+func TestCleanHooksFailed(t *testing.T) {
+	err := CleanHooksFailed([]string{"feature/foo"})
+
+	assert.Contains(t, err.Error(), "clean hooks failed in 1 worktree(s): feature/foo")
+	assert.Contains(t, err.Error(), "hooks.clean")
+}
+
+func TestWorktreeLimitReached(t *testing.T) {
+	err := WorktreeLimitReached(5, 5, false)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "already has 5 of at most 5 worktrees")
+	assert.Contains(t, err.Error(), "policy.allow_override")
+
+	err = WorktreeLimitReached(6, 5, true)
+	assert.Contains(t, err.Error(), "Use '--ignore-policy'")
+}
+
+func TestBranchPrefixRequired(t *testing.T) {
+	err := BranchPrefixRequired("login", []string{"feature/", "fix/"}, true)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "branch 'login' must start with one of: feature/, fix/")
+	assert.Contains(t, err.Error(), "'feature/login'")
+	assert.Contains(t, err.Error(), "--ignore-policy")
+}
+
+func TestUnknownProfile(t *testing.T) {
+	err := UnknownProfile("fronted", []string{"backend", "frontend"})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown profile 'fronted'")
+	assert.Contains(t, err.Error(), "Available profiles: backend, frontend")
+
+	err = UnknownProfile("frontend", nil)
+	assert.NotContains(t, err.Error(), "Available profiles")
+	assert.Contains(t, err.Error(), "'profiles' in .wtp.yml")
+}
+
+func TestReadOnlyMode(t *testing.T) {
+	err := ReadOnlyMode("remove worktrees", "WTP_READONLY")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot remove worktrees: wtp is in read-only mode (WTP_READONLY)")
+	assert.Contains(t, err.Error(), "'wtp list'")
+}
+
+func TestOperationTimedOut(t *testing.T) {
+	err := OperationTimedOut("wtp exec", 2*time.Minute, []string{"finished in: a", "stopped in: b"})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "wtp exec timed out after 2m0s and was stopped")
+	assert.Contains(t, err.Error(), "  • finished in: a\n  • stopped in: b")
+	assert.Contains(t, err.Error(), "defaults.operation_timeout")
+
+	err = OperationTimedOut("wtp add", time.Minute, nil)
+	assert.NotContains(t, err.Error(), "Progress:")
+}
+
+func TestRepositoryBusy(t *testing.T) {
+	err := RepositoryBusy("wtp add", 10*time.Minute, "/repo/.git/wtp/operation.lock")
+
+	assert.Contains(t, err.Error(), "wtp add gave up after waiting 10m0s")
+	assert.Contains(t, err.Error(), "Lock file: /repo/.git/wtp/operation.lock")
+	assert.Contains(t, err.Error(), "--no-lock")
+}
+
+func TestVerificationFailed(t *testing.T) {
+	err := VerificationFailed("feature/auth", 2, 3)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "verification failed for worktree 'feature/auth': 2 of 3 check(s) failed")
+	assert.Contains(t, err.Error(), "wtp verify")
+}
+
+func TestWorktreeRelocationFailed(t *testing.T) {
+	err := WorktreeRelocationFailed([]string{"feature/a", "feature/b"})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to relocate 2 worktree(s): feature/a, feature/b")
+	assert.Contains(t, err.Error(), "git worktree move")
+}
+
+func TestExecFailed(t *testing.T) {
+	err := ExecFailed([]string{"@", "feature/a"})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "command failed in 2 worktree(s): @, feature/a")
+	assert.Contains(t, err.Error(), "wtp exec --branch")
+}
+
+func TestWorktreePathInOtherClone(t *testing.T) {
+	err := WorktreePathInOtherClone("/src/worktrees/feature/a", "/src/clone-b")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "worktree path '/src/worktrees/feature/a' belongs to another clone: /src/clone-b")
+	assert.Contains(t, err.Error(), "${DIRNAME}")
+}
+
+func TestNestedWorktree(t *testing.T) {
+	err := NestedWorktree("/src/app/.worktrees/feature/a", "inside the main worktree /src/app")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "worktree path '/src/app/.worktrees/feature/a' is inside the main worktree /src/app")
+	assert.Contains(t, err.Error(), "defaults.nested_worktrees")
+}
+
+func TestLayoutMigrationFailed(t *testing.T) {
+	err := LayoutMigrationFailed("fix/b", fmt.Errorf("worktree is locked"), nil)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to move fix/b to the new layout: worktree is locked")
+	assert.Contains(t, err.Error(), "nothing changed")
+
+	err = LayoutMigrationFailed("fix/b", fmt.Errorf("worktree is locked"), []string{"feature/a"})
+	assert.Contains(t, err.Error(), "could not be moved back and stay at their new path: feature/a")
+}
+
 func TestBranchRemovalFailed(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -284,6 +447,16 @@ func TestConfigLoadFailed(t *testing.T) {
 				"Original error:",
 			},
 		},
+		{
+			name:   "unknown keys in strict mode",
+			path:   ".wtp.yml",
+			reason: fmt.Errorf("strict mode: unknown keys in the configuration:\n  .wtp.yml:4:3: unknown key 'x'"),
+			expected: []string{
+				"Strict mode rejects keys that name no setting",
+				"WTP_STRICT",
+				".wtp.yml:4:3: unknown key 'x'",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -385,6 +558,14 @@ func TestBranchNotFound(t *testing.T) {
 	assert.Contains(t, err.Error(), "branch 'feature/missing' not found")
 	assert.Contains(t, err.Error(), "local or remote branches")
 	assert.Contains(t, err.Error(), "git branch -a")
+	assert.Contains(t, err.Error(), "wtp add --detach feature/missing")
+}
+
+func TestRefNotFound(t *testing.T) {
+	err := RefNotFound("v9.9.9")
+
+	assert.Contains(t, err.Error(), "'v9.9.9' is not a branch, tag, or commit")
+	assert.Contains(t, err.Error(), "git fetch --tags")
 }
 
 func TestMultipleBranchesFound(t *testing.T) {
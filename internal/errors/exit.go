@@ -0,0 +1,45 @@
+package errors
+
+import "errors"
+
+// Exit statuses of the wtp binary.
+const (
+	// ExitFailure is the exit status of a failed command.
+	ExitFailure = 1
+	// ExitPartialFailure is the exit status of a command run across several worktrees that
+	// succeeded in some of them and failed in others.
+	ExitPartialFailure = 3
+)
+
+// partialFailure marks an error as reporting only some of the worktrees a command ran in.
+type partialFailure struct {
+	err error
+}
+
+func (e *partialFailure) Error() string { return e.err.Error() }
+
+func (e *partialFailure) Unwrap() error { return e.err }
+
+// PartialFailure marks err, the failure of a command in some worktrees, as leaving the
+// others done, so wtp exits with ExitPartialFailure. The code of err is kept.
+func PartialFailure(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &partialFailure{err: err}
+}
+
+// IsPartialFailure reports whether err, or an error it wraps, was marked by PartialFailure.
+func IsPartialFailure(err error) bool {
+	var partial *partialFailure
+	return errors.As(err, &partial)
+}
+
+// ExitStatus returns the status wtp exits with after err: ExitPartialFailure for a
+// partial failure and ExitFailure for any other error.
+func ExitStatus(err error) int {
+	if IsPartialFailure(err) {
+		return ExitPartialFailure
+	}
+	return ExitFailure
+}
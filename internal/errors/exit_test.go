@@ -0,0 +1,28 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitStatus(t *testing.T) {
+	assert.Equal(t, ExitFailure, ExitStatus(fmt.Errorf("plain")))
+	assert.Equal(t, ExitFailure, ExitStatus(ExecFailed([]string{"feature/a"})))
+
+	partial := PartialFailure(ExecFailed([]string{"feature/a"}))
+	assert.Equal(t, ExitPartialFailure, ExitStatus(partial))
+	assert.Equal(t, ExitPartialFailure, ExitStatus(fmt.Errorf("wtp exec: %w", partial)))
+	assert.True(t, IsPartialFailure(partial))
+	assert.Nil(t, PartialFailure(nil))
+}
+
+func TestPartialFailure_KeepsCode(t *testing.T) {
+	err := PartialFailure(ExecFailed([]string{"feature/a"}))
+
+	code, ok := CodeOf(err)
+	assert.True(t, ok)
+	assert.Equal(t, CodeExecFailed, code)
+	assert.Equal(t, ExecFailed([]string{"feature/a"}).Error(), err.Error())
+}
@@ -0,0 +1,462 @@
+package errors
+
+// registry holds the explanation of every code; a code must be registered before it is
+// attached to an error or warning (see TestCodesAreRegistered).
+var registry = map[Code]Explanation{
+	CodeUnclassified: {
+		Summary: "The command failed for a reason that has no code of its own.",
+		Causes:  []string{"The message printed with the code says what went wrong"},
+		Fixes: []string{
+			"Follow the message, e.g. create the missing file or fix the value it names",
+			"If the message is unclear, report it at https://github.com/satococoa/wtp/issues",
+		},
+	},
+	CodeNotInGitRepository: {
+		Summary: "The command was run outside a git repository.",
+		Causes:  []string{"The current directory is not inside a git working tree or a worktree of one"},
+		Fixes: []string{
+			"cd into the repository, or one of its worktrees, before running wtp",
+			"Run 'git init' to create a repository",
+		},
+	},
+	CodeGitCommandFailed: {
+		Summary: "A git command wtp ran exited with an error.",
+		Causes: []string{
+			"git is not installed or is older than 2.17",
+			"The repository is locked by another git process (.git/index.lock)",
+			"The git command was given a ref or path that does not exist",
+		},
+		Fixes: []string{
+			"Run the git command shown in the error manually to see its full output",
+			"Remove a stale .git/index.lock once no other git process is running",
+		},
+	},
+	CodeDirectoryAccessFailed: {
+		Summary: "A directory could not be read, created, or written.",
+		Causes: []string{
+			"Missing permissions on the directory or one of its parents",
+			"The current directory was deleted while wtp was running",
+		},
+		Fixes: []string{"Check the permissions of the path in the error", "Run the command from an existing directory"},
+	},
+	CodeReadOnlyMode: {
+		Summary: "A command that changes worktrees, branches, or files was refused in read-only mode.",
+		Causes:  []string{"WTP_READONLY is set in the environment", "'defaults.readonly: true' is set in a config file"},
+		Fixes: []string{
+			"Unset WTP_READONLY",
+			"Remove 'defaults.readonly' from .wtp.yml, .wtp.local.yml, or ~/.wtp.yml",
+		},
+	},
+	CodeRepositoryBusy: {
+		Summary: "Another wtp command kept the repository's operation lock longer than defaults.lock_timeout.",
+		Causes: []string{
+			"Another 'wtp add', 'wtp remove', or 'wtp prune' is still running, e.g. slow post_create hooks",
+			"The other command waits for input in another terminal",
+		},
+		Fixes: []string{
+			"Wait for the other command to finish and run the command again",
+			"Raise --lock-timeout or defaults.lock_timeout",
+			"Pass --no-lock if the commands cannot interfere with each other",
+		},
+	},
+	CodeInvalidArguments: {
+		Summary: "The command cannot run with the arguments or flags it was given.",
+		Causes: []string{
+			"A required argument is missing, or there are too many",
+			"Two flags that exclude each other were passed together",
+			"A flag value is out of range or not one of the accepted values",
+		},
+		Fixes: []string{"Run the command with --help for its usage and flags"},
+	},
+	CodeTerminalRequired: {
+		Summary: "The command needs an interactive terminal, e.g. to ask for confirmation.",
+		Causes:  []string{"stdin is not a terminal, as in scripts, CI jobs, and pipes"},
+		Fixes:   []string{"Follow the alternative in the message, such as passing --yes or using a non-interactive command"},
+	},
+	CodeOperationTimedOut: {
+		Summary: "A command ran longer than its --timeout or defaults.operation_timeout and was stopped.",
+		Causes: []string{
+			"A git command is waiting on a slow or unreachable remote",
+			"A hook or the command given to 'wtp exec' hangs, e.g. waiting for input",
+			"The limit is too short for the work being done",
+		},
+		Fixes: []string{
+			"Read the error for what finished before the deadline and what was stopped",
+			"Run 'wtp hooks status --rerun <worktree>' to finish hooks that did not run",
+			"Raise --timeout or defaults.operation_timeout",
+		},
+	},
+	CodeWorktreeNameRequired: {
+		Summary: "The command needs the name of a worktree.",
+		Causes:  []string{"No worktree name or path was passed"},
+		Fixes:   []string{"Pass a name shown by 'wtp list', e.g. 'wtp remove feature/auth'"},
+	},
+	CodeWorktreeNotFound: {
+		Summary: "No worktree matches the given name.",
+		Causes: []string{
+			"The name is misspelled, or the worktree was already removed",
+			"The name is relative to base_dir, which changed since the worktree was created",
+		},
+		Fixes: []string{"Run 'wtp list' and use a name or branch from its output"},
+	},
+	CodeAmbiguousWorktree: {
+		Summary: "A partial worktree name matches several worktrees equally well.",
+		Causes: []string{
+			"No worktree has exactly this name, and fuzzy matching found more than one candidate",
+			"The command ran without an interactive terminal, so wtp could not ask which one you meant",
+		},
+		Fixes: []string{"Use a longer part of the branch name, or the exact name from 'wtp list'"},
+	},
+	CodeUnconfirmedWorktreeMatch: {
+		Summary: "A partial worktree name only loosely matches the worktree to remove, move, or rename.",
+		Causes: []string{
+			"No worktree has this name or starts with it, and the one fuzzy match only contains it",
+			"The command ran without an interactive terminal, so wtp could not ask you to confirm the match",
+			"You answered no when asked to confirm the match",
+		},
+		Fixes: []string{"Use the worktree's name from 'wtp list', or a prefix of it"},
+	},
+	CodeNotInWorktree: {
+		Summary: "The command acts on the current worktree, but the current directory is not in one.",
+		Causes:  []string{"The command ran outside the repository, or in a directory git does not track as a worktree"},
+		Fixes:   []string{"cd into a worktree, or pass a worktree name as the message says"},
+	},
+	CodeMainWorktreeUnsupported: {
+		Summary: "The command does not apply to the main worktree.",
+		Causes: []string{
+			"The main worktree is the repository itself: other worktrees and their hooks depend on it",
+			"It was named explicitly, or is the current worktree",
+		},
+		Fixes: []string{"Name a worktree that 'wtp add' created; 'wtp list' shows them"},
+	},
+	CodeExecFailed: {
+		Summary: "The command 'wtp exec' ran failed in some worktrees.",
+		Causes:  []string{"The command exited with a non-zero status, or could not be started"},
+		Fixes: []string{
+			"Read the output lines prefixed with the worktree's name",
+			"Re-run it in the failing worktree with 'wtp exec --branch <branch> -- <command>'",
+		},
+	},
+	CodeWorktreeCreationFailed: {
+		Summary: "git could not create the worktree.",
+		Causes: []string{
+			"The branch is already checked out in another worktree",
+			"The branch or commit does not exist",
+			"The target directory already exists",
+		},
+		Fixes: []string{
+			"Check the branch with 'git branch -a'",
+			"Remove the existing directory or choose another branch name",
+		},
+	},
+	CodeWorktreeRemovalFailed: {
+		Summary: "git could not remove the worktree.",
+		Causes:  []string{"The worktree has uncommitted changes or untracked files", "The worktree is locked"},
+		Fixes: []string{
+			"Commit or stash the changes, or use 'wtp remove --force'",
+			"Unlock it with 'git worktree unlock <path>'",
+		},
+	},
+	CodeCannotRemoveCurrentWorktree: {
+		Summary: "The worktree you are in cannot be removed.",
+		Causes:  []string{"The current directory is inside the worktree being removed"},
+		Fixes:   []string{"Switch to another worktree first, e.g. 'wtp cd @'"},
+	},
+	CodeWorktreeRelocationFailed: {
+		Summary: "'wtp relink --relocate' could not move some worktrees.",
+		Causes:  []string{"The target path is already taken", "The target directory is not writable"},
+		Fixes: []string{
+			"Free the target path and run 'wtp relink --relocate' again",
+			"Move the worktree manually with 'git worktree move <old> <new>'",
+		},
+	},
+	CodeWorktreePathInOtherClone: {
+		Summary: "The worktree path belongs to another clone of the repository.",
+		Causes:  []string{"Two clones share a base_dir that does not include the clone's name"},
+		Fixes: []string{
+			`Include the clone in base_dir, e.g. base_dir: "../worktrees/${DIRNAME}"`,
+			"Set a per-clone base_dir in .wtp.local.yml",
+		},
+	},
+	CodeLayoutMigrationFailed: {
+		Summary: "'wtp migrate-layout' could not move a worktree, so it undid the moves before it.",
+		Causes: []string{
+			"The target path was taken after the moves were checked",
+			"The worktree is locked ('git worktree lock') or is the main worktree of a submodule",
+			"The target directory is not writable",
+		},
+		Fixes: []string{
+			"Fix the cause and run 'wtp migrate-layout' again",
+			"Move worktrees that could not be moved back by hand with 'git worktree move <old> <new>'",
+		},
+	},
+	CodeNestedWorktree: {
+		Summary: "The new worktree would be inside another worktree or git repository.",
+		Causes: []string{
+			"base_dir points into the main worktree or another worktree, e.g. base_dir: \".worktrees\"",
+			"base_dir points into a directory that is itself a git repository, such as a dotfiles home",
+		},
+		Fixes: []string{
+			`Point base_dir outside every checkout, e.g. base_dir: "../worktrees"`,
+			"Set defaults.nested_worktrees to 'warn' or 'allow' if the layout is intended",
+		},
+	},
+	CodeWorktreeLocked: {
+		Summary: "The worktree is locked, so 'wtp remove' and 'wtp prune' leave it alone.",
+		Causes: []string{
+			"The worktree was locked with 'wtp lock' or 'git worktree lock', e.g. because it lives on a removable drive",
+		},
+		Fixes: []string{
+			"Run 'wtp unlock <worktree>' if it no longer needs protecting",
+			"Pass --force to remove it anyway",
+		},
+	},
+	CodeVerificationFailed: {
+		Summary: "One or more 'verify' checks failed.",
+		Causes:  []string{"The worktree's environment is incomplete, e.g. dependencies were not installed"},
+		Fixes:   []string{"Fix the environment and run 'wtp verify' again", "Adjust the checks under 'verify'"},
+	},
+	CodeWorktreeAlreadyExists: {
+		Summary: "The branch is already checked out in another worktree.",
+		Causes:  []string{"git allows a branch in only one worktree at a time"},
+		Fixes: []string{
+			"Use the existing worktree ('wtp cd <branch>')",
+			"Create a new branch with 'wtp add -b <new-branch> <branch>'",
+		},
+	},
+	CodePathAlreadyExists: {
+		Summary: "The worktree directory already exists.",
+		Causes:  []string{"A previous worktree was removed without deleting its directory"},
+		Fixes:   []string{"Remove or rename the directory", "Choose a different branch name"},
+	},
+	CodeBranchNameRequired: {
+		Summary: "The command needs a branch name.",
+		Causes:  []string{"Neither a branch argument nor '-b <branch>' was given"},
+		Fixes:   []string{"Run 'wtp add <existing-branch>' or 'wtp add -b <new-branch>'"},
+	},
+	CodeInvalidBranchName: {
+		Summary: "The branch name is not allowed by git.",
+		Causes:  []string{"The name contains '..', control characters, or other characters git rejects"},
+		Fixes:   []string{"See 'git check-ref-format --help' and choose a valid name"},
+	},
+	CodeBranchRemovalFailed: {
+		Summary: "The worktree was removed but its branch was not deleted.",
+		Causes:  []string{"The branch has commits that are not merged"},
+		Fixes:   []string{"Merge the branch first, or use '--force-branch' to delete it anyway"},
+	},
+	CodeBranchNotFound: {
+		Summary: "The branch exists neither locally nor on a remote.",
+		Causes:  []string{"The name is misspelled", "The remote branch was not fetched yet"},
+		Fixes:   []string{"Run 'git fetch'", "Create it with 'wtp add -b <branch>'"},
+	},
+	CodeMultipleBranchesFound: {
+		Summary: "The branch exists on several remotes, so the one to track is ambiguous.",
+		Causes:  []string{"Several remotes (e.g. origin and upstream) have a branch with this name"},
+		Fixes:   []string{"Name the remote explicitly, e.g. 'wtp add -b <branch> origin/<branch>'"},
+	},
+	CodeBranchAlreadyExists: {
+		Summary: "'wtp add -b' was given a branch that already exists.",
+		Causes:  []string{"-b creates a new branch"},
+		Fixes:   []string{"Drop -b to check out the existing branch", "Choose a different branch name"},
+	},
+	CodeRefNotFound: {
+		Summary: "'wtp add --detach' was given a name that is not a branch, tag, or commit.",
+		Causes:  []string{"The name is misspelled", "The tag was not fetched yet"},
+		Fixes:   []string{"Run 'git fetch --tags'", "Pass a full or abbreviated commit SHA"},
+	},
+	CodeConfigLoadFailed: {
+		Summary: "A configuration file could not be read or is invalid.",
+		Causes: []string{
+			"Syntax errors in .wtp.yml, .wtp.local.yml, or ~/.wtp.yml (or their JSON/TOML variants)",
+			"A value fails validation, e.g. an unknown hook type",
+			"More than one format of the same file exists, e.g. .wtp.yml and .wtp.toml",
+		},
+		Fixes: []string{"Fix the setting named in the error", "Keep only one file per configuration layer"},
+	},
+	CodeConfigAlreadyExists: {
+		Summary: "'wtp init' found an existing configuration file.",
+		Causes:  []string{"The repository already has a .wtp.yml, .wtp.json, or .wtp.toml"},
+		Fixes:   []string{"Edit the existing file, or delete it and run 'wtp init' again"},
+	},
+	CodeWorktreeLimitReached: {
+		Summary: "policy.max_worktrees_per_repo refused a new worktree.",
+		Causes:  []string{"The repository already has the maximum number of worktrees"},
+		Fixes: []string{
+			"Remove worktrees you no longer need",
+			"Raise the limit, or use '--ignore-policy' if policy.allow_override is true",
+		},
+	},
+	CodeBranchPrefixRequired: {
+		Summary: "policy.required_branch_prefixes refused the new branch name.",
+		Causes:  []string{"The name does not start with one of the required prefixes"},
+		Fixes:   []string{"Rename the branch with an allowed prefix, e.g. 'feature/'"},
+	},
+	CodeUnknownProfile: {
+		Summary: "'wtp add --profile' named a profile the configuration does not define.",
+		Causes: []string{
+			"A typo in the profile name",
+			"The profile is defined in a configuration layer that was not loaded",
+		},
+		Fixes: []string{"Use one of the names under 'profiles' in .wtp.yml, or add the profile there"},
+	},
+	CodeHookExecutionFailed: {
+		Summary: "A configured hook failed.",
+		Causes: []string{
+			"A command hook exited with a non-zero status or timed out",
+			"A copy or symlink source does not exist",
+			"Missing permissions at the source or destination",
+		},
+		Fixes: []string{
+			"Check the hook's output above the error",
+			"Re-run the hooks with 'wtp hooks run' once fixed",
+		},
+	},
+	CodePreRemoveHookFailed: {
+		Summary: "A pre_remove hook failed, so the worktree was kept.",
+		Causes:  []string{"A hook under 'hooks.pre_remove' exited with an error"},
+		Fixes:   []string{"Fix the hook", "Use 'wtp remove --force' to remove the worktree anyway"},
+	},
+	CodeMaintenanceHooksFailed: {
+		Summary: "Maintenance hooks failed in some worktrees.",
+		Causes:  []string{"A hook under 'hooks.maintenance' exited with an error"},
+		Fixes:   []string{"Fix the hook and run 'wtp maintain' again; the interval is not reset after a failure"},
+	},
+	CodeCleanHooksFailed: {
+		Summary: "Clean hooks failed in some worktrees.",
+		Causes:  []string{"A hook under 'hooks.clean' exited with an error"},
+		Fixes:   []string{"Fix the hook and run 'wtp clean' again; deleting what is already gone is harmless"},
+	},
+	CodeShellIntegrationRequired: {
+		Summary: "Changing directories needs the shell integration.",
+		Causes:  []string{"A program cannot change its parent shell's directory"},
+		Fixes:   []string{`Add 'eval "$(wtp shell-init <shell>)"' to your shell's startup file`},
+	},
+	CodeUnsupportedShell: {
+		Summary: "The shell is not supported.",
+		Causes:  []string{"Shell integration exists only for the shells listed in the error"},
+		Fixes:   []string{"Use one of the supported shells"},
+	},
+	CodeWarnPostCreateHookFailed: {
+		Summary: "Warning: a post_create hook failed; the worktree was created anyway.",
+		Causes:  []string{"A hook under 'hooks.post_create' exited with an error"},
+		Fixes:   []string{"Inspect 'wtp hooks status <name>' and re-run with 'wtp hooks status --rerun <name>'"},
+	},
+	CodeWarnProvisionRecordFailed: {
+		Summary: "Warning: the provisioning record could not be saved.",
+		Causes:  []string{"The worktree's git directory is not writable"},
+		Fixes:   []string{"Check the permissions of .git/worktrees/<name>; 'wtp info' and 'wtp hooks status' lack data"},
+	},
+	CodeWarnVerifyChecksFailed: {
+		Summary: "Warning: 'verify' checks failed after the worktree was created.",
+		Causes:  []string{"A post_create hook did not set up what a check expects"},
+		Fixes:   []string{"Fix the environment and run 'wtp verify'"},
+	},
+	CodeWarnMaintenanceHooksFailed: {
+		Summary: "Warning: maintenance hooks failed in one worktree; the others were still maintained.",
+		Causes:  []string{"A hook under 'hooks.maintenance' exited with an error"},
+		Fixes:   []string{"Fix the hook and run 'wtp maintain --force'"},
+	},
+	CodeWarnPreRemoveHookFailed: {
+		Summary: "Warning: a pre_remove hook failed and was ignored because of --force.",
+		Causes:  []string{"A hook under 'hooks.pre_remove' exited with an error"},
+		Fixes:   []string{"Clean up whatever the hook would have handled by hand"},
+	},
+	CodeWarnWorktreeMoveFailed: {
+		Summary: "Warning: 'wtp relink --relocate' or 'wtp rename' could not move one worktree.",
+		Causes:  []string{"The target path is taken or not writable"},
+		Fixes: []string{
+			"Free the target path and run 'wtp relink --relocate' again",
+			"After 'wtp rename', the branch is renamed; move the worktree with 'wtp move'",
+		},
+	},
+	CodeWarnPolicySkipped: {
+		Summary: "Warning: policy checks were skipped because of --ignore-policy.",
+		Causes:  []string{"--ignore-policy was passed and policy.allow_override is true"},
+		Fixes:   []string{"Nothing to fix; drop --ignore-policy to enforce the policy"},
+	},
+	CodeWarnNoTimingHistory: {
+		Summary: "Warning: --max-duration could not be checked.",
+		Causes:  []string{"No existing worktree has recorded post_create hook timings yet"},
+		Fixes:   []string{"Create a worktree with 'wtp add' once; later runs are estimated from it"},
+	},
+	CodeWarnPostCheckoutHookFailed: {
+		Summary: "Warning: a post_checkout hook failed; the branch was switched anyway.",
+		Causes:  []string{"A hook under 'hooks.post_checkout' exited with an error"},
+		Fixes:   []string{"Fix the hook; it runs again on the next 'wtp checkout'"},
+	},
+	CodeWarnAfterAddFailed: {
+		Summary: "Warning: the defaults.after_add action failed; the worktree was created anyway.",
+		Causes: []string{
+			"after_add is copy-path but no clipboard tool (pbcopy, wl-copy, xclip, xsel, clip) is available",
+			"after_add is open-editor but none of defaults.editor, VISUAL, and EDITOR is set, or the editor failed",
+			"after_add is cd but the shell hook from 'wtp hook <shell>' is not loaded",
+		},
+		Fixes: []string{
+			"Install a clipboard tool or set defaults.editor, VISUAL, or EDITOR",
+			"Load the shell hook, e.g. eval \"$(wtp hook bash)\"",
+			"Pick another defaults.after_add value",
+		},
+	},
+	CodeWarnCacheOverBudget: {
+		Summary: "Warning: a cache is still larger than cache.max_size after 'wtp cache gc'.",
+		Causes:  []string{"Existing worktrees used the remaining entries, and gc never evicts those"},
+		Fixes: []string{
+			"Remove worktrees you no longer need, e.g. with 'wtp prune', and run 'wtp cache gc' again",
+			"Raise cache.max_size, or run 'wtp cache clear' to empty the caches anyway",
+		},
+	},
+	CodeWarnWorktreeMapFailed: {
+		Summary: "Warning: the worktree map set up in the 'map' config section could not be updated.",
+		Causes: []string{
+			"map.file is in a directory that cannot be created or written",
+			"git could not write the repository's config, e.g. because .git/config.lock exists",
+		},
+		Fixes: []string{
+			"Fix the cause, then run 'wtp map --output <map.file> --format <map.format>' or 'wtp map --git-config'",
+			"The worktree itself was changed as requested; only the map is out of date",
+		},
+	},
+	CodeWarnSymlinkRepointFailed: {
+		Summary: "Warning: 'wtp migrate-layout' or 'wtp move' could not re-point the symbolic links in a worktree.",
+		Causes: []string{
+			"A directory in the worktree is not readable",
+			"A link could not be replaced, e.g. because the directory holding it is not writable",
+		},
+		Fixes: []string{
+			"Fix the cause, then recreate the broken links by hand or with 'wtp hooks run'",
+			"The worktrees themselves were moved; only the reported links may be broken",
+		},
+	},
+	CodeWarnNestedWorktree: {
+		Summary: "Warning: the new worktree is inside another worktree or git repository.",
+		Causes: []string{
+			"base_dir points into the main worktree or another worktree, e.g. base_dir: \".worktrees\"",
+			"base_dir points into a directory that is itself a git repository, such as a dotfiles home",
+		},
+		Fixes: []string{
+			`Point base_dir outside every checkout, e.g. base_dir: "../worktrees"`,
+			"Set defaults.nested_worktrees to 'error' to refuse such paths, or 'allow' to silence this",
+		},
+	},
+	CodeWarnPostRemoveHookFailed: {
+		Summary: "Warning: a post_remove or post_prune hook failed; the worktree was removed anyway.",
+		Causes:  []string{"A hook under 'hooks.post_remove' or 'hooks.post_prune' exited with an error"},
+		Fixes: []string{
+			"Fix the hook and redo its cleanup by hand; the removal is not repeated",
+			"Hooks run in the main worktree, since the removed one is gone; use $GIT_WTP_REMOVED_PATH to refer to it",
+		},
+	},
+	CodeWarnCleanHooksFailed: {
+		Summary: "Warning: clean hooks failed in one worktree; 'wtp clean --all' went on with the others.",
+		Causes:  []string{"A hook under 'hooks.clean' exited with an error"},
+		Fixes:   []string{"Fix the hook and run 'wtp clean <worktree>'"},
+	},
+	CodeWarnHookFailed: {
+		Summary: "Warning: a hook with 'on_error: warn' failed; the hooks after it still ran.",
+		Causes:  []string{"The hook exited with an error, or its command could not be started"},
+		Fixes: []string{
+			"Read the hook's output above the warning",
+			"Run 'wtp hooks run' in the worktree once the hook is fixed",
+		},
+	},
+}
@@ -0,0 +1,99 @@
+// Package events streams wtp's progress as newline-delimited JSON, one event per line,
+// for CI systems and wrappers to parse instead of the human-readable output. Nothing is
+// written until Enable is called, which --json-events does.
+package events
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Types of events
+const (
+	TypeWorktreeCreated = "worktree_created"
+	TypeWorktreeRemoved = "worktree_removed"
+	TypeWorktreePruned  = "worktree_pruned"
+	TypeHookStarted     = "hook_started"
+	TypeHookFinished    = "hook_finished"
+	TypeError           = "error"
+)
+
+// Statuses of hook_finished events
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Event is one line of the stream. Fields that do not apply to the event's type are left
+// out.
+type Event struct {
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+	// Worktree is the path of the worktree the event is about.
+	Worktree string `json:"worktree,omitempty"`
+	Branch   string `json:"branch,omitempty"`
+	// Phase is the hook phase, such as "post_create".
+	Phase string `json:"phase,omitempty"`
+	// Reason says why 'wtp prune' removed the worktree, such as "merged into main".
+	Reason string `json:"reason,omitempty"`
+	// Hook is the 1-based index of the hook in its phase.
+	Hook     int    `json:"hook,omitempty"`
+	HookType string `json:"hook_type,omitempty"`
+	Status   string `json:"status,omitempty"`
+	// DurationMS is how long the hook ran, in milliseconds.
+	DurationMS int64  `json:"duration_ms,omitempty"`
+	Error      string `json:"error,omitempty"`
+	// Code is the WTPxxxx code of an error, when it has one.
+	Code     string `json:"code,omitempty"`
+	ExitCode int    `json:"exit_code,omitempty"`
+}
+
+var (
+	mu  sync.Mutex
+	out io.Writer
+)
+
+// Now returns the time events are stamped with; tests replace it.
+var Now = time.Now
+
+// Enable starts writing events to w; a nil w stops it.
+func Enable(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	out = w
+}
+
+// Enabled reports whether events are written.
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return out != nil
+}
+
+// Writes reports whether events are written to w, e.g. to tell whether they share stderr
+// with the human-readable messages.
+func Writes(w io.Writer) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return out != nil && out == w
+}
+
+// Emit writes event as one line, stamped with the current time unless it has one. It does
+// nothing unless events are enabled; failing to write an event does not fail the command.
+func Emit(event *Event) {
+	mu.Lock()
+	defer mu.Unlock()
+	if out == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = Now().UTC()
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	_, _ = out.Write(append(data, '\n'))
+}
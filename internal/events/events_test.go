@@ -0,0 +1,76 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func capture(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	Enable(&buf)
+	t.Cleanup(func() { Enable(nil) })
+	return &buf
+}
+
+func TestEmit_DisabledWritesNothing(t *testing.T) {
+	Enable(nil)
+	assert.False(t, Enabled())
+	Emit(&Event{Type: TypeError, Error: "boom"}) // must not panic
+}
+
+func TestWrites(t *testing.T) {
+	buf := capture(t)
+	assert.True(t, Writes(buf))
+	assert.False(t, Writes(&bytes.Buffer{}))
+
+	Enable(nil)
+	assert.False(t, Writes(nil))
+}
+
+func TestEmit_WritesOneJSONObjectPerLine(t *testing.T) {
+	buf := capture(t)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	original := Now
+	Now = func() time.Time { return now }
+	t.Cleanup(func() { Now = original })
+
+	Emit(&Event{Type: TypeHookStarted, Worktree: "/w", Phase: "post_create", Hook: 1, HookType: "command"})
+	Emit(&Event{Type: TypeHookFinished, Hook: 1, Status: StatusSucceeded, DurationMS: 12})
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	assert.JSONEq(t, `{"type":"hook_started","time":"2026-01-02T03:04:05Z","worktree":"/w",`+
+		`"phase":"post_create","hook":1,"hook_type":"command"}`, lines[0])
+	assert.JSONEq(t, `{"type":"hook_finished","time":"2026-01-02T03:04:05Z","hook":1,`+
+		`"status":"succeeded","duration_ms":12}`, lines[1])
+}
+
+func TestEmit_ConcurrentEventsDoNotInterleave(t *testing.T) {
+	buf := capture(t)
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Emit(&Event{Type: TypeHookFinished, Hook: i + 1, Error: strings.Repeat("x", 1000)})
+		}()
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 50)
+	for _, line := range lines {
+		var event Event
+		require.NoError(t, json.Unmarshal([]byte(line), &event))
+		assert.Equal(t, TypeHookFinished, event.Type)
+	}
+}
@@ -0,0 +1,74 @@
+// Package filelock provides advisory, exclusive locks on files that are shared between
+// processes. The operating system releases a lock when its holder exits, so a crashed
+// process never leaves a stale lock behind.
+package filelock
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	directoryPermissions = 0o755
+	lockFilePermissions  = 0o644
+)
+
+// Lock blocks until it holds the exclusive lock on path, creating the file and its parent
+// directories when needed. The returned function releases the lock.
+func Lock(path string) (unlock func() error, err error) {
+	unlock, _, err = lock(path, true)
+	return unlock, err
+}
+
+// TryLock is like Lock but does not wait: ok is false, and unlock nil, when another holder
+// has the lock.
+func TryLock(path string) (unlock func() error, ok bool, err error) {
+	return lock(path, false)
+}
+
+func lock(path string, wait bool) (unlock func() error, ok bool, err error) {
+	if err := os.MkdirAll(filepath.Dir(path), directoryPermissions); err != nil {
+		return nil, false, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+	// #nosec G304 -- the lock path is chosen by wtp
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, lockFilePermissions)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	locked, err := lockFile(file, wait)
+	if err != nil || !locked {
+		_ = file.Close()
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		return nil, false, nil
+	}
+	return func() error {
+		unlockErr := unlockFile(file)
+		if closeErr := file.Close(); unlockErr == nil {
+			unlockErr = closeErr
+		}
+		return unlockErr
+	}, true, nil
+}
+
+// Held reports whether another holder has the lock on the existing file path. Unlike
+// TryLock it never creates the file, so it can probe locks that their holders remove.
+func Held(path string) (bool, error) {
+	// #nosec G304 -- the lock path is chosen by wtp
+	file, err := os.OpenFile(path, os.O_RDWR, lockFilePermissions)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = file.Close() }()
+
+	locked, err := lockFile(file, false)
+	if err != nil {
+		return false, err
+	}
+	if !locked {
+		return true, nil
+	}
+	return false, unlockFile(file)
+}
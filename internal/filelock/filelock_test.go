@@ -0,0 +1,95 @@
+package filelock
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLock_CreatesParentDirectories(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "locks", "npm.lock")
+
+	unlock, err := Lock(path)
+	require.NoError(t, err)
+	assert.FileExists(t, path)
+	require.NoError(t, unlock())
+}
+
+func TestLock_IsExclusive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shared.lock")
+
+	unlock, err := Lock(path)
+	require.NoError(t, err)
+
+	var (
+		mu       sync.Mutex
+		acquired bool
+	)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		second, err := Lock(path)
+		if !assert.NoError(t, err) {
+			return
+		}
+		mu.Lock()
+		acquired = true
+		mu.Unlock()
+		assert.NoError(t, second())
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	assert.False(t, acquired, "second lock must wait for the first to be released")
+	mu.Unlock()
+
+	require.NoError(t, unlock())
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("second lock was not acquired after release")
+	}
+	assert.True(t, acquired)
+}
+
+func TestTryLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slot.lock")
+
+	unlock, ok, err := TryLock(path)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	second, ok, err := TryLock(path)
+	require.NoError(t, err)
+	assert.False(t, ok, "a held lock must not be taken again")
+	assert.Nil(t, second)
+
+	require.NoError(t, unlock())
+	again, ok, err := TryLock(path)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	require.NoError(t, again())
+}
+
+func TestHeld(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ticket.lock")
+
+	_, err := Held(path)
+	require.Error(t, err)
+	assert.NoFileExists(t, path, "Held must not create the file")
+
+	unlock, err := Lock(path)
+	require.NoError(t, err)
+	held, err := Held(path)
+	require.NoError(t, err)
+	assert.True(t, held)
+
+	require.NoError(t, unlock())
+	held, err = Held(path)
+	require.NoError(t, err)
+	assert.False(t, held)
+}
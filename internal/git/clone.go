@@ -0,0 +1,34 @@
+package git
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// cloneKeyDigits is the length of a clone key, e.g. "9c01d7e4b6a83f2a".
+const cloneKeyDigits = 16
+
+// CloneKey returns the key identifying a clone: the URL of its origin remote together
+// with the path of its main worktree. Two clones of one repository on a machine get
+// different keys, and so do two repositories cloned to the same path one after the other.
+// remoteURL is empty for a clone without an origin.
+func CloneKey(remoteURL, clonePath string) string {
+	sum := sha256.Sum256([]byte(remoteURL + "\n" + filepath.Clean(clonePath)))
+	return hex.EncodeToString(sum[:])[:cloneKeyDigits]
+}
+
+// OriginURL returns the URL of the origin remote of the clone whose main worktree is at
+// repoRoot, or "" when it has none or the clone cannot be read.
+func OriginURL(repoRoot string) string {
+	// #nosec G204 -- repoRoot is the main worktree of a clone found by wtp
+	cmd := exec.Command("git", "--git-dir", filepath.Join(repoRoot, ".git"),
+		"config", "--get", "remote.origin.url")
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
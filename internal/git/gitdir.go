@@ -0,0 +1,96 @@
+package git
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WorktreeGitDir returns the git directory of the worktree at path: the .git directory
+// of the main worktree, or the directory a linked worktree's .git file points to.
+func WorktreeGitDir(path string) (string, error) {
+	dotGit := filepath.Join(path, ".git")
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return dotGit, nil
+	}
+
+	// #nosec G304 -- path is a worktree reported by git
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return "", err
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return "", fmt.Errorf("unexpected content in %s", dotGit)
+	}
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(path, gitDir)
+	}
+	return filepath.Clean(gitDir), nil
+}
+
+// WorktreeOwnerRepo returns the main worktree of the clone a checkout at path belongs to.
+// ok is false when path holds no checkout, or one of a bare repository.
+func WorktreeOwnerRepo(path string) (owner string, ok bool) {
+	gitDir, err := WorktreeGitDir(path)
+	if err != nil {
+		return "", false
+	}
+	commonDir := gitDir
+	// #nosec G304 -- gitDir is read from the worktree's .git file
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = strings.TrimSpace(string(data))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+		commonDir = filepath.Clean(commonDir)
+	}
+	if filepath.Base(commonDir) != ".git" {
+		return "", false
+	}
+	return filepath.Dir(commonDir), true
+}
+
+// EnclosingCheckout returns the top directory of the checkout, of any repository, that a
+// new directory at path would be inside: the nearest parent directory with a .git entry.
+// path itself need not exist.
+func EnclosingCheckout(path string) (string, bool) {
+	dir := filepath.Dir(filepath.Clean(path))
+	for {
+		if _, err := os.Lstat(filepath.Join(dir, ".git")); err == nil {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// WorktreeDigest returns the hex digest identifying the worktree at path. It hashes the
+// name of the worktree's administrative directory under .git/worktrees, which stays the
+// same when the branch is renamed or the worktree is moved with 'git worktree move'. The
+// main worktree always hashes "@", and the path is used when the git directory cannot be
+// read.
+func WorktreeDigest(path string, isMain bool) string {
+	seed := path
+	switch {
+	case isMain:
+		seed = "@"
+	default:
+		if gitDir, err := WorktreeGitDir(path); err == nil {
+			seed = filepath.Base(gitDir)
+		}
+	}
+	sum := sha256.Sum256([]byte(seed))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,83 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorktreeGitDir(t *testing.T) {
+	gitDir := filepath.Join(t.TempDir(), "worktrees", "foo")
+	require.NoError(t, os.MkdirAll(gitDir, 0o755))
+	worktreePath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(worktreePath, ".git"), []byte("gitdir: "+gitDir+"\n"), 0o644))
+
+	got, err := WorktreeGitDir(worktreePath)
+	require.NoError(t, err)
+	assert.Equal(t, gitDir, got)
+
+	mainPath := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(mainPath, ".git"), 0o755))
+	got, err = WorktreeGitDir(mainPath)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(mainPath, ".git"), got)
+}
+
+func TestWorktreeDigest(t *testing.T) {
+	gitDir := filepath.Join(t.TempDir(), "worktrees", "foo")
+	require.NoError(t, os.MkdirAll(gitDir, 0o755))
+	worktreePath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(worktreePath, ".git"), []byte("gitdir: "+gitDir+"\n"), 0o644))
+
+	// The digest hashes the administrative directory name "foo", not the worktree path
+	assert.Regexp(t, `^2c26`, WorktreeDigest(worktreePath, false))
+	assert.Regexp(t, `^c364`, WorktreeDigest(worktreePath, true))
+}
+
+func TestCloneKey(t *testing.T) {
+	const url = "git@github.com:satococoa/wtp.git"
+	key := CloneKey(url, "/src/wtp")
+	assert.Len(t, key, 16)
+	assert.Equal(t, key, CloneKey(url, "/src/wtp/"), "the path is cleaned")
+	assert.NotEqual(t, key, CloneKey(url, "/work/wtp"), "another clone of the repository")
+	assert.NotEqual(t, key, CloneKey("git@github.com:other/wtp.git", "/src/wtp"), "another repository at the path")
+}
+
+func TestOriginURL(t *testing.T) {
+	repoRoot := t.TempDir()
+	if err := exec.Command("git", "init", "-q", repoRoot).Run(); err != nil {
+		t.Skip("git not available")
+	}
+	assert.Empty(t, OriginURL(repoRoot))
+	assert.Empty(t, OriginURL(t.TempDir()), "not a clone")
+
+	const url = "https://example.com/app.git"
+	require.NoError(t, exec.Command("git", "-C", repoRoot, "remote", "add", "origin", url).Run())
+	assert.Equal(t, url, OriginURL(repoRoot))
+}
+
+func TestEnclosingCheckout(t *testing.T) {
+	outside := t.TempDir()
+	_, ok := EnclosingCheckout(filepath.Join(outside, "worktrees", "feature", "a"))
+	assert.False(t, ok)
+
+	repo := filepath.Join(outside, "repo")
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, ".git"), 0o755))
+	got, ok := EnclosingCheckout(filepath.Join(repo, ".worktrees", "feature", "a"))
+	require.True(t, ok)
+	assert.Equal(t, repo, got)
+
+	// A linked worktree has a .git file; the path itself does not count
+	linked := filepath.Join(outside, "linked")
+	require.NoError(t, os.MkdirAll(linked, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(linked, ".git"), []byte("gitdir: /x\n"), 0o644))
+	got, ok = EnclosingCheckout(filepath.Join(linked, "sub"))
+	require.True(t, ok)
+	assert.Equal(t, linked, got)
+	_, ok = EnclosingCheckout(linked)
+	assert.False(t, ok)
+}
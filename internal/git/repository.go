@@ -74,6 +74,23 @@ func (r *Repository) GetMainWorktreePath() (string, error) {
 	return commonDir, nil
 }
 
+// GetGitCommonDir returns the absolute path of the git directory shared by all worktrees.
+// wtp stores repository-scoped runtime data (e.g. benchmark baselines) beneath it.
+func (r *Repository) GetGitCommonDir() (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--git-common-dir")
+	cmd.Dir = r.path
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get git common directory: %w", err)
+	}
+
+	commonDir := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(r.path, commonDir)
+	}
+	return filepath.Clean(commonDir), nil
+}
+
 // GetWorktrees lists the worktrees associated with the repository.
 func (r *Repository) GetWorktrees() ([]Worktree, error) {
 	cmd := exec.Command("git", "worktree", "list", "--porcelain")
@@ -210,6 +227,11 @@ func (r *Repository) ResolveBranch(branch string) (resolvedBranch string, isRemo
 		return branch, false, nil
 	}
 
+	// A remote-tracking branch such as origin/feature/x names its remote itself
+	if _, _, ok := r.SplitRemoteBranch(branch); ok {
+		return branch, true, nil
+	}
+
 	// Check remote branches
 	remoteBranches, err := r.GetRemoteBranches(branch)
 	if err != nil {
@@ -237,6 +259,72 @@ func (r *Repository) ResolveBranch(branch string) (resolvedBranch string, isRemo
 	return "", false, nil
 }
 
+// SplitRemoteBranch splits ref, when it names a remote-tracking branch such as
+// origin/feature/x, into the remote and the branch on that remote.
+func (r *Repository) SplitRemoteBranch(ref string) (remote, branch string, ok bool) {
+	if strings.Contains(ref, "..") || strings.ContainsAny(ref, "\n\r") {
+		return "", "", false
+	}
+
+	// #nosec G204 - ref is validated above
+	cmd := exec.Command("git", "show-ref", "--verify", "--quiet", "refs/remotes/"+ref)
+	cmd.Dir = r.path
+	if err := cmd.Run(); err != nil {
+		return "", "", false
+	}
+
+	cmd = exec.Command("git", "remote")
+	cmd.Dir = r.path
+	output, err := cmd.Output()
+	if err != nil {
+		return "", "", false
+	}
+	// Remote names may contain slashes, so the longest matching one wins
+	for _, name := range strings.Fields(string(output)) {
+		if strings.HasPrefix(ref, name+"/") && len(name) > len(remote) {
+			remote = name
+		}
+	}
+	branch = strings.TrimPrefix(ref, remote+"/")
+	// origin/HEAD points at the remote's default branch rather than being one
+	if remote == "" || branch == "HEAD" {
+		return "", "", false
+	}
+	return remote, branch, true
+}
+
+// Kinds of ref ResolveRefType tells apart.
+const (
+	RefTypeBranch = "branch"
+	RefTypeTag    = "tag"
+	RefTypeCommit = "commit"
+)
+
+// ResolveRefType tells whether ref names a tag, a local or remote-tracking branch, or
+// another commit, such as a SHA or HEAD~2. Like git, a tag wins over a branch of the
+// same name. A ref that is none of them is reported with errors.RefNotFound.
+func (r *Repository) ResolveRefType(ref string) (string, error) {
+	if ref == "" || strings.HasPrefix(ref, "-") || strings.ContainsAny(ref, "\n\r") {
+		return "", errors.RefNotFound(ref)
+	}
+	verify := func(args ...string) bool {
+		// #nosec G204 - ref is validated above
+		cmd := exec.Command("git", args...)
+		cmd.Dir = r.path
+		return cmd.Run() == nil
+	}
+	switch {
+	case verify("show-ref", "--verify", "--quiet", "refs/tags/"+ref):
+		return RefTypeTag, nil
+	case verify("show-ref", "--verify", "--quiet", "refs/heads/"+ref),
+		verify("show-ref", "--verify", "--quiet", "refs/remotes/"+ref):
+		return RefTypeBranch, nil
+	case verify("rev-parse", "--verify", "--quiet", ref+"^{commit}"):
+		return RefTypeCommit, nil
+	}
+	return "", errors.RefNotFound(ref)
+}
+
 func isGitRepository(path string) bool {
 	// Use git rev-parse to check if we're in a git repository
 	// This works for both regular repos and worktrees
@@ -272,6 +360,9 @@ func parseWorktreeList(output string) []Worktree {
 				current.HEAD = after
 			} else if after, found := strings.CutPrefix(line, "branch refs/heads/"); found {
 				current.Branch = after
+			} else if after, found := strings.CutPrefix(line, "locked"); found {
+				current.Locked = true
+				current.LockReason = strings.TrimPrefix(after, " ")
 			}
 		}
 	}
@@ -146,3 +146,18 @@ func TestGetMainWorktreePath_GitCommandFailure(t *testing.T) {
 	_, err = repo.GetMainWorktreePath()
 	assert.Error(t, err)
 }
+
+func TestGetGitCommonDir(t *testing.T) {
+	tempDir := setupTestRepo(t)
+
+	repo, err := NewRepository(tempDir)
+	assert.NoError(t, err)
+
+	commonDir, err := repo.GetGitCommonDir()
+	assert.NoError(t, err)
+	assert.True(t, filepath.IsAbs(commonDir))
+
+	expectedPath, _ := filepath.EvalSymlinks(filepath.Join(tempDir, ".git"))
+	actualPath, _ := filepath.EvalSymlinks(commonDir)
+	assert.Equal(t, expectedPath, actualPath)
+}
@@ -141,6 +141,23 @@ branch refs/heads/feature/test
 				},
 			},
 		},
+		{
+			name: "locked worktree",
+			output: `worktree /path/to/main
+HEAD abcd1234
+branch refs/heads/main
+
+worktree /path/to/feature
+HEAD efgh5678
+branch refs/heads/feature/test
+locked on a USB drive
+
+`,
+			expected: []Worktree{
+				{Path: "/path/to/main", HEAD: "abcd1234", Branch: "main"},
+				{Path: "/path/to/feature", HEAD: "efgh5678", Branch: "feature/test", Locked: true, LockReason: "on a USB drive"},
+			},
+		},
 		{
 			name:     "empty output",
 			output:   "",
@@ -167,6 +184,10 @@ branch refs/heads/feature/test
 				if result[i].Branch != expected.Branch {
 					t.Errorf("Worktree %d: expected branch %s, got %s", i, expected.Branch, result[i].Branch)
 				}
+				if result[i].Locked != expected.Locked || result[i].LockReason != expected.LockReason {
+					t.Errorf("Worktree %d: expected locked %t (%q), got %t (%q)", i,
+						expected.Locked, expected.LockReason, result[i].Locked, result[i].LockReason)
+				}
 			}
 		})
 	}
@@ -328,6 +349,13 @@ func TestBranchResolution(t *testing.T) {
 			expectRemote: true,
 			expectBranch: "origin/remote-only",
 		},
+		{
+			name:         "Remote-tracking branch",
+			branch:       "upstream/shared-branch",
+			expectError:  false,
+			expectRemote: true,
+			expectBranch: "upstream/shared-branch",
+		},
 		{
 			name:          "Branch exists in multiple remotes",
 			branch:        "shared-branch",
@@ -367,6 +395,81 @@ func TestBranchResolution(t *testing.T) {
 	}
 }
 
+func TestSplitRemoteBranch(t *testing.T) {
+	repoDir := setupTestRepo(t)
+	runCmd(t, repoDir, "git", "remote", "add", "origin", "https://example.com/repo.git")
+	runCmd(t, repoDir, "git", "remote", "add", "team/fork", "https://example.com/fork.git")
+	runCmd(t, repoDir, "git", "update-ref", "refs/remotes/origin/feature/x", "HEAD")
+	runCmd(t, repoDir, "git", "update-ref", "refs/remotes/team/fork/fix", "HEAD")
+	runCmd(t, repoDir, "git", "symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/feature/x")
+
+	repo, err := NewRepository(repoDir)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	tests := []struct {
+		ref    string
+		remote string
+		branch string
+		ok     bool
+	}{
+		{ref: "origin/feature/x", remote: "origin", branch: "feature/x", ok: true},
+		{ref: "team/fork/fix", remote: "team/fork", branch: "fix", ok: true},
+		{ref: "origin/HEAD"},
+		{ref: "origin/missing"},
+		{ref: "main"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			remote, branch, ok := repo.SplitRemoteBranch(tt.ref)
+			if remote != tt.remote || branch != tt.branch || ok != tt.ok {
+				t.Errorf("SplitRemoteBranch(%q) = %q, %q, %v; want %q, %q, %v",
+					tt.ref, remote, branch, ok, tt.remote, tt.branch, tt.ok)
+			}
+		})
+	}
+}
+
+func TestResolveRefType(t *testing.T) {
+	repoDir := setupTestRepo(t)
+	head := getHeadCommit(t, repoDir)
+	runCmd(t, repoDir, "git", "tag", "v1.2.3")
+	runCmd(t, repoDir, "git", "update-ref", "refs/remotes/origin/feature/x", "HEAD")
+
+	repo, err := NewRepository(repoDir)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	tests := []struct {
+		ref     string
+		refType string
+	}{
+		{ref: "v1.2.3", refType: RefTypeTag},
+		{ref: "main", refType: RefTypeBranch},
+		{ref: "origin/feature/x", refType: RefTypeBranch},
+		{ref: head, refType: RefTypeCommit},
+		{ref: head[:7], refType: RefTypeCommit},
+		{ref: "HEAD", refType: RefTypeCommit},
+		{ref: "v9.9.9"},
+		{ref: "--help"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			refType, err := repo.ResolveRefType(tt.ref)
+			if refType != tt.refType {
+				t.Errorf("ResolveRefType(%q) = %q; want %q", tt.ref, refType, tt.refType)
+			}
+			if (err != nil) != (tt.refType == "") {
+				t.Errorf("ResolveRefType(%q) error = %v", tt.ref, err)
+			}
+		})
+	}
+}
+
 func runCmd(t *testing.T, dir, _ string, args ...string) {
 	cmd := exec.Command("git", args...)
 	cmd.Dir = dir
@@ -20,6 +20,10 @@ type Worktree struct {
 	Branch string
 	HEAD   string
 	IsMain bool // True if this is the main/root worktree
+	// Locked is set when the worktree is locked ('git worktree lock'), which keeps it from
+	// being removed, moved, or pruned; LockReason is the reason given, if any.
+	Locked     bool
+	LockReason string
 }
 
 // Name returns the directory name of the worktree path.
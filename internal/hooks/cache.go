@@ -0,0 +1,80 @@
+package hooks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/satococoa/wtp/v2/internal/cache"
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+const (
+	// CacheDirEnv is the environment variable that points command hooks at the shared cache.
+	CacheDirEnv  = "WTP_CACHE_DIR"
+	cacheDirName = "cache"
+)
+
+// cacheEntryPattern finds the shared cache entries a command hook refers to, such as "npm"
+// in --cache "$WTP_CACHE_DIR/npm" or %WTP_CACHE_DIR%\npm.
+var cacheEntryPattern = regexp.MustCompile(`(?:\$\{?WTP_CACHE_DIR\}?|%WTP_CACHE_DIR%)[/\\]([A-Za-z0-9._-]+)`)
+
+// SharedCacheDir returns the cache directory shared by every worktree of the clone whose
+// common git directory is commonDir. Hooks keep download caches there so that each new
+// worktree does not fetch them again.
+func SharedCacheDir(commonDir string) string {
+	return filepath.Join(commonDir, onceStateDir, cacheDirName)
+}
+
+// sharedCacheDir creates the shared cache directory and returns it, or "" when the
+// repository's git directory cannot be located.
+func (e *Executor) sharedCacheDir() string {
+	commonDir, err := e.gitCommonDir()
+	if err != nil {
+		return ""
+	}
+	dir := SharedCacheDir(commonDir)
+	if err := os.MkdirAll(dir, directoryPermissions); err != nil {
+		return ""
+	}
+	return dir
+}
+
+// DownloadCacheDir returns the cache of pinned downloads, which every repository on this
+// machine shares.
+func DownloadCacheDir() (string, error) {
+	cacheDir, err := userCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve cache directory: %w", err)
+	}
+	return filepath.Join(cacheDir, filepath.FromSlash(downloadCacheSubdir)), nil
+}
+
+// recordSharedCacheUse records the shared cache entries hook refers to in its command,
+// script, or env as used by the worktree, so that 'wtp cache gc' keeps them while the
+// worktree exists. Failing to record a use never fails the hook.
+func (e *Executor) recordSharedCacheUse(hook *config.Hook, worktreePath string) {
+	texts := []string{hook.Command, hook.Run}
+	for _, value := range hook.Env {
+		texts = append(texts, value)
+	}
+	var names []string
+	for _, text := range texts {
+		for _, match := range cacheEntryPattern.FindAllStringSubmatch(text, -1) {
+			names = append(names, match[1])
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	dir := e.sharedCacheDir()
+	if dir == "" {
+		return
+	}
+	now := time.Now()
+	for _, name := range names {
+		_ = cache.Use(dir, name, worktreePath, now)
+	}
+}
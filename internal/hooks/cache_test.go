@@ -0,0 +1,95 @@
+package hooks
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/cache"
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func TestExecutePostCreateHooks_SharedCacheDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+	repoRoot := setupCopySourceRepo(t)
+	worktree := filepath.Join(t.TempDir(), "feature")
+	runGit(t, repoRoot, "worktree", "add", "-q", "--detach", worktree, "main")
+
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{{Type: config.HookTypeCommand, Command: "printf %s \"$WTP_CACHE_DIR\" > cache.txt"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(cfg, worktree).ExecutePostCreateHooks(&buf, worktree))
+
+	data, err := os.ReadFile(filepath.Join(worktree, "cache.txt"))
+	require.NoError(t, err)
+	// Every worktree of the clone gets the directory in the main repository's git dir
+	expected, err := filepath.EvalSymlinks(SharedCacheDir(filepath.Join(repoRoot, ".git")))
+	require.NoError(t, err)
+	actual, err := filepath.EvalSymlinks(strings.TrimSpace(string(data)))
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+	assert.DirExists(t, actual)
+}
+
+func TestCommandEnv_NoCacheDirOutsideRepository(t *testing.T) {
+	executor := NewExecutor(&config.Config{}, t.TempDir())
+
+	env := executor.commandEnv(&config.Hook{Type: config.HookTypeCommand}, t.TempDir())
+
+	for _, entry := range env {
+		assert.False(t, strings.HasPrefix(entry, CacheDirEnv+"="), entry)
+	}
+}
+
+func TestExecutePostCreateHooks_RecordsSharedCacheUse(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+	repoRoot := setupCopySourceRepo(t)
+	worktree := filepath.Join(t.TempDir(), "feature")
+	runGit(t, repoRoot, "worktree", "add", "-q", "--detach", worktree, "main")
+
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{{
+				Type:    config.HookTypeCommand,
+				Command: `mkdir -p "$WTP_CACHE_DIR/npm" "${WTP_CACHE_DIR}/pip" "$WTP_CACHE_DIR/yarn"`,
+				Env:     map[string]string{"PIP_CACHE_DIR": "${WTP_CACHE_DIR}/pip"},
+			}},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(cfg, worktree).ExecutePostCreateHooks(&buf, worktree))
+
+	entries, err := cache.List(SharedCacheDir(filepath.Join(repoRoot, ".git")))
+	require.NoError(t, err)
+	refs := map[string]int{}
+	for _, entry := range entries {
+		refs[entry.Name] = len(entry.Refs)
+	}
+	assert.Equal(t, map[string]int{"npm": 1, "pip": 1, "yarn": 1}, refs)
+}
+
+func TestCacheEntryPattern(t *testing.T) {
+	matches := cacheEntryPattern.FindAllStringSubmatch(
+		`npm ci --cache "$WTP_CACHE_DIR/npm" && set X=%WTP_CACHE_DIR%\gradle && echo $WTP_CACHE_DIR`, -1)
+
+	var names []string
+	for _, match := range matches {
+		names = append(names, match[1])
+	}
+	assert.Equal(t, []string{"npm", "gradle"}, names)
+}
@@ -0,0 +1,77 @@
+package hooks
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/progress"
+)
+
+// errCloneUnsupported reports that the platform has no copy-on-write clone call.
+var errCloneUnsupported = errors.New("copy-on-write clones are not supported on this platform")
+
+// cloneFile makes dst a copy-on-write clone of src, sharing its blocks until either is
+// modified; it is a variable to allow mocking in tests.
+var cloneFile = cloneFileContents
+
+// writeFileContents writes the content of src to dst, replacing dst. cow selects how; see
+// the config.CopyCOW constants: with CopyCOWAuto (or empty) a failed clone, e.g. on a file
+// system without shared extents or across file systems, falls back to copying. The bytes
+// written are counted on copied, which may be nil.
+func writeFileContents(src, dst, cow string, copied *progress.Task) error {
+	if cow != config.CopyCOWNever {
+		err := cloneFile(src, dst)
+		if err == nil {
+			countClone(dst, copied)
+			return nil
+		}
+		if cow == config.CopyCOWAlways {
+			return fmt.Errorf("failed to clone file (cow: %s): %w", config.CopyCOWAlways, err)
+		}
+	}
+	return copyFileContents(src, dst, copied)
+}
+
+// countClone counts the size of dst, a clone just made, on copied unless it is nil.
+func countClone(dst string, copied *progress.Task) {
+	if copied == nil {
+		return
+	}
+	if info, err := os.Stat(dst); err == nil {
+		copied.Add(info.Size())
+	}
+}
+
+// copyFileContents copies the content of src to dst byte by byte, counting the bytes on
+// copied unless it is nil.
+func copyFileContents(src, dst string, copied *progress.Task) error {
+	// #nosec G304 -- src is validated against the repository root by the caller
+	sourceFile, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open source file: %w", err)
+	}
+	defer func() {
+		_ = sourceFile.Close()
+	}()
+
+	// #nosec G304 -- dst is validated against the worktree path by the caller
+	destFile, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("failed to create destination file: %w", err)
+	}
+	defer func() {
+		_ = destFile.Close()
+	}()
+
+	var out io.Writer = destFile
+	if copied != nil {
+		out = io.MultiWriter(destFile, copied)
+	}
+	if _, copyErr := io.Copy(out, sourceFile); copyErr != nil {
+		return fmt.Errorf("failed to copy file: %w", copyErr)
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+//go:build darwin
+
+package hooks
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// cloneFileContents makes dst a copy-on-write clone of src with clonefile(2), which APFS
+// supports. clonefile does not replace files, so an existing dst is removed first.
+func cloneFileContents(src, dst string) error {
+	if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return unix.Clonefile(src, dst, unix.CLONE_NOFOLLOW)
+}
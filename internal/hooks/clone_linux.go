@@ -0,0 +1,36 @@
+//go:build linux
+
+package hooks
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// cloneFileContents makes dst a copy-on-write clone of src with the FICLONE ioctl, which
+// btrfs, XFS, and other file systems with shared extents support. On failure dst is removed.
+func cloneFileContents(src, dst string) (err error) {
+	// #nosec G304 -- src is validated by the copy hook
+	source, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = source.Close()
+	}()
+
+	// #nosec G304 -- dst is validated by the copy hook
+	dest, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	err = unix.IoctlFileClone(int(dest.Fd()), int(source.Fd()))
+	if closeErr := dest.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(dst)
+	}
+	return err
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package hooks
+
+// cloneFileContents reports that copy-on-write clones are not supported on this platform.
+func cloneFileContents(_, _ string) error {
+	return errCloneUnsupported
+}
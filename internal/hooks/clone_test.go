@@ -0,0 +1,131 @@
+package hooks
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/progress"
+)
+
+func mockCloneFile(t *testing.T, supported bool) *int {
+	t.Helper()
+	calls := 0
+	original := cloneFile
+	cloneFile = func(_, dst string) error {
+		calls++
+		if !supported {
+			return errCloneUnsupported
+		}
+		return os.WriteFile(dst, []byte("cloned"), 0o600)
+	}
+	t.Cleanup(func() { cloneFile = original })
+	return &calls
+}
+
+func TestWriteFileContents(t *testing.T) {
+	tests := []struct {
+		cow         string
+		supported   bool
+		wantContent string
+		wantCalls   int
+		wantErr     bool
+	}{
+		{cow: "", supported: true, wantContent: "cloned", wantCalls: 1},
+		{cow: config.CopyCOWAuto, supported: true, wantContent: "cloned", wantCalls: 1},
+		{cow: config.CopyCOWAuto, supported: false, wantContent: "content", wantCalls: 1},
+		{cow: config.CopyCOWAlways, supported: true, wantContent: "cloned", wantCalls: 1},
+		{cow: config.CopyCOWAlways, supported: false, wantCalls: 1, wantErr: true},
+		{cow: config.CopyCOWNever, supported: true, wantContent: "content"},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		src := filepath.Join(dir, "src")
+		dst := filepath.Join(dir, "dst")
+		require.NoError(t, os.WriteFile(src, []byte("content"), 0o600))
+		calls := mockCloneFile(t, tt.supported)
+
+		err := writeFileContents(src, dst, tt.cow, nil)
+		assert.Equal(t, tt.wantCalls, *calls, "cow=%q", tt.cow)
+		if tt.wantErr {
+			assert.ErrorContains(t, err, "failed to clone file (cow: always)")
+			assert.ErrorIs(t, err, errCloneUnsupported)
+			continue
+		}
+		require.NoError(t, err, "cow=%q", tt.cow)
+		content, err := os.ReadFile(dst)
+		require.NoError(t, err)
+		assert.Equal(t, tt.wantContent, string(content), "cow=%q supported=%v", tt.cow, tt.supported)
+	}
+}
+
+func TestWriteFileContents_CountsBytesCopied(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	require.NoError(t, os.WriteFile(src, []byte("content"), 0o600))
+	task := progress.New(io.Discard, false, nil).Start("Hook 1 (copy)")
+	defer task.Done()
+
+	require.NoError(t, writeFileContents(src, filepath.Join(dir, "copied"), config.CopyCOWNever, task))
+	assert.Equal(t, int64(len("content")), task.Copied())
+
+	mockCloneFile(t, true)
+	require.NoError(t, writeFileContents(src, filepath.Join(dir, "cloned"), config.CopyCOWAuto, task))
+	assert.Equal(t, int64(len("content")+len("cloned")), task.Copied(), "a clone counts its size")
+}
+
+func TestExecutePostCreateHooks_WithProgress(t *testing.T) {
+	repoRoot := t.TempDir()
+	worktree := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "data.bin"), []byte("data"), 0o644))
+	cfg := &config.Config{Hooks: config.Hooks{PostCreate: []config.Hook{
+		{Type: config.HookTypeCopy, From: "data.bin", To: "data.bin", COW: config.CopyCOWNever},
+	}}}
+
+	var terminal, output bytes.Buffer
+	display := progress.New(&terminal, false, nil)
+	require.NoError(t, NewExecutor(cfg, repoRoot).WithProgress(display).ExecutePostCreateHooks(&output, worktree))
+	assert.Contains(t, output.String(), "✓ Hook 1 completed")
+	assert.Empty(t, terminal.String(), "quick hooks are not shown")
+}
+
+func TestCloneFileContents(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	dst := filepath.Join(dir, "dst")
+	require.NoError(t, os.WriteFile(src, []byte("content"), 0o600))
+	require.NoError(t, os.WriteFile(dst, []byte("old content"), 0o600))
+
+	if err := cloneFileContents(src, dst); err != nil {
+		t.Skipf("the file system of %s cannot clone files: %v", dir, err)
+	}
+	content, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(content))
+}
+
+func TestExecuteCopyHook_COW(t *testing.T) {
+	repoRoot := t.TempDir()
+	worktree := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(repoRoot, "node_modules", "pkg"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "node_modules", "pkg", "index.js"), []byte("x"), 0o644))
+	calls := mockCloneFile(t, false)
+
+	hook := &config.Hook{Type: config.HookTypeCopy, From: "node_modules", To: "node_modules", COW: config.CopyCOWAlways}
+	executor := NewExecutor(&config.Config{}, repoRoot)
+	err := executor.executeCopyHookWithWriter(io.Discard, hook, worktree)
+	require.ErrorIs(t, err, errCloneUnsupported)
+	assert.Equal(t, 1, *calls)
+
+	hook.COW = config.CopyCOWAuto
+	require.NoError(t, executor.executeCopyHookWithWriter(io.Discard, hook, worktree))
+	content, err := os.ReadFile(filepath.Join(worktree, "node_modules", "pkg", "index.js"))
+	require.NoError(t, err)
+	assert.Equal(t, "x", string(content))
+}
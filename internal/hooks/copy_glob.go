@@ -0,0 +1,62 @@
+package hooks
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+// copyGlob copies every path matching pattern into dstDir. Each match keeps its path
+// relative to the pattern's fixed leading directories, so "config/*/app.yml" copied to
+// "conf" produces "conf/<dir>/app.yml". Matching directories are copied recursively, and
+// cow applies as in copyFile.
+func (e *Executor) copyGlob(w io.Writer, sourceRoot, pattern, dstDir, worktreePath, cow string) error {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid glob pattern %s: %w", pattern, err)
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("no files match %s", pattern)
+	}
+
+	base := config.GlobBase(pattern)
+	for _, match := range matches {
+		if err := ensureWithinBase(sourceRoot, match); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(base, match)
+		if err != nil {
+			return err
+		}
+		dstPath := filepath.Join(dstDir, rel)
+
+		srcInfo, err := os.Stat(match)
+		if err != nil {
+			return fmt.Errorf("source path does not exist: %s", match)
+		}
+		if err := ensureDistinctPaths(match, dstPath, srcInfo); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(dstPath), directoryPermissions); err != nil {
+			return fmt.Errorf("failed to create destination directory: %w", err)
+		}
+
+		relSrc, _ := filepath.Rel(sourceRoot, match)
+		relDst, _ := filepath.Rel(worktreePath, dstPath)
+		if _, err := fmt.Fprintf(w, "  Copying: %s → %s\n", relSrc, relDst); err != nil {
+			return err
+		}
+		if srcInfo.IsDir() {
+			err = e.copyDir(match, dstPath, cow)
+		} else {
+			err = e.copyFile(match, dstPath, cow)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
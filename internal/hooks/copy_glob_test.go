@@ -0,0 +1,62 @@
+package hooks
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func TestExecutePostCreateHooks_CopyGlob(t *testing.T) {
+	repoRoot := t.TempDir()
+	worktreeDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(repoRoot, "config", "nested"), directoryPermissions))
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "config", "app.example"), []byte("app"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "config", "run.example"), []byte("run"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "config", "keep.yml"), []byte("keep"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "config", "nested", "a.txt"), []byte("a"), 0o644))
+
+	hooks := []config.Hook{
+		{Type: config.HookTypeCopy, From: "config/*.example"},
+		{Type: config.HookTypeCopy, From: "config/n*", To: "copied"},
+	}
+	for i := range hooks {
+		hooks[i].ApplyDefaults()
+	}
+	cfg := &config.Config{Hooks: config.Hooks{PostCreate: hooks}}
+
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(cfg, repoRoot).ExecutePostCreateHooks(&buf, worktreeDir))
+
+	content, err := os.ReadFile(filepath.Join(worktreeDir, "config", "app.example"))
+	require.NoError(t, err)
+	assert.Equal(t, "app", string(content))
+	assert.NoFileExists(t, filepath.Join(worktreeDir, "config", "keep.yml"))
+	assert.FileExists(t, filepath.Join(worktreeDir, "copied", "nested", "a.txt"))
+	assert.Contains(t, buf.String(), "Copying: config/run.example → config/run.example")
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(filepath.Join(worktreeDir, "config", "run.example"))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+	}
+}
+
+func TestExecutePostCreateHooks_CopyGlobNoMatches(t *testing.T) {
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{{Type: config.HookTypeCopy, From: "config/*.missing", To: "config"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	err := NewExecutor(cfg, t.TempDir()).ExecutePostCreateHooks(&buf, t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no files match")
+}
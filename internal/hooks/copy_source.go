@@ -0,0 +1,162 @@
+package hooks
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+const (
+	gitModeExecutable  = "100755"
+	gitModeSymlink     = "120000"
+	gitObjectTypeBlob  = "blob"
+	executableFileMode = 0o755
+	regularFileMode    = 0o644
+	lsTreeFieldCount   = 3
+)
+
+// resolveWorktreeRoot maps a worktree name as accepted by 'wtp cd' ("@", a branch,
+// or a path relative to base_dir) to the absolute path of that worktree.
+func (e *Executor) resolveWorktreeRoot(name string) (string, error) {
+	repo, err := git.NewRepository(e.repoRoot)
+	if err != nil {
+		return "", err
+	}
+
+	worktrees, err := repo.GetWorktrees()
+	if err != nil {
+		return "", err
+	}
+
+	baseDir := ""
+	if e.config != nil {
+		baseDir = e.config.ResolveWorktreePath(e.repoRoot, "")
+	}
+
+	names := make([]string, 0, len(worktrees))
+	for _, wt := range worktrees {
+		if wt.IsMain && (name == "@" || name == "root") {
+			return wt.Path, nil
+		}
+		if wt.Branch == name || filepath.Base(wt.Path) == name {
+			return wt.Path, nil
+		}
+		if baseDir != "" {
+			if rel, relErr := filepath.Rel(baseDir, wt.Path); relErr == nil && filepath.ToSlash(rel) == name {
+				return wt.Path, nil
+			}
+		}
+		names = append(names, filepath.Base(wt.Path))
+	}
+
+	return "", fmt.Errorf("source worktree '%s' not found (available: %s)", name, strings.Join(names, ", "))
+}
+
+// lsTreeEntry is a single blob reported by 'git ls-tree'.
+type lsTreeEntry struct {
+	mode   string
+	object string
+	path   string
+}
+
+// copyFromRef copies the file or directory at srcPath (relative to the repository root)
+// as it exists in ref into dstPath, without touching any worktree.
+func (e *Executor) copyFromRef(ref, srcPath, dstPath string) error {
+	pathspec := filepath.ToSlash(filepath.Clean(srcPath))
+
+	output, err := e.gitOutput("ls-tree", "-r", "-z", ref, "--", pathspec)
+	if err != nil {
+		return fmt.Errorf("failed to read '%s' from ref '%s': %w", srcPath, ref, err)
+	}
+
+	entries := parseLsTree(output)
+	if len(entries) == 0 {
+		return fmt.Errorf("source path does not exist in ref '%s': %s", ref, srcPath)
+	}
+
+	prefix := pathspec + "/"
+	if pathspec == "." {
+		prefix = ""
+	}
+
+	for _, entry := range entries {
+		target := dstPath
+		if entry.path != pathspec {
+			target = filepath.Join(dstPath, filepath.FromSlash(strings.TrimPrefix(entry.path, prefix)))
+		}
+		if err := e.writeBlob(entry, target); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *Executor) writeBlob(entry lsTreeEntry, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), directoryPermissions); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	content, err := e.gitOutput("cat-file", "blob", entry.object)
+	if err != nil {
+		return fmt.Errorf("failed to read %s from git: %w", entry.path, err)
+	}
+
+	if entry.mode == gitModeSymlink {
+		if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to replace destination path: %w", err)
+		}
+		if err := os.Symlink(string(content), target); err != nil {
+			return fmt.Errorf("failed to create symlink: %w", err)
+		}
+		return nil
+	}
+
+	var perm os.FileMode = regularFileMode
+	if entry.mode == gitModeExecutable {
+		perm = executableFileMode
+	}
+	if err := os.WriteFile(target, content, perm); err != nil {
+		return fmt.Errorf("failed to create destination file: %w", err)
+	}
+	return nil
+}
+
+func (e *Executor) gitOutput(args ...string) ([]byte, error) {
+	// #nosec G204 -- arguments are built by wtp from validated configuration
+	cmd := exec.Command("git", args...)
+	cmd.Dir = e.repoRoot
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return output, nil
+}
+
+// parseLsTree parses 'git ls-tree -z' output, keeping blob entries only
+// (submodules and other object types are skipped).
+func parseLsTree(output []byte) []lsTreeEntry {
+	var entries []lsTreeEntry
+	for _, record := range strings.Split(string(output), "\x00") {
+		meta, path, found := strings.Cut(record, "\t")
+		if !found {
+			continue
+		}
+		fields := strings.Fields(meta)
+		if len(fields) != lsTreeFieldCount || fields[1] != gitObjectTypeBlob {
+			continue
+		}
+		entries = append(entries, lsTreeEntry{mode: fields[0], object: fields[2], path: path})
+	}
+	return entries
+}
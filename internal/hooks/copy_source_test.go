@@ -0,0 +1,154 @@
+package hooks
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %v: %s", args, output)
+}
+
+// setupCopySourceRepo creates a repository whose main branch contains files that
+// are deleted on the checked-out 'feature' branch.
+func setupCopySourceRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repoRoot := t.TempDir()
+	runGit(t, repoRoot, "init", "-b", "main")
+	runGit(t, repoRoot, "config", "user.email", "test@example.com")
+	runGit(t, repoRoot, "config", "user.name", "Test")
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "settings.json"), []byte("{}"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(repoRoot, "scripts", "nested"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "scripts", "run.sh"), []byte("#!/bin/sh\n"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "scripts", "nested", "a.txt"), []byte("a"), 0o644))
+	runGit(t, repoRoot, "add", ".")
+	runGit(t, repoRoot, "commit", "-m", "initial")
+
+	runGit(t, repoRoot, "checkout", "-b", "feature")
+	runGit(t, repoRoot, "rm", "-r", "-q", "settings.json", "scripts")
+	runGit(t, repoRoot, "commit", "-m", "drop files")
+
+	return repoRoot
+}
+
+func TestExecutePostCreateHooks_CopyFromRef(t *testing.T) {
+	repoRoot := setupCopySourceRepo(t)
+	worktreeDir := t.TempDir()
+
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{Type: config.HookTypeCopy, From: "settings.json", To: "config/settings.json", FromRef: "main"},
+				{Type: config.HookTypeCopy, From: "scripts", To: "scripts", FromRef: "main"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	executor := NewExecutor(cfg, repoRoot)
+	require.NoError(t, executor.ExecutePostCreateHooks(&buf, worktreeDir))
+
+	content, err := os.ReadFile(filepath.Join(worktreeDir, "config", "settings.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "{}", string(content))
+
+	content, err = os.ReadFile(filepath.Join(worktreeDir, "scripts", "nested", "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "a", string(content))
+
+	info, err := os.Stat(filepath.Join(worktreeDir, "scripts", "run.sh"))
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode().Perm()&0o100, "executable bit should be preserved")
+
+	assert.Contains(t, buf.String(), "Copying: main:settings.json → config/settings.json")
+
+	_, err = os.Stat(filepath.Join(repoRoot, "settings.json"))
+	assert.True(t, os.IsNotExist(err), "main worktree must not be touched")
+}
+
+func TestExecutePostCreateHooks_CopyFromRef_MissingPath(t *testing.T) {
+	repoRoot := setupCopySourceRepo(t)
+
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{Type: config.HookTypeCopy, From: "missing.txt", To: "missing.txt", FromRef: "main"},
+			},
+		},
+	}
+
+	err := NewExecutor(cfg, repoRoot).ExecutePostCreateHooks(&bytes.Buffer{}, t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not exist in ref 'main'")
+}
+
+func TestExecutePostCreateHooks_CopyFromWorktree(t *testing.T) {
+	repoRoot := setupCopySourceRepo(t)
+	otherWorktree := filepath.Join(t.TempDir(), "other")
+	runGit(t, repoRoot, "worktree", "add", "-q", otherWorktree, "main")
+	require.NoError(t, os.WriteFile(filepath.Join(otherWorktree, ".env"), []byte("FROM=other"), 0o600))
+
+	worktreeDir := t.TempDir()
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{Type: config.HookTypeCopy, From: ".env", To: ".env", FromWorktree: "other"},
+				{Type: config.HookTypeCopy, From: "scripts/nested", To: "nested", FromWorktree: "main"},
+			},
+		},
+	}
+
+	require.NoError(t, NewExecutor(cfg, repoRoot).ExecutePostCreateHooks(&bytes.Buffer{}, worktreeDir))
+
+	content, err := os.ReadFile(filepath.Join(worktreeDir, ".env"))
+	require.NoError(t, err)
+	assert.Equal(t, "FROM=other", string(content))
+
+	_, err = os.Stat(filepath.Join(worktreeDir, "nested", "a.txt"))
+	assert.NoError(t, err)
+}
+
+func TestExecutePostCreateHooks_CopyFromWorktree_NotFound(t *testing.T) {
+	repoRoot := setupCopySourceRepo(t)
+
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{Type: config.HookTypeCopy, From: ".env", To: ".env", FromWorktree: "nope"},
+			},
+		},
+	}
+
+	err := NewExecutor(cfg, repoRoot).ExecutePostCreateHooks(&bytes.Buffer{}, t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "source worktree 'nope' not found")
+}
+
+func TestParseLsTree(t *testing.T) {
+	output := []byte("100644 blob aaa\tfile.txt\x00" +
+		"100755 blob bbb\tbin/run\x00" +
+		"160000 commit ccc\tvendor/sub\x00")
+
+	entries := parseLsTree(output)
+
+	require.Len(t, entries, 2)
+	assert.Equal(t, lsTreeEntry{mode: "100644", object: "aaa", path: "file.txt"}, entries[0])
+	assert.Equal(t, lsTreeEntry{mode: "100755", object: "bbb", path: "bin/run"}, entries[1])
+}
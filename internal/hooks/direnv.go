@@ -0,0 +1,82 @@
+package hooks
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+// direnvProgram is the direnv executable, looked up in PATH.
+const direnvProgram = "direnv"
+
+// executeDirenvHookWithWriter installs hook.From, if set, as the worktree's .envrc (or
+// 'to') and runs 'direnv allow' on it, so direnv loads it once the shell enters the
+// worktree. The .envrc is only rewritten when it changed, so the hook can be re-run.
+func (e *Executor) executeDirenvHookWithWriter(w io.Writer, hook *config.Hook, worktreePath string) error {
+	envrcHook := *hook
+	envrcHook.To = hook.EnvrcFile()
+	_, srcPath, envrcPath, err := e.resolveHookPaths(&envrcHook, worktreePath)
+	if err != nil {
+		return err
+	}
+
+	if hook.From != "" {
+		if err := installEnvrc(w, hook, srcPath, envrcPath); err != nil {
+			return err
+		}
+	}
+	if _, err := os.Stat(envrcPath); err != nil {
+		return fmt.Errorf("%s does not exist in the worktree; set 'from' to the file to install", envrcHook.To)
+	}
+
+	if _, err := exec.LookPath(direnvProgram); err != nil {
+		return fmt.Errorf("direnv is not installed: %w", err)
+	}
+	if _, err := fmt.Fprintf(w, "  direnv allow: %s\n", envrcHook.To); err != nil {
+		return err
+	}
+	// #nosec G204 -- the path is validated against the worktree path
+	cmd := exec.CommandContext(e.ctx, direnvProgram, "allow", envrcPath)
+	cmd.Dir = worktreePath
+	cmd.Stdout = w
+	cmd.Stderr = w
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("direnv allow failed: %w", err)
+	}
+	return nil
+}
+
+// installEnvrc copies or links srcPath to envrcPath according to the hook's 'mode', unless
+// envrcPath is already up to date.
+func installEnvrc(w io.Writer, hook *config.Hook, srcPath, envrcPath string) error {
+	if info, err := os.Stat(srcPath); err != nil || info.IsDir() {
+		return fmt.Errorf("envrc source does not exist: %s", srcPath)
+	}
+	if err := os.MkdirAll(filepath.Dir(envrcPath), directoryPermissions); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	mode := hook.Mode
+	if mode == "" {
+		mode = config.GitHooksModeCopy
+	}
+	var current bool
+	var err error
+	if mode == config.GitHooksModeSymlink {
+		current, err = syncGitHookLink(srcPath, envrcPath)
+	} else {
+		current, err = syncGitHookCopy(srcPath, envrcPath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to install %s: %w", hook.EnvrcFile(), err)
+	}
+	if current {
+		return nil
+	}
+	_, err = fmt.Fprintf(w, "  Envrc (%s): %s → %s\n", mode, hook.From, hook.EnvrcFile())
+	return err
+}
@@ -0,0 +1,87 @@
+package hooks
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+// fakeDirenv puts a direnv on PATH that records its arguments, and returns the file it
+// records them in.
+func fakeDirenv(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping shell script test on Windows")
+	}
+	binDir := t.TempDir()
+	logFile := filepath.Join(binDir, "calls")
+	script := "#!/bin/sh\necho \"$@\" >> " + logFile + "\n"
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "direnv"), []byte(script), 0o755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return logFile
+}
+
+func TestExecutePostCreateHooks_Direnv(t *testing.T) {
+	logFile := fakeDirenv(t)
+	repoRoot := t.TempDir()
+	worktreeDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, ".envrc.worktree"), []byte("use flake\n"), 0o644))
+
+	cfg := &config.Config{Hooks: config.Hooks{PostCreate: []config.Hook{
+		{Type: config.HookTypeDirenv, From: ".envrc.worktree"},
+	}}}
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(cfg, repoRoot).ExecutePostCreateHooks(&buf, worktreeDir))
+	assert.Contains(t, buf.String(), "Envrc (copy): .envrc.worktree → .envrc")
+	assert.Contains(t, buf.String(), "direnv allow: .envrc")
+
+	envrc := filepath.Join(worktreeDir, ".envrc")
+	content, err := os.ReadFile(envrc)
+	require.NoError(t, err)
+	assert.Equal(t, "use flake\n", string(content))
+	calls, err := os.ReadFile(logFile)
+	require.NoError(t, err)
+	assert.Equal(t, "allow "+envrc+"\n", string(calls))
+
+	// A second run leaves the unchanged .envrc alone but allows it again
+	buf.Reset()
+	require.NoError(t, NewExecutor(cfg, repoRoot).ExecutePostCreateHooks(&buf, worktreeDir))
+	assert.NotContains(t, buf.String(), "Envrc (copy)")
+	assert.Contains(t, buf.String(), "direnv allow: .envrc")
+}
+
+func TestExecutePostCreateHooks_DirenvSymlinkTo(t *testing.T) {
+	fakeDirenv(t)
+	repoRoot := t.TempDir()
+	worktreeDir := t.TempDir()
+	src := filepath.Join(repoRoot, "envrc")
+	require.NoError(t, os.WriteFile(src, []byte("dotenv\n"), 0o644))
+
+	cfg := &config.Config{Hooks: config.Hooks{PostCreate: []config.Hook{
+		{Type: config.HookTypeDirenv, From: "envrc", To: "services/api/.envrc", Mode: config.GitHooksModeSymlink},
+	}}}
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(cfg, repoRoot).ExecutePostCreateHooks(&buf, worktreeDir))
+
+	target, err := os.Readlink(filepath.Join(worktreeDir, "services", "api", ".envrc"))
+	require.NoError(t, err)
+	assert.Equal(t, src, target)
+}
+
+func TestExecutePostCreateHooks_DirenvMissingEnvrc(t *testing.T) {
+	logFile := fakeDirenv(t)
+	cfg := &config.Config{Hooks: config.Hooks{PostCreate: []config.Hook{{Type: config.HookTypeDirenv}}}}
+
+	var buf bytes.Buffer
+	err := NewExecutor(cfg, t.TempDir()).ExecutePostCreateHooks(&buf, t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), ".envrc does not exist in the worktree")
+	assert.NoFileExists(t, logFile)
+}
@@ -0,0 +1,155 @@
+package hooks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/satococoa/wtp/v2/internal/cache"
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+const (
+	downloadTimeout      = 10 * time.Minute
+	downloadCacheSubdir  = "wtp/downloads"
+	checksumPrefixSHA256 = "sha256:"
+)
+
+// Variables to allow mocking in tests
+var (
+	downloadHTTPClient = &http.Client{Timeout: downloadTimeout}
+	userCacheDir       = os.UserCacheDir
+)
+
+// executeDownloadHookWithWriter fetches hook.URL into hook.To (relative to the worktree).
+// Downloads pinned by checksum are verified and cached by content in the user cache dir; the
+// cache is shared by all repositories and clones, which is safe because entries are keyed by digest.
+func (e *Executor) executeDownloadHookWithWriter(w io.Writer, hook *config.Hook, worktreePath string) error {
+	dstPath := hook.To
+	if !filepath.IsAbs(dstPath) {
+		dstPath = filepath.Join(worktreePath, dstPath)
+	}
+	dstPath = filepath.Clean(dstPath)
+	if !filepath.IsAbs(hook.To) {
+		if err := ensureWithinBase(worktreePath, dstPath); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dstPath), directoryPermissions); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	relDst, _ := filepath.Rel(worktreePath, dstPath)
+	digest := strings.TrimPrefix(hook.Checksum, checksumPrefixSHA256)
+
+	if digest == "" {
+		if _, err := fmt.Fprintf(w, "  Downloading: %s → %s\n", hook.URL, relDst); err != nil {
+			return err
+		}
+		_, err := download(hook, dstPath)
+		return err
+	}
+
+	cacheDir, err := DownloadCacheDir()
+	if err != nil {
+		return err
+	}
+	entry := "sha256-" + digest
+	cachePath := filepath.Join(cacheDir, entry)
+
+	if sum, err := fileSHA256(cachePath); err == nil && sum == digest {
+		if _, err := fmt.Fprintf(w, "  Downloading: %s → %s (cached)\n", hook.URL, relDst); err != nil {
+			return err
+		}
+		_ = cache.Use(cacheDir, entry, worktreePath, time.Now()) // bookkeeping for 'wtp cache gc'
+		return e.copyFile(cachePath, dstPath, config.CopyCOWAuto)
+	}
+
+	if _, err := fmt.Fprintf(w, "  Downloading: %s → %s\n", hook.URL, relDst); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cachePath), directoryPermissions); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	sum, err := download(hook, cachePath)
+	if err != nil {
+		return err
+	}
+	if sum != digest {
+		_ = os.Remove(cachePath)
+		return fmt.Errorf("checksum mismatch for %s: expected sha256:%s, got sha256:%s", hook.URL, digest, sum)
+	}
+	_ = cache.Use(cacheDir, entry, worktreePath, time.Now())
+	return e.copyFile(cachePath, dstPath, config.CopyCOWAuto)
+}
+
+// download writes the response body for hook.URL to dstPath atomically and
+// returns the hex-encoded SHA-256 of the content.
+func download(hook *config.Hook, dstPath string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, hook.URL, http.NoBody)
+	if err != nil {
+		return "", fmt.Errorf("invalid download URL: %w", err)
+	}
+	if hook.AuthHeaderEnv != "" {
+		value, ok := os.LookupEnv(hook.AuthHeaderEnv)
+		if !ok || value == "" {
+			return "", fmt.Errorf("environment variable %s for the download auth header is not set", hook.AuthHeaderEnv)
+		}
+		req.Header.Set("Authorization", value)
+	}
+
+	resp, err := downloadHTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", hook.URL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download %s: server returned %s", hook.URL, resp.Status)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dstPath), ".wtp-download-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hash), resp.Body); err != nil {
+		_ = tmp.Close()
+		return "", fmt.Errorf("failed to download %s: %w", hook.URL, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write downloaded file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), regularFileMode); err != nil {
+		return "", fmt.Errorf("failed to set file permissions: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), dstPath); err != nil {
+		return "", fmt.Errorf("failed to write downloaded file: %w", err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func fileSHA256(path string) (string, error) {
+	// #nosec G304 -- path is inside the wtp cache directory
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = file.Close() }()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
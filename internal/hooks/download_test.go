@@ -0,0 +1,184 @@
+package hooks
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func newDownloadTestServer(t *testing.T, body string, requests *int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		if r.URL.Path == "/private" && r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func useTestCacheDir(t *testing.T) string {
+	t.Helper()
+	cacheDir := t.TempDir()
+	original := userCacheDir
+	userCacheDir = func() (string, error) { return cacheDir, nil }
+	t.Cleanup(func() { userCacheDir = original })
+	return cacheDir
+}
+
+func sha256Checksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func TestExecutePostCreateHooks_Download(t *testing.T) {
+	useTestCacheDir(t)
+	var requests int32
+	server := newDownloadTestServer(t, "seed data", &requests)
+	worktreeDir := t.TempDir()
+
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{Type: config.HookTypeDownload, URL: server.URL + "/seed.sql", To: "db/seed.sql"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(cfg, t.TempDir()).ExecutePostCreateHooks(&buf, worktreeDir))
+
+	content, err := os.ReadFile(filepath.Join(worktreeDir, "db", "seed.sql"))
+	require.NoError(t, err)
+	assert.Equal(t, "seed data", string(content))
+	assert.Contains(t, buf.String(), "Downloading: "+server.URL+"/seed.sql → db/seed.sql")
+}
+
+func TestExecutePostCreateHooks_DownloadChecksumCached(t *testing.T) {
+	useTestCacheDir(t)
+	var requests int32
+	server := newDownloadTestServer(t, "binary", &requests)
+
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{
+					Type:     config.HookTypeDownload,
+					URL:      server.URL + "/tool",
+					To:       "bin/tool",
+					Checksum: sha256Checksum("binary"),
+				},
+			},
+		},
+	}
+	executor := NewExecutor(cfg, t.TempDir())
+
+	for range 2 {
+		worktreeDir := t.TempDir()
+		var buf bytes.Buffer
+		require.NoError(t, executor.ExecutePostCreateHooks(&buf, worktreeDir))
+		content, err := os.ReadFile(filepath.Join(worktreeDir, "bin", "tool"))
+		require.NoError(t, err)
+		assert.Equal(t, "binary", string(content))
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests), "second run should be served from the cache")
+}
+
+func TestExecutePostCreateHooks_DownloadChecksumMismatch(t *testing.T) {
+	cacheDir := useTestCacheDir(t)
+	var requests int32
+	server := newDownloadTestServer(t, "tampered", &requests)
+	worktreeDir := t.TempDir()
+
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{
+					Type:     config.HookTypeDownload,
+					URL:      server.URL + "/tool",
+					To:       "tool",
+					Checksum: sha256Checksum("expected"),
+				},
+			},
+		},
+	}
+
+	err := NewExecutor(cfg, t.TempDir()).ExecutePostCreateHooks(&bytes.Buffer{}, worktreeDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch")
+
+	_, statErr := os.Stat(filepath.Join(worktreeDir, "tool"))
+	assert.True(t, os.IsNotExist(statErr), "mismatched content must not reach the worktree")
+	entries, _ := os.ReadDir(filepath.Join(cacheDir, "wtp", "downloads"))
+	assert.Empty(t, entries, "mismatched content must not stay in the cache")
+}
+
+func TestExecutePostCreateHooks_DownloadAuthHeaderFromEnv(t *testing.T) {
+	useTestCacheDir(t)
+	var requests int32
+	server := newDownloadTestServer(t, "private", &requests)
+
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{
+					Type:          config.HookTypeDownload,
+					URL:           server.URL + "/private",
+					To:            "fixture.json",
+					AuthHeaderEnv: "WTP_TEST_ARTIFACT_AUTH",
+				},
+			},
+		},
+	}
+	executor := NewExecutor(cfg, t.TempDir())
+
+	t.Setenv("WTP_TEST_ARTIFACT_AUTH", "")
+	err := executor.ExecutePostCreateHooks(&bytes.Buffer{}, t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "WTP_TEST_ARTIFACT_AUTH")
+
+	t.Setenv("WTP_TEST_ARTIFACT_AUTH", "Bearer wrong")
+	err = executor.ExecutePostCreateHooks(&bytes.Buffer{}, t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+
+	t.Setenv("WTP_TEST_ARTIFACT_AUTH", "Bearer secret")
+	worktreeDir := t.TempDir()
+	require.NoError(t, executor.ExecutePostCreateHooks(&bytes.Buffer{}, worktreeDir))
+	content, err := os.ReadFile(filepath.Join(worktreeDir, "fixture.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "private", string(content))
+}
+
+func TestExecutePostCreateHooks_DownloadPathTraversal(t *testing.T) {
+	useTestCacheDir(t)
+	var requests int32
+	server := newDownloadTestServer(t, "x", &requests)
+
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{Type: config.HookTypeDownload, URL: server.URL, To: "../escape"},
+			},
+		},
+	}
+
+	err := NewExecutor(cfg, t.TempDir()).ExecutePostCreateHooks(&bytes.Buffer{}, t.TempDir())
+	require.Error(t, err)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&requests))
+}
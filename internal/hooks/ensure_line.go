@@ -0,0 +1,163 @@
+package hooks
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+// executeEnsureLineHookWithWriter keeps hook.Line present in hook.File, like Ansible's
+// lineinfile. ${NAME} references in the line are expanded as for patch hooks, and the
+// file is only rewritten when it has to change.
+func (e *Executor) executeEnsureLineHookWithWriter(w io.Writer, hook *config.Hook, worktreePath string) error {
+	target, err := hookFilePath(hook, worktreePath)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "  Ensuring line in: %s\n", hook.File); err != nil {
+		return err
+	}
+
+	_, updated, err := e.ensuredLineContent(hook, target, worktreePath)
+	if err != nil {
+		return err
+	}
+	if updated == nil {
+		_, err := fmt.Fprintf(w, "  %s is already up to date\n", hook.File)
+		return err
+	}
+	return writePatchedFile(target, updated)
+}
+
+// ensuredLineContent returns the content of target before and after the ensure_line hook;
+// updated is nil when the line is already in place, and original is nil when target does
+// not exist.
+func (e *Executor) ensuredLineContent(
+	hook *config.Hook, target, worktreePath string,
+) (original, updated []byte, err error) {
+	// #nosec G304 -- target comes from the project configuration file
+	original, err = os.ReadFile(target)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", hook.File, err)
+	}
+	content := string(original)
+	line := e.valueExpander(hook, worktreePath)(hook.Line)
+
+	var ensured string
+	switch {
+	case hook.Marker != "":
+		ensured = ensureMarkedBlock(content, hook.Marker, line)
+	case hook.Match != "":
+		pattern, err := regexp.Compile(hook.Match)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid 'match' pattern: %w", err)
+		}
+		ensured = ensureMatchedLine(content, pattern, line)
+	default:
+		ensured = ensureLines(content, line)
+	}
+
+	if original != nil && ensured == content {
+		return original, nil, nil
+	}
+	return original, []byte(ensured), nil
+}
+
+// ensureLines appends block unless its lines already appear consecutively in content.
+func ensureLines(content, block string) string {
+	lines := splitLines(content)
+	want := splitLines(block)
+	for i := 0; i+len(want) <= len(lines); i++ {
+		if equalLines(lines[i:i+len(want)], want) {
+			return content
+		}
+	}
+	return appendBlock(content, block)
+}
+
+// ensureMatchedLine replaces the last line matching pattern with line, or appends line
+// when nothing matches.
+func ensureMatchedLine(content string, pattern *regexp.Regexp, line string) string {
+	lines := splitLines(content)
+	for i := len(lines) - 1; i >= 0; i-- {
+		if pattern.MatchString(lines[i]) {
+			lines[i] = line
+			return joinLines(lines, content)
+		}
+	}
+	return ensureLines(content, line)
+}
+
+// ensureMarkedBlock keeps block between '# BEGIN wtp <marker>' and '# END wtp <marker>',
+// replacing whatever is there, or appends the marked block.
+func ensureMarkedBlock(content, marker, block string) string {
+	begin, end := "# BEGIN wtp "+marker, "# END wtp "+marker
+	managed := append(append([]string{begin}, splitLines(block)...), end)
+
+	lines := splitLines(content)
+	start, stop := -1, -1
+	for i, line := range lines {
+		switch {
+		case line == begin && start < 0:
+			start = i
+		case line == end && start >= 0:
+			stop = i
+		}
+		if stop >= 0 {
+			break
+		}
+	}
+	if start < 0 || stop < 0 {
+		return appendBlock(content, strings.Join(managed, "\n"))
+	}
+
+	replaced := make([]string, 0, len(lines)-(stop-start+1)+len(managed))
+	replaced = append(replaced, lines[:start]...)
+	replaced = append(replaced, managed...)
+	replaced = append(replaced, lines[stop+1:]...)
+	return joinLines(replaced, content)
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+// joinLines rebuilds file content from lines, keeping the original trailing newline
+// (or adding one to a new file).
+func joinLines(lines []string, original string) string {
+	joined := strings.Join(lines, "\n")
+	if original == "" || strings.HasSuffix(original, "\n") {
+		joined += "\n"
+	}
+	return joined
+}
+
+func appendBlock(content, block string) string {
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + strings.TrimSuffix(block, "\n") + "\n"
+}
+
+func equalLines(a, b []string) bool {
+	for i := range b {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
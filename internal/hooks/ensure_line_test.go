@@ -0,0 +1,77 @@
+package hooks
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func TestEnsureLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		block   string
+		want    string
+	}{
+		{"append to empty", "", "node_modules", "node_modules\n"},
+		{"already present", "a\nnode_modules\nb\n", "node_modules", "a\nnode_modules\nb\n"},
+		{"missing trailing newline", "a", "b", "a\nb\n"},
+		{"block present", "x\na\nb\ny\n", "a\nb\n", "x\na\nb\ny\n"},
+		{"block partially present", "a\nc\n", "a\nb", "a\nc\na\nb\n"},
+		{"crlf content", "a\r\nb\r\n", "b", "a\r\nb\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ensureLines(tt.content, tt.block))
+		})
+	}
+}
+
+func TestEnsureMatchedLine(t *testing.T) {
+	pattern := regexp.MustCompile(`^127\.0\.0\.1\s+app\.local`)
+	assert.Equal(t, "# hosts\n127.0.0.1 app.local api.local\n",
+		ensureMatchedLine("# hosts\n127.0.0.1 app.local\n", pattern, "127.0.0.1 app.local api.local"))
+	assert.Equal(t, "# hosts\n127.0.0.1 app.local\n",
+		ensureMatchedLine("# hosts\n", pattern, "127.0.0.1 app.local"))
+}
+
+func TestEnsureMarkedBlock(t *testing.T) {
+	content := "keep\n# BEGIN wtp ports\nPORT=3000\n# END wtp ports\ntail\n"
+	assert.Equal(t, "keep\n# BEGIN wtp ports\nPORT=4000\nDEBUG=1\n# END wtp ports\ntail\n",
+		ensureMarkedBlock(content, "ports", "PORT=4000\nDEBUG=1\n"))
+
+	assert.Equal(t, "keep\n# BEGIN wtp ports\nPORT=4000\n# END wtp ports\n",
+		ensureMarkedBlock("keep\n", "ports", "PORT=4000"))
+}
+
+func TestExecutePostCreateHooks_EnsureLineIsIdempotent(t *testing.T) {
+	worktreeDir := t.TempDir()
+	exclude := filepath.Join(worktreeDir, ".git-info", "exclude")
+	cfg := &config.Config{Hooks: config.Hooks{PostCreate: []config.Hook{
+		{
+			Type: config.HookTypeEnsureLine, File: ".git-info/exclude", Line: ".env.${SUFFIX}",
+			Env: map[string]string{"SUFFIX": "local"},
+		},
+		{Type: config.HookTypeEnsureLine, File: ".git-info/exclude", Line: "/tmp/\n/log/\n", Marker: "scratch"},
+	}}}
+
+	require.NoError(t, NewExecutor(cfg, t.TempDir()).ExecutePostCreateHooks(&bytes.Buffer{}, worktreeDir))
+	first, err := os.ReadFile(exclude)
+	require.NoError(t, err)
+	assert.Equal(t, ".env.local\n# BEGIN wtp scratch\n/tmp/\n/log/\n# END wtp scratch\n", string(first))
+
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(cfg, t.TempDir()).ExecutePostCreateHooks(&buf, worktreeDir))
+	second, err := os.ReadFile(exclude)
+	require.NoError(t, err)
+	assert.Equal(t, string(first), string(second))
+	assert.Contains(t, buf.String(), "already up to date")
+}
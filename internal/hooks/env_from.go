@@ -0,0 +1,90 @@
+package hooks
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+// envFromEntries returns the NAME=value entries hook's env_from loads, or nil when it has
+// none. The values are only passed to the hook's process, never printed.
+func (e *Executor) envFromEntries(w io.Writer, hook *config.Hook, worktreePath string) ([]string, error) {
+	if hook.EnvFrom == nil {
+		return nil, nil
+	}
+	branch := e.conditionContext(worktreePath).Branch
+	expand := func(s string) string {
+		return e.registered.expand(e.expandVariables(s, worktreePath, branch))
+	}
+
+	if hook.EnvFrom.File != "" {
+		path := expand(hook.EnvFrom.File)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(e.repoRoot, path)
+		}
+		// #nosec G304 -- the file is named in the project configuration
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("env_from: failed to read %s: %w", path, err)
+		}
+		return parseDotenv(string(data)), nil
+	}
+
+	output, err := e.runEnvFromCommand(w, hook, expand(hook.EnvFrom.Command))
+	if err != nil {
+		return nil, err
+	}
+	if hook.EnvFrom.Name != "" {
+		return []string{hook.EnvFrom.Name + "=" + strings.TrimSpace(output)}, nil
+	}
+	return parseDotenv(output), nil
+}
+
+// runEnvFromCommand runs an env_from command in the main worktree with the hook's shell and
+// returns its stdout; its stderr, where tools like 'op' ask to sign in, goes to w.
+func (e *Executor) runEnvFromCommand(w io.Writer, hook *config.Hook, commandLine string) (string, error) {
+	var shell string
+	if e.config != nil {
+		shell = e.config.CommandShell(hook)
+	}
+	cmd := shellCommand(e.ctx, shell, commandLine, nil)
+	cmd.Dir = e.repoRoot
+	cmd.Env = inheritedEnv(hook)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = w
+	if err := cmd.Run(); err != nil {
+		// The command line is configuration, not a secret, so it is safe to name
+		return "", fmt.Errorf("env_from command failed: %w: %s", err, commandLine)
+	}
+	return stdout.String(), nil
+}
+
+// parseDotenv reads NAME=value lines, skipping blank lines and # comments. An "export "
+// prefix and quotes around the value are removed.
+func parseDotenv(content string) []string {
+	var entries []string
+	for _, line := range splitLines(content) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		name, value, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		entries = append(entries, name+"="+value)
+	}
+	return entries
+}
@@ -0,0 +1,99 @@
+package hooks
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func TestParseDotenv(t *testing.T) {
+	content := "# secrets\n" +
+		"API_TOKEN=abc123\n" +
+		"\n" +
+		"export DB_PASSWORD=\"p@ss word\"\r\n" +
+		"SINGLE='quoted'\n" +
+		"URL=https://example.com/?a=b\n" +
+		"not a variable\n" +
+		"=missing-name\n"
+
+	assert.Equal(t, []string{
+		"API_TOKEN=abc123",
+		"DB_PASSWORD=p@ss word",
+		"SINGLE=quoted",
+		"URL=https://example.com/?a=b",
+	}, parseDotenv(content))
+}
+
+func TestExecutePostCreateHooks_EnvFrom(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+	repoRoot := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, ".env.secrets"),
+		[]byte("API_TOKEN=from-file\nOVERRIDDEN=from-file\n"), 0o600))
+
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{
+					Type:    config.HookTypeCommand,
+					Command: "echo token=$API_TOKEN overridden=$OVERRIDDEN",
+					Env:     map[string]string{"OVERRIDDEN": "from-env"},
+					EnvFrom: &config.EnvFrom{File: ".env.secrets"},
+				},
+				{
+					Type:    config.HookTypeCommand,
+					Command: "echo db=$DB_PASSWORD",
+					EnvFrom: &config.EnvFrom{Command: "printf 'DB_PASSWORD=hunter2\\n'"},
+				},
+				{
+					Type:    config.HookTypeCommand,
+					Command: "echo secret=$SECRET",
+					EnvFrom: &config.EnvFrom{Command: "echo ' s3cret '", Name: "SECRET"},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(cfg, repoRoot).ExecutePostCreateHooks(&buf, t.TempDir()))
+
+	assert.Contains(t, buf.String(), "token=from-file overridden=from-env")
+	assert.Contains(t, buf.String(), "db=hunter2")
+	assert.Contains(t, buf.String(), "secret=s3cret")
+}
+
+func TestExecutePostCreateHooks_EnvFromFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+	tests := []struct {
+		name    string
+		envFrom *config.EnvFrom
+		want    string
+	}{
+		{"missing file", &config.EnvFrom{File: ".env.missing"}, "env_from: failed to read"},
+		{"failing command", &config.EnvFrom{Command: "exit 3"}, "env_from command failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			marker := filepath.Join(t.TempDir(), "ran")
+			cfg := &config.Config{Hooks: config.Hooks{PostCreate: []config.Hook{
+				{Type: config.HookTypeCommand, Command: "touch " + marker, EnvFrom: tt.envFrom},
+			}}}
+
+			var buf bytes.Buffer
+			err := NewExecutor(cfg, t.TempDir()).ExecutePostCreateHooks(&buf, t.TempDir())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+			assert.NoFileExists(t, marker, "the hook must not run without its secrets")
+		})
+	}
+}
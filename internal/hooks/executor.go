@@ -2,16 +2,25 @@
 package hooks
 
 import (
+	"bytes"
+	"context"
+	stdErrors "errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/errors"
+	"github.com/satococoa/wtp/v2/internal/events"
+	"github.com/satococoa/wtp/v2/internal/progress"
 )
 
 const (
@@ -23,80 +32,595 @@ const (
 type Executor struct {
 	config   *config.Config
 	repoRoot string
+	// phaseEnv holds extra environment variables for command hooks of the running phase
+	phaseEnv []string
+	// branch overrides the worktree's checked-out branch when evaluating 'when' conditions
+	branch string
+	// registered holds the output command hooks captured with 'register'
+	registered *registeredVars
+	// only restricts a run to these 1-based hook numbers; nil runs every hook
+	only map[int]bool
+	// phase names the hook list being run, e.g. "post_create"; it names the log files of
+	// hooks with 'output: file'
+	phase string
+	// ctx bounds the whole run: once it is done, running command and wait hooks are
+	// stopped and no further hook starts
+	ctx context.Context
+	// progress shows the running hooks on a terminal; nil shows nothing
+	progress *progress.Display
+	// task is the running hook's line on progress; copy hooks count their bytes on it
+	task *progress.Task
 }
 
 // NewExecutor creates a new hook executor
 func NewExecutor(cfg *config.Config, repoRoot string) *Executor {
 	return &Executor{
-		config:   cfg,
-		repoRoot: repoRoot,
+		config:     cfg,
+		repoRoot:   repoRoot,
+		registered: newRegisteredVars(),
+		ctx:        context.Background(),
 	}
 }
 
+// WithContext returns a copy of the executor whose hook runs stop once ctx is done, e.g.
+// when the operation's timeout passes. Hooks that completed before keep their timings.
+func (e *Executor) WithContext(ctx context.Context) *Executor {
+	runner := *e
+	runner.ctx = ctx
+	return &runner
+}
+
+// WithVariables returns a copy of the executor whose hooks see vars like variables an
+// earlier hook registered: as ${NAME} in their fields and in the environment of commands.
+// 'wtp add --pr' passes the pull request's number and title this way.
+func (e *Executor) WithVariables(vars map[string]string) *Executor {
+	runner := *e
+	runner.registered = newRegisteredVars()
+	for name, value := range vars {
+		runner.registered.set(name, value)
+	}
+	return &runner
+}
+
+// WithProgress returns a copy of the executor that shows each hook on display while it
+// runs, with the bytes copy hooks have copied, and writes hook output through it.
+func (e *Executor) WithProgress(display *progress.Display) *Executor {
+	runner := *e
+	runner.progress = display
+	return &runner
+}
+
+// HookTiming records when a single hook ran and how long it took.
+type HookTiming struct {
+	Index     int // 1-based position in the post_create list
+	Type      string
+	StartedAt time.Time
+	Duration  time.Duration
+	Err       error // non-nil when the hook failed
+}
+
+// ExitCode returns the exit status of a failed command hook; ok is false for other errors,
+// e.g. a command that timed out or could not be started.
+func ExitCode(err error) (code int, ok bool) {
+	var exitErr *exec.ExitError
+	if stdErrors.As(err, &exitErr) && exitErr.ExitCode() >= 0 {
+		return exitErr.ExitCode(), true
+	}
+	return 0, false
+}
+
 // ExecutePostCreateHooks executes all post-create hooks and streams output to writer
 func (e *Executor) ExecutePostCreateHooks(w io.Writer, worktreePath string) error {
+	_, err := e.ExecutePostCreateHooksTimed(w, worktreePath)
+	return err
+}
+
+// ExecutePostCreateHooksTimed executes all post-create hooks like ExecutePostCreateHooks
+// and additionally returns the timing of every hook that ran, including the failed ones.
+func (e *Executor) ExecutePostCreateHooksTimed(w io.Writer, worktreePath string) ([]HookTiming, error) {
 	if e.config == nil || !e.config.HasHooks() {
+		return nil, nil
+	}
+
+	release, err := e.acquireProvisionSlot(w)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = release() }()
+	return e.inPhase(phasePostCreate).executeHooks(w, e.config.Hooks.PostCreate, worktreePath)
+}
+
+// ExecutePostCreateHooksSelected executes only the post-create hooks with the given 1-based
+// numbers, in configuration order, and returns their timings like ExecutePostCreateHooksTimed.
+func (e *Executor) ExecutePostCreateHooksSelected(
+	w io.Writer, worktreePath string, numbers []int,
+) ([]HookTiming, error) {
+	if e.config == nil || !e.config.HasHooks() {
+		return nil, nil
+	}
+
+	release, err := e.acquireProvisionSlot(w)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = release() }()
+
+	runner := *e.inPhase(phasePostCreate)
+	runner.only = make(map[int]bool, len(numbers))
+	for _, number := range numbers {
+		runner.only[number] = true
+	}
+	return runner.executeHooks(w, e.config.Hooks.PostCreate, worktreePath)
+}
+
+// ExecutePreRemoveHooks executes all pre-remove hooks against a worktree that is about
+// to be deleted and streams output to writer
+func (e *Executor) ExecutePreRemoveHooks(w io.Writer, worktreePath string) error {
+	if e.config == nil || !e.config.HasPreRemoveHooks() {
 		return nil
 	}
 
-	totalHooks := len(e.config.Hooks.PostCreate)
-	for i, hook := range e.config.Hooks.PostCreate {
-		// Log which hook is starting
-		if _, err := fmt.Fprintf(w, "\n→ Running hook %d of %d...\n", i+1, totalHooks); err != nil {
-			return err
+	_, err := e.inPhase(phasePreRemove).executeHooks(w, e.config.Hooks.PreRemove, worktreePath)
+	return err
+}
+
+// ExecutePostCheckoutHooks executes all post-checkout hooks after a worktree switched
+// from oldBranch to newBranch. oldBranch is empty when the worktree was just created.
+func (e *Executor) ExecutePostCheckoutHooks(w io.Writer, worktreePath, oldBranch, newBranch string) error {
+	if e.config == nil || !e.config.HasPostCheckoutHooks() {
+		return nil
+	}
+
+	runner := *e.inPhase(phasePostCheckout)
+	runner.phaseEnv = []string{
+		fmt.Sprintf("GIT_WTP_OLD_BRANCH=%s", oldBranch),
+		fmt.Sprintf("GIT_WTP_NEW_BRANCH=%s", newBranch),
+	}
+	runner.branch = newBranch
+	_, err := runner.executeHooks(w, e.config.Hooks.PostCheckout, worktreePath)
+	return err
+}
+
+// ExecutePostRemoveHooks executes all post-remove hooks in the main worktree after the
+// worktree at removedPath, which had removedBranch checked out, was removed. ${BRANCH}
+// and 'when' conditions refer to removedBranch.
+func (e *Executor) ExecutePostRemoveHooks(w io.Writer, removedPath, removedBranch string) error {
+	if e.config == nil || !e.config.HasPostRemoveHooks() {
+		return nil
+	}
+
+	runner := *e.inPhase(phasePostRemove)
+	runner.phaseEnv = []string{
+		fmt.Sprintf("GIT_WTP_REMOVED_PATH=%s", removedPath),
+		fmt.Sprintf("GIT_WTP_REMOVED_BRANCH=%s", removedBranch),
+	}
+	runner.branch = removedBranch
+	_, err := runner.executeHooks(w, e.config.Hooks.PostRemove, e.repoRoot)
+	return err
+}
+
+// ExecutePostPruneHooks executes all post-prune hooks once in the main worktree after
+// 'wtp prune' removed the worktrees at prunedPaths, which had prunedBranches checked out.
+func (e *Executor) ExecutePostPruneHooks(w io.Writer, prunedPaths, prunedBranches []string) error {
+	if e.config == nil || !e.config.HasPostPruneHooks() {
+		return nil
+	}
+
+	runner := *e.inPhase(phasePostPrune)
+	runner.phaseEnv = []string{
+		fmt.Sprintf("GIT_WTP_PRUNED_COUNT=%d", len(prunedPaths)),
+		fmt.Sprintf("GIT_WTP_PRUNED_PATHS=%s", strings.Join(prunedPaths, "\n")),
+		fmt.Sprintf("GIT_WTP_PRUNED_BRANCHES=%s", strings.Join(prunedBranches, "\n")),
+	}
+	_, err := runner.executeHooks(w, e.config.Hooks.PostPrune, e.repoRoot)
+	return err
+}
+
+// ExecuteMaintenanceHooks executes all maintenance hooks in one worktree and streams
+// output to writer
+func (e *Executor) ExecuteMaintenanceHooks(w io.Writer, worktreePath string) error {
+	if e.config == nil || !e.config.HasMaintenanceHooks() {
+		return nil
+	}
+
+	_, err := e.inPhase(phaseMaintenance).executeHooks(w, e.config.Hooks.Maintenance, worktreePath)
+	return err
+}
+
+// ExecuteCleanHooks executes all clean hooks in one worktree and streams output to writer
+func (e *Executor) ExecuteCleanHooks(w io.Writer, worktreePath string) error {
+	if e.config == nil || !e.config.HasCleanHooks() {
+		return nil
+	}
+
+	_, err := e.inPhase(phaseClean).executeHooks(w, e.config.Hooks.Clean, worktreePath)
+	return err
+}
+
+// inPhase returns a copy of the executor for running the hook list named phase.
+func (e *Executor) inPhase(phase string) *Executor {
+	runner := *e
+	runner.phase = phase
+	return &runner
+}
+
+// executeHooks runs hookList in order, stopping at the first failure of a hook whose
+// on_error does not let the run continue. Consecutive hooks
+// that share a 'group' run concurrently; the next hook starts once the whole group is done.
+func (e *Executor) executeHooks(w io.Writer, hookList []config.Hook, worktreePath string) ([]HookTiming, error) {
+	w = e.progress.Writer(w)
+	timings := make([]HookTiming, 0, len(hookList))
+	var condCtx *config.ConditionContext
+	for _, batch := range hookBatches(hookList) {
+		if err := e.ctx.Err(); err != nil {
+			return timings, fmt.Errorf("hook %d was not started: %w", batch[0]+1, err)
+		}
+		runnable := make([]int, 0, len(batch))
+		for _, i := range batch {
+			run, err := e.shouldRunHook(w, hookList, i, worktreePath, &condCtx)
+			if err != nil {
+				return timings, err
+			}
+			if run {
+				runnable = append(runnable, i)
+			}
 		}
 
-		if err := e.executeHookWithWriter(w, &hook, worktreePath); err != nil {
-			return fmt.Errorf("failed to execute hook %d: %w", i+1, err)
+		var batchTimings []HookTiming
+		var err error
+		switch {
+		case len(runnable) == 1:
+			batchTimings, err = e.executeSingleHook(w, hookList, runnable[0], worktreePath)
+		case len(runnable) > 1:
+			batchTimings, err = e.executeHookGroup(w, hookList, runnable, worktreePath)
 		}
+		timings = append(timings, batchTimings...)
+		if err != nil {
+			return timings, err
+		}
+	}
 
-		// Log successful completion
-		if _, err := fmt.Fprintf(w, "✓ Hook %d completed\n", i+1); err != nil {
-			return err
+	return timings, nil
+}
+
+// hookBatches splits hookList into runs of consecutive hooks with the same non-empty group.
+// Hooks without a group form a batch of their own.
+func hookBatches(hookList []config.Hook) [][]int {
+	var batches [][]int
+	for i := range hookList {
+		group := hookList[i].Group
+		last := len(batches) - 1
+		if group != "" && last >= 0 && hookList[batches[last][0]].Group == group {
+			batches[last] = append(batches[last], i)
+			continue
 		}
+		batches = append(batches, []int{i})
 	}
+	return batches
+}
 
-	return nil
+// shouldRunHook evaluates the hook's 'os', 'when' condition, and 'once_per_repo' state and logs a
+// skipped hook.
+func (e *Executor) shouldRunHook(
+	w io.Writer, hookList []config.Hook, i int, worktreePath string, condCtx **config.ConditionContext,
+) (bool, error) {
+	if e.only != nil && !e.only[i+1] {
+		return false, nil
+	}
+	reason, err := e.hookSkipReason(&hookList[i], i, worktreePath, condCtx)
+	if err != nil || reason == "" {
+		return err == nil, err
+	}
+	_, err = fmt.Fprintf(w, "\n→ Skipping hook %d of %d (%s)\n", i+1, len(hookList), reason)
+	return false, err
+}
+
+// hookSkipReason returns why the hook at index i must not run, or "" when it should.
+// condCtx is filled in on first use.
+func (e *Executor) hookSkipReason(
+	hook *config.Hook, i int, worktreePath string, condCtx **config.ConditionContext,
+) (string, error) {
+	if !hook.RunsOn(runtime.GOOS) {
+		return "os: " + strings.Join(hook.OS, ", "), nil
+	}
+	if hook.When != "" {
+		if *condCtx == nil {
+			*condCtx = e.conditionContext(worktreePath)
+		}
+		run, err := evaluateWhen(hook.When, **condCtx)
+		if err != nil {
+			return "", fmt.Errorf("failed to evaluate condition for hook %d: %w", i+1, err)
+		}
+		if !run {
+			return "when: " + hook.When, nil
+		}
+	}
+
+	if hook.OncePerRepo {
+		done, err := e.onceHookCompleted(hook)
+		if err != nil {
+			return "", fmt.Errorf("failed to check once_per_repo state for hook %d: %w", i+1, err)
+		}
+		if done {
+			return "already run once for this repository", nil
+		}
+	}
+	return "", nil
+}
+
+func (e *Executor) executeSingleHook(
+	w io.Writer, hookList []config.Hook, i int, worktreePath string,
+) ([]HookTiming, error) {
+	hook := hookList[i]
+
+	// Log which hook is starting
+	if _, err := fmt.Fprintf(w, "\n→ Running hook %d of %d...\n", i+1, len(hookList)); err != nil {
+		return nil, err
+	}
+
+	timing := e.runHook(w, &hook, i+1, worktreePath)
+	err := timing.Err
+	if err != nil && !hook.ContinuesOnError() {
+		return []HookTiming{timing}, fmt.Errorf("failed to execute hook %d: %w", i+1, err)
+	}
+
+	if _, err := io.WriteString(w, hookStatus(&hook, i, err)); err != nil {
+		return nil, err
+	}
+	return []HookTiming{timing}, nil
+}
+
+// executeHookGroup runs the hooks at indexes concurrently, at most defaults.hook_concurrency
+// at a time. Each hook's output is buffered and written as one block when it finishes so
+// that output from different hooks does not interleave. Every hook in the group runs to
+// completion; the error of the first failing hook (in list order) that does not continue
+// on error is returned.
+func (e *Executor) executeHookGroup(
+	w io.Writer, hookList []config.Hook, indexes []int, worktreePath string,
+) ([]HookTiming, error) {
+	numbers := make([]string, len(indexes))
+	for n, i := range indexes {
+		numbers[n] = strconv.Itoa(i + 1)
+	}
+	if _, err := fmt.Fprintf(w, "\n→ Running hooks %s of %d in parallel (group: %s)...\n",
+		strings.Join(numbers, ", "), len(hookList), hookList[indexes[0]].Group); err != nil {
+		return nil, err
+	}
+
+	limit := len(indexes)
+	if e.config != nil && e.config.Defaults.HookConcurrency > 0 {
+		limit = min(limit, e.config.Defaults.HookConcurrency)
+	}
+	slots := make(chan struct{}, limit)
+
+	timings := make([]HookTiming, len(indexes))
+	errs := make([]error, len(indexes))
+	synchronized := newSynchronizedWriter(w)
+	var wg sync.WaitGroup
+	for n, i := range indexes {
+		// Acquire the slot before starting the goroutine so hooks start in list order
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			hook := hookList[i]
+			var output bytes.Buffer
+			timings[n] = e.runHook(&output, &hook, i+1, worktreePath)
+			errs[n] = timings[n].Err
+
+			_, _ = fmt.Fprintf(synchronized, "\n→ Hook %d output:\n%s%s", i+1, output.String(),
+				hookStatus(&hook, i, errs[n]))
+		}()
+	}
+	wg.Wait()
+
+	for n, i := range indexes {
+		if errs[n] != nil && !hookList[i].ContinuesOnError() {
+			return timings, fmt.Errorf("failed to execute hook %d: %w", i+1, errs[n])
+		}
+	}
+	return timings, nil
+}
+
+// runHook runs the hook numbered index, records it when it runs once, and reports its
+// hook_started and hook_finished events.
+func (e *Executor) runHook(w io.Writer, hook *config.Hook, index int, worktreePath string) HookTiming {
+	events.Emit(&events.Event{
+		Type: events.TypeHookStarted, Worktree: worktreePath, Phase: e.phase,
+		Hook: index, HookType: hook.Type,
+	})
+	start := time.Now()
+	runner := *e
+	if hook.Type != config.HookTypePrompt {
+		// A prompt waits on the user, not on work
+		runner.task = e.progress.Start(fmt.Sprintf("Hook %d (%s)", index, hook.Type))
+	}
+	err := runner.executeHookWithOutput(w, hook, index, worktreePath)
+	runner.task.Done()
+	if err == nil {
+		err = e.recordOnceHook(hook)
+	}
+	timing := HookTiming{Index: index, Type: hook.Type, StartedAt: start, Duration: time.Since(start), Err: err}
+
+	finished := &events.Event{
+		Type: events.TypeHookFinished, Worktree: worktreePath, Phase: e.phase,
+		Hook: index, HookType: hook.Type, Status: events.StatusSucceeded, DurationMS: timing.Duration.Milliseconds(),
+	}
+	if err != nil {
+		finished.Status, finished.Error = events.StatusFailed, err.Error()
+	}
+	events.Emit(finished)
+	return timing
+}
+
+// hookStatus returns the line reporting how the hook at index i ended; err is its failure.
+func hookStatus(hook *config.Hook, i int, err error) string {
+	switch {
+	case err == nil:
+		return fmt.Sprintf("✓ Hook %d completed\n", i+1)
+	case hook.OnError == config.OnErrorWarn:
+		warning := errors.Warning(errors.CodeWarnHookFailed, "hook %d failed: %v; continuing (on_error: warn)", i+1, err)
+		return "⚠ " + warning + "\n"
+	case hook.OnError == config.OnErrorContinue:
+		return fmt.Sprintf("✗ Hook %d failed; continuing (on_error: continue)\n", i+1)
+	default:
+		return fmt.Sprintf("✗ Hook %d failed\n", i+1)
+	}
+}
+
+// conditionContext gathers the values 'when' conditions are evaluated against.
+func (e *Executor) conditionContext(worktreePath string) *config.ConditionContext {
+	branch := e.branch
+	if branch == "" {
+		if output, err := e.gitOutput("-C", worktreePath, "rev-parse", "--abbrev-ref", "HEAD"); err == nil {
+			branch = strings.TrimSpace(string(output))
+		}
+		if branch == "HEAD" {
+			branch = "" // detached
+		}
+	}
+	return &config.ConditionContext{
+		Branch: branch,
+		OS:     runtime.GOOS,
+		Arch:   runtime.GOARCH,
+		Slug:   &e.config.Defaults.Slug,
+		Getenv: os.Getenv,
+	}
+}
+
+func evaluateWhen(when string, ctx config.ConditionContext) (bool, error) {
+	cond, err := config.ParseCondition(when)
+	if err != nil {
+		return false, err
+	}
+	return cond.Evaluate(ctx)
+}
+
+// executeHookWithOutput executes hook number (1-based) with its output sent where the
+// hook's 'output' says, and writes the summary of the output to w.
+func (e *Executor) executeHookWithOutput(w io.Writer, hook *config.Hook, number int, worktreePath string) error {
+	out, err := e.openHookOutput(w, hook, number, worktreePath)
+	if err != nil {
+		return err
+	}
+	err = e.executeHookWithWriter(out, hook, worktreePath)
+	if closeErr := out.Close(err); err == nil {
+		err = closeErr
+	}
+	return err
 }
 
 // executeHookWithWriter executes a single hook with output directed to writer
 func (e *Executor) executeHookWithWriter(w io.Writer, hook *config.Hook, worktreePath string) error {
+	hook = e.expandHookFields(hook, worktreePath)
 	switch hook.Type {
 	case config.HookTypeCopy:
 		return e.executeCopyHookWithWriter(w, hook, worktreePath)
 	case config.HookTypeCommand:
-		return e.executeCommandHookWithWriter(w, hook, worktreePath)
+		e.recordSharedCacheUse(hook, worktreePath)
+		return e.executeWithRetry(w, hook, func() error {
+			return e.executeCommandHookWithWriter(w, hook, worktreePath)
+		})
+	case config.HookTypeScript:
+		e.recordSharedCacheUse(hook, worktreePath)
+		return e.executeWithRetry(w, hook, func() error {
+			return e.executeScriptHookWithWriter(w, hook, worktreePath)
+		})
 	case config.HookTypeSymlink:
 		return e.executeSymlinkHookWithWriter(w, hook, worktreePath)
+	case config.HookTypeHardlink:
+		return e.executeHardlinkHookWithWriter(w, hook, worktreePath)
+	case config.HookTypeDownload:
+		return e.executeWithRetry(w, hook, func() error {
+			return e.executeDownloadHookWithWriter(w, hook, worktreePath)
+		})
+	case config.HookTypeExtract:
+		return e.executeExtractHookWithWriter(w, hook, worktreePath)
+	case config.HookTypeGitConfig:
+		return e.executeGitConfigHookWithWriter(w, hook, worktreePath)
+	case config.HookTypePatch:
+		return e.executePatchHookWithWriter(w, hook, worktreePath)
+	case config.HookTypeEnsureLine:
+		return e.executeEnsureLineHookWithWriter(w, hook, worktreePath)
+	case config.HookTypeWait:
+		return e.executeWaitHookWithWriter(w, hook, worktreePath)
+	case config.HookTypePrompt:
+		return e.executePromptHookWithWriter(w, hook, worktreePath)
+	case config.HookTypeGitHooks:
+		return e.executeGitHooksHookWithWriter(w, hook, worktreePath)
+	case config.HookTypeDirenv:
+		return e.executeDirenvHookWithWriter(w, hook, worktreePath)
 	default:
 		return fmt.Errorf("unknown hook type: %s", hook.Type)
 	}
 }
 
-// executeCopyHookWithWriter executes a copy hook with output directed to writer
-func (e *Executor) executeCopyHookWithWriter(w io.Writer, hook *config.Hook, worktreePath string) error {
-	// Resolve source path (relative to repo root)
-	srcPath := hook.From
-	if !filepath.IsAbs(srcPath) {
-		srcPath = filepath.Join(e.repoRoot, srcPath)
+// expandHookFields returns hook with defaults.env merged into its env and with variables and
+// registered variables applied to its 'from', 'to', 'command', 'run', and 'env' values,
+// leaving the configured hook untouched.
+func (e *Executor) expandHookFields(hook *config.Hook, worktreePath string) *config.Hook {
+	env := hook.Env
+	if e.config != nil {
+		env = e.config.HookEnv(hook)
 	}
-	srcPath = filepath.Clean(srcPath)
-	if !filepath.IsAbs(hook.From) {
-		if err := ensureWithinBase(e.repoRoot, srcPath); err != nil {
-			return err
+	envHasVariables := false
+	for _, value := range env {
+		envHasVariables = envHasVariables || strings.Contains(value, "${")
+	}
+	expanded := *hook
+	expanded.Env = env
+	if !envHasVariables && !strings.Contains(hook.From+hook.To+hook.Command+hook.Run, "${") {
+		return &expanded
+	}
+	branch := e.conditionContext(worktreePath).Branch
+	expand := func(s string) string {
+		return e.registered.expand(e.expandVariables(s, worktreePath, branch))
+	}
+	expanded.From = expand(hook.From)
+	expanded.To = expand(hook.To)
+	expanded.Command = expand(hook.Command)
+	expanded.Run = expand(hook.Run)
+	if envHasVariables {
+		expanded.Env = make(map[string]string, len(env))
+		for key, value := range env {
+			expanded.Env[key] = expand(value)
 		}
 	}
+	return &expanded
+}
 
-	// Resolve destination path (relative to worktree)
-	dstPath := hook.To
-	if !filepath.IsAbs(dstPath) {
-		dstPath = filepath.Join(worktreePath, dstPath)
+// expandVariables applies config.ExpandVariables and the worktree variables: ${WORKTREE_PATH}
+// is the absolute path of the worktree being provisioned and ${WORKTREE_DIR} its directory name.
+func (e *Executor) expandVariables(s, worktreePath, branch string) string {
+	if strings.Contains(s, "${WORKTREE_") {
+		absPath, err := filepath.Abs(worktreePath)
+		if err != nil {
+			absPath = worktreePath
+		}
+		s = strings.NewReplacer("${WORKTREE_PATH}", absPath, "${WORKTREE_DIR}", filepath.Base(absPath)).Replace(s)
 	}
-	dstPath = filepath.Clean(dstPath)
-	if !filepath.IsAbs(hook.To) {
-		if err := ensureWithinBase(worktreePath, dstPath); err != nil {
+	return e.config.ExpandVariables(s, e.repoRoot, branch)
+}
+
+// executeCopyHookWithWriter executes a copy hook with output directed to writer
+func (e *Executor) executeCopyHookWithWriter(w io.Writer, hook *config.Hook, worktreePath string) error {
+	sourceRoot, srcPath, dstPath, err := e.resolveHookPaths(hook, worktreePath)
+	if err != nil {
+		return err
+	}
+
+	if hook.FromRef != "" {
+		relSrc, _ := filepath.Rel(sourceRoot, srcPath)
+		relDst, _ := filepath.Rel(worktreePath, dstPath)
+		if _, err := fmt.Fprintf(w, "  Copying: %s:%s → %s\n", hook.FromRef, relSrc, relDst); err != nil {
 			return err
 		}
+		return e.copyFromRef(hook.FromRef, relSrc, dstPath)
+	}
+	if config.IsGlobPattern(hook.From) {
+		return e.copyGlob(w, sourceRoot, srcPath, dstPath, worktreePath, hook.COW)
 	}
 
 	// Check if source exists
@@ -115,42 +639,23 @@ func (e *Executor) executeCopyHookWithWriter(w io.Writer, hook *config.Hook, wor
 	}
 
 	// Log the copy operation to writer
-	relSrc, _ := filepath.Rel(e.repoRoot, srcPath)
+	relSrc, _ := filepath.Rel(sourceRoot, srcPath)
 	relDst, _ := filepath.Rel(worktreePath, dstPath)
 	if _, err := fmt.Fprintf(w, "  Copying: %s → %s\n", relSrc, relDst); err != nil {
 		return err
 	}
 
 	if srcInfo.IsDir() {
-		return e.copyDir(srcPath, dstPath)
+		return e.copyDir(srcPath, dstPath, hook.COW)
 	}
-	return e.copyFile(srcPath, dstPath)
+	return e.copyFile(srcPath, dstPath, hook.COW)
 }
 
 // executeSymlinkHookWithWriter executes a symlink hook with output directed to writer
 func (e *Executor) executeSymlinkHookWithWriter(w io.Writer, hook *config.Hook, worktreePath string) error {
-	// Resolve source path (relative to repo root)
-	srcPath := hook.From
-	if !filepath.IsAbs(srcPath) {
-		srcPath = filepath.Join(e.repoRoot, srcPath)
-	}
-	srcPath = filepath.Clean(srcPath)
-	if !filepath.IsAbs(hook.From) {
-		if err := ensureWithinBase(e.repoRoot, srcPath); err != nil {
-			return err
-		}
-	}
-
-	// Resolve destination path (relative to worktree)
-	dstPath := hook.To
-	if !filepath.IsAbs(dstPath) {
-		dstPath = filepath.Join(worktreePath, dstPath)
-	}
-	dstPath = filepath.Clean(dstPath)
-	if !filepath.IsAbs(hook.To) {
-		if err := ensureWithinBase(worktreePath, dstPath); err != nil {
-			return err
-		}
+	_, srcPath, dstPath, err := e.resolveHookPaths(hook, worktreePath)
+	if err != nil {
+		return err
 	}
 
 	// Check if source exists
@@ -183,13 +688,48 @@ func (e *Executor) executeSymlinkHookWithWriter(w io.Writer, hook *config.Hook,
 	}
 
 	// Use absolute path to avoid ambiguity and match copy hook behavior.
-	if err := os.Symlink(srcPath, dstPath); err != nil {
+	if err := createSymlink(srcPath, dstPath, srcInfo.IsDir()); err != nil {
 		return fmt.Errorf("failed to create symlink: %w", err)
 	}
 
 	return nil
 }
 
+// resolveHookPaths resolves a copy or symlink hook's source (relative to the repository root,
+// or to the worktree named by from_worktree) and destination (relative to the new worktree).
+func (e *Executor) resolveHookPaths(
+	hook *config.Hook, worktreePath string,
+) (sourceRoot, srcPath, dstPath string, err error) {
+	sourceRoot = e.repoRoot
+	if hook.FromWorktree != "" {
+		if sourceRoot, err = e.resolveWorktreeRoot(hook.FromWorktree); err != nil {
+			return "", "", "", err
+		}
+	}
+	srcPath = hook.From
+	if !filepath.IsAbs(srcPath) {
+		srcPath = filepath.Join(sourceRoot, srcPath)
+	}
+	srcPath = filepath.Clean(srcPath)
+	if !filepath.IsAbs(hook.From) {
+		if err := ensureWithinBase(sourceRoot, srcPath); err != nil {
+			return "", "", "", err
+		}
+	}
+
+	dstPath = hook.To
+	if !filepath.IsAbs(dstPath) {
+		dstPath = filepath.Join(worktreePath, dstPath)
+	}
+	dstPath = filepath.Clean(dstPath)
+	if !filepath.IsAbs(hook.To) {
+		if err := ensureWithinBase(worktreePath, dstPath); err != nil {
+			return "", "", "", err
+		}
+	}
+	return sourceRoot, srcPath, dstPath, nil
+}
+
 func ensureWithinBase(base, target string) error {
 	rel, err := filepath.Rel(base, target)
 	if err != nil {
@@ -225,14 +765,45 @@ func ensureDistinctPaths(srcPath, dstPath string, srcInfo os.FileInfo) error {
 
 // executeCommandHookWithWriter executes a command hook with output directed to writer
 func (e *Executor) executeCommandHookWithWriter(w io.Writer, hook *config.Hook, worktreePath string) error {
-	// Execute command using shell for unified command format
-	var cmd *exec.Cmd
-	if runtime.GOOS == windowsOS {
-		// #nosec G204 - Commands come from project configuration file controlled by developer
-		cmd = exec.Command("cmd", "/c", hook.Command)
-	} else {
-		// #nosec G204 - Commands come from project configuration file controlled by developer
-		cmd = exec.Command("sh", "-c", hook.Command)
+	var shell string
+	if e.config != nil {
+		shell = e.config.CommandShell(hook)
+	}
+
+	// Log the command execution to writer
+	if _, err := fmt.Fprintf(w, "  Running: %s\n", hook.Command); err != nil {
+		return err
+	}
+	return e.runShell(w, hook, worktreePath, shell, hook.Command, hook.Command)
+}
+
+// runShell runs commandLine with shell for a command or script hook, in the hook's
+// work_dir and environment, and streams its output to w. label names what ran in errors.
+func (e *Executor) runShell(w io.Writer, hook *config.Hook, worktreePath, shell, commandLine, label string) error {
+	ctx := e.ctx
+	var timeout time.Duration
+	if e.config != nil {
+		timeout = e.config.CommandTimeout(hook)
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// env_from comes first so that the hook's own env and wtp's variables win
+	hookEnv, err := e.envFromEntries(w, hook, worktreePath)
+	if err != nil {
+		return err
+	}
+	hookEnv = append(hookEnv, e.wtpEnv(hook, worktreePath)...)
+	cmd := shellCommand(ctx, shell, commandLine, envNames(hookEnv))
+	if ctx.Done() != nil {
+		// On a timeout, the hook's or the operation's, kill the whole process group so children
+		// of the shell do not linger. Only done with one: a separate group no longer receives
+		// the terminal's Ctrl-C.
+		startInProcessGroup(cmd)
+		cmd.Cancel = func() error { return killProcessGroup(cmd) }
 	}
 
 	// Set working directory
@@ -244,32 +815,96 @@ func (e *Executor) executeCommandHookWithWriter(w io.Writer, hook *config.Hook,
 	}
 	cmd.Dir = workDir
 
-	// Set environment variables (filter out WTP_SHELL_INTEGRATION)
+	cmd.Env = append(inheritedEnv(hook), hookEnv...)
+
+	// A registered command's stdout is captured instead of shown; stderr is still streamed
+	synchronized := newSynchronizedWriter(w)
+	var stdout io.Writer = synchronized
+	var captured bytes.Buffer
+	if hook.Register != "" {
+		stdout = &captured
+	}
+	if err := startStreaming(cmd, stdout, synchronized); err != nil {
+		return err
+	}
+
+	// Wait for command to complete
+	if err := cmd.Wait(); err != nil {
+		if opErr := e.ctx.Err(); opErr != nil {
+			return fmt.Errorf("command was stopped: %w: %s", opErr, label)
+		}
+		if stdErrors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("command timed out after %s and was killed: %s", timeout, label)
+		}
+		return fmt.Errorf("command failed: %w", err)
+	}
+
+	if hook.Register != "" {
+		e.registered.set(hook.Register, strings.TrimSpace(captured.String()))
+		if _, err := fmt.Fprintf(w, "  Registered ${%s}\n", hook.Register); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// minimalEnv lists the variables a command hook with 'clear_env' keeps from wtp's
+// environment, enough for a shell to find programs and temporary directories.
+var minimalEnv = []string{
+	"PATH", "HOME", "USER", "LOGNAME", "SHELL", "TMPDIR", "LANG", "LC_ALL", "TERM",
+	// Needed by cmd.exe and most programs on Windows
+	"SYSTEMROOT", "WINDIR", "COMSPEC", "PATHEXT", "TEMP", "TMP", "USERPROFILE",
+}
+
+// commandEnv builds the environment of a command hook: the inherited environment and
+// wtp's variables.
+func (e *Executor) commandEnv(hook *config.Hook, worktreePath string) []string {
+	return append(inheritedEnv(hook), e.wtpEnv(hook, worktreePath)...)
+}
+
+// inheritedEnv returns the current environment without WTP_SHELL_INTEGRATION, or only
+// minimalEnv with 'clear_env'.
+func inheritedEnv(hook *config.Hook) []string {
 	env := os.Environ()
 	filtered := make([]string, 0, len(env))
-	for _, e := range env {
-		if !strings.HasPrefix(e, "WTP_SHELL_INTEGRATION=") {
-			filtered = append(filtered, e)
+	for _, entry := range env {
+		name, _, _ := strings.Cut(entry, "=")
+		if name == "WTP_SHELL_INTEGRATION" {
+			continue
 		}
+		if hook.ClearEnv && !slices.ContainsFunc(minimalEnv, func(kept string) bool {
+			return strings.EqualFold(kept, name)
+		}) {
+			continue
+		}
+		filtered = append(filtered, entry)
 	}
-	cmd.Env = filtered
+	return filtered
+}
+
+// wtpEnv returns the variables wtp sets for a command hook: the hook's env, the
+// worktree-specific variables, the shared cache directory, and the variables registered
+// by earlier hooks.
+func (e *Executor) wtpEnv(hook *config.Hook, worktreePath string) []string {
+	env := make([]string, 0, len(hook.Env))
 	for key, value := range hook.Env {
-		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
 	}
 
 	// Add worktree-specific environment variables
-	cmd.Env = append(cmd.Env,
+	env = append(env,
 		fmt.Sprintf("GIT_WTP_WORKTREE_PATH=%s", worktreePath),
 		fmt.Sprintf("GIT_WTP_REPO_ROOT=%s", e.repoRoot))
-
-	// Log the command execution to writer
-	if _, err := fmt.Fprintf(w, "  Running: %s", hook.Command); err != nil {
-		return err
-	}
-	if _, err := fmt.Fprintln(w); err != nil {
-		return err
+	if cacheDir := e.sharedCacheDir(); cacheDir != "" {
+		env = append(env, fmt.Sprintf("%s=%s", CacheDirEnv, cacheDir))
 	}
+	env = append(env, e.registered.env()...)
+	return append(env, e.phaseEnv...)
+}
 
+// startStreaming starts cmd and copies its stdout and stderr to the given writers until
+// both are closed. The writers must be safe to use from separate goroutines.
+func startStreaming(cmd *exec.Cmd, stdoutWriter, stderrWriter io.Writer) error {
 	// Create pipes for stdout and stderr to enable real-time streaming
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -290,15 +925,13 @@ func (e *Executor) executeCommandHookWithWriter(w io.Writer, hook *config.Hook,
 	const numStreams = 2 // stdout and stderr
 	done := make(chan error, numStreams)
 
-	synchronized := newSynchronizedWriter(w)
-
 	go func() {
-		_, err := io.Copy(synchronized, stdout)
+		_, err := io.Copy(stdoutWriter, stdout)
 		done <- err
 	}()
 
 	go func() {
-		_, err := io.Copy(synchronized, stderr)
+		_, err := io.Copy(stderrWriter, stderr)
 		done <- err
 	}()
 
@@ -309,11 +942,6 @@ func (e *Executor) executeCommandHookWithWriter(w io.Writer, hook *config.Hook,
 		}
 	}
 
-	// Wait for command to complete
-	if err := cmd.Wait(); err != nil {
-		return fmt.Errorf("command failed: %w", err)
-	}
-
 	return nil
 }
 
@@ -332,8 +960,9 @@ func (sw *synchronizedWriter) Write(p []byte) (int, error) {
 	return sw.w.Write(p)
 }
 
-// copyFile copies a single file
-func (*Executor) copyFile(src, dst string) error {
+// copyFile copies a single file, as a copy-on-write clone as cow allows (see
+// writeFileContents), and gives the copy the mode of src.
+func (e *Executor) copyFile(src, dst, cow string) error {
 	srcInfo, err := os.Stat(src)
 	if err != nil {
 		return fmt.Errorf("failed to open source file: %w", err)
@@ -343,31 +972,13 @@ func (*Executor) copyFile(src, dst string) error {
 		return fmt.Errorf("failed to copy file: source file is not readable")
 	}
 
-	// #nosec G304 -- src is validated against the repository root above
-	sourceFile, err := os.Open(src)
-	if err != nil {
-		return fmt.Errorf("failed to open source file: %w", err)
-	}
-	defer func() {
-		_ = sourceFile.Close()
-	}()
-
 	dstParent := filepath.Dir(dst)
 	if writableErr := ensureDirWritable(dstParent); writableErr != nil {
 		return fmt.Errorf("failed to create destination file: %w", writableErr)
 	}
 
-	// #nosec G304 -- dst is validated against the worktree path above
-	destFile, err := os.Create(dst)
-	if err != nil {
-		return fmt.Errorf("failed to create destination file: %w", err)
-	}
-	defer func() {
-		_ = destFile.Close()
-	}()
-
-	if _, copyErr := io.Copy(destFile, sourceFile); copyErr != nil {
-		return fmt.Errorf("failed to copy file: %w", copyErr)
+	if err := writeFileContents(src, dst, cow, e.task); err != nil {
+		return err
 	}
 
 	if err := os.Chmod(dst, srcInfo.Mode()); err != nil {
@@ -377,8 +988,8 @@ func (*Executor) copyFile(src, dst string) error {
 	return nil
 }
 
-// copyDir recursively copies a directory
-func (e *Executor) copyDir(src, dst string) error {
+// copyDir recursively copies a directory; cow applies to each file as in copyFile.
+func (e *Executor) copyDir(src, dst, cow string) error {
 	srcInfo, err := os.Stat(src)
 	if err != nil {
 		return fmt.Errorf("failed to stat source directory: %w", err)
@@ -407,11 +1018,11 @@ func (e *Executor) copyDir(src, dst string) error {
 		dstPath := filepath.Join(dst, entry.Name())
 
 		if entry.IsDir() {
-			if err := e.copyDir(srcPath, dstPath); err != nil {
+			if err := e.copyDir(srcPath, dstPath, cow); err != nil {
 				return err
 			}
 		} else {
-			if err := e.copyFile(srcPath, dstPath); err != nil {
+			if err := e.copyFile(srcPath, dstPath, cow); err != nil {
 				return err
 			}
 		}
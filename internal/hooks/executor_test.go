@@ -2,6 +2,8 @@ package hooks
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -15,6 +17,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/events"
 )
 
 func TestExecutePostCreateHooks_NilConfig(t *testing.T) {
@@ -154,6 +157,40 @@ func TestExecutePostCreateHooks_CopyFile(t *testing.T) {
 	assert.Contains(t, output, "✓ Hook 1 completed")
 }
 
+func TestExecutePostCreateHooks_ExpandsEnvReferences(t *testing.T) {
+	tempDir := t.TempDir()
+	repoRoot := filepath.Join(tempDir, "repo")
+	worktreeDir := filepath.Join(tempDir, "worktree")
+	require.NoError(t, os.MkdirAll(repoRoot, directoryPermissions))
+	require.NoError(t, os.MkdirAll(worktreeDir, directoryPermissions))
+
+	sharedDir := filepath.Join(tempDir, "shared")
+	require.NoError(t, os.MkdirAll(sharedDir, directoryPermissions))
+	require.NoError(t, os.WriteFile(filepath.Join(sharedDir, "secrets.env"), []byte("TOKEN=x"), 0644))
+	t.Setenv("WTP_TEST_SHARED", sharedDir)
+
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{
+					Type: config.HookTypeCopy,
+					From: "${env:WTP_TEST_SHARED}/secrets.env",
+					To:   "${env:WTP_TEST_TARGET:-.env}",
+				},
+			},
+		},
+	}
+
+	executor := NewExecutor(cfg, repoRoot)
+	var buf bytes.Buffer
+	require.NoError(t, executor.ExecutePostCreateHooks(&buf, worktreeDir))
+
+	content, err := os.ReadFile(filepath.Join(worktreeDir, ".env"))
+	require.NoError(t, err)
+	assert.Equal(t, "TOKEN=x", string(content))
+	assert.Equal(t, "${env:WTP_TEST_SHARED}/secrets.env", cfg.Hooks.PostCreate[0].From)
+}
+
 func TestExecutePostCreateHooks_Symlink(t *testing.T) {
 	requireSymlinkSupport(t)
 
@@ -465,6 +502,61 @@ func TestExecutePostCreateHooks_CommandWithEnv(t *testing.T) {
 	assert.Contains(t, output, "custom_value")
 }
 
+func TestExecutePostCreateHooks_CommandRegister(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+
+	worktreeDir := t.TempDir()
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{Type: config.HookTypeCommand, Command: "echo \"  tok-$((120 + 3))  \"", Register: "API_TOKEN"},
+				{Type: config.HookTypeCommand, Command: "echo \"env=$API_TOKEN\" > env.txt"},
+				{Type: config.HookTypeEnsureLine, File: ".env", Line: "API_TOKEN=${API_TOKEN}"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(cfg, t.TempDir()).ExecutePostCreateHooks(&buf, worktreeDir))
+
+	output := buf.String()
+	assert.Contains(t, output, "Registered ${API_TOKEN}")
+	assert.NotContains(t, output, "tok-123", "captured output is not echoed")
+
+	envFile, err := os.ReadFile(filepath.Join(worktreeDir, "env.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "env=tok-123\n", string(envFile))
+
+	dotenv, err := os.ReadFile(filepath.Join(worktreeDir, ".env"))
+	require.NoError(t, err)
+	assert.Equal(t, "API_TOKEN=tok-123\n", string(dotenv))
+}
+
+func TestExecutePostCreateHooks_WithVariables(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+
+	worktreeDir := t.TempDir()
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{Type: config.HookTypeCommand, Command: "echo \"${PR_NUMBER} $PR_TITLE\" > pr.txt"},
+			},
+		},
+	}
+
+	vars := map[string]string{"PR_NUMBER": "42", "PR_TITLE": "Fix login"}
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(cfg, t.TempDir()).WithVariables(vars).ExecutePostCreateHooks(&buf, worktreeDir))
+
+	content, err := os.ReadFile(filepath.Join(worktreeDir, "pr.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "42 Fix login\n", string(content))
+}
+
 func TestExecutePostCreateHooks_CommandWithWorkDir(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("Skipping command test on Windows")
@@ -538,6 +630,456 @@ func TestExecutePostCreateHooks_CommandFailure(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to execute hook")
 }
 
+func TestExecutePostCreateHooks_OnError(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{Type: config.HookTypeCommand, Command: "exit 3", OnError: config.OnErrorWarn},
+				{Type: config.HookTypeCommand, Command: "exit 4", OnError: config.OnErrorContinue, Group: "g"},
+				{Type: config.HookTypeCommand, Command: "true", Group: "g"},
+				{Type: config.HookTypeCommand, Command: "echo done > done.txt"},
+			},
+		},
+	}
+
+	worktreeDir := t.TempDir()
+	var buf bytes.Buffer
+	timings, err := NewExecutor(cfg, t.TempDir()).ExecutePostCreateHooksTimed(&buf, worktreeDir)
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(worktreeDir, "done.txt"))
+	assert.Contains(t, buf.String(), "⚠ Warning [WTP7017]: hook 1 failed: command failed: exit status 3; continuing")
+	assert.Contains(t, buf.String(), "✗ Hook 2 failed; continuing (on_error: continue)")
+	require.Len(t, timings, 4)
+	assert.Error(t, timings[0].Err)
+	assert.Error(t, timings[1].Err)
+	assert.NoError(t, timings[3].Err)
+
+	cfg.Hooks.PostCreate[0].OnError = config.OnErrorFail
+	require.NoError(t, os.Remove(filepath.Join(worktreeDir, "done.txt")))
+	_, err = NewExecutor(cfg, t.TempDir()).ExecutePostCreateHooksTimed(&bytes.Buffer{}, worktreeDir)
+	assert.ErrorContains(t, err, "failed to execute hook 1")
+	assert.NoFileExists(t, filepath.Join(worktreeDir, "done.txt"))
+}
+
+func TestExecutePostCreateHooks_EmitsHookEvents(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+
+	var stream bytes.Buffer
+	events.Enable(&stream)
+	t.Cleanup(func() { events.Enable(nil) })
+
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{Type: config.HookTypeCommand, Command: "true"},
+				{Type: config.HookTypeCommand, Command: "exit 2", Group: "g", OnError: config.OnErrorContinue},
+				{Type: config.HookTypeCommand, Command: "true", Group: "g"},
+			},
+		},
+	}
+	worktreeDir := t.TempDir()
+	require.NoError(t, NewExecutor(cfg, t.TempDir()).ExecutePostCreateHooks(&bytes.Buffer{}, worktreeDir))
+
+	finished := map[int]events.Event{}
+	started := 0
+	for _, line := range strings.Split(strings.TrimSpace(stream.String()), "\n") {
+		var event events.Event
+		require.NoError(t, json.Unmarshal([]byte(line), &event))
+		assert.Equal(t, worktreeDir, event.Worktree)
+		assert.Equal(t, "post_create", event.Phase)
+		assert.Equal(t, config.HookTypeCommand, event.HookType)
+		switch event.Type {
+		case events.TypeHookStarted:
+			started++
+		case events.TypeHookFinished:
+			finished[event.Hook] = event
+		}
+	}
+	assert.Equal(t, 3, started)
+	require.Len(t, finished, 3)
+	assert.Equal(t, events.StatusSucceeded, finished[1].Status)
+	assert.Equal(t, events.StatusFailed, finished[2].Status)
+	assert.Contains(t, finished[2].Error, "exit status 2")
+	assert.Equal(t, events.StatusSucceeded, finished[3].Status)
+}
+
+func TestExecutePostCreateHooks_CommandTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+
+	worktreeDir := t.TempDir()
+	marker := filepath.Join(worktreeDir, "child-survived")
+	cfg := &config.Config{
+		Defaults: config.Defaults{HookTimeout: "1m"},
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{Type: config.HookTypeCommand, Command: "echo ok"},
+				{
+					Type: config.HookTypeCommand,
+					// The background child must die with the shell, or it would create the marker
+					Command: fmt.Sprintf("(sleep 1 && touch %s) & sleep 30", marker),
+					Timeout: "200ms",
+				},
+			},
+		},
+	}
+
+	start := time.Now()
+	err := NewExecutor(cfg, t.TempDir()).ExecutePostCreateHooks(&bytes.Buffer{}, worktreeDir)
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 10*time.Second)
+	assert.Contains(t, err.Error(), "failed to execute hook 2")
+	assert.Contains(t, err.Error(), "timed out after 200ms")
+
+	time.Sleep(1500 * time.Millisecond)
+	_, statErr := os.Stat(marker)
+	assert.True(t, os.IsNotExist(statErr), "processes spawned by the hook should be killed")
+}
+
+func TestExecutePostCreateHooks_DefaultHookTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+
+	cfg := &config.Config{
+		Defaults: config.Defaults{HookTimeout: "100ms"},
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{{Type: config.HookTypeCommand, Command: "sleep 30"}},
+		},
+	}
+
+	err := NewExecutor(cfg, t.TempDir()).ExecutePostCreateHooks(&bytes.Buffer{}, t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out after 100ms")
+}
+
+func TestExecutePostCreateHooks_OperationDeadline(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+
+	worktreeDir := t.TempDir()
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{Type: config.HookTypeCommand, Command: "echo first"},
+				{Type: config.HookTypeCommand, Command: "sleep 30"},
+				{Type: config.HookTypeCommand, Command: "touch third"},
+			},
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	timings, err := NewExecutor(cfg, t.TempDir()).WithContext(ctx).
+		ExecutePostCreateHooksTimed(&bytes.Buffer{}, worktreeDir)
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 10*time.Second)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "failed to execute hook 2: command was stopped")
+
+	require.Len(t, timings, 2)
+	assert.NoError(t, timings[0].Err)
+	assert.Error(t, timings[1].Err)
+	assert.NoFileExists(t, filepath.Join(worktreeDir, "third"))
+
+	_, err = NewExecutor(cfg, t.TempDir()).WithContext(ctx).ExecutePostCreateHooksTimed(&bytes.Buffer{}, worktreeDir)
+	assert.ErrorContains(t, err, "hook 1 was not started")
+}
+
+func TestHookBatches(t *testing.T) {
+	hookList := []config.Hook{
+		{Command: "a"},
+		{Command: "b", Group: "deps"},
+		{Command: "c", Group: "deps"},
+		{Command: "d"},
+		{Command: "e", Group: "deps"},
+		{Command: "f", Group: "assets"},
+	}
+	assert.Equal(t, [][]int{{0}, {1, 2}, {3}, {4}, {5}}, hookBatches(hookList))
+}
+
+func TestExecutePostCreateHooks_ParallelGroup(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+
+	// The first hook only succeeds if the second one runs while it is still waiting
+	waitForPeer := "for i in $(seq 20); do [ -f peer ] && exit 0; sleep 0.1; done; exit 1"
+	newConfig := func(concurrency int) *config.Config {
+		return &config.Config{
+			Defaults: config.Defaults{HookConcurrency: concurrency},
+			Hooks: config.Hooks{PostCreate: []config.Hook{
+				{Type: config.HookTypeCommand, Command: "echo before"},
+				{Type: config.HookTypeCommand, Command: "echo waiting; " + waitForPeer, Group: "setup", Timeout: "10s"},
+				{Type: config.HookTypeCommand, Command: "touch peer && echo created", Group: "setup"},
+				{Type: config.HookTypeCommand, Command: "test -f peer && echo after"},
+			}},
+		}
+	}
+
+	t.Run("hooks in a group run concurrently", func(t *testing.T) {
+		worktreeDir := t.TempDir()
+		var buf bytes.Buffer
+		timings, err := NewExecutor(newConfig(0), t.TempDir()).ExecutePostCreateHooksTimed(&buf, worktreeDir)
+		require.NoError(t, err)
+		require.Len(t, timings, 4)
+		assert.Equal(t, []int{1, 2, 3, 4}, []int{timings[0].Index, timings[1].Index, timings[2].Index, timings[3].Index})
+
+		output := buf.String()
+		assert.Contains(t, output, "Running hooks 2, 3 of 4 in parallel (group: setup)")
+		assert.Contains(t, output, "→ Hook 2 output:\n  Running: echo waiting;")
+		assert.Less(t, strings.Index(output, "✓ Hook 3 completed"), strings.Index(output, "Running hook 4 of 4"))
+	})
+
+	t.Run("concurrency limit of one runs the group in order", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := NewExecutor(newConfig(1), t.TempDir()).ExecutePostCreateHooks(&buf, t.TempDir())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to execute hook 2")
+		assert.Contains(t, buf.String(), "✓ Hook 3 completed", "other hooks in the group still finish")
+		assert.NotContains(t, buf.String(), "Running hook 4 of 4")
+	})
+}
+
+func TestExecutePostCreateHooksSelected(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+
+	cfg := &config.Config{
+		Hooks: config.Hooks{PostCreate: []config.Hook{
+			{Type: config.HookTypeCommand, Command: "echo first"},
+			{Type: config.HookTypeCommand, Command: "echo second && exit 3"},
+			{Type: config.HookTypeCommand, Command: "echo third"},
+		}},
+	}
+
+	t.Run("failed hook is timed with its error", func(t *testing.T) {
+		var buf bytes.Buffer
+		timings, err := NewExecutor(cfg, t.TempDir()).ExecutePostCreateHooksTimed(&buf, t.TempDir())
+		require.Error(t, err)
+		require.Len(t, timings, 2)
+		assert.NoError(t, timings[0].Err)
+		assert.Equal(t, 2, timings[1].Index)
+		require.Error(t, timings[1].Err)
+		assert.False(t, timings[1].StartedAt.IsZero())
+
+		code, ok := ExitCode(timings[1].Err)
+		assert.True(t, ok)
+		assert.Equal(t, 3, code)
+	})
+
+	t.Run("only the selected hooks run", func(t *testing.T) {
+		var buf bytes.Buffer
+		timings, err := NewExecutor(cfg, t.TempDir()).ExecutePostCreateHooksSelected(&buf, t.TempDir(), []int{3})
+		require.NoError(t, err)
+		require.Len(t, timings, 1)
+		assert.Equal(t, 3, timings[0].Index)
+		assert.Contains(t, buf.String(), "third")
+		assert.NotContains(t, buf.String(), "first")
+		assert.NotContains(t, buf.String(), "Skipping")
+	})
+}
+
+func TestExecutePreRemoveHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+
+	tempDir := t.TempDir()
+	repoRoot := filepath.Join(tempDir, "repo")
+	worktreeDir := filepath.Join(tempDir, "worktree")
+	require.NoError(t, os.MkdirAll(repoRoot, directoryPermissions))
+	require.NoError(t, os.MkdirAll(filepath.Join(worktreeDir, "dist"), directoryPermissions))
+	require.NoError(t, os.WriteFile(filepath.Join(worktreeDir, "dist", "app.js"), []byte("built"), 0o644))
+
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{Type: config.HookTypeCommand, Command: "echo 'post create'"},
+			},
+			PreRemove: []config.Hook{
+				{Type: config.HookTypeCommand, Command: "cp -R dist \"$GIT_WTP_REPO_ROOT/archive\""},
+			},
+		},
+	}
+
+	executor := NewExecutor(cfg, repoRoot)
+	var buf bytes.Buffer
+	require.NoError(t, executor.ExecutePreRemoveHooks(&buf, worktreeDir))
+
+	content, err := os.ReadFile(filepath.Join(repoRoot, "archive", "app.js"))
+	require.NoError(t, err)
+	assert.Equal(t, "built", string(content))
+	assert.NotContains(t, buf.String(), "post create")
+	assert.Contains(t, buf.String(), "✓ Hook 1 completed")
+}
+
+func TestExecutePreRemoveHooks_NoHooks(t *testing.T) {
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{Type: config.HookTypeCommand, Command: "exit 1"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, NewExecutor(cfg, t.TempDir()).ExecutePreRemoveHooks(&buf, t.TempDir()))
+	assert.Empty(t, buf.String())
+}
+
+func TestExecutePostCheckoutHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+
+	worktreeDir := t.TempDir()
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCheckout: []config.Hook{
+				{Type: config.HookTypeCommand, Command: "echo \"$GIT_WTP_OLD_BRANCH -> $GIT_WTP_NEW_BRANCH\""},
+			},
+		},
+	}
+
+	executor := NewExecutor(cfg, t.TempDir())
+	var buf bytes.Buffer
+	require.NoError(t, executor.ExecutePostCheckoutHooks(&buf, worktreeDir, "main", "feature/x"))
+	assert.Contains(t, buf.String(), "main -> feature/x")
+
+	// Branch variables are scoped to the post_checkout phase
+	assert.Empty(t, executor.phaseEnv)
+}
+
+func TestExecutePostRemoveHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+
+	repoRoot := t.TempDir()
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostRemove: []config.Hook{
+				{Type: config.HookTypeCommand, Command: "echo \"dropdb app_${BRANCH_SLUG} from $GIT_WTP_REMOVED_PATH\""},
+				{Type: config.HookTypeCommand, Command: "pwd", When: "branch =~ ^feature/"},
+				{Type: config.HookTypeCommand, Command: "echo release-only", When: "branch =~ ^release/"},
+			},
+		},
+	}
+
+	executor := NewExecutor(cfg, repoRoot)
+	var buf bytes.Buffer
+	require.NoError(t, executor.ExecutePostRemoveHooks(&buf, "/wt/feature-x", "feature/x"))
+
+	output := buf.String()
+	assert.Contains(t, output, "dropdb app_feature-x from /wt/feature-x")
+	resolvedRoot, _ := filepath.EvalSymlinks(repoRoot)
+	assert.True(t, strings.Contains(output, repoRoot) || strings.Contains(output, resolvedRoot),
+		"hooks run in the main worktree")
+	assert.Contains(t, output, "Skipping hook 3 of 3 (when: branch =~ ^release/)")
+	assert.Empty(t, executor.phaseEnv)
+}
+
+func TestExecutePostPruneHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostPrune: []config.Hook{
+				{Type: config.HookTypeCommand, Command: "echo \"$GIT_WTP_PRUNED_COUNT: $GIT_WTP_PRUNED_BRANCHES\""},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(cfg, t.TempDir()).ExecutePostPruneHooks(&buf,
+		[]string{"/wt/a", "/wt/b"}, []string{"a", "b"}))
+	assert.Contains(t, buf.String(), "2: a\nb")
+}
+
+func TestExecuteHooks_WhenConditions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+
+	worktreeDir := t.TempDir()
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCheckout: []config.Hook{
+				{Type: config.HookTypeCommand, Command: "echo feature-only", When: "${BRANCH} =~ ^feature/"},
+				{Type: config.HookTypeCommand, Command: "echo release-only", When: "branch =~ ^release/"},
+				{Type: config.HookTypeCommand, Command: "echo this-os", When: "os == " + runtime.GOOS},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	executor := NewExecutor(cfg, t.TempDir())
+	require.NoError(t, executor.ExecutePostCheckoutHooks(&buf, worktreeDir, "main", "feature/x"))
+
+	output := buf.String()
+	assert.Contains(t, output, "feature-only")
+	assert.NotContains(t, output, "release-only")
+	assert.Contains(t, output, "Skipping hook 2 of 3 (when: branch =~ ^release/)")
+	assert.Contains(t, output, "this-os")
+}
+
+func TestExecuteHooks_OSFilter(t *testing.T) {
+	otherOS := config.OSWindows
+	if runtime.GOOS == config.OSWindows {
+		otherOS = config.OSLinux
+	}
+	worktreeDir := t.TempDir()
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{Type: config.HookTypeEnsureLine, File: "this-os.txt", Line: "ran", OS: []string{runtime.GOOS}},
+				{Type: config.HookTypeEnsureLine, File: "other-os.txt", Line: "ran", OS: []string{otherOS}},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(cfg, t.TempDir()).ExecutePostCreateHooks(&buf, worktreeDir))
+
+	assert.FileExists(t, filepath.Join(worktreeDir, "this-os.txt"))
+	assert.NoFileExists(t, filepath.Join(worktreeDir, "other-os.txt"))
+	assert.Contains(t, buf.String(), "Skipping hook 2 of 2 (os: "+otherOS+")")
+}
+
+func TestExecutePostCreateHooks_WhenUsesWorktreeBranch(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+	repoRoot := setupCopySourceRepo(t) // checked out on 'feature'
+
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{Type: config.HookTypeCommand, Command: "echo on-feature", When: "branch == feature"},
+				{Type: config.HookTypeCommand, Command: "echo on-main", When: "branch == main"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(cfg, repoRoot).ExecutePostCreateHooks(&buf, repoRoot))
+	assert.Contains(t, buf.String(), "on-feature")
+	assert.NotContains(t, buf.String(), "echo on-main")
+}
+
 func TestExecutePostCreateHooks_CopyNonExistentFile(t *testing.T) {
 	// Create temp directories
 	tempDir := t.TempDir()
@@ -690,6 +1232,104 @@ func TestExecutePostCreateHooks_EnvironmentVariables(t *testing.T) {
 	assert.Contains(t, output, fmt.Sprintf("REPO=%s", repoRoot))
 }
 
+func TestExecutePostCreateHooks_WorktreeVariables(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+
+	tempDir := t.TempDir()
+	repoRoot := filepath.Join(tempDir, "repo")
+	worktreeDir := filepath.Join(tempDir, "worktrees", "feature-auth")
+	require.NoError(t, os.MkdirAll(repoRoot, directoryPermissions))
+	require.NoError(t, os.MkdirAll(worktreeDir, directoryPermissions))
+
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{
+					Type:    config.HookTypeCommand,
+					Command: "echo PATH=${WORKTREE_PATH} DIR=${WORKTREE_DIR} ENV=$COMPOSE_PROJECT_NAME",
+					Env:     map[string]string{"COMPOSE_PROJECT_NAME": "app-${WORKTREE_DIR}"},
+				},
+				{Type: config.HookTypeEnsureLine, File: ".env", Line: "ROOT=${WORKTREE_PATH}"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(cfg, repoRoot).ExecutePostCreateHooks(&buf, worktreeDir))
+
+	assert.Contains(t, buf.String(), fmt.Sprintf("PATH=%s DIR=feature-auth ENV=app-feature-auth", worktreeDir))
+	content, err := os.ReadFile(filepath.Join(worktreeDir, ".env"))
+	require.NoError(t, err)
+	assert.Equal(t, "ROOT="+worktreeDir+"\n", string(content))
+	// The configured hook is not modified
+	assert.Equal(t, "app-${WORKTREE_DIR}", cfg.Hooks.PostCreate[0].Env["COMPOSE_PROJECT_NAME"])
+}
+
+func TestExecutePostCreateHooks_DefaultsEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+
+	worktreeDir := filepath.Join(t.TempDir(), "feature-auth")
+	require.NoError(t, os.MkdirAll(worktreeDir, directoryPermissions))
+	cfg := &config.Config{
+		Defaults: config.Defaults{Env: map[string]string{
+			"NODE_ENV":     "development",
+			"PROJECT_NAME": "app-${WORKTREE_DIR}",
+		}},
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{Type: config.HookTypeCommand, Command: "echo first=$NODE_ENV $PROJECT_NAME"},
+				{
+					Type:    config.HookTypeCommand,
+					Command: "echo second=$NODE_ENV",
+					Env:     map[string]string{"NODE_ENV": "test"},
+				},
+				{Type: config.HookTypeEnsureLine, File: ".env", Line: "PROJECT=${PROJECT_NAME}"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(cfg, t.TempDir()).ExecutePostCreateHooks(&buf, worktreeDir))
+
+	assert.Contains(t, buf.String(), "first=development app-feature-auth")
+	assert.Contains(t, buf.String(), "second=test")
+	content, err := os.ReadFile(filepath.Join(worktreeDir, ".env"))
+	require.NoError(t, err)
+	assert.Equal(t, "PROJECT=app-feature-auth\n", string(content))
+}
+
+func TestExecutePostCreateHooks_ClearEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+	t.Setenv("WTP_TEST_LEAKED", "leaked")
+
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{Type: config.HookTypeCommand, Command: "echo inherited=$WTP_TEST_LEAKED"},
+				{
+					Type:     config.HookTypeCommand,
+					Command:  "echo cleared=$WTP_TEST_LEAKED kept=$KEPT path=${PATH:+set} wt=$GIT_WTP_WORKTREE_PATH",
+					Env:      map[string]string{"KEPT": "yes"},
+					ClearEnv: true,
+				},
+			},
+		},
+	}
+
+	worktreeDir := t.TempDir()
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(cfg, t.TempDir()).ExecutePostCreateHooks(&buf, worktreeDir))
+
+	assert.Contains(t, buf.String(), "inherited=leaked")
+	assert.Contains(t, buf.String(), "cleared= kept=yes path=set wt="+worktreeDir)
+}
+
 // streamingWriter tracks when writes occur to verify real-time streaming
 type streamingWriter struct {
 	writes []writeRecord
@@ -1052,7 +1692,7 @@ func TestExecutor_copyFile_SourceFileOpenError(t *testing.T) {
 	executor := NewExecutor(nil, "/test/repo")
 
 	// Try to copy non-existent file
-	err := executor.copyFile("/nonexistent/source.txt", "/tmp/dest.txt")
+	err := executor.copyFile("/nonexistent/source.txt", "/tmp/dest.txt", "")
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to open source file")
@@ -1070,7 +1710,7 @@ func TestExecutor_copyFile_DestinationCreateError(t *testing.T) {
 
 	// Try to create file in non-existent directory without creating parent dirs
 	invalidDest := "/nonexistent/directory/dest.txt"
-	err = executor.copyFile(srcFile, invalidDest)
+	err = executor.copyFile(srcFile, invalidDest, "")
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to create destination file")
@@ -1092,7 +1732,7 @@ func TestExecutor_copyFile_PermissionGetError(t *testing.T) {
 	executor := NewExecutor(nil, "/test/repo")
 
 	// Copy the file first
-	err = executor.copyFile(srcFile, dstFile)
+	err = executor.copyFile(srcFile, dstFile, "")
 	require.NoError(t, err)
 
 	// Remove source to trigger stat error in copyFile
@@ -1100,7 +1740,7 @@ func TestExecutor_copyFile_PermissionGetError(t *testing.T) {
 	require.NoError(t, err)
 
 	// Try to copy again - should fail at getting source file info
-	err = executor.copyFile(srcFile, dstFile+"2")
+	err = executor.copyFile(srcFile, dstFile+"2", "")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to open source file")
 }
@@ -1110,7 +1750,7 @@ func TestExecutor_copyDir_SourceDirectoryNotExist(t *testing.T) {
 	executor := NewExecutor(nil, "/test/repo")
 
 	// Try to copy non-existent directory
-	err := executor.copyDir("/nonexistent/source", "/tmp/dest")
+	err := executor.copyDir("/nonexistent/source", "/tmp/dest", "")
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to stat source directory")
@@ -1132,7 +1772,7 @@ func TestExecutor_copyDir_DestinationCreateError(t *testing.T) {
 	require.NoError(t, err)
 	invalidDest = filepath.Join(invalidDest, "nested")
 
-	err = executor.copyDir(srcDir, invalidDest)
+	err = executor.copyDir(srcDir, invalidDest, "")
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to create destination directory")
@@ -1149,7 +1789,7 @@ func TestExecutor_copyDir_ReadDirectoryError(t *testing.T) {
 
 	executor := NewExecutor(nil, "/test/repo")
 
-	err = executor.copyDir(srcDir, dstDir)
+	err = executor.copyDir(srcDir, dstDir, "")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to read source directory")
 }
@@ -1176,7 +1816,7 @@ func TestExecutor_copyDir_NestedDirectorySuccess(t *testing.T) {
 
 	executor := NewExecutor(nil, "/test/repo")
 
-	err = executor.copyDir(srcDir, dstDir)
+	err = executor.copyDir(srcDir, dstDir, "")
 	assert.NoError(t, err)
 
 	// Verify all files were copied correctly
@@ -1214,7 +1854,7 @@ func TestExecutor_copyDir_FailsWhenNestedFileCannotBeCopied(t *testing.T) {
 
 	executor := NewExecutor(nil, "/test/repo")
 
-	err = executor.copyDir(srcDir, dstDir)
+	err = executor.copyDir(srcDir, dstDir, "")
 	assert.Error(t, err)
 	// The error should propagate from the nested copyFile call
 }
@@ -0,0 +1,360 @@
+package hooks
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+const archiveSniffLength = 4
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zipMagic  = []byte("PK\x03\x04")
+)
+
+// archiveEntry is a format-independent view of a single tar or zip member.
+type archiveEntry struct {
+	name     string
+	mode     os.FileMode
+	isDir    bool
+	linkname string // symlink target, empty for regular files and directories
+	open     func() (io.Reader, error)
+}
+
+// executeExtractHookWithWriter unpacks the archive at hook.From into the directory hook.To.
+// A relative 'from' is looked up in the new worktree first (e.g. the output of an earlier
+// download hook) and then in the main worktree.
+func (e *Executor) executeExtractHookWithWriter(w io.Writer, hook *config.Hook, worktreePath string) error {
+	srcPath, err := e.resolveArchivePath(hook.From, worktreePath)
+	if err != nil {
+		return err
+	}
+
+	dstDir := hook.To
+	if !filepath.IsAbs(dstDir) {
+		dstDir = filepath.Join(worktreePath, dstDir)
+	}
+	dstDir = filepath.Clean(dstDir)
+	if !filepath.IsAbs(hook.To) {
+		if err := ensureWithinBase(worktreePath, dstDir); err != nil {
+			return err
+		}
+	}
+
+	relDst, _ := filepath.Rel(worktreePath, dstDir)
+	if _, err := fmt.Fprintf(w, "  Extracting: %s → %s\n", hook.From, relDst); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dstDir, directoryPermissions); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+	// Entries are confined to the real destination, so that symlinks are compared alike.
+	dstDir, err = filepath.EvalSymlinks(dstDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve destination directory: %w", err)
+	}
+
+	var links []string
+	err = walkArchive(srcPath, func(entry archiveEntry) error {
+		link, err := extractEntry(entry, dstDir, hook.StripComponents, hook.Overwrite)
+		if link != "" {
+			links = append(links, link)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return verifySymlinks(dstDir, links)
+}
+
+func (e *Executor) resolveArchivePath(from, worktreePath string) (string, error) {
+	if filepath.IsAbs(from) {
+		return filepath.Clean(from), nil
+	}
+
+	for _, base := range []string{worktreePath, e.repoRoot} {
+		candidate := filepath.Clean(filepath.Join(base, from))
+		if err := ensureWithinBase(base, candidate); err != nil {
+			return "", err
+		}
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("archive does not exist in the new or main worktree: %s", from)
+}
+
+// walkArchive detects the archive format from its content and calls fn for every entry.
+func walkArchive(srcPath string, fn func(archiveEntry) error) error {
+	// #nosec G304 -- srcPath is resolved from the project configuration file
+	file, err := os.Open(srcPath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	reader := bufio.NewReader(file)
+	magic, _ := reader.Peek(archiveSniffLength)
+
+	switch {
+	case bytes.HasPrefix(magic, zipMagic):
+		info, err := file.Stat()
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+		return walkZip(file, info.Size(), fn)
+	case bytes.HasPrefix(magic, gzipMagic):
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return fmt.Errorf("failed to read gzip archive: %w", err)
+		}
+		defer func() { _ = gz.Close() }()
+		return walkTar(gz, fn)
+	default:
+		return walkTar(reader, fn)
+	}
+}
+
+func walkTar(r io.Reader, fn func(archiveEntry) error) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar archive: %w", err)
+		}
+
+		entry := archiveEntry{
+			name: header.Name,
+			mode: header.FileInfo().Mode().Perm(),
+			open: func() (io.Reader, error) { return tr, nil },
+		}
+		switch header.Typeflag {
+		case tar.TypeXGlobalHeader:
+			continue // e.g. the commit id written by 'git archive'
+		case tar.TypeDir:
+			entry.isDir = true
+		case tar.TypeSymlink:
+			entry.linkname = header.Linkname
+		case tar.TypeReg:
+		default:
+			return fmt.Errorf("unsupported tar entry type for %s", header.Name)
+		}
+
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+}
+
+func walkZip(r io.ReaderAt, size int64, fn func(archiveEntry) error) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("failed to read zip archive: %w", err)
+	}
+
+	for _, member := range zr.File {
+		var rc io.ReadCloser
+		entry := archiveEntry{
+			name:  member.Name,
+			mode:  member.Mode().Perm(),
+			isDir: member.FileInfo().IsDir(),
+			open: func() (io.Reader, error) {
+				var err error
+				rc, err = member.Open()
+				return rc, err
+			},
+		}
+		if member.Mode()&os.ModeSymlink != 0 {
+			target, err := readZipMember(member)
+			if err != nil {
+				return err
+			}
+			entry.linkname = target
+		}
+
+		err := fn(entry)
+		if rc != nil {
+			_ = rc.Close()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readZipMember(member *zip.File) (string, error) {
+	rc, err := member.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", member.Name, err)
+	}
+	defer func() { _ = rc.Close() }()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", member.Name, err)
+	}
+	return string(data), nil
+}
+
+// stripArchivePath removes the first n elements of an archive member name.
+// It returns false when nothing is left, meaning the entry should be skipped.
+func stripArchivePath(name string, n int) (string, bool) {
+	parts := strings.Split(strings.Trim(path.Clean("/"+name), "/"), "/")
+	if len(parts) <= n || (len(parts) == 1 && parts[0] == "") {
+		return "", false
+	}
+	return path.Join(parts[n:]...), true
+}
+
+// extractEntry writes entry below dstDir, which must have no symlinks in it, and returns
+// the path of the symlink it created, if any.
+func extractEntry(entry archiveEntry, dstDir string, strip int, overwrite bool) (string, error) {
+	name, ok := stripArchivePath(entry.name, strip)
+	if !ok {
+		return "", nil
+	}
+
+	target := filepath.Join(dstDir, filepath.FromSlash(name))
+	dir := filepath.Dir(target)
+	if entry.isDir {
+		dir = target
+	}
+	// Symlinks extracted earlier may lead out of dstDir, e.g. "x" -> "." and then
+	// "x/y" -> "..", so the directory is compared once they are resolved.
+	realDir, err := resolveExistingPath(dir)
+	if err == nil {
+		err = ensureWithinBase(dstDir, realDir)
+	}
+	if err != nil {
+		return "", fmt.Errorf("archive entry %s: %w", entry.name, err)
+	}
+
+	if entry.isDir {
+		if err := os.MkdirAll(realDir, directoryPermissions); err != nil {
+			return "", fmt.Errorf("failed to create directory %s: %w", name, err)
+		}
+		return "", nil
+	}
+
+	if err := os.MkdirAll(realDir, directoryPermissions); err != nil {
+		return "", fmt.Errorf("failed to create directory for %s: %w", name, err)
+	}
+	target = filepath.Join(realDir, filepath.Base(target))
+
+	if _, err := os.Lstat(target); err == nil {
+		if !overwrite {
+			return "", fmt.Errorf("destination already exists: %s (set 'overwrite: true' to replace it)", name)
+		}
+		if err := os.Remove(target); err != nil {
+			return "", fmt.Errorf("failed to replace %s: %w", name, err)
+		}
+	}
+
+	if entry.linkname != "" {
+		if err := extractSymlink(&entry, dstDir, target, name); err != nil {
+			return "", err
+		}
+		return target, nil
+	}
+	return "", extractFile(&entry, target, name)
+}
+
+// resolveExistingPath resolves the symlinks in the longest existing prefix of the clean
+// path p and appends the rest. It refuses a dangling symlink, which a write would follow.
+func resolveExistingPath(p string) (string, error) {
+	rest := ""
+	for {
+		resolved, err := filepath.EvalSymlinks(p)
+		if err == nil {
+			return filepath.Join(resolved, rest), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to resolve %s: %w", p, err)
+		}
+		if _, err := os.Lstat(p); err == nil {
+			return "", fmt.Errorf("path %s goes through a dangling symlink", p)
+		}
+		parent := filepath.Dir(p)
+		if parent == p {
+			return filepath.Join(p, rest), nil
+		}
+		rest = filepath.Join(filepath.Base(p), rest)
+		p = parent
+	}
+}
+
+// extractSymlink recreates a symlink entry, refusing links that point outside dstDir.
+func extractSymlink(entry *archiveEntry, dstDir, target, name string) error {
+	resolved := entry.linkname
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(filepath.Dir(target), resolved)
+	}
+	if err := ensureWithinBase(dstDir, filepath.Clean(resolved)); err != nil {
+		return fmt.Errorf("archive symlink %s: %w", entry.name, err)
+	}
+	if err := os.Symlink(entry.linkname, target); err != nil {
+		return fmt.Errorf("failed to create symlink %s: %w", name, err)
+	}
+	return nil
+}
+
+// verifySymlinks checks where the extracted links lead once every entry is in place:
+// a later link can send an earlier one elsewhere, e.g. "x" -> "y/.." and then "y" -> ".".
+// The links that lead outside dstDir are removed.
+func verifySymlinks(dstDir string, links []string) error {
+	var escaped []string
+	for _, link := range links {
+		resolved, err := filepath.EvalSymlinks(link)
+		if err != nil {
+			continue // dangling links lead nowhere, and entries are not written through them
+		}
+		if ensureWithinBase(dstDir, resolved) != nil {
+			_ = os.Remove(link)
+			escaped = append(escaped, link)
+		}
+	}
+	if len(escaped) > 0 {
+		return fmt.Errorf("archive symlinks point outside %s: %s", dstDir, strings.Join(escaped, ", "))
+	}
+	return nil
+}
+
+func extractFile(entry *archiveEntry, target, name string) error {
+	src, err := entry.open()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", entry.name, err)
+	}
+
+	perm := entry.mode
+	if perm == 0 {
+		perm = regularFileMode
+	}
+	// #nosec G304 -- target is confined to the destination directory above
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", name, err)
+	}
+	if _, err := io.Copy(out, src); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return out.Close()
+}
@@ -0,0 +1,216 @@
+package hooks
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+type testArchiveFile struct {
+	name string
+	body string
+	mode int64
+	link string // symlink target, for tar archives
+}
+
+func writeTarGz(t *testing.T, path string, files []testArchiveFile) {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, f := range files {
+		mode := f.mode
+		if mode == 0 {
+			mode = 0o644
+		}
+		header := &tar.Header{Name: f.name, Mode: mode, Size: int64(len(f.body)), Typeflag: tar.TypeReg}
+		if strings.HasSuffix(f.name, "/") {
+			header = &tar.Header{Name: f.name, Mode: 0o755, Typeflag: tar.TypeDir}
+		}
+		if f.link != "" {
+			header = &tar.Header{Name: f.name, Mode: 0o777, Linkname: f.link, Typeflag: tar.TypeSymlink}
+		}
+		require.NoError(t, tw.WriteHeader(header))
+		_, err := tw.Write([]byte(f.body))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+}
+
+func writeZip(t *testing.T, path string, files []testArchiveFile) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(f.body))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+}
+
+func extractConfig(hook *config.Hook) *config.Config {
+	hook.Type = config.HookTypeExtract
+	return &config.Config{Hooks: config.Hooks{PostCreate: []config.Hook{*hook}}}
+}
+
+func TestExecutePostCreateHooks_ExtractTarGzWithStrip(t *testing.T) {
+	repoRoot := t.TempDir()
+	worktreeDir := t.TempDir()
+	writeTarGz(t, filepath.Join(repoRoot, "cache.tgz"), []testArchiveFile{
+		{name: "cache-v1/", body: ""},
+		{name: "cache-v1/index.json", body: "{}"},
+		{name: "cache-v1/bin/tool", body: "#!/bin/sh\n", mode: 0o755},
+	})
+
+	cfg := extractConfig(&config.Hook{From: "cache.tgz", To: ".cache", StripComponents: 1})
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(cfg, repoRoot).ExecutePostCreateHooks(&buf, worktreeDir))
+
+	content, err := os.ReadFile(filepath.Join(worktreeDir, ".cache", "index.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "{}", string(content))
+
+	info, err := os.Stat(filepath.Join(worktreeDir, ".cache", "bin", "tool"))
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode().Perm()&0o100, "executable bit should be preserved")
+	assert.Contains(t, buf.String(), "Extracting: cache.tgz → .cache")
+}
+
+func TestExecutePostCreateHooks_ExtractZipFromNewWorktree(t *testing.T) {
+	repoRoot := t.TempDir()
+	worktreeDir := t.TempDir()
+	// Simulates the output of an earlier download hook
+	writeZip(t, filepath.Join(worktreeDir, "fixtures.zip"), []testArchiveFile{
+		{name: "users.json", body: "[]"},
+	})
+
+	cfg := extractConfig(&config.Hook{From: "fixtures.zip", To: "testdata"})
+	require.NoError(t, NewExecutor(cfg, repoRoot).ExecutePostCreateHooks(&bytes.Buffer{}, worktreeDir))
+
+	content, err := os.ReadFile(filepath.Join(worktreeDir, "testdata", "users.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "[]", string(content))
+}
+
+func TestExecutePostCreateHooks_ExtractOverwrite(t *testing.T) {
+	repoRoot := t.TempDir()
+	worktreeDir := t.TempDir()
+	writeZip(t, filepath.Join(repoRoot, "seed.zip"), []testArchiveFile{{name: "seed.sql", body: "new"}})
+	require.NoError(t, os.WriteFile(filepath.Join(worktreeDir, "seed.sql"), []byte("old"), 0o644))
+
+	err := NewExecutor(extractConfig(&config.Hook{From: "seed.zip", To: "."}), repoRoot).
+		ExecutePostCreateHooks(&bytes.Buffer{}, worktreeDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "overwrite: true")
+
+	cfg := extractConfig(&config.Hook{From: "seed.zip", To: ".", Overwrite: true})
+	require.NoError(t, NewExecutor(cfg, repoRoot).ExecutePostCreateHooks(&bytes.Buffer{}, worktreeDir))
+	content, err := os.ReadFile(filepath.Join(worktreeDir, "seed.sql"))
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(content))
+}
+
+func TestExecutePostCreateHooks_ExtractConfinesEntries(t *testing.T) {
+	repoRoot := t.TempDir()
+	worktreeDir := filepath.Join(t.TempDir(), "worktree")
+	require.NoError(t, os.MkdirAll(worktreeDir, 0o755))
+	writeTarGz(t, filepath.Join(repoRoot, "evil.tgz"), []testArchiveFile{{name: "../../escape.txt", body: "x"}})
+
+	cfg := extractConfig(&config.Hook{From: "evil.tgz", To: "out"})
+	require.NoError(t, NewExecutor(cfg, repoRoot).ExecutePostCreateHooks(&bytes.Buffer{}, worktreeDir))
+
+	_, err := os.Stat(filepath.Join(worktreeDir, "out", "escape.txt"))
+	assert.NoError(t, err, "parent references are dropped from entry names")
+	_, err = os.Stat(filepath.Join(filepath.Dir(worktreeDir), "escape.txt"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestExecutePostCreateHooks_ExtractConfinesChainedSymlinks(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []testArchiveFile
+	}{
+		{
+			name: "link through an earlier link",
+			files: []testArchiveFile{
+				{name: "x", link: "."}, {name: "x/y", link: ".."}, {name: "y/escape.txt", body: "x"},
+			},
+		},
+		{
+			name: "link sent elsewhere by a later link",
+			files: []testArchiveFile{
+				{name: "x", link: "y/.."}, {name: "y", link: "."}, {name: "x/escape.txt", body: "x"},
+			},
+		},
+		{
+			name: "link sent elsewhere by a later link, nothing written through it",
+			files: []testArchiveFile{
+				{name: "x", link: "y/.."}, {name: "y", link: "."},
+			},
+		},
+		{
+			name: "write through a dangling link",
+			files: []testArchiveFile{
+				{name: "s", link: "."}, {name: "x", link: "s/../out"}, {name: "x/escape.txt", body: "x"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoRoot := t.TempDir()
+			worktreeDir := filepath.Join(t.TempDir(), "worktree")
+			require.NoError(t, os.MkdirAll(filepath.Join(worktreeDir, "dest"), 0o755))
+			writeTarGz(t, filepath.Join(repoRoot, "evil.tgz"), tt.files)
+
+			cfg := extractConfig(&config.Hook{From: "evil.tgz", To: "dest"})
+			err := NewExecutor(cfg, repoRoot).ExecutePostCreateHooks(&bytes.Buffer{}, worktreeDir)
+
+			require.Error(t, err)
+			for _, path := range []string{
+				filepath.Join(worktreeDir, "escape.txt"),
+				filepath.Join(worktreeDir, "y", "escape.txt"),
+				filepath.Join(worktreeDir, "out", "escape.txt"),
+			} {
+				_, statErr := os.Stat(path)
+				assert.True(t, os.IsNotExist(statErr), "%s must not be written", path)
+			}
+		})
+	}
+}
+
+func TestStripArchivePath(t *testing.T) {
+	tests := []struct {
+		name  string
+		strip int
+		want  string
+		ok    bool
+	}{
+		{"pkg/bin/tool", 0, "pkg/bin/tool", true},
+		{"pkg/bin/tool", 1, "bin/tool", true},
+		{"pkg/", 1, "", false},
+		{"./pkg/a", 1, "a", true},
+		{"", 0, "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := stripArchivePath(tt.name, tt.strip)
+		assert.Equal(t, tt.ok, ok, tt.name)
+		assert.Equal(t, tt.want, got, tt.name)
+	}
+}
@@ -0,0 +1,134 @@
+package hooks
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+// gitHooksDirName is the directory inside a worktree's private git directory that receives
+// the hook scripts.
+const gitHooksDirName = "hooks"
+
+// executeGitHooksHookWithWriter installs the hook scripts under hook.From (relative to the
+// worktree, so each branch uses its own tracked version) into the worktree's private git
+// directory and points the worktree's core.hooksPath at them. Only scripts that changed are
+// rewritten, so the hook can be re-run to refresh an existing worktree; files installed by
+// other tools are left alone.
+func (e *Executor) executeGitHooksHookWithWriter(w io.Writer, hook *config.Hook, worktreePath string) error {
+	srcDir := hook.From
+	if !filepath.IsAbs(srcDir) {
+		srcDir = filepath.Join(worktreePath, srcDir)
+	}
+	if info, err := os.Stat(srcDir); err != nil || !info.IsDir() {
+		return fmt.Errorf("git hooks directory does not exist: %s", srcDir)
+	}
+
+	output, err := e.gitOutput("-C", worktreePath, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return fmt.Errorf("failed to locate the worktree's git directory: %w", err)
+	}
+	hooksDir := filepath.Join(strings.TrimSpace(string(output)), gitHooksDirName)
+
+	mode := hook.Mode
+	if mode == "" {
+		mode = config.GitHooksModeCopy
+	}
+	updated, unchanged, err := syncGitHooks(srcDir, hooksDir, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "  Git hooks (%s): %s → %s (%d updated, %d unchanged)\n",
+		mode, hook.From, hooksDir, updated, unchanged); err != nil {
+		return err
+	}
+
+	hooksPathHook := &config.Hook{
+		Type:      config.HookTypeGitConfig,
+		Scope:     config.GitConfigScopeWorktree,
+		GitConfig: map[string]string{"core.hooksPath": hooksDir},
+	}
+	return e.executeGitConfigHookWithWriter(w, hooksPathHook, worktreePath)
+}
+
+// syncGitHooks mirrors the files under srcDir into dstDir, copying or symlinking each one
+// unless the destination is already up to date.
+func syncGitHooks(srcDir, dstDir, mode string) (updated, unchanged int, err error) {
+	err = filepath.WalkDir(srcDir, func(srcPath string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		rel, _ := filepath.Rel(srcDir, srcPath)
+		dstPath := filepath.Join(dstDir, rel)
+		if entry.IsDir() {
+			return os.MkdirAll(dstPath, directoryPermissions)
+		}
+
+		var current bool
+		var syncErr error
+		if mode == config.GitHooksModeSymlink {
+			current, syncErr = syncGitHookLink(srcPath, dstPath)
+		} else {
+			current, syncErr = syncGitHookCopy(srcPath, dstPath)
+		}
+		if syncErr != nil {
+			return fmt.Errorf("failed to install git hook %s: %w", rel, syncErr)
+		}
+		if current {
+			unchanged++
+		} else {
+			updated++
+		}
+		return nil
+	})
+	return updated, unchanged, err
+}
+
+// syncGitHookCopy copies src to dst unless dst is a regular file with the same content and
+// permissions. It reports whether dst was already current.
+func syncGitHookCopy(src, dst string) (bool, error) {
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return false, err
+	}
+	// #nosec G304 -- src is inside the configured hooks directory
+	content, err := os.ReadFile(src)
+	if err != nil {
+		return false, err
+	}
+
+	if dstInfo, err := os.Lstat(dst); err == nil && dstInfo.Mode().IsRegular() &&
+		dstInfo.Mode().Perm() == srcInfo.Mode().Perm() {
+		// #nosec G304 -- dst is inside the worktree's git directory
+		if existing, err := os.ReadFile(dst); err == nil && bytes.Equal(existing, content) {
+			return true, nil
+		}
+	}
+
+	// Replace rather than rewrite in place: dst may be a symlink left by mode: symlink
+	if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	if err := os.WriteFile(dst, content, srcInfo.Mode().Perm()); err != nil {
+		return false, err
+	}
+	return false, os.Chmod(dst, srcInfo.Mode().Perm())
+}
+
+// syncGitHookLink points dst at src unless it already does. It reports whether dst was
+// already current.
+func syncGitHookLink(src, dst string) (bool, error) {
+	if target, err := os.Readlink(dst); err == nil && target == src {
+		return true, nil
+	}
+	if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	return false, os.Symlink(src, dst)
+}
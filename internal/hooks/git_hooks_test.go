@@ -0,0 +1,110 @@
+package hooks
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+// setupGitHooksWorktree creates a linked worktree with a .githooks directory and returns
+// the repository root, the worktree, and the worktree's private hooks directory.
+func setupGitHooksWorktree(t *testing.T) (repoRoot, worktreeDir, hooksDir string) {
+	t.Helper()
+	repoRoot = setupCopySourceRepo(t)
+	worktreeDir = filepath.Join(t.TempDir(), "wt")
+	runGit(t, repoRoot, "worktree", "add", "-b", "topic", worktreeDir)
+
+	srcDir := filepath.Join(worktreeDir, ".githooks")
+	require.NoError(t, os.MkdirAll(filepath.Join(srcDir, "lib"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "pre-commit"), []byte("#!/bin/sh\nexit 0\n"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "lib", "common.sh"), []byte("# shared\n"), 0o644))
+
+	cmd := exec.Command("git", "rev-parse", "--absolute-git-dir")
+	cmd.Dir = worktreeDir
+	output, err := cmd.Output()
+	require.NoError(t, err)
+	return repoRoot, worktreeDir, filepath.Join(strings.TrimSpace(string(output)), "hooks")
+}
+
+func gitHooksConfig(mode string) *config.Config {
+	return &config.Config{Hooks: config.Hooks{PostCreate: []config.Hook{
+		{Type: config.HookTypeGitHooks, From: ".githooks", Mode: mode},
+	}}}
+}
+
+func TestExecutePostCreateHooks_GitHooksCopy(t *testing.T) {
+	repoRoot, worktreeDir, hooksDir := setupGitHooksWorktree(t)
+	cfg := gitHooksConfig("")
+
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(cfg, repoRoot).ExecutePostCreateHooks(&buf, worktreeDir))
+	assert.Contains(t, buf.String(), "Git hooks (copy): .githooks → "+hooksDir+" (2 updated, 0 unchanged)")
+
+	content, err := os.ReadFile(filepath.Join(hooksDir, "pre-commit"))
+	require.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\nexit 0\n", string(content))
+	assert.FileExists(t, filepath.Join(hooksDir, "lib", "common.sh"))
+	if runtime.GOOS != windowsOS {
+		info, err := os.Stat(filepath.Join(hooksDir, "pre-commit"))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+	}
+	assert.Equal(t, hooksDir, gitConfigValue(t, worktreeDir, "--worktree", "core.hooksPath"))
+
+	// A second run only refreshes what changed and leaves other tools' hooks alone
+	require.NoError(t, os.WriteFile(filepath.Join(worktreeDir, ".githooks", "pre-commit"),
+		[]byte("#!/bin/sh\nexit 1\n"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(hooksDir, "post-merge"), []byte("#!/bin/sh\n"), 0o755))
+	buf.Reset()
+	require.NoError(t, NewExecutor(cfg, repoRoot).ExecutePostCreateHooks(&buf, worktreeDir))
+	assert.Contains(t, buf.String(), "(1 updated, 1 unchanged)")
+	assert.Contains(t, buf.String(), "core.hooksPath already set")
+	content, err = os.ReadFile(filepath.Join(hooksDir, "pre-commit"))
+	require.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\nexit 1\n", string(content))
+	assert.FileExists(t, filepath.Join(hooksDir, "post-merge"))
+}
+
+func TestExecutePostCreateHooks_GitHooksSymlink(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("symlinks require extra privileges on Windows")
+	}
+	repoRoot, worktreeDir, hooksDir := setupGitHooksWorktree(t)
+	cfg := gitHooksConfig(config.GitHooksModeSymlink)
+
+	require.NoError(t, NewExecutor(cfg, repoRoot).ExecutePostCreateHooks(&bytes.Buffer{}, worktreeDir))
+	target, err := os.Readlink(filepath.Join(hooksDir, "pre-commit"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(worktreeDir, ".githooks", "pre-commit"), target)
+
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(cfg, repoRoot).ExecutePostCreateHooks(&buf, worktreeDir))
+	assert.Contains(t, buf.String(), "(0 updated, 2 unchanged)")
+
+	// Switching to copies replaces the links with files
+	require.NoError(t, NewExecutor(gitHooksConfig(config.GitHooksModeCopy), repoRoot).
+		ExecutePostCreateHooks(&bytes.Buffer{}, worktreeDir))
+	info, err := os.Lstat(filepath.Join(hooksDir, "pre-commit"))
+	require.NoError(t, err)
+	assert.True(t, info.Mode().IsRegular())
+}
+
+func TestExecutePostCreateHooks_GitHooksMissingSource(t *testing.T) {
+	repoRoot := setupCopySourceRepo(t)
+	cfg := &config.Config{Hooks: config.Hooks{PostCreate: []config.Hook{
+		{Type: config.HookTypeGitHooks, From: "no-such-dir"},
+	}}}
+
+	err := NewExecutor(cfg, repoRoot).ExecutePostCreateHooks(&bytes.Buffer{}, repoRoot)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "git hooks directory does not exist")
+}
@@ -0,0 +1,95 @@
+package hooks
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+// executeGitConfigHookWithWriter applies hook.GitConfig to the worktree's git configuration.
+// Keys are applied in sorted order and values that are already set are left untouched,
+// so re-running the hook is a no-op.
+func (e *Executor) executeGitConfigHookWithWriter(w io.Writer, hook *config.Hook, worktreePath string) error {
+	scope := hook.Scope
+	if scope == "" {
+		scope = config.GitConfigScopeLocal
+	}
+
+	if scope == config.GitConfigScopeWorktree {
+		if err := e.enableWorktreeConfig(w, worktreePath); err != nil {
+			return err
+		}
+	}
+
+	keys := make([]string, 0, len(hook.GitConfig))
+	for key := range hook.GitConfig {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := hook.GitConfig[key]
+		current, found, err := e.gitConfigGet(worktreePath, "--"+scope, key)
+		if err != nil {
+			return err
+		}
+		if found && current == value {
+			if _, err := fmt.Fprintf(w, "  Git config (%s): %s already set\n", scope, key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, err := fmt.Fprintf(w, "  Git config (%s): %s = %s\n", scope, key, value); err != nil {
+			return err
+		}
+		// --replace-all keeps the result deterministic when the key has several values
+		if _, err := e.gitOutput("-C", worktreePath, "config", "--"+scope, "--replace-all", key, value); err != nil {
+			return fmt.Errorf("failed to set git config %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// enableWorktreeConfig turns on extensions.worktreeConfig, which 'git config --worktree' requires.
+func (e *Executor) enableWorktreeConfig(w io.Writer, worktreePath string) error {
+	current, _, err := e.gitConfigGet(worktreePath, "--local", "extensions.worktreeConfig")
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(current, "true") {
+		return nil
+	}
+
+	if _, err := fmt.Fprintln(w, "  Git config (local): extensions.worktreeConfig = true"); err != nil {
+		return err
+	}
+	if _, err := e.gitOutput("-C", worktreePath, "config", "--local", "extensions.worktreeConfig", "true"); err != nil {
+		return fmt.Errorf("failed to enable per-worktree configuration: %w", err)
+	}
+	return nil
+}
+
+// gitConfigGet reads the last value of key in the given scope. A missing key is not an error.
+func (e *Executor) gitConfigGet(worktreePath, scopeFlag, key string) (value string, ok bool, err error) {
+	output, err := e.gitOutput("-C", worktreePath, "config", scopeFlag, "--get-all", key)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to read git config %s: %w", key, err)
+	}
+
+	values := strings.Split(strings.TrimSuffix(string(output), "\n"), "\n")
+	if len(values) > 1 {
+		// Several values: report as unset so the key is rewritten to the single configured value
+		return "", false, nil
+	}
+	return values[0], true, nil
+}
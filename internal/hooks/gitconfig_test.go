@@ -0,0 +1,82 @@
+package hooks
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func gitConfigValue(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"config"}, args...)...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	require.NoError(t, err, "git config %v", args)
+	return strings.TrimSpace(string(output))
+}
+
+func gitConfigHookConfig(hook *config.Hook) *config.Config {
+	hook.Type = config.HookTypeGitConfig
+	return &config.Config{Hooks: config.Hooks{PostCreate: []config.Hook{*hook}}}
+}
+
+func TestExecutePostCreateHooks_GitConfigLocal(t *testing.T) {
+	repoRoot := setupCopySourceRepo(t)
+	worktreeDir := filepath.Join(t.TempDir(), "wt")
+	runGit(t, repoRoot, "worktree", "add", "-b", "topic", worktreeDir)
+
+	cfg := gitConfigHookConfig(&config.Hook{GitConfig: map[string]string{
+		"pull.rebase":              "true",
+		"remote.origin.pushurl":    "git@example.com:me/repo.git",
+		"maintenance.auto":         "false",
+		"branch.topic.description": "work in progress",
+	}})
+
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(cfg, repoRoot).ExecutePostCreateHooks(&buf, worktreeDir))
+	assert.Equal(t, "true", gitConfigValue(t, worktreeDir, "--local", "pull.rebase"))
+	assert.Equal(t, "git@example.com:me/repo.git", gitConfigValue(t, worktreeDir, "--local", "remote.origin.pushurl"))
+	assert.Equal(t, "work in progress", gitConfigValue(t, worktreeDir, "--local", "branch.topic.description"))
+	assert.Less(t, strings.Index(buf.String(), "branch.topic.description"), strings.Index(buf.String(), "pull.rebase"),
+		"keys are applied in sorted order")
+
+	buf.Reset()
+	require.NoError(t, NewExecutor(cfg, repoRoot).ExecutePostCreateHooks(&buf, worktreeDir))
+	assert.Equal(t, 4, strings.Count(buf.String(), "already set"), "second run should be a no-op")
+}
+
+func TestExecutePostCreateHooks_GitConfigReplacesMultipleValues(t *testing.T) {
+	repoRoot := setupCopySourceRepo(t)
+	runGit(t, repoRoot, "config", "--add", "remote.origin.pushurl", "a")
+	runGit(t, repoRoot, "config", "--add", "remote.origin.pushurl", "b")
+
+	cfg := gitConfigHookConfig(&config.Hook{GitConfig: map[string]string{"remote.origin.pushurl": "c"}})
+	require.NoError(t, NewExecutor(cfg, repoRoot).ExecutePostCreateHooks(&bytes.Buffer{}, repoRoot))
+	assert.Equal(t, "c", gitConfigValue(t, repoRoot, "--get-all", "remote.origin.pushurl"))
+}
+
+func TestExecutePostCreateHooks_GitConfigWorktreeScope(t *testing.T) {
+	repoRoot := setupCopySourceRepo(t)
+	worktreeDir := filepath.Join(t.TempDir(), "wt")
+	runGit(t, repoRoot, "worktree", "add", "-b", "topic", worktreeDir)
+
+	cfg := gitConfigHookConfig(&config.Hook{
+		Scope:     config.GitConfigScopeWorktree,
+		GitConfig: map[string]string{"core.hooksPath": ".githooks"},
+	})
+	require.NoError(t, NewExecutor(cfg, repoRoot).ExecutePostCreateHooks(&bytes.Buffer{}, worktreeDir))
+
+	assert.Equal(t, "true", gitConfigValue(t, repoRoot, "--local", "extensions.worktreeConfig"))
+	assert.Equal(t, ".githooks", gitConfigValue(t, worktreeDir, "--worktree", "core.hooksPath"))
+
+	cmd := exec.Command("git", "config", "--get", "core.hooksPath")
+	cmd.Dir = repoRoot
+	assert.Error(t, cmd.Run(), "the main worktree should not see a per-worktree setting")
+}
@@ -0,0 +1,99 @@
+package hooks
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+// executeHardlinkHookWithWriter hard links hook.From, a file or a whole directory tree in
+// the main worktree, to hook.To in the new worktree. The links share their content and
+// disk space with the source, so the hook suits large assets that are never edited in
+// place. Files that already are links to their source are left alone, so the hook can be
+// re-run.
+func (e *Executor) executeHardlinkHookWithWriter(w io.Writer, hook *config.Hook, worktreePath string) error {
+	sourceRoot, srcPath, dstPath, err := e.resolveHookPaths(hook, worktreePath)
+	if err != nil {
+		return err
+	}
+
+	srcInfo, err := os.Stat(srcPath)
+	if err != nil {
+		return fmt.Errorf("source path does not exist: %s", srcPath)
+	}
+	if srcPath == dstPath {
+		return fmt.Errorf("source and destination paths must be different: %s -> %s", srcPath, dstPath)
+	}
+	if srcInfo.IsDir() {
+		if err := ensureDistinctPaths(srcPath, dstPath, srcInfo); err != nil {
+			return err
+		}
+		if ensureWithinBase(srcPath, dstPath) == nil {
+			return fmt.Errorf("destination path %s is inside the source directory %s", dstPath, srcPath)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dstPath), directoryPermissions); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	relSrc, _ := filepath.Rel(sourceRoot, srcPath)
+	relDst, _ := filepath.Rel(worktreePath, dstPath)
+	if _, err := fmt.Fprintf(w, "  Hardlinking: %s → %s\n", relSrc, relDst); err != nil {
+		return err
+	}
+	return linkTree(srcPath, dstPath)
+}
+
+// linkTree hard links src, a file or a directory tree, to dst. Directories are created
+// with the mode of their source; symbolic links inside the tree are linked as they are.
+func linkTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if !entry.IsDir() {
+			return linkFile(path, target)
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(target, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to create destination directory: %w", err)
+		}
+		return nil
+	})
+}
+
+// linkFile makes dst a hard link to src, unless it already is one.
+func linkFile(src, dst string) error {
+	err := os.Link(src, dst)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, fs.ErrExist):
+		srcInfo, srcErr := os.Lstat(src)
+		dstInfo, dstErr := os.Lstat(dst)
+		if srcErr == nil && dstErr == nil && os.SameFile(srcInfo, dstInfo) {
+			return nil
+		}
+		return fmt.Errorf("destination path already exists: %s", dst)
+	case errors.Is(err, syscall.EXDEV):
+		return fmt.Errorf("failed to create hard link: %w; the source and destination must be on the same "+
+			"file system, so use a copy hook instead", err)
+	default:
+		return fmt.Errorf("failed to create hard link: %w", err)
+	}
+}
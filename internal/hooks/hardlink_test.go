@@ -0,0 +1,100 @@
+package hooks
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func requireSameFile(t *testing.T, a, b string) {
+	t.Helper()
+	aInfo, err := os.Stat(a)
+	require.NoError(t, err)
+	bInfo, err := os.Stat(b)
+	require.NoError(t, err)
+	assert.True(t, os.SameFile(aInfo, bInfo), "%s and %s should be hard links to the same file", a, b)
+}
+
+func TestExecutePostCreateHooks_Hardlink(t *testing.T) {
+	repoRoot := t.TempDir()
+	worktreeDir := t.TempDir()
+	fixtures := filepath.Join(repoRoot, "fixtures")
+	require.NoError(t, os.MkdirAll(filepath.Join(fixtures, "images"), directoryPermissions))
+	require.NoError(t, os.WriteFile(filepath.Join(fixtures, "images", "logo.png"), []byte("png"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(fixtures, "seed.sql"), []byte("sql"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "model.bin"), []byte("weights"), 0o644))
+
+	cfg := &config.Config{Hooks: config.Hooks{PostCreate: []config.Hook{
+		{Type: config.HookTypeHardlink, From: "fixtures", To: "fixtures"},
+		{Type: config.HookTypeHardlink, From: "model.bin", To: "models/model.bin"},
+	}}}
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(cfg, repoRoot).ExecutePostCreateHooks(&buf, worktreeDir))
+
+	assert.Contains(t, buf.String(), "Hardlinking: fixtures → fixtures")
+	assert.Contains(t, buf.String(), "Hardlinking: model.bin → models/model.bin")
+	requireSameFile(t, filepath.Join(fixtures, "images", "logo.png"),
+		filepath.Join(worktreeDir, "fixtures", "images", "logo.png"))
+	requireSameFile(t, filepath.Join(fixtures, "seed.sql"), filepath.Join(worktreeDir, "fixtures", "seed.sql"))
+	requireSameFile(t, filepath.Join(repoRoot, "model.bin"), filepath.Join(worktreeDir, "models", "model.bin"))
+
+	// Re-running leaves the existing links alone
+	buf.Reset()
+	require.NoError(t, NewExecutor(cfg, repoRoot).ExecutePostCreateHooks(&buf, worktreeDir))
+}
+
+func TestExecutePostCreateHooks_HardlinkErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		hook    config.Hook
+		setup   func(t *testing.T, worktreeDir string)
+		wantErr string
+	}{
+		{
+			name:    "source missing",
+			hook:    config.Hook{Type: config.HookTypeHardlink, From: "missing", To: "missing"},
+			wantErr: "source path does not exist",
+		},
+		{
+			name: "destination is another file",
+			hook: config.Hook{Type: config.HookTypeHardlink, From: "data.bin", To: "data.bin"},
+			setup: func(t *testing.T, worktreeDir string) {
+				t.Helper()
+				require.NoError(t, os.WriteFile(filepath.Join(worktreeDir, "data.bin"), []byte("mine"), 0o644))
+			},
+			wantErr: "destination path already exists",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoRoot := t.TempDir()
+			worktreeDir := t.TempDir()
+			require.NoError(t, os.MkdirAll(filepath.Join(repoRoot, "assets"), directoryPermissions))
+			require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "data.bin"), []byte("data"), 0o644))
+			if tt.setup != nil {
+				tt.setup(t, worktreeDir)
+			}
+
+			err := NewExecutor(&config.Config{}, repoRoot).executeHardlinkHookWithWriter(&bytes.Buffer{}, &tt.hook,
+				worktreeDir)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestExecutePostCreateHooks_HardlinkIntoSource(t *testing.T) {
+	repoRoot := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(repoRoot, "assets"), directoryPermissions))
+	hook := &config.Hook{Type: config.HookTypeHardlink, From: "assets", To: filepath.Join(repoRoot, "assets", "copy")}
+
+	err := NewExecutor(&config.Config{}, repoRoot).executeHardlinkHookWithWriter(&bytes.Buffer{}, hook, t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "inside the source directory")
+}
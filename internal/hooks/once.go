@@ -0,0 +1,139 @@
+package hooks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+const (
+	onceStateDir     = "wtp"
+	onceStateFile    = "once-hooks.json"
+	onceStateDirMode = 0o755
+	onceStateMode    = 0o644
+)
+
+// onceStateMu serializes updates to the state file; hooks in a parallel group may finish together.
+var onceStateMu sync.Mutex
+
+// onceState is the content of the state file: completion times keyed by onceHookKey.
+type onceState struct {
+	Completed map[string]time.Time `json:"completed"`
+}
+
+// onceHookKey identifies a hook by its configuration, so editing a once_per_repo hook
+// makes it run again.
+func onceHookKey(hook *config.Hook) (string, error) {
+	data, err := json.Marshal(hook)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// onceStatePath returns the state file in the repository's common git directory, which
+// every worktree of the clone shares.
+func (e *Executor) onceStatePath() (string, error) {
+	commonDir, err := e.gitCommonDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(commonDir, onceStateDir, onceStateFile), nil
+}
+
+// gitCommonDir returns the absolute path of the repository's common git directory.
+func (e *Executor) gitCommonDir() (string, error) {
+	output, err := e.gitOutput("rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", fmt.Errorf("failed to locate git common directory: %w", err)
+	}
+	commonDir := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(e.repoRoot, commonDir)
+	}
+	return commonDir, nil
+}
+
+func readOnceState(path string) (*onceState, error) {
+	state := &onceState{Completed: make(map[string]time.Time)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if state.Completed == nil {
+		state.Completed = make(map[string]time.Time)
+	}
+	return state, nil
+}
+
+// onceHookCompleted reports whether a once_per_repo hook already ran for this repository.
+func (e *Executor) onceHookCompleted(hook *config.Hook) (bool, error) {
+	path, err := e.onceStatePath()
+	if err != nil {
+		return false, err
+	}
+	key, err := onceHookKey(hook)
+	if err != nil {
+		return false, err
+	}
+
+	onceStateMu.Lock()
+	defer onceStateMu.Unlock()
+	state, err := readOnceState(path)
+	if err != nil {
+		return false, err
+	}
+	_, done := state.Completed[key]
+	return done, nil
+}
+
+// recordOnceHook marks a successfully run once_per_repo hook as completed. Other hooks
+// are ignored.
+func (e *Executor) recordOnceHook(hook *config.Hook) error {
+	if !hook.OncePerRepo {
+		return nil
+	}
+	path, err := e.onceStatePath()
+	if err != nil {
+		return err
+	}
+	key, err := onceHookKey(hook)
+	if err != nil {
+		return err
+	}
+
+	onceStateMu.Lock()
+	defer onceStateMu.Unlock()
+	state, err := readOnceState(path)
+	if err != nil {
+		return err
+	}
+	state.Completed[key] = time.Now().UTC()
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), onceStateDirMode); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), onceStateMode); err != nil {
+		return fmt.Errorf("failed to record once_per_repo hook: %w", err)
+	}
+	return nil
+}
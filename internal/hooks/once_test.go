@@ -0,0 +1,70 @@
+package hooks
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func TestExecutePostCreateHooks_OncePerRepo(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+	repoRoot := setupCopySourceRepo(t)
+	first := filepath.Join(t.TempDir(), "first")
+	second := filepath.Join(t.TempDir(), "second")
+	runGit(t, repoRoot, "worktree", "add", "-q", first, "main")
+	runGit(t, repoRoot, "worktree", "add", "-q", "--detach", second, "main")
+
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{Type: config.HookTypeCommand, Command: "echo once >> installs.log", OncePerRepo: true},
+				{Type: config.HookTypeCommand, Command: "echo every > every.log"},
+			},
+		},
+	}
+
+	// The second worktree runs from its own directory; state lives in the shared git dir.
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(cfg, first).ExecutePostCreateHooks(&buf, first))
+	assert.FileExists(t, filepath.Join(first, "installs.log"))
+	assert.FileExists(t, filepath.Join(repoRoot, ".git", onceStateDir, onceStateFile))
+
+	buf.Reset()
+	require.NoError(t, NewExecutor(cfg, second).ExecutePostCreateHooks(&buf, second))
+	assert.Contains(t, buf.String(), "Skipping hook 1 of 2 (already run once for this repository)")
+	assert.NoFileExists(t, filepath.Join(second, "installs.log"))
+	assert.FileExists(t, filepath.Join(second, "every.log"))
+
+	// Changing the hook makes it run again.
+	cfg.Hooks.PostCreate[0].Command = "echo twice >> installs.log"
+	buf.Reset()
+	require.NoError(t, NewExecutor(cfg, second).ExecutePostCreateHooks(&buf, second))
+	assert.FileExists(t, filepath.Join(second, "installs.log"))
+}
+
+func TestExecutePostCreateHooks_OncePerRepoFailureNotRecorded(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+	repoRoot := setupCopySourceRepo(t)
+
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{{Type: config.HookTypeCommand, Command: "exit 1", OncePerRepo: true}},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.Error(t, NewExecutor(cfg, repoRoot).ExecutePostCreateHooks(&buf, repoRoot))
+	_, err := os.Stat(filepath.Join(repoRoot, ".git", onceStateDir, onceStateFile))
+	assert.True(t, os.IsNotExist(err), "failed hook should not be recorded")
+}
@@ -0,0 +1,152 @@
+package hooks
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/runtimedir"
+)
+
+// Hook lists, as named in the configuration; they name the log files of their hooks.
+const (
+	phasePostCreate   = "post_create"
+	phasePreRemove    = "pre_remove"
+	phasePostCheckout = "post_checkout"
+	phasePostRemove   = "post_remove"
+	phasePostPrune    = "post_prune"
+	phaseMaintenance  = "maintenance"
+	phaseClean        = "clean"
+)
+
+const (
+	// hookLogDirName is the directory of hook log files in the runtime directory.
+	hookLogDirName  = "logs"
+	hookLogFileMode = 0o644
+	// hookLogTailLines is how many of the last lines of a log file are shown when its
+	// hook fails.
+	hookLogTailLines = 20
+)
+
+// hookOutput receives a hook's output and sends it where the hook's 'output' says: to
+// the executor's writer, to a buffer that is shown only if the hook fails, or to a log
+// file. Close writes the summary line.
+type hookOutput struct {
+	w    io.Writer
+	mode string
+	// captured holds the output of HookOutputCapture.
+	captured bytes.Buffer
+	file     *os.File
+	lines    int
+}
+
+// openHookOutput returns where the output of hook number (1-based) goes.
+func (e *Executor) openHookOutput(
+	w io.Writer, hook *config.Hook, number int, worktreePath string,
+) (*hookOutput, error) {
+	out := &hookOutput{w: w, mode: hook.Output}
+	if hook.Output != config.HookOutputFile {
+		return out, nil
+	}
+
+	path, err := e.hookLogPath(worktreePath, number)
+	if err != nil {
+		return nil, err
+	}
+	// #nosec G304 -- the path is inside the configured log directory
+	out.file, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, hookLogFileMode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the hook log: %w", err)
+	}
+	return out, nil
+}
+
+// hookLogPath returns the log file of hook number in the running phase, creating its
+// directory, e.g. <worktree>/.wtp/logs/post_create-2.log.
+func (e *Executor) hookLogPath(worktreePath string, number int) (string, error) {
+	dir := e.config.Defaults.HookLogDir
+	if dir == "" {
+		runtimeDir, err := runtimedir.Ensure(e.config, worktreePath)
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(runtimeDir, hookLogDirName)
+	} else if !filepath.IsAbs(dir) {
+		dir = filepath.Join(worktreePath, dir)
+	}
+	if err := os.MkdirAll(dir, directoryPermissions); err != nil {
+		return "", fmt.Errorf("failed to create the hook log directory: %w", err)
+	}
+
+	phase := e.phase
+	if phase == "" {
+		phase = "hook"
+	}
+	return filepath.Join(dir, fmt.Sprintf("%s-%d.log", phase, number)), nil
+}
+
+func (o *hookOutput) Write(p []byte) (int, error) {
+	o.lines += bytes.Count(p, []byte("\n"))
+	switch {
+	case o.file != nil:
+		return o.file.Write(p)
+	case o.mode == config.HookOutputCapture:
+		return o.captured.Write(p)
+	default:
+		return o.w.Write(p)
+	}
+}
+
+// Close finishes the output of a hook that ended with hookErr: a captured output is shown
+// if the hook failed, and a log file is closed and named, with its last lines if the hook
+// failed.
+func (o *hookOutput) Close(hookErr error) error {
+	switch {
+	case o.file != nil:
+		path := o.file.Name()
+		if err := o.file.Close(); err != nil {
+			return fmt.Errorf("failed to write the hook log: %w", err)
+		}
+		if _, err := fmt.Fprintf(o.w, "  Output: %s (%d lines)\n", path, o.lines); err != nil {
+			return err
+		}
+		if hookErr == nil {
+			return nil
+		}
+		return writeLogTail(o.w, path)
+	case o.mode == config.HookOutputCapture && hookErr != nil:
+		_, err := o.captured.WriteTo(o.w)
+		return err
+	case o.mode == config.HookOutputCapture:
+		_, err := fmt.Fprintf(o.w, "  Output captured (%d lines)\n", o.lines)
+		return err
+	default:
+		return nil
+	}
+}
+
+// writeLogTail shows the last hookLogTailLines lines of the log file at path.
+func writeLogTail(w io.Writer, path string) error {
+	// #nosec G304 -- path is the log file the hook just wrote
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) > hookLogTailLines {
+		lines = lines[len(lines)-hookLogTailLines:]
+	}
+	if _, err := fmt.Fprintf(w, "  Last %d lines:\n", len(lines)); err != nil {
+		return err
+	}
+	for _, line := range lines {
+		if _, err := fmt.Fprintf(w, "    %s\n", line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
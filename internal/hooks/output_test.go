@@ -0,0 +1,75 @@
+package hooks
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func TestExecutePostCreateHooks_OutputModes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+
+	noisy := "for i in 1 2 3; do echo line $i; done"
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{Type: config.HookTypeCommand, Command: noisy, Output: config.HookOutputCapture},
+				{Type: config.HookTypeCommand, Command: noisy, Output: config.HookOutputFile},
+				{Type: config.HookTypeCommand, Command: "echo shown"},
+			},
+		},
+	}
+
+	worktreeDir := t.TempDir()
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(cfg, t.TempDir()).ExecutePostCreateHooks(&buf, worktreeDir))
+	assert.NotContains(t, buf.String(), "line 1")
+	assert.Contains(t, buf.String(), "  Output captured (4 lines)\n")
+	logPath := filepath.Join(worktreeDir, ".wtp", "logs", "post_create-2.log")
+	assert.Contains(t, buf.String(), "  Output: "+logPath+" (4 lines)\n")
+	assert.Contains(t, buf.String(), "shown\n")
+
+	log, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(log), "line 1\nline 2\nline 3\n")
+}
+
+func TestExecutePostCreateHooks_OutputShownOnFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+
+	failing := "echo installing; echo 'E404 not found' >&2; exit 1"
+	cfg := &config.Config{
+		Defaults: config.Defaults{HookLogDir: "build/logs"},
+		Hooks: config.Hooks{
+			PreRemove: []config.Hook{
+				{Type: config.HookTypeCommand, Command: failing, Output: config.HookOutputCapture,
+					OnError: config.OnErrorContinue},
+				{Type: config.HookTypeCommand, Command: failing, Output: config.HookOutputFile},
+			},
+		},
+	}
+
+	worktreeDir := t.TempDir()
+	var buf bytes.Buffer
+	err := NewExecutor(cfg, t.TempDir()).ExecutePreRemoveHooks(&buf, worktreeDir)
+	require.Error(t, err)
+	logPath := filepath.Join(worktreeDir, "build", "logs", "pre_remove-2.log")
+	first := buf.String()[:strings.Index(buf.String(), "✗ Hook 1 failed")]
+	assert.Contains(t, first, "installing\n", "captured output is shown on failure")
+	assert.Contains(t, first, "E404 not found\n")
+	assert.Contains(t, buf.String(), "  Output: "+logPath+" (3 lines)\n  Last 3 lines:\n")
+	assert.Contains(t, buf.String(), "    E404 not found\n")
+	assert.FileExists(t, logPath)
+}
@@ -0,0 +1,463 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"go.yaml.in/yaml/v3"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+const defaultPatchIndent = 2
+
+// hookReferencePattern matches a ${NAME} reference inside a patch or ensure_line value.
+var hookReferencePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// executePatchHookWithWriter applies hook.Set and hook.Delete to a JSON, YAML, or TOML file.
+// The file is only rewritten when its content changes, so re-running the hook is a no-op.
+func (e *Executor) executePatchHookWithWriter(w io.Writer, hook *config.Hook, worktreePath string) error {
+	target, err := hookFilePath(hook, worktreePath)
+	if err != nil {
+		return err
+	}
+	if _, err := hook.PatchFormat(); err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "  Patching: %s\n", hook.File); err != nil {
+		return err
+	}
+
+	_, updated, err := e.patchedContent(hook, target, worktreePath)
+	if err != nil {
+		return err
+	}
+	if updated == nil {
+		_, err := fmt.Fprintf(w, "  %s is already up to date\n", hook.File)
+		return err
+	}
+
+	return writePatchedFile(target, updated)
+}
+
+// hookFilePath resolves the 'file' of a patch or ensure_line hook against the worktree.
+func hookFilePath(hook *config.Hook, worktreePath string) (string, error) {
+	target := hook.File
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(worktreePath, target)
+		if err := ensureWithinBase(worktreePath, target); err != nil {
+			return "", err
+		}
+	}
+	return target, nil
+}
+
+// patchedContent returns the content of target before and after the patch hook; updated
+// is nil when the patch changes nothing, and original is nil when target does not exist.
+func (e *Executor) patchedContent(
+	hook *config.Hook, target, worktreePath string,
+) (original, updated []byte, err error) {
+	format, err := hook.PatchFormat()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// #nosec G304 -- target comes from the project configuration file
+	original, err = os.ReadFile(target)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", hook.File, err)
+	}
+
+	doc, err := decodePatchDocument(original, format)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", hook.File, err)
+	}
+	before, err := encodePatchDocument(doc, format, original)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := e.applyPatch(doc.Content[0], hook, worktreePath); err != nil {
+		return nil, nil, fmt.Errorf("failed to patch %s: %w", hook.File, err)
+	}
+
+	after, err := encodePatchDocument(doc, format, original)
+	if err != nil {
+		return nil, nil, err
+	}
+	if original != nil && bytes.Equal(before, after) {
+		return original, nil, nil
+	}
+	return original, after, nil
+}
+
+func (e *Executor) applyPatch(root *yaml.Node, hook *config.Hook, worktreePath string) error {
+	for _, selector := range hook.Delete {
+		steps, err := config.ParsePatchSelector(selector)
+		if err != nil {
+			return err
+		}
+		if err := deletePatchNode(root, steps); err != nil {
+			return fmt.Errorf("%s: %w", selector, err)
+		}
+	}
+
+	selectors := make([]string, 0, len(hook.Set))
+	for selector := range hook.Set {
+		selectors = append(selectors, selector)
+	}
+	sort.Strings(selectors)
+
+	var expand func(string) string
+	for _, selector := range selectors {
+		steps, err := config.ParsePatchSelector(selector)
+		if err != nil {
+			return err
+		}
+		if expand == nil {
+			expand = e.valueExpander(hook, worktreePath)
+		}
+		value, err := patchValueNode(hook.Set[selector], expand)
+		if err != nil {
+			return fmt.Errorf("%s: %w", selector, err)
+		}
+		if err := setPatchNode(root, steps, value); err != nil {
+			return fmt.Errorf("%s: %w", selector, err)
+		}
+	}
+	return nil
+}
+
+// valueExpander resolves ${NAME} references in patch and ensure_line values: the built-in ${BRANCH},
+// ${BRANCH_SLUG}, ${DIRNAME}, ${PATHNAME}, ${WORKTREE_PATH} and ${WORKTREE_DIR} first, then the
+// hook's env, then the environment.
+func (e *Executor) valueExpander(hook *config.Hook, worktreePath string) func(string) string {
+	branch := e.conditionContext(worktreePath).Branch
+	return func(s string) string {
+		s = e.expandVariables(s, worktreePath, branch)
+		return hookReferencePattern.ReplaceAllStringFunc(s, func(ref string) string {
+			name := hookReferencePattern.FindStringSubmatch(ref)[1]
+			if value, ok := hook.Env[name]; ok {
+				return value
+			}
+			if value, ok := e.registered.get(name); ok {
+				return value
+			}
+			return os.Getenv(name)
+		})
+	}
+}
+
+// patchValueNode converts a configured value into a YAML node. A string that consists of a
+// single ${NAME} reference takes the type of its expansion, so "${PORT}" becomes a number.
+func patchValueNode(value interface{}, expand func(string) string) (*yaml.Node, error) {
+	if s, ok := value.(string); ok {
+		expanded := expand(s)
+		if hookReferencePattern.FindString(s) == s {
+			return &yaml.Node{Kind: yaml.ScalarNode, Value: expanded}, nil
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: expanded}, nil
+	}
+
+	node := &yaml.Node{}
+	if err := node.Encode(value); err != nil {
+		return nil, fmt.Errorf("unsupported value: %w", err)
+	}
+	return node, nil
+}
+
+func setPatchNode(node *yaml.Node, steps []config.PatchStep, value *yaml.Node) error {
+	step := steps[0]
+	last := len(steps) == 1
+	if isNullNode(node) {
+		*node = *emptyContainerNode(step)
+	}
+
+	var child *yaml.Node
+	switch {
+	case step.IsIndex && node.Kind == yaml.SequenceNode:
+		switch {
+		case step.Index < len(node.Content):
+			child = node.Content[step.Index]
+		case step.Index == len(node.Content):
+			child = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+			node.Content = append(node.Content, child)
+		default:
+			return fmt.Errorf("index %d is past the end of a list of %d items", step.Index, len(node.Content))
+		}
+	case !step.IsIndex && node.Kind == yaml.MappingNode:
+		child = mappingValue(node, step.Key)
+		if child == nil {
+			child = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+			node.Content = append(node.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: step.Key}, child)
+		}
+	default:
+		return fmt.Errorf("cannot select %s in a %s", describePatchStep(step), describeNodeKind(node))
+	}
+
+	if last {
+		replaced := *value
+		replaced.HeadComment, replaced.LineComment, replaced.FootComment =
+			child.HeadComment, child.LineComment, child.FootComment
+		*child = replaced
+		return nil
+	}
+	return setPatchNode(child, steps[1:], value)
+}
+
+// deletePatchNode removes the selected value. Selecting something that does not exist is not an error.
+func deletePatchNode(node *yaml.Node, steps []config.PatchStep) error {
+	step := steps[0]
+	last := len(steps) == 1
+
+	switch {
+	case step.IsIndex && node.Kind == yaml.SequenceNode:
+		if step.Index >= len(node.Content) {
+			return nil
+		}
+		if last {
+			node.Content = append(node.Content[:step.Index], node.Content[step.Index+1:]...)
+			return nil
+		}
+		return deletePatchNode(node.Content[step.Index], steps[1:])
+	case !step.IsIndex && node.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value != step.Key {
+				continue
+			}
+			if last {
+				node.Content = append(node.Content[:i], node.Content[i+2:]...)
+				return nil
+			}
+			return deletePatchNode(node.Content[i+1], steps[1:])
+		}
+		return nil
+	case isNullNode(node):
+		return nil
+	default:
+		return fmt.Errorf("cannot select %s in a %s", describePatchStep(step), describeNodeKind(node))
+	}
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func isNullNode(node *yaml.Node) bool {
+	return node.Kind == 0 || (node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null")
+}
+
+func emptyContainerNode(step config.PatchStep) *yaml.Node {
+	if step.IsIndex {
+		return &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	}
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+}
+
+func describePatchStep(step config.PatchStep) string {
+	if step.IsIndex {
+		return fmt.Sprintf("index %d", step.Index)
+	}
+	return fmt.Sprintf("key %q", step.Key)
+}
+
+func describeNodeKind(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "mapping"
+	case yaml.SequenceNode:
+		return "list"
+	default:
+		return "scalar value"
+	}
+}
+
+// decodePatchDocument parses data into a YAML document node. JSON is parsed as YAML,
+// which keeps key order; TOML goes through a generic map.
+func decodePatchDocument(data []byte, format string) (*yaml.Node, error) {
+	doc := &yaml.Node{Kind: yaml.DocumentNode}
+
+	if format == config.PatchFormatTOML {
+		values := map[string]interface{}{}
+		if err := toml.Unmarshal(data, &values); err != nil {
+			return nil, err
+		}
+		root := &yaml.Node{}
+		if err := root.Encode(values); err != nil {
+			return nil, err
+		}
+		doc.Content = []*yaml.Node{root}
+		return doc, nil
+	}
+
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := yaml.Unmarshal(data, doc); err != nil {
+			return nil, err
+		}
+	}
+	if len(doc.Content) == 0 {
+		doc.Kind = yaml.DocumentNode
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	return doc, nil
+}
+
+// encodePatchDocument serializes doc, reusing the indentation of the original file.
+func encodePatchDocument(doc *yaml.Node, format string, original []byte) ([]byte, error) {
+	switch format {
+	case config.PatchFormatJSON:
+		var compact bytes.Buffer
+		if err := writeJSONNode(&compact, doc); err != nil {
+			return nil, err
+		}
+		var out bytes.Buffer
+		if err := json.Indent(&out, compact.Bytes(), "", detectIndent(original, true)); err != nil {
+			return nil, err
+		}
+		out.WriteByte('\n')
+		return out.Bytes(), nil
+	case config.PatchFormatTOML:
+		var values interface{}
+		if err := doc.Content[0].Decode(&values); err != nil {
+			return nil, err
+		}
+		return toml.Marshal(values)
+	default:
+		var out bytes.Buffer
+		enc := yaml.NewEncoder(&out)
+		enc.SetIndent(len(detectIndent(original, false)))
+		if err := enc.Encode(doc); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+		return out.Bytes(), nil
+	}
+}
+
+// detectIndent returns the leading whitespace of the first indented line of data.
+func detectIndent(data []byte, allowTabs bool) string {
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" || len(trimmed) == len(line) {
+			continue
+		}
+		indent := line[:len(line)-len(trimmed)]
+		if strings.HasPrefix(indent, "\t") && allowTabs {
+			return "\t"
+		}
+		if n := len(indent) - len(strings.TrimLeft(indent, " ")); n >= defaultPatchIndent {
+			return strings.Repeat(" ", n)
+		}
+		break
+	}
+	return strings.Repeat(" ", defaultPatchIndent)
+}
+
+// writeJSONNode writes node as compact JSON, preserving mapping key order.
+func writeJSONNode(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		return writeJSONNode(buf, node.Content[0])
+	case yaml.AliasNode:
+		return writeJSONNode(buf, node.Alias)
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSONString(buf, node.Content[i].Value); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := writeJSONNode(buf, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSONNode(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	default:
+		return writeJSONScalar(buf, node)
+	}
+}
+
+func writeJSONScalar(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.ShortTag() {
+	case "!!null":
+		buf.WriteString("null")
+		return nil
+	case "!!int", "!!float":
+		if json.Valid([]byte(node.Value)) {
+			buf.WriteString(node.Value)
+			return nil
+		}
+	case "!!bool":
+	default:
+		return writeJSONString(buf, node.Value)
+	}
+
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	buf.Write(data)
+	return nil
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) error {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return err
+	}
+	buf.Truncate(buf.Len() - 1) // drop the newline Encode appends
+	return nil
+}
+
+func writePatchedFile(target string, data []byte) error {
+	perm := os.FileMode(regularFileMode)
+	if info, err := os.Stat(target); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if err := os.MkdirAll(filepath.Dir(target), directoryPermissions); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(target, data, perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", target, err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package hooks
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func patchConfig(hook *config.Hook) *config.Config {
+	hook.Type = config.HookTypePatch
+	return &config.Config{Hooks: config.Hooks{PostCreate: []config.Hook{*hook}}}
+}
+
+func runPatchHook(t *testing.T, worktreeDir string, hook *config.Hook) string {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(patchConfig(hook), t.TempDir()).ExecutePostCreateHooks(&buf, worktreeDir))
+	return buf.String()
+}
+
+func TestExecutePostCreateHooks_PatchYAMLPreservesComments(t *testing.T) {
+	worktreeDir := t.TempDir()
+	t.Setenv("WTP_PORT_BASE", "4100")
+	file := filepath.Join(worktreeDir, "config", "dev.yml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(file), 0o755))
+	require.NoError(t, os.WriteFile(file, []byte("# dev settings\nport: 3000 # default\nname: app\ndebug: true\n"), 0o644))
+
+	runPatchHook(t, worktreeDir, &config.Hook{
+		File:   "config/dev.yml",
+		Set:    map[string]interface{}{".port": "${WTP_PORT_BASE}", ".db.name": "app_${BRANCH_SLUG}"},
+		Delete: []string{".debug"},
+	})
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "# dev settings\nport: 4100 # default\nname: app\ndb:\n  name: app_\n", string(content))
+}
+
+func TestExecutePostCreateHooks_PatchJSONKeepsKeyOrderAndIndent(t *testing.T) {
+	worktreeDir := t.TempDir()
+	file := filepath.Join(worktreeDir, "package.json")
+	original := "{\n    \"name\": \"app\",\n    \"version\": \"1.0.0\",\n    \"scripts\": [\"a&b\"]\n}\n"
+	require.NoError(t, os.WriteFile(file, []byte(original), 0o600))
+
+	runPatchHook(t, worktreeDir, &config.Hook{
+		File: "package.json",
+		Set: map[string]interface{}{
+			".version":      "2.0.0",
+			".scripts[1]":   "dev",
+			`."dotted.key"`: map[string]interface{}{"enabled": true, "retries": 3},
+		},
+	})
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "{\n    \"name\": \"app\",\n    \"version\": \"2.0.0\",\n    \"scripts\": [\n        \"a&b\",\n"+
+		"        \"dev\"\n    ],\n    \"dotted.key\": {\n        \"enabled\": true,\n        \"retries\": 3\n    }\n}\n",
+		string(content))
+
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "permissions should be preserved")
+}
+
+func TestExecutePostCreateHooks_PatchTOML(t *testing.T) {
+	worktreeDir := t.TempDir()
+	file := filepath.Join(worktreeDir, "settings.toml")
+	require.NoError(t, os.WriteFile(file, []byte("[server]\nport = 3000\nhost = \"localhost\"\n"), 0o644))
+
+	runPatchHook(t, worktreeDir, &config.Hook{
+		File: "settings.toml",
+		Set:  map[string]interface{}{"server.port": 3001},
+	})
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "port = 3001")
+	assert.Contains(t, string(content), "host = 'localhost'")
+}
+
+func TestExecutePostCreateHooks_PatchCreatesFileAndIsIdempotent(t *testing.T) {
+	worktreeDir := t.TempDir()
+	hook := config.Hook{
+		File: ".vscode/settings.json",
+		Set:  map[string]interface{}{`["editor.formatOnSave"]`: true},
+	}
+
+	runPatchHook(t, worktreeDir, &hook)
+	content, err := os.ReadFile(filepath.Join(worktreeDir, ".vscode", "settings.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"editor.formatOnSave\": true\n}\n", string(content))
+
+	output := runPatchHook(t, worktreeDir, &hook)
+	assert.Contains(t, output, "already up to date")
+}
+
+func TestExecutePostCreateHooks_PatchTypeMismatch(t *testing.T) {
+	worktreeDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(worktreeDir, "app.yml"), []byte("port: 3000\n"), 0o644))
+
+	err := NewExecutor(patchConfig(&config.Hook{
+		File: "app.yml",
+		Set:  map[string]interface{}{".port.number": 1},
+	}), t.TempDir()).ExecutePostCreateHooks(&bytes.Buffer{}, worktreeDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `cannot select key "number" in a scalar value`)
+}
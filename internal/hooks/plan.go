@@ -0,0 +1,102 @@
+package hooks
+
+import (
+	"path/filepath"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+// PlannedHook is a hook as it would run; see PlanPostCreateHooks.
+type PlannedHook struct {
+	Index int // 1-based position in the hook list
+	// Hook has its 'from', 'to', and 'command' fields expanded.
+	Hook config.Hook
+	// Skip is why the hook would not run; empty when it would.
+	Skip string
+	// Diff is a unified diff of what a copy, patch, or ensure_line hook that would run
+	// changes in the worktree's files; empty when it changes nothing.
+	Diff string
+	// Problem is why the hook would fail, when the preview found out.
+	Problem string
+}
+
+// PlanPostCreateHooks reports what ExecutePostCreateHooks would do for a new worktree of
+// branch at worktreePath, without running any hook. Variables registered by earlier hooks
+// are only known once those hooks run and are left unexpanded. Diffs compare against the
+// files at worktreePath, so for a worktree that does not exist yet every file is new.
+func (e *Executor) PlanPostCreateHooks(worktreePath, branch string) ([]PlannedHook, error) {
+	if e.config == nil {
+		return nil, nil
+	}
+	return e.planHooks(e.config.Hooks.PostCreate, worktreePath, branch, true)
+}
+
+// PlanPostCheckoutHooks is like PlanPostCreateHooks for the post_checkout hooks run when
+// newBranch is checked out.
+func (e *Executor) PlanPostCheckoutHooks(worktreePath, newBranch string) ([]PlannedHook, error) {
+	if e.config == nil {
+		return nil, nil
+	}
+	return e.planHooks(e.config.Hooks.PostCheckout, worktreePath, newBranch, true)
+}
+
+// ExplainHooks is like PlanPostCreateHooks for any list of hooks, such as pre_remove, but
+// only expands the hooks and decides which would be skipped; nothing is previewed.
+func (e *Executor) ExplainHooks(hookList []config.Hook, worktreePath, branch string) ([]PlannedHook, error) {
+	return e.planHooks(hookList, worktreePath, branch, false)
+}
+
+func (e *Executor) planHooks(hookList []config.Hook, worktreePath, branch string, preview bool) ([]PlannedHook, error) {
+	planner := *e
+	planner.branch = branch
+	var condCtx *config.ConditionContext
+
+	planned := make([]PlannedHook, 0, len(hookList))
+	for i := range hookList {
+		reason, err := planner.hookSkipReason(&hookList[i], i, worktreePath, &condCtx)
+		if err != nil {
+			return nil, err
+		}
+		entry := PlannedHook{
+			Index: i + 1,
+			Hook:  *planner.expandHookFields(&hookList[i], worktreePath),
+			Skip:  reason,
+		}
+		if reason == "" && preview {
+			if entry.Diff, err = planner.previewHook(&entry.Hook, worktreePath); err != nil {
+				entry.Problem = err.Error()
+			}
+		}
+		planned = append(planned, entry)
+	}
+	return planned, nil
+}
+
+// SymlinkPaths returns where the post_create symlink hooks put their links in the worktree
+// of branch at worktreePath, with variables expanded, whether or not the hooks would run
+// now. Hooks whose destination escapes the worktree are left out, as they never run.
+func (e *Executor) SymlinkPaths(worktreePath, branch string) []string {
+	if e.config == nil {
+		return nil
+	}
+	planner := *e
+	planner.branch = branch
+
+	var paths []string
+	for i := range e.config.Hooks.PostCreate {
+		hook := &e.config.Hooks.PostCreate[i]
+		if hook.Type != config.HookTypeSymlink {
+			continue
+		}
+		to := planner.expandHookFields(hook, worktreePath).To
+		dstPath := filepath.Clean(to)
+		if !filepath.IsAbs(to) {
+			dstPath = filepath.Join(worktreePath, to)
+			if ensureWithinBase(worktreePath, dstPath) != nil {
+				continue
+			}
+		}
+		paths = append(paths, dstPath)
+	}
+	return paths
+}
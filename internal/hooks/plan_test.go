@@ -0,0 +1,69 @@
+package hooks
+
+import (
+	"bytes"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func TestPlanPostCreateHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+	repoRoot := setupCopySourceRepo(t)
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{Type: config.HookTypeCommand, Command: "echo once > once.log", OncePerRepo: true},
+				{Type: config.HookTypeCopy, From: "${BRANCH_SLUG}.env", To: ".env"},
+				{Type: config.HookTypeCommand, Command: "echo main", When: `branch == "main"`},
+			},
+		},
+	}
+	worktreePath := filepath.Join(t.TempDir(), "feature")
+
+	planned, err := NewExecutor(cfg, repoRoot).PlanPostCreateHooks(worktreePath, "feature/a")
+	require.NoError(t, err)
+	require.Len(t, planned, 3)
+	assert.Equal(t, "", planned[0].Skip)
+	assert.Equal(t, "feature-a.env", planned[1].Hook.From)
+	assert.Equal(t, 2, planned[1].Index)
+	assert.Contains(t, planned[1].Problem, "source path does not exist")
+	assert.Equal(t, `when: branch == "main"`, planned[2].Skip)
+	assert.NoDirExists(t, worktreePath)
+
+	// Once the once_per_repo hook has run, the plan reports it as skipped.
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(&config.Config{Hooks: config.Hooks{PostCreate: cfg.Hooks.PostCreate[:1]}},
+		repoRoot).ExecutePostCreateHooks(&buf, repoRoot))
+	planned, err = NewExecutor(cfg, repoRoot).PlanPostCreateHooks(worktreePath, "feature/a")
+	require.NoError(t, err)
+	assert.Equal(t, "already run once for this repository", planned[0].Skip)
+}
+
+func TestSymlinkPaths(t *testing.T) {
+	worktreePath := filepath.Join(t.TempDir(), "feature")
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{Type: config.HookTypeSymlink, From: "node_modules", To: "node_modules"},
+				{Type: config.HookTypeCopy, From: ".env", To: ".env"},
+				{Type: config.HookTypeSymlink, From: "data", To: "data/${BRANCH_SLUG}"},
+				{Type: config.HookTypeSymlink, From: "shared", To: "../outside"},
+			},
+		},
+	}
+
+	paths := NewExecutor(cfg, t.TempDir()).SymlinkPaths(worktreePath, "feature/a")
+	assert.Equal(t, []string{
+		filepath.Join(worktreePath, "node_modules"),
+		filepath.Join(worktreePath, "data", "feature-a"),
+	}, paths)
+	assert.Empty(t, NewExecutor(nil, t.TempDir()).SymlinkPaths(worktreePath, "feature/a"))
+}
@@ -0,0 +1,245 @@
+package hooks
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+const (
+	// maxPreviewBytes is the largest file a plan shows the content of; larger files, like
+	// binary ones, are only reported as changed.
+	maxPreviewBytes = 256 << 10
+	// maxPreviewFiles is how many changed files of one hook a plan shows.
+	maxPreviewFiles  = 20
+	diffContextLines = 3
+)
+
+// copyPreview is a file a copy hook would write: dst gets the content of the file src, or
+// of the git blob object when the hook copies from a ref.
+type copyPreview struct {
+	src    string
+	object string
+	dst    string
+}
+
+// previewHook returns a unified diff of what a copy, patch, or ensure_line hook would
+// change in the worktree's files, relative to the worktree; other hook types, and hooks
+// that would change nothing, return "". hook must have its fields expanded.
+func (e *Executor) previewHook(hook *config.Hook, worktreePath string) (string, error) {
+	switch hook.Type {
+	case config.HookTypeCopy:
+		return e.previewCopyHook(hook, worktreePath)
+	case config.HookTypePatch, config.HookTypeEnsureLine:
+		target, err := hookFilePath(hook, worktreePath)
+		if err != nil {
+			return "", err
+		}
+		content := e.patchedContent
+		if hook.Type == config.HookTypeEnsureLine {
+			content = e.ensuredLineContent
+		}
+		original, updated, err := content(hook, target, worktreePath)
+		if err != nil || updated == nil {
+			return "", err
+		}
+		return fileDiff(relativeToWorktree(worktreePath, target), original, updated), nil
+	default:
+		return "", nil
+	}
+}
+
+func (e *Executor) previewCopyHook(hook *config.Hook, worktreePath string) (string, error) {
+	files, err := e.copyPreviews(hook, worktreePath)
+	if err != nil {
+		return "", err
+	}
+
+	var diff strings.Builder
+	shown := 0
+	for _, file := range files {
+		var content []byte
+		if file.object != "" {
+			content, err = e.gitOutput("cat-file", "blob", file.object)
+		} else {
+			// #nosec G304 -- src is validated against the source root
+			content, err = os.ReadFile(file.src)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", file.src, err)
+		}
+		// #nosec G304 -- dst is validated against the worktree path
+		existing, err := os.ReadFile(file.dst)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("failed to read %s: %w", file.dst, err)
+		}
+		if existing != nil && bytes.Equal(existing, content) {
+			continue
+		}
+
+		shown++
+		if shown > maxPreviewFiles {
+			continue
+		}
+		diff.WriteString(fileDiff(relativeToWorktree(worktreePath, file.dst), existing, content))
+	}
+	if shown > maxPreviewFiles {
+		fmt.Fprintf(&diff, "... and %d more changed file(s)\n", shown-maxPreviewFiles)
+	}
+	return diff.String(), nil
+}
+
+// copyPreviews lists the files a copy hook would write, following the same rules as
+// executeCopyHookWithWriter: from a ref, from a glob, or from a file or directory.
+func (e *Executor) copyPreviews(hook *config.Hook, worktreePath string) ([]copyPreview, error) {
+	sourceRoot, srcPath, dstPath, err := e.resolveHookPaths(hook, worktreePath)
+	if err != nil {
+		return nil, err
+	}
+	if hook.FromRef != "" {
+		relSrc, _ := filepath.Rel(sourceRoot, srcPath)
+		return e.refCopyPreviews(hook.FromRef, relSrc, dstPath)
+	}
+
+	sources := map[string]string{srcPath: dstPath}
+	if config.IsGlobPattern(hook.From) {
+		if sources, err = globCopySources(srcPath, dstPath); err != nil {
+			return nil, err
+		}
+	}
+
+	var files []copyPreview
+	for src, dst := range sources {
+		err := filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if path == src && errors.Is(err, fs.ErrNotExist) {
+					return fmt.Errorf("source path does not exist: %s", src)
+				}
+				return err
+			}
+			if entry.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(src, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, copyPreview{src: path, dst: filepath.Join(dst, rel)})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].dst < files[j].dst })
+	return files, nil
+}
+
+// refCopyPreviews lists the blobs copyFromRef would write for srcPath in ref.
+func (e *Executor) refCopyPreviews(ref, srcPath, dstPath string) ([]copyPreview, error) {
+	pathspec := filepath.ToSlash(filepath.Clean(srcPath))
+	output, err := e.gitOutput("ls-tree", "-r", "-z", ref, "--", pathspec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read '%s' from ref '%s': %w", srcPath, ref, err)
+	}
+
+	prefix := pathspec + "/"
+	if pathspec == "." {
+		prefix = ""
+	}
+	var files []copyPreview
+	for _, entry := range parseLsTree(output) {
+		target := dstPath
+		if entry.path != pathspec {
+			target = filepath.Join(dstPath, filepath.FromSlash(strings.TrimPrefix(entry.path, prefix)))
+		}
+		files = append(files, copyPreview{src: ref + ":" + entry.path, object: entry.object, dst: target})
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("source path does not exist in ref '%s': %s", ref, srcPath)
+	}
+	return files, nil
+}
+
+// globCopySources maps every path matching pattern to its destination under dstDir, as
+// copyGlob does.
+func globCopySources(pattern, dstDir string) (map[string]string, error) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob pattern %s: %w", pattern, err)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no files match %s", pattern)
+	}
+	base := config.GlobBase(pattern)
+	sources := make(map[string]string, len(matches))
+	for _, match := range matches {
+		rel, err := filepath.Rel(base, match)
+		if err != nil {
+			return nil, err
+		}
+		sources[match] = filepath.Join(dstDir, rel)
+	}
+	return sources, nil
+}
+
+// fileDiff renders the change of the file at name from before to after as a unified diff.
+// A nil before is a new file. Binary and large files are only named.
+func fileDiff(name string, before, after []byte) string {
+	from := "a/" + filepath.ToSlash(name)
+	if before == nil {
+		from = "/dev/null"
+	}
+	to := "b/" + filepath.ToSlash(name)
+
+	if isBinaryContent(before) || isBinaryContent(after) {
+		return fmt.Sprintf("Binary files %s and %s differ\n", from, to)
+	}
+	if len(before) > maxPreviewBytes || len(after) > maxPreviewBytes {
+		return fmt.Sprintf("--- %s\n+++ %s\n(%d bytes, too large to show)\n", from, to, len(after))
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        diffLines(before),
+		B:        diffLines(after),
+		FromFile: from,
+		ToFile:   to,
+		Context:  diffContextLines,
+	})
+	if err != nil {
+		return fmt.Sprintf("--- %s\n+++ %s\n", from, to)
+	}
+	return diff
+}
+
+// diffLines splits data into the newline-terminated lines difflib expects.
+func diffLines(data []byte) []string {
+	lines := strings.SplitAfter(string(data), "\n")
+	last := len(lines) - 1
+	if lines[last] == "" {
+		return lines[:last]
+	}
+	lines[last] += "\n"
+	return lines
+}
+
+func isBinaryContent(data []byte) bool {
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+func relativeToWorktree(worktreePath, path string) string {
+	rel, err := filepath.Rel(worktreePath, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
@@ -0,0 +1,117 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func TestFileDiff(t *testing.T) {
+	assert.Equal(t, "--- /dev/null\n+++ b/.env\n@@ -0,0 +1,2 @@\n+A=1\n+B=2\n",
+		fileDiff(".env", nil, []byte("A=1\nB=2\n")))
+	assert.Equal(t, "--- a/conf/app.yml\n+++ b/conf/app.yml\n@@ -1,2 +1,2 @@\n port: 1\n-host: a\n+host: b\n",
+		fileDiff(filepath.Join("conf", "app.yml"), []byte("port: 1\nhost: a\n"), []byte("port: 1\nhost: b\n")))
+	assert.Equal(t, "--- a/x\n+++ b/x\n@@ -1 +1 @@\n-a\n+b\n", fileDiff("x", []byte("a"), []byte("b")),
+		"a missing trailing newline does not break the diff")
+	assert.Equal(t, "Binary files a/logo.png and b/logo.png differ\n",
+		fileDiff("logo.png", []byte("\x89PNG\x00"), []byte("\x89PNG\x00\x01")))
+	assert.Contains(t, fileDiff("big", nil, make([]byte, maxPreviewBytes+1)), "Binary files")
+	assert.Contains(t, fileDiff("big", nil, []byte(strings.Repeat("x\n", maxPreviewBytes))), "too large to show")
+}
+
+func TestPreviewHook(t *testing.T) {
+	repoRoot := t.TempDir()
+	worktreeDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(repoRoot, "conf", "nested"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "conf", "a.yml"), []byte("a: 1\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "conf", "nested", "b.yml"), []byte("b: 2\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(worktreeDir, "conf"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(worktreeDir, "conf", "a.yml"), []byte("a: 1\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(worktreeDir, "package.json"),
+		[]byte("{\n  \"name\": \"app\"\n}\n"), 0o644))
+	executor := NewExecutor(&config.Config{}, repoRoot)
+
+	tests := []struct {
+		name     string
+		hook     config.Hook
+		expected string
+	}{
+		{
+			name:     "copy of a directory shows only changed files",
+			hook:     config.Hook{Type: config.HookTypeCopy, From: "conf", To: "conf"},
+			expected: "--- /dev/null\n+++ b/conf/nested/b.yml\n@@ -0,0 +1 @@\n+b: 2\n",
+		},
+		{
+			name:     "copy that changes nothing",
+			hook:     config.Hook{Type: config.HookTypeCopy, From: "conf/a.yml", To: "conf/a.yml"},
+			expected: "",
+		},
+		{
+			name:     "copy of a glob",
+			hook:     config.Hook{Type: config.HookTypeCopy, From: "conf/*.yml", To: "settings"},
+			expected: "--- /dev/null\n+++ b/settings/a.yml\n@@ -0,0 +1 @@\n+a: 1\n",
+		},
+		{
+			name: "patch",
+			hook: config.Hook{Type: config.HookTypePatch, File: "package.json", Set: map[string]interface{}{
+				".name": "app-feature",
+			}},
+			expected: "--- a/package.json\n+++ b/package.json\n@@ -1,3 +1,3 @@\n {\n-  \"name\": \"app\"\n" +
+				"+  \"name\": \"app-feature\"\n }\n",
+		},
+		{
+			name:     "ensure_line",
+			hook:     config.Hook{Type: config.HookTypeEnsureLine, File: ".gitignore", Line: ".env"},
+			expected: "--- /dev/null\n+++ b/.gitignore\n@@ -0,0 +1 @@\n+.env\n",
+		},
+		{
+			name:     "other hook types",
+			hook:     config.Hook{Type: config.HookTypeCommand, Command: "echo hi > out.txt"},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := executor.previewHook(&tt.hook, worktreeDir)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, diff)
+		})
+	}
+
+	_, err := executor.previewHook(&config.Hook{Type: config.HookTypeCopy, From: "missing", To: "x"}, worktreeDir)
+	assert.ErrorContains(t, err, "source path does not exist")
+	assert.NoFileExists(t, filepath.Join(worktreeDir, ".gitignore"), "previews write nothing")
+	assert.NoDirExists(t, filepath.Join(worktreeDir, "settings"))
+}
+
+func TestPreviewHook_CopyFromRef(t *testing.T) {
+	repoRoot := setupCopySourceRepo(t)
+	hook := config.Hook{Type: config.HookTypeCopy, From: "scripts", To: "bin", FromRef: "main"}
+
+	diff, err := NewExecutor(&config.Config{}, repoRoot).previewHook(&hook, t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, "--- /dev/null\n+++ b/bin/nested/a.txt\n@@ -0,0 +1 @@\n+a\n"+
+		"--- /dev/null\n+++ b/bin/run.sh\n@@ -0,0 +1 @@\n+#!/bin/sh\n", diff)
+}
+
+func TestPreviewHook_LimitsFiles(t *testing.T) {
+	repoRoot := t.TempDir()
+	for i := 0; i < maxPreviewFiles+3; i++ {
+		name := filepath.Join(repoRoot, "fixtures", string(rune('a'+i))+".txt")
+		require.NoError(t, os.MkdirAll(filepath.Dir(name), 0o755))
+		require.NoError(t, os.WriteFile(name, []byte("x\n"), 0o644))
+	}
+	hook := config.Hook{Type: config.HookTypeCopy, From: "fixtures", To: "fixtures"}
+
+	diff, err := NewExecutor(&config.Config{}, repoRoot).previewHook(&hook, t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, maxPreviewFiles, strings.Count(diff, "+++ "))
+	assert.True(t, strings.HasSuffix(diff, "... and 3 more changed file(s)\n"))
+}
@@ -0,0 +1,23 @@
+//go:build windows
+
+package hooks
+
+import (
+	"os/exec"
+	"strconv"
+)
+
+// startInProcessGroup is a no-op on Windows; killProcessGroup uses taskkill /T instead.
+func startInProcessGroup(*exec.Cmd) {}
+
+// killProcessGroup kills cmd and its child processes.
+func killProcessGroup(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	// #nosec G204 -- the pid comes from a process started by wtp
+	if err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run(); err != nil {
+		return cmd.Process.Kill()
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package hooks
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/runtimedir"
+)
+
+const (
+	promptAttempts  = 3
+	promptStoreFile = "wtp-prompts.env"
+	secretFileMode  = 0o600
+)
+
+// Variables to allow mocking in tests
+var (
+	promptReader     = bufio.NewReader(os.Stdin)
+	promptIsTerminal = func() bool { return term.IsTerminal(int(os.Stdin.Fd())) }
+	readSecret       = func() (string, error) {
+		value, err := term.ReadPassword(int(os.Stdin.Fd()))
+		return string(value), err
+	}
+)
+
+// executePromptHookWithWriter asks for the value of hook.Register once per worktree. The
+// answer is saved as NAME=value in hook.File, or in the worktree's runtime directory (see
+// runtimedir) when no file is given, and reused on later runs. A non-empty environment variable of the same
+// name is taken instead of asking, which keeps non-interactive runs working.
+func (e *Executor) executePromptHookWithWriter(w io.Writer, hook *config.Hook, worktreePath string) error {
+	store, err := e.promptStorePath(hook, worktreePath)
+	if err != nil {
+		return err
+	}
+
+	// #nosec G304 -- store is inside the worktree or its runtime directory
+	data, err := os.ReadFile(store)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read saved answers: %w", err)
+	}
+	if value, ok := envFileValue(string(data), hook.Register); ok {
+		e.registered.set(hook.Register, value)
+		_, err := fmt.Fprintf(w, "  Using saved value for %s\n", hook.Register)
+		return err
+	}
+
+	value := os.Getenv(hook.Register)
+	if value == "" {
+		if value, err = askPrompt(w, hook); err != nil {
+			return err
+		}
+	}
+
+	pattern := regexp.MustCompile(`^` + regexp.QuoteMeta(hook.Register) + `=`)
+	updated := ensureMatchedLine(string(data), pattern, hook.Register+"="+value)
+	if err := writeSecretFile(store, []byte(updated)); err != nil {
+		return err
+	}
+	e.registered.set(hook.Register, value)
+	_, err = fmt.Fprintf(w, "  Saved %s\n", hook.Register)
+	return err
+}
+
+// promptStorePath returns the env file a prompt hook keeps its answer in.
+func (e *Executor) promptStorePath(hook *config.Hook, worktreePath string) (string, error) {
+	if hook.File == "" {
+		if _, err := runtimedir.Ensure(e.config, worktreePath); err != nil {
+			return "", err
+		}
+		return runtimedir.Path(e.config, worktreePath, promptStoreFile)
+	}
+
+	if filepath.IsAbs(hook.File) {
+		return hook.File, nil
+	}
+	target := filepath.Join(worktreePath, hook.File)
+	if err := ensureWithinBase(worktreePath, target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// askPrompt reads an answer from the terminal, retrying when it does not match hook.Match.
+func askPrompt(w io.Writer, hook *config.Hook) (string, error) {
+	if !promptIsTerminal() {
+		if hook.Default != "" {
+			return hook.Default, nil
+		}
+		return "", fmt.Errorf("prompt for %s needs an interactive terminal; set %s in the environment instead",
+			hook.Register, hook.Register)
+	}
+
+	message := promptLabel(hook)
+	for range promptAttempts {
+		if _, err := fmt.Fprintf(w, "  %s: ", message); err != nil {
+			return "", err
+		}
+		value, err := readPromptAnswer(w, hook.Secret)
+		if err != nil {
+			return "", err
+		}
+		if value == "" {
+			value = hook.Default
+		}
+		reason := invalidAnswerReason(hook, value)
+		if reason == "" {
+			return value, nil
+		}
+		if _, err := fmt.Fprintf(w, "  Invalid answer: %s\n", reason); err != nil {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("no valid answer for %s after %d attempts", hook.Register, promptAttempts)
+}
+
+// promptLabel is the question shown for hook, with the default unless the answer is secret.
+func promptLabel(hook *config.Hook) string {
+	message := hook.Message
+	if message == "" {
+		message = hook.Register
+	}
+	if hook.Default != "" && !hook.Secret {
+		message += " [" + hook.Default + "]"
+	}
+	return message
+}
+
+// invalidAnswerReason explains why value is not an acceptable answer, or returns "".
+func invalidAnswerReason(hook *config.Hook, value string) string {
+	if value == "" {
+		return "a value is required"
+	}
+	// 'match' was validated when the configuration was loaded
+	if hook.Match != "" && !regexp.MustCompile(hook.Match).MatchString(value) {
+		return fmt.Sprintf("value must match %s", hook.Match)
+	}
+	return ""
+}
+
+func readPromptAnswer(w io.Writer, secret bool) (string, error) {
+	if secret {
+		value, err := readSecret()
+		if err != nil {
+			return "", fmt.Errorf("failed to read answer: %w", err)
+		}
+		// The terminal does not echo the newline of a hidden answer
+		if _, err := fmt.Fprintln(w); err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(value), nil
+	}
+
+	line, err := promptReader.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		return "", fmt.Errorf("failed to read answer: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// envFileValue returns the value of the last NAME=value line for name in content.
+func envFileValue(content, name string) (string, bool) {
+	lines := splitLines(content)
+	for i := len(lines) - 1; i >= 0; i-- {
+		if value, ok := strings.CutPrefix(lines[i], name+"="); ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// writeSecretFile writes data to target, creating it readable only by the user when it is new.
+func writeSecretFile(target string, data []byte) error {
+	if _, err := os.Stat(target); err == nil {
+		return writePatchedFile(target, data)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), directoryPermissions); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(target, data, secretFileMode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", target, err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package hooks
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/runtimedir"
+)
+
+func mockPrompt(t *testing.T, input string, terminal bool) {
+	t.Helper()
+	originalReader, originalIsTerminal := promptReader, promptIsTerminal
+	promptReader = bufio.NewReader(strings.NewReader(input))
+	promptIsTerminal = func() bool { return terminal }
+	t.Cleanup(func() {
+		promptReader, promptIsTerminal = originalReader, originalIsTerminal
+	})
+}
+
+func TestExecutePromptHook_SavesAnswerToFile(t *testing.T) {
+	mockPrompt(t, "nope\nsk-123\n", true)
+	worktreeDir := t.TempDir()
+	hook := &config.Hook{
+		Type:     config.HookTypePrompt,
+		Register: "API_KEY",
+		Message:  "API key",
+		Match:    "^sk-",
+		File:     ".env.local",
+	}
+	executor := NewExecutor(&config.Config{}, t.TempDir())
+
+	var buf bytes.Buffer
+	require.NoError(t, executor.executePromptHookWithWriter(&buf, hook, worktreeDir))
+	assert.Contains(t, buf.String(), "Invalid answer: value must match ^sk-")
+
+	content, err := os.ReadFile(filepath.Join(worktreeDir, ".env.local"))
+	require.NoError(t, err)
+	assert.Equal(t, "API_KEY=sk-123\n", string(content))
+	value, ok := executor.registered.get("API_KEY")
+	assert.True(t, ok)
+	assert.Equal(t, "sk-123", value)
+
+	// A second run reuses the saved answer without asking
+	mockPrompt(t, "", true)
+	buf.Reset()
+	executor = NewExecutor(&config.Config{}, t.TempDir())
+	require.NoError(t, executor.executePromptHookWithWriter(&buf, hook, worktreeDir))
+	assert.Contains(t, buf.String(), "Using saved value for API_KEY")
+}
+
+func TestExecutePromptHook_DefaultAndEnvironment(t *testing.T) {
+	worktreeDir := t.TempDir()
+	hook := &config.Hook{Type: config.HookTypePrompt, Register: "FEATURE_FLAGS", Default: "beta", File: "flags.env"}
+
+	executor := NewExecutor(&config.Config{}, t.TempDir())
+
+	mockPrompt(t, "\n", true)
+	require.NoError(t, executor.executePromptHookWithWriter(&bytes.Buffer{}, hook, worktreeDir))
+	content, err := os.ReadFile(filepath.Join(worktreeDir, "flags.env"))
+	require.NoError(t, err)
+	assert.Equal(t, "FEATURE_FLAGS=beta\n", string(content))
+
+	hook.Register = "REGION"
+	hook.Default = ""
+	t.Setenv("REGION", "eu-west-1")
+	mockPrompt(t, "", false)
+	require.NoError(t, executor.executePromptHookWithWriter(&bytes.Buffer{}, hook, worktreeDir))
+	content, err = os.ReadFile(filepath.Join(worktreeDir, "flags.env"))
+	require.NoError(t, err)
+	assert.Equal(t, "FEATURE_FLAGS=beta\nREGION=eu-west-1\n", string(content))
+}
+
+func TestExecutePromptHook_NonInteractiveWithoutDefault(t *testing.T) {
+	mockPrompt(t, "", false)
+	hook := &config.Hook{Type: config.HookTypePrompt, Register: "WTP_TEST_UNSET_ANSWER", File: "answers.env"}
+
+	err := NewExecutor(&config.Config{}, t.TempDir()).executePromptHookWithWriter(&bytes.Buffer{}, hook, t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "needs an interactive terminal")
+}
+
+func TestExecutePromptHook_StoresInRuntimeDirByDefault(t *testing.T) {
+	mockPrompt(t, "secret-value\n", true)
+	repoRoot := setupCopySourceRepo(t)
+	hook := &config.Hook{Type: config.HookTypePrompt, Register: "TOKEN"}
+
+	executor := NewExecutor(&config.Config{}, repoRoot)
+	require.NoError(t, executor.executePromptHookWithWriter(&bytes.Buffer{}, hook, repoRoot))
+
+	store := filepath.Join(repoRoot, runtimedir.DirName, promptStoreFile)
+	content, err := os.ReadFile(store)
+	require.NoError(t, err)
+	assert.Equal(t, "TOKEN=secret-value\n", string(content))
+	if runtime.GOOS != windowsOS {
+		info, err := os.Stat(store)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(secretFileMode), info.Mode().Perm())
+	}
+}
@@ -0,0 +1,129 @@
+package hooks
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/satococoa/wtp/v2/internal/filelock"
+)
+
+const (
+	provisionDirName = "provision"
+	queueDirName     = "queue"
+	ticketExt        = ".ticket"
+	// staleTicketAge is how old an unlocked queue ticket must be before it is removed; a
+	// waiter creates its ticket and locks it right away, so only dead waiters leave one.
+	staleTicketAge = time.Minute
+)
+
+// Variables to allow mocking in tests
+var (
+	provisionQueueDir     = defaultProvisionQueueDir
+	provisionPollInterval = 500 * time.Millisecond
+)
+
+// defaultProvisionQueueDir returns the per-user directory holding the provisioning slots,
+// shared by every repository on the machine.
+func defaultProvisionQueueDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "wtp", provisionDirName), nil
+}
+
+// acquireProvisionSlot waits until fewer than defaults.max_concurrent_provisions wtp processes
+// run post_create hooks and returns a function that frees the slot it took. Each slot is a
+// locked file, so the slots of a process that dies are freed with it. Waiting processes take
+// a queue ticket, are served in arrival order, and report their position to w.
+func (e *Executor) acquireProvisionSlot(w io.Writer) (release func() error, err error) {
+	limit := 0
+	if e.config != nil {
+		limit = e.config.Defaults.MaxConcurrentProvisions
+	}
+	if limit <= 0 {
+		return func() error { return nil }, nil
+	}
+	dir, err := provisionQueueDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate the provisioning queue: %w", err)
+	}
+
+	queueDir := filepath.Join(dir, queueDirName)
+	ticketName := fmt.Sprintf("%020d-%d%s", time.Now().UnixNano(), os.Getpid(), ticketExt)
+	ticket := filepath.Join(queueDir, ticketName)
+	leave, err := filelock.Lock(ticket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to join the provisioning queue: %w", err)
+	}
+	defer func() {
+		_ = leave()
+		_ = os.Remove(ticket)
+	}()
+
+	reported := -1
+	for {
+		ahead, err := ticketsAhead(queueDir, ticketName)
+		if err != nil {
+			return nil, err
+		}
+		if ahead == 0 {
+			release, ok, err := tryProvisionSlot(dir, limit)
+			if err != nil || ok {
+				return release, err
+			}
+		}
+		if ahead != reported {
+			if _, err := fmt.Fprintf(w, "  Waiting for a provisioning slot (position %d in queue, limit %d)\n",
+				ahead+1, limit); err != nil {
+				return nil, err
+			}
+			reported = ahead
+		}
+		time.Sleep(provisionPollInterval)
+	}
+}
+
+// tryProvisionSlot takes the first free one of limit slots without waiting.
+func tryProvisionSlot(dir string, limit int) (release func() error, ok bool, err error) {
+	for i := 0; i < limit; i++ {
+		release, ok, err := filelock.TryLock(filepath.Join(dir, fmt.Sprintf("slot-%d.lock", i)))
+		if err != nil || ok {
+			return release, ok, err
+		}
+	}
+	return nil, false, nil
+}
+
+// ticketsAhead counts the live queue tickets that sort before ticketName. Tickets are named
+// by the time they were taken, and a ticket is live while its waiter holds its lock.
+func ticketsAhead(queueDir, ticketName string) (int, error) {
+	entries, err := os.ReadDir(queueDir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the provisioning queue: %w", err)
+	}
+	ahead := 0
+	for _, entry := range entries {
+		name := entry.Name()
+		if name >= ticketName || !strings.HasSuffix(name, ticketExt) {
+			continue
+		}
+		path := filepath.Join(queueDir, name)
+		held, err := filelock.Held(path)
+		if err != nil {
+			continue
+		}
+		if held {
+			ahead++
+			continue
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleTicketAge {
+			_ = os.Remove(path)
+		}
+	}
+	return ahead, nil
+}
@@ -0,0 +1,109 @@
+package hooks
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/filelock"
+)
+
+func withProvisionQueueDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	originalDir, originalInterval := provisionQueueDir, provisionPollInterval
+	provisionQueueDir = func() (string, error) { return dir, nil }
+	provisionPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() {
+		provisionQueueDir, provisionPollInterval = originalDir, originalInterval
+	})
+	return dir
+}
+
+// lockedBuffer is a bytes.Buffer that can be read while another goroutine writes to it.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestAcquireProvisionSlot_Unlimited(t *testing.T) {
+	dir := withProvisionQueueDir(t)
+
+	release, err := NewExecutor(&config.Config{}, t.TempDir()).acquireProvisionSlot(&bytes.Buffer{})
+	require.NoError(t, err)
+	require.NoError(t, release())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "no slots are taken without a limit")
+}
+
+func TestAcquireProvisionSlot_WaitsInQueue(t *testing.T) {
+	dir := withProvisionQueueDir(t)
+	cfg := &config.Config{Defaults: config.Defaults{MaxConcurrentProvisions: 1}}
+	executor := NewExecutor(cfg, t.TempDir())
+
+	// Another process is provisioning, and one more waits ahead of us
+	busy, ok, err := filelock.TryLock(filepath.Join(dir, "slot-0.lock"))
+	require.NoError(t, err)
+	require.True(t, ok)
+	earlierTicket := filepath.Join(dir, queueDirName, "00000000000000000001-1"+ticketExt)
+	earlier, err := filelock.Lock(earlierTicket)
+	require.NoError(t, err)
+
+	var output lockedBuffer
+	acquired := make(chan func() error)
+	go func() {
+		release, err := executor.acquireProvisionSlot(&output)
+		assert.NoError(t, err)
+		acquired <- release
+	}()
+
+	assert.Eventually(t, func() bool {
+		return strings.Contains(output.String(), "position 2 in queue, limit 1")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, earlier())
+	require.NoError(t, os.Remove(earlierTicket))
+	assert.Eventually(t, func() bool {
+		return strings.Contains(output.String(), "position 1 in queue, limit 1")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	select {
+	case <-acquired:
+		t.Fatal("the slot must not be taken while it is busy")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	require.NoError(t, busy())
+	select {
+	case release := <-acquired:
+		require.NoError(t, release())
+	case <-time.After(5 * time.Second):
+		t.Fatal("the slot was not taken after it was freed")
+	}
+
+	tickets, err := os.ReadDir(filepath.Join(dir, queueDirName))
+	require.NoError(t, err)
+	assert.Empty(t, tickets, "the waiter removes its ticket")
+}
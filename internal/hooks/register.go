@@ -0,0 +1,57 @@
+package hooks
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// registeredVars holds the values command hooks captured with 'register'. They are
+// shared by every hook of an Executor, including hooks running in a parallel group.
+type registeredVars struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func newRegisteredVars() *registeredVars {
+	return &registeredVars{values: make(map[string]string)}
+}
+
+func (r *registeredVars) get(name string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	value, ok := r.values[name]
+	return value, ok
+}
+
+func (r *registeredVars) set(name, value string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[name] = value
+}
+
+// env returns the registered values as NAME=value entries for command hooks.
+func (r *registeredVars) env() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, 0, len(r.values))
+	for name := range r.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	env := make([]string, 0, len(names))
+	for _, name := range names {
+		env = append(env, fmt.Sprintf("%s=%s", name, r.values[name]))
+	}
+	return env
+}
+
+// expand replaces ${NAME} references to registered variables, leaving others untouched.
+func (r *registeredVars) expand(s string) string {
+	return hookReferencePattern.ReplaceAllStringFunc(s, func(ref string) string {
+		if value, ok := r.get(hookReferencePattern.FindStringSubmatch(ref)[1]); ok {
+			return value
+		}
+		return ref
+	})
+}
@@ -0,0 +1,42 @@
+package hooks
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+// Variables to allow mocking in tests
+var retrySleep = time.Sleep
+
+// executeWithRetry runs a hook with run, running it again after a failure as long as the
+// hook's retry policy allows another attempt and the failure is one the policy retries.
+func (e *Executor) executeWithRetry(w io.Writer, hook *config.Hook, run func() error) error {
+	retry := hook.RetryPolicy()
+	if retry == nil || retry.Attempts <= 1 {
+		return run()
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := run()
+		if err == nil {
+			return nil
+		}
+		code, hasCode := ExitCode(err)
+		if attempt >= retry.Attempts || !retry.RetriesFailure(code, hasCode) || e.ctx.Err() != nil {
+			if attempt > 1 {
+				return fmt.Errorf("%w (after %d attempts)", err, attempt)
+			}
+			return err
+		}
+
+		delay := retry.DelayBefore(attempt)
+		if _, werr := fmt.Fprintf(w, "  Attempt %d/%d failed: %v; retrying in %s\n",
+			attempt, retry.Attempts, err, delay); werr != nil {
+			return werr
+		}
+		retrySleep(delay)
+	}
+}
@@ -0,0 +1,106 @@
+package hooks
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func recordRetrySleeps(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var sleeps []time.Duration
+	original := retrySleep
+	retrySleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+	t.Cleanup(func() { retrySleep = original })
+	return &sleeps
+}
+
+func TestExecuteWithRetry(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping command test on Windows")
+	}
+
+	// Fails with exit status 7 until it has run three times
+	flaky := "n=$(cat count 2>/dev/null || echo 0); n=$((n+1)); echo $n > count; [ $n -ge 3 ] || exit 7"
+	executor := NewExecutor(&config.Config{}, t.TempDir())
+
+	t.Run("retries until the command succeeds", func(t *testing.T) {
+		sleeps := recordRetrySleeps(t)
+		hook := &config.Hook{Type: config.HookTypeCommand, Command: flaky, Retry: &config.Retry{
+			Attempts: 5, Delay: "1s", MaxDelay: "1500ms", OnExitCodes: []int{1, 7},
+		}}
+
+		var buf bytes.Buffer
+		require.NoError(t, executor.executeHookWithWriter(&buf, hook, t.TempDir()))
+		assert.Contains(t, buf.String(), "Attempt 1/5 failed: command failed: exit status 7; retrying in 1s")
+		assert.Contains(t, buf.String(), "Attempt 2/5 failed")
+		assert.Equal(t, []time.Duration{time.Second, 1500 * time.Millisecond}, *sleeps)
+	})
+
+	t.Run("gives up after the last attempt", func(t *testing.T) {
+		sleeps := recordRetrySleeps(t)
+		hook := &config.Hook{Type: config.HookTypeCommand, Command: flaky, Retry: &config.Retry{Attempts: 2}}
+
+		err := executor.executeHookWithWriter(&bytes.Buffer{}, hook, t.TempDir())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exit status 7 (after 2 attempts)")
+		code, ok := ExitCode(err)
+		assert.True(t, ok)
+		assert.Equal(t, 7, code)
+		assert.Len(t, *sleeps, 1)
+	})
+
+	t.Run("does not retry other exit codes", func(t *testing.T) {
+		sleeps := recordRetrySleeps(t)
+		hook := &config.Hook{Type: config.HookTypeCommand, Command: "exit 2", Retry: &config.Retry{
+			Attempts: 5, OnExitCodes: []int{1, 7},
+		}}
+
+		err := executor.executeHookWithWriter(&bytes.Buffer{}, hook, t.TempDir())
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "attempts")
+		assert.Empty(t, *sleeps)
+	})
+
+	t.Run("retries shorthand waits retry_delay every time", func(t *testing.T) {
+		sleeps := recordRetrySleeps(t)
+		hook := &config.Hook{Type: config.HookTypeCommand, Command: flaky, Retries: 2, RetryDelay: "3s"}
+
+		require.NoError(t, executor.executeHookWithWriter(&bytes.Buffer{}, hook, t.TempDir()))
+		assert.Equal(t, []time.Duration{3 * time.Second, 3 * time.Second}, *sleeps)
+	})
+}
+
+func TestExecuteWithRetry_Download(t *testing.T) {
+	sleeps := recordRetrySleeps(t)
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&requests, 1) < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("payload"))
+	}))
+	t.Cleanup(server.Close)
+
+	worktree := t.TempDir()
+	hook := &config.Hook{Type: config.HookTypeDownload, URL: server.URL, To: "data.txt", Retries: 1}
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(&config.Config{}, t.TempDir()).executeHookWithWriter(&buf, hook, worktree))
+	assert.Contains(t, buf.String(), "Attempt 1/2 failed")
+	assert.Len(t, *sleeps, 1)
+	content, err := os.ReadFile(filepath.Join(worktree, "data.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "payload", string(content))
+}
@@ -0,0 +1,40 @@
+package hooks
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+// posixScriptPrelude makes sh and bash stop at the first failing command of a script,
+// as powerShellPrelude does for PowerShell.
+const posixScriptPrelude = "set -e\n"
+
+// executeScriptHookWithWriter runs a script hook's 'run' block with its shell. The hook
+// fails at the first failing command of the script, as a list of command hooks would.
+func (e *Executor) executeScriptHookWithWriter(w io.Writer, hook *config.Hook, worktreePath string) error {
+	var shell string
+	if e.config != nil {
+		shell = e.config.CommandShell(hook)
+	}
+	shell = resolveShell(shell, runtime.GOOS, exec.LookPath)
+	if shell == config.ShellCmd {
+		return fmt.Errorf("script hooks cannot run with cmd; set the hook's 'shell' to %s or %s",
+			config.ShellPwsh, config.ShellPowerShell)
+	}
+
+	lines := strings.Count(strings.TrimRight(hook.Run, "\n"), "\n") + 1
+	if _, err := fmt.Fprintf(w, "  Running script with %s (%d lines)\n", shell, lines); err != nil {
+		return err
+	}
+
+	script := hook.Run
+	if shell == config.ShellSh || shell == config.ShellBash {
+		script = posixScriptPrelude + script
+	}
+	return e.runShell(w, hook, worktreePath, shell, script, "script")
+}
@@ -0,0 +1,69 @@
+package hooks
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func TestExecutePostCreateHooks_Script(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping sh test on Windows")
+	}
+	worktreeDir := t.TempDir()
+	cfg := &config.Config{Hooks: config.Hooks{PostCreate: []config.Hook{
+		{
+			Type: config.HookTypeScript,
+			Run: "if [ -n \"$GREETING\" ]; then\n  echo \"$GREETING\" > out.txt\nfi\n" +
+				"echo \"dir=${WORKTREE_DIR}\" >> out.txt\necho done\n",
+			Env: map[string]string{"GREETING": "hello"},
+		},
+	}}}
+
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(cfg, t.TempDir()).ExecutePostCreateHooks(&buf, worktreeDir))
+	assert.Contains(t, buf.String(), "Running script with sh (5 lines)")
+	assert.Contains(t, buf.String(), "done\n")
+	content, err := os.ReadFile(filepath.Join(worktreeDir, "out.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello\ndir="+filepath.Base(worktreeDir)+"\n", string(content))
+}
+
+func TestExecutePostCreateHooks_ScriptStopsAtFailure(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping bash test on Windows")
+	}
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not installed")
+	}
+	worktreeDir := t.TempDir()
+	cfg := &config.Config{Hooks: config.Hooks{PostCreate: []config.Hook{
+		{Type: config.HookTypeScript, Shell: config.ShellBash, Run: "echo before\nfalse\ntouch after\n"},
+	}}}
+
+	var buf bytes.Buffer
+	err := NewExecutor(cfg, t.TempDir()).ExecutePostCreateHooks(&buf, worktreeDir)
+	require.Error(t, err)
+	assert.Contains(t, buf.String(), "Running script with bash (3 lines)")
+	assert.Contains(t, buf.String(), "before")
+	assert.NoFileExists(t, filepath.Join(worktreeDir, "after"), "the script stops at the failing line")
+}
+
+func TestExecutePostCreateHooks_ScriptRefusesCmd(t *testing.T) {
+	cfg := &config.Config{
+		Defaults: config.Defaults{Shell: config.ShellCmd},
+		Hooks:    config.Hooks{PostCreate: []config.Hook{{Type: config.HookTypeScript, Run: "echo hi"}}},
+	}
+
+	var buf bytes.Buffer
+	err := NewExecutor(cfg, t.TempDir()).ExecutePostCreateHooks(&buf, t.TempDir())
+	assert.ErrorContains(t, err, "script hooks cannot run with cmd")
+}
@@ -0,0 +1,113 @@
+package hooks
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+// powerShellPrelude makes PowerShell stop at the first failing cmdlet, like a failing
+// command fails the hook; powerShellEpilogue passes on the exit status of the last
+// program run.
+const (
+	powerShellPrelude  = "$ErrorActionPreference = 'Stop'\n"
+	powerShellEpilogue = "\nexit $LASTEXITCODE"
+)
+
+// shellVarPattern matches the POSIX shell references $NAME and ${NAME}.
+var shellVarPattern = regexp.MustCompile(`\$(?:\{([A-Za-z_][A-Za-z0-9_]*)\}|([A-Za-z_][A-Za-z0-9_]*))`)
+
+// shellCommand returns the process that runs commandLine, a command hook's command, with
+// shell; empty picks the platform's default. wtpVars names the variables wtp sets for the
+// hook: PowerShell and cmd get their $NAME and ${NAME} references rewritten to their own
+// syntax, so that the same command works with every shell.
+func shellCommand(ctx context.Context, shell, commandLine string, wtpVars []string) *exec.Cmd {
+	name, args := shellArgs(resolveShell(shell, runtime.GOOS, exec.LookPath), commandLine, wtpVars)
+	// #nosec G204 - Commands come from project configuration file controlled by developer
+	cmd := exec.CommandContext(ctx, name, args...)
+	if name == config.ShellCmd {
+		setCmdLine(cmd, args)
+	}
+	return cmd
+}
+
+// resolveShell returns shell, or the default one for goos when it is empty: sh, or on
+// Windows PowerShell 7 when it is installed and Windows PowerShell otherwise.
+func resolveShell(shell, goos string, lookPath func(string) (string, error)) string {
+	if shell != "" {
+		return shell
+	}
+	if goos != windowsOS {
+		return config.ShellSh
+	}
+	if _, err := lookPath(config.ShellPwsh); err == nil {
+		return config.ShellPwsh
+	}
+	return config.ShellPowerShell
+}
+
+// shellArgs returns the program and arguments that run commandLine with shell. A one-line
+// command starting with a .cmd or .bat script runs with cmd even when shell is PowerShell,
+// which would not find a script in the current directory nor pass on cmd's quoting.
+func shellArgs(shell, commandLine string, wtpVars []string) (name string, args []string) {
+	switch shell {
+	case config.ShellPwsh, config.ShellPowerShell:
+		if !isBatchScript(commandLine) {
+			script := translateShellVars(commandLine, wtpVars, func(v string) string { return "${env:" + v + "}" })
+			return shell, []string{"-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass",
+				"-Command", powerShellPrelude + script + powerShellEpilogue}
+		}
+		fallthrough
+	case config.ShellCmd:
+		script := translateShellVars(commandLine, wtpVars, func(v string) string { return "%" + v + "%" })
+		return config.ShellCmd, []string{"/d", "/s", "/c", script}
+	default:
+		return shell, []string{"-c", commandLine}
+	}
+}
+
+// isBatchScript reports whether commandLine is one line whose program is a .cmd or .bat
+// script; cmd would only run the first line of a longer one.
+func isBatchScript(commandLine string) bool {
+	program := strings.TrimSpace(commandLine)
+	if strings.Contains(program, "\n") {
+		return false
+	}
+	if quote := program[:min(len(program), 1)]; quote == `"` || quote == "'" {
+		program, _, _ = strings.Cut(program[1:], quote)
+	} else if i := strings.IndexAny(program, " \t"); i >= 0 {
+		program = program[:i]
+	}
+	ext := strings.ToLower(filepath.Ext(program))
+	return ext == ".cmd" || ext == ".bat"
+}
+
+// translateShellVars rewrites the $NAME and ${NAME} references in commandLine to the
+// variables in wtpVars with format. Other references are left alone: in PowerShell they
+// may be its own variables.
+func translateShellVars(commandLine string, wtpVars []string, format func(string) string) string {
+	return shellVarPattern.ReplaceAllStringFunc(commandLine, func(ref string) string {
+		match := shellVarPattern.FindStringSubmatch(ref)
+		name := match[1] + match[2]
+		if !slices.Contains(wtpVars, name) {
+			return ref
+		}
+		return format(name)
+	})
+}
+
+// envNames returns the names of the NAME=value entries in env.
+func envNames(env []string) []string {
+	names := make([]string, 0, len(env))
+	for _, entry := range env {
+		name, _, _ := strings.Cut(entry, "=")
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,115 @@
+package hooks
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func TestResolveShell(t *testing.T) {
+	found := func(string) (string, error) { return "/usr/bin/pwsh", nil }
+	missing := func(string) (string, error) { return "", errors.New("not found") }
+
+	assert.Equal(t, config.ShellBash, resolveShell(config.ShellBash, windowsOS, found))
+	assert.Equal(t, config.ShellSh, resolveShell("", "linux", found))
+	assert.Equal(t, config.ShellPwsh, resolveShell("", windowsOS, found))
+	assert.Equal(t, config.ShellPowerShell, resolveShell("", windowsOS, missing))
+}
+
+func TestShellArgs(t *testing.T) {
+	vars := []string{"GIT_WTP_WORKTREE_PATH", "WTP_CACHE_DIR"}
+
+	tests := []struct {
+		name      string
+		shell     string
+		command   string
+		wantName  string
+		wantLast  string
+		wantFlags []string
+	}{
+		{
+			name:      "sh keeps the command",
+			shell:     config.ShellSh,
+			command:   "echo $WTP_CACHE_DIR",
+			wantName:  "sh",
+			wantLast:  "echo $WTP_CACHE_DIR",
+			wantFlags: []string{"-c"},
+		},
+		{
+			name:     "PowerShell reads wtp's variables from env:",
+			shell:    config.ShellPwsh,
+			command:  `npm ci --cache "${WTP_CACHE_DIR}/npm"; echo $GIT_WTP_WORKTREE_PATH $HOME $env:PATH`,
+			wantName: "pwsh",
+			wantLast: powerShellPrelude +
+				`npm ci --cache "${env:WTP_CACHE_DIR}/npm"; echo ${env:GIT_WTP_WORKTREE_PATH} $HOME $env:PATH` +
+				powerShellEpilogue,
+			wantFlags: []string{"-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-Command"},
+		},
+		{
+			name:      "cmd uses percent references",
+			shell:     config.ShellCmd,
+			command:   `copy .env.example .env && echo $GIT_WTP_WORKTREE_PATH $OTHER`,
+			wantName:  "cmd",
+			wantLast:  `copy .env.example .env && echo %GIT_WTP_WORKTREE_PATH% $OTHER`,
+			wantFlags: []string{"/d", "/s", "/c"},
+		},
+		{
+			name:      "batch scripts run with cmd under PowerShell",
+			shell:     config.ShellPowerShell,
+			command:   `scripts\setup.CMD $WTP_CACHE_DIR`,
+			wantName:  "cmd",
+			wantLast:  `scripts\setup.CMD %WTP_CACHE_DIR%`,
+			wantFlags: []string{"/d", "/s", "/c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, args := shellArgs(tt.shell, tt.command, vars)
+			assert.Equal(t, tt.wantName, name)
+			require.NotEmpty(t, args)
+			assert.Equal(t, tt.wantFlags, args[:len(args)-1])
+			assert.Equal(t, tt.wantLast, args[len(args)-1])
+		})
+	}
+}
+
+func TestIsBatchScript(t *testing.T) {
+	assert.True(t, isBatchScript("setup.bat"))
+	assert.True(t, isBatchScript(`  scripts\setup.cmd --fast`))
+	assert.True(t, isBatchScript(`"C:\Program Files\tool\run.cmd" arg`))
+	assert.False(t, isBatchScript("npm install setup.bat"))
+	assert.False(t, isBatchScript("./setup.ps1"))
+	assert.False(t, isBatchScript(""))
+	assert.False(t, isBatchScript("setup.cmd\necho done"), "cmd would run only the first line")
+}
+
+func TestExecutePostCreateHooks_CommandShell(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping bash test on Windows")
+	}
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not installed")
+	}
+
+	cfg := &config.Config{
+		Defaults: config.Defaults{Shell: config.ShellBash},
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{Type: config.HookTypeCommand, Command: `[[ -n "$GIT_WTP_WORKTREE_PATH" ]] && echo bash-ok`},
+				{Type: config.HookTypeCommand, Command: "echo sh-ok", Shell: config.ShellSh},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(cfg, t.TempDir()).ExecutePostCreateHooks(&buf, t.TempDir()))
+	assert.Contains(t, buf.String(), "bash-ok")
+	assert.Contains(t, buf.String(), "sh-ok")
+}
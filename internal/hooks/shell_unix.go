@@ -0,0 +1,8 @@
+//go:build !windows
+
+package hooks
+
+import "os/exec"
+
+// setCmdLine is a no-op outside Windows, where programs receive their arguments as given.
+func setCmdLine(*exec.Cmd, []string) {}
@@ -0,0 +1,21 @@
+//go:build windows
+
+package hooks
+
+import (
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// setCmdLine passes cmd.exe its arguments verbatim. Go quotes arguments the way most
+// Windows programs parse them, escaping quotes as \", which cmd does not understand; with
+// /s, cmd strips the outer quotes and runs the rest exactly as written.
+func setCmdLine(cmd *exec.Cmd, args []string) {
+	last := len(args) - 1
+	line := "cmd " + strings.Join(args[:last], " ") + ` "` + args[last] + `"`
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CmdLine = line
+}
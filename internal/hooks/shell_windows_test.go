@@ -0,0 +1,82 @@
+//go:build windows
+
+package hooks
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func TestWindowsCommandHooks(t *testing.T) {
+	worktreeDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(worktreeDir, "setup.cmd"),
+		[]byte("@echo off\r\necho script=%1\r\n"), 0644))
+
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				// The default shell is PowerShell
+				{Type: config.HookTypeCommand, Command: `Write-Output "ps=$GIT_WTP_WORKTREE_PATH"`},
+				{Type: config.HookTypeCommand, Command: `echo cmd="$NAME" & echo done`, Shell: config.ShellCmd,
+					Env: map[string]string{"NAME": "a b"}},
+				{Type: config.HookTypeCommand, Command: "setup.cmd $NAME", Env: map[string]string{"NAME": "x"}},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(cfg, t.TempDir()).ExecutePostCreateHooks(&buf, worktreeDir))
+	assert.Contains(t, buf.String(), "ps="+worktreeDir)
+	assert.Contains(t, buf.String(), `cmd="a b"`)
+	assert.Contains(t, buf.String(), "done")
+	assert.Contains(t, buf.String(), "script=x")
+}
+
+func TestWindowsCommandHookFailure(t *testing.T) {
+	for _, hook := range []config.Hook{
+		{Type: config.HookTypeCommand, Command: "Get-Item does-not-exist"},
+		{Type: config.HookTypeCommand, Command: "cmd /c exit 3"},
+		{Type: config.HookTypeCommand, Command: "exit 3", Shell: config.ShellCmd},
+	} {
+		cfg := &config.Config{Hooks: config.Hooks{PostCreate: []config.Hook{hook}}}
+		var buf bytes.Buffer
+		assert.Error(t, NewExecutor(cfg, t.TempDir()).ExecutePostCreateHooks(&buf, t.TempDir()), hook.Command)
+	}
+}
+
+func TestWindowsCopyAndSymlinkHooks(t *testing.T) {
+	repoRoot := t.TempDir()
+	worktreeDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(repoRoot, "node_modules", "pkg"), directoryPermissions))
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "node_modules", "pkg", "index.js"), []byte("js"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, ".env"), []byte("KEY=1"), 0644))
+
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			PostCreate: []config.Hook{
+				{Type: config.HookTypeCopy, From: ".env", To: `config\.env`},
+				{Type: config.HookTypeSymlink, From: "node_modules", To: "node_modules"},
+				{Type: config.HookTypeSymlink, From: ".env", To: ".env"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, NewExecutor(cfg, repoRoot).ExecutePostCreateHooks(&buf, worktreeDir), buf.String())
+
+	// Symbolic links, or junctions and hard links without the privilege, show the same content
+	for _, path := range []string{`config\.env`, ".env", `node_modules\pkg\index.js`} {
+		_, err := os.Stat(filepath.Join(worktreeDir, path))
+		assert.NoError(t, err, path)
+	}
+	content, err := os.ReadFile(filepath.Join(worktreeDir, `node_modules\pkg\index.js`))
+	require.NoError(t, err)
+	assert.Equal(t, "js", string(content))
+}
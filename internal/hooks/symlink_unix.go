@@ -0,0 +1,10 @@
+//go:build !windows
+
+package hooks
+
+import "os"
+
+// createSymlink makes dst a symbolic link to src.
+func createSymlink(src, dst string, _ bool) error {
+	return os.Symlink(src, dst)
+}
@@ -0,0 +1,36 @@
+//go:build windows
+
+package hooks
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// errorPrivilegeNotHeld is ERROR_PRIVILEGE_NOT_HELD, which Windows returns for symbolic
+// links unless Developer Mode is on or the process runs as administrator.
+const errorPrivilegeNotHeld = syscall.Errno(1314)
+
+// createSymlink makes dst a symbolic link to src. Without the privilege to create one, a
+// directory gets a junction and a file a hard link instead: both need no privilege on
+// NTFS, and tools see the same content through them.
+func createSymlink(src, dst string, isDir bool) error {
+	err := os.Symlink(src, dst)
+	if !errors.Is(err, errorPrivilegeNotHeld) {
+		return err
+	}
+	if !isDir {
+		if linkErr := os.Link(src, dst); linkErr != nil {
+			return fmt.Errorf("%w; hard link fallback: %w", err, linkErr)
+		}
+		return nil
+	}
+	// #nosec G204 -- both paths are resolved by the symlink hook
+	if out, linkErr := exec.Command("cmd", "/d", "/c", "mklink", "/J", dst, src).CombinedOutput(); linkErr != nil {
+		return fmt.Errorf("%w; junction fallback: %w: %s", err, linkErr, out)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package hooks
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+const (
+	verifyDialTimeout = 2 * time.Second
+	// verifyOutputLines is how many trailing output lines of a failed command check are reported.
+	verifyOutputLines = 5
+)
+
+// CheckResult is the outcome of one 'verify' check.
+type CheckResult struct {
+	Name     string
+	Kind     string
+	Target   string // with variables expanded
+	Duration time.Duration
+	Err      error // nil when the check passed
+}
+
+// Verify runs the configured 'verify' checks against the worktree at worktreePath. Every
+// check runs, even after a failure, so the report is complete.
+func (e *Executor) Verify(worktreePath string) []CheckResult {
+	if e.config == nil {
+		return nil
+	}
+	results := make([]CheckResult, 0, len(e.config.Verify))
+	for i := range e.config.Verify {
+		check := &e.config.Verify[i]
+		kind, target := check.Kind()
+		target = e.valueExpander(&config.Hook{}, worktreePath)(target)
+
+		start := time.Now()
+		err := e.runCheck(check, kind, target, worktreePath)
+		name := check.Name
+		if name == "" {
+			name = kind + " " + target
+		}
+		results = append(results, CheckResult{
+			Name: name, Kind: kind, Target: target, Duration: time.Since(start), Err: err,
+		})
+	}
+	return results
+}
+
+func (e *Executor) runCheck(check *config.Check, kind, target, worktreePath string) error {
+	switch kind {
+	case config.CheckKindFile:
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(worktreePath, target)
+		}
+		if _, err := os.Stat(target); err != nil {
+			return fmt.Errorf("%s does not exist", target)
+		}
+		return nil
+	case config.CheckKindCommand:
+		hook := &config.Hook{Type: config.HookTypeCommand, Command: target, Timeout: check.Timeout}
+		var output bytes.Buffer
+		if err := e.executeCommandHookWithWriter(&output, hook, worktreePath); err != nil {
+			return commandCheckError(err, output.String())
+		}
+		return nil
+	case config.CheckKindPortFree, config.CheckKindPortInUse:
+		address := config.CheckAddress(target)
+		conn, err := net.DialTimeout("tcp", address, verifyDialTimeout)
+		inUse := err == nil
+		if inUse {
+			_ = conn.Close()
+		}
+		if kind == config.CheckKindPortFree && inUse {
+			return fmt.Errorf("%s is in use", address)
+		}
+		if kind == config.CheckKindPortInUse && !inUse {
+			return fmt.Errorf("nothing is listening on %s", address)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown check kind: %s", kind)
+	}
+}
+
+// commandCheckError adds the last lines of a failed command's output to err. The first
+// line of output is the "Running:" header written by the command hook runner.
+func commandCheckError(err error, output string) error {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > 0 && strings.HasPrefix(lines[0], "  Running: ") {
+		lines = lines[1:]
+	}
+	if len(lines) > verifyOutputLines {
+		lines = lines[len(lines)-verifyOutputLines:]
+	}
+	if len(lines) == 0 || (len(lines) == 1 && lines[0] == "") {
+		return err
+	}
+	return fmt.Errorf("%w\n%s", err, strings.Join(lines, "\n"))
+}
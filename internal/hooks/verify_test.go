@@ -0,0 +1,76 @@
+package hooks
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func TestVerify_FileChecks(t *testing.T) {
+	worktreeDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(worktreeDir, ".env"), []byte("A=1\n"), 0o644))
+
+	cfg := &config.Config{Verify: []config.Check{
+		{Name: "env file", File: ".env"},
+		{File: "node_modules"},
+	}}
+	results := NewExecutor(cfg, t.TempDir()).Verify(worktreeDir)
+
+	require.Len(t, results, 2)
+	assert.Equal(t, "env file", results[0].Name)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, "file node_modules", results[1].Name)
+	require.Error(t, results[1].Err)
+	assert.Contains(t, results[1].Err.Error(), filepath.Join(worktreeDir, "node_modules")+" does not exist")
+}
+
+func TestVerify_CommandCheckReportsOutput(t *testing.T) {
+	cfg := &config.Config{Verify: []config.Check{
+		{Command: "true"},
+		{Name: "lint", Command: "echo 'lint: 3 problems'; exit 1"},
+	}}
+	results := NewExecutor(cfg, t.TempDir()).Verify(t.TempDir())
+
+	require.Len(t, results, 2)
+	assert.NoError(t, results[0].Err)
+	require.Error(t, results[1].Err)
+	assert.Contains(t, results[1].Err.Error(), "lint: 3 problems")
+	assert.NotContains(t, results[1].Err.Error(), "Running:")
+}
+
+func TestVerify_PortChecks(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+	address := listener.Addr().String()
+
+	cfg := &config.Config{Verify: []config.Check{
+		{PortInUse: address},
+		{PortFree: address},
+	}}
+	results := NewExecutor(cfg, t.TempDir()).Verify(t.TempDir())
+
+	require.Len(t, results, 2)
+	assert.NoError(t, results[0].Err)
+	require.Error(t, results[1].Err)
+	assert.Contains(t, results[1].Err.Error(), address+" is in use")
+}
+
+func TestVerify_ExpandsVariables(t *testing.T) {
+	worktreeDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(worktreeDir, "marker.env"), nil, 0o644))
+	t.Setenv("WTP_TEST_MARKER", "marker")
+
+	cfg := &config.Config{Verify: []config.Check{{File: "${WTP_TEST_MARKER}.env"}}}
+	results := NewExecutor(cfg, t.TempDir()).Verify(worktreeDir)
+
+	require.Len(t, results, 1)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, "marker.env", results[0].Target)
+}
@@ -0,0 +1,94 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+const waitAttemptTimeout = 5 * time.Second
+
+// Variables to allow mocking in tests
+var (
+	waitPollInterval = 500 * time.Millisecond
+	waitHTTPClient   = &http.Client{Timeout: waitAttemptTimeout}
+)
+
+// executeWaitHookWithWriter polls until the hook's tcp address accepts connections, its
+// http URL answers with a 2xx/3xx status, or its file exists, failing once the wait
+// timeout has passed. ${NAME} references in the target are expanded as for patch hooks.
+func (e *Executor) executeWaitHookWithWriter(w io.Writer, hook *config.Hook, worktreePath string) error {
+	kind, target := hook.WaitCondition()
+	target = e.valueExpander(hook, worktreePath)(target)
+	if kind == config.WaitKindFile && !filepath.IsAbs(target) {
+		target = filepath.Join(worktreePath, target)
+	}
+
+	timeout := e.config.WaitTimeout(hook)
+	if _, err := fmt.Fprintf(w, "  Waiting for %s %s (timeout %s)\n", kind, target, timeout); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(e.ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	for {
+		err := checkWaitCondition(ctx, kind, target)
+		if err == nil {
+			_, err := fmt.Fprintf(w, "  Ready after %s\n", time.Since(start).Round(time.Millisecond))
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			if opErr := e.ctx.Err(); opErr != nil {
+				return fmt.Errorf("stopped waiting for %s %s: %w", kind, target, opErr)
+			}
+			return fmt.Errorf("timed out after %s waiting for %s %s: %w", timeout, kind, target, err)
+		case <-time.After(waitPollInterval):
+		}
+	}
+}
+
+func checkWaitCondition(ctx context.Context, kind, target string) error {
+	switch kind {
+	case config.WaitKindTCP:
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", target)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	case config.WaitKindHTTP:
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, http.NoBody)
+		if err != nil {
+			return err
+		}
+		resp, err := waitHTTPClient.Do(req)
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf("unexpected status %s", resp.Status)
+		}
+		return nil
+	default:
+		if _, err := os.Stat(target); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return errors.New("file does not exist yet")
+			}
+			return err
+		}
+		return nil
+	}
+}
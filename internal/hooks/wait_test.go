@@ -0,0 +1,88 @@
+package hooks
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func withFastWaitPolling(t *testing.T) {
+	t.Helper()
+	original := waitPollInterval
+	waitPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { waitPollInterval = original })
+}
+
+func TestExecuteWaitHook_TCP(t *testing.T) {
+	withFastWaitPolling(t)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+
+	hook := &config.Hook{Type: config.HookTypeWait, TCP: "127.0.0.1:${PORT}", Env: map[string]string{"PORT": port}}
+	executor := NewExecutor(&config.Config{}, t.TempDir())
+
+	var buf bytes.Buffer
+	require.NoError(t, executor.executeWaitHookWithWriter(&buf, hook, t.TempDir()))
+	assert.Contains(t, buf.String(), "Waiting for tcp 127.0.0.1:"+port)
+	assert.Contains(t, buf.String(), "Ready after")
+}
+
+func TestExecuteWaitHook_HTTPBecomesReady(t *testing.T) {
+	withFastWaitPolling(t)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	hook := &config.Hook{Type: config.HookTypeWait, HTTP: server.URL + "/health"}
+	executor := NewExecutor(&config.Config{}, t.TempDir())
+
+	var buf bytes.Buffer
+	require.NoError(t, executor.executeWaitHookWithWriter(&buf, hook, t.TempDir()))
+	assert.Equal(t, 3, requests)
+}
+
+func TestExecuteWaitHook_FileTimesOut(t *testing.T) {
+	withFastWaitPolling(t)
+	hook := &config.Hook{Type: config.HookTypeWait, File: "tmp/ready", Timeout: "50ms"}
+	executor := NewExecutor(&config.Config{}, t.TempDir())
+
+	var buf bytes.Buffer
+	err := executor.executeWaitHookWithWriter(&buf, hook, t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out after 50ms waiting for file")
+}
+
+func TestExecuteWaitHook_FileAppears(t *testing.T) {
+	withFastWaitPolling(t)
+	worktree := t.TempDir()
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		_ = os.WriteFile(filepath.Join(worktree, "ready"), nil, 0o600)
+	}()
+
+	hook := &config.Hook{Type: config.HookTypeWait, File: "ready", Timeout: "5s"}
+	executor := NewExecutor(&config.Config{}, t.TempDir())
+
+	var buf bytes.Buffer
+	require.NoError(t, executor.executeWaitHookWithWriter(&buf, hook, worktree))
+}
@@ -0,0 +1,255 @@
+// Package progress shows what wtp is waiting on, such as a hook running npm install or
+// copying node_modules, as live lines on a terminal: a spinner, the time spent so far,
+// and for copies the bytes copied. A nil *Display shows nothing, so callers need no
+// checks when progress is off, e.g. with --quiet or when stderr is not a terminal.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// tickInterval is how often the lines are redrawn.
+	tickInterval = 100 * time.Millisecond
+	// showAfter is how long a task runs before its line appears, so that quick tasks
+	// do not flash.
+	showAfter = time.Second
+	// frameColumns are taken by the spinner and the spaces around the label.
+	frameColumns = 3
+
+	ansiClearLine = "\r\x1b[2K"
+	ansiCursorUp  = "\x1b[1A"
+	ansiCyan      = "\x1b[36m"
+	ansiDim       = "\x1b[2m"
+	ansiReset     = "\x1b[0m"
+
+	byteUnit = 1024
+)
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// Display draws one line per running task at the bottom of a terminal and redraws them
+// as time passes. Output written through Writer clears the lines first, so it is never
+// mixed up with them.
+type Display struct {
+	out   io.Writer
+	color bool
+	// width returns the width of the terminal in columns, or 0 when unknown; lines are
+	// cut to fit, since a wrapped line could not be cleared.
+	width func() int
+	now   func() time.Time
+
+	mu    sync.Mutex
+	tasks []*Task
+	frame int
+	// drawn is how many lines are on the terminal now.
+	drawn int
+	// midLine is set while the last output did not end a line, e.g. a prompt waiting
+	// for an answer; nothing is drawn then.
+	midLine bool
+	stop    chan struct{}
+}
+
+// Task is a piece of work shown on the display, from Start until Done.
+type Task struct {
+	display *Display
+	label   string
+	started time.Time
+	copied  atomic.Int64
+}
+
+// New returns a display drawing on out, a terminal width columns wide, with colors
+// unless color is false.
+func New(out io.Writer, color bool, width func() int) *Display {
+	return &Display{out: out, color: color, width: width, now: time.Now}
+}
+
+// Start shows label, e.g. "Hook 2 (command)", until the returned task is done.
+func (d *Display) Start(label string) *Task {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	task := &Task{display: d, label: label, started: d.now()}
+	d.tasks = append(d.tasks, task)
+	if d.stop == nil {
+		d.stop = make(chan struct{})
+		go d.tick(d.stop)
+	}
+	return task
+}
+
+// Writer returns w wrapped so that the lines are cleared before anything is written to
+// it; they are drawn again on the next tick.
+func (d *Display) Writer(w io.Writer) io.Writer {
+	if d == nil {
+		return w
+	}
+	return &clearingWriter{display: d, w: w}
+}
+
+// Add counts n more bytes copied by the task.
+func (t *Task) Add(n int64) {
+	if t == nil {
+		return
+	}
+	t.copied.Add(n)
+}
+
+// Copied returns the bytes counted so far.
+func (t *Task) Copied() int64 {
+	if t == nil {
+		return 0
+	}
+	return t.copied.Load()
+}
+
+// Write counts len(p) bytes copied, so that the task can be given to io.MultiWriter or
+// io.TeeReader.
+func (t *Task) Write(p []byte) (int, error) {
+	t.Add(int64(len(p)))
+	return len(p), nil
+}
+
+// Done removes the task's line. The display stops redrawing once no task is left.
+func (t *Task) Done() {
+	if t == nil {
+		return
+	}
+	d := t.display
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.clear()
+	d.tasks = slices.DeleteFunc(d.tasks, func(task *Task) bool { return task == t })
+	if len(d.tasks) == 0 && d.stop != nil {
+		close(d.stop)
+		d.stop = nil
+	}
+}
+
+func (d *Display) tick(stop <-chan struct{}) {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			d.mu.Lock()
+			d.draw()
+			d.mu.Unlock()
+		}
+	}
+}
+
+// draw replaces the lines on the terminal with those of the tasks that have run for
+// showAfter. d.mu must be held.
+func (d *Display) draw() {
+	d.clear()
+	if d.midLine {
+		return
+	}
+	now := d.now()
+	width := 0
+	if d.width != nil {
+		width = d.width()
+	}
+	var lines []string
+	for _, task := range d.tasks {
+		if now.Sub(task.started) >= showAfter {
+			lines = append(lines, d.line(task, now, width))
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+	if _, err := io.WriteString(d.out, strings.Join(lines, "\n")); err == nil {
+		d.drawn = len(lines)
+	}
+	d.frame++
+}
+
+// clear erases the lines drawn, leaving the cursor where the first one started. d.mu
+// must be held.
+func (d *Display) clear() {
+	if d.drawn == 0 {
+		return
+	}
+	erase := ansiClearLine + strings.Repeat(ansiCursorUp+ansiClearLine, d.drawn-1)
+	_, _ = io.WriteString(d.out, erase)
+	d.drawn = 0
+}
+
+// line renders task, e.g. "⠹ Hook 1 (copy) 12s, 310.2 MiB copied", cut to width
+// columns unless width is 0.
+func (d *Display) line(task *Task, now time.Time, width int) string {
+	spinner := spinnerFrames[d.frame%len(spinnerFrames)]
+	detail := now.Sub(task.started).Truncate(time.Second).String()
+	if copied := task.copied.Load(); copied > 0 {
+		detail += ", " + formatBytes(copied) + " copied"
+	}
+
+	label := task.label
+	if width > 0 {
+		room := width - frameColumns - utf8.RuneCountInString(detail)
+		label = truncate(label, room)
+	}
+	if !d.color {
+		return fmt.Sprintf("%s %s %s", spinner, label, detail)
+	}
+	return fmt.Sprintf("%s%s%s %s %s%s%s", ansiCyan, spinner, ansiReset, label, ansiDim, detail, ansiReset)
+}
+
+// truncate cuts s to at most n runes, ending it with "…" when cut.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	if n <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	return string(runes[:n-1]) + "…"
+}
+
+// formatBytes formats a byte count with binary units, e.g. "12.3 MiB".
+func formatBytes(n int64) string {
+	if n < byteUnit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value := float64(n)
+	unit := ""
+	for _, unit = range []string{"KiB", "MiB", "GiB", "TiB"} {
+		value /= byteUnit
+		if value < byteUnit {
+			break
+		}
+	}
+	return fmt.Sprintf("%.1f %s", value, unit)
+}
+
+// clearingWriter clears the display's lines before each write.
+type clearingWriter struct {
+	display *Display
+	w       io.Writer
+}
+
+func (cw *clearingWriter) Write(p []byte) (int, error) {
+	d := cw.display
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.clear()
+	n, err := cw.w.Write(p)
+	if n > 0 {
+		d.midLine = p[n-1] != '\n'
+	}
+	return n, err
+}
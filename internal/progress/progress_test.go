@@ -0,0 +1,169 @@
+package progress
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDisplay returns a display whose clock the test moves, with nothing drawn by
+// ticks until the test draws itself.
+func newTestDisplay(t *testing.T, color bool, width int) (display *Display, out *bytes.Buffer, now *time.Time) {
+	t.Helper()
+	out = &bytes.Buffer{}
+	current := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	display = New(out, color, func() int { return width })
+	display.now = func() time.Time { return current }
+	return display, out, &current
+}
+
+// drawn redraws d and returns what it wrote.
+func drawn(d *Display, out *bytes.Buffer) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	out.Reset()
+	d.draw()
+	return out.String()
+}
+
+func TestNilDisplayShowsNothing(t *testing.T) {
+	var display *Display
+	task := display.Start("Hook 1 (command)")
+	assert.Nil(t, task)
+	task.Add(10)
+	assert.Zero(t, task.Copied())
+	task.Done()
+
+	var buf bytes.Buffer
+	assert.Same(t, &buf, display.Writer(&buf).(*bytes.Buffer))
+}
+
+func TestDisplay_DrawsTasksAfterShowAfter(t *testing.T) {
+	display, out, now := newTestDisplay(t, false, 0)
+	task := display.Start("Hook 1 (command)")
+	defer task.Done()
+
+	assert.Empty(t, drawn(display, out), "quick tasks are not shown")
+
+	*now = now.Add(65 * time.Second)
+	assert.Equal(t, "⠋ Hook 1 (command) 1m5s", drawn(display, out))
+	assert.Equal(t, ansiClearLine+"⠙ Hook 1 (command) 1m5s", drawn(display, out))
+}
+
+func TestDisplay_ShowsBytesCopied(t *testing.T) {
+	display, out, now := newTestDisplay(t, false, 0)
+	task := display.Start("Hook 2 (copy)")
+	defer task.Done()
+	task.Add(3 * 1024 * 1024)
+	_, err := io.Copy(task, strings.NewReader(strings.Repeat("x", 512*1024)))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3.5*1024*1024), task.Copied())
+
+	*now = now.Add(2 * time.Second)
+	assert.Equal(t, "⠋ Hook 2 (copy) 2s, 3.5 MiB copied", drawn(display, out))
+}
+
+func TestDisplay_OneLinePerTask(t *testing.T) {
+	display, out, now := newTestDisplay(t, false, 0)
+	first := display.Start("Hook 1 (command)")
+	second := display.Start("Hook 2 (command)")
+	defer second.Done()
+
+	*now = now.Add(time.Second)
+	assert.Equal(t, "⠋ Hook 1 (command) 1s\n⠋ Hook 2 (command) 1s", drawn(display, out))
+
+	out.Reset()
+	first.Done()
+	assert.Equal(t, ansiClearLine+ansiCursorUp+ansiClearLine, out.String(), "both lines are cleared")
+	assert.Equal(t, "⠙ Hook 2 (command) 1s", drawn(display, out))
+}
+
+func TestDisplay_Color(t *testing.T) {
+	display, out, now := newTestDisplay(t, true, 0)
+	task := display.Start("Hook 1 (command)")
+	defer task.Done()
+
+	*now = now.Add(time.Second)
+	assert.Equal(t, ansiCyan+"⠋"+ansiReset+" Hook 1 (command) "+ansiDim+"1s"+ansiReset, drawn(display, out))
+}
+
+func TestDisplay_CutsLinesToWidth(t *testing.T) {
+	display, out, now := newTestDisplay(t, false, 20)
+	task := display.Start("Hook 1 (command: npm install)")
+	defer task.Done()
+
+	*now = now.Add(time.Second)
+	line := drawn(display, out)
+	assert.Equal(t, "⠋ Hook 1 (comman… 1s", line)
+	assert.Len(t, []rune(line), 20)
+}
+
+func TestDisplay_WriterClearsLines(t *testing.T) {
+	display, out, now := newTestDisplay(t, false, 0)
+	task := display.Start("Hook 1 (command)")
+	defer task.Done()
+	*now = now.Add(time.Second)
+	drawn(display, out)
+
+	var hookOutput bytes.Buffer
+	w := display.Writer(&hookOutput)
+	out.Reset()
+	_, err := fmt.Fprint(w, "Answer: ")
+	require.NoError(t, err)
+	assert.Equal(t, ansiClearLine, out.String(), "the line is cleared before the output")
+	assert.Equal(t, "Answer: ", hookOutput.String())
+	assert.Empty(t, drawn(display, out), "nothing is drawn after a partial line")
+
+	_, err = fmt.Fprintln(w, "yes")
+	require.NoError(t, err)
+	assert.NotEmpty(t, drawn(display, out))
+}
+
+func TestDisplay_TicksUntilDone(t *testing.T) {
+	var out bytes.Buffer
+	synced := &lockedBuffer{buf: &out}
+	display := New(synced, false, nil)
+
+	task := display.Start("Hook 1 (command)")
+	display.mu.Lock()
+	display.now = func() time.Time { return time.Now().Add(showAfter) }
+	display.mu.Unlock()
+	assert.Eventually(t, func() bool { return strings.Contains(synced.String(), "Hook 1 (command)") },
+		time.Second, 10*time.Millisecond)
+	task.Done()
+
+	display.mu.Lock()
+	defer display.mu.Unlock()
+	assert.Nil(t, display.stop, "the ticker stops with the last task")
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "512 B", formatBytes(512))
+	assert.Equal(t, "1.5 KiB", formatBytes(1536))
+	assert.Equal(t, "2.0 GiB", formatBytes(2*1024*1024*1024))
+}
+
+// lockedBuffer is a bytes.Buffer the ticker and the test can use at once.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf *bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
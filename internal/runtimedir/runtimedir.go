@@ -0,0 +1,166 @@
+// Package runtimedir locates the directory wtp keeps a worktree's logs, manifests, and
+// state in. It is <worktree>/.wtp unless defaults.runtime_dir moves it out of the
+// worktree, to <runtime_dir>/<clone key>/<key>, where the clone key tells clones of one
+// repository apart and the key extends the worktree's ID.
+package runtimedir
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+const (
+	// DirName is the runtime directory inside a worktree when defaults.runtime_dir is unset.
+	DirName = ".wtp"
+	// keyPrefix and keyDigits make up a worktree's directory under defaults.runtime_dir,
+	// e.g. "wt-3f2a9c01d7e4b6a8" for the worktree with ID "wt-3f2a".
+	keyPrefix = "wt-"
+	keyDigits = 16
+
+	dirPermissions = 0o755
+	ignoreFileMode = 0o644
+	// ignoreFileContent keeps the runtime directory inside a worktree out of 'git status'.
+	ignoreFileContent = "*\n"
+)
+
+// Dir returns the runtime directory of the worktree at worktreePath without creating it.
+// A directory under defaults.runtime_dir needs the worktree's git directory to be readable.
+func Dir(cfg *config.Config, worktreePath string) (string, error) {
+	if !relocated(cfg) {
+		return filepath.Join(worktreePath, DirName), nil
+	}
+
+	info, err := os.Stat(filepath.Join(worktreePath, ".git"))
+	if err != nil {
+		return "", err
+	}
+	repoRoot, ok := git.WorktreeOwnerRepo(worktreePath)
+	if !ok {
+		repoRoot = worktreePath
+	}
+	key := keyPrefix + git.WorktreeDigest(worktreePath, info.IsDir())[:keyDigits]
+	return filepath.Join(CloneDir(cfg, repoRoot, git.OriginURL(repoRoot)), key), nil
+}
+
+// CloneDir returns the directory under defaults.runtime_dir holding the runtime
+// directories of the worktrees of the clone at repoRoot whose origin is remoteURL. Clones
+// sharing runtime_dir, e.g. two checkouts of one repository, get one directory each.
+// It is "" unless runtime_dir is set.
+func CloneDir(cfg *config.Config, repoRoot, remoteURL string) string {
+	if !relocated(cfg) {
+		return ""
+	}
+	base := cfg.ExpandVariables(cfg.Defaults.RuntimeDir, repoRoot, "")
+	if !filepath.IsAbs(base) {
+		base = filepath.Join(repoRoot, base)
+	}
+	return filepath.Join(base, git.CloneKey(remoteURL, repoRoot))
+}
+
+// Ensure returns the runtime directory of the worktree at worktreePath, creating it first.
+// Inside the worktree, the directory gets a .gitignore that ignores all of it.
+func Ensure(cfg *config.Config, worktreePath string) (string, error) {
+	dir, err := Dir(cfg, worktreePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the runtime directory: %w", err)
+	}
+	if err := os.MkdirAll(dir, dirPermissions); err != nil {
+		return "", fmt.Errorf("failed to create the runtime directory: %w", err)
+	}
+	if relocated(cfg) {
+		return dir, nil
+	}
+
+	ignoreFile := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(ignoreFile); os.IsNotExist(err) {
+		if err := os.WriteFile(ignoreFile, []byte(ignoreFileContent), ignoreFileMode); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", ignoreFile, err)
+		}
+	}
+	return dir, nil
+}
+
+// Path returns where the runtime file name of the worktree at worktreePath is read from: the
+// runtime directory, or the worktree's git directory when only a wtp version from before
+// the runtime directory wrote it there. The file need not exist.
+func Path(cfg *config.Config, worktreePath, name string) (string, error) {
+	dir, err := Dir(cfg, worktreePath)
+	if err != nil {
+		return "", err
+	}
+	current := filepath.Join(dir, name)
+	if _, err := os.Stat(current); err == nil {
+		return current, nil
+	}
+	if legacy, ok := legacyPath(worktreePath, name); ok {
+		return legacy, nil
+	}
+	return current, nil
+}
+
+// WriteFile writes the runtime file name of the worktree at worktreePath, and removes the
+// copy an older wtp version left in the git directory.
+func WriteFile(cfg *config.Config, worktreePath, name string, data []byte, perm os.FileMode) error {
+	dir, err := Ensure(cfg, worktreePath)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), data, perm); err != nil {
+		return err
+	}
+	if legacy, ok := legacyPath(worktreePath, name); ok {
+		return os.Remove(legacy)
+	}
+	return nil
+}
+
+// Remove deletes the runtime file name of the worktree at worktreePath, wherever it is.
+func Remove(cfg *config.Config, worktreePath, name string) error {
+	if dir, err := Dir(cfg, worktreePath); err == nil {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if legacy, ok := legacyPath(worktreePath, name); ok {
+		return os.Remove(legacy)
+	}
+	return nil
+}
+
+// Cleanup returns a function that deletes the runtime directory of the worktree at
+// worktreePath when it lives outside the worktree. It must be taken before the worktree is
+// removed, since the directory is found through the worktree's git directory.
+func Cleanup(cfg *config.Config, worktreePath string) func() error {
+	dir, err := Dir(cfg, worktreePath)
+	if err != nil || !relocated(cfg) {
+		return func() error { return nil }
+	}
+	return func() error {
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+		// The clone's directory goes with its last worktree
+		_ = os.Remove(filepath.Dir(dir))
+		return nil
+	}
+}
+
+func relocated(cfg *config.Config) bool {
+	return cfg != nil && cfg.Defaults.RuntimeDir != ""
+}
+
+func legacyPath(worktreePath, name string) (string, bool) {
+	gitDir, err := git.WorktreeGitDir(worktreePath)
+	if err != nil {
+		return "", false
+	}
+	path := filepath.Join(gitDir, name)
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+	return path, true
+}
@@ -0,0 +1,123 @@
+package runtimedir
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+// setupLinkedWorktree creates a worktree whose .git file points at an administrative
+// directory named "foo" of the repository at repoRoot, and returns the worktree and the
+// administrative directory.
+func setupLinkedWorktree(t *testing.T) (repoRoot, worktreePath, gitDir string) {
+	t.Helper()
+	repoRoot = filepath.Join(t.TempDir(), "myapp")
+	gitDir = filepath.Join(repoRoot, ".git", "worktrees", "foo")
+	require.NoError(t, os.MkdirAll(gitDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(gitDir, "commondir"), []byte("../..\n"), 0o644))
+
+	worktreePath = t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(worktreePath, ".git"), []byte("gitdir: "+gitDir+"\n"), 0o644))
+	return repoRoot, worktreePath, gitDir
+}
+
+func TestDir(t *testing.T) {
+	repoRoot, worktreePath, _ := setupLinkedWorktree(t)
+
+	dir, err := Dir(&config.Config{}, worktreePath)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(worktreePath, ".wtp"), dir)
+
+	cfg := &config.Config{Defaults: config.Defaults{RuntimeDir: "../wtp-runtime/${DIRNAME}"}}
+	dir, err = Dir(cfg, worktreePath)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(filepath.Dir(repoRoot), "wtp-runtime", "myapp", git.CloneKey("", repoRoot),
+		"wt-2c26b46b68ffc68f"), dir)
+	assert.Equal(t, filepath.Dir(dir), CloneDir(cfg, repoRoot, ""))
+	assert.Empty(t, CloneDir(&config.Config{}, repoRoot, ""))
+
+	_, err = Dir(cfg, t.TempDir())
+	assert.True(t, os.IsNotExist(err), "a relocated runtime directory needs the worktree's git directory")
+}
+
+func TestDir_ClonesSharingRuntimeDir(t *testing.T) {
+	cfg := &config.Config{Defaults: config.Defaults{RuntimeDir: t.TempDir()}}
+	_, first, _ := setupLinkedWorktree(t)
+	_, second, _ := setupLinkedWorktree(t)
+
+	firstDir, err := Dir(cfg, first)
+	require.NoError(t, err)
+	secondDir, err := Dir(cfg, second)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Base(firstDir), filepath.Base(secondDir), "both worktrees are named foo")
+	assert.NotEqual(t, firstDir, secondDir)
+}
+
+func TestEnsure(t *testing.T) {
+	_, worktreePath, _ := setupLinkedWorktree(t)
+
+	dir, err := Ensure(&config.Config{}, worktreePath)
+	require.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	require.NoError(t, err)
+	assert.Equal(t, "*\n", string(content))
+
+	cfg := &config.Config{Defaults: config.Defaults{RuntimeDir: t.TempDir()}}
+	dir, err = Ensure(cfg, worktreePath)
+	require.NoError(t, err)
+	assert.DirExists(t, dir)
+	assert.NoFileExists(t, filepath.Join(dir, ".gitignore"))
+}
+
+func TestPath_FallsBackToGitDir(t *testing.T) {
+	_, worktreePath, gitDir := setupLinkedWorktree(t)
+	cfg := &config.Config{}
+
+	path, err := Path(cfg, worktreePath, "state.json")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(worktreePath, ".wtp", "state.json"), path)
+
+	legacy := filepath.Join(gitDir, "state.json")
+	require.NoError(t, os.WriteFile(legacy, []byte("old"), 0o644))
+	path, err = Path(cfg, worktreePath, "state.json")
+	require.NoError(t, err)
+	assert.Equal(t, legacy, path)
+
+	// Writing moves the file into the runtime directory
+	require.NoError(t, WriteFile(cfg, worktreePath, "state.json", []byte("new"), 0o644))
+	assert.NoFileExists(t, legacy)
+	path, err = Path(cfg, worktreePath, "state.json")
+	require.NoError(t, err)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(content))
+
+	require.NoError(t, Remove(cfg, worktreePath, "state.json"))
+	assert.NoFileExists(t, path)
+	require.NoError(t, Remove(cfg, worktreePath, "state.json"))
+}
+
+func TestCleanup(t *testing.T) {
+	_, worktreePath, _ := setupLinkedWorktree(t)
+
+	cfg := &config.Config{Defaults: config.Defaults{RuntimeDir: t.TempDir()}}
+	dir, err := Ensure(cfg, worktreePath)
+	require.NoError(t, err)
+	cleanup := Cleanup(cfg, worktreePath)
+	require.NoError(t, os.Remove(filepath.Join(worktreePath, ".git")))
+	require.NoError(t, cleanup())
+	assert.NoDirExists(t, dir)
+	assert.NoDirExists(t, filepath.Dir(dir), "the empty directory of the clone is removed")
+
+	// The default runtime directory goes away with the worktree
+	inside, err := Ensure(&config.Config{}, worktreePath)
+	require.NoError(t, err)
+	require.NoError(t, Cleanup(&config.Config{}, worktreePath)())
+	assert.DirExists(t, inside)
+}
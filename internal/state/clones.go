@@ -0,0 +1,115 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/satococoa/wtp/v2/internal/filelock"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+const (
+	// ClonesFileName is the name of the clone registry file in the user cache directory.
+	ClonesFileName = "clones.json"
+
+	clonesLockFileName = "clones.lock"
+)
+
+// userCacheDir is a variable to allow mocking in tests.
+var userCacheDir = os.UserCacheDir
+
+// Clone is a clone of a repository that wtp created worktrees in. Clones are told apart by
+// Key, so two clones of one repository on a machine are two entries.
+type Clone struct {
+	// Key is the git.CloneKey of RemoteURL and Path.
+	Key       string    `json:"key"`
+	RemoteURL string    `json:"remote_url,omitempty"`
+	Path      string    `json:"path"`
+	LastUsed  time.Time `json:"last_used"`
+}
+
+// Clones is the content of the clone registry, which every repository on the machine
+// shares. It lets commands such as 'wtp list --all-repos' find the other clones.
+type Clones struct {
+	Version int     `json:"version"`
+	Clones  []Clone `json:"clones"`
+}
+
+// NewClone returns the entry of the clone whose main worktree is at path and whose origin
+// is remoteURL, used at now.
+func NewClone(remoteURL, path string, now time.Time) Clone {
+	path = filepath.Clean(path)
+	return Clone{Key: git.CloneKey(remoteURL, path), RemoteURL: remoteURL, Path: path, LastUsed: now}
+}
+
+// ClonesPath returns the clone registry file in the user cache directory.
+func ClonesPath() (string, error) {
+	cacheDir, err := userCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve cache directory: %w", err)
+	}
+	return filepath.Join(cacheDir, DirName, ClonesFileName), nil
+}
+
+// LoadClones reads the clone registry at path. A missing file is an empty registry.
+func LoadClones(path string) (*Clones, error) {
+	// #nosec G304 -- path is in the user cache directory
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Clones{Version: Version}, nil
+		}
+		return nil, fmt.Errorf("failed to read clone registry: %w", err)
+	}
+
+	var clones Clones
+	if err := json.Unmarshal(data, &clones); err != nil {
+		return nil, fmt.Errorf("failed to parse clone registry %s: %w", path, err)
+	}
+	return &clones, nil
+}
+
+// UpdateClones applies change to the clone registry at path and writes it back, holding
+// a lock meanwhile like Update.
+func UpdateClones(path string, change func(*Clones)) error {
+	unlock, err := filelock.Lock(filepath.Join(filepath.Dir(path), clonesLockFileName))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = unlock() }()
+
+	clones, err := LoadClones(path)
+	if err != nil {
+		return err
+	}
+	change(clones)
+	clones.Version = Version
+	return writeJSON(path, clones, "clone registry")
+}
+
+// Add registers a copy of clone, replacing an earlier entry with the same key.
+func (c *Clones) Add(clone *Clone) {
+	if i := c.index(clone.Key); i >= 0 {
+		c.Clones[i] = *clone
+		return
+	}
+	c.Clones = append(c.Clones, *clone)
+}
+
+// Remove drops the clone with key and reports whether it was registered.
+func (c *Clones) Remove(key string) bool {
+	i := c.index(key)
+	if i < 0 {
+		return false
+	}
+	c.Clones = slices.Delete(c.Clones, i, i+1)
+	return true
+}
+
+func (c *Clones) index(key string) int {
+	return slices.IndexFunc(c.Clones, func(clone Clone) bool { return clone.Key == key })
+}
@@ -0,0 +1,54 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClonesPath(t *testing.T) {
+	cacheDir := t.TempDir()
+	original := userCacheDir
+	userCacheDir = func() (string, error) { return cacheDir, nil }
+	defer func() { userCacheDir = original }()
+
+	path, err := ClonesPath()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(cacheDir, "wtp", "clones.json"), path)
+}
+
+func TestUpdateClones_KeysOnRemoteAndPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ClonesFileName)
+	const url = "git@github.com:satococoa/wtp.git"
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	first := NewClone(url, "/src/wtp", now)
+	second := NewClone(url, "/work/wtp", now)
+	require.NoError(t, UpdateClones(path, func(c *Clones) {
+		c.Add(&first)
+		c.Add(&second)
+	}))
+
+	later := NewClone(url, "/src/wtp/", now.Add(time.Hour))
+	require.NoError(t, UpdateClones(path, func(c *Clones) { c.Add(&later) }))
+
+	clones, err := LoadClones(path)
+	require.NoError(t, err)
+	require.Len(t, clones.Clones, 2, "two clones of one repository are two entries")
+	assert.Equal(t, later, clones.Clones[0], "the same clone is replaced")
+	assert.Equal(t, second, clones.Clones[1])
+
+	require.NoError(t, UpdateClones(path, func(c *Clones) { assert.True(t, c.Remove(second.Key)) }))
+	clones, err = LoadClones(path)
+	require.NoError(t, err)
+	assert.Len(t, clones.Clones, 1)
+}
+
+func TestLoadClones_Missing(t *testing.T) {
+	clones, err := LoadClones(filepath.Join(t.TempDir(), ClonesFileName))
+	require.NoError(t, err)
+	assert.Empty(t, clones.Clones)
+}
@@ -0,0 +1,233 @@
+// Package state keeps the registry of the worktrees wtp created, so that they can be told
+// apart from worktrees added with 'git worktree add' or by other tools. The registry is
+// wtp/state.json in the git directory of the main worktree, shared by every worktree.
+package state
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/filelock"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+const (
+	// DirName is the directory under the git directory holding wtp's state.
+	DirName = "wtp"
+	// FileName is the name of the registry file.
+	FileName = "state.json"
+	// Version is the format version written to the registry.
+	Version = 1
+
+	lockFileName          = "state.lock"
+	operationLockFileName = "operation.lock"
+	fileMode              = 0o644
+	dirMode               = 0o755
+	configHashSize        = 12
+)
+
+// Outcomes of a post_create hook
+const (
+	HookOK     = "ok"
+	HookFailed = "failed"
+)
+
+// HookResult is the outcome of one post_create hook when wtp created the worktree.
+type HookResult struct {
+	Index  int    `json:"index"` // 1-based post_create number
+	Type   string `json:"type"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Worktree is a worktree wtp created.
+type Worktree struct {
+	Path      string    `json:"path"`
+	Branch    string    `json:"branch,omitempty"`
+	Profile   string    `json:"profile,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// ConfigHash identifies the configuration the worktree was created with; see ConfigHash.
+	ConfigHash string       `json:"config_hash,omitempty"`
+	Hooks      []HookResult `json:"hooks,omitempty"`
+}
+
+// Registry is the content of the registry file.
+type Registry struct {
+	Version   int        `json:"version"`
+	Worktrees []Worktree `json:"worktrees"`
+}
+
+// Path returns the registry file of the repository whose main worktree is at mainRepoPath.
+func Path(mainRepoPath string) (string, error) {
+	gitDir, err := git.WorktreeGitDir(mainRepoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to find the git directory of %s: %w", mainRepoPath, err)
+	}
+	return filepath.Join(gitDir, DirName, FileName), nil
+}
+
+// OperationLockPath returns the lock file that serializes the wtp commands creating and
+// removing worktrees of the repository whose main worktree is at mainRepoPath. It lives
+// next to the registry, so every worktree and clone sharing the git directory uses it.
+func OperationLockPath(mainRepoPath string) (string, error) {
+	path, err := Path(mainRepoPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), operationLockFileName), nil
+}
+
+// Load reads the registry at path. A missing file is an empty registry.
+func Load(path string) (*Registry, error) {
+	// #nosec G304 -- path is derived from the repository's git directory
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Registry{Version: Version}, nil
+		}
+		return nil, fmt.Errorf("failed to read worktree registry: %w", err)
+	}
+
+	var registry Registry
+	if err := json.Unmarshal(data, &registry); err != nil {
+		return nil, fmt.Errorf("failed to parse worktree registry %s: %w", path, err)
+	}
+	return &registry, nil
+}
+
+// Update applies change to the registry at path and writes it back. It holds a lock
+// meanwhile, so that wtp commands running at the same time do not lose each other's
+// changes, and replaces the file at once, so that readers never see half of it.
+func Update(path string, change func(*Registry)) error {
+	unlock, err := filelock.Lock(filepath.Join(filepath.Dir(path), lockFileName))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = unlock() }()
+
+	registry, err := Load(path)
+	if err != nil {
+		return err
+	}
+	change(registry)
+	registry.Version = Version
+	return registry.save(path)
+}
+
+func (r *Registry) save(path string) error {
+	return writeJSON(path, r, "worktree registry")
+}
+
+// writeJSON replaces the file at path with v encoded as JSON at once, so that readers
+// never see half of it. what names the file in errors.
+func writeJSON(path string, v any, what string) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", what, err)
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, dirMode); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", what, err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", what, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", what, err)
+	}
+	if err := os.Chmod(tmp.Name(), fileMode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", what, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", what, err)
+	}
+	return nil
+}
+
+// Find returns the registered worktree at path, or nil. Paths that differ in spelling
+// but name the same existing directory, e.g. through a symlink, match too.
+func (r *Registry) Find(path string) *Worktree {
+	if i := r.index(path); i >= 0 {
+		return &r.Worktrees[i]
+	}
+	return nil
+}
+
+// Add registers a copy of wt, replacing an earlier entry for the same path.
+func (r *Registry) Add(wt *Worktree) {
+	entry := *wt
+	entry.Path = filepath.Clean(entry.Path)
+	if i := r.index(entry.Path); i >= 0 {
+		r.Worktrees[i] = entry
+		return
+	}
+	r.Worktrees = append(r.Worktrees, entry)
+}
+
+// Remove drops the worktree at path and reports whether it was registered.
+func (r *Registry) Remove(path string) bool {
+	i := r.index(path)
+	if i < 0 {
+		return false
+	}
+	r.Worktrees = append(r.Worktrees[:i], r.Worktrees[i+1:]...)
+	return true
+}
+
+// Move changes the path of the worktree registered at from to to, after it was moved.
+func (r *Registry) Move(from, to string) {
+	if wt := r.Find(from); wt != nil {
+		wt.Path = filepath.Clean(to)
+	}
+}
+
+// Rename changes the branch of the worktree registered at path, after it was renamed.
+func (r *Registry) Rename(path, branch string) {
+	if wt := r.Find(path); wt != nil {
+		wt.Branch = branch
+	}
+}
+
+func (r *Registry) index(path string) int {
+	path = filepath.Clean(path)
+	for i := range r.Worktrees {
+		if r.Worktrees[i].Path == path {
+			return i
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return -1
+	}
+	for i := range r.Worktrees {
+		if other, err := os.Stat(r.Worktrees[i].Path); err == nil && os.SameFile(info, other) {
+			return i
+		}
+	}
+	return -1
+}
+
+// ConfigHash returns a short digest of the effective configuration cfg, which changes
+// whenever a setting or hook does.
+func ConfigHash(cfg *config.Config) string {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:configHashSize]
+}
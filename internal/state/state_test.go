@@ -0,0 +1,117 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/config"
+)
+
+func TestPath(t *testing.T) {
+	repo := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(repo, ".git"), 0o755))
+
+	path, err := Path(repo)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(repo, ".git", DirName, FileName), path)
+
+	lockPath, err := OperationLockPath(repo)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(repo, ".git", DirName, "operation.lock"), lockPath)
+
+	_, err = Path(t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestLoad_Missing(t *testing.T) {
+	registry, err := Load(filepath.Join(t.TempDir(), FileName))
+	require.NoError(t, err)
+	assert.Equal(t, &Registry{Version: Version}, registry)
+}
+
+func TestUpdate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DirName, FileName)
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	wt := Worktree{
+		Path:       "/worktrees/feature/a",
+		Branch:     "feature/a",
+		CreatedAt:  created,
+		ConfigHash: "abc",
+		Hooks:      []HookResult{{Index: 1, Type: "command", Status: HookOK}},
+	}
+
+	require.NoError(t, Update(path, func(r *Registry) { r.Add(&wt) }))
+	registry, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, &Registry{Version: Version, Worktrees: []Worktree{wt}}, registry)
+
+	require.NoError(t, Update(path, func(r *Registry) { r.Move("/worktrees/feature/a", "/worktrees/feature-a") }))
+	registry, err = Load(path)
+	require.NoError(t, err)
+	assert.Nil(t, registry.Find("/worktrees/feature/a"))
+	require.NotNil(t, registry.Find("/worktrees/feature-a/"))
+	assert.Equal(t, "feature/a", registry.Find("/worktrees/feature-a").Branch)
+
+	require.NoError(t, Update(path, func(r *Registry) { r.Rename("/worktrees/feature-a", "feature/b") }))
+	registry, err = Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "feature/b", registry.Find("/worktrees/feature-a").Branch)
+
+	require.NoError(t, Update(path, func(r *Registry) { assert.True(t, r.Remove("/worktrees/feature-a")) }))
+	registry, err = Load(path)
+	require.NoError(t, err)
+	assert.Empty(t, registry.Worktrees)
+}
+
+func TestUpdate_Concurrent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, FileName)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, Update(path, func(r *Registry) {
+				r.Add(&Worktree{Path: filepath.Join(dir, "worktrees", string(rune('a'+i)))})
+			}))
+		}(i)
+	}
+	wg.Wait()
+
+	registry, err := Load(path)
+	require.NoError(t, err)
+	assert.Len(t, registry.Worktrees, 10)
+}
+
+func TestRegistry_AddReplacesAndFindsSameDirectory(t *testing.T) {
+	dir := t.TempDir()
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(dir, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	registry := &Registry{}
+	registry.Add(&Worktree{Path: dir, Branch: "old"})
+	registry.Add(&Worktree{Path: dir + string(filepath.Separator), Branch: "new"})
+	require.Len(t, registry.Worktrees, 1)
+	assert.Equal(t, "new", registry.Worktrees[0].Branch)
+
+	require.NotNil(t, registry.Find(link))
+	assert.Nil(t, registry.Find(filepath.Join(dir, "other")))
+	assert.False(t, registry.Remove(filepath.Join(dir, "other")))
+}
+
+func TestConfigHash(t *testing.T) {
+	cfg := &config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}
+	hash := ConfigHash(cfg)
+	assert.Len(t, hash, configHashSize)
+	assert.Equal(t, hash, ConfigHash(&config.Config{Defaults: config.Defaults{BaseDir: "../worktrees"}}))
+	assert.NotEqual(t, hash, ConfigHash(&config.Config{Defaults: config.Defaults{BaseDir: ".worktrees"}}))
+}
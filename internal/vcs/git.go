@@ -0,0 +1,109 @@
+package vcs
+
+import (
+	"strings"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+// Git is the backend for git worktrees, the default.
+type Git struct{}
+
+// Name returns "git".
+func (Git) Name() string {
+	return config.VCSGit
+}
+
+// AddWorkdir returns 'git worktree add'. With opts.Sparse, the worktree is created empty,
+// limited with 'git sparse-checkout set', and then checked out, so that files outside the
+// sparse paths are never written.
+func (Git) AddWorkdir(path, commitish string, opts command.GitWorktreeAddOptions) []command.Command {
+	if len(opts.Sparse) == 0 {
+		return []command.Command{command.GitWorktreeAdd(path, commitish, opts)}
+	}
+	opts.NoCheckout = true
+	return []command.Command{
+		command.GitWorktreeAdd(path, commitish, opts),
+		command.GitSparseCheckoutSet(path, opts.Sparse),
+		command.GitCheckoutFiles(path),
+	}
+}
+
+// ListWorkdirs returns 'git worktree list --porcelain'.
+func (Git) ListWorkdirs() command.Command {
+	return command.GitWorktreeList()
+}
+
+// ParseWorkdirs parses the output of ListWorkdirs.
+func (Git) ParseWorkdirs(output string) []git.Worktree {
+	return ParseGitWorktreeList(output)
+}
+
+// RemoveWorkdir returns 'git worktree remove', which deletes the worktree's files too.
+func (Git) RemoveWorkdir(path string, force bool) []command.Command {
+	return []command.Command{command.GitWorktreeRemove(path, force)}
+}
+
+// KeepsFiles returns false.
+func (Git) KeepsFiles() bool {
+	return false
+}
+
+// Status returns 'git status --porcelain'.
+func (Git) Status(path string) command.Command {
+	return command.GitStatusPorcelain(path)
+}
+
+// DeleteBranch returns 'git branch -d', or -D with force.
+func (Git) DeleteBranch(branch string, force bool) command.Command {
+	return command.GitBranchDelete(branch, force)
+}
+
+// ParseGitWorktreeList parses the output of 'git worktree list --porcelain'. The first
+// worktree is the main one; a detached worktree has DetachedBranch as its Branch.
+func ParseGitWorktreeList(output string) []git.Worktree {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	var worktrees []git.Worktree
+	var currentWorktree git.Worktree
+	isFirst := true
+
+	for _, line := range lines {
+		if line == "" {
+			if currentWorktree.Path != "" {
+				// First worktree is always the main worktree
+				if isFirst {
+					currentWorktree.IsMain = true
+					isFirst = false
+				}
+				worktrees = append(worktrees, currentWorktree)
+				currentWorktree = git.Worktree{}
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, "worktree ") {
+			currentWorktree.Path = strings.TrimPrefix(line, "worktree ")
+		} else if strings.HasPrefix(line, "HEAD ") {
+			currentWorktree.HEAD = strings.TrimPrefix(line, "HEAD ")
+		} else if strings.HasPrefix(line, "branch ") {
+			currentWorktree.Branch = strings.TrimPrefix(line, "branch refs/heads/")
+		} else if line == DetachedBranch {
+			currentWorktree.Branch = DetachedBranch
+		} else if line == "locked" || strings.HasPrefix(line, "locked ") {
+			currentWorktree.Locked = true
+			currentWorktree.LockReason = strings.TrimPrefix(strings.TrimPrefix(line, "locked"), " ")
+		}
+	}
+
+	if currentWorktree.Path != "" {
+		// First worktree is always the main worktree
+		if isFirst {
+			currentWorktree.IsMain = true
+		}
+		worktrees = append(worktrees, currentWorktree)
+	}
+
+	return worktrees
+}
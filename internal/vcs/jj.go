@@ -0,0 +1,165 @@
+package vcs
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+// jjDefaultWorkspace is the name jj gives the workspace of the repository itself.
+const jjDefaultWorkspace = "default"
+
+// jjWorkspaceTemplate prints a workspace per line: its name, the commit of its working
+// copy, and the bookmarks on that commit and on its parents.
+const jjWorkspaceTemplate = `name ++ "\t" ++ target.commit_id() ++ "\t" ++ ` +
+	`target.local_bookmarks().map(|b| b.name()).join(",") ++ "\t" ++ ` +
+	`target.parents().map(|c| c.local_bookmarks().map(|b| b.name()).join(",")).join(",") ++ "\n"`
+
+// Jujutsu is the experimental backend for jj workspaces. The repository must be colocated
+// with git, since wtp still finds the repository and reads branches through git. A
+// workspace is named after its path relative to the repository, and a bookmark stands in
+// for a branch.
+type Jujutsu struct {
+	mainRepoPath string
+}
+
+// NewJujutsu returns the jj backend for the repository at mainRepoPath.
+func NewJujutsu(mainRepoPath string) Jujutsu {
+	return Jujutsu{mainRepoPath: mainRepoPath}
+}
+
+// Name returns "jj".
+func (Jujutsu) Name() string {
+	return config.VCSJujutsu
+}
+
+// AddWorkdir returns 'jj workspace add' on top of commitish, and, for a new branch, the
+// 'jj bookmark' command creating or tracking it, and, with opts.Sparse, 'jj sparse set'.
+// Force and Detach have no jj equivalent.
+func (j Jujutsu) AddWorkdir(path, commitish string, opts command.GitWorktreeAddOptions) []command.Command {
+	revision := commitish
+	if opts.Track != "" {
+		revision = jjRemoteRevision(opts.Track)
+	}
+	args := []string{"workspace", "add", "--name", j.workspaceName(path)}
+	if revision != "" {
+		args = append(args, "-r", revision)
+	}
+	commands := []command.Command{j.command(append(args, path)...)}
+
+	switch {
+	case opts.Track != "" && !opts.NoTrack && (opts.Branch == "" || opts.Branch == jjLocalName(opts.Track)):
+		commands = append(commands, j.command("bookmark", "track", revision))
+	case opts.Branch != "":
+		if revision == "" {
+			// The new workspace starts on the parent of the repository's working copy
+			revision = "@-"
+		}
+		commands = append(commands, j.command("bookmark", "create", opts.Branch, "-r", revision))
+	}
+	if len(opts.Sparse) > 0 {
+		args := []string{"sparse", "set", "--clear"}
+		for _, sparsePath := range opts.Sparse {
+			args = append(args, "--add", sparsePath)
+		}
+		// 'jj sparse' changes the workspace it runs in
+		commands = append(commands, command.Command{Name: "jj", Args: args, WorkDir: path})
+	}
+	return commands
+}
+
+// ListWorkdirs returns 'jj workspace list' with a template ParseWorkdirs reads.
+func (j Jujutsu) ListWorkdirs() command.Command {
+	return j.command("workspace", "list", "-T", jjWorkspaceTemplate)
+}
+
+// ParseWorkdirs parses the output of ListWorkdirs. A workspace's branch is the first
+// bookmark on its working copy or, as the working copy is usually a new change on top of
+// one, on a parent of it.
+func (j Jujutsu) ParseWorkdirs(output string) []git.Worktree {
+	var main []git.Worktree
+	var others []git.Worktree
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 || fields[0] == "" {
+			continue
+		}
+		wt := git.Worktree{HEAD: fields[1], Branch: DetachedBranch}
+		for _, bookmarks := range fields[2:] {
+			if name, _, _ := strings.Cut(bookmarks, ","); name != "" {
+				wt.Branch = name
+				break
+			}
+		}
+		if fields[0] == jjDefaultWorkspace {
+			wt.Path = j.mainRepoPath
+			wt.IsMain = true
+			main = append(main, wt)
+			continue
+		}
+		wt.Path = filepath.FromSlash(fields[0])
+		if !filepath.IsAbs(wt.Path) {
+			wt.Path = filepath.Join(j.mainRepoPath, wt.Path)
+		}
+		others = append(others, wt)
+	}
+	return append(main, others...)
+}
+
+// RemoveWorkdir returns the commands forgetting the workspace at path. It first runs
+// 'jj status' in the workspace, which snapshots the files of its working copy into a
+// commit, so nothing is lost when the caller deletes them; force is not needed for that.
+func (j Jujutsu) RemoveWorkdir(path string, _ bool) []command.Command {
+	return []command.Command{
+		{Name: "jj", Args: []string{"status"}, WorkDir: path},
+		j.command("workspace", "forget", j.workspaceName(path)),
+	}
+}
+
+// KeepsFiles returns true: 'jj workspace forget' leaves the directory behind.
+func (Jujutsu) KeepsFiles() bool {
+	return true
+}
+
+// Status returns 'jj diff --summary', which prints a line per changed file.
+func (Jujutsu) Status(path string) command.Command {
+	return command.Command{Name: "jj", Args: []string{"diff", "--summary"}, WorkDir: path}
+}
+
+// DeleteBranch returns 'jj bookmark delete'; jj has no merged check to force past.
+func (j Jujutsu) DeleteBranch(branch string, _ bool) command.Command {
+	return j.command("bookmark", "delete", branch)
+}
+
+func (j Jujutsu) command(args ...string) command.Command {
+	return command.Command{Name: "jj", Args: args, WorkDir: j.mainRepoPath}
+}
+
+func (j Jujutsu) workspaceName(path string) string {
+	rel, err := filepath.Rel(j.mainRepoPath, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// jjRemoteRevision turns a git remote branch such as origin/feature into the jj revision
+// feature@origin.
+func jjRemoteRevision(remoteBranch string) string {
+	remote, branch, found := strings.Cut(remoteBranch, "/")
+	if !found {
+		return remoteBranch
+	}
+	return branch + "@" + remote
+}
+
+func jjLocalName(remoteBranch string) string {
+	_, branch, found := strings.Cut(remoteBranch, "/")
+	if !found {
+		return remoteBranch
+	}
+	return branch
+}
@@ -0,0 +1,58 @@
+// Package vcs puts the version control system worktrees are created with behind one
+// interface, so that systems with a worktree-like concept besides git can be supported.
+package vcs
+
+import (
+	"fmt"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+// DetachedBranch is the Branch of a worktree that has no branch checked out.
+const DetachedBranch = "detached"
+
+// Backend builds the commands that create, list, inspect, and remove the working
+// directories of one version control system: git worktrees or jj workspaces.
+type Backend interface {
+	// Name is the defaults.vcs value selecting the backend.
+	Name() string
+	// AddWorkdir returns the commands creating a working directory at path with commitish
+	// checked out, in the order they must run.
+	AddWorkdir(path, commitish string, opts command.GitWorktreeAddOptions) []command.Command
+	// ListWorkdirs returns the command listing the working directories; ParseWorkdirs
+	// reads its output, with the main working directory first.
+	ListWorkdirs() command.Command
+	ParseWorkdirs(output string) []git.Worktree
+	// RemoveWorkdir returns the commands removing the working directory at path.
+	RemoveWorkdir(path string, force bool) []command.Command
+	// KeepsFiles reports whether RemoveWorkdir leaves the directory's files on disk for the
+	// caller to delete.
+	KeepsFiles() bool
+	// Status returns a command printing one line per uncommitted change at path.
+	Status(path string) command.Command
+	// DeleteBranch returns the command deleting branch.
+	DeleteBranch(branch string, force bool) command.Command
+}
+
+// New returns the backend for name, a defaults.vcs value, for the repository at
+// mainRepoPath. An empty name is git.
+func New(name, mainRepoPath string) (Backend, error) {
+	switch name {
+	case "", config.VCSGit:
+		return Git{}, nil
+	case config.VCSJujutsu:
+		return NewJujutsu(mainRepoPath), nil
+	default:
+		return nil, fmt.Errorf("unknown vcs '%s'", name)
+	}
+}
+
+// ForConfig returns the backend cfg selects with defaults.vcs; a nil cfg is git.
+func ForConfig(cfg *config.Config, mainRepoPath string) (Backend, error) {
+	if cfg == nil {
+		return Git{}, nil
+	}
+	return New(cfg.Defaults.VCS, mainRepoPath)
+}
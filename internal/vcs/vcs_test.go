@@ -0,0 +1,183 @@
+package vcs
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/satococoa/wtp/v2/internal/command"
+	"github.com/satococoa/wtp/v2/internal/config"
+	"github.com/satococoa/wtp/v2/internal/git"
+)
+
+func TestNew(t *testing.T) {
+	for name, expected := range map[string]string{"": config.VCSGit, "git": config.VCSGit, "jj": config.VCSJujutsu} {
+		backend, err := New(name, "/repo")
+		require.NoError(t, err)
+		assert.Equal(t, expected, backend.Name())
+	}
+
+	_, err := New("sl", "/repo")
+	assert.ErrorContains(t, err, "unknown vcs 'sl'")
+
+	backend, err := ForConfig(nil, "/repo")
+	require.NoError(t, err)
+	assert.Equal(t, config.VCSGit, backend.Name())
+}
+
+func TestGit(t *testing.T) {
+	opts := command.GitWorktreeAddOptions{Branch: "feature"}
+	backend := Git{}
+
+	assert.Equal(t, []command.Command{command.GitWorktreeAdd("/wt", "main", opts)},
+		backend.AddWorkdir("/wt", "main", opts))
+	assert.Equal(t, command.GitWorktreeList(), backend.ListWorkdirs())
+	assert.Equal(t, []command.Command{command.GitWorktreeRemove("/wt", true)}, backend.RemoveWorkdir("/wt", true))
+	assert.False(t, backend.KeepsFiles())
+	assert.Equal(t, command.GitStatusPorcelain("/wt"), backend.Status("/wt"))
+	assert.Equal(t, command.GitBranchDelete("feature", false), backend.DeleteBranch("feature", false))
+}
+
+func TestGit_AddWorkdirSparse(t *testing.T) {
+	commands := Git{}.AddWorkdir("/wt", "main", command.GitWorktreeAddOptions{
+		Branch: "feature", Sparse: []string{"apps/web", "libs"},
+	})
+
+	require.Len(t, commands, 3)
+	assert.Equal(t, []string{"worktree", "add", "--no-checkout", "-b", "feature", "/wt", "main"}, commands[0].Args)
+	assert.Equal(t, command.GitSparseCheckoutSet("/wt", []string{"apps/web", "libs"}), commands[1])
+	assert.Equal(t, command.GitCheckoutFiles("/wt"), commands[2])
+}
+
+func TestParseGitWorktreeList(t *testing.T) {
+	worktrees := ParseGitWorktreeList("worktree /repo\nHEAD abc\nbranch refs/heads/main\n\n" +
+		"worktree /wt\nHEAD def\ndetached\n")
+
+	assert.Equal(t, []git.Worktree{
+		{Path: "/repo", HEAD: "abc", Branch: "main", IsMain: true},
+		{Path: "/wt", HEAD: "def", Branch: DetachedBranch},
+	}, worktrees)
+}
+
+func TestParseGitWorktreeList_Locked(t *testing.T) {
+	worktrees := ParseGitWorktreeList("worktree /repo\nHEAD abc\nbranch refs/heads/main\n\n" +
+		"worktree /a\nHEAD def\nbranch refs/heads/a\nlocked\n\n" +
+		"worktree /b\nHEAD 123\nbranch refs/heads/b\nlocked on a USB drive\n")
+
+	require.Len(t, worktrees, 3)
+	assert.False(t, worktrees[0].Locked)
+	assert.True(t, worktrees[1].Locked)
+	assert.Empty(t, worktrees[1].LockReason)
+	assert.True(t, worktrees[2].Locked)
+	assert.Equal(t, "on a USB drive", worktrees[2].LockReason)
+}
+
+func TestJujutsu_AddWorkdir(t *testing.T) {
+	mainRepoPath := filepath.Join(string(filepath.Separator), "src", "repo")
+	path := filepath.Join(string(filepath.Separator), "src", "worktrees", "feature", "auth")
+	backend := NewJujutsu(mainRepoPath)
+	jj := func(args ...string) command.Command {
+		return command.Command{Name: "jj", Args: args, WorkDir: mainRepoPath}
+	}
+	add := func(revision ...string) command.Command {
+		args := append([]string{"workspace", "add", "--name", "../worktrees/feature/auth"}, revision...)
+		return jj(append(args, path)...)
+	}
+
+	tests := []struct {
+		name      string
+		commitish string
+		opts      command.GitWorktreeAddOptions
+		expected  []command.Command
+	}{
+		{
+			name:      "existing bookmark",
+			commitish: "feature/auth",
+			expected:  []command.Command{add("-r", "feature/auth")},
+		},
+		{
+			name:      "new bookmark from a revision",
+			commitish: "main",
+			opts:      command.GitWorktreeAddOptions{Branch: "feature/auth"},
+			expected: []command.Command{
+				add("-r", "main"),
+				jj("bookmark", "create", "feature/auth", "-r", "main"),
+			},
+		},
+		{
+			name: "new bookmark from the current change",
+			opts: command.GitWorktreeAddOptions{Branch: "feature/auth"},
+			expected: []command.Command{
+				add(),
+				jj("bookmark", "create", "feature/auth", "-r", "@-"),
+			},
+		},
+		{
+			name:      "remote bookmark",
+			commitish: "origin/feature/auth",
+			opts:      command.GitWorktreeAddOptions{Branch: "feature/auth", Track: "origin/feature/auth"},
+			expected: []command.Command{
+				add("-r", "feature/auth@origin"),
+				jj("bookmark", "track", "feature/auth@origin"),
+			},
+		},
+		{
+			name:      "sparse",
+			commitish: "feature/auth",
+			opts:      command.GitWorktreeAddOptions{Sparse: []string{"apps/web", "libs"}},
+			expected: []command.Command{
+				add("-r", "feature/auth"),
+				{Name: "jj", Args: []string{"sparse", "set", "--clear", "--add", "apps/web", "--add", "libs"}, WorkDir: path},
+			},
+		},
+		{
+			name:      "remote bookmark without tracking",
+			commitish: "origin/feature/auth",
+			opts: command.GitWorktreeAddOptions{
+				Branch: "feature/auth", Track: "origin/feature/auth", NoTrack: true,
+			},
+			expected: []command.Command{
+				add("-r", "feature/auth@origin"),
+				jj("bookmark", "create", "feature/auth", "-r", "feature/auth@origin"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, backend.AddWorkdir(path, tt.commitish, tt.opts))
+		})
+	}
+}
+
+func TestJujutsu_ParseWorkdirs(t *testing.T) {
+	mainRepoPath := filepath.Join(string(filepath.Separator), "src", "repo")
+	backend := NewJujutsu(mainRepoPath)
+
+	worktrees := backend.ParseWorkdirs("../worktrees/feature/auth\tdef\t\tfeature/auth\n" +
+		"default\tabc\tmain,trunk\t\n" +
+		"../worktrees/spike\t123\t\t\n")
+
+	assert.Equal(t, []git.Worktree{
+		{Path: mainRepoPath, HEAD: "abc", Branch: "main", IsMain: true},
+		{Path: filepath.Join(filepath.Dir(mainRepoPath), "worktrees", "feature", "auth"), HEAD: "def",
+			Branch: "feature/auth"},
+		{Path: filepath.Join(filepath.Dir(mainRepoPath), "worktrees", "spike"), HEAD: "123", Branch: DetachedBranch},
+	}, worktrees)
+}
+
+func TestJujutsu_RemoveWorkdir(t *testing.T) {
+	mainRepoPath := filepath.Join(string(filepath.Separator), "src", "repo")
+	path := filepath.Join(mainRepoPath, ".worktrees", "spike")
+	backend := NewJujutsu(mainRepoPath)
+
+	assert.Equal(t, []command.Command{
+		{Name: "jj", Args: []string{"status"}, WorkDir: path},
+		{Name: "jj", Args: []string{"workspace", "forget", ".worktrees/spike"}, WorkDir: mainRepoPath},
+	}, backend.RemoveWorkdir(path, false))
+	assert.True(t, backend.KeepsFiles())
+	assert.Equal(t, command.Command{Name: "jj", Args: []string{"bookmark", "delete", "spike"}, WorkDir: mainRepoPath},
+		backend.DeleteBranch("spike", true))
+}
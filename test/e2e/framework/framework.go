@@ -234,7 +234,8 @@ func (r *TestRepo) RunWTP(args ...string) (string, error) {
 	// Create command with validated binary path
 	cmd := createSafeCommand(r.env.wtpBinary, args...)
 	cmd.Dir = r.path
-	cmd.Env = append(os.Environ(), "HOME="+r.env.tmpDir)
+	// The user cache holds the clone registry, which tests must not add to
+	cmd.Env = append(os.Environ(), "HOME="+r.env.tmpDir, "XDG_CACHE_HOME="+filepath.Join(r.env.tmpDir, ".cache"))
 
 	output, err := cmd.CombinedOutput()
 	return string(output), err